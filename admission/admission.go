@@ -0,0 +1,312 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package admission validates the GenericController, CompositeController
+// & DecoratorController objects that are created or updated in the
+// cluster. This is served by metac as a validating admission webhook
+// so that invalid controllers are rejected by the API server before
+// these get reconciled.
+package admission
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"reflect"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+	"openebs.io/metac/config"
+	"openebs.io/metac/controller/common"
+	"openebs.io/metac/controller/composite"
+	"openebs.io/metac/controller/decorator"
+	"openebs.io/metac/controller/generic"
+	dynamicdiscovery "openebs.io/metac/dynamic/discovery"
+)
+
+// Path is the path at which the admission reviews are served
+const Path = "/validate"
+
+// maxReviewBytes is the maximum size of an admission review that is
+// read from a request
+const maxReviewBytes = 3 * 1024 * 1024
+
+// Validator validates the metac controllers sent as admission reviews
+// by the API server
+type Validator struct {
+	// ResourceManager verifies the resources of a controller are
+	// discovered in the cluster; resources are not verified if this
+	// is nil or has not synced yet
+	ResourceManager *dynamicdiscovery.APIResourceManager
+
+	// HookDialTimeout is the duration within which a connection to
+	// every webhook of a controller needs to be opened; webhooks are
+	// not dialled if this is 0
+	HookDialTimeout time.Duration
+}
+
+// ServeHTTP implements http.Handler interface. The request is an
+// AdmissionReview of admission.k8s.io/v1 or v1beta1 & the response is
+// the same review having the verdict set against it.
+func (v *Validator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+	data, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxReviewBytes))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// NOTE:
+	//	v1beta1 reviews have the same fields as v1 reviews. The
+	// response is sent as the version of the request.
+	review := &admissionv1.AdmissionReview{}
+	err = json.Unmarshal(data, review)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid admission review: %v", err), http.StatusBadRequest)
+		return
+	}
+	if review.Request == nil {
+		http.Error(w, "Invalid admission review: Nil request", http.StatusBadRequest)
+		return
+	}
+	review.Response = v.Review(review.Request)
+	review.Request = nil
+	data, err = json.Marshal(review)
+	if err != nil {
+		glog.Errorf("Can't marshal admission review: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// Review returns the verdict of the given admission request. Only the
+// creates & the updates that change the spec of metac controllers are
+// validated while the rest of the requests are allowed.
+func (v *Validator) Review(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	resp := &admissionv1.AdmissionResponse{UID: req.UID, Allowed: true}
+	if req.Kind.Group != v1alpha1.GroupName || req.SubResource != "" {
+		return resp
+	}
+	switch req.Operation {
+	case admissionv1.Create:
+	case admissionv1.Update:
+		if isSpecUnchangedOrDeleting(req) {
+			glog.V(4).Infof(
+				"Allowed %s of %s %s/%s: Spec is unchanged or object is being deleted",
+				req.Operation, req.Kind.Kind, req.Namespace, req.Name,
+			)
+			return resp
+		}
+	default:
+		return resp
+	}
+	err := v.Validate(req.Kind.Kind, req.Object.Raw)
+	if err != nil {
+		glog.V(2).Infof(
+			"Rejected %s of %s %s/%s: %v",
+			req.Operation, req.Kind.Kind, req.Namespace, req.Name, err,
+		)
+		resp.Allowed = false
+		resp.Result = &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Reason:  metav1.StatusReasonInvalid,
+			Code:    http.StatusUnprocessableEntity,
+			Message: err.Error(),
+		}
+		return resp
+	}
+	glog.V(4).Infof(
+		"Allowed %s of %s %s/%s", req.Operation, req.Kind.Kind, req.Namespace, req.Name,
+	)
+	return resp
+}
+
+// isSpecUnchangedOrDeleting returns true if the given update request
+// does not change the spec of the object or if the object is being
+// deleted
+//
+// NOTE:
+//	Such updates e.g. of labels or the removal of finalizers are not
+// validated. This lets controllers that were stored before a stricter
+// validation or that refer to resources that are no longer discovered
+// to be updated & deleted.
+func isSpecUnchangedOrDeleting(req *admissionv1.AdmissionRequest) bool {
+	if len(req.OldObject.Raw) == 0 {
+		return false
+	}
+	var oldObj, newObj map[string]interface{}
+	if json.Unmarshal(req.OldObject.Raw, &oldObj) != nil ||
+		json.Unmarshal(req.Object.Raw, &newObj) != nil {
+		return false
+	}
+	if metadata, ok := oldObj["metadata"].(map[string]interface{}); ok &&
+		metadata["deletionTimestamp"] != nil {
+		return true
+	}
+	return reflect.DeepEqual(oldObj["spec"], newObj["spec"])
+}
+
+// Validate returns error if the given json encoded controller of the
+// given kind is invalid. Problems found by all the validations are
+// returned as a single aggregated error.
+//
+// NOTE:
+//	Controllers having unknown fields or fields of a wrong type are
+// not validated any further
+func (v *Validator) Validate(kind string, data []byte) error {
+	err := config.ValidateGenericControllers(kind, data)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	var hooks []*v1alpha1.Hook
+	isSynced := v.ResourceManager != nil && v.ResourceManager.HasSynced()
+	switch kind {
+	case "GenericController":
+		gctl := &v1alpha1.GenericController{}
+		err = json.Unmarshal(data, gctl)
+		if err != nil {
+			return errors.Wrapf(err, "Can't decode %s", kind)
+		}
+		errs = append(errs, generic.ValidateConfig(gctl))
+		if isSynced {
+			errs = append(errs, generic.ValidateConfigResources(v.ResourceManager, gctl))
+		}
+		// NOTE:
+		//	Controllers without hooks are rejected since these can't
+		// reconcile their watches
+		if gctl.Spec.Hooks == nil ||
+			(gctl.Spec.Hooks.Sync == nil && gctl.Spec.Hooks.Finalize == nil) {
+			errs = append(errs, errors.Errorf("Invalid hooks: Specify 'Sync' or 'Finalize'"))
+		} else {
+			hooks = []*v1alpha1.Hook{gctl.Spec.Hooks.Sync, gctl.Spec.Hooks.Finalize}
+		}
+	case "CompositeController":
+		cctl := &v1alpha1.CompositeController{}
+		err = json.Unmarshal(data, cctl)
+		if err != nil {
+			return errors.Wrapf(err, "Can't decode %s", kind)
+		}
+		errs = append(errs, composite.ValidateConfig(cctl))
+		if isSynced {
+			errs = append(errs, composite.ValidateConfigResources(v.ResourceManager, cctl))
+		}
+		if cctl.Spec.Hooks != nil {
+			hooks = []*v1alpha1.Hook{
+				cctl.Spec.Hooks.Sync,
+				cctl.Spec.Hooks.Finalize,
+				cctl.Spec.Hooks.PreUpdateChild,
+				cctl.Spec.Hooks.PostUpdateChild,
+			}
+		}
+	case "DecoratorController":
+		dctl := &v1alpha1.DecoratorController{}
+		err = json.Unmarshal(data, dctl)
+		if err != nil {
+			return errors.Wrapf(err, "Can't decode %s", kind)
+		}
+		errs = append(errs, decorator.ValidateConfig(dctl))
+		if isSynced {
+			errs = append(errs, decorator.ValidateConfigResources(v.ResourceManager, dctl))
+		}
+		if dctl.Spec.Hooks != nil {
+			hooks = []*v1alpha1.Hook{dctl.Spec.Hooks.Sync, dctl.Spec.Hooks.Finalize}
+		}
+	default:
+		// not a controller
+		return nil
+	}
+	err = utilerrors.Flatten(utilerrors.NewAggregate(errs))
+	if err != nil {
+		// hooks of an invalid controller are not dialled
+		return err
+	}
+	if v.HookDialTimeout > 0 {
+		return v.validateHooksReachable(hooks)
+	}
+	return nil
+}
+
+// validateHooksReachable returns error if a connection can't be opened
+// to any of the given webhooks or if any of the given inline hooks is
+// not registered
+func (v *Validator) validateHooksReachable(hooks []*v1alpha1.Hook) error {
+	var errs []error
+	for _, hook := range hooks {
+		if hook == nil {
+			continue
+		}
+		if hook.Inline != nil && hook.Inline.FuncName != nil {
+			if !generic.IsInlineHookRegistered(*hook.Inline.FuncName) {
+				errs = append(errs, errors.Errorf(
+					"Inline hook %q is not registered", *hook.Inline.FuncName,
+				))
+			}
+			continue
+		}
+		if hook.Webhook == nil {
+			continue
+		}
+		hookURL, err := common.GetWebhookURL(hook.Webhook)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		err = dialURL(hookURL, v.HookDialTimeout)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "Webhook %s is not reachable", hookURL))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// dialURL opens & closes a connection to the host of the given URL
+// within the given timeout
+func dialURL(rawURL string, timeout time.Duration) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	port := u.Port()
+	if port == "" {
+		switch u.Scheme {
+		case "https":
+			port = "443"
+		case "http":
+			port = "80"
+		default:
+			return errors.Errorf("Unsupported scheme %q", u.Scheme)
+		}
+	}
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(u.Hostname(), port), timeout)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
@@ -0,0 +1,375 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+)
+
+func TestValidatorServeHTTP(t *testing.T) {
+	var tests = map[string]struct {
+		kind      string
+		operation admissionv1.Operation
+		object    string
+		isAllowed bool
+	}{
+		"valid generic controller": {
+			kind:      "GenericController",
+			operation: admissionv1.Create,
+			object: `{
+				"apiVersion": "metac.openebs.io/v1alpha1",
+				"kind": "GenericController",
+				"metadata": {"name": "test", "namespace": "metac"},
+				"spec": {
+					"watch": {"apiVersion": "v1", "resource": "configmaps"},
+					"hooks": {"sync": {"webhook": {"url": "http://hooks.metac/sync"}}}
+				}
+			}`,
+			isAllowed: true,
+		},
+		"generic controller with unknown field": {
+			kind:      "GenericController",
+			operation: admissionv1.Update,
+			object: `{
+				"apiVersion": "metac.openebs.io/v1alpha1",
+				"kind": "GenericController",
+				"metadata": {"name": "test", "namespace": "metac"},
+				"spec": {
+					"watch": {"apiVersion": "v1", "resource": "configmaps"},
+					"hook": {"sync": {"webhook": {"url": "http://hooks.metac/sync"}}}
+				}
+			}`,
+		},
+		"generic controller without hooks": {
+			kind:      "GenericController",
+			operation: admissionv1.Create,
+			object: `{
+				"apiVersion": "metac.openebs.io/v1alpha1",
+				"kind": "GenericController",
+				"metadata": {"name": "test", "namespace": "metac"},
+				"spec": {
+					"watch": {"apiVersion": "v1", "resource": "configmaps"}
+				}
+			}`,
+		},
+		"generic controller with invalid label selector": {
+			kind:      "GenericController",
+			operation: admissionv1.Create,
+			object: `{
+				"apiVersion": "metac.openebs.io/v1alpha1",
+				"kind": "GenericController",
+				"metadata": {"name": "test", "namespace": "metac"},
+				"spec": {
+					"watch": {
+						"apiVersion": "v1",
+						"resource": "configmaps",
+						"labelSelector": {"matchLabels": {"bad key!": "x"}}
+					},
+					"hooks": {"sync": {"webhook": {"url": "http://hooks.metac/sync"}}}
+				}
+			}`,
+		},
+		"composite controller without sync hook": {
+			kind:      "CompositeController",
+			operation: admissionv1.Create,
+			object: `{
+				"apiVersion": "metac.openebs.io/v1alpha1",
+				"kind": "CompositeController",
+				"metadata": {"name": "test"},
+				"spec": {
+					"parentResource": {"apiVersion": "apps/v1", "resource": "deployments"}
+				}
+			}`,
+		},
+		"composite controller with invalid update method": {
+			kind:      "CompositeController",
+			operation: admissionv1.Create,
+			object: `{
+				"apiVersion": "metac.openebs.io/v1alpha1",
+				"kind": "CompositeController",
+				"metadata": {"name": "test"},
+				"spec": {
+					"parentResource": {"apiVersion": "apps/v1", "resource": "deployments"},
+					"childResources": [{
+						"apiVersion": "v1",
+						"resource": "pods",
+						"updateStrategy": {"method": "Sometimes"}
+					}],
+					"hooks": {"sync": {"webhook": {"url": "http://hooks.metac/sync"}}}
+				}
+			}`,
+		},
+		"valid decorator controller": {
+			kind:      "DecoratorController",
+			operation: admissionv1.Update,
+			object: `{
+				"apiVersion": "metac.openebs.io/v1alpha1",
+				"kind": "DecoratorController",
+				"metadata": {"name": "test"},
+				"spec": {
+					"resources": [{
+						"apiVersion": "v1",
+						"resource": "pods",
+						"annotationSelector": {"matchAnnotations": {"metac.openebs.io/enabled": "true"}}
+					}],
+					"hooks": {
+						"sync": {"webhook": {"service": {"name": "hooks", "namespace": "metac"}, "path": "/sync"}}
+					}
+				}
+			}`,
+			isAllowed: true,
+		},
+		"decorator controller with invalid annotation selector": {
+			kind:      "DecoratorController",
+			operation: admissionv1.Create,
+			object: `{
+				"apiVersion": "metac.openebs.io/v1alpha1",
+				"kind": "DecoratorController",
+				"metadata": {"name": "test"},
+				"spec": {
+					"resources": [{
+						"apiVersion": "v1",
+						"resource": "pods",
+						"annotationSelector": {"matchExpressions": [{"key": "enabled", "operator": "Maybe"}]}
+					}],
+					"hooks": {"sync": {"webhook": {"url": "http://hooks.metac/sync"}}}
+				}
+			}`,
+		},
+		"decorator controller with webhook without path": {
+			kind:      "DecoratorController",
+			operation: admissionv1.Create,
+			object: `{
+				"apiVersion": "metac.openebs.io/v1alpha1",
+				"kind": "DecoratorController",
+				"metadata": {"name": "test"},
+				"spec": {
+					"resources": [{"apiVersion": "v1", "resource": "pods"}],
+					"hooks": {"sync": {"webhook": {"service": {"name": "hooks", "namespace": "metac"}}}}
+				}
+			}`,
+		},
+		"delete is not validated": {
+			kind:      "GenericController",
+			operation: admissionv1.Delete,
+			isAllowed: true,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			review := &admissionv1.AdmissionReview{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "admission.k8s.io/v1beta1",
+					Kind:       "AdmissionReview",
+				},
+				Request: &admissionv1.AdmissionRequest{
+					UID: "test-uid",
+					Kind: metav1.GroupVersionKind{
+						Group:   v1alpha1.GroupName,
+						Version: "v1alpha1",
+						Kind:    mock.kind,
+					},
+					Operation: mock.operation,
+				},
+			}
+			if mock.object != "" {
+				review.Request.Object = runtime.RawExtension{Raw: []byte(mock.object)}
+			}
+			got := serveReview(t, &Validator{}, review)
+			if got.APIVersion != "admission.k8s.io/v1beta1" {
+				t.Fatalf("Expected apiVersion admission.k8s.io/v1beta1 got %q", got.APIVersion)
+			}
+			if got.Response == nil || got.Response.UID != "test-uid" {
+				t.Fatalf("Expected response for test-uid got %+v", got.Response)
+			}
+			if got.Response.Allowed != mock.isAllowed {
+				t.Fatalf(
+					"Expected allowed %t got %t: %+v",
+					mock.isAllowed, got.Response.Allowed, got.Response.Result,
+				)
+			}
+			if !mock.isAllowed &&
+				(got.Response.Result == nil || got.Response.Result.Message == "") {
+				t.Fatalf("Expected rejection message got %+v", got.Response.Result)
+			}
+		})
+	}
+}
+
+func TestValidatorReviewUpdate(t *testing.T) {
+	// this controller is invalid since it has no hooks
+	const invalid = `{
+		"apiVersion": "metac.openebs.io/v1alpha1",
+		"kind": "GenericController",
+		"metadata": {%s"name": "test", "namespace": "metac"},
+		"spec": {"watch": {"apiVersion": "v1", "resource": "%s"}}
+	}`
+	var tests = map[string]struct {
+		oldObject string
+		object    string
+		isAllowed bool
+	}{
+		"spec is unchanged": {
+			oldObject: fmt.Sprintf(invalid, "", "configmaps"),
+			object:    fmt.Sprintf(invalid, `"labels": {"app": "metac"}, `, "configmaps"),
+			isAllowed: true,
+		},
+		"finalizer is removed while deleting": {
+			oldObject: fmt.Sprintf(
+				invalid,
+				`"deletionTimestamp": "2019-10-01T00:00:00Z", "finalizers": ["protect"], `,
+				"configmaps",
+			),
+			object: fmt.Sprintf(
+				invalid, `"deletionTimestamp": "2019-10-01T00:00:00Z", `, "configmaps",
+			),
+			isAllowed: true,
+		},
+		"spec is changed": {
+			oldObject: fmt.Sprintf(invalid, "", "configmaps"),
+			object:    fmt.Sprintf(invalid, "", "secrets"),
+		},
+		"old object is not set": {
+			object: fmt.Sprintf(invalid, "", "configmaps"),
+		},
+		"old object is invalid json": {
+			oldObject: `{"spec": [}`,
+			object:    fmt.Sprintf(invalid, "", "configmaps"),
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			req := &admissionv1.AdmissionRequest{
+				UID: "test-uid",
+				Kind: metav1.GroupVersionKind{
+					Group:   v1alpha1.GroupName,
+					Version: "v1alpha1",
+					Kind:    "GenericController",
+				},
+				Operation: admissionv1.Update,
+				Object:    runtime.RawExtension{Raw: []byte(mock.object)},
+			}
+			if mock.oldObject != "" {
+				req.OldObject = runtime.RawExtension{Raw: []byte(mock.oldObject)}
+			}
+			got := (&Validator{}).Review(req)
+			if got.Allowed != mock.isAllowed {
+				t.Fatalf(
+					"Expected allowed %t got %t: %+v", mock.isAllowed, got.Allowed, got.Result,
+				)
+			}
+		})
+	}
+}
+
+func TestValidatorServeHTTPMethod(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, Path, nil)
+	rec := httptest.NewRecorder()
+	(&Validator{}).ServeHTTP(rec, req)
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("Expected status %d got %d", http.StatusMethodNotAllowed, rec.Code)
+	}
+}
+
+func TestValidatorValidateHooksReachable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	// a listener that is closed right away refers to an address that
+	// is not served
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Expected no error got %v", err)
+	}
+	closedURL := "http://" + listener.Addr().String() + "/sync"
+	listener.Close()
+
+	var tests = map[string]struct {
+		url     string
+		isError bool
+	}{
+		"reachable webhook": {
+			url: server.URL + "/sync",
+		},
+		"unreachable webhook": {
+			url:     closedURL,
+			isError: true,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			v := &Validator{HookDialTimeout: 2 * time.Second}
+			err := v.Validate("CompositeController", []byte(`{
+				"apiVersion": "metac.openebs.io/v1alpha1",
+				"kind": "CompositeController",
+				"metadata": {"name": "test"},
+				"spec": {
+					"parentResource": {"apiVersion": "apps/v1", "resource": "deployments"},
+					"hooks": {"sync": {"webhook": {"url": "`+mock.url+`"}}}
+				}
+			}`))
+			if mock.isError && err == nil {
+				t.Fatalf("Expected error got none")
+			}
+			if !mock.isError && err != nil {
+				t.Fatalf("Expected no error got %v", err)
+			}
+		})
+	}
+}
+
+// serveReview sends the given review to the given validator & returns
+// the review it responds with
+func serveReview(
+	t *testing.T, v *Validator, review *admissionv1.AdmissionReview,
+) *admissionv1.AdmissionReview {
+	data, err := json.Marshal(review)
+	if err != nil {
+		t.Fatalf("Expected no error got %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, Path, bytes.NewReader(data))
+	rec := httptest.NewRecorder()
+	v.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	got := &admissionv1.AdmissionReview{}
+	err = json.Unmarshal(rec.Body.Bytes(), got)
+	if err != nil {
+		t.Fatalf("Expected no error got %v", err)
+	}
+	return got
+}
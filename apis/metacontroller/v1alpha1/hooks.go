@@ -31,12 +31,57 @@ type Hook struct {
 
 // Webhook refers to the logic that gets invoked as
 // as web hook to arrive at the desired state
+//
+// NOTE:
+//	Timeout, Retries, TLS & Headers that are not set here are
+// inherited from the hook defaults that metac binary is started with
 type Webhook struct {
 	URL     *string          `json:"url,omitempty"`
 	Timeout *metav1.Duration `json:"timeout,omitempty"`
 
 	Path    *string           `json:"path,omitempty"`
 	Service *ServiceReference `json:"service,omitempty"`
+
+	// Retries is the number of times an invocation that fails with a
+	// transient error e.g. a timeout or a 5xx response is retried
+	//
+	// NOTE:
+	//	This is optional. Defaults to 0 i.e. no retries.
+	Retries *int32 `json:"retries,omitempty"`
+
+	// TLS tunes the verification of the webhook server's certificate
+	//
+	// NOTE:
+	//	This is optional
+	TLS *WebhookTLS `json:"tls,omitempty"`
+
+	// Headers are set against every request of this webhook. These are
+	// merged with the default headers; a header set here overrides the
+	// default header having the same name.
+	//
+	// NOTE:
+	//	This is optional
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// WebhookTLS represents the TLS settings used to invoke a webhook
+// over https
+type WebhookTLS struct {
+	// CABundle is the PEM encoded CA bundle used to verify the
+	// webhook server's certificate. System roots are used if this is
+	// not set.
+	CABundle []byte `json:"caBundle,omitempty"`
+
+	// ServerName is used to verify the hostname of the webhook
+	// server's certificate
+	ServerName *string `json:"serverName,omitempty"`
+
+	// InsecureSkipVerify when set to true does not verify the webhook
+	// server's certificate
+	//
+	// NOTE:
+	//	This should be used for testing purposes only
+	InsecureSkipVerify *bool `json:"insecureSkipVerify,omitempty"`
 }
 
 // Inline refers to the logic that gets invoked as inline
@@ -16,10 +16,72 @@ limitations under the License.
 
 package v1alpha1
 
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// MaxNameSelectorPatternLength is the maximum length of a name
+// pattern set in a NameSelector. This bounds the cost of evaluating
+// the patterns.
+const MaxNameSelectorPatternLength = 253
+
 // NameSelector is used to select resources based on
 // the names set here
+//
+// NOTE:
+//	A name can be a glob pattern e.g. 'tls-*'. Supported wildcards
+// are '*' that matches any sequence of characters & '?' that matches
+// any single character.
 type NameSelector []string
 
+// IsNamePattern returns true if the given name has wildcards
+func IsNamePattern(name string) bool {
+	return strings.ContainsAny(name, "*?")
+}
+
+// Patterns returns the compiled form of the glob patterns present
+// in the selector
+//
+// NOTE:
+//	Patterns are compiled to regular expressions that are guaranteed
+// to run in time linear to the size of the input.
+func (s NameSelector) Patterns() ([]*regexp.Regexp, error) {
+	var patterns []*regexp.Regexp
+	for _, name := range s {
+		if !IsNamePattern(name) {
+			continue
+		}
+		if len(name) > MaxNameSelectorPatternLength {
+			return nil, errors.Errorf(
+				"Invalid name pattern %q: Length %d exceeds max %d",
+				name, len(name), MaxNameSelectorPatternLength,
+			)
+		}
+		var expr strings.Builder
+		expr.WriteString("^")
+		for _, r := range name {
+			switch r {
+			case '*':
+				expr.WriteString(".*")
+			case '?':
+				expr.WriteString(".")
+			default:
+				expr.WriteString(regexp.QuoteMeta(string(r)))
+			}
+		}
+		expr.WriteString("$")
+		pattern, err := regexp.Compile(expr.String())
+		if err != nil {
+			return nil, errors.Wrapf(err, "Invalid name pattern %q", name)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
 // Contains returns true if the provided search item
 // is present in the selector
 func (s NameSelector) Contains(search string) bool {
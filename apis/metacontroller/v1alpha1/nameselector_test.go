@@ -0,0 +1,144 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNameSelectorPatterns(t *testing.T) {
+	var tests = map[string]struct {
+		selector NameSelector
+		matches  []string
+		misses   []string
+		isErr    bool
+	}{
+		"no patterns": {
+			selector: NameSelector{"tls-cert"},
+			misses:   []string{"tls-cert"},
+		},
+		"star pattern": {
+			selector: NameSelector{"tls-*"},
+			matches:  []string{"tls-", "tls-cert", "tls-a.b"},
+			misses:   []string{"tls", "my-tls-cert"},
+		},
+		"question pattern": {
+			selector: NameSelector{"pod-?"},
+			matches:  []string{"pod-1", "pod-a"},
+			misses:   []string{"pod-", "pod-12"},
+		},
+		"dots are literals": {
+			selector: NameSelector{"a.*"},
+			matches:  []string{"a.b"},
+			misses:   []string{"ab"},
+		},
+		"too long pattern": {
+			selector: NameSelector{strings.Repeat("a", MaxNameSelectorPatternLength) + "*"},
+			isErr:    true,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			patterns, err := mock.selector.Patterns()
+			if mock.isErr && err == nil {
+				t.Fatalf("Expected error got none")
+			}
+			if !mock.isErr && err != nil {
+				t.Fatalf("Expected no error got %v", err)
+			}
+			isMatch := func(name string) bool {
+				for _, p := range patterns {
+					if p.MatchString(name) {
+						return true
+					}
+				}
+				return false
+			}
+			for _, name := range mock.matches {
+				if !isMatch(name) {
+					t.Fatalf("Expected %q to match", name)
+				}
+			}
+			for _, name := range mock.misses {
+				if isMatch(name) {
+					t.Fatalf("Expected %q to not match", name)
+				}
+			}
+		})
+	}
+}
+
+func TestIsNamePattern(t *testing.T) {
+	var tests = map[string]struct {
+		name   string
+		expect bool
+	}{
+		"empty name":        {name: "", expect: false},
+		"plain name":        {name: "tls-cert", expect: false},
+		"dotted name":       {name: "a.b", expect: false},
+		"star wildcard":     {name: "tls-*", expect: true},
+		"question wildcard": {name: "pod-?", expect: true},
+		"only star":         {name: "*", expect: true},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			got := IsNamePattern(mock.name)
+			if got != mock.expect {
+				t.Fatalf("Expected pattern %t got %t", mock.expect, got)
+			}
+		})
+	}
+}
+
+func TestNameSelectorContainsIgnoresPatterns(t *testing.T) {
+	var tests = map[string]struct {
+		selector NameSelector
+		search   string
+		expect   bool
+	}{
+		"exact name": {
+			selector: NameSelector{"tls-cert", "tls-*"},
+			search:   "tls-cert",
+			expect:   true,
+		},
+		"name matching pattern": {
+			selector: NameSelector{"tls-*"},
+			search:   "tls-cert",
+			expect:   false,
+		},
+		"pattern as name": {
+			selector: NameSelector{"tls-*"},
+			search:   "tls-*",
+			expect:   true,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			got := mock.selector.Contains(mock.search)
+			if got != mock.expect {
+				t.Fatalf("Expected contains %t got %t", mock.expect, got)
+			}
+		})
+	}
+}
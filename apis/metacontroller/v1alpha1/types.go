@@ -90,6 +90,22 @@ const (
 	ChildUpdateRollingInPlace ChildUpdateMethod = "RollingInPlace"
 )
 
+// NamespacePolicy represents a typed constant to determine how
+// namespace annotations include or exclude namespaces from being
+// reconciled by a controller
+type NamespacePolicy string
+
+const (
+	// NamespacePolicyOptOut implies resources of all namespaces are
+	// reconciled except the namespaces annotated to exclude this
+	// controller
+	NamespacePolicyOptOut NamespacePolicy = "OptOut"
+
+	// NamespacePolicyOptIn implies resources of only those namespaces
+	// are reconciled that are annotated to include this controller
+	NamespacePolicyOptIn NamespacePolicy = "OptIn"
+)
+
 type CompositeControllerChildResourceRule struct {
 	ResourceRule   `json:",inline"`
 	UpdateStrategy *CompositeControllerChildUpdateStrategy `json:"updateStrategy,omitempty"`
@@ -191,6 +207,9 @@ type DecoratorControllerSpec struct {
 	Hooks *DecoratorControllerHooks `json:"hooks,omitempty"`
 
 	ResyncPeriodSeconds *int32 `json:"resyncPeriodSeconds,omitempty"`
+
+	// NamespacePolicy defaults to OptOut
+	NamespacePolicy NamespacePolicy `json:"namespacePolicy,omitempty"`
 }
 
 type DecoratorControllerResourceRule struct {
@@ -18,6 +18,8 @@ package v1alpha1
 
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // +genclient
@@ -26,6 +28,9 @@ import (
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:scope=Namespaced,shortName=gctl
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="Workers",type="integer",JSONPath=".status.workerCount"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 // GenericController defines GenericController API schema
 type GenericController struct {
 	metav1.TypeMeta   `json:",inline"`
@@ -53,6 +58,12 @@ type GenericControllerSpec struct {
 	// the attachments that were created by its controller instance. Other
 	// attachments (i.e. the ones created via some other means) are used
 	// for readonly purposes during reconciliation.
+	//
+	// NOTE:
+	//	Rules of an attachment e.g. readOnly, updatePolicy, etc. are
+	// looked up by api group & kind. Hence at most one attachment can
+	// refer to a given api group & kind. Use selectors of this single
+	// attachment to select resources of different names, labels, etc.
 	Attachments []GenericControllerAttachment `json:"attachments,omitempty"`
 
 	// Hooks to be invoked to arrive at the desired state
@@ -115,12 +126,385 @@ type GenericControllerSpec struct {
 	// ReadOnly is set to true.
 	DeleteAny *bool `json:"deleteAny,omitempty"`
 
-	// Parameters represent a set of key value pairs that can be used by
-	// the sync hook implementation logic.
+	// DryRun when set to true makes every create, update, patch &
+	// delete request of this controller against its watches &
+	// attachments a dry run. These requests are validated & admitted
+	// by the API server but are never persisted.
+	//
+	// NOTE:
+	//	This is useful to exercise the hooks & the reconcile of a new
+	// or changed controller against a live cluster without changing
+	// any resource. Events are still raised against the watches.
+	//
+	// NOTE:
+	//	This is optional. Defaults to false.
+	DryRun *bool `json:"dryRun,omitempty"`
+
+	// Impersonate when set makes this controller send its requests
+	// against the watches & attachments as the given identity instead
+	// of metac's own identity. This scopes the permissions of this
+	// controller to the RBAC rules of the given identity.
+	//
+	// NOTE:
+	//	Watches & attachments are still listed & watched via the
+	// informers that are shared by all the controllers. These use
+	// metac's own identity.
+	//
+	// NOTE:
+	//	This is optional. Metac must be allowed to impersonate the
+	// given identity.
+	Impersonate *GenericControllerImpersonation `json:"impersonate,omitempty"`
+
+	// TargetCluster when set makes this controller create, observe &
+	// update its attachments in the given cluster instead of the
+	// cluster of its watches. Attachments are discovered & cached
+	// separately for this cluster.
+	//
+	// NOTE:
+	//	Attachments in a target cluster can't be owned by their
+	// watches. These are deleted via finalizer when their watch is
+	// deleted. Impersonation if any applies to the watches only.
+	//
+	// NOTE:
+	//	This is optional. Defaults to the cluster of the watches.
+	TargetCluster *GenericControllerTargetCluster `json:"targetCluster,omitempty"`
+
+	// ClientQPS is the number of queries per second that the clients
+	// of this controller are allowed to make. This lets a heavy
+	// controller be throttled independently of other controllers.
+	//
+	// NOTE:
+	//	This replaces the client limit shared by the controllers that
+	// do not set their own. Limits of the individual resources are
+	// still shared by all the controllers.
+	//
+	// NOTE:
+	//	This is optional. Defaults to the controller client QPS of
+	// metac if any. Burst defaults to twice the QPS if not set.
+	ClientQPS *float32 `json:"clientQPS,omitempty"`
+
+	// ClientBurst is the number of queries that the clients of this
+	// controller are allowed to make in a burst
+	//
+	// NOTE:
+	//	This is optional. Defaults to the controller client burst of
+	// metac if any.
+	ClientBurst *int32 `json:"clientBurst,omitempty"`
+
+	// ClientTimeout is the time within which every request of the
+	// clients of this controller should complete e.g. 30s
+	//
+	// NOTE:
+	//	This is optional. Defaults to the controller client timeout of
+	// metac if any.
+	ClientTimeout *metav1.Duration `json:"clientTimeout,omitempty"`
+
+	// OrphanOnDelete when set to true leaves the attachments in place
+	// when their watch or this GenericController is deleted. Ownership
+	// markers i.e. owner references & annotations set against these
+	// attachments by this controller are removed.
+	//
+	// NOTE:
+	//	This is useful during controller migrations when some other
+	// system takes over these attachments.
+	//
+	// NOTE:
+	//	Watch is set with a finalizer when this is set to true. Finalize
+	// hook if any is not invoked since attachments are orphaned.
+	//
+	// NOTE:
+	//	This is optional. Defaults to false.
+	OrphanOnDelete *bool `json:"orphanOnDelete,omitempty"`
+
+	// NamespacePolicy determines if namespaces are included or excluded
+	// from reconciliation based on the annotations set against these
+	// namespaces. A watch that belongs to an excluded namespace is not
+	// reconciled.
+	//
+	// NOTE:
+	//	This lets cluster operators opt a namespace in or out without
+	// changing this GenericController.
+	//
+	// NOTE:
+	//	This is optional. Defaults to OptOut. This does not apply to
+	// cluster scoped watches.
+	NamespacePolicy NamespacePolicy `json:"namespacePolicy,omitempty"`
+
+	// MaxAttachments is the maximum number of attachments across all
+	// the attachment kinds that can be desired per watch. A sync that
+	// needs to create new attachments beyond this count fails & an
+	// event is raised against the watch.
+	//
+	// NOTE:
+	//	This is optional. There is no limit if this is not set.
+	MaxAttachments *int32 `json:"maxAttachments,omitempty"`
+
+	// MaxDeletionsPerSync is the maximum number of attachments that
+	// can be deleted by a single sync. This is either an absolute
+	// number or a percentage of the observed attachments of the watch
+	// e.g. "10%". Percentages are rounded up. A sync that needs to
+	// delete more attachments is aborted & an event is raised against
+	// the watch.
+	//
+	// NOTE:
+	//	This protects against a sync hook that briefly returns an
+	// empty list of desired attachments.
+	//
+	// NOTE:
+	//	This limit does not apply once the watch is being deleted
+	// since finalizing the watch is expected to delete its attachments.
+	//
+	// NOTE:
+	//	Watch can be annotated with metac.openebs.io/skip-deletion-limit
+	// set to "true" to override this limit.
+	//
+	// NOTE:
+	//	This is optional. There is no limit if this is not set.
+	MaxDeletionsPerSync *intstr.IntOrString `json:"maxDeletionsPerSync,omitempty"`
+
+	// MaxUpdateConflictRetries is the maximum number of times an
+	// update of an attachment that fails due to a conflict i.e. the
+	// attachment was changed since it was observed, is retried after
+	// fetching the attachment again. An event is raised against the
+	// watch if the conflict persists after these retries.
+	//
+	// NOTE:
+	//	This applies to the updates of the watch's labels, annotations,
+	// status & finalizers as well as to the adoption & release of
+	// attachments.
+	//
+	// NOTE:
+	//	Conflicts on the fields managed by other field managers during
+	// server side apply are not retried.
+	//
+	// NOTE:
+	//	This is optional. Defaults to 3. Setting this to 0 disables
+	// retries.
+	MaxUpdateConflictRetries *int32 `json:"maxUpdateConflictRetries,omitempty"`
+
+	// ObservedGenerationPath when set makes this controller set the
+	// watch's metadata.generation at this path of the watch's status
+	// after each successful sync. This is a dot separated path that is
+	// relative to the watch's status e.g. "observedGeneration".
+	//
+	// NOTE:
+	//	Sync hook need not return this field as part of the desired
+	// status. This field is retained even if the hook returns a status
+	// without it.
+	//
+	// NOTE:
+	//	This is optional. Observed generation is not set if this is
+	// not set.
+	ObservedGenerationPath *string `json:"observedGenerationPath,omitempty"`
+
+	// StatusRollups aggregate the status of the attachments into the
+	// watch's status. These are applied after the attachments are
+	// reconciled successfully. This avoids a sync hook implementation
+	// for common logic e.g. watch is Ready if all its attachments are
+	// Ready.
+	//
+	// NOTE:
+	//	Sync hook need not return the fields set by these rollups as
+	// part of the desired status. These fields are retained even if the
+	// hook returns a status without them.
 	//
 	// NOTE:
 	//	This is optional
-	Parameters map[string]string `json:"parameters,omitempty"`
+	StatusRollups []GenericControllerStatusRollup `json:"statusRollups,omitempty"`
+
+	// AllowSelfReference when set to true lets this controller manage
+	// attachments that may match its watch i.e. attachments of the same
+	// kind as the watch whose selectors may overlap with the watch's
+	// selectors.
+	//
+	// NOTE:
+	//	Such attachments trigger this controller again & may result in
+	// runaway creation of attachments. Hence this controller does not
+	// start if this is not set to true.
+	//
+	// NOTE:
+	//	This is optional. Defaults to false.
+	AllowSelfReference *bool `json:"allowSelfReference,omitempty"`
+
+	// FinalizerName is the finalizer that is set against the watches
+	// by this controller. This should be a domain prefixed name e.g.
+	// "example.com/my-finalizer".
+	//
+	// NOTE:
+	//	This avoids collisions when multiple metac instances run on the
+	// same cluster. Watches having the default finalizer get migrated to
+	// this finalizer.
+	//
+	// NOTE:
+	//	This is optional. Defaults to protect.gctl.metac.openebs.io/
+	// followed by this controller's namespace & name.
+	FinalizerName *string `json:"finalizerName,omitempty"`
+
+	// ApplyStrategy determines how the desired attachments are applied
+	// against the cluster. LastApplied does a 3-way merge based on the
+	// last applied state that is stored as an annotation against the
+	// attachment. ServerSideApply lets the API server merge the desired
+	// attachment based on its managed fields. ManagedFields does a 3-way
+	// merge where the last applied state is derived from the fields
+	// that are owned by this controller's field manager.
+	//
+	// NOTE:
+	//	Attachments that were applied earlier via LastApplied retain
+	// their last applied annotation. This annotation is no longer
+	// updated once the strategy is switched to ServerSideApply or
+	// ManagedFields.
+	//
+	// NOTE:
+	//	This is optional. Defaults to the strategy that metac binary
+	// is started with, which in turn defaults to LastApplied.
+	ApplyStrategy ApplyStrategy `json:"applyStrategy,omitempty"`
+
+	// ServerSideApply tunes the apply requests when ApplyStrategy is
+	// ServerSideApply. Its field manager is also used when ApplyStrategy
+	// is ManagedFields.
+	//
+	// NOTE:
+	//	This is optional
+	ServerSideApply *ServerSideApplyConfig `json:"serverSideApply,omitempty"`
+
+	// IncludeApplyDiffs when set to true sends the fields of the
+	// attachments that got updated by the previous sync of the watch as
+	// part of the next sync hook request. This helps the hook find why
+	// an attachment was updated.
+	//
+	// NOTE:
+	//	Diffs are tracked in memory & are lost when metac restarts.
+	// Values of the fields are not included.
+	//
+	// NOTE:
+	//	This is optional. Defaults to false.
+	IncludeApplyDiffs *bool `json:"includeApplyDiffs,omitempty"`
+
+	// IncludeWarnings when set to true sends the warnings that were
+	// recently returned by the API server in response to the requests
+	// of this controller as part of every sync hook request. Examples
+	// are usage of deprecated APIs & warnings of admission webhooks.
+	//
+	// NOTE:
+	//	Warnings are captured for the requests made by this controller
+	// & not for the lists & watches of the shared informers. These are
+	// always set at this controller's status.warnings.
+	//
+	// NOTE:
+	//	This is optional. Defaults to false.
+	IncludeWarnings *bool `json:"includeWarnings,omitempty"`
+
+	// Parameters is an arbitrary object that is sent verbatim as part
+	// of every hook request of this controller. This lets a single hook
+	// implementation serve many controllers that are configured
+	// declaratively instead of via environment variables per
+	// deployment.
+	//
+	// NOTE:
+	//	This is optional
+	Parameters *runtime.RawExtension `json:"parameters,omitempty"`
+}
+
+// ApplyStrategy represents the mechanism used to apply the desired
+// state of a resource against the cluster
+type ApplyStrategy string
+
+const (
+	// ApplyStrategyLastApplied implies a 3-way merge between the
+	// observed, desired & last applied states. Last applied state is
+	// stored as an annotation against the resource.
+	ApplyStrategyLastApplied ApplyStrategy = "LastApplied"
+
+	// ApplyStrategyServerSideApply implies the desired state is sent
+	// as an apply patch to the API server. The fields set by this
+	// patch are tracked by the API server against a field manager.
+	ApplyStrategyServerSideApply ApplyStrategy = "ServerSideApply"
+
+	// ApplyStrategyManagedFields implies a 3-way merge between the
+	// observed, desired & last applied states. Last applied state is
+	// derived from the resource's managed fields that are owned by
+	// this field manager.
+	//
+	// NOTE:
+	//	This lets resources created by other tools be adopted without
+	// inheriting a stale last applied annotation.
+	//
+	// NOTE:
+	//	API server attributes only the fields changed by an update to
+	// its field manager. Hence fields of an adopted resource that were
+	// already set to their desired values are not removed when these
+	// are no longer desired.
+	ApplyStrategyManagedFields ApplyStrategy = "ManagedFields"
+)
+
+// ServerSideApplyConflictPolicy represents the policy to be followed
+// when the fields of a server side apply are managed by some other
+// field manager
+type ServerSideApplyConflictPolicy string
+
+const (
+	// ServerSideApplyConflictForce implies the conflicting fields are
+	// taken over by this controller's field manager
+	ServerSideApplyConflictForce ServerSideApplyConflictPolicy = "Force"
+
+	// ServerSideApplyConflictFail implies the apply fails without
+	// changing the resource. The sync is retried later.
+	ServerSideApplyConflictFail ServerSideApplyConflictPolicy = "Fail"
+)
+
+// ServerSideApplyConfig tunes the server side apply of attachments
+type ServerSideApplyConfig struct {
+	// FieldManager is the name of the manager that owns the fields
+	// set by this controller
+	//
+	// NOTE:
+	//	This is optional. Defaults to gctl.metac.openebs.io/ followed
+	// by this controller's namespace & name.
+	FieldManager *string `json:"fieldManager,omitempty"`
+
+	// ConflictPolicy determines what happens when the applied fields
+	// are managed by other field managers
+	//
+	// NOTE:
+	//	This is optional. Defaults to Force.
+	ConflictPolicy ServerSideApplyConflictPolicy `json:"conflictPolicy,omitempty"`
+}
+
+// GenericControllerStatusRollup aggregates the status of the desired
+// attachments of a resource into the watch's status. An attachment
+// matches this rollup if its condition or the result of its JSONPath
+// matches.
+//
+// NOTE:
+//	Either AttachmentCondition or JSONPath should be set. Either
+// Condition or CountsPath or both should be set.
+type GenericControllerStatusRollup struct {
+	// ResourceRule refers to the attachments whose status gets
+	// aggregated
+	ResourceRule `json:",inline"`
+
+	// AttachmentCondition is the type of the condition found at the
+	// attachment's status.conditions. An attachment matches if the
+	// status of this condition is "True".
+	AttachmentCondition string `json:"attachmentCondition,omitempty"`
+
+	// JSONPath is evaluated against each attachment e.g.
+	// {.status.phase}. An attachment matches if the result is equal to
+	// Value.
+	JSONPath string `json:"jsonPath,omitempty"`
+
+	// Value that the result of JSONPath should be equal to
+	Value string `json:"value,omitempty"`
+
+	// Condition is the type of the condition set at the watch's
+	// status.conditions. Its status is set to "True" if all the
+	// attachments match & "False" otherwise.
+	Condition string `json:"condition,omitempty"`
+
+	// CountsPath is a dot separated path relative to the watch's
+	// status e.g. "pods". This is set with the "total" number of
+	// attachments & the number of "matched" attachments.
+	CountsPath string `json:"countsPath,omitempty"`
 }
 
 // GenericControllerHooks holds the sync as well as finalize hooks
@@ -146,6 +530,13 @@ type GenericControllerHooks struct {
 // NOTE:
 // 	A watch as well as any attachment will have its own label selector &/
 // annotation selector.
+//
+// NOTE:
+//	Resource can be set to a short name e.g. deploy, a singular name or
+// a category e.g. all similar to kubectl. These are resolved based on
+// the discovered resources. A category resolves to all its resources
+// when used in an attachment & must have exactly one resource when used
+// in a watch. A name that matches more than one resource is an error.
 type GenericControllerResource struct {
 	ResourceRule `json:",inline"`
 
@@ -168,10 +559,54 @@ type GenericControllerResource struct {
 	//
 	// This is ANDed with other selectors if present
 	ResourceSelector *ResourceSelector `json:"resourceSelector,omitempty"`
+
+	// Namespaces restricts the resources to the given namespaces.
+	// Resources are observed from these namespaces only. Watches of
+	// other namespaces are not reconciled & desired attachments i.e.
+	// hook response that belong to other namespaces result in sync
+	// errors.
+	//
+	// NOTE:
+	//	Informers are started for these namespaces only instead of
+	// caching the resources of all the namespaces. This reduces the
+	// memory used by metac in clusters with many namespaces.
+	//
+	// NOTE:
+	//	This is optional. This is ANDed with NamespaceSelector of an
+	// attachment if present. This can't be set for cluster scoped
+	// resources.
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// SelectorPushDown when set to true sends the label selector &
+	// the name selector of this resource to the API server as part of
+	// the list & watch requests. Only the matching resources are then
+	// cached instead of every resource of this kind. This is useful for
+	// kinds with many resources e.g. Secrets or Pods.
+	//
+	// NOTE:
+	//	A name selector is pushed down only if it has a single name
+	// without wildcards. Other selectors are evaluated by metac as
+	// before.
+	//
+	// NOTE:
+	//	A watch that stops matching the label selector is no longer
+	// observed. Hence its finalizer if any is not removed by this
+	// controller.
+	//
+	// NOTE:
+	//	This is optional. Defaults to false.
+	SelectorPushDown *bool `json:"selectorPushDown,omitempty"`
 }
 
 // GenericControllerAttachment represents a resources that takes
 // part in sync &/or finalize.
+//
+// NOTE:
+//	Resource can be set to "*" to refer to all the resources of the
+// apiVersion e.g. apps/v1. Version of the apiVersion can be set to "*"
+// e.g. apps/* to refer to the preferred version of the api group.
+// Wildcards are expanded based on the discovered resources & are
+// re-evaluated as resources e.g. CRDs get installed or removed.
 type GenericControllerAttachment struct {
 	// This represents the resource that should participates in
 	// sync/finalize
@@ -180,8 +615,268 @@ type GenericControllerAttachment struct {
 	// UpdateStrategy to be used for the resource to take into
 	// account the changes due to sync/finalize
 	UpdateStrategy *GenericControllerAttachmentUpdateStrategy `json:"updateStrategy,omitempty"`
+
+	// ReadOnly when set to true marks the resources selected by this
+	// attachment as observe only. These resources are sent to the
+	// hooks as part of the request but are never created, updated
+	// or deleted by this controller.
+	//
+	// NOTE:
+	//	This is useful to let hooks read resources like nodes,
+	// storage classes or resources managed by other operators without
+	// any risk of these resources being mutated.
+	//
+	// NOTE:
+	//	This is optional. This takes precedence over spec.UpdateAny
+	// and spec.DeleteAny for the resources of this attachment.
+	ReadOnly *bool `json:"readOnly,omitempty"`
+
+	// MetadataOnly when set to true caches only the metadata of the
+	// resources of this attachment instead of the entire resources.
+	// Entire resources are fetched from the API server only when a
+	// hook is invoked.
+	//
+	// NOTE:
+	//	This reduces the memory used by metac for read only attachments
+	// of kinds with many or large resources. However hook invocations
+	// get these resources from the API server. A resource is got again
+	// only if its resource version changed since it was last got.
+	//
+	// NOTE:
+	//	This is optional. Defaults to false. This can be set only if
+	// this attachment or this controller is read only. This can't be
+	// set along with ResourceSelector since resource selectors may
+	// refer to fields other than metadata.
+	MetadataOnly *bool `json:"metadataOnly,omitempty"`
+
+	// UpdatePolicy determines if the resources of this attachment
+	// should be reconciled once they are created.
+	//
+	// NOTE:
+	//	This is optional. Defaults to Reconcile.
+	UpdatePolicy GenericControllerAttachmentUpdatePolicy `json:"updatePolicy,omitempty"`
+
+	// DeletionPropagation is the propagation policy used when this
+	// controller deletes the resources of this attachment. Supported
+	// values are Foreground, Background & Orphan.
+	//
+	// NOTE:
+	//	Resources with finalizers of their own e.g. persistent volume
+	// claims or namespaces may need Foreground propagation.
+	//
+	// NOTE:
+	//	This is optional. Defaults to Background.
+	DeletionPropagation *metav1.DeletionPropagation `json:"deletionPropagation,omitempty"`
+
+	// Adopt determines if this controller should take ownership of
+	// the existing resources of this attachment that are declared as
+	// desired by the hook but were not created by this controller.
+	//
+	// NOTE:
+	//	This is optional. Defaults to Never.
+	Adopt GenericControllerAttachmentAdoptPolicy `json:"adopt,omitempty"`
+
+	// NamespaceSelector selects the namespaces of the resources of
+	// this attachment based on the labels of these namespaces. This
+	// lets a watch manage attachments across namespaces.
+	//
+	// NOTE:
+	//	Desired attachments i.e. hook response that belong to other
+	// namespaces result in sync errors.
+	//
+	// NOTE:
+	//	This is optional. Resources from all namespaces are selected
+	// if this is not set. This is not used for cluster scoped resources.
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// MaxCount is the maximum number of resources of this attachment
+	// that can be desired per watch. A sync that needs to create new
+	// resources beyond this count fails & an event is raised against
+	// the watch.
+	//
+	// NOTE:
+	//	This guards the cluster against a buggy sync hook that keeps
+	// desiring new resources.
+	//
+	// NOTE:
+	//	This is optional. There is no limit if this is not set.
+	MaxCount *int32 `json:"maxCount,omitempty"`
+
+	// ListMergeKeys declares the keys by which the list fields of the
+	// resources of this attachment are merged during updates. Lists
+	// whose items are not identified by well known keys e.g. name or
+	// port are replaced as a whole otherwise.
+	//
+	// NOTE:
+	//	This is useful for custom resources that lack strategic merge
+	// metadata. This is not used if apply strategy is ServerSideApply.
+	// This is not used for the built-in kinds of core, apps & batch api
+	// groups either, since these are merged via strategic merge patch.
+	//
+	// NOTE:
+	//	This is optional
+	ListMergeKeys []ListMergeKey `json:"listMergeKeys,omitempty"`
+
+	// IgnorePaths are the dot separated paths of the fields of the
+	// resources of this attachment that are never updated by this
+	// controller e.g. "spec.replicas". These fields are excluded while
+	// finding the differences between observed & desired states as
+	// well as while applying the desired state.
+	//
+	// NOTE:
+	//	This is useful for fields that are mutated by other controllers
+	// or defaulted by the API server. Paths can't refer to the items of
+	// list fields.
+	//
+	// NOTE:
+	//	This is optional
+	IgnorePaths []string `json:"ignorePaths,omitempty"`
+
+	// ConflictPolicy determines what happens when the desired fields
+	// of the resources of this attachment were changed by someone else
+	// e.g. another controller, since these were last applied by this
+	// controller
+	//
+	// NOTE:
+	//	A field owned by some other field manager is a conflict if apply
+	// strategy is ServerSideApply or ManagedFields. List fields are
+	// compared as a whole.
+	//
+	// NOTE:
+	//	This is optional. Conflicting fields are overwritten without an
+	// event if this is not set. Server side apply follows the conflict
+	// policy of spec.serverSideApply in that case.
+	ConflictPolicy GenericControllerAttachmentConflictPolicy `json:"conflictPolicy,omitempty"`
+
+	// DryRunCompare when set to true sends the merged state of a
+	// resource of this attachment as a dry run update before actually
+	// updating it. Resource is not updated if the result of this dry
+	// run does not differ from its observed state. This avoids updates
+	// that are due to fields defaulted by the API server.
+	//
+	// NOTE:
+	//	Results of dry runs are cached till the resource or its desired
+	// state changes. This is not used if apply strategy is
+	// ServerSideApply since API server does not persist an apply that
+	// changes nothing.
+	//
+	// NOTE:
+	//	This is optional. Defaults to false.
+	DryRunCompare *bool `json:"dryRunCompare,omitempty"`
+
+	// MergeMode determines how the desired state of a resource of this
+	// attachment as returned by the sync hook is merged with its
+	// observed state
+	//
+	// NOTE:
+	//	This is not used if apply strategy is ServerSideApply since the
+	// API server merges partial desired states by design.
+	//
+	// NOTE:
+	//	This is optional. Defaults to ThreeWay.
+	MergeMode GenericControllerAttachmentMergeMode `json:"mergeMode,omitempty"`
 }
 
+// ListMergeKey represents the key by which the items of a list field
+// are merged
+type ListMergeKey struct {
+	// Path is the dot separated path of the list field e.g.
+	// "spec.volumes". List items are not part of this path e.g.
+	// "spec.containers.ports" refers to the ports of each container.
+	Path string `json:"path"`
+
+	// Key is the field of the list items that identifies these items
+	// e.g. "name"
+	Key string `json:"key"`
+}
+
+// GenericControllerAttachmentAdoptPolicy represents the policy to be
+// followed to adopt pre-existing attachments
+type GenericControllerAttachmentAdoptPolicy string
+
+const (
+	// AttachmentAdoptPolicyNever implies the pre-existing attachment
+	// is never adopted. Such an attachment is only updated if
+	// spec.UpdateAny is set.
+	AttachmentAdoptPolicyNever GenericControllerAttachmentAdoptPolicy = "Never"
+
+	// AttachmentAdoptPolicyIfUnowned implies the pre-existing
+	// attachment is adopted if it is neither created by any watch
+	// nor has a controller owner reference
+	//
+	// NOTE:
+	//	This is useful to migrate resources that were installed
+	// by tools like Helm or kubectl.
+	AttachmentAdoptPolicyIfUnowned GenericControllerAttachmentAdoptPolicy = "IfUnowned"
+
+	// AttachmentAdoptPolicyAlways implies the pre-existing attachment
+	// is adopted even if it is owned by some other watch or controller
+	AttachmentAdoptPolicyAlways GenericControllerAttachmentAdoptPolicy = "Always"
+)
+
+// GenericControllerAttachmentConflictPolicy represents the policy to
+// be followed when the desired fields of an attachment were changed by
+// someone else
+type GenericControllerAttachmentConflictPolicy string
+
+const (
+	// AttachmentConflictPolicyForce implies the conflicting fields are
+	// overwritten with their desired values. An event is raised against
+	// the watch.
+	AttachmentConflictPolicyForce GenericControllerAttachmentConflictPolicy = "Force"
+
+	// AttachmentConflictPolicyYield implies the conflicting fields are
+	// left with their observed values. Rest of the desired fields are
+	// applied.
+	AttachmentConflictPolicyYield GenericControllerAttachmentConflictPolicy = "Yield"
+
+	// AttachmentConflictPolicyFail implies the attachment is not
+	// updated. A warning event is raised against the watch & the sync
+	// is retried later.
+	AttachmentConflictPolicyFail GenericControllerAttachmentConflictPolicy = "Fail"
+)
+
+// GenericControllerAttachmentMergeMode represents the way desired
+// state of an attachment is merged with its observed state
+type GenericControllerAttachmentMergeMode string
+
+const (
+	// AttachmentMergeModeThreeWay implies a 3-way merge between the
+	// observed, desired & last applied states. Fields that were applied
+	// earlier & are no longer desired are removed.
+	AttachmentMergeModeThreeWay GenericControllerAttachmentMergeMode = "ThreeWay"
+
+	// AttachmentMergeModePartial implies the desired state consists of
+	// only those fields that the sync hook intends to manage. These
+	// fields are merged onto the observed state. Rest of the fields are
+	// left untouched & no field is ever removed.
+	//
+	// NOTE:
+	//	This is useful to co-manage resources with other operators.
+	// Lists without merge keys are replaced as a whole.
+	AttachmentMergeModePartial GenericControllerAttachmentMergeMode = "Partial"
+)
+
+// GenericControllerAttachmentUpdatePolicy represents the policy to
+// be followed for an attachment after it gets created
+type GenericControllerAttachmentUpdatePolicy string
+
+const (
+	// AttachmentUpdatePolicyReconcile implies the attachment will be
+	// created, updated & deleted as per its desired state
+	AttachmentUpdatePolicyReconcile GenericControllerAttachmentUpdatePolicy = "Reconcile"
+
+	// AttachmentUpdatePolicyCreateOnly implies the attachment will be
+	// created if it is missing but will never be updated or deleted
+	// afterwards. In other words, the attachment is owned by the user
+	// once it is bootstrapped.
+	//
+	// NOTE:
+	//	This is useful to seed resources like secrets or configs
+	// which get managed by admins subsequently.
+	AttachmentUpdatePolicyCreateOnly GenericControllerAttachmentUpdatePolicy = "CreateOnly"
+)
+
 // GenericControllerAttachmentUpdateStrategy represents the update
 // strategy to be followed for the attachments
 type GenericControllerAttachmentUpdateStrategy struct {
@@ -196,8 +891,114 @@ type GenericControllerAttachmentUpdateStrategy struct {
 	// does a plain override of the observed instance from desired
 	// instance.
 	Patch *bool `json:"patch,omitempty"`
+
+	// RecreateOnImmutableError when set to true will delete the
+	// attachment if its in-place update fails due to changes in its
+	// immutable fields. The attachment gets created with its desired
+	// state during the next sync.
+	//
+	// NOTE:
+	//	Such recreations are rate limited per GenericController.
+	//
+	// NOTE:
+	//	This is optional. Defaults to false.
+	RecreateOnImmutableError *bool `json:"recreateOnImmutableError,omitempty"`
+
+	// PatchType when set sends the in-place updates of the attachments
+	// as patches of this type instead of full updates. A patch consists
+	// of the differences between the observed & the merged states.
+	//
+	// NOTE:
+	//	StrategicMerge is supported by built-in resources only. Custom
+	// resources are patched via Merge instead.
+	//
+	// NOTE:
+	//	This is optional. This is ignored by server side apply which
+	// always sends apply patches.
+	PatchType GenericControllerAttachmentPatchType `json:"patchType,omitempty"`
+}
+
+// GenericControllerImpersonation represents the identity used by a
+// GenericController to send its requests
+type GenericControllerImpersonation struct {
+	// ServiceAccount whose identity is used
+	//
+	// NOTE:
+	//	This can't be set along with User
+	ServiceAccount *GenericControllerServiceAccount `json:"serviceAccount,omitempty"`
+
+	// User whose identity is used
+	//
+	// NOTE:
+	//	This can't be set along with ServiceAccount
+	User string `json:"user,omitempty"`
+
+	// Groups the user belongs to
+	//
+	// NOTE:
+	//	This is optional
+	Groups []string `json:"groups,omitempty"`
+}
+
+// GenericControllerServiceAccount refers to a service account
+type GenericControllerServiceAccount struct {
+	// Name of the service account
+	Name string `json:"name"`
+
+	// Namespace of the service account
+	//
+	// NOTE:
+	//	This is optional. Defaults to the namespace of the
+	// GenericController.
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// GenericControllerTargetCluster refers to the cluster where the
+// attachments of a GenericController are created & observed
+type GenericControllerTargetCluster struct {
+	// KubeconfigSecretRef refers to the secret that holds the
+	// kubeconfig of this cluster
+	KubeconfigSecretRef GenericControllerKubeconfigSecretRef `json:"kubeconfigSecretRef"`
+}
+
+// GenericControllerKubeconfigSecretRef refers to a secret that holds
+// a kubeconfig
+type GenericControllerKubeconfigSecretRef struct {
+	// Name of the secret
+	Name string `json:"name"`
+
+	// Namespace of the secret
+	//
+	// NOTE:
+	//	This is optional. Defaults to the namespace of the
+	// GenericController.
+	Namespace string `json:"namespace,omitempty"`
+
+	// Key of the secret data that holds the kubeconfig
+	//
+	// NOTE:
+	//	This is optional. Defaults to "kubeconfig".
+	Key string `json:"key,omitempty"`
 }
 
+// GenericControllerAttachmentPatchType represents the type of patch
+// used to update the attachments in-place
+type GenericControllerAttachmentPatchType string
+
+const (
+	// AttachmentPatchTypeStrategicMerge implies the attachments are
+	// updated via strategic merge patches
+	AttachmentPatchTypeStrategicMerge GenericControllerAttachmentPatchType = "StrategicMerge"
+
+	// AttachmentPatchTypeMerge implies the attachments are updated via
+	// JSON merge patches i.e. RFC 7386
+	AttachmentPatchTypeMerge GenericControllerAttachmentPatchType = "Merge"
+
+	// AttachmentPatchTypeJSON implies the attachments are updated via
+	// JSON patches i.e. RFC 6902
+	AttachmentPatchTypeJSON GenericControllerAttachmentPatchType = "JSON"
+)
+
 // GenericControllerStatusPhase represents various execution states
 // supported by GenericController
 type GenericControllerStatusPhase string
@@ -210,12 +1011,57 @@ const (
 	// GenericControllerStatusPhaseError is used to indicate Error
 	// state of GenericController
 	GenericControllerStatusPhaseError GenericControllerStatusPhase = "Error"
+
+	// GenericControllerStatusPhasePending is used to indicate that
+	// GenericController is waiting for its watch resource to be
+	// discovered e.g. CRD to be installed
+	GenericControllerStatusPhasePending GenericControllerStatusPhase = "Pending"
 )
 
 // GenericControllerStatus represents the current state of this controller
 type GenericControllerStatus struct {
 	Phase      GenericControllerStatusPhase `json:"phase"`
 	Conditions []GenericControllerCondition `json:"conditions,omitempty"`
+
+	// ObservedGeneration is the generation of this controller that
+	// was last handled by metac
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Watch is the watch resource as resolved via discovery
+	Watch *GenericControllerResolvedResource `json:"watch,omitempty"`
+
+	// Attachments are the attachment resources as resolved via
+	// discovery. Wildcard attachments if any are expanded.
+	Attachments []GenericControllerResolvedResource `json:"attachments,omitempty"`
+
+	// InformersSynced is true if the caches of watch & attachment
+	// resources are synced
+	InformersSynced bool `json:"informersSynced"`
+
+	// WorkerCount is the number of workers that reconcile the watch
+	// resources of this controller
+	WorkerCount int `json:"workerCount,omitempty"`
+
+	// LastError is the most recent error if any that was observed
+	// while starting this controller or while reconciling its watch
+	// resources
+	LastError string `json:"lastError,omitempty"`
+
+	// Warnings are the distinct warnings that were recently returned
+	// by the API server in response to the requests of this controller
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// GenericControllerResolvedResource represents a resource that has
+// been resolved via discovery
+type GenericControllerResolvedResource struct {
+	APIVersion string `json:"apiVersion"`
+	Resource   string `json:"resource"`
+	Kind       string `json:"kind,omitempty"`
+
+	// Deprecation is the warning of the API server if this resource
+	// is deprecated e.g. since its apiVersion will be removed
+	Deprecation string `json:"deprecation,omitempty"`
 }
 
 // GenericControllerConditionState represents various execution states
@@ -246,6 +1092,37 @@ const (
 	GenericControllerConditionAssertFailed GenericControllerConditionAssert = "Failed"
 )
 
+// GenericControllerConditionType represents the standard condition
+// types set by metac against a GenericController
+type GenericControllerConditionType string
+
+const (
+	// GenericControllerConditionReady is true when this controller
+	// is running & its informers are synced
+	GenericControllerConditionReady GenericControllerConditionType = "Ready"
+
+	// GenericControllerConditionDegraded is true when this controller
+	// failed to start or failed to reconcile its most recent watch
+	GenericControllerConditionDegraded GenericControllerConditionType = "Degraded"
+)
+
+// GenericControllerConditionStatus represents the status of a
+// standard condition
+type GenericControllerConditionStatus string
+
+const (
+	// GenericControllerConditionTrue implies the condition holds
+	GenericControllerConditionTrue GenericControllerConditionStatus = "True"
+
+	// GenericControllerConditionFalse implies the condition does
+	// not hold
+	GenericControllerConditionFalse GenericControllerConditionStatus = "False"
+
+	// GenericControllerConditionUnknown implies the condition can't
+	// be determined
+	GenericControllerConditionUnknown GenericControllerConditionStatus = "Unknown"
+)
+
 // GenericControllerCondition represents a condition that can be
 // used to represent the current state of this controller. This can also
 // be used to indicate if this controller can proceed further.
@@ -278,6 +1155,21 @@ type GenericControllerCondition struct {
 	// LastUpdatedTimestamp is the last timestamp when this
 	// condition got added/updated
 	LastUpdatedTimestamp *metav1.Time `json:"lastUpdatedTimestamp,omitempty"`
+
+	// Type of a standard condition e.g. Ready. ID is set to the
+	// same value for a standard condition.
+	Type GenericControllerConditionType `json:"type,omitempty"`
+
+	// Status of a standard condition
+	Status GenericControllerConditionStatus `json:"status,omitempty"`
+
+	// Reason is a brief reason for the status of a standard
+	// condition
+	Reason string `json:"reason,omitempty"`
+
+	// LastTransitionTime is the last timestamp when the status of
+	// a standard condition changed
+	LastTransitionTime *metav1.Time `json:"lastTransitionTime,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -23,6 +23,7 @@ package v1alpha1
 import (
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	intstr "k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -645,6 +646,46 @@ func (in *GenericControllerAttachment) DeepCopyInto(out *GenericControllerAttach
 		*out = new(GenericControllerAttachmentUpdateStrategy)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ReadOnly != nil {
+		in, out := &in.ReadOnly, &out.ReadOnly
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MetadataOnly != nil {
+		in, out := &in.MetadataOnly, &out.MetadataOnly
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DeletionPropagation != nil {
+		in, out := &in.DeletionPropagation, &out.DeletionPropagation
+		*out = new(v1.DeletionPropagation)
+		**out = **in
+	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaxCount != nil {
+		in, out := &in.MaxCount, &out.MaxCount
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ListMergeKeys != nil {
+		in, out := &in.ListMergeKeys, &out.ListMergeKeys
+		*out = make([]ListMergeKey, len(*in))
+		copy(*out, *in)
+	}
+	if in.IgnorePaths != nil {
+		in, out := &in.IgnorePaths, &out.IgnorePaths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DryRunCompare != nil {
+		in, out := &in.DryRunCompare, &out.DryRunCompare
+		*out = new(bool)
+		**out = **in
+	}
 	return
 }
 
@@ -666,6 +707,11 @@ func (in *GenericControllerAttachmentUpdateStrategy) DeepCopyInto(out *GenericCo
 		*out = new(bool)
 		**out = **in
 	}
+	if in.RecreateOnImmutableError != nil {
+		in, out := &in.RecreateOnImmutableError, &out.RecreateOnImmutableError
+		*out = new(bool)
+		**out = **in
+	}
 	return
 }
 
@@ -696,6 +742,10 @@ func (in *GenericControllerCondition) DeepCopyInto(out *GenericControllerConditi
 		in, out := &in.LastUpdatedTimestamp, &out.LastUpdatedTimestamp
 		*out = (*in).DeepCopy()
 	}
+	if in.LastTransitionTime != nil {
+		in, out := &in.LastTransitionTime, &out.LastTransitionTime
+		*out = (*in).DeepCopy()
+	}
 	return
 }
 
@@ -735,6 +785,48 @@ func (in *GenericControllerHooks) DeepCopy() *GenericControllerHooks {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GenericControllerImpersonation) DeepCopyInto(out *GenericControllerImpersonation) {
+	*out = *in
+	if in.ServiceAccount != nil {
+		in, out := &in.ServiceAccount, &out.ServiceAccount
+		*out = new(GenericControllerServiceAccount)
+		**out = **in
+	}
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GenericControllerImpersonation.
+func (in *GenericControllerImpersonation) DeepCopy() *GenericControllerImpersonation {
+	if in == nil {
+		return nil
+	}
+	out := new(GenericControllerImpersonation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GenericControllerKubeconfigSecretRef) DeepCopyInto(out *GenericControllerKubeconfigSecretRef) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GenericControllerKubeconfigSecretRef.
+func (in *GenericControllerKubeconfigSecretRef) DeepCopy() *GenericControllerKubeconfigSecretRef {
+	if in == nil {
+		return nil
+	}
+	out := new(GenericControllerKubeconfigSecretRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GenericControllerList) DeepCopyInto(out *GenericControllerList) {
 	*out = *in
@@ -768,6 +860,22 @@ func (in *GenericControllerList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GenericControllerResolvedResource) DeepCopyInto(out *GenericControllerResolvedResource) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GenericControllerResolvedResource.
+func (in *GenericControllerResolvedResource) DeepCopy() *GenericControllerResolvedResource {
+	if in == nil {
+		return nil
+	}
+	out := new(GenericControllerResolvedResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GenericControllerResource) DeepCopyInto(out *GenericControllerResource) {
 	*out = *in
@@ -792,6 +900,16 @@ func (in *GenericControllerResource) DeepCopyInto(out *GenericControllerResource
 		*out = new(ResourceSelector)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SelectorPushDown != nil {
+		in, out := &in.SelectorPushDown, &out.SelectorPushDown
+		*out = new(bool)
+		**out = **in
+	}
 	return
 }
 
@@ -805,6 +923,22 @@ func (in *GenericControllerResource) DeepCopy() *GenericControllerResource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GenericControllerServiceAccount) DeepCopyInto(out *GenericControllerServiceAccount) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GenericControllerServiceAccount.
+func (in *GenericControllerServiceAccount) DeepCopy() *GenericControllerServiceAccount {
+	if in == nil {
+		return nil
+	}
+	out := new(GenericControllerServiceAccount)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GenericControllerSpec) DeepCopyInto(out *GenericControllerSpec) {
 	*out = *in
@@ -841,12 +975,95 @@ func (in *GenericControllerSpec) DeepCopyInto(out *GenericControllerSpec) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.DryRun != nil {
+		in, out := &in.DryRun, &out.DryRun
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Impersonate != nil {
+		in, out := &in.Impersonate, &out.Impersonate
+		*out = new(GenericControllerImpersonation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TargetCluster != nil {
+		in, out := &in.TargetCluster, &out.TargetCluster
+		*out = new(GenericControllerTargetCluster)
+		**out = **in
+	}
+	if in.ClientQPS != nil {
+		in, out := &in.ClientQPS, &out.ClientQPS
+		*out = new(float32)
+		**out = **in
+	}
+	if in.ClientBurst != nil {
+		in, out := &in.ClientBurst, &out.ClientBurst
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ClientTimeout != nil {
+		in, out := &in.ClientTimeout, &out.ClientTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.OrphanOnDelete != nil {
+		in, out := &in.OrphanOnDelete, &out.OrphanOnDelete
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MaxAttachments != nil {
+		in, out := &in.MaxAttachments, &out.MaxAttachments
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxDeletionsPerSync != nil {
+		in, out := &in.MaxDeletionsPerSync, &out.MaxDeletionsPerSync
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.MaxUpdateConflictRetries != nil {
+		in, out := &in.MaxUpdateConflictRetries, &out.MaxUpdateConflictRetries
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ObservedGenerationPath != nil {
+		in, out := &in.ObservedGenerationPath, &out.ObservedGenerationPath
+		*out = new(string)
+		**out = **in
+	}
+	if in.StatusRollups != nil {
+		in, out := &in.StatusRollups, &out.StatusRollups
+		*out = make([]GenericControllerStatusRollup, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowSelfReference != nil {
+		in, out := &in.AllowSelfReference, &out.AllowSelfReference
+		*out = new(bool)
+		**out = **in
+	}
+	if in.FinalizerName != nil {
+		in, out := &in.FinalizerName, &out.FinalizerName
+		*out = new(string)
+		**out = **in
+	}
+	if in.ServerSideApply != nil {
+		in, out := &in.ServerSideApply, &out.ServerSideApply
+		*out = new(ServerSideApplyConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.IncludeApplyDiffs != nil {
+		in, out := &in.IncludeApplyDiffs, &out.IncludeApplyDiffs
+		*out = new(bool)
+		**out = **in
+	}
+	if in.IncludeWarnings != nil {
+		in, out := &in.IncludeWarnings, &out.IncludeWarnings
+		*out = new(bool)
+		**out = **in
+	}
 	if in.Parameters != nil {
 		in, out := &in.Parameters, &out.Parameters
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
 	}
 	return
 }
@@ -871,6 +1088,21 @@ func (in *GenericControllerStatus) DeepCopyInto(out *GenericControllerStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Watch != nil {
+		in, out := &in.Watch, &out.Watch
+		*out = new(GenericControllerResolvedResource)
+		**out = **in
+	}
+	if in.Attachments != nil {
+		in, out := &in.Attachments, &out.Attachments
+		*out = make([]GenericControllerResolvedResource, len(*in))
+		copy(*out, *in)
+	}
+	if in.Warnings != nil {
+		in, out := &in.Warnings, &out.Warnings
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -884,6 +1116,40 @@ func (in *GenericControllerStatus) DeepCopy() *GenericControllerStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GenericControllerStatusRollup) DeepCopyInto(out *GenericControllerStatusRollup) {
+	*out = *in
+	out.ResourceRule = in.ResourceRule
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GenericControllerStatusRollup.
+func (in *GenericControllerStatusRollup) DeepCopy() *GenericControllerStatusRollup {
+	if in == nil {
+		return nil
+	}
+	out := new(GenericControllerStatusRollup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GenericControllerTargetCluster) DeepCopyInto(out *GenericControllerTargetCluster) {
+	*out = *in
+	out.KubeconfigSecretRef = in.KubeconfigSecretRef
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GenericControllerTargetCluster.
+func (in *GenericControllerTargetCluster) DeepCopy() *GenericControllerTargetCluster {
+	if in == nil {
+		return nil
+	}
+	out := new(GenericControllerTargetCluster)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Hook) DeepCopyInto(out *Hook) {
 	*out = *in
@@ -931,6 +1197,22 @@ func (in *Inline) DeepCopy() *Inline {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListMergeKey) DeepCopyInto(out *ListMergeKey) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListMergeKey.
+func (in *ListMergeKey) DeepCopy() *ListMergeKey {
+	if in == nil {
+		return nil
+	}
+	out := new(ListMergeKey)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in NameSelector) DeepCopyInto(out *NameSelector) {
 	{
@@ -1100,6 +1382,27 @@ func (in *SelectorTerm) DeepCopy() *SelectorTerm {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServerSideApplyConfig) DeepCopyInto(out *ServerSideApplyConfig) {
+	*out = *in
+	if in.FieldManager != nil {
+		in, out := &in.FieldManager, &out.FieldManager
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServerSideApplyConfig.
+func (in *ServerSideApplyConfig) DeepCopy() *ServerSideApplyConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ServerSideApplyConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServiceReference) DeepCopyInto(out *ServiceReference) {
 	*out = *in
@@ -1196,6 +1499,23 @@ func (in *Webhook) DeepCopyInto(out *Webhook) {
 		*out = new(ServiceReference)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Retries != nil {
+		in, out := &in.Retries, &out.Retries
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(WebhookTLS)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
@@ -1208,3 +1528,34 @@ func (in *Webhook) DeepCopy() *Webhook {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookTLS) DeepCopyInto(out *WebhookTLS) {
+	*out = *in
+	if in.CABundle != nil {
+		in, out := &in.CABundle, &out.CABundle
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+	if in.ServerName != nil {
+		in, out := &in.ServerName, &out.ServerName
+		*out = new(string)
+		**out = **in
+	}
+	if in.InsecureSkipVerify != nil {
+		in, out := &in.InsecureSkipVerify, &out.InsecureSkipVerify
+		*out = new(bool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookTLS.
+func (in *WebhookTLS) DeepCopy() *WebhookTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookTLS)
+	in.DeepCopyInto(out)
+	return out
+}
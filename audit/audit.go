@@ -0,0 +1,235 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package audit records every write that metac makes against the
+// API server i.e. the creates, updates, patches & deletes of the
+// watches & their attachments. Each write is recorded as an entry
+// that answers what changed the object & why.
+package audit
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+const (
+	// maximum number of entries that are buffered before these get
+	// written to the sink; entries are dropped once the buffer is full
+	bufferSize = 4096
+
+	// maximum number of entries written to the sink at once
+	batchSize = 256
+
+	// interval at which the buffered entries are written
+	flushInterval = 1 * time.Second
+)
+
+// Operations of the recorded writes
+const (
+	OperationCreate           = "create"
+	OperationUpdate           = "update"
+	OperationUpdateStatus     = "update_status"
+	OperationPatch            = "patch"
+	OperationDelete           = "delete"
+	OperationDeleteCollection = "delete_collection"
+)
+
+// OutcomeSuccess is the outcome of a write that succeeded. Writes that
+// failed have the reason of their failure as their outcome e.g.
+// Conflict.
+const OutcomeSuccess = "Success"
+
+// Entry represents a single write made against the API server
+type Entry struct {
+	// time at which the write completed
+	Time time.Time `json:"time"`
+
+	// controller that made the write e.g. namespace/name of a
+	// GenericController; this is empty for the writes that are not
+	// made on behalf of a controller
+	Controller string `json:"controller,omitempty"`
+
+	// watch whose sync made the write referred to by its apiVersion,
+	// kind, namespace & name; this is empty if not known
+	Watch string `json:"watch,omitempty"`
+
+	// written object
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name,omitempty"`
+
+	// subresource that was written if any e.g. status or scale
+	Subresource string `json:"subresource,omitempty"`
+
+	// create, update, update_status, patch, delete or
+	// delete_collection
+	Operation string `json:"operation"`
+
+	// summary of the fields that were changed by the write if known
+	//
+	// NOTE:
+	//	Values are not part of the summary since these may be
+	// sensitive e.g. data of secrets
+	Diff string `json:"diff,omitempty"`
+
+	// Success or the reason of the failure of the write
+	Outcome string `json:"outcome"`
+
+	// error of the write if it failed
+	Error string `json:"error,omitempty"`
+}
+
+// Config represents the tunables of auditing
+type Config struct {
+	// sink the entries are written to i.e. stdout, stderr, a file
+	// as file:<path> or an http(s) URL of a webhook; auditing is
+	// disabled if this is not set
+	Sink string
+}
+
+// auditor is the running auditor if auditing is enabled; this is
+// accessed atomically
+var auditor atomic.Value
+
+// recorder buffers the entries & writes these to its sink
+type recorder struct {
+	sink Sink
+
+	entries chan Entry
+	stopCh  chan struct{}
+	doneCh  chan struct{}
+
+	// number of entries that were dropped since the buffer was full
+	dropped int64
+}
+
+// Start writes the recorded entries to the sink of the given config.
+// Nothing is recorded if the config does not set a sink. The returned
+// function writes the pending entries & stops auditing.
+//
+// NOTE:
+//	This needs to be invoked before the controllers are started
+func Start(config Config) (func(), error) {
+	if config.Sink == "" {
+		return func() {}, nil
+	}
+	sink, err := NewSink(config.Sink)
+	if err != nil {
+		return nil, err
+	}
+	r := &recorder{
+		sink:    sink,
+		entries: make(chan Entry, bufferSize),
+		stopCh:  make(chan struct{}),
+		doneCh:  make(chan struct{}),
+	}
+	go r.run()
+	auditor.Store(r)
+	return func() {
+		r.stop()
+		err := sink.Close()
+		if err != nil {
+			glog.Warningf("Failed to close audit sink %s: %v", sink, err)
+		}
+	}, nil
+}
+
+// IsEnabled returns true if the writes are being audited
+func IsEnabled() bool {
+	return getRecorder() != nil
+}
+
+// Record buffers the given entry to be written to the sink. This is a
+// no-op if auditing is not enabled. The entry is dropped if the
+// buffer is full.
+func Record(entry Entry) {
+	r := getRecorder()
+	if r == nil {
+		return
+	}
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	select {
+	case r.entries <- entry:
+	default:
+		atomic.AddInt64(&r.dropped, 1)
+	}
+}
+
+// getRecorder returns the running recorder if any
+func getRecorder() *recorder {
+	r, _ := auditor.Load().(*recorder)
+	return r
+}
+
+// run writes the buffered entries in batches till this recorder is
+// stopped
+func (r *recorder) run() {
+	defer close(r.doneCh)
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	var batch []Entry
+	for {
+		select {
+		case entry := <-r.entries:
+			batch = append(batch, entry)
+			if len(batch) >= batchSize {
+				batch = r.flush(batch)
+			}
+		case <-ticker.C:
+			batch = r.flush(batch)
+		case <-r.stopCh:
+			// write whatever is buffered
+			for {
+				select {
+				case entry := <-r.entries:
+					batch = append(batch, entry)
+				default:
+					r.flush(batch)
+					return
+				}
+			}
+		}
+	}
+}
+
+// stop writes the buffered entries & stops this recorder
+func (r *recorder) stop() {
+	close(r.stopCh)
+	<-r.doneCh
+}
+
+// flush writes the given entries & returns the batch to be filled
+// next. Entries are dropped if these can't be written.
+func (r *recorder) flush(batch []Entry) []Entry {
+	if dropped := atomic.SwapInt64(&r.dropped, 0); dropped > 0 {
+		glog.Warningf("Dropped %d audit entries: Audit buffer is full", dropped)
+	}
+	if len(batch) == 0 {
+		return batch
+	}
+	err := r.sink.Write(batch)
+	if err != nil {
+		glog.Warningf("Failed to write %d audit entries to %s: %v", len(batch), r.sink, err)
+	}
+	return batch[:0]
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNewSink(t *testing.T) {
+	var tests = map[string]struct {
+		spec    string
+		isError bool
+	}{
+		"stdout": {
+			spec: "stdout",
+		},
+		"stderr": {
+			spec: "stderr",
+		},
+		"webhook": {
+			spec: "https://audit.example.com/entries",
+		},
+		"file without path": {
+			spec:    "file:",
+			isError: true,
+		},
+		"unknown sink": {
+			spec:    "syslog",
+			isError: true,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			_, err := NewSink(mock.spec)
+			if mock.isError && err == nil {
+				t.Fatalf("Expected error got none")
+			}
+			if !mock.isError && err != nil {
+				t.Fatalf("Expected no error got %v", err)
+			}
+		})
+	}
+}
+
+func TestStreamSinkWrite(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewStreamSink("test", &buf)
+	err := sink.Write([]Entry{
+		{Controller: "ns/ctrl", Kind: "Pod", Name: "a", Operation: OperationCreate, Outcome: OutcomeSuccess},
+		{Controller: "ns/ctrl", Kind: "Pod", Name: "b", Operation: OperationDelete, Outcome: "NotFound"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error got %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines got %d: %q", len(lines), buf.String())
+	}
+	var got Entry
+	err = json.Unmarshal([]byte(lines[1]), &got)
+	if err != nil {
+		t.Fatalf("Expected no error got %v", err)
+	}
+	if got.Name != "b" || got.Operation != OperationDelete || got.Outcome != "NotFound" {
+		t.Fatalf("Expected delete of b with NotFound got %+v", got)
+	}
+}
+
+func TestWebhookSinkWrite(t *testing.T) {
+	var tests = map[string]struct {
+		status  int
+		isError bool
+	}{
+		"accepted": {
+			status: http.StatusAccepted,
+		},
+		"rejected": {
+			status:  http.StatusInternalServerError,
+			isError: true,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			var got WebhookRequest
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_ = json.NewDecoder(r.Body).Decode(&got)
+				w.WriteHeader(mock.status)
+			}))
+			defer srv.Close()
+
+			err := NewWebhookSink(srv.URL).Write([]Entry{{Name: "a"}})
+			if mock.isError && err == nil {
+				t.Fatalf("Expected error got none")
+			}
+			if !mock.isError && err != nil {
+				t.Fatalf("Expected no error got %v", err)
+			}
+			if len(got.Entries) != 1 || got.Entries[0].Name != "a" {
+				t.Fatalf("Expected 1 entry of a got %+v", got.Entries)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// prefix of the sink that writes to a file
+	fileSinkPrefix = "file:"
+
+	// time within which a single webhook request should complete
+	webhookRequestTimeout = 10 * time.Second
+)
+
+// Sink writes the audit entries to their destination
+type Sink interface {
+	// Write writes the given entries in the given order
+	Write(entries []Entry) error
+
+	// Close releases the resources held by this sink
+	Close() error
+
+	// String describes this sink
+	String() string
+}
+
+// NewSink returns the sink of the given spec i.e. stdout, stderr,
+// file:<path> or an http(s) URL of a webhook
+func NewSink(spec string) (Sink, error) {
+	switch {
+	case spec == "stdout":
+		return NewStreamSink("stdout", os.Stdout), nil
+	case spec == "stderr":
+		return NewStreamSink("stderr", os.Stderr), nil
+	case strings.HasPrefix(spec, fileSinkPrefix):
+		return NewFileSink(strings.TrimPrefix(spec, fileSinkPrefix))
+	case strings.HasPrefix(spec, "http://"), strings.HasPrefix(spec, "https://"):
+		return NewWebhookSink(spec), nil
+	default:
+		return nil, errors.Errorf(
+			"Invalid audit sink %q: Want stdout, stderr, file:<path> or an http(s) URL", spec,
+		)
+	}
+}
+
+// streamSink writes the entries as JSON lines to a stream
+type streamSink struct {
+	name string
+
+	mutex  sync.Mutex
+	writer io.Writer
+	closer io.Closer
+}
+
+// NewStreamSink returns a sink that writes the entries as JSON lines
+// to the given writer
+func NewStreamSink(name string, writer io.Writer) Sink {
+	return &streamSink{name: name, writer: writer}
+}
+
+// NewFileSink returns a sink that appends the entries as JSON lines
+// to the file at the given path. The file is created if it does not
+// exist.
+func NewFileSink(path string) (Sink, error) {
+	if path == "" {
+		return nil, errors.Errorf("Invalid audit sink: Missing file path")
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Can't open audit file %q", path)
+	}
+	return &streamSink{name: fileSinkPrefix + path, writer: file, closer: file}, nil
+}
+
+// Write implements Sink interface
+func (s *streamSink) Write(entries []Entry) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	buf := bufio.NewWriter(s.writer)
+	encoder := json.NewEncoder(buf)
+	for _, entry := range entries {
+		err := encoder.Encode(entry)
+		if err != nil {
+			return errors.Wrapf(err, "Failed to encode audit entry")
+		}
+	}
+	return buf.Flush()
+}
+
+// Close implements Sink interface
+func (s *streamSink) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
+
+// String implements Stringer interface
+func (s *streamSink) String() string {
+	return s.name
+}
+
+// WebhookRequest is the body of the request that is sent to an audit
+// webhook
+type WebhookRequest struct {
+	Entries []Entry `json:"entries"`
+}
+
+// webhookSink posts the entries as JSON to a webhook
+type webhookSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookSink returns a sink that posts the entries to the webhook
+// of the given URL as a WebhookRequest. Any 2xx response implies the
+// entries were written.
+func NewWebhookSink(url string) Sink {
+	return &webhookSink{
+		url:    url,
+		client: &http.Client{Timeout: webhookRequestTimeout},
+	}
+}
+
+// Write implements Sink interface
+func (s *webhookSink) Write(entries []Entry) error {
+	body, err := json.Marshal(WebhookRequest{Entries: entries})
+	if err != nil {
+		return errors.Wrapf(err, "Failed to marshal audit entries")
+	}
+	resp, err := s.client.Post(s.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return errors.Errorf("Got status %d: Response %q", resp.StatusCode, respBody)
+	}
+	return nil
+}
+
+// Close implements Sink interface
+func (s *webhookSink) Close() error {
+	return nil
+}
+
+// String implements Stringer interface
+func (s *webhookSink) String() string {
+	return fmt.Sprintf("webhook %s", s.url)
+}
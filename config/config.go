@@ -17,13 +17,21 @@ limitations under the License.
 package config
 
 import (
+	"fmt"
 	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
 	"strings"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/golang/glog"
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/json"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 
 	"openebs.io/metac/apis/metacontroller/v1alpha1"
 	k8s "openebs.io/metac/third_party/kubernetes"
@@ -40,12 +48,8 @@ func (mc MetacConfigs) ListGenericControllers() ([]*v1alpha1.GenericController,
 		if u.GetKind() != "GenericController" {
 			continue
 		}
-		raw, err := u.MarshalJSON()
-		if err != nil {
-			return nil, err
-		}
 		gctl := v1alpha1.GenericController{}
-		if err := json.Unmarshal(raw, &gctl); err != nil {
+		if err := unmarshalConfig(u, &gctl); err != nil {
 			return nil, err
 		}
 		gctls = append(gctls, &gctl)
@@ -53,14 +57,177 @@ func (mc MetacConfigs) ListGenericControllers() ([]*v1alpha1.GenericController,
 	return gctls, nil
 }
 
+// ListCompositeControllers returns all CompositeController configs
+func (mc MetacConfigs) ListCompositeControllers() ([]*v1alpha1.CompositeController, error) {
+	var cctls []*v1alpha1.CompositeController
+	for _, u := range mc {
+		if u.GetKind() != "CompositeController" {
+			continue
+		}
+		cctl := v1alpha1.CompositeController{}
+		if err := unmarshalConfig(u, &cctl); err != nil {
+			return nil, err
+		}
+		cctls = append(cctls, &cctl)
+	}
+	return cctls, nil
+}
+
+// ListDecoratorControllers returns all DecoratorController configs
+func (mc MetacConfigs) ListDecoratorControllers() ([]*v1alpha1.DecoratorController, error) {
+	var dctls []*v1alpha1.DecoratorController
+	for _, u := range mc {
+		if u.GetKind() != "DecoratorController" {
+			continue
+		}
+		dctl := v1alpha1.DecoratorController{}
+		if err := unmarshalConfig(u, &dctl); err != nil {
+			return nil, err
+		}
+		dctls = append(dctls, &dctl)
+	}
+	return dctls, nil
+}
+
+// unmarshalConfig unmarshals the given config into the given typed
+// instance
+func unmarshalConfig(u unstructured.Unstructured, into interface{}) error {
+	raw, err := u.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, into)
+}
+
 // Config is the path to metac's Config files
+//
+// NOTE:
+//	Path may be a directory, a file or a glob pattern. Directories
+// are walked recursively. Hidden directories e.g. the ones managed by
+// kubelet for mounted config maps are skipped. Files found at
+// profiles/<profile>/ of a walked directory belong to that profile
+// unless they set their own. Files are loaded in the sorted order of
+// their paths. Configs of different files that have the same kind,
+// namespace & name are handled as per DuplicatePolicy.
 type Config struct {
 	Path string
+
+	// Include has the glob patterns of the config files that are
+	// loaded. All the config files are loaded if this is empty.
+	//
+	// NOTE:
+	//	Patterns are matched against the file's path relative to the
+	// walked directory as well as against the file's name
+	Include []string
+
+	// Exclude has the glob patterns of the config files that are
+	// not loaded. This has higher priority than Include.
+	Exclude []string
+
+	// Renderer renders the config files as templates. This happens
+	// before variables are substituted. Config files are not rendered
+	// if this is nil.
+	Renderer *Renderer
+
+	// Substituter substitutes the variables referred in the config
+	// files. Variables are not substituted if this is nil.
+	Substituter *Substituter
+
+	// DuplicatePolicy decides what happens to the configs that have
+	// the same kind, namespace & name. Defaults to Fail. With Override
+	// a config overrides the configs of earlier files e.g.
+	// 20-override.yaml overrides 10-base.yaml.
+	DuplicatePolicy DuplicatePolicy
 }
 
 // New returns a new instance of config
 func New(path string) *Config {
-	return &Config{path}
+	return &Config{Path: path}
+}
+
+// ValidatePatterns returns error if any of the given glob patterns
+// is malformed
+func ValidatePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		_, err := filepath.Match(pattern, "")
+		if err != nil {
+			return errors.Wrapf(err, "Invalid metac config pattern %q", pattern)
+		}
+	}
+	return nil
+}
+
+// isMatch returns true if the file at the given relative path matches
+// any of the given patterns
+func isMatch(relPath string, patterns []string) bool {
+	relPath = filepath.ToSlash(relPath)
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, relPath); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(pattern, path.Base(relPath)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isHiddenDir returns true if the given directory name is hidden
+func isHiddenDir(name string) bool {
+	return strings.HasPrefix(name, ".") && name != "." && name != ".."
+}
+
+// listFiles returns the files matched by the config's path in sorted
+// order. Each file is returned along with its path relative to the
+// walked directory.
+func (c *Config) listFiles() ([]string, map[string]string, error) {
+	matches, err := filepath.Glob(c.Path)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "Invalid metac config path %s", c.Path)
+	}
+	if len(matches) == 0 {
+		// report the error of the path as is
+		_, statErr := os.Stat(c.Path)
+		if statErr != nil {
+			return nil, nil, statErr
+		}
+		matches = []string{c.Path}
+	}
+
+	var files []string
+	relPaths := make(map[string]string)
+	for _, match := range matches {
+		walkErr := filepath.Walk(match, func(file string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				if file != match && isHiddenDir(info.Name()) {
+					glog.V(4).Infof(
+						"Will skip metac config dir %s at path %s: Hidden dir", file, c.Path,
+					)
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if _, found := relPaths[file]; found {
+				// already matched by some other glob match
+				return nil
+			}
+			relPath, err := filepath.Rel(match, file)
+			if err != nil || relPath == "." {
+				relPath = info.Name()
+			}
+			files = append(files, file)
+			relPaths[file] = relPath
+			return nil
+		})
+		if walkErr != nil {
+			return nil, nil, walkErr
+		}
+	}
+	sort.Strings(files)
+	return files, relPaths, nil
 }
 
 // Load loads all metac config files & converts them
@@ -68,36 +235,42 @@ func New(path string) *Config {
 func (c *Config) Load() (MetacConfigs, error) {
 	glog.V(4).Infof("Will load metac config(s) from path %s", c.Path)
 
-	files, readDirErr := ioutil.ReadDir(c.Path)
-	if readDirErr != nil {
-		return nil, readDirErr
+	files, relPaths, listErr := c.listFiles()
+	if listErr != nil {
+		return nil, listErr
 	}
 
 	if len(files) == 0 {
 		return nil, errors.Errorf("No metac config(s) found at %s", c.Path)
 	}
 
-	var out MetacConfigs
+	// configs of different files may have the same key
+	overrides := NewOverrides(c.DuplicatePolicy)
+	var validateErrs []error
 
 	// there can be multiple config files
-	for _, file := range files {
-		fileName := file.Name()
-		if file.IsDir() || file.Mode().IsDir() {
+	for _, fileNameWithPath := range files {
+		relPath := relPaths[fileNameWithPath]
+		if !strings.HasSuffix(relPath, ".yaml") && !strings.HasSuffix(relPath, ".json") {
 			glog.V(4).Infof(
-				"Will skip metac config %s at path %s: Not a file", fileName, c.Path,
+				"Will skip metac config %s at path %s: Not yaml or json", relPath, c.Path,
 			)
-			// we don't want to load directory
+			// we support either proper yaml or json file only
 			continue
 		}
-		if !strings.HasSuffix(fileName, ".yaml") && !strings.HasSuffix(fileName, ".json") {
+		if len(c.Include) != 0 && !isMatch(relPath, c.Include) {
 			glog.V(4).Infof(
-				"Will skip metac config %s at path %s: Not yaml or json", fileName, c.Path,
+				"Will skip metac config %s at path %s: Not included", relPath, c.Path,
+			)
+			continue
+		}
+		if isMatch(relPath, c.Exclude) {
+			glog.V(4).Infof(
+				"Will skip metac config %s at path %s: Excluded", relPath, c.Path,
 			)
-			// we support either proper yaml or json file only
 			continue
 		}
 
-		fileNameWithPath := c.Path + fileName
 		glog.V(4).Infof("Will load metac config %s", fileNameWithPath)
 
 		contents, readFileErr := ioutil.ReadFile(fileNameWithPath)
@@ -107,16 +280,178 @@ func (c *Config) Load() (MetacConfigs, error) {
 			)
 		}
 
+		contents, renderErr := c.Renderer.Render(fileNameWithPath, contents)
+		if renderErr != nil {
+			return nil, errors.Wrapf(
+				renderErr, "Failed to render metac config %s", fileNameWithPath,
+			)
+		}
+
+		contents, subErr := c.Substituter.Substitute(contents)
+		if subErr != nil {
+			return nil, errors.Wrapf(
+				subErr, "Failed to substitute metac config %s", fileNameWithPath,
+			)
+		}
+
+		// problems of all the files are reported together
+		validateErr := ValidateGenericControllers(fileNameWithPath, contents)
+		if validateErr != nil {
+			validateErrs = append(validateErrs, validateErr)
+			continue
+		}
+
 		ul, loaderr := k8s.YAMLToUnstructuredSlice(contents)
 		if loaderr != nil {
 			loaderr = errors.Wrapf(loaderr, "Failed to load metac config %s", fileNameWithPath)
 			return nil, loaderr
 		}
 
+		// files in profile directories belong to these profiles
+		setProfile(ul, profileOfPath(relPath))
+
 		glog.V(4).Infof("Metac config %s loaded successfully", fileNameWithPath)
-		out = append(out, ul...)
+		overrides.Add(fileNameWithPath, ul)
+	}
+
+	if len(validateErrs) != 0 {
+		return nil, errors.Wrapf(
+			utilerrors.Flatten(utilerrors.NewAggregate(validateErrs)),
+			"Invalid metac config(s) at path %s",
+			c.Path,
+		)
+	}
+
+	out, dupErr := overrides.Configs()
+	if dupErr != nil {
+		return nil, errors.Wrapf(dupErr, "Invalid metac config(s) at path %s", c.Path)
 	}
 
 	glog.V(4).Infof("Metac config(s) loaded successfully from path %s", c.Path)
 	return out, nil
 }
+
+// WatchDirs returns the directories that are watched to find the
+// changes to the config files matched by the config's path
+//
+// NOTE:
+//	This includes the directory of the path that is free of glob
+// patterns. This lets newly created files & directories that match
+// these patterns be found.
+func (c *Config) WatchDirs() ([]string, error) {
+	dirs := make(map[string]bool)
+	root := c.Path
+	for hasGlobMeta(root) {
+		root = filepath.Dir(root)
+	}
+	if info, err := os.Stat(root); err == nil && info.IsDir() {
+		dirs[filepath.Clean(root)] = true
+	}
+	matches, err := filepath.Glob(c.Path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Invalid metac config path %s", c.Path)
+	}
+	for _, match := range matches {
+		walkErr := filepath.Walk(match, func(file string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() {
+				dirs[filepath.Dir(filepath.Clean(file))] = true
+				return nil
+			}
+			if file != match && isHiddenDir(info.Name()) {
+				return filepath.SkipDir
+			}
+			dirs[filepath.Clean(file)] = true
+			return nil
+		})
+		if walkErr != nil {
+			return nil, walkErr
+		}
+	}
+	out := make([]string, 0, len(dirs))
+	for dir := range dirs {
+		out = append(out, dir)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// String implements Stringer interface
+func (c *Config) String() string {
+	return fmt.Sprintf("config path %s", c.Path)
+}
+
+// List returns the configs at this config's path. This implements
+// ConfigSource interface.
+func (c *Config) List() (MetacConfigs, error) {
+	return c.Load()
+}
+
+// Watch invokes the given function whenever the files at this
+// config's path change. This implements ConfigSource interface.
+func (c *Config) Watch(stopCh <-chan struct{}, onChange func()) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return errors.Wrapf(err, "Can't watch %s", c)
+	}
+	defer watcher.Close()
+
+	err = c.addWatches(watcher)
+	if err != nil {
+		return errors.Wrapf(err, "Can't watch %s", c)
+	}
+	glog.Infof("Watching %s for changes", c)
+
+	for {
+		select {
+		case <-stopCh:
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			glog.V(4).Infof("%s changed: %s", c, event)
+			// directories may have been added by this change
+			err := c.addWatches(watcher)
+			if err != nil {
+				utilruntime.HandleError(errors.Wrapf(err, "Can't watch %s", c))
+			}
+			onChange()
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			utilruntime.HandleError(errors.Wrapf(err, "Error watching %s", c))
+		}
+	}
+}
+
+// addWatches watches the directories having the files at this
+// config's path
+//
+// NOTE:
+//	Watches of the removed directories are dropped by the watcher
+// itself
+func (c *Config) addWatches(watcher *fsnotify.Watcher) error {
+	dirs, err := c.WatchDirs()
+	if err != nil {
+		return err
+	}
+	if len(dirs) == 0 {
+		return errors.Errorf("No directories found")
+	}
+	for _, dir := range dirs {
+		err = watcher.Add(dir)
+		if err != nil {
+			return errors.Wrapf(err, "Can't watch directory %s", dir)
+		}
+	}
+	return nil
+}
+
+// hasGlobMeta returns true if the given path has any glob pattern
+func hasGlobMeta(pattern string) bool {
+	return strings.ContainsAny(pattern, `*?[\`)
+}
@@ -17,6 +17,11 @@ limitations under the License.
 package config
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	k8s "openebs.io/metac/third_party/kubernetes"
@@ -40,6 +45,23 @@ func TestConfigLoad(t *testing.T) {
 	if len(gctls) != 1 {
 		t.Fatalf("Expected gctl count 1: Got %d", len(gctls))
 	}
+	cctls, err := mConfigs.ListCompositeControllers()
+	if err != nil {
+		t.Fatalf("Expected no error while listing cctls: Got %v", err)
+	}
+	if len(cctls) != 1 || cctls[0].Name != "bluegreen-controller" {
+		t.Fatalf("Expected cctl bluegreen-controller: Got %v", cctls)
+	}
+	if len(cctls[0].Spec.ChildResources) != 2 {
+		t.Fatalf("Expected cctl child count 2: Got %d", len(cctls[0].Spec.ChildResources))
+	}
+	dctls, err := mConfigs.ListDecoratorControllers()
+	if err != nil {
+		t.Fatalf("Expected no error while listing dctls: Got %v", err)
+	}
+	if len(dctls) != 1 || dctls[0].Name != "cluster-parent" {
+		t.Fatalf("Expected dctl cluster-parent: Got %v", dctls)
+	}
 }
 
 func TestMetacConfigsListGeneric(t *testing.T) {
@@ -115,3 +137,160 @@ test-foo: 2
 		)
 	}
 }
+
+// writeGCtl writes a GenericController config with the given name to
+// the given file
+func writeGCtl(t *testing.T, file, name string) {
+	err := os.MkdirAll(filepath.Dir(file), 0755)
+	if err != nil {
+		t.Fatalf("Expected no error: Got %v", err)
+	}
+	err = ioutil.WriteFile(file, []byte(`
+apiVersion: metac.openebs.io/v1alpha1
+kind: GenericController
+metadata:
+  name: `+name+`
+`), 0644)
+	if err != nil {
+		t.Fatalf("Expected no error: Got %v", err)
+	}
+}
+
+func TestConfigLoadNested(t *testing.T) {
+	root, err := ioutil.TempDir("", "metac-config-test")
+	if err != nil {
+		t.Fatalf("Expected no error: Got %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	writeGCtl(t, filepath.Join(root, "gctl-root.yaml"), "gctl-root")
+	writeGCtl(t, filepath.Join(root, "team-a", "gctl-a.yaml"), "gctl-a")
+	writeGCtl(t, filepath.Join(root, "team-a", "app", "gctl-a-app.yaml"), "gctl-a-app")
+	writeGCtl(t, filepath.Join(root, "team-b", "gctl-b.yaml"), "gctl-b")
+	writeGCtl(t, filepath.Join(root, "team-b", "gctl-b.test.yaml"), "gctl-b-test")
+	writeGCtl(t, filepath.Join(root, "..data", "gctl-hidden.yaml"), "gctl-hidden")
+
+	var tests = map[string]struct {
+		path      string
+		include   []string
+		exclude   []string
+		gctlNames []string
+	}{
+		"nested dirs are walked": {
+			path: root,
+			gctlNames: []string{
+				"gctl-root", "gctl-a-app", "gctl-a", "gctl-b-test", "gctl-b",
+			},
+		},
+		"glob of dirs": {
+			path:      filepath.Join(root, "team-*"),
+			gctlNames: []string{"gctl-a-app", "gctl-a", "gctl-b-test", "gctl-b"},
+		},
+		"glob of files": {
+			path:      filepath.Join(root, "*", "gctl-?.yaml"),
+			gctlNames: []string{"gctl-a", "gctl-b"},
+		},
+		"include by relative path": {
+			path:      root,
+			include:   []string{"team-a/*/*.yaml"},
+			gctlNames: []string{"gctl-a-app"},
+		},
+		"exclude by name": {
+			path:      root,
+			exclude:   []string{"*.test.yaml", "gctl-root.yaml"},
+			gctlNames: []string{"gctl-a-app", "gctl-a", "gctl-b"},
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			config := &Config{
+				Path:    mock.path,
+				Include: mock.include,
+				Exclude: mock.exclude,
+			}
+			mConfigs, err := config.Load()
+			if err != nil {
+				t.Fatalf("Expected no error: Got %v", err)
+			}
+			gctls, err := mConfigs.ListGenericControllers()
+			if err != nil {
+				t.Fatalf("Expected no error while listing gctls: Got %v", err)
+			}
+			var got []string
+			for _, gctl := range gctls {
+				got = append(got, gctl.Name)
+			}
+			if !reflect.DeepEqual(got, mock.gctlNames) {
+				t.Fatalf("Expected gctls %v: Got %v", mock.gctlNames, got)
+			}
+		})
+	}
+}
+
+func TestConfigWatchDirs(t *testing.T) {
+	root, err := ioutil.TempDir("", "metac-config-test")
+	if err != nil {
+		t.Fatalf("Expected no error: Got %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	writeGCtl(t, filepath.Join(root, "team-a", "app", "gctl.yaml"), "gctl-a")
+	writeGCtl(t, filepath.Join(root, "..data", "gctl.yaml"), "gctl-hidden")
+
+	config := &Config{Path: filepath.Join(root, "team-*")}
+	dirs, err := config.WatchDirs()
+	if err != nil {
+		t.Fatalf("Expected no error: Got %v", err)
+	}
+	expected := []string{
+		root,
+		filepath.Join(root, "team-a"),
+		filepath.Join(root, "team-a", "app"),
+	}
+	if !reflect.DeepEqual(dirs, expected) {
+		t.Fatalf("Expected dirs %v: Got %v", expected, dirs)
+	}
+}
+
+func TestValidatePatterns(t *testing.T) {
+	if err := ValidatePatterns([]string{"*.yaml", "team-a/*"}); err != nil {
+		t.Fatalf("Expected no error: Got %v", err)
+	}
+	if err := ValidatePatterns([]string{"[a-"}); err == nil {
+		t.Fatalf("Expected error got none")
+	}
+}
+
+func TestConfigLoadInvalid(t *testing.T) {
+	root, err := ioutil.TempDir("", "metac-config-test")
+	if err != nil {
+		t.Fatalf("Expected no error: Got %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	writeGCtl(t, filepath.Join(root, "gctl.yaml"), "gctl")
+	for _, file := range []string{"invalid-1.yaml", "invalid-2.yaml"} {
+		err = ioutil.WriteFile(filepath.Join(root, file), []byte(`
+kind: GenericController
+spec:
+  watchh: {}
+`), 0644)
+		if err != nil {
+			t.Fatalf("Expected no error: Got %v", err)
+		}
+	}
+
+	_, err = (&Config{Path: root}).Load()
+	if err == nil {
+		t.Fatalf("Expected error got none")
+	}
+	// problems of all the files are reported
+	for _, file := range []string{"invalid-1.yaml", "invalid-2.yaml"} {
+		expected := filepath.Join(root, file) + ":4: spec.watchh: Unknown field"
+		if !strings.Contains(err.Error(), expected) {
+			t.Fatalf("Expected error %q got %v", expected, err)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	k8s "openebs.io/metac/third_party/kubernetes"
+)
+
+// ConfigMaps is the ConfigSource of the metac configs that are set
+// as the data of config maps. Config maps of a namespace that match a
+// label selector are considered.
+type ConfigMaps struct {
+	Clientset kubernetes.Interface
+	Namespace string
+	Selector  string
+
+	// Renderer renders the configs as templates. Configs are not
+	// rendered if this is nil.
+	Renderer *Renderer
+
+	// Substituter substitutes the variables referred in the configs.
+	// Variables are not substituted if this is nil.
+	Substituter *Substituter
+
+	// DuplicatePolicy decides what happens to the configs that have
+	// the same kind, namespace & name. Defaults to Fail.
+	DuplicatePolicy DuplicatePolicy
+}
+
+// NewConfigMaps returns a new instance of ConfigMaps
+func NewConfigMaps(
+	clientset kubernetes.Interface, namespace, selector string,
+) (*ConfigMaps, error) {
+	if clientset == nil {
+		return nil, errors.Errorf("Invalid config map source: Nil clientset")
+	}
+	if namespace == "" {
+		return nil, errors.Errorf("Invalid config map source: Namespace can't be empty")
+	}
+	_, err := labels.Parse(selector)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Invalid config map source: Invalid selector %q", selector)
+	}
+	return &ConfigMaps{
+		Clientset: clientset,
+		Namespace: namespace,
+		Selector:  selector,
+	}, nil
+}
+
+// String implements Stringer interface
+func (c *ConfigMaps) String() string {
+	return fmt.Sprintf("config maps in namespace %q with selector %q", c.Namespace, c.Selector)
+}
+
+// List lists the config maps having the metac configs & returns the
+// metac configs found in these config maps. This implements
+// ConfigSource interface.
+func (c *ConfigMaps) List() (MetacConfigs, error) {
+	list, err := c.Clientset.CoreV1().ConfigMaps(c.Namespace).List(
+		metav1.ListOptions{LabelSelector: c.Selector},
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Can't list %s", c)
+	}
+	return LoadConfigMaps(list.Items, c.Renderer, c.Substituter, c.DuplicatePolicy)
+}
+
+// Watch invokes the given function whenever the config maps having
+// the metac configs are added, changed or deleted. This implements
+// ConfigSource interface.
+func (c *ConfigMaps) Watch(stopCh <-chan struct{}, onChange func()) error {
+	factory := informers.NewSharedInformerFactoryWithOptions(
+		c.Clientset,
+		0,
+		informers.WithNamespace(c.Namespace),
+		informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			opts.LabelSelector = c.Selector
+		}),
+	)
+	informer := factory.Core().V1().ConfigMaps().Informer()
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { onChange() },
+		UpdateFunc: func(old, cur interface{}) { onChange() },
+		DeleteFunc: func(obj interface{}) { onChange() },
+	})
+	factory.Start(stopCh)
+	if !cache.WaitForCacheSync(stopCh, informer.HasSynced) {
+		return nil
+	}
+	glog.Infof("Watching %s for changes", c)
+	<-stopCh
+	return nil
+}
+
+// LoadConfigMaps converts the metac configs that are set as the data
+// of the given config maps to unstructured instances. Configs are
+// rendered via the given renderer & then their variables are
+// substituted via the given substituter if these are not nil.
+//
+// NOTE:
+//	Only the keys ending with .yaml or .json are loaded. Config maps
+// & their keys are loaded in sorted order. Configs that have the same
+// kind, namespace & name are handled as per the given policy.
+func LoadConfigMaps(
+	configMaps []corev1.ConfigMap,
+	renderer *Renderer,
+	substituter *Substituter,
+	policy DuplicatePolicy,
+) (MetacConfigs, error) {
+	sorted := make([]corev1.ConfigMap, len(configMaps))
+	copy(sorted, configMaps)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Namespace != sorted[j].Namespace {
+			return sorted[i].Namespace < sorted[j].Namespace
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	// configs of different keys may have the same key
+	overrides := NewOverrides(policy)
+	var validateErrs []error
+	for _, cm := range sorted {
+		keys := make([]string, 0, len(cm.Data))
+		for key := range cm.Data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if !strings.HasSuffix(key, ".yaml") && !strings.HasSuffix(key, ".json") {
+				glog.V(4).Infof(
+					"Will skip metac config %s of config map %s/%s: Not yaml or json",
+					key, cm.Namespace, cm.Name,
+				)
+				continue
+			}
+			source := fmt.Sprintf("configmap/%s/%s/%s", cm.Namespace, cm.Name, key)
+			contents, err := renderer.Render(source, []byte(cm.Data[key]))
+			if err != nil {
+				return nil, errors.Wrapf(
+					err,
+					"Failed to render metac config %s of config map %s/%s",
+					key, cm.Namespace, cm.Name,
+				)
+			}
+			contents, err = substituter.Substitute(contents)
+			if err != nil {
+				return nil, errors.Wrapf(
+					err,
+					"Failed to substitute metac config %s of config map %s/%s",
+					key, cm.Namespace, cm.Name,
+				)
+			}
+			// problems of all the config maps are reported together
+			err = ValidateGenericControllers(source, contents)
+			if err != nil {
+				validateErrs = append(validateErrs, err)
+				continue
+			}
+			ul, err := k8s.YAMLToUnstructuredSlice(contents)
+			if err != nil {
+				return nil, errors.Wrapf(
+					err,
+					"Failed to load metac config %s of config map %s/%s",
+					key, cm.Namespace, cm.Name,
+				)
+			}
+			glog.V(4).Infof(
+				"Metac config %s of config map %s/%s loaded successfully",
+				key, cm.Namespace, cm.Name,
+			)
+			overrides.Add(source, ul)
+		}
+	}
+	if len(validateErrs) != 0 {
+		return nil, errors.Wrapf(
+			utilerrors.Flatten(utilerrors.NewAggregate(validateErrs)),
+			"Invalid metac config(s) in config maps",
+		)
+	}
+	out, err := overrides.Configs()
+	if err != nil {
+		return nil, errors.Wrapf(err, "Invalid metac config(s) in config maps")
+	}
+	return out, nil
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestLoadConfigMaps(t *testing.T) {
+	var tests = map[string]struct {
+		configMaps []corev1.ConfigMap
+		isErr      bool
+		gctlNames  []string
+	}{
+		"no config maps": {},
+		"config maps & keys are loaded in sorted order": {
+			configMaps: []corev1.ConfigMap{
+				{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "metac", Name: "cm-b"},
+					Data: map[string]string{
+						"gctl.yaml": `
+apiVersion: metac.openebs.io/v1alpha1
+kind: GenericController
+metadata:
+  name: gctl-b
+`,
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "metac", Name: "cm-a"},
+					Data: map[string]string{
+						"gctl-2.json": `{"apiVersion":"metac.openebs.io/v1alpha1","kind":"GenericController","metadata":{"name":"gctl-a2"}}`,
+						"gctl-1.yaml": `
+apiVersion: metac.openebs.io/v1alpha1
+kind: GenericController
+metadata:
+  name: gctl-a1
+`,
+					},
+				},
+			},
+			gctlNames: []string{"gctl-a1", "gctl-a2", "gctl-b"},
+		},
+		"keys other than yaml or json are skipped": {
+			configMaps: []corev1.ConfigMap{
+				{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "metac", Name: "cm"},
+					Data: map[string]string{
+						"README": "not a config",
+						"gctl.yaml": `
+apiVersion: metac.openebs.io/v1alpha1
+kind: GenericController
+metadata:
+  name: gctl
+`,
+					},
+				},
+			},
+			gctlNames: []string{"gctl"},
+		},
+		"invalid config": {
+			configMaps: []corev1.ConfigMap{
+				{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "metac", Name: "cm"},
+					Data: map[string]string{
+						"gctl.yaml": "{invalid",
+					},
+				},
+			},
+			isErr: true,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			mConfigs, err := LoadConfigMaps(mock.configMaps, nil, nil, "")
+			if mock.isErr {
+				if err == nil {
+					t.Fatalf("Expected error got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error: Got %v", err)
+			}
+			gctls, err := mConfigs.ListGenericControllers()
+			if err != nil {
+				t.Fatalf("Expected no error while listing gctls: Got %v", err)
+			}
+			if len(gctls) != len(mock.gctlNames) {
+				t.Fatalf("Expected gctl count %d: Got %d", len(mock.gctlNames), len(gctls))
+			}
+			for idx, gctl := range gctls {
+				if gctl.Name != mock.gctlNames[idx] {
+					t.Fatalf(
+						"Expected gctl %q at %d: Got %q", mock.gctlNames[idx], idx, gctl.Name,
+					)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+)
+
+// Git fetches metac configs from a sub path of a git repository at
+// a given ref
+//
+// NOTE:
+//	This shells out to the git binary. Only the tip of the ref is
+// fetched to a local checkout on every fetch.
+type Git struct {
+	// Repository is the url of the git repository
+	Repository string
+
+	// Ref is the branch, tag or commit of the repository that has
+	// the configs
+	Ref string
+
+	// SubPath is the path relative to the root of the repository
+	// that has the config files
+	SubPath string
+
+	// Dir is the local directory where the repository is checked out
+	Dir string
+
+	// Renderer renders the fetched configs as templates. Configs are
+	// not rendered if this is nil.
+	Renderer *Renderer
+
+	// Substituter substitutes the variables referred in the fetched
+	// configs. Variables are not substituted if this is nil.
+	Substituter *Substituter
+
+	// DuplicatePolicy decides what happens to the fetched configs
+	// that have the same kind, namespace & name. Defaults to Fail.
+	DuplicatePolicy DuplicatePolicy
+
+	// mutex guards the checkout & the revision of the last
+	// successful fetch
+	mutex    sync.Mutex
+	revision string
+}
+
+// NewGit returns a new instance of Git. A temporary directory is used
+// as the local checkout if the given directory is empty.
+func NewGit(repository, ref, subPath, dir string) (*Git, error) {
+	if repository == "" {
+		return nil, errors.Errorf("Invalid metac config git source: Repository can't be empty")
+	}
+	if ref == "" {
+		ref = "HEAD"
+	}
+	if filepath.IsAbs(subPath) || strings.HasPrefix(filepath.Clean(subPath), "..") {
+		return nil, errors.Errorf(
+			"Invalid metac config git source: Sub path %q must be within the repository",
+			subPath,
+		)
+	}
+	if dir == "" {
+		tmpDir, err := ioutil.TempDir(os.TempDir(), "metac-config-git")
+		if err != nil {
+			return nil, errors.Wrapf(
+				err, "Invalid metac config git source: Can't create checkout dir",
+			)
+		}
+		dir = tmpDir
+	}
+	return &Git{
+		Repository: repository,
+		Ref:        ref,
+		SubPath:    subPath,
+		Dir:        dir,
+	}, nil
+}
+
+// String implements Stringer interface
+func (g *Git) String() string {
+	return g.Repository + "@" + g.Ref + ":" + g.SubPath
+}
+
+// run runs the git command with the given args at the local checkout
+func (g *Git) run(args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", g.Dir}, args...)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		return "", errors.Wrapf(
+			err,
+			"git %s failed: %s",
+			strings.Join(args, " "),
+			strings.TrimSpace(stderr.String()),
+		)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// Fetch pulls the ref of the git repository to the local checkout &
+// loads the metac configs found at the sub path
+//
+// NOTE:
+//	Returned bool is false if the ref did not move since the last
+// successful fetch. No configs are returned in this case.
+func (g *Git) Fetch() (MetacConfigs, bool, error) {
+	glog.V(4).Infof("Will fetch metac config(s) from git %s", g)
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	err := os.MkdirAll(g.Dir, 0755)
+	if err != nil {
+		return nil, false, errors.Wrapf(
+			err, "Failed to fetch metac config(s) from git %s", g,
+		)
+	}
+	// init is a no-op for an existing checkout
+	_, err = g.run("init", "--quiet")
+	if err != nil {
+		return nil, false, errors.Wrapf(
+			err, "Failed to fetch metac config(s) from git %s", g,
+		)
+	}
+	_, err = g.run("fetch", "--quiet", "--depth=1", "--force", "--", g.Repository, g.Ref)
+	if err != nil {
+		return nil, false, errors.Wrapf(
+			err, "Failed to fetch metac config(s) from git %s", g,
+		)
+	}
+	revision, err := g.run("rev-parse", "FETCH_HEAD")
+	if err != nil {
+		return nil, false, errors.Wrapf(
+			err, "Failed to fetch metac config(s) from git %s", g,
+		)
+	}
+	if revision == g.revision {
+		glog.V(4).Infof("Metac config(s) at git %s are not modified", g)
+		return nil, false, nil
+	}
+	_, err = g.run("checkout", "--quiet", "--force", "--detach", revision)
+	if err != nil {
+		return nil, false, errors.Wrapf(
+			err, "Failed to fetch metac config(s) from git %s", g,
+		)
+	}
+
+	// config expects the path to end with a separator
+	path := filepath.Join(g.Dir, g.SubPath) + string(filepath.Separator)
+	out, err := (&Config{
+		Path:            path,
+		Renderer:        g.Renderer,
+		Substituter:     g.Substituter,
+		DuplicatePolicy: g.DuplicatePolicy,
+	}).Load()
+	if err != nil {
+		return nil, false, errors.Wrapf(
+			err, "Failed to load metac config(s) from git %s at revision %s", g, revision,
+		)
+	}
+
+	// revision is retained only if the configs were loaded; this lets
+	// an invalid revision be loaded again
+	g.revision = revision
+
+	glog.V(4).Infof("Metac config(s) fetched successfully from git %s at revision %s", g, revision)
+	return out, true, nil
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// runGit runs the git command with the given args at the given dir
+func runGit(t *testing.T, dir string, args ...string) {
+	cmd := exec.Command(
+		"git",
+		append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@metac"}, args...)...,
+	)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Expected no error from git %v: Got %v: %s", args, err, out)
+	}
+}
+
+// commitGCtl commits a GenericController config with the given name to
+// the given repository
+func commitGCtl(t *testing.T, repo, name string) {
+	err := os.MkdirAll(filepath.Join(repo, "configs"), 0755)
+	if err != nil {
+		t.Fatalf("Expected no error: Got %v", err)
+	}
+	err = ioutil.WriteFile(
+		filepath.Join(repo, "configs", "gctl.yaml"),
+		[]byte(`
+apiVersion: metac.openebs.io/v1alpha1
+kind: GenericController
+metadata:
+  name: `+name+`
+`),
+		0644,
+	)
+	if err != nil {
+		t.Fatalf("Expected no error: Got %v", err)
+	}
+	runGit(t, repo, "add", "-A")
+	runGit(t, repo, "commit", "--quiet", "-m", name)
+}
+
+func TestNewGit(t *testing.T) {
+	var tests = map[string]struct {
+		repository string
+		subPath    string
+		isErr      bool
+	}{
+		"valid source": {
+			repository: "https://git.metac/configs.git",
+			subPath:    "configs",
+		},
+		"no repository": {
+			subPath: "configs",
+			isErr:   true,
+		},
+		"absolute sub path": {
+			repository: "https://git.metac/configs.git",
+			subPath:    "/configs",
+			isErr:      true,
+		},
+		"sub path outside repository": {
+			repository: "https://git.metac/configs.git",
+			subPath:    "../configs",
+			isErr:      true,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "metac-git-test")
+			if err != nil {
+				t.Fatalf("Expected no error: Got %v", err)
+			}
+			defer os.RemoveAll(dir)
+			_, err = NewGit(mock.repository, "", mock.subPath, dir)
+			if mock.isErr && err == nil {
+				t.Fatalf("Expected error got none")
+			}
+			if !mock.isErr && err != nil {
+				t.Fatalf("Expected no error got %v", err)
+			}
+		})
+	}
+}
+
+func TestGitFetch(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("Skipping: git binary not found")
+	}
+	tmp, err := ioutil.TempDir("", "metac-git-test")
+	if err != nil {
+		t.Fatalf("Expected no error: Got %v", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	repo := filepath.Join(tmp, "repo")
+	err = os.MkdirAll(repo, 0755)
+	if err != nil {
+		t.Fatalf("Expected no error: Got %v", err)
+	}
+	runGit(t, repo, "init", "--quiet")
+	runGit(t, repo, "checkout", "--quiet", "-b", "main")
+	commitGCtl(t, repo, "gctl-1")
+
+	source, err := NewGit(repo, "main", "configs", filepath.Join(tmp, "checkout"))
+	if err != nil {
+		t.Fatalf("Expected no error: Got %v", err)
+	}
+
+	var fetchGCtl = func(isModified bool, gctlName string) {
+		mConfigs, gotModified, err := source.Fetch()
+		if err != nil {
+			t.Fatalf("Expected no error: Got %v", err)
+		}
+		if gotModified != isModified {
+			t.Fatalf("Expected modified %t got %t", isModified, gotModified)
+		}
+		if !isModified {
+			return
+		}
+		gctls, err := mConfigs.ListGenericControllers()
+		if err != nil {
+			t.Fatalf("Expected no error while listing gctls: Got %v", err)
+		}
+		if len(gctls) != 1 || gctls[0].Name != gctlName {
+			t.Fatalf("Expected gctl %q: Got %d gctls", gctlName, len(gctls))
+		}
+	}
+
+	fetchGCtl(true, "gctl-1")
+	// ref did not move
+	fetchGCtl(false, "")
+	commitGCtl(t, repo, "gctl-2")
+	fetchGCtl(true, "gctl-2")
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// SourceAnnotationKey is the annotation that is set against a loaded
+// config with the source e.g. the file this config was loaded from
+const SourceAnnotationKey = "metac.openebs.io/config-source"
+
+// SourceOf returns the source the given config was loaded from.
+// Empty string is returned if the source is not known.
+func SourceOf(obj metav1.Object) string {
+	return obj.GetAnnotations()[SourceAnnotationKey]
+}
+
+// DuplicatePolicy decides what happens when more than one metac
+// configs have the same kind, namespace & name
+type DuplicatePolicy string
+
+const (
+	// DuplicatePolicyFail fails the load of the configs & reports the
+	// sources of all the duplicates
+	DuplicatePolicyFail DuplicatePolicy = "Fail"
+
+	// DuplicatePolicyOverride lets a config override the configs
+	// loaded earlier that have the same key. Each override is logged
+	// as a warning.
+	DuplicatePolicyOverride DuplicatePolicy = "Override"
+)
+
+// ValidateDuplicatePolicy returns error if the given policy is not
+// supported
+func ValidateDuplicatePolicy(policy DuplicatePolicy) error {
+	switch policy {
+	case "", DuplicatePolicyFail, DuplicatePolicyOverride:
+		return nil
+	default:
+		return errors.Errorf("Invalid duplicate policy %q", policy)
+	}
+}
+
+// Overrides merges the metac configs loaded from multiple sources
+// e.g. files & detects the configs having the same kind, namespace &
+// name
+//
+// NOTE:
+//	Sources are expected to be added in a deterministic order e.g.
+// sorted file names. With Override policy this lets numbered drop-in
+// files like 10-base.yaml & 20-override.yaml set the precedence of
+// their configs. An overriding config takes the position of the
+// overridden config.
+type Overrides struct {
+	// Policy decides what happens to duplicates. Defaults to Fail.
+	Policy DuplicatePolicy
+
+	configs MetacConfigs
+	sources [][]string
+	index   map[string]int
+}
+
+// NewOverrides returns a new instance of Overrides
+func NewOverrides(policy DuplicatePolicy) *Overrides {
+	return &Overrides{
+		Policy: policy,
+		index:  make(map[string]int),
+	}
+}
+
+// configKey returns the key that identifies the given config
+func configKey(u unstructured.Unstructured) string {
+	if u.GetNamespace() == "" {
+		return fmt.Sprintf("%s %s", u.GetKind(), u.GetName())
+	}
+	return fmt.Sprintf("%s %s/%s", u.GetKind(), u.GetNamespace(), u.GetName())
+}
+
+// Add adds the given configs loaded from the given source
+func (o *Overrides) Add(source string, configs []unstructured.Unstructured) {
+	for _, u := range configs {
+		key := configKey(u)
+		idx, found := o.index[key]
+		if !found {
+			o.index[key] = len(o.configs)
+			o.configs = append(o.configs, u)
+			o.sources = append(o.sources, []string{source})
+			continue
+		}
+		if o.Policy == DuplicatePolicyOverride {
+			glog.Warningf(
+				"Metac config %s from %s overrides the one from %s",
+				key, source, o.sources[idx][len(o.sources[idx])-1],
+			)
+			o.configs[idx] = u
+		}
+		o.sources[idx] = append(o.sources[idx], source)
+	}
+}
+
+// Configs returns the merged configs. Each config is annotated with
+// the source it was loaded from. Error is returned if duplicates were
+// found & policy is not Override.
+func (o *Overrides) Configs() (MetacConfigs, error) {
+	var errs []error
+	for idx, u := range o.configs {
+		sources := o.sources[idx]
+		if len(sources) > 1 && o.Policy != DuplicatePolicyOverride {
+			errs = append(errs, errors.Errorf(
+				"Duplicate metac config %s: Found in %s",
+				configKey(u),
+				strings.Join(sources, ", "),
+			))
+			continue
+		}
+		source := sources[len(sources)-1]
+		glog.V(4).Infof("Metac config %s is loaded from %s", configKey(u), source)
+		anns := u.GetAnnotations()
+		if anns == nil {
+			anns = make(map[string]string)
+		}
+		anns[SourceAnnotationKey] = source
+		o.configs[idx].SetAnnotations(anns)
+	}
+	if len(errs) != 0 {
+		return nil, utilerrors.NewAggregate(errs)
+	}
+	return o.configs, nil
+}
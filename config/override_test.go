@@ -0,0 +1,152 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	k8s "openebs.io/metac/third_party/kubernetes"
+)
+
+func TestOverridesAdd(t *testing.T) {
+	base, err := k8s.YAMLToUnstructuredSlice([]byte(`
+kind: GenericController
+metadata:
+  name: gctl-1
+  namespace: metac
+spec:
+  resyncPeriodSeconds: 10
+---
+kind: GenericController
+metadata:
+  name: gctl-2
+  namespace: metac
+---
+kind: CompositeController
+metadata:
+  name: gctl-1
+`))
+	if err != nil {
+		t.Fatalf("Expected no error got %v", err)
+	}
+	override, err := k8s.YAMLToUnstructuredSlice([]byte(`
+kind: GenericController
+metadata:
+  name: gctl-1
+  namespace: metac
+spec:
+  resyncPeriodSeconds: 20
+`))
+	if err != nil {
+		t.Fatalf("Expected no error got %v", err)
+	}
+
+	overrides := NewOverrides(DuplicatePolicyFail)
+	overrides.Add("10-base.yaml", base)
+	overrides.Add("20-override.yaml", override)
+	_, err = overrides.Configs()
+	if err == nil {
+		t.Fatalf("Expected duplicate error got none")
+	}
+	expected := "Duplicate metac config GenericController metac/gctl-1: Found in 10-base.yaml, 20-override.yaml"
+	if err.Error() != expected {
+		t.Fatalf("Expected error %q got %q", expected, err.Error())
+	}
+
+	overrides = NewOverrides(DuplicatePolicyOverride)
+	overrides.Add("10-base.yaml", base)
+	overrides.Add("20-override.yaml", override)
+	configs, err := overrides.Configs()
+	if err != nil {
+		t.Fatalf("Expected no error got %v", err)
+	}
+	if len(configs) != 3 {
+		t.Fatalf("Expected 3 configs got %d", len(configs))
+	}
+	// overriding config takes the position of the overridden one
+	if configs[0].GetName() != "gctl-1" || configs[0].GetKind() != "GenericController" {
+		t.Fatalf("Expected GenericController gctl-1 at 0 got %s %s", configs[0].GetKind(), configs[0].GetName())
+	}
+	gctls, err := configs.ListGenericControllers()
+	if err != nil {
+		t.Fatalf("Expected no error got %v", err)
+	}
+	if *gctls[0].Spec.ResyncPeriodSeconds != 20 {
+		t.Fatalf("Expected resync period 20 got %d", *gctls[0].Spec.ResyncPeriodSeconds)
+	}
+	if SourceOf(gctls[0]) != "20-override.yaml" {
+		t.Fatalf("Expected source 20-override.yaml got %q", SourceOf(gctls[0]))
+	}
+	if SourceOf(gctls[1]) != "10-base.yaml" {
+		t.Fatalf("Expected source 10-base.yaml got %q", SourceOf(gctls[1]))
+	}
+}
+
+func TestConfigLoadOverrides(t *testing.T) {
+	root, err := ioutil.TempDir("", "metac-config-test")
+	if err != nil {
+		t.Fatalf("Expected no error: Got %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	files := map[string]string{
+		"10-base.yaml": `
+kind: GenericController
+metadata:
+  name: gctl
+spec:
+  resyncPeriodSeconds: 10
+`,
+		"20-override.yaml": `
+kind: GenericController
+metadata:
+  name: gctl
+spec:
+  resyncPeriodSeconds: 20
+`,
+	}
+	for name, data := range files {
+		err = ioutil.WriteFile(filepath.Join(root, name), []byte(data), 0644)
+		if err != nil {
+			t.Fatalf("Expected no error: Got %v", err)
+		}
+	}
+
+	_, err = (&Config{Path: root}).Load()
+	if err == nil || !strings.Contains(err.Error(), "Duplicate metac config GenericController gctl") {
+		t.Fatalf("Expected duplicate error: Got %v", err)
+	}
+
+	mConfigs, err := (&Config{Path: root, DuplicatePolicy: DuplicatePolicyOverride}).Load()
+	if err != nil {
+		t.Fatalf("Expected no error: Got %v", err)
+	}
+	gctls, err := mConfigs.ListGenericControllers()
+	if err != nil {
+		t.Fatalf("Expected no error: Got %v", err)
+	}
+	if len(gctls) != 1 {
+		t.Fatalf("Expected 1 gctl: Got %d", len(gctls))
+	}
+	if *gctls[0].Spec.ResyncPeriodSeconds != 20 {
+		t.Fatalf("Expected resync period 20: Got %d", *gctls[0].Spec.ResyncPeriodSeconds)
+	}
+}
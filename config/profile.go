@@ -0,0 +1,151 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/golang/glog"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+)
+
+const (
+	// ProfileLabelKey is the label that sets the profile of a config
+	ProfileLabelKey = "metac.openebs.io/profile"
+
+	// DefaultProfile is the profile of the configs that do not set
+	// any profile. This profile is enabled unless it is disabled.
+	DefaultProfile = "default"
+
+	// AllProfiles enables all the profiles when set as an enabled
+	// profile
+	AllProfiles = "*"
+
+	// profilesDir is the directory of the config path whose sub
+	// directories are the profiles of the config files found in them
+	// e.g. profiles/<profile>/config.yaml
+	profilesDir = "profiles"
+)
+
+// profileOfPath returns the profile of the config file at the given
+// path relative to the walked directory. Empty string is returned if
+// the file is not in a profile directory.
+func profileOfPath(relPath string) string {
+	parts := strings.Split(filepath.ToSlash(relPath), "/")
+	if len(parts) < 3 || parts[0] != profilesDir {
+		return ""
+	}
+	return parts[1]
+}
+
+// setProfile sets the given profile against the given configs that
+// do not set any profile
+func setProfile(configs []unstructured.Unstructured, profile string) {
+	if profile == "" {
+		return
+	}
+	for idx := range configs {
+		labels := configs[idx].GetLabels()
+		if labels[ProfileLabelKey] != "" {
+			continue
+		}
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+		labels[ProfileLabelKey] = profile
+		configs[idx].SetLabels(labels)
+	}
+}
+
+// ProfileOf returns the profile of the given controller
+func ProfileOf(gctl *v1alpha1.GenericController) string {
+	return profileOfLabels(gctl.GetLabels())
+}
+
+// profileOfLabels returns the profile set in the given labels
+func profileOfLabels(labels map[string]string) string {
+	if profile := labels[ProfileLabelKey]; profile != "" {
+		return profile
+	}
+	return DefaultProfile
+}
+
+// Profiles filters configs based on their profiles
+type Profiles struct {
+	// Enabled has the profiles whose configs are used. Configs of
+	// the default profile are used even if this is empty.
+	Enabled []string
+
+	// Disabled has the profiles whose configs are not used. This has
+	// higher priority than Enabled.
+	Disabled []string
+}
+
+// has returns true if the given profile is among the given profiles
+func has(profiles []string, profile string) bool {
+	for _, p := range profiles {
+		if p == profile || p == AllProfiles {
+			return true
+		}
+	}
+	return false
+}
+
+// IsEnabled returns true if the given profile is enabled
+func (p Profiles) IsEnabled(profile string) bool {
+	if has(p.Disabled, profile) {
+		return false
+	}
+	return profile == DefaultProfile || has(p.Enabled, profile)
+}
+
+// FilterConfigs returns the given configs whose profiles are enabled
+func (p Profiles) FilterConfigs(configs MetacConfigs) MetacConfigs {
+	var out MetacConfigs
+	for _, u := range configs {
+		profile := profileOfLabels(u.GetLabels())
+		if !p.IsEnabled(profile) {
+			glog.V(4).Infof(
+				"Will skip %s %s: Profile %q is not enabled",
+				u.GetKind(), u.GetName(), profile,
+			)
+			continue
+		}
+		out = append(out, u)
+	}
+	return out
+}
+
+// Filter returns the given controllers whose profiles are enabled
+func (p Profiles) Filter(gctls []*v1alpha1.GenericController) []*v1alpha1.GenericController {
+	var out []*v1alpha1.GenericController
+	for _, gctl := range gctls {
+		profile := ProfileOf(gctl)
+		if !p.IsEnabled(profile) {
+			glog.V(4).Infof(
+				"Will skip GenericController %s: Profile %q is not enabled",
+				gctl.Key(), profile,
+			)
+			continue
+		}
+		out = append(out, gctl)
+	}
+	return out
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+	k8s "openebs.io/metac/third_party/kubernetes"
+)
+
+func TestProfilesFilter(t *testing.T) {
+	gctls := []*v1alpha1.GenericController{
+		{ObjectMeta: metav1.ObjectMeta{Name: "core"}},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "backup",
+				Labels: map[string]string{ProfileLabelKey: "backup"},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "monitoring",
+				Labels: map[string]string{ProfileLabelKey: "monitoring"},
+			},
+		},
+	}
+	var tests = map[string]struct {
+		profiles Profiles
+		expected []string
+	}{
+		"default profile is enabled by default": {
+			expected: []string{"core"},
+		},
+		"enabled profile": {
+			profiles: Profiles{Enabled: []string{"backup"}},
+			expected: []string{"core", "backup"},
+		},
+		"all profiles are enabled": {
+			profiles: Profiles{Enabled: []string{AllProfiles}},
+			expected: []string{"core", "backup", "monitoring"},
+		},
+		"disabled profile has higher priority": {
+			profiles: Profiles{
+				Enabled:  []string{AllProfiles},
+				Disabled: []string{"monitoring"},
+			},
+			expected: []string{"core", "backup"},
+		},
+		"default profile is disabled": {
+			profiles: Profiles{
+				Enabled:  []string{"backup"},
+				Disabled: []string{DefaultProfile},
+			},
+			expected: []string{"backup"},
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			var got []string
+			for _, gctl := range mock.profiles.Filter(gctls) {
+				got = append(got, gctl.Name)
+			}
+			if !reflect.DeepEqual(got, mock.expected) {
+				t.Fatalf("Expected gctls %v got %v", mock.expected, got)
+			}
+		})
+	}
+}
+
+func TestProfilesFilterConfigs(t *testing.T) {
+	configs, err := k8s.YAMLToUnstructuredSlice([]byte(`
+kind: CompositeController
+metadata:
+  name: core
+---
+kind: DecoratorController
+metadata:
+  name: backup
+  labels:
+    metac.openebs.io/profile: backup
+`))
+	if err != nil {
+		t.Fatalf("Expected no error got %v", err)
+	}
+	var got []string
+	for _, u := range (Profiles{}).FilterConfigs(configs) {
+		got = append(got, u.GetName())
+	}
+	if !reflect.DeepEqual(got, []string{"core"}) {
+		t.Fatalf("Expected configs [core] got %v", got)
+	}
+}
+
+func TestConfigLoadProfileDirs(t *testing.T) {
+	root, err := ioutil.TempDir("", "metac-config-test")
+	if err != nil {
+		t.Fatalf("Expected no error: Got %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	writeGCtl(t, filepath.Join(root, "core.yaml"), "core")
+	writeGCtl(t, filepath.Join(root, "profiles", "backup", "backup.yaml"), "backup")
+	writeGCtl(t, filepath.Join(root, "profiles", "backup", "nested", "restore.yaml"), "restore")
+	// files directly in profiles dir do not belong to any profile
+	writeGCtl(t, filepath.Join(root, "profiles", "other.yaml"), "other")
+
+	mConfigs, err := (&Config{Path: root}).Load()
+	if err != nil {
+		t.Fatalf("Expected no error: Got %v", err)
+	}
+	gctls, err := mConfigs.ListGenericControllers()
+	if err != nil {
+		t.Fatalf("Expected no error while listing gctls: Got %v", err)
+	}
+	got := make(map[string]string)
+	for _, gctl := range gctls {
+		got[gctl.Name] = ProfileOf(gctl)
+	}
+	expected := map[string]string{
+		"core":    DefaultProfile,
+		"backup":  "backup",
+		"restore": "backup",
+		"other":   DefaultProfile,
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("Expected profiles %v got %v", expected, got)
+	}
+}
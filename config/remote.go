@@ -0,0 +1,163 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+
+	k8s "openebs.io/metac/third_party/kubernetes"
+)
+
+// defaultRemoteTimeout is the timeout of a single request to fetch
+// the metac configs from a remote endpoint
+const defaultRemoteTimeout = 30 * time.Second
+
+// Remote fetches metac configs from a HTTP(S) endpoint that serves
+// these configs as a single yaml or json bundle
+//
+// NOTE:
+//	Requests are conditional i.e. they are sent with the ETag &
+// Last-Modified values of the last successful fetch. This lets the
+// endpoint reply with 304 Not Modified when the bundle is unchanged.
+type Remote struct {
+	URL    string
+	Client *http.Client
+
+	// Renderer renders the fetched configs as templates. Configs are
+	// not rendered if this is nil.
+	Renderer *Renderer
+
+	// Substituter substitutes the variables referred in the fetched
+	// configs. Variables are not substituted if this is nil.
+	Substituter *Substituter
+
+	// DuplicatePolicy decides what happens to the fetched configs
+	// that have the same kind, namespace & name. Defaults to Fail.
+	DuplicatePolicy DuplicatePolicy
+
+	// mutex guards the validators of the last successful fetch
+	mutex        sync.Mutex
+	etag         string
+	lastModified string
+}
+
+// NewRemote returns a new instance of Remote for the given URL
+func NewRemote(rawURL string) (*Remote, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Invalid metac config url %q", rawURL)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, errors.Errorf(
+			"Invalid metac config url %q: Scheme must be http or https", rawURL,
+		)
+	}
+	return &Remote{
+		URL:    rawURL,
+		Client: &http.Client{Timeout: defaultRemoteTimeout},
+	}, nil
+}
+
+// String implements Stringer interface
+func (r *Remote) String() string {
+	return "config url " + r.URL
+}
+
+// Fetch fetches the metac configs from the remote endpoint & converts
+// them to unstructured instances
+//
+// NOTE:
+//	Returned bool is false if the configs did not change since the
+// last successful fetch. No configs are returned in this case.
+func (r *Remote) Fetch() (MetacConfigs, bool, error) {
+	glog.V(4).Infof("Will fetch metac config(s) from url %s", r.URL)
+
+	req, err := http.NewRequest(http.MethodGet, r.URL, nil)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "Failed to fetch metac config(s) from %s", r.URL)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.etag != "" {
+		req.Header.Set("If-None-Match", r.etag)
+	}
+	if r.lastModified != "" {
+		req.Header.Set("If-Modified-Since", r.lastModified)
+	}
+
+	resp, err := r.Client.Do(req)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "Failed to fetch metac config(s) from %s", r.URL)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		glog.V(4).Infof("Metac config(s) at url %s are not modified", r.URL)
+		return nil, false, nil
+	case http.StatusOK:
+	default:
+		return nil, false, errors.Errorf(
+			"Failed to fetch metac config(s) from %s: Got status %q", r.URL, resp.Status,
+		)
+	}
+
+	contents, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "Failed to read metac config(s) from %s", r.URL)
+	}
+	contents, err = r.Renderer.Render(r.URL, contents)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "Failed to render metac config(s) from %s", r.URL)
+	}
+	contents, err = r.Substituter.Substitute(contents)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "Failed to substitute metac config(s) from %s", r.URL)
+	}
+	err = ValidateGenericControllers(r.URL, contents)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "Invalid metac config(s) from %s", r.URL)
+	}
+	ul, err := k8s.YAMLToUnstructuredSlice(contents)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "Failed to load metac config(s) from %s", r.URL)
+	}
+	// documents of the bundle may have the same key
+	overrides := NewOverrides(r.DuplicatePolicy)
+	overrides.Add(r.URL, ul)
+	out, err := overrides.Configs()
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "Invalid metac config(s) from %s", r.URL)
+	}
+
+	// validators are retained only if the configs were loaded; this
+	// lets an invalid bundle be fetched again
+	r.etag = resp.Header.Get("ETag")
+	r.lastModified = resp.Header.Get("Last-Modified")
+
+	glog.V(4).Infof("Metac config(s) fetched successfully from url %s", r.URL)
+	return out, true, nil
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const remoteTestBundle = `
+apiVersion: metac.openebs.io/v1alpha1
+kind: GenericController
+metadata:
+  name: gctl
+`
+
+func TestNewRemote(t *testing.T) {
+	var tests = map[string]struct {
+		url   string
+		isErr bool
+	}{
+		"http url":        {url: "http://configs.metac/bundle.yaml"},
+		"https url":       {url: "https://configs.metac/bundle.yaml"},
+		"file url":        {url: "file:///etc/config/metac/", isErr: true},
+		"url sans scheme": {url: "configs.metac/bundle.yaml", isErr: true},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			_, err := NewRemote(mock.url)
+			if mock.isErr && err == nil {
+				t.Fatalf("Expected error got none")
+			}
+			if !mock.isErr && err != nil {
+				t.Fatalf("Expected no error got %v", err)
+			}
+		})
+	}
+}
+
+func TestRemoteFetch(t *testing.T) {
+	var requests []*http.Request
+	var status int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		if status != 0 {
+			w.WriteHeader(status)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+		w.Write([]byte(remoteTestBundle))
+	}))
+	defer srv.Close()
+
+	remote, err := NewRemote(srv.URL)
+	if err != nil {
+		t.Fatalf("Expected no error got %v", err)
+	}
+
+	// first fetch is unconditional
+	mConfigs, isModified, err := remote.Fetch()
+	if err != nil {
+		t.Fatalf("Expected no error got %v", err)
+	}
+	if !isModified {
+		t.Fatalf("Expected modified got not modified")
+	}
+	gctls, err := mConfigs.ListGenericControllers()
+	if err != nil {
+		t.Fatalf("Expected no error while listing gctls: Got %v", err)
+	}
+	if len(gctls) != 1 {
+		t.Fatalf("Expected gctl count 1: Got %d", len(gctls))
+	}
+	if got := requests[0].Header.Get("If-None-Match"); got != "" {
+		t.Fatalf("Expected no If-None-Match header got %q", got)
+	}
+
+	// next fetch is conditional
+	mConfigs, isModified, err = remote.Fetch()
+	if err != nil {
+		t.Fatalf("Expected no error got %v", err)
+	}
+	if isModified || len(mConfigs) != 0 {
+		t.Fatalf("Expected not modified got modified with %d configs", len(mConfigs))
+	}
+	if got := requests[1].Header.Get("If-Modified-Since"); got != "Mon, 02 Jan 2006 15:04:05 GMT" {
+		t.Fatalf("Expected If-Modified-Since header got %q", got)
+	}
+
+	// failed fetch is an error
+	remote.etag = ""
+	status = http.StatusInternalServerError
+	_, _, err = remote.Fetch()
+	if err == nil {
+		t.Fatalf("Expected error got none")
+	}
+}
@@ -0,0 +1,258 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// SchemaFormat is the format of the schema documents
+type SchemaFormat string
+
+const (
+	// SchemaFormatJSONSchema refers to a JSON Schema (draft-07)
+	// document. Types are set at the document's definitions.
+	SchemaFormatJSONSchema SchemaFormat = "jsonschema"
+
+	// SchemaFormatOpenAPI refers to an OpenAPI v3 document. Types are
+	// set at the document's components.schemas.
+	SchemaFormatOpenAPI SchemaFormat = "openapi"
+)
+
+// ConfigTypes returns the go types of the metac configs anchored by
+// their kinds
+func ConfigTypes() map[string]reflect.Type {
+	types := make(map[string]reflect.Type, len(configSchemas))
+	for kind, typ := range configSchemas {
+		types[kind] = typ
+	}
+	return types
+}
+
+// GenerateSchema returns the schema document of the given go types in
+// the given format. Types are anchored by the names by which these are
+// referred in the document.
+//
+// NOTE:
+//	Schemas are derived from the json tags of the go types. A type
+// having a kind field e.g. GenericController is constrained to the
+// kind that matches its name. A JSON Schema document validates any of
+// the given types.
+func GenerateSchema(
+	format SchemaFormat, types map[string]reflect.Type,
+) (map[string]interface{}, error) {
+	var refPrefix string
+	switch format {
+	case SchemaFormatJSONSchema:
+		refPrefix = "#/definitions/"
+	case SchemaFormatOpenAPI:
+		refPrefix = "#/components/schemas/"
+	default:
+		return nil, errors.Errorf("Invalid schema format %q", format)
+	}
+	if len(types) == 0 {
+		return nil, errors.Errorf("No types to generate schema")
+	}
+
+	g := &schemaGenerator{
+		refPrefix:   refPrefix,
+		definitions: make(map[string]interface{}),
+	}
+	var names []string
+	for name := range types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	roots := make(map[string]interface{}, len(types))
+	for _, name := range names {
+		roots[name] = g.rootSchemaOf(name, types[name])
+	}
+
+	if format == SchemaFormatOpenAPI {
+		schemas := g.definitions
+		for name, root := range roots {
+			schemas[name] = root
+		}
+		return map[string]interface{}{
+			"openapi": "3.0.0",
+			"info": map[string]interface{}{
+				"title":   "metac",
+				"version": "v1alpha1",
+			},
+			"paths": map[string]interface{}{},
+			"components": map[string]interface{}{
+				"schemas": schemas,
+			},
+		}, nil
+	}
+
+	doc := map[string]interface{}{
+		"$schema":     "http://json-schema.org/draft-07/schema#",
+		"definitions": g.definitions,
+	}
+	if len(names) == 1 {
+		for key, val := range roots[names[0]].(map[string]interface{}) {
+			doc[key] = val
+		}
+		return doc, nil
+	}
+	var oneOf []interface{}
+	for _, name := range names {
+		oneOf = append(oneOf, roots[name])
+	}
+	doc["oneOf"] = oneOf
+	return doc, nil
+}
+
+// schemaGenerator derives the schemas of go types. Schemas of named
+// struct types are set as definitions & are referred to from other
+// schemas.
+type schemaGenerator struct {
+	refPrefix   string
+	definitions map[string]interface{}
+}
+
+// rootSchemaOf returns the schema of the given type that is anchored
+// by the given name
+func (g *schemaGenerator) rootSchemaOf(name string, typ reflect.Type) map[string]interface{} {
+	schema := g.schemaOf(typ)
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return schema
+	}
+	fields := make(map[string]reflect.Type)
+	collectFields(typ, fields)
+	if _, found := fields["kind"]; !found {
+		return schema
+	}
+	return map[string]interface{}{
+		"allOf": []interface{}{
+			schema,
+			map[string]interface{}{
+				"required": []interface{}{"kind"},
+				"properties": map[string]interface{}{
+					"kind": map[string]interface{}{
+						"enum": []interface{}{name},
+					},
+				},
+			},
+		},
+	}
+}
+
+// definitionName returns the name by which the given named type is
+// set at definitions
+func definitionName(typ reflect.Type) string {
+	return strings.Replace(typ.PkgPath(), "/", ".", -1) + "." + typ.Name()
+}
+
+// schemaOf returns the schema of the given type
+func (g *schemaGenerator) schemaOf(typ reflect.Type) map[string]interface{} {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	switch typ {
+	case reflect.TypeOf(metav1.Time{}), reflect.TypeOf(metav1.MicroTime{}):
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case reflect.TypeOf(intstr.IntOrString{}), reflect.TypeOf(resource.Quantity{}):
+		return map[string]interface{}{
+			"anyOf": []interface{}{
+				map[string]interface{}{"type": "integer"},
+				map[string]interface{}{"type": "string"},
+			},
+			"x-kubernetes-int-or-string": true,
+		}
+	}
+	if reflect.PtrTo(typ).Implements(jsonUnmarshalerType) {
+		// these values have their own formats e.g. unstructured
+		// instances & raw extensions are arbitrary objects
+		if typ.Kind() == reflect.Struct {
+			return map[string]interface{}{
+				"type":                                 "object",
+				"x-kubernetes-preserve-unknown-fields": true,
+			}
+		}
+		return map[string]interface{}{}
+	}
+	switch typ.Kind() {
+	case reflect.Struct:
+		if typ.Name() == "" {
+			return g.structSchemaOf(typ)
+		}
+		name := definitionName(typ)
+		if _, found := g.definitions[name]; !found {
+			// set before the fields are derived to let recursive
+			// types refer to this definition
+			g.definitions[name] = map[string]interface{}{}
+			g.definitions[name] = g.structSchemaOf(typ)
+		}
+		return map[string]interface{}{"$ref": g.refPrefix + name}
+	case reflect.Map:
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": g.schemaOf(typ.Elem()),
+		}
+	case reflect.Slice, reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			// bytes are base64 encoded strings
+			return map[string]interface{}{"type": "string", "format": "byte"}
+		}
+		return map[string]interface{}{
+			"type":  "array",
+			"items": g.schemaOf(typ.Elem()),
+		}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int32, reflect.Uint32, reflect.Int8, reflect.Int16,
+		reflect.Uint8, reflect.Uint16:
+		return map[string]interface{}{"type": "integer", "format": "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	}
+	// any value is valid for interface types
+	return map[string]interface{}{}
+}
+
+// structSchemaOf returns the schema of the given struct type. Fields
+// that are not declared are not allowed.
+func (g *schemaGenerator) structSchemaOf(typ reflect.Type) map[string]interface{} {
+	fields := make(map[string]reflect.Type)
+	collectFields(typ, fields)
+	properties := make(map[string]interface{}, len(fields))
+	for name, fieldType := range fields {
+		properties[name] = g.schemaOf(fieldType)
+	}
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+)
+
+func TestGenerateSchemaJSONSchema(t *testing.T) {
+	doc, err := GenerateSchema(
+		SchemaFormatJSONSchema,
+		map[string]reflect.Type{
+			"GenericController": reflect.TypeOf(v1alpha1.GenericController{}),
+		},
+	)
+	if err != nil {
+		t.Fatalf("Expected no error got %v", err)
+	}
+	allOf, ok := doc["allOf"].([]interface{})
+	if !ok || len(allOf) != 2 {
+		t.Fatalf("Expected root allOf of 2 schemas got %v", doc["allOf"])
+	}
+	ref := allOf[0].(map[string]interface{})["$ref"]
+	expectedRef := "#/definitions/openebs.io.metac.apis.metacontroller.v1alpha1.GenericController"
+	if ref != expectedRef {
+		t.Fatalf("Expected root ref %q got %v", expectedRef, ref)
+	}
+	kind := allOf[1].(map[string]interface{})["properties"].(map[string]interface{})["kind"]
+	if !reflect.DeepEqual(kind, map[string]interface{}{"enum": []interface{}{"GenericController"}}) {
+		t.Fatalf("Expected kind constrained to GenericController got %v", kind)
+	}
+
+	defs := doc["definitions"].(map[string]interface{})
+	gctl := defs["openebs.io.metac.apis.metacontroller.v1alpha1.GenericController"].(map[string]interface{})
+	if gctl["additionalProperties"] != false {
+		t.Fatalf("Expected unknown fields to be rejected got %v", gctl["additionalProperties"])
+	}
+	props := gctl["properties"].(map[string]interface{})
+	for _, field := range []string{"apiVersion", "kind", "metadata", "spec", "status"} {
+		if _, found := props[field]; !found {
+			t.Fatalf("Expected property %q got %v", field, props)
+		}
+	}
+	spec := defs["openebs.io.metac.apis.metacontroller.v1alpha1.GenericControllerSpec"].(map[string]interface{})
+	resync := spec["properties"].(map[string]interface{})["resyncPeriodSeconds"]
+	if !reflect.DeepEqual(resync, map[string]interface{}{"type": "integer", "format": "int32"}) {
+		t.Fatalf("Expected resyncPeriodSeconds as int32 got %v", resync)
+	}
+}
+
+func TestGenerateSchemaOpenAPI(t *testing.T) {
+	doc, err := GenerateSchema(SchemaFormatOpenAPI, ConfigTypes())
+	if err != nil {
+		t.Fatalf("Expected no error got %v", err)
+	}
+	schemas := doc["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+	for kind := range ConfigTypes() {
+		if _, found := schemas[kind]; !found {
+			t.Fatalf("Expected schema of %s got none", kind)
+		}
+	}
+	status, ok := schemas["openebs.io.metac.apis.metacontroller.v1alpha1.GenericControllerStatus"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected schema of GenericControllerStatus got none")
+	}
+	if _, found := status["properties"].(map[string]interface{})["conditions"]; !found {
+		t.Fatalf("Expected conditions property got %v", status["properties"])
+	}
+}
+
+func TestGenerateSchemaInvalid(t *testing.T) {
+	_, err := GenerateSchema("xml", ConfigTypes())
+	if err == nil {
+		t.Fatalf("Expected error for invalid format got none")
+	}
+	_, err = GenerateSchema(SchemaFormatJSONSchema, nil)
+	if err == nil {
+		t.Fatalf("Expected error for no types got none")
+	}
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+
+package config
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// ConfigSource is the source of metac configs e.g. files, config maps
+// or a remote endpoint. Binaries that embed metac can implement this
+// to load the configs from their own sources e.g. a database while
+// reusing the reload of metac's controllers.
+type ConfigSource interface {
+	// List returns all the configs of this source
+	List() (MetacConfigs, error)
+
+	// Watch invokes the given function whenever the configs of this
+	// source may have changed. This blocks till the given channel is
+	// closed.
+	//
+	// NOTE:
+	//	Configs are listed again after the given function is invoked.
+	// Sources that can not find their changes can return immediately.
+	Watch(stopCh <-chan struct{}, onChange func()) error
+
+	// String describes this source
+	String() string
+}
+
+// Fetcher fetches metac configs only if these changed since they were
+// fetched last e.g. Remote & Git
+type Fetcher interface {
+	// Fetch returns the configs & true if the configs changed since
+	// these were fetched last. No configs are returned if these did
+	// not change.
+	Fetch() (MetacConfigs, bool, error)
+}
+
+// PollSource is the ConfigSource of a Fetcher whose configs are
+// fetched at a given interval
+type PollSource struct {
+	Fetcher  Fetcher
+	Interval time.Duration
+
+	// mutex guards the configs that were fetched last
+	mutex   sync.Mutex
+	configs MetacConfigs
+}
+
+// NewPollSource returns a new instance of PollSource
+func NewPollSource(fetcher Fetcher, interval time.Duration) *PollSource {
+	return &PollSource{
+		Fetcher:  fetcher,
+		Interval: interval,
+	}
+}
+
+// String implements Stringer interface
+func (p *PollSource) String() string {
+	return fmt.Sprint(p.Fetcher)
+}
+
+// fetch fetches the configs & returns true if these changed since
+// these were fetched last
+func (p *PollSource) fetch() (MetacConfigs, bool, error) {
+	configs, isModified, err := p.Fetcher.Fetch()
+	if err != nil {
+		return nil, false, err
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if isModified {
+		p.configs = configs
+	}
+	return p.configs, isModified, nil
+}
+
+// List returns the configs of the fetcher. Configs that were fetched
+// last are returned if these did not change since.
+func (p *PollSource) List() (MetacConfigs, error) {
+	configs, _, err := p.fetch()
+	return configs, err
+}
+
+// Watch fetches the configs at this source's interval & invokes the
+// given function if these changed or could not be fetched
+func (p *PollSource) Watch(stopCh <-chan struct{}, onChange func()) error {
+	glog.Infof("Polling %s for changes every %s", p, p.Interval)
+	wait.Until(func() {
+		_, isModified, err := p.fetch()
+		if err == nil && !isModified {
+			glog.V(4).Infof("%s not modified", p)
+			return
+		}
+		// failures are reported when the configs are listed
+		onChange()
+	}, p.Interval, stopCh)
+	return nil
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var (
+	_ ConfigSource = &Config{}
+	_ ConfigSource = &ConfigMaps{}
+	_ ConfigSource = &PollSource{}
+)
+
+type fakeFetcher struct {
+	configs    MetacConfigs
+	isModified bool
+	err        error
+
+	// fetches is read by the tests while the watch is in
+	// progress & hence is accessed atomically
+	fetches int32
+}
+
+func (f *fakeFetcher) Fetch() (MetacConfigs, bool, error) {
+	atomic.AddInt32(&f.fetches, 1)
+	if f.err != nil || !f.isModified {
+		return nil, false, f.err
+	}
+	return f.configs, true, nil
+}
+
+func TestPollSourceList(t *testing.T) {
+	fetcher := &fakeFetcher{
+		configs:    MetacConfigs{unstructured.Unstructured{}},
+		isModified: true,
+	}
+	source := NewPollSource(fetcher, time.Minute)
+	got, err := source.List()
+	if err != nil {
+		t.Fatalf("Expected no error got %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("Expected 1 config got %d", len(got))
+	}
+
+	// configs fetched last are listed if these did not change
+	fetcher.isModified = false
+	got, err = source.List()
+	if err != nil {
+		t.Fatalf("Expected no error got %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("Expected 1 config got %d", len(got))
+	}
+
+	fetcher.err = errors.New("test error")
+	_, err = source.List()
+	if err == nil {
+		t.Fatalf("Expected error got none")
+	}
+}
+
+func TestPollSourceWatch(t *testing.T) {
+	var tests = map[string]struct {
+		isModified bool
+		err        error
+		isChange   bool
+	}{
+		"not modified": {},
+		"modified": {
+			isModified: true,
+			isChange:   true,
+		},
+		"fetch failed": {
+			err:      errors.New("test error"),
+			isChange: true,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			fetcher := &fakeFetcher{isModified: mock.isModified, err: mock.err}
+			source := NewPollSource(fetcher, time.Hour)
+			stopCh := make(chan struct{})
+			var changes int
+			go func() {
+				defer close(stopCh)
+				for atomic.LoadInt32(&fetcher.fetches) == 0 {
+					time.Sleep(10 * time.Millisecond)
+				}
+			}()
+			err := source.Watch(stopCh, func() { changes++ })
+			if err != nil {
+				t.Fatalf("Expected no error got %v", err)
+			}
+			if (changes != 0) != mock.isChange {
+				t.Fatalf("Expected change %t got %d changes", mock.isChange, changes)
+			}
+		})
+	}
+}
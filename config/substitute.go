@@ -0,0 +1,144 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// VarClusterName refers to the name of the cluster where metac runs
+	VarClusterName = "METAC_CLUSTER_NAME"
+
+	// VarNamespace refers to the namespace where metac runs
+	VarNamespace = "METAC_NAMESPACE"
+
+	// VarPodName refers to the name of the pod where metac runs
+	VarPodName = "METAC_POD_NAME"
+)
+
+// serviceAccountNamespaceFile has the namespace of the pod when metac
+// runs in cluster
+var serviceAccountNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+// varRegex matches the variables referred in the configs i.e. ${VAR}
+// or ${VAR:-default} & the $$ escape
+var varRegex = regexp.MustCompile(`\$\$|\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// Substituter substitutes the variables referred in the configs
+// with their values
+//
+// NOTE:
+//	A variable is referred as ${VAR}. A default value may be set as
+// ${VAR:-default}. $$ is substituted with a literal $. It is an error
+// to refer a variable that is not defined & has no default value.
+type Substituter struct {
+	// Vars has the runtime values e.g. cluster name. These have
+	// higher priority than environment variables.
+	Vars map[string]string
+
+	// LookupEnv returns the value of the environment variable
+	LookupEnv func(name string) (string, bool)
+}
+
+// NewSubstituter returns a new instance of Substituter that resolves
+// the given runtime values & the environment variables of this process
+func NewSubstituter(vars map[string]string) *Substituter {
+	return &Substituter{
+		Vars:      vars,
+		LookupEnv: os.LookupEnv,
+	}
+}
+
+// lookup returns the value of the given variable
+func (s *Substituter) lookup(name string) (string, bool) {
+	if val, found := s.Vars[name]; found {
+		return val, true
+	}
+	if s.LookupEnv != nil {
+		return s.LookupEnv(name)
+	}
+	return "", false
+}
+
+// Substitute returns the given data with the variables substituted
+// with their values. Nil substituter returns the data as is.
+func (s *Substituter) Substitute(data []byte) ([]byte, error) {
+	if s == nil {
+		return data, nil
+	}
+	undefined := make(map[string]bool)
+	out := varRegex.ReplaceAllFunc(data, func(match []byte) []byte {
+		if string(match) == "$$" {
+			return []byte("$")
+		}
+		groups := varRegex.FindSubmatch(match)
+		name := string(groups[1])
+		if val, found := s.lookup(name); found {
+			return []byte(val)
+		}
+		if len(groups[2]) != 0 {
+			return groups[3]
+		}
+		undefined[name] = true
+		return match
+	})
+	if len(undefined) != 0 {
+		names := make([]string, 0, len(undefined))
+		for name := range undefined {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, errors.Errorf(
+			"Undefined variable(s) %s", strings.Join(names, ", "),
+		)
+	}
+	return out, nil
+}
+
+// RuntimeVars returns the runtime values that can be referred in the
+// configs
+//
+// NOTE:
+//	Namespace & pod name are derived from POD_NAMESPACE & POD_NAME
+// environment variables that are typically set via downward API. The
+// namespace of the service account & hostname are used otherwise.
+func RuntimeVars(clusterName string) map[string]string {
+	vars := make(map[string]string)
+	if clusterName != "" {
+		vars[VarClusterName] = clusterName
+	}
+	if ns := os.Getenv("POD_NAMESPACE"); ns != "" {
+		vars[VarNamespace] = ns
+	} else if data, err := ioutil.ReadFile(serviceAccountNamespaceFile); err == nil {
+		if ns := strings.TrimSpace(string(data)); ns != "" {
+			vars[VarNamespace] = ns
+		}
+	}
+	if pod := os.Getenv("POD_NAME"); pod != "" {
+		vars[VarPodName] = pod
+	} else if host, err := os.Hostname(); err == nil {
+		vars[VarPodName] = host
+	}
+	return vars
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"testing"
+)
+
+func TestSubstituterSubstitute(t *testing.T) {
+	var tests = map[string]struct {
+		data     string
+		expected string
+		isErr    bool
+	}{
+		"no variables": {
+			data:     "name: gctl",
+			expected: "name: gctl",
+		},
+		"runtime value": {
+			data:     "name: gctl-${METAC_CLUSTER_NAME}",
+			expected: "name: gctl-prod",
+		},
+		"runtime value has higher priority than env": {
+			data:     "namespace: ${METAC_NAMESPACE}",
+			expected: "namespace: metac",
+		},
+		"env var": {
+			data:     "url: http://${HOOK_HOST}/sync",
+			expected: "url: http://hooks.metac/sync",
+		},
+		"default value of undefined var": {
+			data:     "replicas: ${REPLICAS:-3}",
+			expected: "replicas: 3",
+		},
+		"default value of defined var": {
+			data:     "url: http://${HOOK_HOST:-localhost}/sync",
+			expected: "url: http://hooks.metac/sync",
+		},
+		"empty default value": {
+			data:     "suffix: '${SUFFIX:-}'",
+			expected: "suffix: ''",
+		},
+		"escaped dollar": {
+			data:     "script: echo $${HOME} $$1",
+			expected: "script: echo ${HOME} $1",
+		},
+		"dollar without braces": {
+			data:     "script: echo $HOME",
+			expected: "script: echo $HOME",
+		},
+		"undefined var": {
+			data:  "name: ${UNDEFINED}-${ALSO_UNDEFINED}",
+			isErr: true,
+		},
+	}
+	s := &Substituter{
+		Vars: map[string]string{
+			VarClusterName: "prod",
+			VarNamespace:   "metac",
+		},
+		LookupEnv: func(name string) (string, bool) {
+			env := map[string]string{
+				"HOOK_HOST":  "hooks.metac",
+				VarNamespace: "other",
+			}
+			val, found := env[name]
+			return val, found
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			got, err := s.Substitute([]byte(mock.data))
+			if mock.isErr {
+				if err == nil {
+					t.Fatalf("Expected error got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error got %v", err)
+			}
+			if string(got) != mock.expected {
+				t.Fatalf("Expected %q got %q", mock.expected, string(got))
+			}
+		})
+	}
+}
+
+func TestNilSubstituterSubstitute(t *testing.T) {
+	var s *Substituter
+	got, err := s.Substitute([]byte("name: ${UNDEFINED}"))
+	if err != nil {
+		t.Fatalf("Expected no error got %v", err)
+	}
+	if string(got) != "name: ${UNDEFINED}" {
+		t.Fatalf("Expected data as is got %q", string(got))
+	}
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+)
+
+// Renderer renders the configs as go templates against a set of
+// values
+//
+// NOTE:
+//	Values are referred in the configs as {{ .Values.key }}. It is an
+// error to refer a value that is not set. Optional values are referred
+// via index & default functions e.g.
+// {{ index .Values "replicas" | default 1 }}.
+type Renderer struct {
+	// Values are the values referred in the configs
+	Values map[string]interface{}
+}
+
+// NewRenderer returns a new instance of Renderer that renders the
+// configs against the values of the given yaml file
+func NewRenderer(valuesPath string) (*Renderer, error) {
+	data, err := ioutil.ReadFile(valuesPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to read config values %s", valuesPath)
+	}
+	values := make(map[string]interface{})
+	err = yaml.Unmarshal(data, &values)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to load config values %s", valuesPath)
+	}
+	return &Renderer{Values: values}, nil
+}
+
+// templateFuncs are the functions that can be used in the configs
+var templateFuncs = template.FuncMap{
+	"default": defaultValue,
+	"quote":   quote,
+	"indent":  indent,
+	"toYaml":  toYAML,
+}
+
+// Render renders the given config data against the values. The given
+// source is used to report errors.
+//
+// NOTE:
+//	Data is returned as is if this instance is nil
+func (r *Renderer) Render(source string, data []byte) ([]byte, error) {
+	if r == nil {
+		return data, nil
+	}
+	tmpl, err := template.New(source).
+		Option("missingkey=error").
+		Funcs(templateFuncs).
+		Parse(string(data))
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, map[string]interface{}{"Values": r.Values})
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// defaultValue returns the given value if it is set or else the given
+// default
+func defaultValue(def interface{}, val ...interface{}) interface{} {
+	if len(val) == 0 || val[0] == nil || val[0] == "" {
+		return def
+	}
+	return val[0]
+}
+
+// quote returns the given value as a double quoted string
+func quote(val interface{}) string {
+	return strconv.Quote(fmt.Sprint(val))
+}
+
+// indent indents every line of the given text with the given number
+// of spaces
+func indent(spaces int, text string) string {
+	pad := strings.Repeat(" ", spaces)
+	return pad + strings.Replace(text, "\n", "\n"+pad, -1)
+}
+
+// toYAML returns the given value as yaml
+func toYAML(val interface{}) (string, error) {
+	data, err := yaml.Marshal(val)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(data), "\n"), nil
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRendererRender(t *testing.T) {
+	r := &Renderer{
+		Values: map[string]interface{}{
+			"namespace": "prod",
+			"hook": map[string]interface{}{
+				"url": "http://hooks.metac/sync",
+			},
+			"labels": map[string]interface{}{
+				"app": "metac",
+			},
+		},
+	}
+	var tests = map[string]struct {
+		data     string
+		expected string
+		isErr    bool
+	}{
+		"no template": {
+			data:     "name: gctl",
+			expected: "name: gctl",
+		},
+		"value": {
+			data:     "namespace: {{ .Values.namespace }}",
+			expected: "namespace: prod",
+		},
+		"nested value": {
+			data:     "url: {{ .Values.hook.url }}",
+			expected: "url: http://hooks.metac/sync",
+		},
+		"default of missing nested value": {
+			data:     `replicas: {{ index .Values.hook "replicas" | default 3 }}`,
+			expected: "replicas: 3",
+		},
+		"quoted value": {
+			data:     "name: {{ .Values.namespace | quote }}",
+			expected: `name: "prod"`,
+		},
+		"value as yaml": {
+			data:     "labels:\n{{ .Values.labels | toYaml | indent 2 }}",
+			expected: "labels:\n  app: metac",
+		},
+		"missing value": {
+			data:  "namespace: {{ .Values.cluster }}",
+			isErr: true,
+		},
+		"invalid template": {
+			data:  "namespace: {{ .Values.namespace",
+			isErr: true,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			got, err := r.Render("test.yaml", []byte(mock.data))
+			if mock.isErr {
+				if err == nil {
+					t.Fatalf("Expected error got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error got %v", err)
+			}
+			if string(got) != mock.expected {
+				t.Fatalf("Expected %q got %q", mock.expected, string(got))
+			}
+		})
+	}
+}
+
+func TestNilRendererRender(t *testing.T) {
+	var r *Renderer
+	got, err := r.Render("test.yaml", []byte("name: {{ .Values.name }}"))
+	if err != nil {
+		t.Fatalf("Expected no error got %v", err)
+	}
+	if string(got) != "name: {{ .Values.name }}" {
+		t.Fatalf("Expected data as is got %q", string(got))
+	}
+}
+
+func TestNewRenderer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "metac-values")
+	if err != nil {
+		t.Fatalf("Expected no error got %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	valuesPath := filepath.Join(dir, "values.yaml")
+	err = ioutil.WriteFile(valuesPath, []byte("namespace: prod\n"), 0644)
+	if err != nil {
+		t.Fatalf("Expected no error got %v", err)
+	}
+	r, err := NewRenderer(valuesPath)
+	if err != nil {
+		t.Fatalf("Expected no error got %v", err)
+	}
+	if r.Values["namespace"] != "prod" {
+		t.Fatalf("Expected namespace prod got %v", r.Values["namespace"])
+	}
+	_, err = NewRenderer(filepath.Join(dir, "none.yaml"))
+	if err == nil {
+		t.Fatalf("Expected error for missing values file got none")
+	}
+}
@@ -0,0 +1,283 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+	yamlv3 "gopkg.in/yaml.v3"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+)
+
+// jsonUnmarshalerType is the type of the values that unmarshal
+// themselves e.g. metav1.Time, runtime.RawExtension, etc.
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// configSchemas are the schemas against which the configs of the
+// respective kinds are validated
+var configSchemas = map[string]reflect.Type{
+	"GenericController":   reflect.TypeOf(v1alpha1.GenericController{}),
+	"CompositeController": reflect.TypeOf(v1alpha1.CompositeController{}),
+	"DecoratorController": reflect.TypeOf(v1alpha1.DecoratorController{}),
+}
+
+// ValidateGenericControllers validates the GenericController documents
+// found in the given yaml or json data against v1alpha1 schema. This
+// rejects unknown fields as well as fields of a wrong type.
+// CompositeController & DecoratorController documents are validated
+// as well.
+//
+// NOTE:
+//	Problems of all the documents are returned as a single aggregated
+// error. Each problem refers to the given source, the line & the path
+// of the field.
+func ValidateGenericControllers(source string, data []byte) error {
+	var errs []error
+	decoder := yamlv3.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc yamlv3.Node
+		err := decoder.Decode(&doc)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// rest of the documents can't be decoded
+			errs = append(errs, errors.Errorf("%s: %v", source, err))
+			break
+		}
+		node := resolveNode(&doc)
+		if node == nil {
+			continue
+		}
+		schema, found := configSchemas[kindOfNode(node)]
+		if !found {
+			continue
+		}
+		v := &schemaValidator{source: source}
+		v.validate("", node, schema)
+		errs = append(errs, v.errs...)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// resolveNode returns the content of the given document or alias node
+func resolveNode(node *yamlv3.Node) *yamlv3.Node {
+	for node != nil {
+		switch node.Kind {
+		case yamlv3.DocumentNode:
+			if len(node.Content) == 0 {
+				return nil
+			}
+			node = node.Content[0]
+		case yamlv3.AliasNode:
+			node = node.Alias
+		default:
+			return node
+		}
+	}
+	return nil
+}
+
+// kindOfNode returns the kind of the object represented by the given
+// node
+func kindOfNode(node *yamlv3.Node) string {
+	if node.Kind != yamlv3.MappingNode {
+		return ""
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == "kind" {
+			return node.Content[i+1].Value
+		}
+	}
+	return ""
+}
+
+// schemaValidator validates yaml nodes against go types & collects
+// the problems found
+type schemaValidator struct {
+	source string
+	errs   []error
+}
+
+// addError records the problem of the field at the given path
+func (v *schemaValidator) addError(node *yamlv3.Node, path, format string, args ...interface{}) {
+	if path == "" {
+		path = "."
+	}
+	v.errs = append(v.errs, errors.Errorf(
+		"%s:%d: %s: %s", v.source, node.Line, path, fmt.Sprintf(format, args...),
+	))
+}
+
+// validate validates the given node of the field at the given path
+// against the given type
+func (v *schemaValidator) validate(path string, node *yamlv3.Node, typ reflect.Type) {
+	node = resolveNode(node)
+	if node == nil {
+		return
+	}
+	if node.Kind == yamlv3.ScalarNode && node.Tag == "!!null" {
+		// null is the zero value of any type
+		return
+	}
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if reflect.PtrTo(typ).Implements(jsonUnmarshalerType) {
+		// these values have their own formats
+		return
+	}
+	switch typ.Kind() {
+	case reflect.Struct:
+		if node.Kind != yamlv3.MappingNode {
+			v.addError(node, path, "Expected object got %s", describeNode(node))
+			return
+		}
+		fields := make(map[string]reflect.Type)
+		collectFields(typ, fields)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, val := node.Content[i], node.Content[i+1]
+			fieldPath := joinFieldPath(path, key.Value)
+			fieldType, found := fields[key.Value]
+			if !found {
+				v.addError(key, fieldPath, "Unknown field")
+				continue
+			}
+			v.validate(fieldPath, val, fieldType)
+		}
+	case reflect.Map:
+		if node.Kind != yamlv3.MappingNode {
+			v.addError(node, path, "Expected object got %s", describeNode(node))
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, val := node.Content[i], node.Content[i+1]
+			v.validate(joinFieldPath(path, key.Value), val, typ.Elem())
+		}
+	case reflect.Slice:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			// bytes are base64 encoded strings
+			v.validateScalar(path, node, "string", "!!str")
+			return
+		}
+		if node.Kind != yamlv3.SequenceNode {
+			v.addError(node, path, "Expected list got %s", describeNode(node))
+			return
+		}
+		for idx, item := range node.Content {
+			v.validate(fmt.Sprintf("%s[%d]", path, idx), item, typ.Elem())
+		}
+	case reflect.String:
+		v.validateScalar(path, node, "string", "!!str")
+	case reflect.Bool:
+		v.validateScalar(path, node, "bool", "!!bool")
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.validateScalar(path, node, "integer", "!!int")
+	case reflect.Float32, reflect.Float64:
+		v.validateScalar(path, node, "number", "!!int", "!!float")
+	}
+	// any value is valid for interface types
+}
+
+// validateScalar validates the given node is a scalar having any of
+// the given yaml tags
+func (v *schemaValidator) validateScalar(
+	path string, node *yamlv3.Node, expected string, tags ...string,
+) {
+	if node.Kind == yamlv3.ScalarNode {
+		for _, tag := range tags {
+			if node.Tag == tag {
+				return
+			}
+		}
+	}
+	v.addError(node, path, "Expected %s got %s", expected, describeNode(node))
+}
+
+// describeNode returns the type of the given node as a string
+func describeNode(node *yamlv3.Node) string {
+	switch node.Kind {
+	case yamlv3.MappingNode:
+		return "object"
+	case yamlv3.SequenceNode:
+		return "list"
+	default:
+		switch node.Tag {
+		case "!!str":
+			return fmt.Sprintf("string %q", node.Value)
+		case "!!int":
+			return fmt.Sprintf("integer %s", node.Value)
+		case "!!float":
+			return fmt.Sprintf("number %s", node.Value)
+		case "!!bool":
+			return fmt.Sprintf("bool %s", node.Value)
+		default:
+			return fmt.Sprintf("%q", node.Value)
+		}
+	}
+}
+
+// collectFields sets the json names of the fields of the given struct
+// type against the given fields. Fields of inlined structs are set as
+// fields of the given struct.
+func collectFields(typ reflect.Type, fields map[string]reflect.Type) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		isInline := strings.Contains(tag, ",inline") || (field.Anonymous && name == "")
+		if isInline {
+			fieldType := field.Type
+			for fieldType.Kind() == reflect.Ptr {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() == reflect.Struct {
+				collectFields(fieldType, fields)
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			// unexported fields are not unmarshaled
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field.Type
+	}
+}
+
+// joinFieldPath returns the path of the given field of the object at
+// the given path
+func joinFieldPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateGenericControllers(t *testing.T) {
+	var tests = map[string]struct {
+		data   string
+		errors []string
+	}{
+		"valid config": {
+			data: `
+apiVersion: metac.openebs.io/v1alpha1
+kind: GenericController
+metadata:
+  name: gctl
+  labels:
+    app: metac
+spec:
+  resyncPeriodSeconds: 30
+  watch:
+    apiVersion: v1
+    resource: namespaces
+  attachments:
+  - apiVersion: v1
+    resource: configmaps
+    updateStrategy:
+      method: InPlace
+  hooks:
+    sync:
+      webhook:
+        url: http://hooks.metac/sync
+`,
+		},
+		"parameters can be any object": {
+			data: `
+kind: GenericController
+metadata:
+  name: gctl
+spec:
+  watch:
+    apiVersion: v1
+    resource: namespaces
+  parameters:
+    replicas: 3
+    labels:
+      app: test
+    tiers:
+    - web
+`,
+		},
+		"other kinds are not validated": {
+			data: `
+kind: Test
+stuff: 1
+`,
+		},
+		"problems of all documents are reported": {
+			data: `
+kind: GenericController
+metadata:
+  name: gctl-1
+spec:
+  watch:
+    apiVersion: v1
+    resources: namespaces
+---
+kind: GenericController
+metadata:
+  name: gctl-2
+spec:
+  resyncPeriodSeconds: thirty
+  attachments:
+    apiVersion: v1
+`,
+			errors: []string{
+				"test.yaml:8: spec.watch.resources: Unknown field",
+				`test.yaml:14: spec.resyncPeriodSeconds: Expected integer got string "thirty"`,
+				"test.yaml:16: spec.attachments: Expected list got object",
+			},
+		},
+		"nested unknown field": {
+			data: `
+kind: GenericController
+spec:
+  attachments:
+  - apiVersion: v1
+    resource: configmaps
+    updateStrategy:
+      methd: InPlace
+`,
+			errors: []string{
+				"test.yaml:8: spec.attachments[0].updateStrategy.methd: Unknown field",
+			},
+		},
+		"composite & decorator controllers are validated": {
+			data: `
+kind: CompositeController
+spec:
+  parentResource:
+    apiVersion: v1
+    resources: namespaces
+---
+kind: DecoratorController
+spec:
+  resyncPeriodSeconds: thirty
+`,
+			errors: []string{
+				"test.yaml:6: spec.parentResource.resources: Unknown field",
+				`test.yaml:10: spec.resyncPeriodSeconds: Expected integer got string "thirty"`,
+			},
+		},
+		"invalid yaml": {
+			data: `
+kind: GenericController
+spec: [
+`,
+			errors: []string{"test.yaml: yaml: line"},
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			err := ValidateGenericControllers("test.yaml", []byte(mock.data))
+			if len(mock.errors) == 0 {
+				if err != nil {
+					t.Fatalf("Expected no error got %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Expected errors %v got none", mock.errors)
+			}
+			for _, expected := range mock.errors {
+				if !strings.Contains(err.Error(), expected) {
+					t.Fatalf("Expected error %q got %v", expected, err)
+				}
+			}
+		})
+	}
+}
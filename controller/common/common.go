@@ -152,8 +152,12 @@ func (m AnyUnstructRegistry) FindByGroupKindName(
 
 // relativeName returns the name of the attachment relative to the provided
 // reference.
+//
+// NOTE:
+//	Namespace is part of the name if the attachment does not belong to
+// the reference's namespace
 func relativeName(ref metav1.Object, obj *unstructured.Unstructured) string {
-	if ref.GetNamespace() == "" && obj.GetNamespace() != "" {
+	if obj.GetNamespace() != "" && obj.GetNamespace() != ref.GetNamespace() {
 		return fmt.Sprintf("%s/%s", obj.GetNamespace(), obj.GetName())
 	}
 	return obj.GetName()
@@ -348,8 +352,34 @@ func (m ResourceInformerRegistryByVR) Get(
 	return m[makeKeyFromAPIVersionResource(apiVersion, resource)]
 }
 
+// SetInNamespace registers the given informer object that is scoped
+// to the given namespace based on the given version and resource
+func (m ResourceInformerRegistryByVR) SetInNamespace(
+	apiVersion, resource, namespace string,
+	informer *dynamicinformer.ResourceInformer,
+) {
+
+	m[makeKeyFromAPIVersionResourceNamespace(apiVersion, resource, namespace)] = informer
+}
+
+// GetInNamespace returns the informer instance that is scoped to the
+// given namespace from the registrar based on the given version and
+// resource
+func (m ResourceInformerRegistryByVR) GetInNamespace(
+	apiVersion, resource, namespace string,
+) *dynamicinformer.ResourceInformer {
+
+	return m[makeKeyFromAPIVersionResourceNamespace(apiVersion, resource, namespace)]
+}
+
 // makeKeyFromAPIVersionResource returns the string format of given
 // apiVersion and resource
 func makeKeyFromAPIVersionResource(apiVersion, resource string) string {
 	return fmt.Sprintf("%s.%s", resource, apiVersion)
 }
+
+// makeKeyFromAPIVersionResourceNamespace returns the string format of
+// given apiVersion, resource and namespace
+func makeKeyFromAPIVersionResourceNamespace(apiVersion, resource, namespace string) string {
+	return fmt.Sprintf("%s/%s", makeKeyFromAPIVersionResource(apiVersion, resource), namespace)
+}
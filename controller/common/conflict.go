@@ -0,0 +1,149 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+	dynamicapply "openebs.io/metac/dynamic/apply"
+)
+
+const (
+	// EventReasonFieldConflict is the reason of the event raised
+	// when the desired fields of an attachment were changed by
+	// someone else
+	EventReasonFieldConflict string = "FieldConflict"
+)
+
+// ConflictPolicy returns the policy to be followed when the desired
+// fields of the attachments handled by this executor were changed by
+// someone else
+//
+// NOTE:
+//	Empty policy implies conflicts are not looked for
+func (e AttachmentResourcesExecutor) ConflictPolicy() v1alpha1.GenericControllerAttachmentConflictPolicy {
+	if e.GetConflictPolicyByGK == nil {
+		return ""
+	}
+	return e.GetConflictPolicyByGK(
+		e.DynamicResourceClient.Group, e.DynamicResourceClient.Kind,
+	)
+}
+
+// isForceApply returns true if server side apply should take over
+// the fields that are managed by other field managers
+func (e AttachmentResourcesExecutor) isForceApply() bool {
+	switch e.ConflictPolicy() {
+	case "":
+		return e.IsForceApplyConflicts
+	case v1alpha1.AttachmentConflictPolicyForce:
+		return true
+	default:
+		return false
+	}
+}
+
+// conflictsFn returns the function that finds the conflicting fields
+// during a 3-way merge. It returns nil if conflicts should not be
+// looked for.
+func (e AttachmentResourcesExecutor) conflictsFn() func(
+	observed, desired *unstructured.Unstructured, lastApplied map[string]interface{},
+) ([][]string, error) {
+	if e.ConflictPolicy() == "" {
+		return nil
+	}
+	if e.IsManagedFieldsApply() {
+		return func(
+			observed, desired *unstructured.Unstructured, lastApplied map[string]interface{},
+		) ([][]string, error) {
+			return e.findOwnedConflicts(observed, desired)
+		}
+	}
+	return func(
+		observed, desired *unstructured.Unstructured, lastApplied map[string]interface{},
+	) ([][]string, error) {
+		return dynamicapply.ComputeConflicts(
+			observed.UnstructuredContent(), lastApplied, desired.UnstructuredContent(),
+		), nil
+	}
+}
+
+// findOwnedConflicts returns the paths of the desired fields that are
+// managed by field managers other than this controller's & differ from
+// their observed values
+func (e AttachmentResourcesExecutor) findOwnedConflicts(
+	observed, desired *unstructured.Unstructured,
+) ([][]string, error) {
+	owned, err := dynamicapply.GetOthersManagedFieldsState(observed, e.FieldManager)
+	if err != nil {
+		return nil, err
+	}
+	return dynamicapply.ComputeOwnedConflicts(
+		observed.UnstructuredContent(), owned, desired.UnstructuredContent(),
+	), nil
+}
+
+// handleConflicts acts on the given conflicting fields of the given
+// attachment based on the conflict policy. It returns error if the
+// attachment should not be updated.
+func (e AttachmentResourcesExecutor) handleConflicts(
+	obj *unstructured.Unstructured, conflicts [][]string,
+) error {
+	if len(conflicts) == 0 {
+		return nil
+	}
+	fields := make([]string, 0, len(conflicts))
+	for _, path := range conflicts {
+		fields = append(fields, strings.Join(path, "."))
+	}
+	desc := strings.Join(fields, ", ")
+	switch e.ConflictPolicy() {
+	case v1alpha1.AttachmentConflictPolicyForce:
+		glog.V(3).Infof(
+			"%s: Will overwrite conflicting fields of %s: %s", e, DescObjectAsKey(obj), desc,
+		)
+		e.recordEvent(
+			corev1.EventTypeNormal,
+			EventReasonFieldConflict,
+			"Overwriting fields of %s changed by someone else: %s",
+			DescObjectAsKey(obj),
+			desc,
+		)
+	case v1alpha1.AttachmentConflictPolicyYield:
+		glog.V(4).Infof(
+			"%s: Won't update conflicting fields of %s: %s", e, DescObjectAsKey(obj), desc,
+		)
+	case v1alpha1.AttachmentConflictPolicyFail:
+		e.recordEvent(
+			corev1.EventTypeWarning,
+			EventReasonFieldConflict,
+			"Can't update %s: Fields changed by someone else: %s",
+			DescObjectAsKey(obj),
+			desc,
+		)
+		return errors.Errorf(
+			"%s: Can't update %s: Conflicting fields: %s", e, DescObjectAsKey(obj), desc,
+		)
+	}
+	return nil
+}
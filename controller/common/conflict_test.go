@@ -0,0 +1,144 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/record"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+	dynamicclientset "openebs.io/metac/dynamic/clientset"
+	dynamicdiscovery "openebs.io/metac/dynamic/discovery"
+	"openebs.io/metac/third_party/kubernetes"
+)
+
+func TestAttachmentResourcesExecutorUpdateWithConflicts(t *testing.T) {
+	var tests = map[string]struct {
+		policy       v1alpha1.GenericControllerAttachmentConflictPolicy
+		isErr        bool
+		isEvent      bool
+		wantReplicas interface{}
+	}{
+		"no policy": {
+			wantReplicas: int64(1),
+		},
+		"force": {
+			policy:       v1alpha1.AttachmentConflictPolicyForce,
+			isEvent:      true,
+			wantReplicas: int64(1),
+		},
+		"yield": {
+			policy:       v1alpha1.AttachmentConflictPolicyYield,
+			wantReplicas: int64(3),
+		},
+		"fail": {
+			policy:  v1alpha1.AttachmentConflictPolicyFail,
+			isErr:   true,
+			isEvent: true,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			op := &RecordUpdateResourceOperation{}
+			recorder := record.NewFakeRecorder(1)
+			executor := &AttachmentResourcesExecutor{
+				AttachmentExecuteBase: AttachmentExecuteBase{
+					GetChildUpdateStrategyByGK: func(group, kind string) v1alpha1.ChildUpdateMethod {
+						return v1alpha1.ChildUpdateInPlace
+					},
+					IsPatchByGK: func(group, kind string) bool {
+						return false
+					},
+					GetConflictPolicyByGK: func(group, kind string) v1alpha1.GenericControllerAttachmentConflictPolicy {
+						return mock.policy
+					},
+					Watch: &unstructured.Unstructured{
+						Object: map[string]interface{}{
+							"metadata": map[string]interface{}{
+								"name": "watch",
+								"uid":  "test-watch-uid",
+							},
+						},
+					},
+					UpdateAny:     kubernetes.BoolPtr(true),
+					EventRecorder: recorder,
+				},
+				DynamicResourceClient: &dynamicclientset.ResourceClient{
+					ResourceInterface: op,
+					APIResource:       &dynamicdiscovery.APIResource{},
+				},
+			}
+			// replicas were last applied as 1 & were later changed
+			// to 3 by someone else
+			observed := &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"metadata": map[string]interface{}{
+						"name": "attachment",
+						"annotations": map[string]interface{}{
+							"test-watch-uid" + lastAppliedAnnotationKeySuffix: `{"spec":{"replicas":1,"image":"v1"}}`,
+						},
+					},
+					"spec": map[string]interface{}{
+						"replicas": int64(3),
+						"image":    "v1",
+					},
+				},
+			}
+			desired := &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"metadata": map[string]interface{}{
+						"name": "attachment",
+					},
+					"spec": map[string]interface{}{
+						"replicas": int64(1),
+						"image":    "v2",
+					},
+				},
+			}
+			got, err := executor.Update(observed, desired)
+			if mock.isErr {
+				if err == nil {
+					t.Fatalf("Expected error got none")
+				}
+				if len(op.updated) != 0 {
+					t.Fatalf("Expected no update got %d", len(op.updated))
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Expected no error got %v", err)
+				}
+				if !got || len(op.updated) != 1 {
+					t.Fatalf("Expected 1 update got %t %d", got, len(op.updated))
+				}
+				spec, _, _ := unstructured.NestedMap(op.updated[0].Object, "spec")
+				if spec["replicas"] != mock.wantReplicas {
+					t.Fatalf("Expected replicas %v got %v", mock.wantReplicas, spec["replicas"])
+				}
+				if spec["image"] != "v2" {
+					t.Fatalf("Expected image v2 got %v", spec["image"])
+				}
+			}
+			if isEvent := len(recorder.Events) == 1; isEvent != mock.isEvent {
+				t.Fatalf("Expected event %t got %t", mock.isEvent, isEvent)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+)
+
+// DefaultDrainTimeout is the maximum duration for which a controller
+// waits for its in-flight syncs on shutdown by default
+//
+// NOTE:
+//	This is less than the default termination grace period of pods
+// to let the pending events be flushed as well
+const DefaultDrainTimeout = 20 * time.Second
+
+// drainTimeout is the maximum duration for which the controllers wait
+// for their in-flight syncs on shutdown; this is accessed atomically
+var drainTimeout = int64(DefaultDrainTimeout)
+
+// drainDeadline is the time in unix nanoseconds by which the shutdown
+// should complete; this is zero till the shutdown starts & is accessed
+// atomically
+var drainDeadline int64
+
+// SetDrainTimeout sets the maximum duration for which the controllers
+// wait for their in-flight syncs on shutdown. A timeout of 0 waits
+// till these syncs complete.
+func SetDrainTimeout(timeout time.Duration) error {
+	if timeout < 0 {
+		return errors.Errorf("Invalid drain timeout: Must be >= 0: Got %s", timeout)
+	}
+	atomic.StoreInt64(&drainTimeout, int64(timeout))
+	atomic.StoreInt64(&drainDeadline, 0)
+	return nil
+}
+
+// StartDrain marks the start of the shutdown. In-flight syncs & then
+// the pending events are drained within a single drain timeout that
+// starts now instead of each of these waiting for the drain timeout.
+func StartDrain() {
+	timeout := GetDrainTimeout()
+	if timeout == 0 {
+		return
+	}
+	atomic.StoreInt64(&drainDeadline, time.Now().Add(timeout).UnixNano())
+}
+
+// GetDrainTimeLeft returns the duration that is left to drain. This is
+// the drain timeout if the shutdown has not started. This returns false
+// if there is no drain timeout i.e. drain waits till completion.
+//
+// NOTE:
+//	Controllers that are stopped without a shutdown e.g. on a config
+// reload wait for their in-flight syncs for the whole drain timeout
+func GetDrainTimeLeft() (time.Duration, bool) {
+	timeout := GetDrainTimeout()
+	if timeout == 0 {
+		return 0, false
+	}
+	deadline := atomic.LoadInt64(&drainDeadline)
+	if deadline == 0 {
+		return timeout, true
+	}
+	left := time.Until(time.Unix(0, deadline))
+	if left < 0 {
+		left = 0
+	}
+	return left, true
+}
+
+// GetDrainTimeout returns the maximum duration for which the
+// controllers wait for their in-flight syncs on shutdown
+func GetDrainTimeout() time.Duration {
+	return time.Duration(atomic.LoadInt64(&drainTimeout))
+}
+
+// IsStopped returns true if the given stop channel is closed
+//
+// NOTE:
+//	Workers check this before they sync the next queued item. Items
+// that are queued once a controller is stopped are not synced; these
+// get synced after the next start.
+func IsStopped(stopCh <-chan struct{}) bool {
+	select {
+	case <-stopCh:
+		return true
+	default:
+		return false
+	}
+}
+
+// WaitForDrain waits till the given done channel is closed i.e. the
+// in-flight syncs of the given controller complete or till the drain
+// timeout elapses. This returns false if the timeout elapsed first.
+func WaitForDrain(name string, doneCh <-chan struct{}) bool {
+	left, isLimited := GetDrainTimeLeft()
+	if !isLimited {
+		<-doneCh
+		return true
+	}
+	select {
+	case <-doneCh:
+		return true
+	default:
+	}
+	timer := time.NewTimer(left)
+	defer timer.Stop()
+
+	select {
+	case <-doneCh:
+		return true
+	case <-timer.C:
+		glog.Warningf(
+			"%s: In-flight syncs did not complete within drain timeout %s: Stopping anyway",
+			name, GetDrainTimeout(),
+		)
+		return false
+	}
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSetDrainTimeout(t *testing.T) {
+	defer SetDrainTimeout(DefaultDrainTimeout)
+
+	var tests = map[string]struct {
+		timeout time.Duration
+		isError bool
+	}{
+		"positive timeout": {
+			timeout: 5 * time.Second,
+		},
+		"zero timeout": {
+			timeout: 0,
+		},
+		"negative timeout": {
+			timeout: -1 * time.Second,
+			isError: true,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			SetDrainTimeout(DefaultDrainTimeout)
+			err := SetDrainTimeout(mock.timeout)
+			if mock.isError && err == nil {
+				t.Fatalf("Expected error got none")
+			}
+			if !mock.isError && err != nil {
+				t.Fatalf("Expected no error got %v", err)
+			}
+			want := mock.timeout
+			if mock.isError {
+				want = DefaultDrainTimeout
+			}
+			if got := GetDrainTimeout(); got != want {
+				t.Fatalf("Expected timeout %s got %s", want, got)
+			}
+		})
+	}
+}
+
+func TestWaitForDrain(t *testing.T) {
+	defer SetDrainTimeout(DefaultDrainTimeout)
+
+	SetDrainTimeout(10 * time.Millisecond)
+	doneCh := make(chan struct{})
+	if WaitForDrain("test", doneCh) {
+		t.Fatalf("Expected drain to time out")
+	}
+	close(doneCh)
+	if !WaitForDrain("test", doneCh) {
+		t.Fatalf("Expected drain to complete")
+	}
+	// no timeout waits till done
+	SetDrainTimeout(0)
+	if !WaitForDrain("test", doneCh) {
+		t.Fatalf("Expected drain to complete")
+	}
+}
+
+func TestStartDrain(t *testing.T) {
+	defer SetDrainTimeout(DefaultDrainTimeout)
+
+	SetDrainTimeout(50 * time.Millisecond)
+	left, isLimited := GetDrainTimeLeft()
+	if !isLimited || left != 50*time.Millisecond {
+		t.Fatalf("Expected whole drain timeout before shutdown got %s", left)
+	}
+
+	// in-flight syncs & pending events share the timeout
+	StartDrain()
+	doneCh := make(chan struct{})
+	if WaitForDrain("test", doneCh) {
+		t.Fatalf("Expected drain to time out")
+	}
+	start := time.Now()
+	if WaitForDrain("test", doneCh) {
+		t.Fatalf("Expected drain to time out")
+	}
+	if elapsed := time.Since(start); elapsed > 25*time.Millisecond {
+		t.Fatalf("Expected no wait once drain timeout elapsed got %s", elapsed)
+	}
+	if left, _ := GetDrainTimeLeft(); left != 0 {
+		t.Fatalf("Expected no time left got %s", left)
+	}
+	close(doneCh)
+	if !WaitForDrain("test", doneCh) {
+		t.Fatalf("Expected drain to complete")
+	}
+
+	// no timeout waits till done
+	SetDrainTimeout(0)
+	StartDrain()
+	if _, isLimited := GetDrainTimeLeft(); isLimited {
+		t.Fatalf("Expected no drain timeout")
+	}
+}
+
+func TestIsStopped(t *testing.T) {
+	stopCh := make(chan struct{})
+	if IsStopped(stopCh) {
+		t.Fatalf("Expected not stopped")
+	}
+	close(stopCh)
+	if !IsStopped(stopCh) {
+		t.Fatalf("Expected stopped")
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/golang/glog"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilcache "k8s.io/apimachinery/pkg/util/cache"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+// dryRunCacheTTL is the duration for which the result of a dry run
+// is cached
+const dryRunCacheTTL = 15 * time.Minute
+
+// DryRunCache caches the results of dry run updates of attachments.
+// A result is valid as long as the observed attachment i.e. its
+// resource version & the merged state sent as the dry run are same.
+type DryRunCache struct {
+	cache *utilcache.LRUExpireCache
+}
+
+// NewDryRunCache returns a new instance of DryRunCache that holds
+// at most the given number of results
+func NewDryRunCache(size int) *DryRunCache {
+	return &DryRunCache{
+		cache: utilcache.NewLRUExpireCache(size),
+	}
+}
+
+// get returns the cached result of the given key
+func (c *DryRunCache) get(key string) (isNoop bool, found bool) {
+	if c == nil || key == "" {
+		return false, false
+	}
+	val, found := c.cache.Get(key)
+	if !found {
+		return false, false
+	}
+	isNoop, found = val.(bool)
+	return isNoop, found
+}
+
+// add caches the given result against the given key
+func (c *DryRunCache) add(key string, isNoop bool) {
+	if c == nil || key == "" {
+		return
+	}
+	c.cache.Add(key, isNoop, dryRunCacheTTL)
+}
+
+// makeDryRunCacheKey returns the key used to cache the result of a
+// dry run of the given merged state against the given observed
+// attachment. It returns empty key if merged state can't be hashed.
+func makeDryRunCacheKey(observedObj, mergedObj *unstructured.Unstructured) string {
+	data, err := json.Marshal(mergedObj.UnstructuredContent())
+	if err != nil {
+		return ""
+	}
+	hash := sha256.Sum256(data)
+	return string(observedObj.GetUID()) + "/" +
+		observedObj.GetResourceVersion() + "/" +
+		hex.EncodeToString(hash[:])
+}
+
+// IsDryRunCompare returns true if the attachments handled by this
+// executor should be compared with their observed state via a dry
+// run update before being updated
+func (e AttachmentResourcesExecutor) IsDryRunCompare() bool {
+	if e.IsDryRunCompareByGK == nil {
+		return false
+	}
+	return e.IsDryRunCompareByGK(
+		e.DynamicResourceClient.Group, e.DynamicResourceClient.Kind,
+	)
+}
+
+// isDryRunNoop returns true if updating the given observed
+// attachment with the given merged state changes nothing once the
+// merged state is defaulted by the API server
+//
+// NOTE:
+//	This returns false if the dry run fails. The actual update is
+// expected to surface the error if any.
+func (e *AttachmentResourcesExecutor) isDryRunNoop(
+	ns string, observedObj, mergedObj *unstructured.Unstructured,
+) bool {
+	key := makeDryRunCacheKey(observedObj, mergedObj)
+	if isNoop, found := e.DryRunCache.get(key); found {
+		return isNoop
+	}
+	opts := e.updateOptions()
+	opts.DryRun = []string{metav1.DryRunAll}
+	defaulted, err := e.DynamicResourceClient.Namespace(ns).Update(mergedObj, opts)
+	if err != nil {
+		glog.V(4).Infof(
+			"%s: Dry run update of %s failed: %v", e, DescObjectAsKey(mergedObj), err,
+		)
+		return false
+	}
+	isNoop := isApplyNoop(observedObj, defaulted)
+	e.DryRunCache.add(key, isNoop)
+	return isNoop
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+	dynamicclientset "openebs.io/metac/dynamic/clientset"
+	dynamicdiscovery "openebs.io/metac/dynamic/discovery"
+	"openebs.io/metac/third_party/kubernetes"
+)
+
+// DefaultingResourceOperation normalizes spec.cpu similar to the
+// API server's defaulting of quantities
+type DefaultingResourceOperation struct {
+	RecordUpdateResourceOperation
+
+	dryRuns int
+}
+
+func (r *DefaultingResourceOperation) Update(obj *unstructured.Unstructured, options metav1.UpdateOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	if len(options.DryRun) == 0 {
+		return r.RecordUpdateResourceOperation.Update(obj, options, subresources...)
+	}
+	r.dryRuns++
+	defaulted := obj.DeepCopy()
+	if cpu, _, _ := unstructured.NestedString(defaulted.Object, "spec", "cpu"); cpu == "1000m" {
+		_ = unstructured.SetNestedField(defaulted.Object, "1", "spec", "cpu")
+	}
+	return defaulted, nil
+}
+
+func TestAttachmentResourcesExecutorUpdateWithDryRunCompare(t *testing.T) {
+	var tests = map[string]struct {
+		desiredCPU string
+		isUpdate   bool
+	}{
+		"defaulted to observed": {
+			desiredCPU: "1000m",
+		},
+		"changed": {
+			desiredCPU: "2",
+			isUpdate:   true,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			op := &DefaultingResourceOperation{}
+			executor := &AttachmentResourcesExecutor{
+				AttachmentExecuteBase: AttachmentExecuteBase{
+					GetChildUpdateStrategyByGK: func(group, kind string) v1alpha1.ChildUpdateMethod {
+						return v1alpha1.ChildUpdateInPlace
+					},
+					IsPatchByGK: func(group, kind string) bool {
+						return false
+					},
+					IsDryRunCompareByGK: func(group, kind string) bool {
+						return true
+					},
+					DryRunCache: NewDryRunCache(10),
+					Watch: &unstructured.Unstructured{
+						Object: map[string]interface{}{
+							"metadata": map[string]interface{}{
+								"name": "watch",
+								"uid":  "test-watch-uid",
+							},
+						},
+					},
+					UpdateAny: kubernetes.BoolPtr(true),
+				},
+				DynamicResourceClient: &dynamicclientset.ResourceClient{
+					ResourceInterface: op,
+					APIResource:       &dynamicdiscovery.APIResource{},
+				},
+			}
+			observed := &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"metadata": map[string]interface{}{
+						"name":            "attachment",
+						"uid":             "test-attachment-uid",
+						"resourceVersion": "1",
+						"annotations": map[string]interface{}{
+							"test-watch-uid" + lastAppliedAnnotationKeySuffix: `{"metadata":{"name":"attachment"},"spec":{"cpu":"1000m"}}`,
+						},
+					},
+					"spec": map[string]interface{}{
+						"cpu": "1",
+					},
+				},
+			}
+			desired := &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"metadata": map[string]interface{}{
+						"name": "attachment",
+					},
+					"spec": map[string]interface{}{
+						"cpu": mock.desiredCPU,
+					},
+				},
+			}
+			for i := 0; i < 2; i++ {
+				got, err := executor.Update(observed.DeepCopy(), desired.DeepCopy())
+				if err != nil {
+					t.Fatalf("Expected no error got %v", err)
+				}
+				if got != mock.isUpdate {
+					t.Fatalf("Expected update %t got %t", mock.isUpdate, got)
+				}
+			}
+			if op.dryRuns != 1 {
+				t.Fatalf("Expected 1 dry run got %d", op.dryRuns)
+			}
+			wantUpdates := 0
+			if mock.isUpdate {
+				wantUpdates = 2
+			}
+			if len(op.updated) != wantUpdates {
+				t.Fatalf("Expected %d updates got %d", wantUpdates, len(op.updated))
+			}
+		})
+	}
+}
@@ -33,6 +33,45 @@ type Finalizer struct {
 
 	// Boolean that flags if finalizer should be added or removed
 	Enabled bool
+
+	// Names of the finalizers that were managed previously. These
+	// get replaced with Name.
+	//
+	// NOTE:
+	//	This is useful when the finalizer name gets changed since the
+	// objects may still have a previous finalizer name.
+	LegacyNames []string
+}
+
+// HasFinalizer returns true if the given object has this finalizer
+// or any of its legacy finalizers
+func (m *Finalizer) HasFinalizer(obj metav1.Object) bool {
+	return dynamicobject.HasFinalizer(obj, m.Name) || m.hasLegacyFinalizer(obj)
+}
+
+// hasLegacyFinalizer returns true if the given object has any of the
+// legacy finalizers
+func (m *Finalizer) hasLegacyFinalizer(obj metav1.Object) bool {
+	for _, name := range m.LegacyNames {
+		if name != m.Name && dynamicobject.HasFinalizer(obj, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// removeLegacyFinalizers removes the legacy finalizers from the given
+// object. It returns true if the object got changed.
+func (m *Finalizer) removeLegacyFinalizers(obj metav1.Object) bool {
+	if !m.hasLegacyFinalizer(obj) {
+		return false
+	}
+	for _, name := range m.LegacyNames {
+		if name != m.Name {
+			dynamicobject.RemoveFinalizer(obj, name)
+		}
+	}
+	return true
 }
 
 // SyncObject reconciles i.e. adds or removes the finalizer on
@@ -48,7 +87,7 @@ func (m *Finalizer) SyncObject(
 	//
 	// - Finalizer is desired and this object has the finalizer
 	// - Finalizer is not desired & this object does not have the finalizer
-	if dynamicobject.HasFinalizer(obj, m.Name) == m.Enabled {
+	if dynamicobject.HasFinalizer(obj, m.Name) == m.Enabled && !m.hasLegacyFinalizer(obj) {
 		return obj, nil
 	}
 
@@ -60,12 +99,43 @@ func (m *Finalizer) SyncObject(
 	if m.Enabled {
 		// If the object is already pending deletion, we don't add the finalizer.
 		// We might have already removed it.
+		//
+		// NOTE:
+		//	Legacy finalizers are retained as well since new finalizers
+		// can't be added to an object that is pending deletion.
 		if obj.GetDeletionTimestamp() != nil {
 			return obj, nil
 		}
-		return client.Namespace(obj.GetNamespace()).AddFinalizer(obj, m.Name)
+		if !m.hasLegacyFinalizer(obj) {
+			return client.Namespace(obj.GetNamespace()).AddFinalizer(obj, m.Name)
+		}
+		// replace legacy finalizers with this finalizer
+		return client.Namespace(obj.GetNamespace()).AtomicUpdate(
+			obj,
+			func(obj *unstructured.Unstructured) bool {
+				changed := m.removeLegacyFinalizers(obj)
+				if !dynamicobject.HasFinalizer(obj, m.Name) {
+					dynamicobject.AddFinalizer(obj, m.Name)
+					changed = true
+				}
+				return changed
+			},
+		)
+	}
+	if !m.hasLegacyFinalizer(obj) {
+		return client.Namespace(obj.GetNamespace()).RemoveFinalizer(obj, m.Name)
 	}
-	return client.Namespace(obj.GetNamespace()).RemoveFinalizer(obj, m.Name)
+	return client.Namespace(obj.GetNamespace()).AtomicUpdate(
+		obj,
+		func(obj *unstructured.Unstructured) bool {
+			changed := m.removeLegacyFinalizers(obj)
+			if dynamicobject.HasFinalizer(obj, m.Name) {
+				dynamicobject.RemoveFinalizer(obj, m.Name)
+				changed = true
+			}
+			return changed
+		},
+	)
 }
 
 // ShouldFinalize returns true if the controller should take action
@@ -78,7 +148,7 @@ func (m *Finalizer) ShouldFinalize(parent metav1.Object) bool {
 		return false
 	}
 	// If we already removed the finalizer, don't try to manage children anymore.
-	if !dynamicobject.HasFinalizer(parent, m.Name) {
+	if !m.HasFinalizer(parent) {
 		return false
 	}
 	return m.Enabled
@@ -91,6 +161,7 @@ func (m *Finalizer) ShouldFinalize(parent metav1.Object) bool {
 // at the cluster.
 func (m *Finalizer) RemoveFinalizer(obj *unstructured.Unstructured) {
 	dynamicobject.RemoveFinalizer(obj, m.Name)
+	m.removeLegacyFinalizers(obj)
 }
 
 // hasGCFinalizer returns true if obj has any GC finalizer.
@@ -0,0 +1,225 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+const (
+	// EventReasonHotLoop is the reason of the event raised when a
+	// watch is synced & mutated continuously without converging
+	EventReasonHotLoop string = "HotLoop"
+)
+
+var (
+	// hotLoopControllerKey tags the hot loop metric with the name
+	// of the controller
+	hotLoopControllerKey, _ = tag.NewKey("controller")
+
+	// hotLoopMeasure counts the detected hot loops
+	hotLoopMeasure = stats.Int64(
+		"metac/hot_loops",
+		"Number of times a resource was found to be synced & mutated continuously",
+		stats.UnitDimensionless,
+	)
+
+	// HotLoopView exposes the detected hot loops per controller
+	//
+	// NOTE:
+	//	This needs to be registered to be exported
+	HotLoopView = &view.View{
+		Name:        "metac/hot_loops_total",
+		Description: hotLoopMeasure.Description(),
+		Measure:     hotLoopMeasure,
+		TagKeys:     []tag.Key{hotLoopControllerKey},
+		Aggregation: view.Count(),
+	}
+)
+
+// RecordHotLoop records a hot loop detected by the given controller
+func RecordHotLoop(controller string) {
+	err := stats.RecordWithTags(
+		context.Background(),
+		[]tag.Mutator{tag.Upsert(hotLoopControllerKey, controller)},
+		hotLoopMeasure.M(1),
+	)
+	if err != nil {
+		glog.Warningf("Failed to record hot loop of %s: %v", controller, err)
+	}
+}
+
+// hotLoopRecord tracks the syncs of a single key
+type hotLoopRecord struct {
+	// state of the key as observed in its last sync
+	lastState string
+
+	// true if the last sync of the key wrote to the key or to the
+	// resources that are reconciled for this key
+	isWritten bool
+
+	// start of the current window
+	windowStart time.Time
+
+	// number of syncs in the current window that observed a
+	// change in state that followed a write
+	count int
+
+	// syncs of this key are delayed till this time
+	backoffUntil time.Time
+}
+
+// HotLoopDetector detects keys that are synced & mutated continuously
+// i.e. each sync writes & the state observed by the next sync differs
+// without converging. This typically happens when a sync's apply
+// results in an event that triggers the next sync that applies again.
+//
+// NOTE:
+//	A change in state that does not follow a write of the previous
+// sync is not counted. Such a change is made by others e.g. other
+// controllers & is expected to be reconciled.
+//
+// NOTE:
+//	Methods of a nil detector are no-ops i.e. hot loops are not
+// detected
+type HotLoopDetector struct {
+	// Threshold is the number of changed states that followed a
+	// write observed within Window that marks a key as hot looping
+	Threshold int
+
+	// Window is the duration within which Threshold is evaluated
+	Window time.Duration
+
+	// Backoff is the duration for which the key's syncs should be
+	// delayed once it is found to be hot looping
+	Backoff time.Duration
+
+	// now returns the current time; useful for unit tests
+	now func() time.Time
+
+	mutex   sync.Mutex
+	records map[string]*hotLoopRecord
+}
+
+// NewHotLoopDetector returns a new instance of HotLoopDetector
+func NewHotLoopDetector(threshold int, window, backoff time.Duration) *HotLoopDetector {
+	return &HotLoopDetector{
+		Threshold: threshold,
+		Window:    window,
+		Backoff:   backoff,
+		now:       time.Now,
+		records:   make(map[string]*hotLoopRecord),
+	}
+}
+
+// Observe records a sync of the given key that observed the given
+// state. It returns true if this key is found to be hot looping.
+//
+// NOTE:
+//	State can be any string that changes when the key or the
+// resources that are reconciled for this key change e.g. resource
+// versions.
+func (d *HotLoopDetector) Observe(key, state string) bool {
+	if d == nil {
+		return false
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	now := d.now()
+	record := d.records[key]
+	if record == nil {
+		d.records[key] = &hotLoopRecord{lastState: state, windowStart: now}
+		return false
+	}
+	isWritten := record.isWritten
+	record.isWritten = false
+	if record.lastState == state || !isWritten {
+		// either converged or changed by others
+		record.lastState = state
+		record.count = 0
+		record.windowStart = now
+		return false
+	}
+	record.lastState = state
+	if now.Sub(record.windowStart) > d.Window {
+		record.windowStart = now
+		record.count = 0
+	}
+	record.count++
+	if record.count < d.Threshold {
+		return false
+	}
+	record.count = 0
+	record.windowStart = now
+	record.backoffUntil = now.Add(d.Backoff)
+	return true
+}
+
+// RecordWrite records that the current sync of the given key wrote to
+// the key or to the resources that are reconciled for this key. This
+// is expected to be invoked after Observe of the same sync.
+func (d *HotLoopDetector) RecordWrite(key string) {
+	if d == nil {
+		return
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if record := d.records[key]; record != nil {
+		record.isWritten = true
+	}
+}
+
+// GetBackoff returns the remaining duration for which the syncs of
+// the given key should be delayed. Zero is returned if the key is not
+// backing off.
+func (d *HotLoopDetector) GetBackoff(key string) time.Duration {
+	if d == nil {
+		return 0
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	record := d.records[key]
+	if record == nil {
+		return 0
+	}
+	remaining := record.backoffUntil.Sub(d.now())
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// Forget stops tracking the given key
+func (d *HotLoopDetector) Forget(key string) {
+	if d == nil {
+		return
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	delete(d.records, key)
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestHotLoopDetectorObserve(t *testing.T) {
+	var tests = map[string]struct {
+		states     []string
+		isWritten  bool
+		step       time.Duration
+		isHotLoop  bool
+		hasBackoff bool
+	}{
+		"converged states": {
+			states:    []string{"1", "2", "2", "3", "3"},
+			isWritten: true,
+			step:      time.Second,
+		},
+		"changing states within window": {
+			states:     []string{"1", "2", "3", "4"},
+			isWritten:  true,
+			step:       time.Second,
+			isHotLoop:  true,
+			hasBackoff: true,
+		},
+		"changing states without writes within window": {
+			states:    []string{"1", "2", "3", "4"},
+			isWritten: false,
+			step:      time.Second,
+		},
+		"changing states beyond window": {
+			states:    []string{"1", "2", "3", "4"},
+			isWritten: true,
+			step:      time.Minute,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			now := time.Now()
+			d := NewHotLoopDetector(3, 10*time.Second, time.Minute)
+			d.now = func() time.Time { return now }
+
+			var got bool
+			for _, state := range mock.states {
+				got = d.Observe("key", state)
+				if mock.isWritten {
+					d.RecordWrite("key")
+				}
+				now = now.Add(mock.step)
+			}
+			if got != mock.isHotLoop {
+				t.Fatalf("Expected is hot loop %t got %t", mock.isHotLoop, got)
+			}
+			if hasBackoff := d.GetBackoff("key") > 0; hasBackoff != mock.hasBackoff {
+				t.Fatalf("Expected has backoff %t got %t", mock.hasBackoff, hasBackoff)
+			}
+		})
+	}
+}
+
+func TestHotLoopDetectorForget(t *testing.T) {
+	d := NewHotLoopDetector(2, time.Minute, time.Minute)
+	for i := 0; i < 3; i++ {
+		d.Observe("key", fmt.Sprintf("%d", i))
+		d.RecordWrite("key")
+	}
+	if d.GetBackoff("key") == 0 {
+		t.Fatalf("Expected backoff got none")
+	}
+	d.Forget("key")
+	if backoff := d.GetBackoff("key"); backoff != 0 {
+		t.Fatalf("Expected no backoff got %s", backoff)
+	}
+}
+
+func TestHotLoopDetectorWriteBreaksLoop(t *testing.T) {
+	d := NewHotLoopDetector(3, time.Minute, time.Minute)
+	// a sync without any write in between resets the count
+	for i, isWritten := range []bool{true, true, false, true, true} {
+		if d.Observe("key", fmt.Sprintf("%d", i)) {
+			t.Fatalf("Expected no hot loop at sync %d", i)
+		}
+		if isWritten {
+			d.RecordWrite("key")
+		}
+	}
+	if d.Observe("key", "5") {
+		t.Fatalf("Expected no hot loop after 2 consecutive writes")
+	}
+	d.RecordWrite("key")
+	if !d.Observe("key", "6") {
+		t.Fatalf("Expected hot loop after 3 consecutive writes")
+	}
+}
+
+func TestHotLoopDetectorNil(t *testing.T) {
+	var d *HotLoopDetector
+	if d.Observe("key", "1") {
+		t.Fatalf("Expected nil detector to detect no hot loop")
+	}
+	d.RecordWrite("key")
+	if backoff := d.GetBackoff("key"); backoff != 0 {
+		t.Fatalf("Expected no backoff got %s", backoff)
+	}
+	d.Forget("key")
+}
@@ -17,15 +17,123 @@ limitations under the License.
 package common
 
 import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"io/ioutil"
+	"sync"
 	"time"
 
+	"github.com/ghodss/yaml"
 	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
 	"openebs.io/metac/apis/metacontroller/v1alpha1"
 	"openebs.io/metac/hooks"
 	"openebs.io/metac/hooks/webhook"
 )
 
+// webhookDefaults holds the settings that are inherited by the
+// webhooks that do not set these
+var webhookDefaults = struct {
+	sync.RWMutex
+	schema *v1alpha1.Webhook
+}{}
+
+// SetWebhookDefaults sets the given settings as the defaults of all
+// the webhooks invoked by metac. A webhook inherits the timeout,
+// retries, TLS & headers that it does not set from these defaults.
+//
+// NOTE:
+//	Defaults can't set the URL, service or path of webhooks
+func SetWebhookDefaults(defaults *v1alpha1.Webhook) error {
+	if defaults != nil {
+		if defaults.URL != nil || defaults.Service != nil || defaults.Path != nil {
+			return errors.Errorf(
+				"Invalid webhook defaults: Can't set 'URL', 'Service' or 'Path'",
+			)
+		}
+		if defaults.Timeout != nil && defaults.Timeout.Duration <= 0 {
+			return errors.Errorf(
+				"Invalid webhook defaults: Timeout must be > 0: Got %s",
+				defaults.Timeout.Duration,
+			)
+		}
+		if defaults.Retries != nil && *defaults.Retries < 0 {
+			return errors.Errorf(
+				"Invalid webhook defaults: Retries must be >= 0: Got %d",
+				*defaults.Retries,
+			)
+		}
+		defaults = defaults.DeepCopy()
+	}
+	webhookDefaults.Lock()
+	defer webhookDefaults.Unlock()
+	webhookDefaults.schema = defaults
+	return nil
+}
+
+// LoadWebhookDefaults returns the webhook defaults from the yaml file
+// at the given path. This file has the same fields as that of a
+// webhook e.g. timeout, retries, tls & headers.
+func LoadWebhookDefaults(path string) (*v1alpha1.Webhook, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Can't load webhook defaults")
+	}
+	defaults := &v1alpha1.Webhook{}
+	err = yaml.Unmarshal(data, defaults)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Can't load webhook defaults from %q", path)
+	}
+	return defaults, nil
+}
+
+// withWebhookDefaults returns a copy of the given webhook that
+// inherits the defaults for the settings it does not set
+func withWebhookDefaults(schema *v1alpha1.Webhook) *v1alpha1.Webhook {
+	webhookDefaults.RLock()
+	defaults := webhookDefaults.schema
+	webhookDefaults.RUnlock()
+	if defaults == nil {
+		return schema
+	}
+
+	merged := schema.DeepCopy()
+	if merged.Timeout == nil {
+		merged.Timeout = defaults.Timeout
+	}
+	if merged.Retries == nil {
+		merged.Retries = defaults.Retries
+	}
+	if defaults.TLS != nil {
+		if merged.TLS == nil {
+			merged.TLS = &v1alpha1.WebhookTLS{}
+		}
+		if len(merged.TLS.CABundle) == 0 {
+			merged.TLS.CABundle = defaults.TLS.CABundle
+		}
+		if merged.TLS.ServerName == nil {
+			merged.TLS.ServerName = defaults.TLS.ServerName
+		}
+		if merged.TLS.InsecureSkipVerify == nil {
+			merged.TLS.InsecureSkipVerify = defaults.TLS.InsecureSkipVerify
+		}
+	}
+	if len(defaults.Headers) != 0 {
+		headers := make(map[string]string)
+		for name, value := range defaults.Headers {
+			headers[name] = value
+		}
+		// headers of the webhook override the default ones
+		for name, value := range merged.Headers {
+			headers[name] = value
+		}
+		merged.Headers = headers
+	}
+	return merged
+}
+
 // InvokeHook invokes the given hook with the given request
 func InvokeHook(schema *v1alpha1.Hook, request, response interface{}) error {
 	i, err := hooks.NewInvoker(WithHookSchema(schema))
@@ -35,26 +143,58 @@ func InvokeHook(schema *v1alpha1.Hook, request, response interface{}) error {
 	return i.Invoke(request, response)
 }
 
+// InvokeHookInSpan invokes the given hook with the given request as
+// part of the given trace span
+func InvokeHookInSpan(
+	span *trace.Span, schema *v1alpha1.Hook, request, response interface{},
+) error {
+	return InvokeHookWith(schema, request, response, webhook.SetSpan(span))
+}
+
+// InvokeHookWith invokes the given hook with the given request. Given
+// webhook options are applied after the options that are derived from
+// the schema e.g. to set the trace span or the request ID.
+func InvokeHookWith(
+	schema *v1alpha1.Hook, request, response interface{}, opts ...webhook.InvokerOption,
+) error {
+	i, err := hooks.NewInvoker(WithHookSchema(schema, opts...))
+	if err != nil {
+		return err
+	}
+	return i.Invoke(request, response)
+}
+
 // WithHookSchema sets the hook invoker instance with appropriate
 // invoke function based on the provided schema
 //
 // NOTE:
 //	This logic is expected to have multiple **if conditions** to support
 // different hook types e.g. webhook, inline hook, etc
-func WithHookSchema(schema *v1alpha1.Hook) hooks.InvokerOption {
+//
+// NOTE:
+//	Given webhook options if any are applied after the options that
+// are derived from the schema
+func WithHookSchema(schema *v1alpha1.Hook, opts ...webhook.InvokerOption) hooks.InvokerOption {
 	return func(invoker *hooks.Invoker) error {
 		// webhook is the only commonly supported hook for
 		// all meta controllers
 		if schema.Webhook == nil {
 			return errors.Errorf("Unsupported hook %v", schema)
 		}
+		// settings that are not set by this webhook are
+		// inherited from the defaults
+		whSchema := withWebhookDefaults(schema.Webhook)
 		// Since this is webhook set the webhook call func
 		// create a new instance of webhook invoker
-		whi, err := webhook.NewInvoker(
+		whOpts := []webhook.InvokerOption{
 			// set various webhook options
-			SetWebhookURLFromSchema(schema.Webhook),
-			SetWebhookTimeoutFromSchemaOrDefault(schema.Webhook),
-		)
+			SetWebhookURLFromSchema(whSchema),
+			SetWebhookTimeoutFromSchemaOrDefault(whSchema),
+			SetWebhookRetriesFromSchema(whSchema),
+			SetWebhookTLSFromSchema(whSchema),
+			SetWebhookHeadersFromSchema(whSchema),
+		}
+		whi, err := webhook.NewInvoker(append(whOpts, opts...)...)
 		if err != nil {
 			return err
 		}
@@ -63,6 +203,54 @@ func WithHookSchema(schema *v1alpha1.Hook) hooks.InvokerOption {
 	}
 }
 
+// ValidateHook returns error if the given hook can't be invoked via
+// InvokeHook. This does not invoke the hook.
+func ValidateHook(hook *v1alpha1.Hook) error {
+	if hook == nil {
+		return nil
+	}
+	if hook.Webhook == nil {
+		return errors.Errorf("Unsupported hook: Specify 'Webhook'")
+	}
+	return ValidateWebhook(hook.Webhook)
+}
+
+// ValidateWebhook returns error if the given webhook can't be invoked
+// as declared. This does not invoke the webhook.
+func ValidateWebhook(schema *v1alpha1.Webhook) error {
+	caller := &webhook.Invoker{}
+	for _, opt := range []webhook.InvokerOption{
+		SetWebhookURLFromSchema(schema),
+		SetWebhookTLSFromSchema(schema),
+	} {
+		err := opt(caller)
+		if err != nil {
+			return err
+		}
+	}
+	if schema.Timeout != nil && schema.Timeout.Duration <= 0 {
+		return errors.Errorf(
+			"Invalid webhook: Timeout must be > 0: Got %s", schema.Timeout.Duration,
+		)
+	}
+	if schema.Retries != nil && *schema.Retries < 0 {
+		return errors.Errorf(
+			"Invalid webhook: Retries must be >= 0: Got %d", *schema.Retries,
+		)
+	}
+	return nil
+}
+
+// GetWebhookURL returns the URL at which the given webhook is invoked
+func GetWebhookURL(schema *v1alpha1.Webhook) (string, error) {
+	caller := &webhook.Invoker{}
+	err := SetWebhookURLFromSchema(schema)(caller)
+	if err != nil {
+		return "", err
+	}
+	return caller.URL, nil
+}
+
 // SetWebhookURLFromSchema evaluates provided webhook's url and sets
 // the evaluated url against the WebhookCaller instance
 func SetWebhookURLFromSchema(schema *v1alpha1.Webhook) webhook.InvokerOption {
@@ -131,3 +319,91 @@ func SetWebhookTimeoutFromSchemaOrDefault(schema *v1alpha1.Webhook) webhook.Invo
 		return nil
 	}
 }
+
+// SetWebhookRetriesFromSchema sets the number of retries of the
+// provided webhook against WebhookCaller instance
+func SetWebhookRetriesFromSchema(schema *v1alpha1.Webhook) webhook.InvokerOption {
+	return func(caller *webhook.Invoker) error {
+		if schema.Retries == nil {
+			return nil
+		}
+		if *schema.Retries < 0 {
+			return errors.Errorf(
+				"Invalid webhook retries: Must be >= 0: %v",
+				schema,
+			)
+		}
+		caller.Retries = int(*schema.Retries)
+		return nil
+	}
+}
+
+// SetWebhookTLSFromSchema evaluates the TLS settings of the provided
+// webhook and sets the transport of these settings against
+// WebhookCaller instance
+//
+// NOTE:
+//	Transports are cached by their TLS settings. Hence webhooks with
+// the same TLS settings share their transport & connections.
+func SetWebhookTLSFromSchema(schema *v1alpha1.Webhook) webhook.InvokerOption {
+	return func(caller *webhook.Invoker) error {
+		if schema.TLS == nil {
+			return nil
+		}
+		transport, err := webhook.GetTLSTransport(
+			makeWebhookTLSKey(schema.TLS),
+			func() (*tls.Config, error) {
+				return newWebhookTLSConfig(schema)
+			},
+		)
+		if err != nil {
+			return err
+		}
+		caller.Transport = transport
+		return nil
+	}
+}
+
+// makeWebhookTLSKey returns the key that identifies the given TLS
+// settings
+func makeWebhookTLSKey(settings *v1alpha1.WebhookTLS) string {
+	var serverName string
+	if settings.ServerName != nil {
+		serverName = *settings.ServerName
+	}
+	insecure := settings.InsecureSkipVerify != nil && *settings.InsecureSkipVerify
+	sum := sha256.Sum256(settings.CABundle)
+	return fmt.Sprintf("%x/%s/%t", sum, serverName, insecure)
+}
+
+// newWebhookTLSConfig returns the TLS config evaluated from the TLS
+// settings of the provided webhook
+func newWebhookTLSConfig(schema *v1alpha1.Webhook) (*tls.Config, error) {
+	config := &tls.Config{}
+	if len(schema.TLS.CABundle) != 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(schema.TLS.CABundle) {
+			return nil, errors.Errorf(
+				"Invalid webhook CA bundle: No PEM encoded certificates found: %v",
+				schema,
+			)
+		}
+		config.RootCAs = pool
+	}
+	if schema.TLS.ServerName != nil {
+		config.ServerName = *schema.TLS.ServerName
+	}
+	if schema.TLS.InsecureSkipVerify != nil {
+		config.InsecureSkipVerify = *schema.TLS.InsecureSkipVerify
+	}
+	return config, nil
+}
+
+// SetWebhookHeadersFromSchema sets the headers of the provided
+// webhook against WebhookCaller instance
+func SetWebhookHeadersFromSchema(schema *v1alpha1.Webhook) webhook.InvokerOption {
+	return func(caller *webhook.Invoker) error {
+		caller.Headers = schema.Headers
+		return nil
+	}
+}
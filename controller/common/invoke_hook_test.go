@@ -0,0 +1,267 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+
+package common
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+	"openebs.io/metac/hooks/webhook"
+)
+
+func TestSetWebhookDefaults(t *testing.T) {
+	url := "http://hooks.metac/sync"
+	retries := int32(-1)
+	var tests = map[string]struct {
+		defaults *v1alpha1.Webhook
+		isErr    bool
+	}{
+		"nil defaults": {},
+		"valid defaults": {
+			defaults: &v1alpha1.Webhook{
+				Timeout: &metav1.Duration{Duration: 5 * time.Second},
+				Headers: map[string]string{"X-Test": "test"},
+			},
+		},
+		"defaults with url": {
+			defaults: &v1alpha1.Webhook{URL: &url},
+			isErr:    true,
+		},
+		"defaults with zero timeout": {
+			defaults: &v1alpha1.Webhook{Timeout: &metav1.Duration{}},
+			isErr:    true,
+		},
+		"defaults with negative retries": {
+			defaults: &v1alpha1.Webhook{Retries: &retries},
+			isErr:    true,
+		},
+	}
+	defer SetWebhookDefaults(nil)
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			err := SetWebhookDefaults(mock.defaults)
+			if mock.isErr && err == nil {
+				t.Fatalf("Expected error got none")
+			}
+			if !mock.isErr && err != nil {
+				t.Fatalf("Expected no error got %v", err)
+			}
+		})
+	}
+}
+
+func TestWithWebhookDefaults(t *testing.T) {
+	serverName := "hooks.metac"
+	insecure := true
+	defaultRetries := int32(2)
+	hookRetries := int32(0)
+	err := SetWebhookDefaults(&v1alpha1.Webhook{
+		Timeout: &metav1.Duration{Duration: 5 * time.Second},
+		Retries: &defaultRetries,
+		TLS: &v1alpha1.WebhookTLS{
+			ServerName:         &serverName,
+			InsecureSkipVerify: &insecure,
+		},
+		Headers: map[string]string{
+			"X-Team":  "default",
+			"X-Trace": "on",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error got %v", err)
+	}
+	defer SetWebhookDefaults(nil)
+
+	hook := &v1alpha1.Webhook{
+		Retries: &hookRetries,
+		TLS: &v1alpha1.WebhookTLS{
+			InsecureSkipVerify: new(bool),
+		},
+		Headers: map[string]string{"X-Team": "hook"},
+	}
+	got := withWebhookDefaults(hook)
+	if got.Timeout == nil || got.Timeout.Duration != 5*time.Second {
+		t.Fatalf("Expected timeout 5s got %v", got.Timeout)
+	}
+	if got.Retries == nil || *got.Retries != 0 {
+		t.Fatalf("Expected retries 0 got %v", got.Retries)
+	}
+	if got.TLS.ServerName == nil || *got.TLS.ServerName != serverName {
+		t.Fatalf("Expected server name %q got %v", serverName, got.TLS.ServerName)
+	}
+	if *got.TLS.InsecureSkipVerify {
+		t.Fatalf("Expected insecure skip verify false got true")
+	}
+	if got.Headers["X-Team"] != "hook" || got.Headers["X-Trace"] != "on" {
+		t.Fatalf("Expected merged headers got %v", got.Headers)
+	}
+	if len(hook.Headers) != 1 || hook.Timeout != nil {
+		t.Fatalf("Expected hook to be unchanged got %v", hook)
+	}
+}
+
+func TestValidateHook(t *testing.T) {
+	url := "http://hooks.metac/sync"
+	path := "/sync"
+	funcName := "sync"
+	retries := int32(-1)
+	var tests = map[string]struct {
+		hook  *v1alpha1.Hook
+		isErr bool
+	}{
+		"nil hook": {},
+		"webhook with url": {
+			hook: &v1alpha1.Hook{Webhook: &v1alpha1.Webhook{URL: &url}},
+		},
+		"webhook with service & path": {
+			hook: &v1alpha1.Hook{Webhook: &v1alpha1.Webhook{
+				Service: &v1alpha1.ServiceReference{Name: "hooks", Namespace: "metac"},
+				Path:    &path,
+			}},
+		},
+		"webhook with service without path": {
+			hook: &v1alpha1.Hook{Webhook: &v1alpha1.Webhook{
+				Service: &v1alpha1.ServiceReference{Name: "hooks", Namespace: "metac"},
+			}},
+			isErr: true,
+		},
+		"webhook with zero timeout": {
+			hook: &v1alpha1.Hook{Webhook: &v1alpha1.Webhook{
+				URL: &url, Timeout: &metav1.Duration{},
+			}},
+			isErr: true,
+		},
+		"webhook with negative retries": {
+			hook:  &v1alpha1.Hook{Webhook: &v1alpha1.Webhook{URL: &url, Retries: &retries}},
+			isErr: true,
+		},
+		"webhook with invalid ca bundle": {
+			hook: &v1alpha1.Hook{Webhook: &v1alpha1.Webhook{
+				URL: &url, TLS: &v1alpha1.WebhookTLS{CABundle: []byte("not a cert")},
+			}},
+			isErr: true,
+		},
+		"inline hook": {
+			hook:  &v1alpha1.Hook{Inline: &v1alpha1.Inline{FuncName: &funcName}},
+			isErr: true,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			err := ValidateHook(mock.hook)
+			if mock.isErr && err == nil {
+				t.Fatalf("Expected error got none")
+			}
+			if !mock.isErr && err != nil {
+				t.Fatalf("Expected no error got %v", err)
+			}
+		})
+	}
+}
+
+func TestInvokeHookWithRetriesAndHeaders(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Header.Get("X-Team") != "test" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"ok": true}`))
+	}))
+	defer srv.Close()
+
+	err := SetWebhookDefaults(&v1alpha1.Webhook{
+		Headers: map[string]string{"X-Team": "test"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error got %v", err)
+	}
+	defer SetWebhookDefaults(nil)
+
+	url := srv.URL
+	retries := int32(1)
+	hook := &v1alpha1.Hook{
+		Webhook: &v1alpha1.Webhook{
+			URL:     &url,
+			Retries: &retries,
+		},
+	}
+	var resp struct {
+		OK bool `json:"ok"`
+	}
+	err = InvokeHook(hook, map[string]string{}, &resp)
+	if err != nil {
+		t.Fatalf("Expected no error got %v", err)
+	}
+	if !resp.OK {
+		t.Fatalf("Expected ok response got %v", resp)
+	}
+	if calls != 2 {
+		t.Fatalf("Expected 2 calls got %d", calls)
+	}
+}
+
+func TestSetWebhookTLSFromSchemaSharesTransports(t *testing.T) {
+	newCaller := func(tlsSettings *v1alpha1.WebhookTLS) *webhook.Invoker {
+		caller, err := webhook.NewInvoker(
+			SetWebhookTLSFromSchema(&v1alpha1.Webhook{TLS: tlsSettings}),
+		)
+		if err != nil {
+			t.Fatalf("Expected no error got %+v", err)
+		}
+		return caller
+	}
+	serverName := "hooks.metac"
+	otherServerName := "other.metac"
+
+	if caller := newCaller(nil); caller.Transport != nil {
+		t.Fatalf("Expected default transport without TLS settings")
+	}
+	first := newCaller(&v1alpha1.WebhookTLS{ServerName: &serverName})
+	second := newCaller(&v1alpha1.WebhookTLS{ServerName: &serverName})
+	if first.Transport == nil || first.Transport != second.Transport {
+		t.Fatalf("Expected same TLS settings to share their transport")
+	}
+	other := newCaller(&v1alpha1.WebhookTLS{ServerName: &otherServerName})
+	if other.Transport == first.Transport {
+		t.Fatalf("Expected different TLS settings to use different transports")
+	}
+
+	_, err := webhook.NewInvoker(
+		SetWebhookTLSFromSchema(&v1alpha1.Webhook{
+			TLS: &v1alpha1.WebhookTLS{CABundle: []byte("invalid")},
+		}),
+	)
+	if err == nil {
+		t.Fatalf("Expected error for invalid CA bundle got none")
+	}
+}
@@ -17,20 +17,28 @@ limitations under the License.
 package common
 
 import (
+	"context"
 	"fmt"
+	"sort"
 	"strings"
 
-	"github.com/golang/glog"
 	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/flowcontrol"
 
 	"openebs.io/metac/apis/metacontroller/v1alpha1"
 	dynamicapply "openebs.io/metac/dynamic/apply"
 	dynamicclientset "openebs.io/metac/dynamic/clientset"
+	"openebs.io/metac/logging"
 	"openebs.io/metac/third_party/kubernetes"
+	"openebs.io/metac/tracing"
 )
 
 const (
@@ -41,6 +49,73 @@ const (
 	lastAppliedAnnotationKeySuffix string = "/gctl-last-applied"
 )
 
+const (
+	// AttachmentProtectAnnotationKey is the annotation that can be
+	// set against an attachment to protect it from this controller
+	AttachmentProtectAnnotationKey string = "metac.openebs.io/protect"
+
+	// AttachmentProtectDelete when set as the value of protect
+	// annotation implies the attachment should never be deleted
+	AttachmentProtectDelete string = "true"
+
+	// AttachmentProtectAll when set as the value of protect
+	// annotation implies the attachment should never be deleted
+	// or updated
+	AttachmentProtectAll string = "all"
+)
+
+const (
+	// SkipDeletionLimitAnnotationKey is the annotation that can be set
+	// against a watch with value "true" to let a sync delete more
+	// attachments than the configured deletion limit
+	SkipDeletionLimitAnnotationKey string = "metac.openebs.io/skip-deletion-limit"
+
+	// ReportApplyDiffsAnnotationKey is the annotation that can be set
+	// against a watch with value "true" to raise events listing the
+	// fields of the attachments that get updated
+	ReportApplyDiffsAnnotationKey string = "metac.openebs.io/report-apply-diffs"
+)
+
+const (
+	// EventReasonRecreatedOnImmutableError is the reason of the
+	// event raised when an attachment is deleted to be recreated
+	// since its update failed due to changes in immutable fields
+	EventReasonRecreatedOnImmutableError string = "RecreatedOnImmutableError"
+
+	// EventReasonRecreateThrottled is the reason of the event
+	// raised when an attachment can not be recreated due to rate
+	// limits
+	EventReasonRecreateThrottled string = "RecreateThrottled"
+
+	// EventReasonProtected is the reason of the event raised when
+	// an attachment is not deleted or updated since it is protected
+	EventReasonProtected string = "Protected"
+
+	// EventReasonAdopted is the reason of the event raised when a
+	// pre-existing attachment is adopted by the watch
+	EventReasonAdopted string = "Adopted"
+
+	// EventReasonAttachmentLimitExceeded is the reason of the event
+	// raised when a sync is refused since it needs more attachments
+	// than allowed
+	EventReasonAttachmentLimitExceeded string = "AttachmentLimitExceeded"
+
+	// EventReasonApplyDiff is the reason of the event raised when
+	// an attachment is updated & the watch is annotated to report
+	// the updated fields
+	EventReasonApplyDiff string = "ApplyDiff"
+
+	// EventReasonDeletionLimitExceeded is the reason of the event
+	// raised when a sync is aborted since it needs to delete more
+	// attachments than allowed
+	EventReasonDeletionLimitExceeded string = "DeletionLimitExceeded"
+
+	// EventReasonAttachmentsPruned is the reason of the event raised
+	// when observed attachments are deleted since these are no longer
+	// desired by the watch
+	EventReasonAttachmentsPruned string = "AttachmentsPruned"
+)
+
 // AttachmentExecuteBase holds the common properties required to
 // operate against an attachment.
 type AttachmentExecuteBase struct {
@@ -54,6 +129,132 @@ type AttachmentExecuteBase struct {
 	// default 3-way merge during update operations.
 	IsPatchByGK func(group, kind string) bool
 
+	// GetPatchTypeByGK returns the type of patch used to update the
+	// attachment based on the given api group & kind in-place
+	//
+	// NOTE:
+	//	This is optional. Attachments are updated as a whole if this
+	// is not set or returns an empty patch type.
+	GetPatchTypeByGK func(group, kind string) types.PatchType
+
+	// IsReadOnlyByGK returns true if attachment based on the given
+	// api group & kind should never be created, updated or deleted.
+	// These attachments are only observed.
+	IsReadOnlyByGK func(group, kind string) bool
+
+	// IsCreateOnlyByGK returns true if attachment based on the given
+	// api group & kind should be created if missing but should never
+	// be updated or deleted afterwards.
+	IsCreateOnlyByGK func(group, kind string) bool
+
+	// GetDeletionPropagationByGK returns the propagation policy to be
+	// used while deleting the attachment based on the given api group
+	// & kind
+	GetDeletionPropagationByGK func(group, kind string) metav1.DeletionPropagation
+
+	// IsRecreateOnImmutableErrorByGK returns true if attachment based
+	// on the given api group & kind should be deleted & recreated if
+	// its update fails due to changes in immutable fields
+	IsRecreateOnImmutableErrorByGK func(group, kind string) bool
+
+	// GetAdoptPolicyByGK returns the policy to be followed to adopt
+	// pre-existing attachment based on the given api group & kind
+	GetAdoptPolicyByGK func(group, kind string) v1alpha1.GenericControllerAttachmentAdoptPolicy
+
+	// GetListMapKeysByGK returns the merge keys of the list fields of
+	// attachment based on the given api group & kind. These keys are
+	// anchored by the dot separated paths of the list fields.
+	GetListMapKeysByGK func(group, kind string) map[string]string
+
+	// GetIgnorePathsByGK returns the fields of attachment based on the
+	// given api group & kind that should never be updated. Each field
+	// is represented by its path.
+	GetIgnorePathsByGK func(group, kind string) [][]string
+
+	// GetConflictPolicyByGK returns the policy to be followed when the
+	// desired fields of attachment based on the given api group & kind
+	// were changed by someone else
+	//
+	// NOTE:
+	//	This is optional
+	GetConflictPolicyByGK func(group, kind string) v1alpha1.GenericControllerAttachmentConflictPolicy
+
+	// IsDryRunCompareByGK returns true if attachment based on the
+	// given api group & kind should be compared with its observed
+	// state via a dry run update before being updated
+	//
+	// NOTE:
+	//	This is optional
+	IsDryRunCompareByGK func(group, kind string) bool
+
+	// GetMergeModeByGK returns the way desired state of attachment
+	// based on the given api group & kind is merged with its observed
+	// state
+	//
+	// NOTE:
+	//	This is optional
+	GetMergeModeByGK func(group, kind string) v1alpha1.GenericControllerAttachmentMergeMode
+
+	// MaxUpdateConflictRetries is the number of times an update that
+	// fails due to a conflict is retried after fetching the attachment
+	// again
+	MaxUpdateConflictRetries int
+
+	// DryRunCache caches the results of dry run updates
+	//
+	// NOTE:
+	//	Dry runs are not cached if this is nil
+	DryRunCache *DryRunCache
+
+	// OnApplyDiffFn is invoked with the fields that differ between
+	// the observed & applied states of an attachment that got updated
+	//
+	// NOTE:
+	//	This is optional
+	OnApplyDiffFn func(obj *unstructured.Unstructured, diffs []dynamicapply.FieldDiff)
+
+	// OnActionFn is invoked with every change made to an attachment
+	// e.g. Created, Updated or Deleted
+	//
+	// NOTE:
+	//	This is optional
+	OnActionFn func(action string, obj *unstructured.Unstructured)
+
+	// TraceContext holds the trace span of the reconcile. Create,
+	// update & delete of every attachment is traced as its child.
+	//
+	// NOTE:
+	//	This is optional
+	TraceContext context.Context
+
+	// RequestID identifies the reconcile that operates upon the
+	// attachments. This is appended to the messages of the events.
+	//
+	// NOTE:
+	//	This is optional
+	RequestID string
+
+	// Logger logs the operations against the attachments of the
+	// watch. The attachment is set against every entry.
+	//
+	// NOTE:
+	//	This is optional. Entries are logged at glog's verbosity
+	// without the controller & the watch if this is not set.
+	Logger logging.Logger
+
+	// RecreateRateLimiter limits the number of attachments that get
+	// recreated due to immutable field errors
+	//
+	// NOTE:
+	//	Recreation is not rate limited if this is nil
+	RecreateRateLimiter flowcontrol.RateLimiter
+
+	// EventRecorder is used to raise events against the watch
+	//
+	// NOTE:
+	//	Events are not raised if this is nil
+	EventRecorder record.EventRecorder
+
 	// Resource that is under watch. A watch might be related
 	// to the attachments. For example, a watch object might
 	// be owner of the attachments, etc.
@@ -83,6 +284,25 @@ type AttachmentExecuteBase struct {
 	// If UpdateDuringPendingDelete is set to true it will proceed with
 	// updating the resource even if this resource is pending deletion
 	UpdateDuringPendingDelete *bool
+
+	// ApplyStrategy determines how the desired attachments are applied
+	// against the cluster
+	//
+	// NOTE:
+	//	Attachments are applied via 3-way merge based on the last
+	// applied annotation unless this is set to ServerSideApply or
+	// ManagedFields
+	ApplyStrategy v1alpha1.ApplyStrategy
+
+	// FieldManager is the name of the manager that owns the fields
+	// set via server side apply or via managed fields based 3-way
+	// merge
+	FieldManager string
+
+	// IsForceApplyConflicts when set to true takes over the fields
+	// that are managed by other field managers during server side
+	// apply. Apply fails on such conflicts otherwise.
+	IsForceApplyConflicts bool
 }
 
 // String implements Stringer interface
@@ -98,6 +318,60 @@ func (m AttachmentExecuteBase) String() string {
 	return strings.Join(strs, " ")
 }
 
+// names of the spans of the attachment operations
+const (
+	spanCreateAttachment = "metac/create-attachment"
+	spanUpdateAttachment = "metac/update-attachment"
+	spanDeleteAttachment = "metac/delete-attachment"
+)
+
+// startAttachmentSpan starts the span of the given operation against
+// the given attachment
+func (m AttachmentExecuteBase) startAttachmentSpan(
+	name string, obj *unstructured.Unstructured,
+) (context.Context, *trace.Span) {
+	return tracing.StartSpan(
+		m.TraceContext,
+		name,
+		trace.StringAttribute(tracing.AttributeAttachment, DescObjectAsKey(obj)),
+	)
+}
+
+// logFor returns the logger of the operations against the given
+// attachment
+func (m AttachmentExecuteBase) logFor(obj *unstructured.Unstructured) logging.Logger {
+	return m.Logger.WithValues(logging.KeyAttachment, DescObjectAsKey(obj))
+}
+
+// IsDeleteProtected returns true if the given attachment is
+// annotated to be protected from deletion
+func IsDeleteProtected(obj metav1.Object) bool {
+	switch obj.GetAnnotations()[AttachmentProtectAnnotationKey] {
+	case AttachmentProtectDelete, AttachmentProtectAll:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsCreatedByWatch returns true if the given attachment was created
+// by the given watch or was adopted by it
+func IsCreatedByWatch(obj, watch metav1.Object) bool {
+	return obj.GetAnnotations()[attachmentCreateAnnotationKey] == string(watch.GetUID())
+}
+
+// IsDeletionLimitSkipped returns true if the given watch is annotated
+// to skip the deletion limit
+func IsDeletionLimitSkipped(watch metav1.Object) bool {
+	return watch.GetAnnotations()[SkipDeletionLimitAnnotationKey] == "true"
+}
+
+// IsUpdateProtected returns true if the given attachment is
+// annotated to be protected from updates
+func IsUpdateProtected(obj metav1.Object) bool {
+	return obj.GetAnnotations()[AttachmentProtectAnnotationKey] == AttachmentProtectAll
+}
+
 // AttachmentManager manages applying the attachment resources
 // in Kubernetes cluster. Here apply implies either Create or
 // Update or Delete attachment resources against the Kubernetes
@@ -114,6 +388,10 @@ type AttachmentManager struct {
 	// for a specific resource
 	DynamicClientSet *dynamicclientset.Clientset
 
+	// IsDryRun when true makes every create, update, patch & delete
+	// of the attachments a dry run
+	IsDryRun bool
+
 	// Observed state (i.e. current state in kubernetes) of
 	// attachment resources
 	Observed AnyUnstructRegistry
@@ -121,6 +399,15 @@ type AttachmentManager struct {
 	// Desired kubernetes state of attachment resources
 	Desired AnyUnstructRegistry
 
+	// MaxDeletions is the maximum number of attachments that can be
+	// deleted by a single apply. Apply fails without deleting any
+	// attachment if this limit is exceeded.
+	//
+	// NOTE:
+	//	There is no limit if this is nil, if the watch is being deleted
+	// or if the watch is annotated to skip the deletion limit.
+	MaxDeletions *int
+
 	// Various executioners required to execute apply
 	DeleteFn         func() error
 	CreateOrUpdateFn func() error
@@ -147,7 +434,7 @@ func anyAttachmentsDefaultDeleter() AttachmentManagerOption {
 		// iterate to group resources of same kind & apiVersion
 		for verkind, objects := range m.Observed {
 			apiVersion, kind := ParseKeyToAPIVersionKind(verkind)
-			client, err := m.DynamicClientSet.GetClientByKind(apiVersion, kind)
+			client, err := m.getClientByKind(apiVersion, kind)
 			if err != nil {
 				errs = append(errs, err)
 				continue
@@ -180,7 +467,7 @@ func anyAttachmentsDefaultCreateUpdater() AttachmentManagerOption {
 		for verkind, objects := range m.Desired {
 			apiVersion, kind := ParseKeyToAPIVersionKind(verkind)
 			// get the specific resource client
-			client, err := m.DynamicClientSet.GetClientByKind(apiVersion, kind)
+			client, err := m.getClientByKind(apiVersion, kind)
 			if err != nil {
 				errs = append(errs, err)
 				continue
@@ -213,6 +500,22 @@ func (m AttachmentManager) String() string {
 	return m.AttachmentExecuteBase.String()
 }
 
+// getClientByKind returns the client of the attachments of the given
+// version & kind. Writes of this client are dry runs if this manager
+// is a dry run.
+func (m *AttachmentManager) getClientByKind(
+	apiVersion, kind string,
+) (*dynamicclientset.ResourceClient, error) {
+	client, err := m.DynamicClientSet.GetClientByKind(apiVersion, kind)
+	if err != nil {
+		return nil, err
+	}
+	if m.IsDryRun {
+		return client.DryRun(), nil
+	}
+	return client, nil
+}
+
 // setDefaults prepares the attachment manager before invoking the
 // Apply
 //
@@ -246,12 +549,119 @@ func (m *AttachmentManager) Apply() error {
 		return utilerrors.NewAggregate(m.errs)
 	}
 
+	err := m.verifyDeletionLimit()
+	if err != nil {
+		return err
+	}
+
 	m.errs = appendErrIfNotNil(m.errs, m.DeleteFn())
 	m.errs = appendErrIfNotNil(m.errs, m.CreateOrUpdateFn())
 
 	return utilerrors.NewAggregate(m.errs)
 }
 
+// verifyDeletionLimit returns error if the attachments that need to be
+// deleted are more than the maximum deletions allowed
+//
+// NOTE:
+//	The limit is not verified if the watch is being deleted since
+// finalizing a watch is expected to delete its attachments
+func (m *AttachmentManager) verifyDeletionLimit() error {
+	if m.MaxDeletions == nil || m.Watch == nil ||
+		m.Watch.GetDeletionTimestamp() != nil ||
+		IsDeletionLimitSkipped(m.Watch) {
+		return nil
+	}
+	count, err := m.CountDeletions()
+	if err != nil {
+		return err
+	}
+	if count <= *m.MaxDeletions {
+		return nil
+	}
+	e := AttachmentResourcesExecutor{AttachmentExecuteBase: m.AttachmentExecuteBase}
+	e.recordEvent(
+		corev1.EventTypeWarning,
+		EventReasonDeletionLimitExceeded,
+		"Refused to delete %d attachments: Max deletions %d: Set annotation %s=true to override",
+		count,
+		*m.MaxDeletions,
+		SkipDeletionLimitAnnotationKey,
+	)
+	return errors.Errorf(
+		"Can't delete %d attachments of watch %s: Max deletions %d",
+		count,
+		DescObjectAsKey(m.Watch),
+		*m.MaxDeletions,
+	)
+}
+
+// CountDeletions returns the number of observed attachments that will
+// get deleted by this manager
+func (m *AttachmentManager) CountDeletions() (int, error) {
+	var count int
+	var errs []error
+	for verkind, objects := range m.Observed {
+		apiVersion, kind := ParseKeyToAPIVersionKind(verkind)
+		client, err := m.getClientByKind(apiVersion, kind)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		r := &AttachmentResourcesExecutor{
+			AttachmentExecuteBase: m.AttachmentExecuteBase,
+			DynamicResourceClient: client,
+			Observed:              objects,
+			Desired:               m.Desired[verkind],
+		}
+		count += r.CountDeletions()
+	}
+	return count, utilerrors.NewAggregate(errs)
+}
+
+// Release removes the ownership markers set by the watch against
+// the observed attachments of this manager. Attachments are left in
+// place i.e. these are orphaned.
+func (m *AttachmentManager) Release() error {
+	var errs []error
+	for verkind, objects := range m.Observed {
+		apiVersion, kind := ParseKeyToAPIVersionKind(verkind)
+		client, err := m.getClientByKind(apiVersion, kind)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		r := &AttachmentResourcesExecutor{
+			AttachmentExecuteBase: m.AttachmentExecuteBase,
+			DynamicResourceClient: client,
+			Observed:              objects,
+		}
+		errs = appendErrIfNotNil(errs, r.Release())
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// DeleteUnowned deletes the observed attachments of this manager
+// that were created by the watch but are not owned by the watch
+func (m *AttachmentManager) DeleteUnowned() error {
+	var errs []error
+	for verkind, objects := range m.Observed {
+		apiVersion, kind := ParseKeyToAPIVersionKind(verkind)
+		client, err := m.getClientByKind(apiVersion, kind)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		d := &AttachmentResourcesExecutor{
+			AttachmentExecuteBase: m.AttachmentExecuteBase,
+			DynamicResourceClient: client,
+			Observed:              objects,
+		}
+		errs = appendErrIfNotNil(errs, d.DeleteUnowned())
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
 // AttachmentResourcesExecutor holds fields required to execute
 // operations against the attachment resources
 type AttachmentResourcesExecutor struct {
@@ -278,6 +688,14 @@ func (e AttachmentResourcesExecutor) String() string {
 	return e.AttachmentExecuteBase.String()
 }
 
+// logForKind returns the logger of the operations against all the
+// attachments handled by this executor
+func (e AttachmentResourcesExecutor) logForKind() logging.Logger {
+	return e.Logger.WithValues(
+		logging.KeyGVR, e.DynamicResourceClient.APIVersion+"/"+e.DynamicResourceClient.Name,
+	)
+}
+
 // IsUpdateDuringPendingDelete returns true if update is allowed
 // even if the targeted resource is pending deletion
 func (e AttachmentResourcesExecutor) IsUpdateDuringPendingDelete() bool {
@@ -287,12 +705,422 @@ func (e AttachmentResourcesExecutor) IsUpdateDuringPendingDelete() bool {
 	return *e.UpdateDuringPendingDelete
 }
 
+// IsReadOnly returns true if the attachments handled by this
+// executor should only be observed & never be created, updated
+// or deleted
+func (e AttachmentResourcesExecutor) IsReadOnly() bool {
+	if e.IsReadOnlyByGK == nil {
+		return false
+	}
+	return e.IsReadOnlyByGK(
+		e.DynamicResourceClient.Group, e.DynamicResourceClient.Kind,
+	)
+}
+
+// IsCreateOnly returns true if the attachments handled by this
+// executor should never be updated or deleted once created
+func (e AttachmentResourcesExecutor) IsCreateOnly() bool {
+	if e.IsCreateOnlyByGK == nil {
+		return false
+	}
+	return e.IsCreateOnlyByGK(
+		e.DynamicResourceClient.Group, e.DynamicResourceClient.Kind,
+	)
+}
+
+// DeletionPropagation returns the propagation policy to be used
+// while deleting the attachments handled by this executor
+func (e AttachmentResourcesExecutor) DeletionPropagation() metav1.DeletionPropagation {
+	if e.GetDeletionPropagationByGK == nil {
+		// Explicitly request deletion propagation, which is what
+		// users expect, since some objects default to orphaning
+		// for backwards compatibility.
+		return metav1.DeletePropagationBackground
+	}
+	return e.GetDeletionPropagationByGK(
+		e.DynamicResourceClient.Group, e.DynamicResourceClient.Kind,
+	)
+}
+
+// canWatchOwn returns true if the watch can be set as an owner
+// reference of the attachment that belongs to the given namespace.
+//
+// NOTE:
+//	A namespaced watch can only own attachments in its own namespace.
+// Attachments that can't be owned are tracked via the annotation that
+// is set during creation. These are deleted by this controller when
+// the watch is deleted.
+func (e AttachmentResourcesExecutor) canWatchOwn(namespace string) bool {
+	if e.Watch.GetNamespace() == "" {
+		// cluster scoped watch can own namespaced as well as
+		// cluster scoped attachments
+		return true
+	}
+	return e.DynamicResourceClient.Namespaced &&
+		namespace == e.Watch.GetNamespace()
+}
+
+// IsRecreateOnImmutableError returns true if the attachments handled
+// by this executor should be recreated when their updates fail due
+// to changes in immutable fields
+func (e AttachmentResourcesExecutor) IsRecreateOnImmutableError() bool {
+	if e.IsRecreateOnImmutableErrorByGK == nil {
+		return false
+	}
+	return e.IsRecreateOnImmutableErrorByGK(
+		e.DynamicResourceClient.Group, e.DynamicResourceClient.Kind,
+	)
+}
+
+// AdoptPolicy returns the policy to be followed to adopt the
+// pre-existing attachments handled by this executor
+func (e AttachmentResourcesExecutor) AdoptPolicy() v1alpha1.GenericControllerAttachmentAdoptPolicy {
+	if e.GetAdoptPolicyByGK == nil {
+		return v1alpha1.AttachmentAdoptPolicyNever
+	}
+	return e.GetAdoptPolicyByGK(
+		e.DynamicResourceClient.Group, e.DynamicResourceClient.Kind,
+	)
+}
+
+// ListMapKeys returns the merge keys of the list fields of the
+// attachments handled by this executor
+func (e AttachmentResourcesExecutor) ListMapKeys() map[string]string {
+	if e.GetListMapKeysByGK == nil {
+		return nil
+	}
+	return e.GetListMapKeysByGK(
+		e.DynamicResourceClient.Group, e.DynamicResourceClient.Kind,
+	)
+}
+
+// IgnorePaths returns the fields of the attachments handled by this
+// executor that should never be updated
+func (e AttachmentResourcesExecutor) IgnorePaths() [][]string {
+	if e.GetIgnorePathsByGK == nil {
+		return nil
+	}
+	return e.GetIgnorePathsByGK(
+		e.DynamicResourceClient.Group, e.DynamicResourceClient.Kind,
+	)
+}
+
+// IsPartialMerge returns true if the desired state of the attachments
+// handled by this executor should be merged onto their observed state
+// without removing any field
+func (e AttachmentResourcesExecutor) IsPartialMerge() bool {
+	if e.GetMergeModeByGK == nil {
+		return false
+	}
+	return e.GetMergeModeByGK(
+		e.DynamicResourceClient.Group, e.DynamicResourceClient.Kind,
+	) == v1alpha1.AttachmentMergeModePartial
+}
+
+// isAdoptable returns true if the given observed attachment can be
+// adopted by the watch
+func (e AttachmentResourcesExecutor) isAdoptable(
+	observedObj *unstructured.Unstructured, createdByWatchUID string,
+) bool {
+	switch e.AdoptPolicy() {
+	case v1alpha1.AttachmentAdoptPolicyAlways:
+		return true
+	case v1alpha1.AttachmentAdoptPolicyIfUnowned:
+		return createdByWatchUID == "" && metav1.GetControllerOf(observedObj) == nil
+	default:
+		return false
+	}
+}
+
+// adopt claims the given observed attachment by setting the watch
+// details in its annotations & owner references. Adopted attachment
+// is returned.
+func (e *AttachmentResourcesExecutor) adopt(
+	ns string, observedObj *unstructured.Unstructured,
+) (*unstructured.Unstructured, error) {
+	e.logFor(observedObj).V(4).Info("Adopting attachment")
+
+	adoptObj := observedObj.DeepCopy()
+	e.setAdoption(adoptObj)
+
+	adopted, err := e.DynamicResourceClient.Namespace(ns).
+		WithAuditDiff(dynamicclientset.AuditDiff(observedObj, adoptObj)).
+		UpdateWithRetries(
+			adoptObj,
+			metav1.UpdateOptions{},
+			e.MaxUpdateConflictRetries,
+			func(latest *unstructured.Unstructured) bool {
+				// the latest state may have been claimed by others
+				createdByWatchUID := latest.GetAnnotations()[attachmentCreateAnnotationKey]
+				if createdByWatchUID != string(e.Watch.GetUID()) &&
+					!e.isAdoptable(latest, createdByWatchUID) {
+					return false
+				}
+				e.setAdoption(latest)
+				return true
+			},
+		)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: Failed to adopt %s", e, DescObjectAsKey(observedObj))
+	}
+	if adopted.GetAnnotations()[attachmentCreateAnnotationKey] != string(e.Watch.GetUID()) {
+		return nil, errors.Errorf(
+			"%s: Failed to adopt %s: Is no longer adoptable", e, DescObjectAsKey(observedObj),
+		)
+	}
+	e.recordEvent(
+		corev1.EventTypeNormal,
+		EventReasonAdopted,
+		"Adopted %s: AdoptPolicy=%q",
+		DescObjectAsKey(observedObj),
+		e.AdoptPolicy(),
+	)
+	e.logFor(observedObj).Info("Adopted attachment")
+	e.reportAction("Adopted", observedObj)
+	return adopted, nil
+}
+
+// setAdoption sets the watch details in the annotations & owner
+// references of the given attachment
+func (e *AttachmentResourcesExecutor) setAdoption(obj *unstructured.Unstructured) {
+	ann := obj.GetAnnotations()
+	if ann == nil {
+		ann = make(map[string]string)
+	}
+	ann[attachmentCreateAnnotationKey] = string(e.Watch.GetUID())
+	obj.SetAnnotations(ann)
+
+	if e.IsWatchOwner != nil && *e.IsWatchOwner && e.canWatchOwn(obj.GetNamespace()) {
+		// an object can have only one controller owner reference;
+		// hence existing controller references if any are replaced
+		// with the watch
+		var ownerRefs []metav1.OwnerReference
+		for _, ref := range obj.GetOwnerReferences() {
+			if ref.UID == e.Watch.GetUID() ||
+				(ref.Controller != nil && *ref.Controller) {
+				continue
+			}
+			ownerRefs = append(ownerRefs, ref)
+		}
+		ownerRefs = append(ownerRefs, *MakeOwnerRef(e.Watch))
+		obj.SetOwnerReferences(ownerRefs)
+	}
+}
+
+// recordEvent raises an event against the watch. This is a no-op
+// if event recorder is not set.
+func (e AttachmentResourcesExecutor) recordEvent(
+	eventType, reason, messageFmt string, args ...interface{},
+) {
+	if e.EventRecorder == nil || e.Watch == nil {
+		return
+	}
+	if e.RequestID != "" {
+		messageFmt += ": RequestID=%s"
+		args = append(args, e.RequestID)
+	}
+	e.EventRecorder.Eventf(e.Watch, eventType, reason, messageFmt, args...)
+}
+
+// recordDeleteProtectedEvent raises an event against the watch
+// stating the given attachment was not deleted since it is protected
+func (e AttachmentResourcesExecutor) recordDeleteProtectedEvent(
+	obj *unstructured.Unstructured,
+) {
+	e.recordEvent(
+		corev1.EventTypeWarning,
+		EventReasonProtected,
+		"Won't delete %s: Annotation %s=%q",
+		DescObjectAsKey(obj),
+		AttachmentProtectAnnotationKey,
+		obj.GetAnnotations()[AttachmentProtectAnnotationKey],
+	)
+}
+
+// reportAction passes the given change made to the given attachment
+// to OnActionFn if set
+func (e AttachmentResourcesExecutor) reportAction(
+	action string, obj *unstructured.Unstructured,
+) {
+	if e.OnActionFn != nil {
+		e.OnActionFn(action, obj)
+	}
+}
+
+// maxReportedApplyDiffs is the maximum number of fields that are
+// listed in an event
+const maxReportedApplyDiffs = 20
+
+// reportApplyDiffs logs the fields that got changed by an update of
+// the given attachment. An event is raised if the watch is annotated
+// to report these fields.
+func (e AttachmentResourcesExecutor) reportApplyDiffs(
+	action string, obj *unstructured.Unstructured, diffs []dynamicapply.FieldDiff,
+) {
+	if len(diffs) == 0 {
+		return
+	}
+	e.logFor(obj).V(4).Info(
+		action+" attachment", "diff", dynamicapply.JoinFieldDiffs(diffs, 0),
+	)
+	if e.OnApplyDiffFn != nil {
+		e.OnApplyDiffFn(obj, diffs)
+	}
+	if e.Watch == nil ||
+		e.Watch.GetAnnotations()[ReportApplyDiffsAnnotationKey] != "true" {
+		return
+	}
+	e.recordEvent(
+		corev1.EventTypeNormal,
+		EventReasonApplyDiff,
+		"%s %s: %s",
+		action,
+		DescObjectAsKey(obj),
+		dynamicapply.JoinFieldDiffs(diffs, maxReportedApplyDiffs),
+	)
+}
+
+// isImmutableFieldError returns true if the given error was due to
+// an update against immutable fields
+func isImmutableFieldError(err error) bool {
+	return apierrors.IsInvalid(err) &&
+		strings.Contains(strings.ToLower(err.Error()), "immutable")
+}
+
+// recreateOnImmutableError deletes the observed attachment s.t.
+// it gets created with its desired state during the next sync. This
+// is invoked when the update of the attachment failed due to changes
+// in its immutable fields.
+func (e *AttachmentResourcesExecutor) recreateOnImmutableError(
+	ns string, observedObj *unstructured.Unstructured, updateErr error,
+) error {
+	if e.RecreateRateLimiter != nil && !e.RecreateRateLimiter.TryAccept() {
+		e.recordEvent(
+			corev1.EventTypeWarning,
+			EventReasonRecreateThrottled,
+			"Can't recreate %s: Rate limited: %v",
+			DescObjectAsKey(observedObj),
+			updateErr,
+		)
+		return errors.Wrapf(
+			updateErr,
+			"%s: Can't recreate %s: Rate limited",
+			e, DescObjectAsKey(observedObj),
+		)
+	}
+	e.logFor(observedObj).V(4).Info("Deleting attachment for recreate", "err", updateErr)
+	uid := observedObj.GetUID()
+	propagation := e.DeletionPropagation()
+	err := e.DynamicResourceClient.Namespace(ns).Delete(
+		observedObj.GetName(),
+		&metav1.DeleteOptions{
+			Preconditions:     &metav1.Preconditions{UID: &uid},
+			PropagationPolicy: &propagation,
+		},
+	)
+	if err != nil {
+		return err
+	}
+	e.recordEvent(
+		corev1.EventTypeNormal,
+		EventReasonRecreatedOnImmutableError,
+		"Deleted %s for recreate: %v",
+		DescObjectAsKey(observedObj),
+		updateErr,
+	)
+	e.logFor(observedObj).Info("Deleted attachment for recreate")
+	e.reportAction("Deleted for recreate", observedObj)
+	return nil
+}
+
+// merge returns the result of the 3-way merge of the given desired
+// attachment against the given observed attachment. The apply used to
+// merge is returned to evaluate the differences & conflicts.
+func (e *AttachmentResourcesExecutor) merge(
+	observedObj, desiredObj *unstructured.Unstructured,
+) (*Apply, *unstructured.Unstructured, error) {
+	// 3-way merge
+	//
+	// Construct the annotation key that holds the last applied
+	// state. The annotation key is based on the current watch.
+	//
+	// NOTE:
+	// 	This lets an attachment to be updated independently even
+	// with multiple updates (read 3-way merges) triggered due to
+	// different watches.
+	lastAppliedKey := string(e.Watch.GetUID()) + lastAppliedAnnotationKeySuffix
+
+	// Check if its a patch based update vs. 3-way merge based update
+	isPatch := e.IsPatchByGK(e.DynamicResourceClient.Group, e.DynamicResourceClient.Kind)
+	if isPatch && !e.IsManagedFieldsApply() && !e.IsPartialMerge() {
+		// Since patch is enabled; resource based on this api group
+		// & kind will be patched versus the standard 3-way merge based
+		// update.
+		//
+		// NOTE:
+		// 	We set last applied state as observed instance's content.
+		// This last applied state is then set against the same observed
+		// instance's annotation.
+		//
+		// This lets metac to have full control of all the fields during
+		// the 3-way merge operation. This way of executing 3-way merge
+		// to arbitrary resources is equivalent to apply operation of
+		// resources created by metac controllers.
+		//
+		// NOTE:
+		// 	However, the final merged instance is saved in the cluster
+		// with desired instance's content as the last applied state.
+		err := dynamicapply.SetLastAppliedByAnnKey(
+			observedObj,
+			observedObj.UnstructuredContent(),
+			lastAppliedKey,
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Invoke Merge from a new instance of Apply struct
+	a := NewApplyFromAnnKey(lastAppliedKey)
+	if e.IsManagedFieldsApply() {
+		a = NewApplyFromManagedFields(e.FieldManager)
+		if isPatch {
+			// metac has full control of all the fields of the
+			// observed instance
+			a.GetLastAppliedFn = func(o *unstructured.Unstructured) (map[string]interface{}, error) {
+				return o.DeepCopy().UnstructuredContent(), nil
+			}
+		}
+	}
+	if e.IsPartialMerge() {
+		a = NewPartialApply()
+	}
+	a.ListMapKeys = e.ListMapKeys()
+	a.IgnorePaths = e.IgnorePaths()
+	a.IsStrategicMerge = true
+	if !isPatch {
+		a.ConflictsFn = e.conflictsFn()
+		a.IsYieldConflicts = e.ConflictPolicy() == v1alpha1.AttachmentConflictPolicyYield
+	}
+	mergedObj, err := a.Merge(observedObj, desiredObj)
+	if err != nil {
+		return nil, nil, err
+	}
+	return a, mergedObj, nil
+}
+
 // Update updates the observed attachment to its desired attachment
 //
 // NOTE:
 //	Return value with bool datatype indicates a update or no update.
 func (e *AttachmentResourcesExecutor) Update(
 	observedObj, desiredObj *unstructured.Unstructured) (bool, error) {
+	// Leave it alone if this attachment is meant to be observed only
+	if e.IsReadOnly() {
+		e.logFor(desiredObj).V(4).Info("Won't update attachment: Attachment is read only")
+		return false, nil
+	}
+
 	// TODO (@amitkumardas):
 	//
 	// Should this be desired object's namespace or
@@ -303,11 +1131,30 @@ func (e *AttachmentResourcesExecutor) Update(
 		ns = e.Watch.GetNamespace()
 	}
 
+	// Leave it alone if this attachment is owned by the user
+	// after its creation
+	if e.IsCreateOnly() {
+		e.logFor(desiredObj).V(4).Info("Won't update attachment: Attachment is create only")
+		return false, nil
+	}
+
 	// Leave it alone if it's pending deletion && updating during
 	// pending deletion is not enabled
 	if observedObj.GetDeletionTimestamp() != nil && !e.IsUpdateDuringPendingDelete() {
-		glog.V(4).Infof(
-			"%s: Can't update %s: Pending deletion", e, DescObjectAsKey(desiredObj),
+		e.logFor(desiredObj).V(4).Info("Can't update attachment: Pending deletion")
+		return false, nil
+	}
+
+	// Leave it alone if it's protected from updates
+	if IsUpdateProtected(observedObj) {
+		e.logFor(desiredObj).V(4).Info("Won't update attachment: Attachment is protected")
+		e.recordEvent(
+			corev1.EventTypeWarning,
+			EventReasonProtected,
+			"Won't update %s: Annotation %s=%q",
+			DescObjectAsKey(observedObj),
+			AttachmentProtectAnnotationKey,
+			AttachmentProtectAll,
 		)
 		return false, nil
 	}
@@ -326,17 +1173,25 @@ func (e *AttachmentResourcesExecutor) Update(
 		createdByWatchUID = observedAnns[attachmentCreateAnnotationKey]
 	}
 
+	// Claim this attachment if it was not created by this watch
+	// & adopt policy permits
+	if createdByWatchUID != currentWatchUID &&
+		e.isAdoptable(observedObj, createdByWatchUID) {
+		adopted, err := e.adopt(ns, observedObj)
+		if err != nil {
+			return false, err
+		}
+		observedObj = adopted
+		createdByWatchUID = currentWatchUID
+	}
+
 	// If watches don't match && this controller is not granted
 	// to update any arbitrary attachments then skip this update
 	if createdByWatchUID != currentWatchUID && !updateAny {
-		glog.V(4).Infof(
-			"%s: Won't update %s: Annotation %s has %q got %q: UpdateAny %t",
-			e,
-			DescObjectAsKey(desiredObj),
-			attachmentCreateAnnotationKey,
-			createdByWatchUID,
-			currentWatchUID,
-			updateAny,
+		e.logFor(desiredObj).V(4).Info(
+			"Won't update attachment: Not created by watch: UpdateAny is not set",
+			"createdByWatchUID", createdByWatchUID,
+			"watchUID", currentWatchUID,
 		)
 		return false, nil
 	}
@@ -351,55 +1206,19 @@ func (e *AttachmentResourcesExecutor) Update(
 		// This means we don't try to update anything unless
 		// it gets deleted by someone else i.e. we won't delete it
 		// ourselves
-		glog.V(4).Infof(
-			"%s: Won't update %s: UpdateStrategy=%q", e, DescObjectAsKey(desiredObj), method,
+		e.logFor(desiredObj).V(4).Info(
+			"Won't update attachment: Update strategy", "method", string(method),
 		)
 		return false, nil
 	}
 
-	// 3-way merge
-	//
-	// Construct the annotation key that holds the last applied
-	// state. The annotation key is based on the current watch.
-	//
-	// NOTE:
-	// 	This lets an attachment to be updated independently even
-	// with multiple updates (read 3-way merges) triggered due to
-	// different watches.
-	lastAppliedKey := string(e.Watch.GetUID()) + lastAppliedAnnotationKeySuffix
-
-	// Check if its a patch based update vs. 3-way merge based update
-	if e.IsPatchByGK(e.DynamicResourceClient.Group, e.DynamicResourceClient.Kind) {
-		// Since patch is enabled; resource based on this api group
-		// & kind will be patched versus the standard 3-way merge based
-		// update.
-		//
-		// NOTE:
-		// 	We set last applied state as observed instance's content.
-		// This last applied state is then set against the same observed
-		// instance's annotation.
-		//
-		// This lets metac to have full control of all the fields during
-		// the 3-way merge operation. This way of executing 3-way merge
-		// to arbitrary resources is equivalent to apply operation of
-		// resources created by metac controllers.
-		//
-		// NOTE:
-		// 	However, the final merged instance is saved in the cluster
-		// with desired instance's content as the last applied state.
-		err := dynamicapply.SetLastAppliedByAnnKey(
-			observedObj,
-			observedObj.UnstructuredContent(),
-			lastAppliedKey,
+	if e.IsServerSideApply() {
+		return e.updateViaServerSideApply(
+			ns, observedObj, desiredObj, createdByWatchUID == currentWatchUID, method,
 		)
-		if err != nil {
-			return false, err
-		}
 	}
 
-	// Invoke Merge from a new instance of Apply struct
-	a := NewApplyFromAnnKey(lastAppliedKey)
-	mergedObj, err := a.Merge(observedObj, desiredObj)
+	a, mergedObj, err := e.merge(observedObj, desiredObj)
 	if err != nil {
 		return false, err
 	}
@@ -411,25 +1230,33 @@ func (e *AttachmentResourcesExecutor) Update(
 		return false, err
 	}
 	if !isDiff {
-		glog.V(4).Infof(
-			"%s: Won't update %s: Nothing changed.", e, DescObjectAsKey(desiredObj),
-		)
+		e.logFor(desiredObj).V(4).Info("Won't update attachment: Nothing changed")
 		return false, nil
 	}
-	glog.V(4).Infof(
-		"%s: Will update %s: Diff is found between its observed & desired states.",
-		e, DescObjectAsKey(desiredObj),
+	err = e.handleConflicts(desiredObj, a.Conflicts())
+	if err != nil {
+		return false, err
+	}
+	if e.IsDryRunCompare() && e.isDryRunNoop(ns, observedObj, mergedObj) {
+		e.logFor(desiredObj).V(4).Info("Won't update attachment: Nothing changed after defaulting")
+		return false, nil
+	}
+	e.logFor(desiredObj).V(4).Info(
+		"Will update attachment: Diff is found between its observed & desired states",
 	)
 
 	// Act based on the update strategy for this child kind.
 	switch method {
 	case v1alpha1.ChildUpdateRecreate, v1alpha1.ChildUpdateRollingRecreate:
+		if IsDeleteProtected(observedObj) {
+			e.logFor(desiredObj).V(4).Info("Won't recreate attachment: Attachment is protected")
+			e.recordDeleteProtectedEvent(observedObj)
+			return false, nil
+		}
 		// Delete the object (now) and recreate it (on the next sync).
-		glog.V(4).Infof("%s: Deleting %s for update", e, DescObjectAsKey(desiredObj))
+		e.logFor(desiredObj).V(4).Info("Deleting attachment for update")
 		uid := observedObj.GetUID()
-		// Explicitly request deletion propagation, which is what users expect,
-		// since some objects default to orphaning for backwards compatibility.
-		propagation := metav1.DeletePropagationBackground
+		propagation := e.DeletionPropagation()
 		err := e.DynamicResourceClient.Namespace(ns).Delete(
 			desiredObj.GetName(),
 			&metav1.DeleteOptions{
@@ -440,27 +1267,71 @@ func (e *AttachmentResourcesExecutor) Update(
 		if err != nil {
 			return false, err
 		}
-		glog.Infof("%s: Deleted %s for update", e, DescObjectAsKey(desiredObj))
+		e.logFor(desiredObj).Info("Deleted attachment for update")
+		e.reportAction("Deleted for update", observedObj)
+		e.reportApplyDiffs("Deleted for update", observedObj, a.FieldDiffs())
 	case v1alpha1.ChildUpdateInPlace, v1alpha1.ChildUpdateRollingInPlace:
 		// Update the object in-place.
-		glog.V(4).Infof("%s: Updating %s", e, DescObjectAsKey(desiredObj))
-		// Set who is responsible for this update.
-		// In other words set the watch details in the annotations
-		updatedAnns := mergedObj.GetAnnotations()
-		if updatedAnns == nil {
-			updatedAnns = make(map[string]string)
-		}
-		updatedAnns[string(e.Watch.GetUID())+attachmentUpdateAnnotationKeySuffix] =
-			DescObjectAsSanitisedKey(e.Watch)
-		mergedObj.SetAnnotations(updatedAnns)
+		e.logFor(desiredObj).V(4).Info("Updating attachment")
+		e.setUpdatedBy(mergedObj)
+		// The desired state is merged again against the latest state
+		// of this attachment if the update conflicts
+		var remergeErr error
+		isRemergeNoop := false
+		remerge := func(latest *unstructured.Unstructured) bool {
+			if IsUpdateProtected(latest) {
+				isRemergeNoop = true
+				return false
+			}
+			latestApply, latestMerged, err := e.merge(latest, desiredObj)
+			if err != nil {
+				remergeErr = err
+				return false
+			}
+			isDiff, err := latestApply.HasMergeDiff()
+			if err != nil {
+				remergeErr = err
+				return false
+			}
+			if !isDiff {
+				isRemergeNoop = true
+				return false
+			}
+			err = e.handleConflicts(desiredObj, latestApply.Conflicts())
+			if err != nil {
+				remergeErr = err
+				return false
+			}
+			e.setUpdatedBy(latestMerged)
+			latest.Object = latestMerged.Object
+			a = latestApply
+			return true
+		}
 		// update the merged state at the cluster
-		_, err := e.DynamicResourceClient.Namespace(ns).Update(
-			mergedObj, metav1.UpdateOptions{},
-		)
+		err := e.updateInPlace(ns, observedObj, mergedObj, remerge)
+		if err == nil {
+			err = remergeErr
+		}
+		if err != nil && isImmutableFieldError(err) &&
+			e.IsRecreateOnImmutableError() && !IsDeleteProtected(observedObj) {
+			// Delete the object (now) and recreate it (on the next sync)
+			// since it can't be updated in-place
+			err = e.recreateOnImmutableError(ns, observedObj, err)
+			if err != nil {
+				return false, err
+			}
+			return true, nil
+		}
 		if err != nil {
 			return false, err
 		}
-		glog.V(3).Infof("%s: Updated %s", e, DescObjectAsKey(desiredObj))
+		if isRemergeNoop {
+			e.logFor(desiredObj).V(4).Info("Won't update attachment: Nothing changed since conflict")
+			return false, nil
+		}
+		e.logFor(desiredObj).V(3).Info("Updated attachment")
+		e.reportAction("Updated", desiredObj)
+		e.reportApplyDiffs("Updated", observedObj, a.FieldDiffs())
 	default:
 		return false, errors.Errorf(
 			"%s: Invalid update strategy %s for %s",
@@ -471,16 +1342,44 @@ func (e *AttachmentResourcesExecutor) Update(
 	return true, nil
 }
 
+// setUpdatedBy sets the watch details in the annotations of the given
+// attachment i.e. who is responsible for its update
+func (e *AttachmentResourcesExecutor) setUpdatedBy(obj *unstructured.Unstructured) {
+	anns := obj.GetAnnotations()
+	if anns == nil {
+		anns = make(map[string]string)
+	}
+	anns[string(e.Watch.GetUID())+attachmentUpdateAnnotationKeySuffix] =
+		DescObjectAsSanitisedKey(e.Watch)
+	obj.SetAnnotations(anns)
+}
+
 // Create creates the desired attachment
 func (e *AttachmentResourcesExecutor) Create(dObj *unstructured.Unstructured) error {
+	// Don't create if this attachment is meant to be observed only
+	if e.IsReadOnly() {
+		e.logFor(dObj).V(4).Info("Won't create attachment: Attachment is read only")
+		return nil
+	}
+
 	ns := dObj.GetNamespace()
 	if ns == "" {
 		// if desired object has not been given a namespace
 		// then it is set to watch's namespace
 		ns = e.Watch.GetNamespace()
 	}
+	if ns == "" && e.DynamicResourceClient.Namespaced {
+		// this happens if the watch is cluster scoped
+		return errors.Errorf(
+			"%s: Can't create %s: Namespace is required", e, DescObjectAsKey(dObj),
+		)
+	}
 
-	glog.V(4).Infof("%s: Creating %s", e, DescObjectAsKey(dObj))
+	if e.IsServerSideApply() {
+		return e.createViaServerSideApply(ns, dObj)
+	}
+
+	e.logFor(dObj).V(4).Info("Creating attachment")
 
 	// The controller i.e. sync hook should return a partial attachment
 	// containing only the fields it cares about. We save this partial
@@ -488,13 +1387,20 @@ func (e *AttachmentResourcesExecutor) Create(dObj *unstructured.Unstructured) er
 	// of "kubectl apply".
 	//
 	// Make sure this happens before we add anything else to the object.
-	err := dynamicapply.SetLastAppliedByAnnKey(
-		dObj,
-		dObj.UnstructuredContent(),
-		string(e.Watch.GetUID())+lastAppliedAnnotationKeySuffix,
-	)
-	if err != nil {
-		return err
+	//
+	// NOTE:
+	//	This is skipped if the fields of this attachment are tracked
+	// as managed fields of this controller's field manager or if this
+	// attachment is never merged with its last applied state
+	if !e.IsManagedFieldsApply() && !e.IsPartialMerge() {
+		err := dynamicapply.SetLastAppliedByAnnKey(
+			dObj,
+			dObj.UnstructuredContent(),
+			string(e.Watch.GetUID())+lastAppliedAnnotationKeySuffix,
+		)
+		if err != nil {
+			return err
+		}
 	}
 
 	// add create specific annotation
@@ -509,7 +1415,7 @@ func (e *AttachmentResourcesExecutor) Create(dObj *unstructured.Unstructured) er
 
 	// Attachments are set with current watch as
 	// the owner reference if watch is flagged to be the owner
-	if e.IsWatchOwner != nil && *e.IsWatchOwner {
+	if e.IsWatchOwner != nil && *e.IsWatchOwner && e.canWatchOwn(ns) {
 		watchAsOwnerRef := MakeOwnerRef(e.Watch)
 
 		// fetch existing owner references of this attachment
@@ -519,13 +1425,14 @@ func (e *AttachmentResourcesExecutor) Create(dObj *unstructured.Unstructured) er
 		dObj.SetOwnerReferences(ownerRefs)
 	}
 
-	_, err =
-		e.DynamicResourceClient.Namespace(ns).Create(dObj, metav1.CreateOptions{})
+	_, err :=
+		e.DynamicResourceClient.Namespace(ns).Create(dObj, e.createOptions())
 	if err != nil {
 		return err
 	}
 
-	glog.Infof("%s: Created %s", e, DescObjectAsKey(dObj))
+	e.logFor(dObj).Info("Created attachment")
+	e.reportAction("Created", dObj)
 	return nil
 }
 
@@ -541,7 +1448,9 @@ func (e *AttachmentResourcesExecutor) CreateOrUpdate() error {
 			// -------------------------------------------
 			// try update since object already exists
 			// -------------------------------------------
+			_, span := e.startAttachmentSpan(spanUpdateAttachment, oObj)
 			_, err := e.Update(oObj, dObj)
+			tracing.EndSpan(span, err)
 			if err != nil {
 				errs = appendErrIfNotNil(errs, err)
 			}
@@ -549,7 +1458,9 @@ func (e *AttachmentResourcesExecutor) CreateOrUpdate() error {
 			// ----------------------------------------------------
 			// try create since this object is not observed in cluster
 			// ----------------------------------------------------
+			_, span := e.startAttachmentSpan(spanCreateAttachment, dObj)
 			err := e.Create(dObj)
+			tracing.EndSpan(span, err)
 			if err != nil {
 				errs = appendErrIfNotNil(errs, err)
 			}
@@ -559,10 +1470,45 @@ func (e *AttachmentResourcesExecutor) CreateOrUpdate() error {
 	return utilerrors.NewAggregate(errs)
 }
 
+// CountDeletions returns the number of observed attachments that will
+// get deleted by this executor
+//
+// NOTE:
+//	This needs to be in sync with the checks done in Delete
+func (e *AttachmentResourcesExecutor) CountDeletions() int {
+	if e.IsReadOnly() || e.IsCreateOnly() {
+		return 0
+	}
+	deleteAny := e.DeleteAny != nil && *e.DeleteAny
+	var count int
+	for name, obj := range e.Observed {
+		if obj.GetDeletionTimestamp() != nil ||
+			(e.Desired != nil && e.Desired[name] != nil) {
+			continue
+		}
+		gotWatch := obj.GetAnnotations()[attachmentCreateAnnotationKey]
+		if gotWatch != string(e.Watch.GetUID()) && !deleteAny {
+			continue
+		}
+		if IsDeleteProtected(obj) {
+			continue
+		}
+		count++
+	}
+	return count
+}
+
 // Delete will delete the child resources based on observed
 // & owned child resources that are no longer desired
 func (e *AttachmentResourcesExecutor) Delete() error {
 	var errs []error
+	var pruned []string
+
+	// Don't delete if these attachments are meant to be observed only
+	if e.IsReadOnly() {
+		e.logForKind().V(4).Info("Won't delete attachments: Attachments are read only")
+		return nil
+	}
 
 	// check if controller has rights to delete any attachments
 	deleteAny := false
@@ -570,12 +1516,17 @@ func (e *AttachmentResourcesExecutor) Delete() error {
 		deleteAny = *e.DeleteAny
 	}
 
+	// Don't delete if these attachments are owned by the user after
+	// their creation
+	if e.IsCreateOnly() {
+		e.logForKind().V(4).Info("Won't delete attachments: Attachments are create only")
+		return nil
+	}
+
 	for name, obj := range e.Observed {
 		if obj.GetDeletionTimestamp() != nil {
 			// Skip objects that are already pending deletion.
-			glog.V(4).Infof("%s: Can't delete %s: Pending deletion",
-				e, DescObjectAsKey(obj),
-			)
+			e.logFor(obj).V(4).Info("Can't delete attachment: Pending deletion")
 			continue
 		}
 
@@ -590,27 +1541,28 @@ func (e *AttachmentResourcesExecutor) Delete() error {
 
 			if gotWatch != wantWatch && !deleteAny {
 				// Skip objects that was not created due to this watch
-				glog.V(4).Infof(
-					"%s: Can't delete %s: Annotation %s has %q want %q: DeleteAny %t",
-					e,
-					DescObjectAsKey(obj),
-					attachmentCreateAnnotationKey,
-					gotWatch,
-					wantWatch,
-					deleteAny,
+				e.logFor(obj).V(4).Info(
+					"Can't delete attachment: Not created by watch: DeleteAny is not set",
+					"createdByWatchUID", gotWatch,
+					"watchUID", wantWatch,
 				)
 				continue
 			}
 
+			// Skip objects that are protected from deletion
+			if IsDeleteProtected(obj) {
+				e.logFor(obj).V(4).Info("Won't delete attachment: Attachment is protected")
+				e.recordDeleteProtectedEvent(obj)
+				continue
+			}
+
 			// This observed object wasn't listed as desired.
 			// Hence, this is the right candidate to be deleted.
-			glog.V(4).Infof("%s: Deleting %s", e, DescObjectAsKey(obj))
+			e.logFor(obj).V(4).Info("Deleting attachment")
 
 			uid := obj.GetUID()
-			// Explicitly request deletion propagation, which is what
-			// users expect, since some objects default to orphaning
-			// for backwards compatibility.
-			propagation := metav1.DeletePropagationBackground
+			propagation := e.DeletionPropagation()
+			_, span := e.startAttachmentSpan(spanDeleteAttachment, obj)
 			err := e.DynamicResourceClient.Namespace(obj.GetNamespace()).Delete(
 				obj.GetName(),
 				&metav1.DeleteOptions{
@@ -618,11 +1570,10 @@ func (e *AttachmentResourcesExecutor) Delete() error {
 					PropagationPolicy: &propagation,
 				},
 			)
+			tracing.EndSpan(span, err)
 			if err != nil {
 				if apierrors.IsNotFound(err) {
-					glog.V(4).Infof(
-						"%s: Can't delete %s: Is not found: %v",
-						e, DescObjectAsKey(obj), err)
+					e.logFor(obj).V(4).Info("Can't delete attachment: Is not found", "err", err)
 					continue
 				}
 				errs = append(
@@ -632,8 +1583,170 @@ func (e *AttachmentResourcesExecutor) Delete() error {
 				continue
 			}
 
-			glog.Infof("%s: Deleted %s", e, DescObjectAsKey(obj))
+			e.logFor(obj).Info("Deleted attachment")
+			e.reportAction("Deleted", obj)
+			pruned = append(pruned, DescObjectAsKey(obj))
+		}
+	}
+
+	if len(pruned) != 0 {
+		sort.Strings(pruned)
+		e.recordEvent(
+			corev1.EventTypeNormal,
+			EventReasonAttachmentsPruned,
+			"Deleted %d attachments that are no longer desired: %s",
+			len(pruned),
+			strings.Join(pruned, ", "),
+		)
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// Release removes the owner reference & annotations set by the
+// watch against the observed attachments
+func (e *AttachmentResourcesExecutor) Release() error {
+	var errs []error
+	for _, obj := range e.Observed {
+		if obj.GetDeletionTimestamp() != nil {
+			// Skip objects that are already pending deletion.
+			e.logFor(obj).V(4).Info("Can't release attachment: Pending deletion")
+			continue
+		}
+
+		releaseObj := obj.DeepCopy()
+		if !e.releaseFrom(releaseObj) {
+			// nothing was set by this watch
+			continue
+		}
+
+		e.logFor(obj).V(4).Info("Releasing attachment")
+		_, err := e.DynamicResourceClient.Namespace(obj.GetNamespace()).
+			WithAuditDiff(dynamicclientset.AuditDiff(obj, releaseObj)).
+			UpdateWithRetries(
+				releaseObj,
+				metav1.UpdateOptions{},
+				e.MaxUpdateConflictRetries,
+				e.releaseFrom,
+			)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				e.logFor(obj).V(4).Info("Can't release attachment: Is not found", "err", err)
+				continue
+			}
+			errs = append(
+				errs,
+				errors.Wrapf(err, "%s: Failed to release %s", e, DescObjectAsKey(obj)),
+			)
+			continue
+		}
+		e.logFor(obj).Info("Released attachment")
+		e.reportAction("Released", obj)
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// releaseFrom removes the owner reference & annotations set by the
+// watch from the given attachment
+//
+// NOTE:
+//	Return value with bool datatype indicates if the given attachment
+// was changed.
+func (e *AttachmentResourcesExecutor) releaseFrom(obj *unstructured.Unstructured) bool {
+	watchUID := e.Watch.GetUID()
+	isChanged := false
+
+	ann := obj.GetAnnotations()
+	if ann[attachmentCreateAnnotationKey] == string(watchUID) {
+		delete(ann, attachmentCreateAnnotationKey)
+		isChanged = true
+	}
+	for _, key := range []string{
+		string(watchUID) + attachmentUpdateAnnotationKeySuffix,
+		string(watchUID) + lastAppliedAnnotationKeySuffix,
+	} {
+		if _, found := ann[key]; found {
+			delete(ann, key)
+			isChanged = true
+		}
+	}
+
+	var ownerRefs []metav1.OwnerReference
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.UID == watchUID {
+			isChanged = true
+			continue
+		}
+		ownerRefs = append(ownerRefs, ref)
+	}
+
+	if isChanged {
+		obj.SetAnnotations(ann)
+		obj.SetOwnerReferences(ownerRefs)
+	}
+	return isChanged
+}
+
+// DeleteUnowned deletes the observed attachments that were created
+// by the watch but do not have the watch as their owner reference.
+// These attachments are not garbage collected by Kubernetes when the
+// watch is deleted.
+func (e *AttachmentResourcesExecutor) DeleteUnowned() error {
+	var errs []error
+
+	// Don't delete if these attachments are observed only or are
+	// owned by the user after their creation
+	if e.IsReadOnly() || e.IsCreateOnly() {
+		return nil
+	}
+
+	watchUID := e.Watch.GetUID()
+	for _, obj := range e.Observed {
+		if obj.GetDeletionTimestamp() != nil {
+			// Skip objects that are already pending deletion.
+			continue
+		}
+		if obj.GetAnnotations()[attachmentCreateAnnotationKey] != string(watchUID) {
+			// Skip objects that were not created due to this watch
+			continue
+		}
+		isOwned := false
+		for _, ref := range obj.GetOwnerReferences() {
+			if ref.UID == watchUID {
+				isOwned = true
+				break
+			}
+		}
+		if isOwned {
+			// Kubernetes garbage collector will take care
+			continue
+		}
+		if IsDeleteProtected(obj) {
+			e.logFor(obj).V(4).Info("Won't delete attachment: Attachment is protected")
+			e.recordDeleteProtectedEvent(obj)
+			continue
+		}
+
+		e.logFor(obj).V(4).Info("Deleting unowned attachment")
+		uid := obj.GetUID()
+		propagation := e.DeletionPropagation()
+		err := e.DynamicResourceClient.Namespace(obj.GetNamespace()).Delete(
+			obj.GetName(),
+			&metav1.DeleteOptions{
+				Preconditions:     &metav1.Preconditions{UID: &uid},
+				PropagationPolicy: &propagation,
+			},
+		)
+		if err != nil && !apierrors.IsNotFound(err) {
+			errs = append(
+				errs,
+				errors.Wrapf(err, "%s: Failed to delete %s", e, DescObjectAsKey(obj)),
+			)
+			continue
 		}
+		e.logFor(obj).Info("Deleted unowned attachment")
+		e.reportAction("Deleted unowned", obj)
 	}
 
 	return utilerrors.NewAggregate(errs)
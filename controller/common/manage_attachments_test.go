@@ -17,13 +17,23 @@ limitations under the License.
 package common
 
 import (
+	"reflect"
 	"testing"
+	"time"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/apimachinery/pkg/watch"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/rest"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
 	"openebs.io/metac/apis/metacontroller/v1alpha1"
 	dynamicapply "openebs.io/metac/dynamic/apply"
 	dynamicclientset "openebs.io/metac/dynamic/clientset"
@@ -844,3 +854,774 @@ func TestAttachmentResourcesExecutorUpdate(t *testing.T) {
 		})
 	}
 }
+
+func TestAttachmentResourcesExecutorReadOnly(t *testing.T) {
+	executor := &AttachmentResourcesExecutor{
+		AttachmentExecuteBase: AttachmentExecuteBase{
+			GetChildUpdateStrategyByGK: func(group, kind string) v1alpha1.ChildUpdateMethod {
+				return v1alpha1.ChildUpdateInPlace
+			},
+			IsPatchByGK: func(group, kind string) bool {
+				return false
+			},
+			IsReadOnlyByGK: func(group, kind string) bool {
+				return true
+			},
+			Watch: &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"metadata": map[string]interface{}{
+						"uid": "test-watch-uid",
+					},
+				},
+			},
+			UpdateAny: kubernetes.BoolPtr(true),
+			DeleteAny: kubernetes.BoolPtr(true),
+		},
+		DynamicResourceClient: &dynamicclientset.ResourceClient{
+			ResourceInterface: &NoopResourceOperation{},
+			APIResource:       &dynamicdiscovery.APIResource{},
+		},
+	}
+	observed := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": "old value",
+		},
+	}
+	desired := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": "new value",
+		},
+	}
+	isUpdate, err := executor.Update(observed, desired)
+	if err != nil {
+		t.Fatalf("Expected no error: Got %v", err)
+	}
+	if isUpdate {
+		t.Fatalf("Expected no update for read only attachment: Got update")
+	}
+	if !executor.IsReadOnly() {
+		t.Fatalf("Expected read only executor: Got false")
+	}
+}
+
+func TestIsImmutableFieldError(t *testing.T) {
+	var tests = map[string]struct {
+		err    error
+		isTrue bool
+	}{
+		"nil error": {
+			err:    nil,
+			isTrue: false,
+		},
+		"invalid error due to immutable field": {
+			err: apierrors.NewInvalid(
+				schema.GroupKind{Kind: "Job"},
+				"test",
+				field.ErrorList{
+					field.Invalid(
+						field.NewPath("spec", "template"),
+						"new",
+						"field is immutable",
+					),
+				},
+			),
+			isTrue: true,
+		},
+		"invalid error due to other field": {
+			err: apierrors.NewInvalid(
+				schema.GroupKind{Kind: "Job"},
+				"test",
+				field.ErrorList{
+					field.Required(field.NewPath("spec", "template"), ""),
+				},
+			),
+			isTrue: false,
+		},
+		"conflict error": {
+			err: apierrors.NewConflict(
+				schema.GroupResource{Resource: "jobs"},
+				"test",
+				nil,
+			),
+			isTrue: false,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			got := isImmutableFieldError(mock.err)
+			if got != mock.isTrue {
+				t.Fatalf("Expected %t got %t", mock.isTrue, got)
+			}
+		})
+	}
+}
+
+func TestAttachmentResourcesExecutorIsAdoptable(t *testing.T) {
+	var tests = map[string]struct {
+		policy            v1alpha1.GenericControllerAttachmentAdoptPolicy
+		ownerRefs         []metav1.OwnerReference
+		createdByWatchUID string
+		isAdoptable       bool
+	}{
+		"no policy": {
+			isAdoptable: false,
+		},
+		"never": {
+			policy:      v1alpha1.AttachmentAdoptPolicyNever,
+			isAdoptable: false,
+		},
+		"if unowned + unowned": {
+			policy:      v1alpha1.AttachmentAdoptPolicyIfUnowned,
+			isAdoptable: true,
+		},
+		"if unowned + created by other watch": {
+			policy:            v1alpha1.AttachmentAdoptPolicyIfUnowned,
+			createdByWatchUID: "other-watch-uid",
+			isAdoptable:       false,
+		},
+		"if unowned + owned by controller": {
+			policy: v1alpha1.AttachmentAdoptPolicyIfUnowned,
+			ownerRefs: []metav1.OwnerReference{
+				{
+					Name:       "other",
+					UID:        "other-uid",
+					Controller: kubernetes.BoolPtr(true),
+				},
+			},
+			isAdoptable: false,
+		},
+		"if unowned + owned by non controller": {
+			policy: v1alpha1.AttachmentAdoptPolicyIfUnowned,
+			ownerRefs: []metav1.OwnerReference{
+				{
+					Name: "other",
+					UID:  "other-uid",
+				},
+			},
+			isAdoptable: true,
+		},
+		"always + created by other watch": {
+			policy:            v1alpha1.AttachmentAdoptPolicyAlways,
+			createdByWatchUID: "other-watch-uid",
+			isAdoptable:       true,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			executor := &AttachmentResourcesExecutor{
+				AttachmentExecuteBase: AttachmentExecuteBase{
+					GetAdoptPolicyByGK: func(
+						group, kind string,
+					) v1alpha1.GenericControllerAttachmentAdoptPolicy {
+						return mock.policy
+					},
+				},
+				DynamicResourceClient: &dynamicclientset.ResourceClient{
+					ResourceInterface: &NoopResourceOperation{},
+					APIResource:       &dynamicdiscovery.APIResource{},
+				},
+			}
+			observed := &unstructured.Unstructured{
+				Object: map[string]interface{}{},
+			}
+			observed.SetOwnerReferences(mock.ownerRefs)
+			got := executor.isAdoptable(observed, mock.createdByWatchUID)
+			if got != mock.isAdoptable {
+				t.Fatalf("Expected adoptable %t got %t", mock.isAdoptable, got)
+			}
+		})
+	}
+}
+
+type RecordUpdateResourceOperation struct {
+	NoopResourceOperation
+
+	updated []*unstructured.Unstructured
+}
+
+func (r *RecordUpdateResourceOperation) Update(obj *unstructured.Unstructured, options metav1.UpdateOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	r.updated = append(r.updated, obj)
+	return obj, nil
+}
+
+func TestAttachmentResourcesExecutorRelease(t *testing.T) {
+	watch := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"uid": "test-watch-uid",
+			},
+		},
+	}
+	owned := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name": "owned",
+				"annotations": map[string]interface{}{
+					attachmentCreateAnnotationKey:                     "test-watch-uid",
+					"test-watch-uid" + lastAppliedAnnotationKeySuffix: "{}",
+					"hello": "world",
+				},
+				"ownerReferences": []interface{}{
+					map[string]interface{}{
+						"apiVersion": "v1",
+						"kind":       "Watch",
+						"name":       "watch",
+						"uid":        "test-watch-uid",
+					},
+					map[string]interface{}{
+						"apiVersion": "v1",
+						"kind":       "Other",
+						"name":       "other",
+						"uid":        "other-uid",
+					},
+				},
+			},
+		},
+	}
+	notOwned := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name": "not-owned",
+				"annotations": map[string]interface{}{
+					attachmentCreateAnnotationKey: "other-watch-uid",
+				},
+			},
+		},
+	}
+	op := &RecordUpdateResourceOperation{}
+	executor := &AttachmentResourcesExecutor{
+		AttachmentExecuteBase: AttachmentExecuteBase{
+			Watch: watch,
+		},
+		DynamicResourceClient: &dynamicclientset.ResourceClient{
+			ResourceInterface: op,
+			APIResource:       &dynamicdiscovery.APIResource{},
+		},
+		Observed: map[string]*unstructured.Unstructured{
+			"owned":     owned,
+			"not-owned": notOwned,
+		},
+	}
+	err := executor.Release()
+	if err != nil {
+		t.Fatalf("Expected no error: Got %v", err)
+	}
+	if len(op.updated) != 1 {
+		t.Fatalf("Expected 1 update: Got %d", len(op.updated))
+	}
+	got := op.updated[0]
+	if got.GetName() != "owned" {
+		t.Fatalf("Expected update of owned: Got %s", got.GetName())
+	}
+	wantAnns := map[string]string{"hello": "world"}
+	if !reflect.DeepEqual(got.GetAnnotations(), wantAnns) {
+		t.Fatalf("Expected annotations %v: Got %v", wantAnns, got.GetAnnotations())
+	}
+	refs := got.GetOwnerReferences()
+	if len(refs) != 1 || refs[0].UID != "other-uid" {
+		t.Fatalf("Expected only other-uid as owner reference: Got %v", refs)
+	}
+	// observed instance should not be mutated
+	if len(owned.GetOwnerReferences()) != 2 {
+		t.Fatalf("Expected observed instance to be unchanged: Got %v", owned)
+	}
+}
+
+func TestIsProtected(t *testing.T) {
+	var tests = map[string]struct {
+		anns              map[string]string
+		isDeleteProtected bool
+		isUpdateProtected bool
+	}{
+		"no annotations": {},
+		"protect true": {
+			anns: map[string]string{
+				AttachmentProtectAnnotationKey: "true",
+			},
+			isDeleteProtected: true,
+		},
+		"protect all": {
+			anns: map[string]string{
+				AttachmentProtectAnnotationKey: "all",
+			},
+			isDeleteProtected: true,
+			isUpdateProtected: true,
+		},
+		"protect false": {
+			anns: map[string]string{
+				AttachmentProtectAnnotationKey: "false",
+			},
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			obj := &unstructured.Unstructured{
+				Object: map[string]interface{}{},
+			}
+			obj.SetAnnotations(mock.anns)
+			if IsDeleteProtected(obj) != mock.isDeleteProtected {
+				t.Fatalf(
+					"Expected delete protected %t got %t",
+					mock.isDeleteProtected, IsDeleteProtected(obj),
+				)
+			}
+			if IsUpdateProtected(obj) != mock.isUpdateProtected {
+				t.Fatalf(
+					"Expected update protected %t got %t",
+					mock.isUpdateProtected, IsUpdateProtected(obj),
+				)
+			}
+		})
+	}
+}
+
+func TestAttachmentResourcesExecutorCanWatchOwn(t *testing.T) {
+	var tests = map[string]struct {
+		watchNamespace      string
+		isNamespaced        bool
+		attachmentNamespace string
+		canOwn              bool
+	}{
+		"cluster scoped watch + namespaced attachment": {
+			isNamespaced:        true,
+			attachmentNamespace: "tenant",
+			canOwn:              true,
+		},
+		"cluster scoped watch + cluster scoped attachment": {
+			canOwn: true,
+		},
+		"namespaced watch + attachment in same namespace": {
+			watchNamespace:      "control",
+			isNamespaced:        true,
+			attachmentNamespace: "control",
+			canOwn:              true,
+		},
+		"namespaced watch + attachment in other namespace": {
+			watchNamespace:      "control",
+			isNamespaced:        true,
+			attachmentNamespace: "tenant",
+			canOwn:              false,
+		},
+		"namespaced watch + cluster scoped attachment": {
+			watchNamespace: "control",
+			canOwn:         false,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			watch := &unstructured.Unstructured{
+				Object: map[string]interface{}{},
+			}
+			watch.SetNamespace(mock.watchNamespace)
+			executor := &AttachmentResourcesExecutor{
+				AttachmentExecuteBase: AttachmentExecuteBase{
+					Watch: watch,
+				},
+				DynamicResourceClient: &dynamicclientset.ResourceClient{
+					ResourceInterface: &NoopResourceOperation{},
+					APIResource: &dynamicdiscovery.APIResource{
+						APIResource: metav1.APIResource{
+							Namespaced: mock.isNamespaced,
+						},
+					},
+				},
+			}
+			got := executor.canWatchOwn(mock.attachmentNamespace)
+			if got != mock.canOwn {
+				t.Fatalf("Expected can own %t got %t", mock.canOwn, got)
+			}
+		})
+	}
+}
+
+func TestAttachmentResourcesExecutorCountDeletions(t *testing.T) {
+	watch := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"uid": "test-watch-uid",
+			},
+		},
+	}
+	newObj := func(name, createdBy, protect string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetName(name)
+		anns := map[string]string{attachmentCreateAnnotationKey: createdBy}
+		if protect != "" {
+			anns[AttachmentProtectAnnotationKey] = protect
+		}
+		obj.SetAnnotations(anns)
+		return obj
+	}
+	observed := map[string]*unstructured.Unstructured{
+		"desired":   newObj("desired", "test-watch-uid", ""),
+		"owned":     newObj("owned", "test-watch-uid", ""),
+		"protected": newObj("protected", "test-watch-uid", AttachmentProtectDelete),
+		"not-owned": newObj("not-owned", "other-watch-uid", ""),
+	}
+	desired := map[string]*unstructured.Unstructured{
+		"desired": newObj("desired", "", ""),
+	}
+	var tests = map[string]struct {
+		deleteAny *bool
+		readOnly  bool
+		expect    int
+	}{
+		"owned attachments only": {
+			expect: 1,
+		},
+		"delete any": {
+			deleteAny: kubernetes.BoolPtr(true),
+			expect:    2,
+		},
+		"read only": {
+			readOnly: true,
+			expect:   0,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			executor := &AttachmentResourcesExecutor{
+				AttachmentExecuteBase: AttachmentExecuteBase{
+					Watch:     watch,
+					DeleteAny: mock.deleteAny,
+					IsReadOnlyByGK: func(apiGroup, kind string) bool {
+						return mock.readOnly
+					},
+				},
+				DynamicResourceClient: &dynamicclientset.ResourceClient{
+					ResourceInterface: NoopResourceOperation{},
+					APIResource:       &dynamicdiscovery.APIResource{},
+				},
+				Observed: observed,
+				Desired:  desired,
+			}
+			got := executor.CountDeletions()
+			if got != mock.expect {
+				t.Fatalf("Expected deletions %d got %d", mock.expect, got)
+			}
+		})
+	}
+}
+
+func TestAttachmentResourcesExecutorReportApplyDiffs(t *testing.T) {
+	var tests = map[string]struct {
+		watchAnns map[string]string
+		diffs     []dynamicapply.FieldDiff
+		isEvent   bool
+		isCalled  bool
+	}{
+		"no diffs": {
+			watchAnns: map[string]string{ReportApplyDiffsAnnotationKey: "true"},
+		},
+		"diffs without annotation": {
+			diffs:    []dynamicapply.FieldDiff{{Path: "spec.a", Operation: dynamicapply.FieldDiffAdd}},
+			isCalled: true,
+		},
+		"diffs with annotation": {
+			watchAnns: map[string]string{ReportApplyDiffsAnnotationKey: "true"},
+			diffs:     []dynamicapply.FieldDiff{{Path: "spec.a", Operation: dynamicapply.FieldDiffAdd}},
+			isEvent:   true,
+			isCalled:  true,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			watch := &unstructured.Unstructured{Object: map[string]interface{}{}}
+			watch.SetAnnotations(mock.watchAnns)
+			recorder := record.NewFakeRecorder(1)
+			var isCalled bool
+			executor := &AttachmentResourcesExecutor{
+				AttachmentExecuteBase: AttachmentExecuteBase{
+					Watch:         watch,
+					EventRecorder: recorder,
+					OnApplyDiffFn: func(obj *unstructured.Unstructured, diffs []dynamicapply.FieldDiff) {
+						isCalled = true
+					},
+				},
+			}
+			executor.reportApplyDiffs(
+				"Updated", &unstructured.Unstructured{Object: map[string]interface{}{}}, mock.diffs,
+			)
+			if isCalled != mock.isCalled {
+				t.Fatalf("Expected callback %t got %t", mock.isCalled, isCalled)
+			}
+			if isEvent := len(recorder.Events) == 1; isEvent != mock.isEvent {
+				t.Fatalf("Expected event %t got %t", mock.isEvent, isEvent)
+			}
+		})
+	}
+}
+
+// deleteRecorder records the options of each delete invoked against it
+type deleteRecorder struct {
+	NoopResourceOperation
+	deleted map[string]metav1.DeleteOptions
+}
+
+func (r *deleteRecorder) Delete(name string, options *metav1.DeleteOptions, subresources ...string) error {
+	r.deleted[name] = *options
+	return nil
+}
+
+func TestAttachmentResourcesExecutorCreateOnly(t *testing.T) {
+	watch := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"uid": "test-watch-uid",
+			},
+		},
+	}
+	obj := &unstructured.Unstructured{}
+	obj.SetName("not-desired")
+	obj.SetAnnotations(map[string]string{
+		attachmentCreateAnnotationKey: "test-watch-uid",
+	})
+	recorder := &deleteRecorder{deleted: map[string]metav1.DeleteOptions{}}
+	executor := &AttachmentResourcesExecutor{
+		AttachmentExecuteBase: AttachmentExecuteBase{
+			Watch: watch,
+			IsCreateOnlyByGK: func(group, kind string) bool {
+				return true
+			},
+		},
+		DynamicResourceClient: &dynamicclientset.ResourceClient{
+			ResourceInterface: recorder,
+			APIResource:       &dynamicdiscovery.APIResource{},
+		},
+		Observed: map[string]*unstructured.Unstructured{
+			"not-desired": obj,
+		},
+	}
+	observed := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": "old value",
+		},
+	}
+	desired := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": "new value",
+		},
+	}
+	isUpdate, err := executor.Update(observed, desired)
+	if err != nil {
+		t.Fatalf("Expected no error: Got %v", err)
+	}
+	if isUpdate {
+		t.Fatalf("Expected no update for create only attachment: Got update")
+	}
+	err = executor.Delete()
+	if err != nil {
+		t.Fatalf("Expected no error: Got %v", err)
+	}
+	if len(recorder.deleted) != 0 {
+		t.Fatalf("Expected no deletes for create only attachments: Got %v", recorder.deleted)
+	}
+	if executor.CountDeletions() != 0 {
+		t.Fatalf("Expected no deletions for create only attachments: Got %d", executor.CountDeletions())
+	}
+}
+
+func TestAttachmentResourcesExecutorDeletionPropagation(t *testing.T) {
+	watch := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"uid": "test-watch-uid",
+			},
+		},
+	}
+	var tests = map[string]struct {
+		getPropagation func(group, kind string) metav1.DeletionPropagation
+		expect         metav1.DeletionPropagation
+	}{
+		"default propagation": {
+			expect: metav1.DeletePropagationBackground,
+		},
+		"foreground propagation": {
+			getPropagation: func(group, kind string) metav1.DeletionPropagation {
+				return metav1.DeletePropagationForeground
+			},
+			expect: metav1.DeletePropagationForeground,
+		},
+		"orphan propagation": {
+			getPropagation: func(group, kind string) metav1.DeletionPropagation {
+				return metav1.DeletePropagationOrphan
+			},
+			expect: metav1.DeletePropagationOrphan,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			obj := &unstructured.Unstructured{}
+			obj.SetName("not-desired")
+			obj.SetAnnotations(map[string]string{
+				attachmentCreateAnnotationKey: "test-watch-uid",
+			})
+			recorder := &deleteRecorder{deleted: map[string]metav1.DeleteOptions{}}
+			executor := &AttachmentResourcesExecutor{
+				AttachmentExecuteBase: AttachmentExecuteBase{
+					Watch:                      watch,
+					GetDeletionPropagationByGK: mock.getPropagation,
+				},
+				DynamicResourceClient: &dynamicclientset.ResourceClient{
+					ResourceInterface: recorder,
+					APIResource:       &dynamicdiscovery.APIResource{},
+				},
+				Observed: map[string]*unstructured.Unstructured{
+					"not-desired": obj,
+				},
+			}
+			err := executor.Delete()
+			if err != nil {
+				t.Fatalf("Expected no error got %v", err)
+			}
+			options, found := recorder.deleted["not-desired"]
+			if !found {
+				t.Fatalf("Expected attachment to be deleted got none")
+			}
+			if options.PropagationPolicy == nil ||
+				*options.PropagationPolicy != mock.expect {
+				t.Fatalf(
+					"Expected propagation %q got %v", mock.expect, options.PropagationPolicy,
+				)
+			}
+		})
+	}
+}
+
+func intPtr(val int) *int {
+	return &val
+}
+
+// newFakeClientset returns a clientset whose resources are discovered
+// from the given resource lists. The returned function stops the
+// discovery.
+func newFakeClientset(
+	t *testing.T, resources ...*metav1.APIResourceList,
+) (*dynamicclientset.Clientset, func()) {
+	resourceMgr := dynamicdiscovery.NewAPIResourceManager(
+		&fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{Resources: resources}},
+	)
+	resourceMgr.Start(time.Hour)
+	err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return resourceMgr.HasSynced(), nil
+	})
+	if err != nil {
+		t.Fatalf("Can't discover fake resources: %v", err)
+	}
+	cs, err := dynamicclientset.New(&rest.Config{Host: "http://localhost"}, resourceMgr)
+	if err != nil {
+		t.Fatalf("Can't create fake clientset: %v", err)
+	}
+	return cs, resourceMgr.Stop
+}
+
+func TestAttachmentManagerVerifyDeletionLimit(t *testing.T) {
+	cs, stop := newFakeClientset(t, &metav1.APIResourceList{
+		GroupVersion: "v1",
+		APIResources: []metav1.APIResource{
+			{Name: "pods", Kind: "Pod", Namespaced: true},
+		},
+	})
+	defer stop()
+	newWatch := func(isDeleting bool, annotations map[string]string) *unstructured.Unstructured {
+		watch := &unstructured.Unstructured{}
+		watch.SetUID("test-watch-uid")
+		watch.SetAnnotations(annotations)
+		if isDeleting {
+			now := metav1.Now()
+			watch.SetDeletionTimestamp(&now)
+		}
+		return watch
+	}
+	newPod := func(name string) *unstructured.Unstructured {
+		pod := &unstructured.Unstructured{}
+		pod.SetAPIVersion("v1")
+		pod.SetKind("Pod")
+		pod.SetName(name)
+		pod.SetAnnotations(map[string]string{
+			attachmentCreateAnnotationKey: "test-watch-uid",
+		})
+		return pod
+	}
+	var tests = map[string]struct {
+		watch        *unstructured.Unstructured
+		maxDeletions *int
+		isErr        bool
+	}{
+		"no limit": {
+			watch: newWatch(false, nil),
+			isErr: false,
+		},
+		"deletions within limit": {
+			watch:        newWatch(false, nil),
+			maxDeletions: intPtr(2),
+			isErr:        false,
+		},
+		"deletions beyond limit": {
+			watch:        newWatch(false, nil),
+			maxDeletions: intPtr(1),
+			isErr:        true,
+		},
+		"zero limit": {
+			watch:        newWatch(false, nil),
+			maxDeletions: intPtr(0),
+			isErr:        true,
+		},
+		"deletions beyond limit of a watch that skips the limit": {
+			watch: newWatch(false, map[string]string{
+				SkipDeletionLimitAnnotationKey: "true",
+			}),
+			maxDeletions: intPtr(1),
+			isErr:        false,
+		},
+		"deletions beyond limit of a watch being deleted": {
+			watch:        newWatch(true, nil),
+			maxDeletions: intPtr(0),
+			isErr:        false,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			observed := AnyUnstructRegistry{}
+			observed.InsertByReference(mock.watch, newPod("pod-1"))
+			observed.InsertByReference(mock.watch, newPod("pod-2"))
+			recorder := record.NewFakeRecorder(1)
+			m := &AttachmentManager{
+				AttachmentExecuteBase: AttachmentExecuteBase{
+					Watch:         mock.watch,
+					EventRecorder: recorder,
+				},
+				DynamicClientSet: cs,
+				Observed:         observed,
+				Desired:          AnyUnstructRegistry{},
+				MaxDeletions:     mock.maxDeletions,
+			}
+			err := m.verifyDeletionLimit()
+			if mock.isErr && err == nil {
+				t.Fatalf("Expected error got none")
+			}
+			if !mock.isErr && err != nil {
+				t.Fatalf("Expected no error got %+v", err)
+			}
+			if mock.isErr && len(recorder.Events) != 1 {
+				t.Fatalf("Expected deletion limit event got none")
+			}
+		})
+	}
+}
@@ -19,6 +19,7 @@ package common
 import (
 	"fmt"
 	"reflect"
+	"strings"
 
 	"github.com/golang/glog"
 	"github.com/google/go-cmp/cmp"
@@ -56,6 +57,54 @@ type Apply struct {
 	//	This is typically invoked before calling SetLastAppliedFn
 	SanitizeLastAppliedFn func(lastApplied map[string]interface{})
 
+	// ListMapKeys are the merge keys of the list fields anchored by
+	// the dot separated paths of these fields
+	//
+	// NOTE:
+	//	Lists that are not declared here are merged by well known
+	// keys if possible & are replaced otherwise
+	ListMapKeys map[string]string
+
+	// IsStrategicMerge when set to true merges the built-in kinds of
+	// core, apps & batch api groups via strategic merge patch based on
+	// their compiled-in types. ListMapKeys are not used for such kinds.
+	//
+	// NOTE:
+	//	Other kinds are merged via generic 3-way merge
+	IsStrategicMerge bool
+
+	// IgnorePaths are the fields that are reverted to their observed
+	// values after merge. These fields are never updated & never
+	// result in a diff.
+	IgnorePaths [][]string
+
+	// ConflictsFn returns the paths of the desired fields that were
+	// changed by someone else since these were last applied
+	//
+	// NOTE:
+	//	Conflicts are not detected if this is nil
+	ConflictsFn func(
+		observed, desired *unstructured.Unstructured, lastApplied map[string]interface{},
+	) ([][]string, error)
+
+	// IsYieldConflicts when set to true reverts the conflicting fields
+	// to their observed values after merge
+	IsYieldConflicts bool
+
+	// conflicts are the paths of the desired fields that were changed
+	// by someone else
+	//
+	// NOTE:
+	//	This should be used after invoking Merge operation
+	conflicts [][]string
+
+	// diffs are the fields that differ between observed & merged
+	// states
+	//
+	// NOTE:
+	//	This should be used after invoking Merge operation
+	diffs []dynamicapply.FieldDiff
+
 	// isRun is set to true if Merge operation was invoked sucessfully
 	isRun bool
 
@@ -88,6 +137,41 @@ func NewApplyFromAnnKey(key string) *Apply {
 	}
 }
 
+// NewApplyFromManagedFields returns a new instance of Apply that
+// derives the last applied state from the managed fields of the given
+// field manager
+//
+// NOTE:
+//	Last applied state is not stored against the object. API server
+// tracks the fields set by this manager provided the updates are sent
+// with this manager.
+func NewApplyFromManagedFields(manager string) *Apply {
+	return &Apply{
+		GetLastAppliedFn: func(o *unstructured.Unstructured) (map[string]interface{}, error) {
+			return dynamicapply.GetManagedFieldsState(o, manager)
+		},
+		SetLastAppliedFn: func(o *unstructured.Unstructured, last map[string]interface{}) error {
+			// a no-op
+			return nil
+		},
+	}
+}
+
+// NewPartialApply returns a new instance of Apply that merges the
+// desired state onto the observed state without a last applied state.
+// Hence fields that are not desired are never removed.
+func NewPartialApply() *Apply {
+	return &Apply{
+		GetLastAppliedFn: func(o *unstructured.Unstructured) (map[string]interface{}, error) {
+			return nil, nil
+		},
+		SetLastAppliedFn: func(o *unstructured.Unstructured, last map[string]interface{}) error {
+			// a no-op
+			return nil
+		},
+	}
+}
+
 // Merge applies the update against the original object in the
 // style of kubectl apply
 func (a *Apply) Merge(
@@ -124,12 +208,16 @@ func (a *Apply) merge(
 		return nil, err
 	}
 
+	a.conflicts = nil
+	if a.ConflictsFn != nil {
+		a.conflicts, err = a.ConflictsFn(observed, desired, lastApplied)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to find conflicts")
+		}
+	}
+
 	merged := &unstructured.Unstructured{}
-	merged.Object, err = dynamicapply.Merge(
-		observed.UnstructuredContent(),
-		lastApplied,
-		desired.UnstructuredContent(),
-	)
+	merged.Object, err = a.mergeContent(observed, lastApplied, desired)
 	if err != nil {
 		return nil, err
 	}
@@ -151,6 +239,31 @@ func (a *Apply) merge(
 		return nil, errors.Wrapf(err, "Failed to revert .status")
 	}
 
+	// Revert the fields that should be left alone e.g. fields mutated
+	// by other controllers
+	for _, path := range a.IgnorePaths {
+		if err := revertField(merged, observed, path...); err != nil {
+			return nil, errors.Wrapf(
+				err, "Failed to revert ignored field %s", strings.Join(path, "."),
+			)
+		}
+	}
+
+	// ignored fields are never applied & hence are never conflicts
+	a.conflicts = withoutPaths(a.conflicts, a.IgnorePaths)
+
+	// Revert the fields that were changed by someone else if this
+	// controller yields to such changes
+	if a.IsYieldConflicts {
+		for _, path := range a.conflicts {
+			if err := revertField(merged, observed, path...); err != nil {
+				return nil, errors.Wrapf(
+					err, "Failed to revert conflicting field %s", strings.Join(path, "."),
+				)
+			}
+		}
+	}
+
 	// set flags to let consumers of this function take appropriate decisions
 	//
 	// One of the examples of consumers using these flags can be checking
@@ -160,7 +273,11 @@ func (a *Apply) merge(
 	a.isEqual =
 		reflect.DeepEqual(merged.UnstructuredContent(), observed.UnstructuredContent())
 
+	a.diffs = nil
 	if !a.isEqual {
+		a.diffs = dynamicapply.ComputeFieldDiffs(
+			observed.UnstructuredContent(), merged.UnstructuredContent(),
+		)
 		// log the diff if verbose log level is enabled
 		glog.V(5).Infof(
 			"Desired %s: Diff: a=observed, b=new:\n%s",
@@ -192,6 +309,20 @@ func (a *Apply) HasMergeDiff() (bool, error) {
 	return !a.isEqual, nil
 }
 
+// FieldDiffs returns the fields that differ between observed &
+// merged states. This is nil if Merge invocation did not find any
+// differences.
+func (a *Apply) FieldDiffs() []dynamicapply.FieldDiff {
+	return a.diffs
+}
+
+// Conflicts returns the paths of the desired fields that were changed
+// by someone else since these were last applied. Ignored fields are
+// not part of these conflicts.
+func (a *Apply) Conflicts() [][]string {
+	return a.conflicts
+}
+
 // objectMetaSystemFields is a list of JSON field names within ObjectMeta
 // that are both read-only and system-populated according to the comments in
 // k8s.io/apimachinery/pkg/apis/meta/v1/types.go.
@@ -245,6 +376,56 @@ func revertField(newObj, orig *unstructured.Unstructured, fieldPath ...string) e
 	return nil
 }
 
+// mergeContent merges the given desired state onto the given observed
+// state based on the given last applied state
+func (a *Apply) mergeContent(
+	observed *unstructured.Unstructured,
+	lastApplied map[string]interface{},
+	desired *unstructured.Unstructured,
+) (map[string]interface{}, error) {
+	if a.IsStrategicMerge {
+		schema, ok := dynamicapply.NewStrategicMergeSchema(
+			observed.GetAPIVersion(), observed.GetKind(),
+		)
+		if ok {
+			return dynamicapply.StrategicMerge(
+				observed.UnstructuredContent(),
+				lastApplied,
+				desired.UnstructuredContent(),
+				schema,
+			)
+		}
+	}
+	return dynamicapply.MergeWithListMapKeys(
+		observed.UnstructuredContent(),
+		lastApplied,
+		desired.UnstructuredContent(),
+		a.ListMapKeys,
+	)
+}
+
+// withoutPaths returns the given paths excluding the ones that are
+// same as or nested under any of the given excluded paths
+func withoutPaths(paths, excluded [][]string) [][]string {
+	if len(paths) == 0 || len(excluded) == 0 {
+		return paths
+	}
+	var filtered [][]string
+	for _, path := range paths {
+		isExcluded := false
+		for _, prefix := range excluded {
+			if len(prefix) <= len(path) && reflect.DeepEqual(prefix, path[:len(prefix)]) {
+				isExcluded = true
+				break
+			}
+		}
+		if !isExcluded {
+			filtered = append(filtered, path)
+		}
+	}
+	return filtered
+}
+
 // MakeOwnerRef builds & returns a new instance of OwnerReference
 // from the given unstruct instance
 func MakeOwnerRef(obj *unstructured.Unstructured) *metav1.OwnerReference {
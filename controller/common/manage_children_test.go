@@ -1157,3 +1157,111 @@ func TestRevertObjectMetaSystemFields(t *testing.T) {
 		t.Fatalf("revertObjectMetaSystemFields() = %#v, want %#v", got, want)
 	}
 }
+
+func TestApplyMergeWithIgnorePaths(t *testing.T) {
+	tests := map[string]struct {
+		observed string
+		desired  string
+		want     string
+		isDiff   bool
+	}{
+		"ignored field differs": {
+			observed: `{"spec": {"replicas": 5, "image": "old"}}`,
+			desired:  `{"spec": {"replicas": 1, "image": "old"}}`,
+			want:     `{"spec": {"replicas": 5, "image": "old"}}`,
+		},
+		"ignored field is missing in observed": {
+			observed: `{"spec": {"image": "old"}}`,
+			desired:  `{"spec": {"replicas": 1, "image": "old"}}`,
+			want:     `{"spec": {"image": "old"}}`,
+		},
+		"other field differs": {
+			observed: `{"spec": {"replicas": 5, "image": "old"}}`,
+			desired:  `{"spec": {"replicas": 1, "image": "new"}}`,
+			want:     `{"spec": {"replicas": 5, "image": "new"}}`,
+			isDiff:   true,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			observed := &unstructured.Unstructured{}
+			if err := json.Unmarshal([]byte(mock.observed), &observed.Object); err != nil {
+				t.Fatalf("Can't unmarshal observed: %v", err)
+			}
+			desired := &unstructured.Unstructured{}
+			if err := json.Unmarshal([]byte(mock.desired), &desired.Object); err != nil {
+				t.Fatalf("Can't unmarshal desired: %v", err)
+			}
+			want := make(map[string]interface{})
+			if err := json.Unmarshal([]byte(mock.want), &want); err != nil {
+				t.Fatalf("Can't unmarshal want: %v", err)
+			}
+			a := NewApplyFromAnnKey("last-applied-state")
+			a.IgnorePaths = [][]string{{"spec", "replicas"}}
+			got, err := a.Merge(observed, desired)
+			if err != nil {
+				t.Fatalf("Can't merge: %+v", err)
+			}
+			// last applied state is not verified
+			unstructured.RemoveNestedField(got.Object, "metadata")
+			if !reflect.DeepEqual(got.Object, want) {
+				t.Fatalf("Expected no diff: a=got, b=want:\n%s", cmp.Diff(got.Object, want))
+			}
+			if isDiff, _ := a.HasMergeDiff(); isDiff != mock.isDiff {
+				t.Fatalf("Expected diff %t got %t", mock.isDiff, isDiff)
+			}
+		})
+	}
+}
+
+func TestPartialApplyMerge(t *testing.T) {
+	tests := map[string]struct {
+		observed string
+		desired  string
+		want     string
+		isDiff   bool
+	}{
+		"fields managed by others are retained": {
+			observed: `{"metadata": {"annotations": {"last-applied-state": "{\"spec\":{\"a\":\"1\",\"b\":\"1\"}}"}}, "spec": {"a": "1", "b": "1", "c": "1"}}`,
+			desired:  `{"spec": {"a": "1"}}`,
+			want:     `{"metadata": {"annotations": {"last-applied-state": "{\"spec\":{\"a\":\"1\",\"b\":\"1\"}}"}}, "spec": {"a": "1", "b": "1", "c": "1"}}`,
+		},
+		"desired fields are merged": {
+			observed: `{"spec": {"a": "1", "containers": [{"name": "c1", "image": "v1"}, {"name": "c2", "image": "v1"}]}}`,
+			desired:  `{"spec": {"containers": [{"name": "c2", "image": "v2"}]}}`,
+			want:     `{"spec": {"a": "1", "containers": [{"name": "c1", "image": "v1"}, {"name": "c2", "image": "v2"}]}}`,
+			isDiff:   true,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			observed := &unstructured.Unstructured{}
+			if err := json.Unmarshal([]byte(mock.observed), &observed.Object); err != nil {
+				t.Fatalf("Can't unmarshal observed: %v", err)
+			}
+			desired := &unstructured.Unstructured{}
+			if err := json.Unmarshal([]byte(mock.desired), &desired.Object); err != nil {
+				t.Fatalf("Can't unmarshal desired: %v", err)
+			}
+			want := make(map[string]interface{})
+			if err := json.Unmarshal([]byte(mock.want), &want); err != nil {
+				t.Fatalf("Can't unmarshal want: %v", err)
+			}
+			a := NewPartialApply()
+			got, err := a.Merge(observed, desired)
+			if err != nil {
+				t.Fatalf("Can't merge: %+v", err)
+			}
+			if !reflect.DeepEqual(got.Object, want) {
+				t.Fatalf("Expected no diff: a=got, b=want:\n%s", cmp.Diff(got.Object, want))
+			}
+			if isDiff, _ := a.HasMergeDiff(); isDiff != mock.isDiff {
+				t.Fatalf("Expected diff %t got %t", mock.isDiff, isDiff)
+			}
+		})
+	}
+}
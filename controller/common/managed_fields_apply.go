@@ -0,0 +1,54 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+)
+
+// IsManagedFieldsApply returns true if the attachments handled by
+// this executor should be applied via a 3-way merge whose last
+// applied state is derived from managed fields
+func (e AttachmentResourcesExecutor) IsManagedFieldsApply() bool {
+	return e.ApplyStrategy == v1alpha1.ApplyStrategyManagedFields
+}
+
+// createOptions returns the options used to create attachments
+//
+// NOTE:
+//	Field manager is set to let the API server track the fields
+// owned by this controller
+func (e AttachmentResourcesExecutor) createOptions() metav1.CreateOptions {
+	if !e.IsManagedFieldsApply() {
+		return metav1.CreateOptions{}
+	}
+	return metav1.CreateOptions{FieldManager: e.FieldManager}
+}
+
+// updateOptions returns the options used to update attachments
+//
+// NOTE:
+//	Field manager is set to let the API server track the fields
+// owned by this controller
+func (e AttachmentResourcesExecutor) updateOptions() metav1.UpdateOptions {
+	if !e.IsManagedFieldsApply() {
+		return metav1.UpdateOptions{}
+	}
+	return metav1.UpdateOptions{FieldManager: e.FieldManager}
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+	dynamicclientset "openebs.io/metac/dynamic/clientset"
+	dynamicdiscovery "openebs.io/metac/dynamic/discovery"
+	"openebs.io/metac/third_party/kubernetes"
+)
+
+type RecordUpdateOptionsResourceOperation struct {
+	RecordUpdateResourceOperation
+
+	options []metav1.UpdateOptions
+}
+
+func (r *RecordUpdateOptionsResourceOperation) Update(obj *unstructured.Unstructured, options metav1.UpdateOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	r.options = append(r.options, options)
+	return r.RecordUpdateResourceOperation.Update(obj, options, subresources...)
+}
+
+func TestAttachmentResourcesExecutorUpdateWithManagedFields(t *testing.T) {
+	op := &RecordUpdateOptionsResourceOperation{}
+	executor := &AttachmentResourcesExecutor{
+		AttachmentExecuteBase: AttachmentExecuteBase{
+			GetChildUpdateStrategyByGK: func(group, kind string) v1alpha1.ChildUpdateMethod {
+				return v1alpha1.ChildUpdateInPlace
+			},
+			IsPatchByGK: func(group, kind string) bool {
+				return false
+			},
+			Watch: &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"metadata": map[string]interface{}{
+						"name": "watch",
+						"uid":  "test-watch-uid",
+					},
+				},
+			},
+			UpdateAny:     kubernetes.BoolPtr(true),
+			ApplyStrategy: v1alpha1.ApplyStrategyManagedFields,
+			FieldManager:  "test-manager",
+		},
+		DynamicResourceClient: &dynamicclientset.ResourceClient{
+			ResourceInterface: op,
+			APIResource:       &dynamicdiscovery.APIResource{},
+		},
+	}
+	// observed attachment carries a poisoned last applied annotation
+	// & a label that is managed by some other tool
+	observed := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name": "attachment",
+				"annotations": map[string]interface{}{
+					"test-watch-uid" + lastAppliedAnnotationKeySuffix: `{"metadata":{"labels":{"team":"other"}}}`,
+				},
+				"labels": map[string]interface{}{
+					"team": "other",
+				},
+			},
+			"spec": map[string]interface{}{
+				"old": "value",
+				"new": "old value",
+			},
+		},
+	}
+	observed.SetManagedFields([]metav1.ManagedFieldsEntry{
+		{
+			Manager:    "other-manager",
+			Operation:  metav1.ManagedFieldsOperationUpdate,
+			FieldsType: "FieldsV1",
+			FieldsV1: &metav1.FieldsV1{
+				Raw: []byte(`{"f:metadata": {"f:labels": {"f:team": {}}}}`),
+			},
+		},
+		{
+			Manager:    "test-manager",
+			Operation:  metav1.ManagedFieldsOperationUpdate,
+			FieldsType: "FieldsV1",
+			FieldsV1: &metav1.FieldsV1{
+				Raw: []byte(`{"f:spec": {"f:old": {}, "f:new": {}}}`),
+			},
+		},
+	})
+	desired := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name": "attachment",
+			},
+			"spec": map[string]interface{}{
+				"new": "new value",
+			},
+		},
+	}
+	got, err := executor.Update(observed, desired)
+	if err != nil {
+		t.Fatalf("Expected no error got %v", err)
+	}
+	if !got {
+		t.Fatalf("Expected update got none")
+	}
+	if len(op.updated) != 1 {
+		t.Fatalf("Expected 1 update got %d", len(op.updated))
+	}
+	if op.options[0].FieldManager != "test-manager" {
+		t.Fatalf("Expected field manager test-manager got %q", op.options[0].FieldManager)
+	}
+	updated := op.updated[0]
+	if updated.GetLabels()["team"] != "other" {
+		t.Fatalf("Expected label managed by other tool to be retained got %v", updated.GetLabels())
+	}
+	spec, _, _ := unstructured.NestedMap(updated.Object, "spec")
+	if _, found := spec["old"]; found {
+		t.Fatalf("Expected spec.old to be removed got %v", spec)
+	}
+	if spec["new"] != "new value" {
+		t.Fatalf("Expected spec.new to be updated got %v", spec)
+	}
+	ann := updated.GetAnnotations()["test-watch-uid"+lastAppliedAnnotationKeySuffix]
+	if ann != `{"metadata":{"labels":{"team":"other"}}}` {
+		t.Fatalf("Expected last applied annotation to be left alone got %q", ann)
+	}
+}
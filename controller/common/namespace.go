@@ -0,0 +1,92 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+)
+
+const (
+	// NamespaceIncludeAnnotationKey is the annotation set against a
+	// namespace to include this namespace for reconciliation by the
+	// controllers whose names are set as a comma separated value. A
+	// value of NamespaceAnnotationAllControllers includes all the
+	// controllers.
+	NamespaceIncludeAnnotationKey = "metac.openebs.io/include-controllers"
+
+	// NamespaceExcludeAnnotationKey is the annotation set against a
+	// namespace to exclude this namespace from reconciliation by the
+	// controllers whose names are set as a comma separated value. A
+	// value of NamespaceAnnotationAllControllers excludes all the
+	// controllers.
+	//
+	// NOTE:
+	//	Exclusion has higher priority than inclusion
+	NamespaceExcludeAnnotationKey = "metac.openebs.io/exclude-controllers"
+
+	// NamespaceAnnotationAllControllers is the annotation value that
+	// refers to all the controllers
+	NamespaceAnnotationAllControllers = "*"
+)
+
+// isControllerListed returns true if any of the given controller
+// names is listed in the given comma separated value
+func isControllerListed(value string, names ...string) bool {
+	for _, listed := range strings.Split(value, ",") {
+		listed = strings.TrimSpace(listed)
+		if listed == "" {
+			continue
+		}
+		if listed == NamespaceAnnotationAllControllers {
+			return true
+		}
+		for _, name := range names {
+			if listed == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IsNamespaceSelectedByAnnotations returns true if the given namespace
+// should be reconciled by the controller known by the given names based
+// on the namespace's annotations & the given policy.
+func IsNamespaceSelectedByAnnotations(
+	namespace metav1.Object, policy v1alpha1.NamespacePolicy, names ...string,
+) bool {
+	annotations := namespace.GetAnnotations()
+	if isControllerListed(annotations[NamespaceExcludeAnnotationKey], names...) {
+		return false
+	}
+	if policy == v1alpha1.NamespacePolicyOptIn {
+		return isControllerListed(annotations[NamespaceIncludeAnnotationKey], names...)
+	}
+	return true
+}
+
+// IsNamespaceAnnotationsChanged returns true if the annotations that
+// include or exclude the namespaces from reconciliation have changed
+func IsNamespaceAnnotationsChanged(old, cur metav1.Object) bool {
+	oldAnns, curAnns := old.GetAnnotations(), cur.GetAnnotations()
+	return oldAnns[NamespaceIncludeAnnotationKey] != curAnns[NamespaceIncludeAnnotationKey] ||
+		oldAnns[NamespaceExcludeAnnotationKey] != curAnns[NamespaceExcludeAnnotationKey]
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+)
+
+func TestIsNamespaceSelectedByAnnotations(t *testing.T) {
+	var tests = map[string]struct {
+		annotations map[string]string
+		policy      v1alpha1.NamespacePolicy
+		isSelected  bool
+	}{
+		"no annotations with default policy": {
+			isSelected: true,
+		},
+		"no annotations with opt in policy": {
+			policy:     v1alpha1.NamespacePolicyOptIn,
+			isSelected: false,
+		},
+		"excluded by name": {
+			annotations: map[string]string{
+				NamespaceExcludeAnnotationKey: "other, ctl",
+			},
+			isSelected: false,
+		},
+		"excluded by all": {
+			annotations: map[string]string{
+				NamespaceExcludeAnnotationKey: "*",
+			},
+			isSelected: false,
+		},
+		"excluded other controller": {
+			annotations: map[string]string{
+				NamespaceExcludeAnnotationKey: "other",
+			},
+			isSelected: true,
+		},
+		"included by name with opt in policy": {
+			annotations: map[string]string{
+				NamespaceIncludeAnnotationKey: "ns/ctl",
+			},
+			policy:     v1alpha1.NamespacePolicyOptIn,
+			isSelected: true,
+		},
+		"included other controller with opt in policy": {
+			annotations: map[string]string{
+				NamespaceIncludeAnnotationKey: "other",
+			},
+			policy:     v1alpha1.NamespacePolicyOptIn,
+			isSelected: false,
+		},
+		"exclude has higher priority": {
+			annotations: map[string]string{
+				NamespaceIncludeAnnotationKey: "*",
+				NamespaceExcludeAnnotationKey: "ctl",
+			},
+			policy:     v1alpha1.NamespacePolicyOptIn,
+			isSelected: false,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			ns := &metav1.ObjectMeta{
+				Name:        "test",
+				Annotations: mock.annotations,
+			}
+			got := IsNamespaceSelectedByAnnotations(ns, mock.policy, "ctl", "ns/ctl")
+			if got != mock.isSelected {
+				t.Fatalf("Expected is selected %t got %t", mock.isSelected, got)
+			}
+		})
+	}
+}
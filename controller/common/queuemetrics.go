@@ -0,0 +1,265 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/golang/glog"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"k8s.io/client-go/util/workqueue"
+)
+
+var (
+	// queueControllerKey tags the workqueue metrics with the name of
+	// the queue. This refers to the controller that owns the queue.
+	queueControllerKey, _ = tag.NewKey("controller")
+
+	// queueDepthMeasure tracks the number of items in the queue
+	queueDepthMeasure = stats.Int64(
+		"metac/workqueue_depth",
+		"Number of items waiting in the workqueue",
+		stats.UnitDimensionless,
+	)
+
+	// queueAddsMeasure counts the items added to the queue
+	queueAddsMeasure = stats.Int64(
+		"metac/workqueue_adds",
+		"Number of items added to the workqueue",
+		stats.UnitDimensionless,
+	)
+
+	// queueLatencyMeasure tracks the time an item waits in the queue
+	// before it is processed
+	queueLatencyMeasure = stats.Float64(
+		"metac/workqueue_queue_duration_seconds",
+		"Time an item waits in the workqueue before it is processed",
+		"s",
+	)
+
+	// queueWorkDurationMeasure tracks the time taken to process an
+	// item
+	queueWorkDurationMeasure = stats.Float64(
+		"metac/workqueue_work_duration_seconds",
+		"Time taken to process an item of the workqueue",
+		"s",
+	)
+
+	// queueUnfinishedWorkMeasure tracks the time spent by the items
+	// that are being processed
+	queueUnfinishedWorkMeasure = stats.Float64(
+		"metac/workqueue_unfinished_work_seconds",
+		"Time spent by the items of the workqueue that are being processed",
+		"s",
+	)
+
+	// queueLongestRunningMeasure tracks the time spent by the item
+	// that is being processed the longest
+	queueLongestRunningMeasure = stats.Float64(
+		"metac/workqueue_longest_running_processor_seconds",
+		"Time spent by the longest running item of the workqueue that is being processed",
+		"s",
+	)
+
+	// queueRetriesMeasure counts the items that are re-queued after
+	// a failure
+	queueRetriesMeasure = stats.Int64(
+		"metac/workqueue_retries",
+		"Number of items re-queued to the workqueue after a failure",
+		stats.UnitDimensionless,
+	)
+
+	// queueDurationBuckets are the buckets of the latency & work
+	// duration distributions in seconds
+	queueDurationBuckets = view.Distribution(
+		0.001, 0.01, 0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 300,
+	)
+
+	// QueueViews expose the workqueue metrics per controller
+	//
+	// NOTE:
+	//	These need to be registered to be exported. Metrics are
+	// recorded only after RegisterQueueMetrics is invoked.
+	QueueViews = []*view.View{
+		{
+			Name:        "metac/workqueue_depth",
+			Description: queueDepthMeasure.Description(),
+			Measure:     queueDepthMeasure,
+			TagKeys:     []tag.Key{queueControllerKey},
+			Aggregation: view.LastValue(),
+		},
+		{
+			Name:        "metac/workqueue_adds_total",
+			Description: queueAddsMeasure.Description(),
+			Measure:     queueAddsMeasure,
+			TagKeys:     []tag.Key{queueControllerKey},
+			Aggregation: view.Count(),
+		},
+		{
+			Name:        "metac/workqueue_queue_duration_seconds",
+			Description: queueLatencyMeasure.Description(),
+			Measure:     queueLatencyMeasure,
+			TagKeys:     []tag.Key{queueControllerKey},
+			Aggregation: queueDurationBuckets,
+		},
+		{
+			Name:        "metac/workqueue_work_duration_seconds",
+			Description: queueWorkDurationMeasure.Description(),
+			Measure:     queueWorkDurationMeasure,
+			TagKeys:     []tag.Key{queueControllerKey},
+			Aggregation: queueDurationBuckets,
+		},
+		{
+			Name:        "metac/workqueue_unfinished_work_seconds",
+			Description: queueUnfinishedWorkMeasure.Description(),
+			Measure:     queueUnfinishedWorkMeasure,
+			TagKeys:     []tag.Key{queueControllerKey},
+			Aggregation: view.LastValue(),
+		},
+		{
+			Name:        "metac/workqueue_longest_running_processor_seconds",
+			Description: queueLongestRunningMeasure.Description(),
+			Measure:     queueLongestRunningMeasure,
+			TagKeys:     []tag.Key{queueControllerKey},
+			Aggregation: view.LastValue(),
+		},
+		{
+			Name:        "metac/workqueue_retries_total",
+			Description: queueRetriesMeasure.Description(),
+			Measure:     queueRetriesMeasure,
+			TagKeys:     []tag.Key{queueControllerKey},
+			Aggregation: view.Count(),
+		},
+	}
+)
+
+// RegisterQueueMetrics lets the workqueues record their metrics
+//
+// NOTE:
+//	This needs to be invoked before the controllers are started.
+// Queues created earlier do not record any metrics.
+func RegisterQueueMetrics() {
+	workqueue.SetProvider(queueMetricsProvider{})
+}
+
+// queueMetricsProvider implements workqueue.MetricsProvider
+// interface by recording the workqueue metrics as opencensus
+// measures
+type queueMetricsProvider struct{}
+
+// NewDepthMetric implements workqueue.MetricsProvider interface
+func (queueMetricsProvider) NewDepthMetric(name string) workqueue.GaugeMetric {
+	return &queueGauge{queueMetric: newQueueMetric(name), measure: queueDepthMeasure}
+}
+
+// NewAddsMetric implements workqueue.MetricsProvider interface
+func (queueMetricsProvider) NewAddsMetric(name string) workqueue.CounterMetric {
+	return &queueCounter{queueMetric: newQueueMetric(name), measure: queueAddsMeasure}
+}
+
+// NewLatencyMetric implements workqueue.MetricsProvider interface
+func (queueMetricsProvider) NewLatencyMetric(name string) workqueue.HistogramMetric {
+	return &queueSeconds{queueMetric: newQueueMetric(name), measure: queueLatencyMeasure}
+}
+
+// NewWorkDurationMetric implements workqueue.MetricsProvider interface
+func (queueMetricsProvider) NewWorkDurationMetric(name string) workqueue.HistogramMetric {
+	return &queueSeconds{queueMetric: newQueueMetric(name), measure: queueWorkDurationMeasure}
+}
+
+// NewUnfinishedWorkSecondsMetric implements workqueue.MetricsProvider
+// interface
+func (queueMetricsProvider) NewUnfinishedWorkSecondsMetric(name string) workqueue.SettableGaugeMetric {
+	return &queueSeconds{queueMetric: newQueueMetric(name), measure: queueUnfinishedWorkMeasure}
+}
+
+// NewLongestRunningProcessorSecondsMetric implements
+// workqueue.MetricsProvider interface
+func (queueMetricsProvider) NewLongestRunningProcessorSecondsMetric(name string) workqueue.SettableGaugeMetric {
+	return &queueSeconds{queueMetric: newQueueMetric(name), measure: queueLongestRunningMeasure}
+}
+
+// NewRetriesMetric implements workqueue.MetricsProvider interface
+func (queueMetricsProvider) NewRetriesMetric(name string) workqueue.CounterMetric {
+	return &queueCounter{queueMetric: newQueueMetric(name), measure: queueRetriesMeasure}
+}
+
+// queueMetric holds the context tagged with the queue whose metric
+// is recorded
+type queueMetric struct {
+	ctx context.Context
+}
+
+// newQueueMetric returns a new instance of queueMetric for the
+// queue of the given name
+func newQueueMetric(name string) queueMetric {
+	ctx, err := tag.New(context.Background(), tag.Upsert(queueControllerKey, name))
+	if err != nil {
+		glog.Warningf("Failed to tag metrics of workqueue %s: %v", name, err)
+		ctx = context.Background()
+	}
+	return queueMetric{ctx: ctx}
+}
+
+// queueGauge records the number of items of a queue
+type queueGauge struct {
+	queueMetric
+	measure *stats.Int64Measure
+
+	// current value; this is accessed atomically
+	value int64
+}
+
+// Inc implements workqueue.GaugeMetric interface
+func (g *queueGauge) Inc() {
+	stats.Record(g.ctx, g.measure.M(atomic.AddInt64(&g.value, 1)))
+}
+
+// Dec implements workqueue.GaugeMetric interface
+func (g *queueGauge) Dec() {
+	stats.Record(g.ctx, g.measure.M(atomic.AddInt64(&g.value, -1)))
+}
+
+// queueCounter counts the occurrences of a queue operation
+type queueCounter struct {
+	queueMetric
+	measure *stats.Int64Measure
+}
+
+// Inc implements workqueue.CounterMetric interface
+func (c *queueCounter) Inc() {
+	stats.Record(c.ctx, c.measure.M(1))
+}
+
+// queueSeconds records the durations observed by a queue
+type queueSeconds struct {
+	queueMetric
+	measure *stats.Float64Measure
+}
+
+// Observe implements workqueue.HistogramMetric interface
+func (s *queueSeconds) Observe(seconds float64) {
+	stats.Record(s.ctx, s.measure.M(seconds))
+}
+
+// Set implements workqueue.SettableGaugeMetric interface
+func (s *queueSeconds) Set(seconds float64) {
+	stats.Record(s.ctx, s.measure.M(seconds))
+}
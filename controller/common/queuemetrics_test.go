@@ -0,0 +1,84 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"testing"
+
+	"go.opencensus.io/stats/view"
+)
+
+func TestQueueMetricsProvider(t *testing.T) {
+	err := view.Register(QueueViews...)
+	if err != nil {
+		t.Fatalf("Expected no error got %v", err)
+	}
+	defer view.Unregister(QueueViews...)
+
+	provider := queueMetricsProvider{}
+	depth := provider.NewDepthMetric("test-depth")
+	depth.Inc()
+	depth.Inc()
+	depth.Dec()
+	retries := provider.NewRetriesMetric("test-retries")
+	retries.Inc()
+	retries.Inc()
+
+	var tests = map[string]struct {
+		view  string
+		queue string
+		want  float64
+	}{
+		"depth": {
+			view:  "metac/workqueue_depth",
+			queue: "test-depth",
+			want:  1,
+		},
+		"retries": {
+			view:  "metac/workqueue_retries_total",
+			queue: "test-retries",
+			want:  2,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			rows, err := view.RetrieveData(mock.view)
+			if err != nil {
+				t.Fatalf("Expected no error got %v", err)
+			}
+			for _, row := range rows {
+				if len(row.Tags) != 1 || row.Tags[0].Value != mock.queue {
+					continue
+				}
+				var got float64
+				switch data := row.Data.(type) {
+				case *view.LastValueData:
+					got = data.Value
+				case *view.CountData:
+					got = float64(data.Value)
+				}
+				if got != mock.want {
+					t.Fatalf("Expected %v got %v", mock.want, got)
+				}
+				return
+			}
+			t.Fatalf("Expected metric of queue %q got none: %v", mock.queue, rows)
+		})
+	}
+}
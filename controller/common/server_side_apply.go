@@ -0,0 +1,257 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"reflect"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+	dynamicapply "openebs.io/metac/dynamic/apply"
+)
+
+const (
+	// EventReasonApplyConflict is the reason of the event raised
+	// when a server side apply of an attachment fails since its
+	// fields are managed by some other field manager
+	EventReasonApplyConflict string = "ApplyConflict"
+)
+
+// IsServerSideApply returns true if the attachments handled by this
+// executor should be applied via server side apply
+func (e AttachmentResourcesExecutor) IsServerSideApply() bool {
+	return e.ApplyStrategy == v1alpha1.ApplyStrategyServerSideApply
+}
+
+// makeApplyObj returns the object that is sent as the server side
+// apply patch of the given desired attachment
+//
+// NOTE:
+//	Fields that were set by this field manager earlier & are missing
+// from this object get removed by the API server. Hence the ownership
+// markers of this watch are always set against this object if the
+// attachment was created by this watch.
+func (e *AttachmentResourcesExecutor) makeApplyObj(
+	ns string, desiredObj *unstructured.Unstructured, isCreatedByWatch bool,
+) *unstructured.Unstructured {
+	applyObj := desiredObj.DeepCopy()
+	// ignored fields are left to their current owners
+	for _, path := range e.IgnorePaths() {
+		unstructured.RemoveNestedField(applyObj.Object, path...)
+	}
+	if e.DynamicResourceClient.Namespaced {
+		applyObj.SetNamespace(ns)
+	}
+	ann := applyObj.GetAnnotations()
+	if ann == nil {
+		ann = make(map[string]string)
+	}
+	if isCreatedByWatch {
+		ann[attachmentCreateAnnotationKey] = string(e.Watch.GetUID())
+		if e.IsWatchOwner != nil && *e.IsWatchOwner && e.canWatchOwn(ns) {
+			var ownerRefs []metav1.OwnerReference
+			for _, ref := range applyObj.GetOwnerReferences() {
+				if ref.UID != e.Watch.GetUID() {
+					ownerRefs = append(ownerRefs, ref)
+				}
+			}
+			ownerRefs = append(ownerRefs, *MakeOwnerRef(e.Watch))
+			applyObj.SetOwnerReferences(ownerRefs)
+		}
+	}
+	ann[string(e.Watch.GetUID())+attachmentUpdateAnnotationKeySuffix] =
+		DescObjectAsSanitisedKey(e.Watch)
+	applyObj.SetAnnotations(ann)
+	return applyObj
+}
+
+// serverSideApply sends the given object as an apply patch to the
+// API server. The object is created if it does not exist.
+func (e *AttachmentResourcesExecutor) serverSideApply(
+	ns string, applyObj *unstructured.Unstructured, isDryRun bool,
+) (*unstructured.Unstructured, error) {
+	force := e.isForceApply()
+	opts := metav1.PatchOptions{
+		FieldManager: e.FieldManager,
+		Force:        &force,
+	}
+	if isDryRun {
+		opts.DryRun = []string{metav1.DryRunAll}
+	}
+	applied, err := e.DynamicResourceClient.Namespace(ns).ApplyPatch(applyObj, opts)
+	if err != nil && apierrors.IsConflict(err) && !force {
+		e.recordEvent(
+			corev1.EventTypeWarning,
+			EventReasonApplyConflict,
+			"Can't apply %s: FieldManager %q: %v",
+			DescObjectAsKey(applyObj),
+			e.FieldManager,
+			err,
+		)
+	}
+	return applied, err
+}
+
+// stripApplyFields returns the content of the given object without
+// the fields that are changed by the API server on every apply
+func stripApplyFields(obj *unstructured.Unstructured) map[string]interface{} {
+	c := obj.DeepCopy()
+	c.SetResourceVersion("")
+	c.SetGeneration(0)
+	c.SetManagedFields(nil)
+	return c.UnstructuredContent()
+}
+
+// isApplyNoop returns true if the given applied object i.e. the
+// result of a dry run apply does not differ from the given observed
+// object
+func isApplyNoop(observedObj, appliedObj *unstructured.Unstructured) bool {
+	return reflect.DeepEqual(stripApplyFields(observedObj), stripApplyFields(appliedObj))
+}
+
+// computeApplyDiffs returns the fields that differ between the given
+// observed & applied objects
+func computeApplyDiffs(observedObj, appliedObj *unstructured.Unstructured) []dynamicapply.FieldDiff {
+	return dynamicapply.ComputeFieldDiffs(
+		stripApplyFields(observedObj), stripApplyFields(appliedObj),
+	)
+}
+
+// updateViaServerSideApply updates the observed attachment to its
+// desired state via server side apply
+//
+// NOTE:
+//	Return value with bool datatype indicates a update or no update.
+func (e *AttachmentResourcesExecutor) updateViaServerSideApply(
+	ns string,
+	observedObj, desiredObj *unstructured.Unstructured,
+	isCreatedByWatch bool,
+	method v1alpha1.ChildUpdateMethod,
+) (bool, error) {
+	applyObj := e.makeApplyObj(ns, desiredObj, isCreatedByWatch)
+
+	if e.ConflictPolicy() != "" {
+		// look for conflicts before the API server does to let the
+		// conflict policy act on these
+		conflicts, err := e.findOwnedConflicts(observedObj, applyObj)
+		if err != nil {
+			return false, err
+		}
+		conflicts = withoutPaths(conflicts, e.IgnorePaths())
+		if e.ConflictPolicy() == v1alpha1.AttachmentConflictPolicyYield {
+			// conflicting fields are left to their current owners
+			for _, path := range conflicts {
+				unstructured.RemoveNestedField(applyObj.Object, path...)
+			}
+		}
+		err = e.handleConflicts(desiredObj, conflicts)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	switch method {
+	case v1alpha1.ChildUpdateRecreate, v1alpha1.ChildUpdateRollingRecreate:
+		// find if the apply results in any changes without
+		// persisting these changes
+		applied, err := e.serverSideApply(ns, applyObj, true)
+		if err != nil {
+			return false, err
+		}
+		if isApplyNoop(observedObj, applied) {
+			e.logFor(desiredObj).V(4).Info("Won't update attachment: Nothing changed")
+			return false, nil
+		}
+		if IsDeleteProtected(observedObj) {
+			e.logFor(desiredObj).V(4).Info("Won't recreate attachment: Attachment is protected")
+			e.recordDeleteProtectedEvent(observedObj)
+			return false, nil
+		}
+		// Delete the object (now) and recreate it (on the next sync).
+		e.logFor(desiredObj).V(4).Info("Deleting attachment for update")
+		uid := observedObj.GetUID()
+		propagation := e.DeletionPropagation()
+		err = e.DynamicResourceClient.Namespace(ns).Delete(
+			desiredObj.GetName(),
+			&metav1.DeleteOptions{
+				Preconditions:     &metav1.Preconditions{UID: &uid},
+				PropagationPolicy: &propagation,
+			},
+		)
+		if err != nil {
+			return false, err
+		}
+		e.logFor(desiredObj).Info("Deleted attachment for update")
+		e.reportAction("Deleted for update", observedObj)
+		e.reportApplyDiffs(
+			"Deleted for update", observedObj, computeApplyDiffs(observedObj, applied),
+		)
+	case v1alpha1.ChildUpdateInPlace, v1alpha1.ChildUpdateRollingInPlace:
+		e.logFor(desiredObj).V(4).Info("Applying attachment")
+		applied, err := e.serverSideApply(ns, applyObj, false)
+		if err != nil && isImmutableFieldError(err) &&
+			e.IsRecreateOnImmutableError() && !IsDeleteProtected(observedObj) {
+			// Delete the object (now) and recreate it (on the next sync)
+			// since it can't be updated in-place
+			err = e.recreateOnImmutableError(ns, observedObj, err)
+			if err != nil {
+				return false, err
+			}
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		// API server does not persist an apply that changes nothing
+		if applied.GetResourceVersion() == observedObj.GetResourceVersion() {
+			e.logFor(desiredObj).V(4).Info("Won't update attachment: Nothing changed")
+			return false, nil
+		}
+		e.logFor(desiredObj).V(3).Info("Applied attachment")
+		e.reportAction("Updated", desiredObj)
+		e.reportApplyDiffs(
+			"Updated", observedObj, computeApplyDiffs(observedObj, applied),
+		)
+	default:
+		return false, errors.Errorf(
+			"%s: Invalid update strategy %s for %s",
+			e, method, DescObjectAsKey(desiredObj),
+		)
+	}
+	// this resulted in an actual update
+	return true, nil
+}
+
+// createViaServerSideApply creates the desired attachment via server
+// side apply
+func (e *AttachmentResourcesExecutor) createViaServerSideApply(
+	ns string, desiredObj *unstructured.Unstructured,
+) error {
+	e.logFor(desiredObj).V(4).Info("Creating attachment via apply")
+	_, err := e.serverSideApply(ns, e.makeApplyObj(ns, desiredObj, true), false)
+	if err != nil {
+		return err
+	}
+	e.logFor(desiredObj).Info("Created attachment")
+	e.reportAction("Created", desiredObj)
+	return nil
+}
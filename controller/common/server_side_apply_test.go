@@ -0,0 +1,191 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/json"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+	dynamicclientset "openebs.io/metac/dynamic/clientset"
+	dynamicdiscovery "openebs.io/metac/dynamic/discovery"
+	"openebs.io/metac/third_party/kubernetes"
+)
+
+type RecordPatchResourceOperation struct {
+	NoopResourceOperation
+
+	// resource version of the patched object
+	resourceVersion string
+
+	patchTypes []types.PatchType
+	patches    []*unstructured.Unstructured
+	options    []metav1.PatchOptions
+}
+
+func (r *RecordPatchResourceOperation) Patch(name string, pt types.PatchType, data []byte, options metav1.PatchOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	obj := &unstructured.Unstructured{}
+	err := json.Unmarshal(data, &obj.Object)
+	if err != nil {
+		return nil, err
+	}
+	r.patchTypes = append(r.patchTypes, pt)
+	r.patches = append(r.patches, obj)
+	r.options = append(r.options, options)
+	patched := obj.DeepCopy()
+	patched.SetResourceVersion(r.resourceVersion)
+	return patched, nil
+}
+
+func TestAttachmentResourcesExecutorUpdateViaServerSideApply(t *testing.T) {
+	var tests = map[string]struct {
+		appliedResourceVersion string
+		createdByWatchUID      string
+		isForce                bool
+		isUpdate               bool
+		isCreatedByWatch       bool
+	}{
+		"applied with changes": {
+			appliedResourceVersion: "2",
+			createdByWatchUID:      "test-watch-uid",
+			isForce:                true,
+			isUpdate:               true,
+			isCreatedByWatch:       true,
+		},
+		"applied without changes": {
+			appliedResourceVersion: "1",
+			createdByWatchUID:      "test-watch-uid",
+			isCreatedByWatch:       true,
+		},
+		"applied to attachment of other watch": {
+			appliedResourceVersion: "2",
+			createdByWatchUID:      "other-watch-uid",
+			isUpdate:               true,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			op := &RecordPatchResourceOperation{
+				resourceVersion: mock.appliedResourceVersion,
+			}
+			executor := &AttachmentResourcesExecutor{
+				AttachmentExecuteBase: AttachmentExecuteBase{
+					GetChildUpdateStrategyByGK: func(group, kind string) v1alpha1.ChildUpdateMethod {
+						return v1alpha1.ChildUpdateInPlace
+					},
+					IsPatchByGK: func(group, kind string) bool {
+						return false
+					},
+					Watch: &unstructured.Unstructured{
+						Object: map[string]interface{}{
+							"metadata": map[string]interface{}{
+								"name": "watch",
+								"uid":  "test-watch-uid",
+							},
+						},
+					},
+					UpdateAny:             kubernetes.BoolPtr(true),
+					ApplyStrategy:         v1alpha1.ApplyStrategyServerSideApply,
+					FieldManager:          "test-manager",
+					IsForceApplyConflicts: mock.isForce,
+				},
+				DynamicResourceClient: &dynamicclientset.ResourceClient{
+					ResourceInterface: op,
+					APIResource:       &dynamicdiscovery.APIResource{},
+				},
+			}
+			observed := &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"metadata": map[string]interface{}{
+						"name":            "attachment",
+						"resourceVersion": "1",
+						"annotations": map[string]interface{}{
+							attachmentCreateAnnotationKey: mock.createdByWatchUID,
+						},
+					},
+					"spec": "old value",
+				},
+			}
+			desired := &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"metadata": map[string]interface{}{
+						"name": "attachment",
+					},
+					"spec": "new value",
+				},
+			}
+			got, err := executor.Update(observed, desired)
+			if err != nil {
+				t.Fatalf("Expected no error got %v", err)
+			}
+			if got != mock.isUpdate {
+				t.Fatalf("Expected update %t got %t", mock.isUpdate, got)
+			}
+			if len(op.patches) != 1 {
+				t.Fatalf("Expected 1 patch got %d", len(op.patches))
+			}
+			if op.patchTypes[0] != types.ApplyPatchType {
+				t.Fatalf("Expected patch type %q got %q", types.ApplyPatchType, op.patchTypes[0])
+			}
+			if op.options[0].FieldManager != "test-manager" {
+				t.Fatalf("Expected field manager test-manager got %q", op.options[0].FieldManager)
+			}
+			if op.options[0].Force == nil || *op.options[0].Force != mock.isForce {
+				t.Fatalf("Expected force %t got %v", mock.isForce, op.options[0].Force)
+			}
+			anns := op.patches[0].GetAnnotations()
+			if _, found := anns["test-watch-uid"+lastAppliedAnnotationKeySuffix]; found {
+				t.Fatalf("Expected no last applied annotation got %v", anns)
+			}
+			if _, found := anns[attachmentCreateAnnotationKey]; found != mock.isCreatedByWatch {
+				t.Fatalf(
+					"Expected create annotation %t got %t", mock.isCreatedByWatch, found,
+				)
+			}
+		})
+	}
+}
+
+func TestIsApplyNoop(t *testing.T) {
+	observed := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name":            "attachment",
+				"resourceVersion": "1",
+				"generation":      int64(1),
+			},
+			"spec": "value",
+		},
+	}
+	same := observed.DeepCopy()
+	same.SetResourceVersion("2")
+	same.SetGeneration(2)
+	if !isApplyNoop(observed, same) {
+		t.Fatalf("Expected noop got diff")
+	}
+	changed := observed.DeepCopy()
+	changed.Object["spec"] = "new value"
+	if isApplyNoop(observed, changed) {
+		t.Fatalf("Expected diff got noop")
+	}
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	dynamicclientset "openebs.io/metac/dynamic/clientset"
+)
+
+const (
+	// EventReasonUpdateConflict is the reason of the event raised
+	// when an attachment can't be updated since it keeps changing
+	// between its fetch & update
+	EventReasonUpdateConflict string = "UpdateConflict"
+)
+
+// updateWithConflictRetries updates the observed attachment to the
+// given merged state. Update that fails due to a conflict is retried
+// against the latest state of the attachment as set by the given
+// remerge func.
+func (e *AttachmentResourcesExecutor) updateWithConflictRetries(
+	ns string,
+	observedObj, mergedObj *unstructured.Unstructured,
+	remerge func(latest *unstructured.Unstructured) bool,
+) error {
+	_, err := e.DynamicResourceClient.Namespace(ns).
+		WithAuditDiff(dynamicclientset.AuditDiff(observedObj, mergedObj)).
+		UpdateWithRetries(mergedObj, e.updateOptions(), e.MaxUpdateConflictRetries, remerge)
+	if apierrors.IsConflict(err) {
+		e.recordEvent(
+			corev1.EventTypeWarning,
+			EventReasonUpdateConflict,
+			"Can't update %s: Conflict persisted after %d retries",
+			DescObjectAsKey(mergedObj),
+			e.MaxUpdateConflictRetries,
+		)
+		return errors.Errorf(
+			"%s: Can't update %s: Conflict persisted after %d retries",
+			e, DescObjectAsKey(mergedObj), e.MaxUpdateConflictRetries,
+		)
+	}
+	return err
+}
@@ -0,0 +1,293 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+	dynamicclientset "openebs.io/metac/dynamic/clientset"
+	dynamicdiscovery "openebs.io/metac/dynamic/discovery"
+	"openebs.io/metac/third_party/kubernetes"
+)
+
+// ConflictResourceOperation fails the given number of updates with
+// the given error
+type ConflictResourceOperation struct {
+	NoopResourceOperation
+
+	conflicts int
+	err       error
+
+	// latest is returned by Get if set
+	latest *unstructured.Unstructured
+
+	updates int
+	gets    int
+}
+
+func (r *ConflictResourceOperation) Update(obj *unstructured.Unstructured, options metav1.UpdateOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	r.updates++
+	if r.updates <= r.conflicts {
+		return nil, r.err
+	}
+	return obj, nil
+}
+
+func (r *ConflictResourceOperation) Get(name string, options metav1.GetOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	r.gets++
+	if r.latest != nil {
+		return r.latest.DeepCopy(), nil
+	}
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name": name,
+			},
+			"spec": "old value",
+		},
+	}, nil
+}
+
+func TestAttachmentResourcesExecutorUpdateWithConflictRetries(t *testing.T) {
+	resourceConflict := apierrors.NewConflict(
+		schema.GroupResource{Resource: "tests"}, "attachment", nil,
+	)
+	var tests = map[string]struct {
+		conflicts   int
+		latest      *unstructured.Unstructured
+		isErr       bool
+		isUpdated   bool
+		isEvent     bool
+		wantUpdates int
+		wantGets    int
+	}{
+		"resolved after retries": {
+			conflicts:   2,
+			isUpdated:   true,
+			wantUpdates: 3,
+			wantGets:    2,
+		},
+		"persists after retries": {
+			conflicts:   10,
+			isErr:       true,
+			isEvent:     true,
+			wantUpdates: 4,
+			wantGets:    3,
+		},
+		"latest is already desired": {
+			conflicts: 10,
+			latest: &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"metadata": map[string]interface{}{
+						"name": "attachment",
+					},
+					"spec": "new value",
+				},
+			},
+			wantUpdates: 1,
+			wantGets:    1,
+		},
+		"latest is protected": {
+			conflicts: 10,
+			latest: &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"metadata": map[string]interface{}{
+						"name": "attachment",
+						"annotations": map[string]interface{}{
+							AttachmentProtectAnnotationKey: AttachmentProtectAll,
+						},
+					},
+					"spec": "old value",
+				},
+			},
+			wantUpdates: 1,
+			wantGets:    1,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			op := &ConflictResourceOperation{
+				conflicts: mock.conflicts,
+				err:       resourceConflict,
+				latest:    mock.latest,
+			}
+			recorder := record.NewFakeRecorder(1)
+			executor := &AttachmentResourcesExecutor{
+				AttachmentExecuteBase: AttachmentExecuteBase{
+					GetChildUpdateStrategyByGK: func(group, kind string) v1alpha1.ChildUpdateMethod {
+						return v1alpha1.ChildUpdateInPlace
+					},
+					IsPatchByGK: func(group, kind string) bool {
+						return false
+					},
+					Watch: &unstructured.Unstructured{
+						Object: map[string]interface{}{
+							"metadata": map[string]interface{}{
+								"name": "watch",
+								"uid":  "test-watch-uid",
+							},
+						},
+					},
+					UpdateAny:                kubernetes.BoolPtr(true),
+					EventRecorder:            recorder,
+					MaxUpdateConflictRetries: 3,
+				},
+				DynamicResourceClient: &dynamicclientset.ResourceClient{
+					ResourceInterface: op,
+					APIResource:       &dynamicdiscovery.APIResource{},
+				},
+			}
+			observed := &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"metadata": map[string]interface{}{
+						"name": "attachment",
+					},
+					"spec": "old value",
+				},
+			}
+			desired := &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"metadata": map[string]interface{}{
+						"name": "attachment",
+					},
+					"spec": "new value",
+				},
+			}
+			got, err := executor.Update(observed, desired)
+			if mock.isErr && err == nil {
+				t.Fatalf("Expected error got none")
+			}
+			if !mock.isErr && err != nil {
+				t.Fatalf("Expected no error got %v", err)
+			}
+			if got != mock.isUpdated {
+				t.Fatalf("Expected update %t got %t", mock.isUpdated, got)
+			}
+			if op.updates != mock.wantUpdates {
+				t.Fatalf("Expected %d updates got %d", mock.wantUpdates, op.updates)
+			}
+			if op.gets != mock.wantGets {
+				t.Fatalf("Expected %d gets got %d", mock.wantGets, op.gets)
+			}
+			if isEvent := len(recorder.Events) == 1; isEvent != mock.isEvent {
+				t.Fatalf("Expected event %t got %t", mock.isEvent, isEvent)
+			}
+		})
+	}
+}
+
+func TestAttachmentResourcesExecutorReleaseWithConflictRetries(t *testing.T) {
+	resourceConflict := apierrors.NewConflict(
+		schema.GroupResource{Resource: "tests"}, "attachment", nil,
+	)
+	owned := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name": "attachment",
+				"annotations": map[string]interface{}{
+					attachmentCreateAnnotationKey: "test-watch-uid",
+				},
+			},
+		},
+	}
+	var tests = map[string]struct {
+		conflicts   int
+		latest      *unstructured.Unstructured
+		isErr       bool
+		wantUpdates int
+		wantGets    int
+	}{
+		"resolved after retries": {
+			conflicts:   2,
+			latest:      owned,
+			wantUpdates: 3,
+			wantGets:    2,
+		},
+		"released by others": {
+			conflicts: 2,
+			latest: &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"metadata": map[string]interface{}{
+						"name": "attachment",
+					},
+				},
+			},
+			wantUpdates: 1,
+			wantGets:    1,
+		},
+		"persists after retries": {
+			conflicts:   10,
+			latest:      owned,
+			isErr:       true,
+			wantUpdates: 4,
+			wantGets:    3,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			op := &ConflictResourceOperation{
+				conflicts: mock.conflicts,
+				err:       resourceConflict,
+				latest:    mock.latest,
+			}
+			executor := &AttachmentResourcesExecutor{
+				AttachmentExecuteBase: AttachmentExecuteBase{
+					Watch: &unstructured.Unstructured{
+						Object: map[string]interface{}{
+							"metadata": map[string]interface{}{
+								"name": "watch",
+								"uid":  "test-watch-uid",
+							},
+						},
+					},
+					MaxUpdateConflictRetries: 3,
+				},
+				DynamicResourceClient: &dynamicclientset.ResourceClient{
+					ResourceInterface: op,
+					APIResource:       &dynamicdiscovery.APIResource{},
+				},
+				Observed: map[string]*unstructured.Unstructured{
+					"attachment": owned,
+				},
+			}
+			err := executor.Release()
+			if mock.isErr && err == nil {
+				t.Fatalf("Expected error got none")
+			}
+			if !mock.isErr && err != nil {
+				t.Fatalf("Expected no error got %v", err)
+			}
+			if op.updates != mock.wantUpdates {
+				t.Fatalf("Expected %d updates got %d", mock.wantUpdates, op.updates)
+			}
+			if op.gets != mock.wantGets {
+				t.Fatalf("Expected %d gets got %d", mock.wantGets, op.gets)
+			}
+		})
+	}
+}
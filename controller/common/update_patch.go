@@ -0,0 +1,75 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"github.com/golang/glog"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// PatchType returns the type of patch used to update the attachments
+// handled by this executor in-place. Empty patch type implies these
+// attachments are updated as a whole.
+//
+// NOTE:
+//	Strategic merge patch falls back to JSON merge patch for the
+// resources that do not support it e.g. custom resources
+func (e AttachmentResourcesExecutor) PatchType() types.PatchType {
+	if e.GetPatchTypeByGK == nil {
+		return ""
+	}
+	pt := e.GetPatchTypeByGK(
+		e.DynamicResourceClient.Group, e.DynamicResourceClient.Kind,
+	)
+	if pt == types.StrategicMergePatchType &&
+		!e.DynamicResourceClient.IsStrategicMergePatchSupported() {
+		return types.MergePatchType
+	}
+	return pt
+}
+
+// updateInPlace updates the observed attachment to the given merged
+// state either as a whole or via a patch of the differences between
+// these states. An update as a whole that conflicts is retried after
+// the given remerge func sets the desired changes against the latest
+// state of the attachment.
+func (e *AttachmentResourcesExecutor) updateInPlace(
+	ns string,
+	observedObj, mergedObj *unstructured.Unstructured,
+	remerge func(latest *unstructured.Unstructured) bool,
+) error {
+	pt := e.PatchType()
+	if pt == "" {
+		return e.updateWithConflictRetries(ns, observedObj, mergedObj, remerge)
+	}
+	data, err := e.DynamicResourceClient.CreatePatch(pt, observedObj, mergedObj)
+	if err != nil {
+		return err
+	}
+	glog.V(4).Infof(
+		"%s: Patching %s: PatchType=%q", e, DescObjectAsKey(mergedObj), pt,
+	)
+	_, err = e.DynamicResourceClient.Namespace(ns).Patch(
+		mergedObj.GetName(),
+		pt,
+		data,
+		metav1.PatchOptions{FieldManager: e.updateOptions().FieldManager},
+	)
+	return err
+}
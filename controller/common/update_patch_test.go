@@ -0,0 +1,176 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/json"
+
+	dynamicclientset "openebs.io/metac/dynamic/clientset"
+	dynamicdiscovery "openebs.io/metac/dynamic/discovery"
+)
+
+type RecordRawPatchResourceOperation struct {
+	NoopResourceOperation
+
+	patchTypes []types.PatchType
+	patches    [][]byte
+}
+
+func (r *RecordRawPatchResourceOperation) Patch(name string, pt types.PatchType, data []byte, options metav1.PatchOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	r.patchTypes = append(r.patchTypes, pt)
+	r.patches = append(r.patches, data)
+	return &unstructured.Unstructured{}, nil
+}
+
+func TestAttachmentResourcesExecutorUpdateInPlace(t *testing.T) {
+	var tests = map[string]struct {
+		kind          string
+		patchType     types.PatchType
+		wantPatchType types.PatchType
+		wantPatch     interface{}
+	}{
+		"merge patch": {
+			kind:          "ConfigMap",
+			patchType:     types.MergePatchType,
+			wantPatchType: types.MergePatchType,
+			wantPatch: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"resourceVersion": "1",
+				},
+				"data": map[string]interface{}{
+					"new": "value",
+					"old": nil,
+				},
+			},
+		},
+		"strategic merge patch": {
+			kind:          "ConfigMap",
+			patchType:     types.StrategicMergePatchType,
+			wantPatchType: types.StrategicMergePatchType,
+			wantPatch: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"resourceVersion": "1",
+				},
+				"data": map[string]interface{}{
+					"new": "value",
+					"old": nil,
+				},
+			},
+		},
+		"strategic merge patch of custom resource falls back to merge patch": {
+			kind:          "Unknown",
+			patchType:     types.StrategicMergePatchType,
+			wantPatchType: types.MergePatchType,
+			wantPatch: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"resourceVersion": "1",
+				},
+				"data": map[string]interface{}{
+					"new": "value",
+					"old": nil,
+				},
+			},
+		},
+		"json patch": {
+			kind:          "ConfigMap",
+			patchType:     types.JSONPatchType,
+			wantPatchType: types.JSONPatchType,
+			wantPatch: []interface{}{
+				map[string]interface{}{
+					"op":    "replace",
+					"path":  "/metadata/resourceVersion",
+					"value": "1",
+				},
+				map[string]interface{}{
+					"op":   "remove",
+					"path": "/data/old",
+				},
+				map[string]interface{}{
+					"op":    "add",
+					"path":  "/data/new",
+					"value": "value",
+				},
+			},
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			op := &RecordRawPatchResourceOperation{}
+			executor := &AttachmentResourcesExecutor{
+				AttachmentExecuteBase: AttachmentExecuteBase{
+					GetPatchTypeByGK: func(group, kind string) types.PatchType {
+						return mock.patchType
+					},
+				},
+				DynamicResourceClient: &dynamicclientset.ResourceClient{
+					ResourceInterface: op,
+					APIResource: &dynamicdiscovery.APIResource{
+						APIResource: metav1.APIResource{
+							Name:    "tests",
+							Version: "v1",
+							Kind:    mock.kind,
+						},
+						APIVersion: "v1",
+					},
+				},
+			}
+			observed := &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"apiVersion": "v1",
+					"kind":       mock.kind,
+					"metadata": map[string]interface{}{
+						"name":            "attachment",
+						"resourceVersion": "1",
+					},
+					"data": map[string]interface{}{
+						"old": "value",
+					},
+				},
+			}
+			merged := observed.DeepCopy()
+			merged.Object["data"] = map[string]interface{}{
+				"new": "value",
+			}
+			err := executor.updateInPlace("", observed, merged, nil)
+			if err != nil {
+				t.Fatalf("Expected no error got %v", err)
+			}
+			if len(op.patches) != 1 {
+				t.Fatalf("Expected 1 patch got %d", len(op.patches))
+			}
+			if op.patchTypes[0] != mock.wantPatchType {
+				t.Fatalf("Expected patch type %q got %q", mock.wantPatchType, op.patchTypes[0])
+			}
+			var got interface{}
+			err = json.Unmarshal(op.patches[0], &got)
+			if err != nil {
+				t.Fatalf("Expected valid patch got %v", err)
+			}
+			if !reflect.DeepEqual(got, mock.wantPatch) {
+				t.Fatalf("Expected patch %v got %v", mock.wantPatch, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composite
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+	"openebs.io/metac/config"
+	dynamicclientset "openebs.io/metac/dynamic/clientset"
+	dynamicdiscovery "openebs.io/metac/dynamic/discovery"
+	dynamicinformer "openebs.io/metac/dynamic/informer"
+)
+
+// ConfigBasedMetacontroller runs the CompositeControllers that are
+// declared in metac configs instead of CompositeController custom
+// resources
+//
+// NOTE:
+//	ControllerRevisions are not available in config based mode.
+// Hence CompositeControllers whose children use rolling updates are
+// rejected.
+type ConfigBasedMetacontroller struct {
+	resourceManager        *dynamicdiscovery.APIResourceManager
+	dynamicClientset       *dynamicclientset.Clientset
+	dynamicInformerFactory *dynamicinformer.SharedInformerFactory
+
+	workerCount       int
+	parentControllers map[string]*parentController
+
+	// mutex guards the parent controllers that are mutated by config
+	// syncs & stop
+	mutex sync.Mutex
+}
+
+// NewConfigBasedMetacontroller returns a new instance of
+// ConfigBasedMetacontroller
+func NewConfigBasedMetacontroller(
+	resourceMgr *dynamicdiscovery.APIResourceManager,
+	dynamicClientset *dynamicclientset.Clientset,
+	dynamicInformerFactory *dynamicinformer.SharedInformerFactory,
+	workerCount int,
+) *ConfigBasedMetacontroller {
+	return &ConfigBasedMetacontroller{
+		resourceManager:        resourceMgr,
+		dynamicClientset:       dynamicClientset,
+		dynamicInformerFactory: dynamicInformerFactory,
+		workerCount:            workerCount,
+		parentControllers:      make(map[string]*parentController),
+	}
+}
+
+func (mc *ConfigBasedMetacontroller) String() string {
+	return "Local CompositeController"
+}
+
+// SyncConfigs stops the parent controllers whose configs got removed
+// or changed & starts the parent controllers of the CompositeController
+// configs found in the given configs
+func (mc *ConfigBasedMetacontroller) SyncConfigs(configs config.MetacConfigs) error {
+	cctls, err := configs.ListCompositeControllers()
+	if err != nil {
+		return errors.Wrapf(err, "%s: Can't list configs", mc)
+	}
+	desired := make(map[string]*v1alpha1.CompositeController, len(cctls))
+	for _, cc := range cctls {
+		desired[cc.Name] = cc
+	}
+
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	for name, pc := range mc.parentControllers {
+		cc, found := desired[name]
+		if found && apiequality.Semantic.DeepEqual(cc.Spec, pc.api.Spec) {
+			continue
+		}
+		if found {
+			logForController(name).Info("Will restart controller: Config changed", "metacontroller", mc)
+		} else {
+			logForController(name).Info("Will stop controller: Config removed", "metacontroller", mc)
+		}
+		pc.Stop()
+		delete(mc.parentControllers, name)
+	}
+
+	var errs []error
+	for _, cc := range cctls {
+		if _, found := mc.parentControllers[cc.Name]; found {
+			continue
+		}
+		err := validateConfigBasedCompositeController(cc)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "%s: Can't start %s", mc, cc.Name))
+			continue
+		}
+		// parent controllers in config based mode do not have access
+		// to ControllerRevisions
+		pc, err := newParentController(
+			mc.resourceManager,
+			mc.dynamicClientset,
+			mc.dynamicInformerFactory,
+			nil,
+			nil,
+			cc,
+		)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "%s: Can't start %s", mc, cc.Name))
+			continue
+		}
+		pc.Start(mc.workerCount)
+		mc.parentControllers[cc.Name] = pc
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// Stop stops all the parent controllers
+func (mc *ConfigBasedMetacontroller) Stop() {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	var wg sync.WaitGroup
+	for _, pc := range mc.parentControllers {
+		wg.Add(1)
+		go func(pc *parentController) {
+			defer wg.Done()
+			pc.Stop()
+		}(pc)
+	}
+	wg.Wait()
+	mc.parentControllers = make(map[string]*parentController)
+}
+
+// validateConfigBasedCompositeController returns error if the given
+// controller can't be run in config based mode
+func validateConfigBasedCompositeController(cc *v1alpha1.CompositeController) error {
+	for _, child := range cc.Spec.ChildResources {
+		if isRollingStrategy(child.UpdateStrategy) {
+			return errors.Errorf(
+				"Rolling update of child %s %s is not supported in config based mode",
+				child.APIVersion,
+				child.Resource,
+			)
+		}
+	}
+	return nil
+}
@@ -23,7 +23,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/golang/glog"
 	"github.com/pkg/errors"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -44,6 +43,8 @@ import (
 	dynamiccontrollerref "openebs.io/metac/dynamic/controllerref"
 	dynamicdiscovery "openebs.io/metac/dynamic/discovery"
 	dynamicinformer "openebs.io/metac/dynamic/informer"
+	"openebs.io/metac/hooks/webhook"
+	"openebs.io/metac/logging"
 	k8s "openebs.io/metac/third_party/kubernetes"
 )
 
@@ -68,6 +69,8 @@ type parentController struct {
 	childInformers common.ResourceInformerRegistryByVR
 
 	finalizer *finalizer.Finalizer
+
+	log logging.Logger
 }
 
 func newParentController(
@@ -154,6 +157,14 @@ func newParentController(
 			Name:    "metac.openebs.io/compositecontroller-" + api.Name,
 			Enabled: api.Spec.Hooks.Finalize != nil,
 		},
+		log: newControllerLogger(api),
+	}
+
+	// report this controller as the subscriber of its informers
+	subscriber := "CompositeController " + pc.String()
+	parentInformer.SetSubscriber(subscriber)
+	for _, childInformer := range childInformers {
+		childInformer.SetSubscriber(subscriber)
 	}
 
 	return pc, nil
@@ -211,13 +222,11 @@ func (pc *parentController) Start(workerCount int) {
 		defer close(pc.doneCh)
 		defer utilruntime.HandleCrash()
 
-		glog.Infof("Starting CompositeController %s", pc)
-		defer glog.Infof("Shutting down CompositeController %s", pc)
+		pc.log.Info("Starting controller")
+		defer pc.log.Info("Shutting down controller")
 
 		// Wait for dynamic client and all informers.
-		glog.Infof(
-			"CompositeController %s waiting for caches to sync", pc,
-		)
+		pc.log.Info("Waiting for caches to sync")
 
 		syncFuncs := make([]cache.InformerSynced, 0, 2+len(pc.api.Spec.ChildResources))
 		syncFuncs = append(
@@ -239,13 +248,11 @@ func (pc *parentController) Start(workerCount int) {
 		)
 		if !waitForCacheSync(pc.stopCh, syncFuncs...) {
 			// We wait forever unless Stop() is called, so this isn't an error.
-			glog.Warningf(
-				"CompositeController %s cache sync never finished", pc,
-			)
+			pc.log.Warning("Cache sync never finished")
 			return
 		}
 
-		glog.Infof("Starting %d workers for %s", workerCount, pc)
+		pc.log.Info("Starting workers", "count", workerCount)
 		var wg sync.WaitGroup
 		for i := 0; i < workerCount; i++ {
 			wg.Add(1)
@@ -264,8 +271,9 @@ func (pc *parentController) Stop() {
 	// to stop reconciliation
 	close(pc.stopCh)
 	pc.queue.ShutDown()
-	// wait till done channel is closed
-	<-pc.doneCh
+	// wait till done channel is closed i.e. till the in-flight
+	// syncs complete or till the drain timeout
+	common.WaitForDrain(pc.String(), pc.doneCh)
 
 	// Remove event handlers and close informers (i.e. decrement the counter)
 	// for all child resources.
@@ -295,7 +303,22 @@ func (pc *parentController) processNextWorkItem() bool {
 	}
 
 	defer pc.queue.Done(key)
+	// queued parents are not synced once this controller is asked
+	// to stop
+	if common.IsStopped(pc.stopCh) {
+		return false
+	}
 	err := pc.sync(key.(string))
+	if err != nil && webhook.IsPermanentError(err) {
+		// hook rejected this request; wait for a change to the
+		// parent or its children or for the next resync
+		utilruntime.HandleError(errors.Wrapf(
+			err,
+			"failed to sync %v %q: will not re-queue", pc.parentResource.Kind, key),
+		)
+		pc.queue.Forget(key)
+		return true
+	}
 	if err != nil {
 		utilruntime.HandleError(errors.Wrapf(
 			err,
@@ -399,13 +422,10 @@ func (pc *parentController) onChildAdd(obj interface{}) {
 			// The controllerRef isn't a parent we know about.
 			return
 		}
-		glog.V(4).Infof(
-			"%s %s: child %s %s/%s created or updated",
-			pc.parentResource.Kind,
-			parent.GetName(),
-			child.GetKind(),
-			child.GetNamespace(),
-			child.GetName(),
+		pc.log.V(4).Info(
+			"Child created or updated",
+			KeyParent, parentKey(parent),
+			"child", common.DescObjectAsKey(child),
 		)
 		pc.enqueueParentObject(parent)
 		return
@@ -417,12 +437,8 @@ func (pc *parentController) onChildAdd(obj interface{}) {
 	if len(parents) == 0 {
 		return
 	}
-	glog.V(4).Infof(
-		"%s: orphan child %s %s/%s created or updated",
-		pc.parentResource.Kind,
-		child.GetKind(),
-		child.GetNamespace(),
-		child.GetName(),
+	pc.log.V(4).Info(
+		"Orphan child created or updated", "child", common.DescObjectAsKey(child),
 	)
 	for _, parent := range parents {
 		pc.enqueueParentObject(parent)
@@ -484,13 +500,10 @@ func (pc *parentController) onChildDelete(obj interface{}) {
 		// The controllerRef isn't a parent we know about.
 		return
 	}
-	glog.V(4).Infof(
-		"%s %s: child %s %s/%s deleted",
-		pc.parentResource.Kind,
-		parent.GetName(),
-		child.GetKind(),
-		child.GetNamespace(),
-		child.GetName(),
+	pc.log.V(4).Info(
+		"Child deleted",
+		KeyParent, parentKey(parent),
+		"child", common.DescObjectAsKey(child),
 	)
 	pc.enqueueParentObject(parent)
 }
@@ -543,15 +556,13 @@ func (pc *parentController) sync(key string) error {
 		return err
 	}
 
-	glog.V(4).Infof("CompositeController %s: sync %s/%s", pc, namespace, name)
+	log := pc.log.WithValues(KeyParent, key)
+	log.V(4).Info("Syncing parent")
 
 	parent, err := pc.parentInformer.Lister().Get(namespace, name)
 	if apierrors.IsNotFound(err) {
 		// Swallow the error since there's no point retrying if parent is gone.
-		glog.V(4).Infof(
-			"CompositeController %s: parent %s/%s has been deleted",
-			pc, namespace, name,
-		)
+		log.V(4).Info("Parent has been deleted")
 		return nil
 	}
 	if err != nil {
@@ -24,7 +24,6 @@ import (
 	"strings"
 	"sync"
 
-	"github.com/golang/glog"
 	"github.com/pkg/errors"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -191,7 +190,7 @@ func (pc *parentController) syncRevisions(
 	// If any of the sync calls failed, abort.
 	for _, pr := range parentRevisions {
 		if pr.syncError != nil {
-			return nil, fmt.Errorf("sync hook failed for %v %v/%v: %v", pc.parentResource.Kind, parent.GetNamespace(), parent.GetName(), pr.syncError)
+			return nil, errors.Wrapf(pr.syncError, "sync hook failed for %v %v/%v", pc.parentResource.Kind, parent.GetNamespace(), parent.GetName())
 		}
 	}
 
@@ -281,7 +280,11 @@ func (pc *parentController) manageRevisions(parent *unstructured.Unstructured, o
 			opts := &metav1.DeleteOptions{
 				Preconditions: &metav1.Preconditions{UID: &revision.UID},
 			}
-			glog.Infof("%v %v/%v: deleting ControllerRevision %v", parent.GetKind(), parent.GetNamespace(), parent.GetName(), revision.GetName())
+			pc.log.Info(
+				"Deleting ControllerRevision",
+				KeyParent, parentKey(parent),
+				"revision", revision.GetName(),
+			)
 			if err := client.Delete(revision.Name, opts); err != nil {
 				return fmt.Errorf("can't delete ControllerRevision %v for %v %v/%v: %v", revision.Name, pc.parentResource.Kind, parent.GetNamespace(), parent.GetName(), err)
 			}
@@ -296,7 +299,11 @@ func (pc *parentController) manageRevisions(parent *unstructured.Unstructured, o
 				// We didn't change anything.
 				continue
 			}
-			glog.Infof("%v %v/%v: updating ControllerRevision %v", parent.GetKind(), parent.GetNamespace(), parent.GetName(), revision.GetName())
+			pc.log.Info(
+				"Updating ControllerRevision",
+				KeyParent, parentKey(parent),
+				"revision", revision.GetName(),
+			)
 			if _, err := client.Update(revision); err != nil {
 				return fmt.Errorf("can't update ControllerRevision %v for %v %v/%v: %v", revision.Name, pc.parentResource.Kind, parent.GetNamespace(), parent.GetName(), err)
 			}
@@ -304,7 +311,11 @@ func (pc *parentController) manageRevisions(parent *unstructured.Unstructured, o
 			// Create
 			ownerRef := common.MakeOwnerRef(parent)
 			revision.OwnerReferences = append(revision.OwnerReferences, *ownerRef)
-			glog.Infof("%v %v/%v: creating ControllerRevision %v", parent.GetKind(), parent.GetNamespace(), parent.GetName(), revision.GetName())
+			pc.log.Info(
+				"Creating ControllerRevision",
+				KeyParent, parentKey(parent),
+				"revision", revision.GetName(),
+			)
 			if _, err := client.Create(revision); err != nil {
 				return fmt.Errorf("can't create ControllerRevision %v for %v %v/%v: %v", revision.Name, pc.parentResource.Kind, parent.GetNamespace(), parent.GetName(), err)
 			}
@@ -0,0 +1,60 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composite
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+	"openebs.io/metac/controller/common"
+	"openebs.io/metac/logging"
+)
+
+// LogKeyPrefix is prefixed to the name of a CompositeController to
+// form its controller key in the log entries. The log level of this
+// controller is set at runtime via the debug endpoint against this
+// key e.g. "CompositeController:my-controller".
+const LogKeyPrefix = "CompositeController:"
+
+// MetaControllerLogKey is the controller key of the entries logged by
+// the loops that start & stop the CompositeControllers
+const MetaControllerLogKey = "metacontroller:CompositeController"
+
+// KeyParent is the key of the parent an entry refers to
+const KeyParent = "parent"
+
+// logForController returns the logger of the CompositeController of
+// the given name
+func logForController(name string) logging.Logger {
+	return logging.ForController(LogKeyPrefix + name)
+}
+
+// newControllerLogger returns the logger of the given controller that
+// sets the controller's key & the parent's group, version & resource
+// against every entry
+func newControllerLogger(api *v1alpha1.CompositeController) logging.Logger {
+	return logForController(api.Name).WithValues(
+		logging.KeyGVR, api.Spec.ParentResource.APIVersion+"/"+api.Spec.ParentResource.Resource,
+	)
+}
+
+// parentKey returns the key of the given parent as logged against
+// the entries that refer to this parent
+func parentKey(parent *unstructured.Unstructured) string {
+	key, _ := common.KeyFunc(parent)
+	return key
+}
@@ -20,8 +20,6 @@ import (
 	"fmt"
 	"sync"
 
-	"github.com/golang/glog"
-
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -36,6 +34,7 @@ import (
 	dynamicclientset "openebs.io/metac/dynamic/clientset"
 	dynamicdiscovery "openebs.io/metac/dynamic/discovery"
 	dynamicinformer "openebs.io/metac/dynamic/informer"
+	"openebs.io/metac/logging"
 	k8s "openebs.io/metac/third_party/kubernetes"
 )
 
@@ -99,8 +98,8 @@ func (mc *Metacontroller) Start() {
 		defer close(mc.doneCh)
 		defer utilruntime.HandleCrash()
 
-		glog.Info("Starting CompositeController metacontroller")
-		defer glog.Info("Shutting down CompositeController metacontroller")
+		logging.ForController(MetaControllerLogKey).Info("Starting")
+		defer logging.ForController(MetaControllerLogKey).Info("Shutting down")
 
 		if !k8s.WaitForCacheSync("CompositeController", mc.stopCh, mc.informer.HasSynced) {
 			return
@@ -155,11 +154,12 @@ func (mc *Metacontroller) sync(key string) error {
 		return err
 	}
 
-	glog.V(4).Infof("sync CompositeController %v", name)
+	log := logForController(name)
+	log.V(4).Info("Syncing controller")
 
 	cc, err := mc.lister.Get(name)
 	if apierrors.IsNotFound(err) {
-		glog.V(4).Infof("CompositeController %v has been deleted", name)
+		log.V(4).Info("Controller has been deleted")
 		// Stop and remove the controller if it exists.
 		if pc, ok := mc.parentControllers[name]; ok {
 			pc.Stop()
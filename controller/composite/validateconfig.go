@@ -0,0 +1,122 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composite
+
+import (
+	"github.com/pkg/errors"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+	"openebs.io/metac/controller/common"
+	dynamicdiscovery "openebs.io/metac/dynamic/discovery"
+)
+
+// ValidateConfig verifies the given controller as declared i.e. without
+// verifying its resources against the cluster. Problems found by all
+// the validations are returned as a single aggregated error.
+func ValidateConfig(config *v1alpha1.CompositeController) error {
+	var errs []error
+	parent := config.Spec.ParentResource
+	if parent.APIVersion == "" || parent.Resource == "" {
+		errs = append(errs, errors.Errorf(
+			"Invalid parent resource: Specify 'APIVersion' & 'Resource'",
+		))
+	}
+	for _, child := range config.Spec.ChildResources {
+		if child.APIVersion == "" || child.Resource == "" {
+			errs = append(errs, errors.Errorf(
+				"Invalid child resource: Specify 'APIVersion' & 'Resource'",
+			))
+			continue
+		}
+		if child.UpdateStrategy == nil {
+			continue
+		}
+		switch child.UpdateStrategy.Method {
+		case "",
+			v1alpha1.ChildUpdateOnDelete,
+			v1alpha1.ChildUpdateRecreate,
+			v1alpha1.ChildUpdateInPlace,
+			v1alpha1.ChildUpdateRollingRecreate,
+			v1alpha1.ChildUpdateRollingInPlace:
+		default:
+			errs = append(errs, errors.Errorf(
+				"Invalid update method %q of child %s %s",
+				child.UpdateStrategy.Method, child.APIVersion, child.Resource,
+			))
+		}
+	}
+	if config.Spec.ResyncPeriodSeconds != nil && *config.Spec.ResyncPeriodSeconds < 0 {
+		errs = append(errs, errors.Errorf(
+			"Invalid resync period %d: Must be >= 0", *config.Spec.ResyncPeriodSeconds,
+		))
+	}
+	err := validateHooks(config.Spec.Hooks)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// validateHooks returns error if the given hooks can't be invoked
+//
+// NOTE:
+//	Sync hook is mandatory since every parent that is not pending
+// deletion is reconciled via this hook
+func validateHooks(hooks *v1alpha1.CompositeControllerHooks) error {
+	if hooks == nil || hooks.Sync == nil {
+		return errors.Errorf("Invalid hooks: Specify 'Sync'")
+	}
+	var errs []error
+	for _, named := range []struct {
+		name string
+		hook *v1alpha1.Hook
+	}{
+		{"Sync", hooks.Sync},
+		{"Finalize", hooks.Finalize},
+		{"PreUpdateChild", hooks.PreUpdateChild},
+		{"PostUpdateChild", hooks.PostUpdateChild},
+	} {
+		err := common.ValidateHook(named.hook)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "Invalid %s hook", named.name))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// ValidateConfigResources verifies the resources of the given controller
+// are discovered by the given resource manager
+func ValidateConfigResources(
+	resourceMgr *dynamicdiscovery.APIResourceManager, config *v1alpha1.CompositeController,
+) error {
+	var errs []error
+	parent := config.Spec.ParentResource
+	if resourceMgr.GetByResource(parent.APIVersion, parent.Resource) == nil {
+		errs = append(errs, errors.Errorf(
+			"Parent %s %s is not discovered", parent.APIVersion, parent.Resource,
+		))
+	}
+	for _, child := range config.Spec.ChildResources {
+		if resourceMgr.GetByResource(child.APIVersion, child.Resource) == nil {
+			errs = append(errs, errors.Errorf(
+				"Child %s %s is not discovered", child.APIVersion, child.Resource,
+			))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
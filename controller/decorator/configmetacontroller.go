@@ -0,0 +1,133 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package decorator
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+	"openebs.io/metac/config"
+	dynamicclientset "openebs.io/metac/dynamic/clientset"
+	dynamicdiscovery "openebs.io/metac/dynamic/discovery"
+	dynamicinformer "openebs.io/metac/dynamic/informer"
+)
+
+// ConfigBasedMetacontroller runs the DecoratorControllers that are
+// declared in metac configs instead of DecoratorController custom
+// resources
+type ConfigBasedMetacontroller struct {
+	resourceManager *dynamicdiscovery.APIResourceManager
+	clientset       *dynamicclientset.Clientset
+	informerFactory *dynamicinformer.SharedInformerFactory
+
+	workerCount          int
+	decoratorControllers map[string]*decoratorController
+
+	// mutex guards the decorator controllers that are mutated by
+	// config syncs & stop
+	mutex sync.Mutex
+}
+
+// NewConfigBasedMetacontroller returns a new instance of
+// ConfigBasedMetacontroller
+func NewConfigBasedMetacontroller(
+	resourceMgr *dynamicdiscovery.APIResourceManager,
+	clientset *dynamicclientset.Clientset,
+	dynInformers *dynamicinformer.SharedInformerFactory,
+	workerCount int,
+) *ConfigBasedMetacontroller {
+	return &ConfigBasedMetacontroller{
+		resourceManager:      resourceMgr,
+		clientset:            clientset,
+		informerFactory:      dynInformers,
+		workerCount:          workerCount,
+		decoratorControllers: make(map[string]*decoratorController),
+	}
+}
+
+func (mc *ConfigBasedMetacontroller) String() string {
+	return "Local DecoratorController"
+}
+
+// SyncConfigs stops the decorator controllers whose configs got
+// removed or changed & starts the decorator controllers of the
+// DecoratorController configs found in the given configs
+func (mc *ConfigBasedMetacontroller) SyncConfigs(configs config.MetacConfigs) error {
+	dctls, err := configs.ListDecoratorControllers()
+	if err != nil {
+		return errors.Wrapf(err, "%s: Can't list configs", mc)
+	}
+	desired := make(map[string]*v1alpha1.DecoratorController, len(dctls))
+	for _, dc := range dctls {
+		desired[dc.Name] = dc
+	}
+
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	for name, c := range mc.decoratorControllers {
+		dc, found := desired[name]
+		if found && apiequality.Semantic.DeepEqual(dc.Spec, c.schema.Spec) {
+			continue
+		}
+		if found {
+			logForController(name).Info("Will restart controller: Config changed", "metacontroller", mc)
+		} else {
+			logForController(name).Info("Will stop controller: Config removed", "metacontroller", mc)
+		}
+		c.Stop()
+		delete(mc.decoratorControllers, name)
+	}
+
+	var errs []error
+	for _, dc := range dctls {
+		if _, found := mc.decoratorControllers[dc.Name]; found {
+			continue
+		}
+		c, err := newDecoratorController(
+			mc.resourceManager, mc.clientset, mc.informerFactory, dc,
+		)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "%s: Can't start %s", mc, dc.Name))
+			continue
+		}
+		c.Start(mc.workerCount)
+		mc.decoratorControllers[dc.Name] = c
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// Stop stops all the decorator controllers
+func (mc *ConfigBasedMetacontroller) Stop() {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	var wg sync.WaitGroup
+	for _, c := range mc.decoratorControllers {
+		wg.Add(1)
+		go func(c *decoratorController) {
+			defer wg.Done()
+			c.Stop()
+		}(c)
+	}
+	wg.Wait()
+	mc.decoratorControllers = make(map[string]*decoratorController)
+}
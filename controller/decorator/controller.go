@@ -23,7 +23,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/golang/glog"
 	"github.com/pkg/errors"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -42,6 +41,8 @@ import (
 	dynamicdiscovery "openebs.io/metac/dynamic/discovery"
 	dynamicinformer "openebs.io/metac/dynamic/informer"
 	dynamicobject "openebs.io/metac/dynamic/object"
+	"openebs.io/metac/hooks/webhook"
+	"openebs.io/metac/logging"
 	k8s "openebs.io/metac/third_party/kubernetes"
 )
 
@@ -86,9 +87,16 @@ type decoratorController struct {
 	parentInformers common.ResourceInformerRegistryByVR
 	childInformers  common.ResourceInformerRegistryByVR
 
+	// informer of namespaces that is needed to include or exclude
+	// namespaces based on their annotations; this is nil if all the
+	// parent resources are cluster scoped
+	namespaceInformer *dynamicinformer.ResourceInformer
+
 	// instance that deals with this controller's finalizer
 	// if any
 	finalizer *finalizer.Finalizer
+
+	log logging.Logger
 }
 
 // newDecoratorController returns a new instance of decorator
@@ -121,10 +129,19 @@ func newDecoratorController(
 			// gets enabled if Finalize property is set
 			Enabled: schema.Spec.Hooks.Finalize != nil,
 		},
+		log: logForController(schema.Name),
 	}
 
 	var err error
 
+	switch schema.Spec.NamespacePolicy {
+	case "", v1alpha1.NamespacePolicyOptOut, v1alpha1.NamespacePolicyOptIn:
+	default:
+		return nil, errors.Errorf(
+			"invalid namespace policy %q", schema.Spec.NamespacePolicy,
+		)
+	}
+
 	c.parentSelector, err = newDecoratorSelector(resourceMgr, schema)
 	if err != nil {
 		return nil, err
@@ -162,6 +179,9 @@ func newDecoratorController(
 			for _, informer := range c.parentInformers {
 				informer.Close()
 			}
+			if c.namespaceInformer != nil {
+				c.namespaceInformer.Close()
+			}
 		}
 	}()
 
@@ -187,6 +207,32 @@ func newDecoratorController(
 		c.childInformers.Set(child.APIVersion, child.Resource, informer)
 	}
 
+	// init namespace informer if any parent is namespaced
+	for _, parent := range c.parentKinds {
+		if !parent.Namespaced {
+			continue
+		}
+		c.namespaceInformer, err = informerFactory.GetOrCreate("v1", "namespaces")
+		if err != nil {
+			return nil, errors.Errorf(
+				"can't create informer for namespaces: %v", err,
+			)
+		}
+		break
+	}
+
+	// report this controller as the subscriber of its informers
+	subscriber := "DecoratorController " + schema.Name
+	for _, informer := range c.parentInformers {
+		informer.SetSubscriber(subscriber)
+	}
+	for _, informer := range c.childInformers {
+		informer.SetSubscriber(subscriber)
+	}
+	if c.namespaceInformer != nil {
+		c.namespaceInformer.SetSubscriber(subscriber)
+	}
+
 	return c, nil
 }
 
@@ -229,6 +275,12 @@ func (c *decoratorController) Start(workerCount int) {
 			DeleteFunc: c.onChildDelete,
 		})
 	}
+	if c.namespaceInformer != nil {
+		c.namespaceInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc:    c.onNamespaceAdd,
+			UpdateFunc: c.onNamespaceUpdate,
+		})
+	}
 
 	if workerCount <= 0 {
 		workerCount = 5
@@ -240,11 +292,11 @@ func (c *decoratorController) Start(workerCount int) {
 		// provide the ability to run operations after panics
 		defer utilruntime.HandleCrash()
 
-		glog.Infof("Starting DecoratorController %v", c.schema.Name)
-		defer glog.Infof("Shutting down DecoratorController %v", c.schema.Name)
+		c.log.Info("Starting controller")
+		defer c.log.Info("Shutting down controller")
 
 		// Wait for dynamic client and all informers.
-		glog.Infof("Waiting for DecoratorController %v caches to sync", c.schema.Name)
+		c.log.Info("Waiting for caches to sync")
 		syncFuncs := make(
 			[]cache.InformerSynced,
 			0,
@@ -256,15 +308,16 @@ func (c *decoratorController) Start(workerCount int) {
 		for _, informer := range c.childInformers {
 			syncFuncs = append(syncFuncs, informer.Informer().HasSynced)
 		}
+		if c.namespaceInformer != nil {
+			syncFuncs = append(syncFuncs, c.namespaceInformer.Informer().HasSynced)
+		}
 		if !k8s.WaitForCacheSync(c.schema.Name, c.stopCh, syncFuncs...) {
 			// We wait forever unless Stop() is called, so this isn't an error.
-			glog.Warningf(
-				"DecoratorController %v cache sync never finished", c.schema.Name,
-			)
+			c.log.Warning("Cache sync never finished")
 			return
 		}
 
-		glog.Infof("Starting %d workers for %v", workerCount, c.schema.Name)
+		c.log.Info("Starting workers", "count", workerCount)
 		var wg sync.WaitGroup
 		for i := 0; i < workerCount; i++ {
 			wg.Add(1)
@@ -285,11 +338,12 @@ func (c *decoratorController) Stop() {
 	c.queue.ShutDown()
 
 	// IMO since nothing is pushed into doneCh, this will block
-	// till doneCh is closed.
+	// till doneCh is closed or till the drain timeout.
 	//
 	// Note: doneCh will be closed after all the workers are
-	// stopped via above close(c.stopCh) invocation
-	<-c.doneCh
+	// stopped via above close(c.stopCh) invocation i.e. after
+	// the in-flight syncs complete
+	common.WaitForDrain("DecoratorController "+c.schema.Name, c.doneCh)
 
 	// Remove event handlers and close informers for all child resources.
 	for _, informer := range c.childInformers {
@@ -301,6 +355,11 @@ func (c *decoratorController) Stop() {
 		informer.Informer().RemoveEventHandlers()
 		informer.Close()
 	}
+	// Remove event handlers and close informer for namespaces.
+	if c.namespaceInformer != nil {
+		c.namespaceInformer.Informer().RemoveEventHandlers()
+		c.namespaceInformer.Close()
+	}
 }
 
 // worker works for ever. Its only work is to process the
@@ -328,8 +387,23 @@ func (c *decoratorController) processNextWorkItem() bool {
 	}
 	defer c.queue.Done(key)
 
+	// queued parents are not synced once this controller is asked
+	// to stop
+	if common.IsStopped(c.stopCh) {
+		return false
+	}
+
 	// real reconcile logic happens here
 	err := c.sync(key.(string))
+	if err != nil && webhook.IsPermanentError(err) {
+		// hook rejected this request; wait for a change to the
+		// parent or its children or for the next resync
+		utilruntime.HandleError(
+			errors.Errorf("failed to sync %v %q: will not re-queue: %v", c.schema.Name, key, err),
+		)
+		c.queue.Forget(key)
+		return true
+	}
 	if err != nil {
 		utilruntime.HandleError(
 			errors.Errorf("failed to sync %v %q: %v", c.schema.Name, key, err),
@@ -354,6 +428,12 @@ func (c *decoratorController) enqueueParentObject(obj interface{}) {
 			!dynamicobject.HasFinalizer(parent, c.finalizer.Name) {
 			return
 		}
+		// Ignore the parent if its namespace is excluded, unless it
+		// needs to be finalized by us.
+		if !c.isNamespaceSelected(parent.GetNamespace()) &&
+			!dynamicobject.HasFinalizer(parent, c.finalizer.Name) {
+			return
+		}
 	}
 
 	key, err := parentQueueKey(obj)
@@ -366,6 +446,65 @@ func (c *decoratorController) enqueueParentObject(obj interface{}) {
 	c.queue.Add(key)
 }
 
+// isNamespaceSelected returns true if parent resources of the given
+// namespace should be reconciled based on the namespace's annotations
+func (c *decoratorController) isNamespaceSelected(namespace string) bool {
+	if namespace == "" || c.namespaceInformer == nil {
+		return true
+	}
+	nsObj, err := c.namespaceInformer.Lister().Get("", namespace)
+	if err != nil {
+		// namespace will be evaluated again when it gets cached
+		if !apierrors.IsNotFound(err) {
+			c.log.Warning("Can't get namespace", "namespace", namespace, "err", err)
+		}
+		return c.schema.Spec.NamespacePolicy != v1alpha1.NamespacePolicyOptIn
+	}
+	return common.IsNamespaceSelectedByAnnotations(
+		nsObj, c.schema.Spec.NamespacePolicy, c.schema.Name,
+	)
+}
+
+// enqueueParentObjectsInNamespace enqueues all the parent resources
+// that belong to the given namespace
+func (c *decoratorController) enqueueParentObjectsInNamespace(namespace string) {
+	for _, informer := range c.parentInformers {
+		parents, err := informer.Lister().ListNamespace(namespace, labels.Everything())
+		if err != nil {
+			utilruntime.HandleError(
+				errors.Errorf(
+					"can't list parents in namespace %q: %v", namespace, err,
+				),
+			)
+			continue
+		}
+		for _, parent := range parents {
+			c.enqueueParentObject(parent)
+		}
+	}
+}
+
+// onNamespaceAdd enqueues the parent resources of the added namespace
+// since these parents might have been ignored before this namespace
+// got cached
+func (c *decoratorController) onNamespaceAdd(obj interface{}) {
+	if nsObj, ok := obj.(*unstructured.Unstructured); ok {
+		c.enqueueParentObjectsInNamespace(nsObj.GetName())
+	}
+}
+
+// onNamespaceUpdate enqueues the parent resources of the namespace if
+// the namespace got included or excluded via annotations
+func (c *decoratorController) onNamespaceUpdate(old, cur interface{}) {
+	oldNS, _ := old.(*unstructured.Unstructured)
+	curNS, _ := cur.(*unstructured.Unstructured)
+	if oldNS == nil || curNS == nil ||
+		!common.IsNamespaceAnnotationsChanged(oldNS, curNS) {
+		return
+	}
+	c.enqueueParentObjectsInNamespace(curNS.GetName())
+}
+
 func (c *decoratorController) enqueueParentObjectAfter(obj interface{}, delay time.Duration) {
 	key, err := parentQueueKey(obj)
 	if err != nil {
@@ -451,14 +590,10 @@ func (c *decoratorController) onChildAdd(obj interface{}) {
 		// The controllerRef isn't a parent we know about.
 		return
 	}
-	glog.V(4).Infof(
-		"DecoratorController %v: %v %v/%v: child %v %v created or updated",
-		c.schema.Name,
-		parent.GetKind(),
-		parent.GetNamespace(),
-		parent.GetName(),
-		child.GetKind(),
-		child.GetName(),
+	c.log.V(4).Info(
+		"Child created or updated",
+		KeyParent, parentKey(parent),
+		"child", common.DescObjectAsKey(child),
 	)
 	c.enqueueParentObject(parent)
 }
@@ -516,14 +651,10 @@ func (c *decoratorController) onChildDelete(obj interface{}) {
 		// The controllerRef isn't a parent we know about.
 		return
 	}
-	glog.V(4).Infof(
-		"DecoratorController %v: %v %v/%v: child %v %v deleted",
-		c.schema.Name,
-		parent.GetKind(),
-		parent.GetNamespace(),
-		parent.GetName(),
-		child.GetKind(),
-		child.GetName(),
+	c.log.V(4).Info(
+		"Child deleted",
+		KeyParent, parentKey(parent),
+		"child", common.DescObjectAsKey(child),
 	)
 	c.enqueueParentObject(parent)
 }
@@ -551,7 +682,7 @@ func (c *decoratorController) sync(key string) error {
 	parent, err := informer.Lister().Get(namespace, name)
 	if apierrors.IsNotFound(err) {
 		// Swallow the error since there's no point retrying if the parent is gone.
-		glog.V(4).Infof("%v %v/%v has been deleted", kind, namespace, name)
+		c.log.V(4).Info("Parent has been deleted", KeyParent, key)
 		return nil
 	}
 	if err != nil {
@@ -568,10 +699,8 @@ func (c *decoratorController) syncParentObject(parent *unstructured.Unstructured
 		return nil
 	}
 
-	glog.V(4).Infof(
-		"DecoratorController %v: sync %v %v/%v",
-		c.schema.Name, parent.GetKind(), parent.GetNamespace(), parent.GetName(),
-	)
+	log := c.log.WithValues(KeyParent, parentKey(parent))
+	log.V(4).Info("Syncing parent")
 
 	parentClient, err :=
 		c.dynCliSet.GetClientByKind(parent.GetAPIVersion(), parent.GetKind())
@@ -678,7 +807,7 @@ func (c *decoratorController) syncParentObject(parent *unstructured.Unstructured
 			dynamicobject.RemoveFinalizer(updatedParent, c.finalizer.Name)
 		}
 
-		glog.V(4).Infof("DecoratorController %v: updating %v %v/%v", c.schema.Name, parent.GetKind(), parent.GetNamespace(), parent.GetName())
+		log.V(4).Info("Updating parent")
 		_, err = parentClient.
 			Namespace(parent.GetNamespace()).Update(updatedParent, metav1.UpdateOptions{})
 		if err != nil {
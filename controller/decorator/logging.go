@@ -0,0 +1,49 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package decorator
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"openebs.io/metac/controller/common"
+	"openebs.io/metac/logging"
+)
+
+// LogKeyPrefix is prefixed to the name of a DecoratorController to
+// form its controller key in the log entries. The log level of this
+// controller is set at runtime via the debug endpoint against this
+// key e.g. "DecoratorController:my-controller".
+const LogKeyPrefix = "DecoratorController:"
+
+// MetaControllerLogKey is the controller key of the entries logged by
+// the loops that start & stop the DecoratorControllers
+const MetaControllerLogKey = "metacontroller:DecoratorController"
+
+// KeyParent is the key of the parent an entry refers to
+const KeyParent = "parent"
+
+// logForController returns the logger of the DecoratorController of
+// the given name
+func logForController(name string) logging.Logger {
+	return logging.ForController(LogKeyPrefix + name)
+}
+
+// parentKey returns the key of the given parent as logged against
+// the entries that refer to this parent
+func parentKey(parent *unstructured.Unstructured) string {
+	return common.DescObjectAsKey(parent)
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package decorator
+
+import (
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+	"openebs.io/metac/controller/common"
+	dynamicdiscovery "openebs.io/metac/dynamic/discovery"
+)
+
+// ValidateConfig verifies the given controller as declared i.e. without
+// verifying its resources against the cluster. Problems found by all
+// the validations are returned as a single aggregated error.
+func ValidateConfig(config *v1alpha1.DecoratorController) error {
+	var errs []error
+	if len(config.Spec.Resources) == 0 {
+		errs = append(errs, errors.Errorf("Invalid resources: Specify at least one resource"))
+	}
+	for _, parent := range config.Spec.Resources {
+		if parent.APIVersion == "" || parent.Resource == "" {
+			errs = append(errs, errors.Errorf(
+				"Invalid resource: Specify 'APIVersion' & 'Resource'",
+			))
+			continue
+		}
+		err := validateSelectors(parent)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, child := range config.Spec.Attachments {
+		if child.APIVersion == "" || child.Resource == "" {
+			errs = append(errs, errors.Errorf(
+				"Invalid attachment: Specify 'APIVersion' & 'Resource'",
+			))
+			continue
+		}
+		if child.UpdateStrategy == nil {
+			continue
+		}
+		switch child.UpdateStrategy.Method {
+		case "",
+			v1alpha1.ChildUpdateOnDelete,
+			v1alpha1.ChildUpdateRecreate,
+			v1alpha1.ChildUpdateInPlace,
+			v1alpha1.ChildUpdateRollingRecreate,
+			v1alpha1.ChildUpdateRollingInPlace:
+		default:
+			errs = append(errs, errors.Errorf(
+				"Invalid update method %q of attachment %s %s",
+				child.UpdateStrategy.Method, child.APIVersion, child.Resource,
+			))
+		}
+	}
+	switch config.Spec.NamespacePolicy {
+	case "", v1alpha1.NamespacePolicyOptOut, v1alpha1.NamespacePolicyOptIn:
+	default:
+		errs = append(errs, errors.Errorf(
+			"Invalid namespace policy %q", config.Spec.NamespacePolicy,
+		))
+	}
+	if config.Spec.ResyncPeriodSeconds != nil && *config.Spec.ResyncPeriodSeconds < 0 {
+		errs = append(errs, errors.Errorf(
+			"Invalid resync period %d: Must be >= 0", *config.Spec.ResyncPeriodSeconds,
+		))
+	}
+	err := validateHooks(config.Spec.Hooks)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// validateSelectors returns error if the selectors of the given
+// resource can't be converted to the form used to match the parents
+func validateSelectors(parent v1alpha1.DecoratorControllerResourceRule) error {
+	if parent.LabelSelector != nil {
+		_, err := metav1.LabelSelectorAsSelector(parent.LabelSelector)
+		if err != nil {
+			return errors.Wrapf(
+				err,
+				"Invalid label selector of resource %s %s",
+				parent.APIVersion,
+				parent.Resource,
+			)
+		}
+	}
+	if parent.AnnotationSelector != nil {
+		// annotation selector is matched as a label selector
+		_, err := metav1.LabelSelectorAsSelector(&metav1.LabelSelector{
+			MatchLabels:      parent.AnnotationSelector.MatchAnnotations,
+			MatchExpressions: parent.AnnotationSelector.MatchExpressions,
+		})
+		if err != nil {
+			return errors.Wrapf(
+				err,
+				"Invalid annotation selector of resource %s %s",
+				parent.APIVersion,
+				parent.Resource,
+			)
+		}
+	}
+	return nil
+}
+
+// validateHooks returns error if the given hooks can't be invoked
+//
+// NOTE:
+//	Sync hook is mandatory since every parent that is not pending
+// deletion is reconciled via this hook
+func validateHooks(hooks *v1alpha1.DecoratorControllerHooks) error {
+	if hooks == nil || hooks.Sync == nil {
+		return errors.Errorf("Invalid hooks: Specify 'Sync'")
+	}
+	var errs []error
+	err := common.ValidateHook(hooks.Sync)
+	if err != nil {
+		errs = append(errs, errors.Wrapf(err, "Invalid Sync hook"))
+	}
+	err = common.ValidateHook(hooks.Finalize)
+	if err != nil {
+		errs = append(errs, errors.Wrapf(err, "Invalid Finalize hook"))
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// ValidateConfigResources verifies the resources of the given controller
+// are discovered by the given resource manager
+func ValidateConfigResources(
+	resourceMgr *dynamicdiscovery.APIResourceManager, config *v1alpha1.DecoratorController,
+) error {
+	var errs []error
+	for _, parent := range config.Spec.Resources {
+		if resourceMgr.GetByResource(parent.APIVersion, parent.Resource) == nil {
+			errs = append(errs, errors.Errorf(
+				"Resource %s %s is not discovered", parent.APIVersion, parent.Resource,
+			))
+		}
+	}
+	for _, child := range config.Spec.Attachments {
+		if resourceMgr.GetByResource(child.APIVersion, child.Resource) == nil {
+			errs = append(errs, errors.Errorf(
+				"Attachment %s %s is not discovered", child.APIVersion, child.Resource,
+			))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
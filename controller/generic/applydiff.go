@@ -0,0 +1,108 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	"openebs.io/metac/controller/common"
+	dynamicapply "openebs.io/metac/dynamic/apply"
+)
+
+// watchApplyDiffs holds the fields of the attachments that got
+// updated by the most recent sync of a watch
+type watchApplyDiffs struct {
+	// uid of the watch; diffs of a watch that got deleted &
+	// recreated with the same name are not used
+	uid types.UID
+
+	diffs []AttachmentApplyDiff
+}
+
+// isIncludeApplyDiffs returns true if the sync hook requests should
+// include the fields of the attachments updated by previous syncs
+func (mgr *watchController) isIncludeApplyDiffs() bool {
+	return mgr.GCtlConfig.Spec.IncludeApplyDiffs != nil &&
+		*mgr.GCtlConfig.Spec.IncludeApplyDiffs
+}
+
+// takeApplyDiffs returns the fields of the attachments that got
+// updated by the previous sync of the given watch. These diffs are
+// no longer tracked once returned.
+func (mgr *watchController) takeApplyDiffs(
+	watch *unstructured.Unstructured,
+) []AttachmentApplyDiff {
+	if !mgr.isIncludeApplyDiffs() {
+		return nil
+	}
+	key, err := makeWatchQueueKey(watch)
+	if err != nil {
+		mgr.log.Warning("Can't get apply diffs", "err", err)
+		return nil
+	}
+
+	mgr.applyDiffMutex.Lock()
+	defer mgr.applyDiffMutex.Unlock()
+
+	tracked, found := mgr.applyDiffs[key]
+	delete(mgr.applyDiffs, key)
+	if !found || tracked.uid != watch.GetUID() {
+		return nil
+	}
+	return tracked.diffs
+}
+
+// setApplyDiffs tracks the given diffs as the fields of the
+// attachments that got updated by the current sync of the given watch
+func (mgr *watchController) setApplyDiffs(
+	watch *unstructured.Unstructured, diffs []AttachmentApplyDiff,
+) {
+	if !mgr.isIncludeApplyDiffs() || len(diffs) == 0 {
+		return
+	}
+	key, err := makeWatchQueueKey(watch)
+	if err != nil {
+		mgr.log.Warning("Can't set apply diffs", "err", err)
+		return
+	}
+
+	mgr.applyDiffMutex.Lock()
+	defer mgr.applyDiffMutex.Unlock()
+
+	mgr.applyDiffs[key] = watchApplyDiffs{uid: watch.GetUID(), diffs: diffs}
+}
+
+// forgetApplyDiffs stops tracking the diffs of the watch having the
+// given key
+func (mgr *watchController) forgetApplyDiffs(key string) {
+	mgr.applyDiffMutex.Lock()
+	defer mgr.applyDiffMutex.Unlock()
+
+	delete(mgr.applyDiffs, key)
+}
+
+// makeApplyDiff returns the fields of the given attachment that got
+// updated
+func makeApplyDiff(
+	obj *unstructured.Unstructured, diffs []dynamicapply.FieldDiff,
+) AttachmentApplyDiff {
+	return AttachmentApplyDiff{
+		Attachment: common.DescObjectAsKey(obj),
+		Diffs:      diffs,
+	}
+}
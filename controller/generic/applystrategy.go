@@ -0,0 +1,126 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"github.com/pkg/errors"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+	"openebs.io/metac/controller/common"
+)
+
+// maxFieldManagerLength is the maximum length of a field manager
+// name that is accepted by the API server
+const maxFieldManagerLength = 128
+
+// ValidateApplyStrategy returns error if the given strategy is not
+// supported
+func ValidateApplyStrategy(strategy v1alpha1.ApplyStrategy) error {
+	switch strategy {
+	case "",
+		v1alpha1.ApplyStrategyLastApplied,
+		v1alpha1.ApplyStrategyServerSideApply,
+		v1alpha1.ApplyStrategyManagedFields:
+		return nil
+	default:
+		return errors.Errorf("Invalid apply strategy %q", strategy)
+	}
+}
+
+// getApplyStrategy returns the strategy to be used to apply the
+// attachments of the given controller. The given default strategy is
+// used if the controller does not set one.
+func getApplyStrategy(
+	config *v1alpha1.GenericController, defaultStrategy v1alpha1.ApplyStrategy,
+) v1alpha1.ApplyStrategy {
+	if config.Spec.ApplyStrategy != "" {
+		return config.Spec.ApplyStrategy
+	}
+	if defaultStrategy != "" {
+		return defaultStrategy
+	}
+	return v1alpha1.ApplyStrategyLastApplied
+}
+
+// getFieldManager returns the name of the field manager that owns
+// the fields applied by the given controller via server side apply or
+// via managed fields based 3-way merge
+func getFieldManager(config *v1alpha1.GenericController) string {
+	if config.Spec.ServerSideApply == nil ||
+		config.Spec.ServerSideApply.FieldManager == nil {
+		return "gctl.metac.openebs.io/" +
+			common.DescMetaAsSanitisedNSName(config.GetObjectMeta())
+	}
+	return *config.Spec.ServerSideApply.FieldManager
+}
+
+// isForceApplyConflicts returns true if the given controller should
+// take over the fields managed by other field managers during server
+// side apply
+func isForceApplyConflicts(config *v1alpha1.GenericController) bool {
+	return config.Spec.ServerSideApply == nil ||
+		config.Spec.ServerSideApply.ConflictPolicy != v1alpha1.ServerSideApplyConflictFail
+}
+
+// validateServerSideApply returns error if the apply strategy or
+// the server side apply tunables of the given controller are invalid
+func validateServerSideApply(config *v1alpha1.GenericController) error {
+	err := ValidateApplyStrategy(config.Spec.ApplyStrategy)
+	if err != nil {
+		return err
+	}
+	if config.Spec.ServerSideApply == nil {
+		return nil
+	}
+	switch config.Spec.ServerSideApply.ConflictPolicy {
+	case "", v1alpha1.ServerSideApplyConflictForce, v1alpha1.ServerSideApplyConflictFail:
+	default:
+		return errors.Errorf(
+			"Invalid server side apply conflict policy %q",
+			config.Spec.ServerSideApply.ConflictPolicy,
+		)
+	}
+	manager := getFieldManager(config)
+	if manager == "" || len(manager) > maxFieldManagerLength {
+		return errors.Errorf(
+			"Invalid field manager %q: Must be 1 to %d characters",
+			manager, maxFieldManagerLength,
+		)
+	}
+	return nil
+}
+
+// isManagedFieldsNeeded returns true if the given controller reads
+// metadata.managedFields of its observed attachments when these are
+// applied via the given strategy
+func isManagedFieldsNeeded(
+	config *v1alpha1.GenericController, strategy v1alpha1.ApplyStrategy,
+) bool {
+	switch strategy {
+	case v1alpha1.ApplyStrategyManagedFields:
+		return true
+	case v1alpha1.ApplyStrategyServerSideApply:
+		// conflicts are found from the fields owned by other
+		// field managers
+		for _, a := range config.Spec.Attachments {
+			if a.ConflictPolicy != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,523 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+	"openebs.io/metac/controller/common"
+	dynamicdiscovery "openebs.io/metac/dynamic/discovery"
+)
+
+// attachmentRuleManager provides lookup of attachment rules declared
+// in a GenericController based on api group & kind of the attachment
+type attachmentRuleManager struct {
+	// rules holds the attachment rules anchored by api group
+	// & kind
+	rules map[string]*v1alpha1.GenericControllerAttachment
+}
+
+// String implements Stringer interface
+func (mgr attachmentRuleManager) String() string {
+	return "attachmentRuleManager"
+}
+
+// makeAttachmentRuleKeyFromGK builds a key to be used in attachment
+// rule registry. This key is built out of the provided api group &
+// kind.
+func makeAttachmentRuleKeyFromGK(apiGroup, kind string) string {
+	return fmt.Sprintf("%s.%s", kind, apiGroup)
+}
+
+// newAttachmentRuleManager returns a new instance of
+// attachmentRuleManager
+func newAttachmentRuleManager(
+	resourceMgr *dynamicdiscovery.APIResourceManager,
+	attachments []v1alpha1.GenericControllerAttachment,
+) *attachmentRuleManager {
+	mgr := &attachmentRuleManager{
+		rules: make(map[string]*v1alpha1.GenericControllerAttachment),
+	}
+	for idx := range attachments {
+		attachment := &attachments[idx]
+		// this is done to map resource name to kind name
+		resource := resourceMgr.GetByResource(attachment.APIVersion, attachment.Resource)
+		if resource == nil {
+			if glog.V(2) {
+				glog.Warningf("%s: Can't find resource %s/%s",
+					mgr,
+					attachment.APIVersion,
+					attachment.Resource,
+				)
+			}
+			continue
+		}
+		// Ignore API version.
+		apiGroup, _ := common.ParseAPIVersionToGroupVersion(attachment.APIVersion)
+		mgr.rules[makeAttachmentRuleKeyFromGK(apiGroup, resource.Kind)] = attachment
+	}
+	return mgr
+}
+
+// getRuleByGK returns the attachment rule based on the given api
+// group & kind
+func (mgr attachmentRuleManager) getRuleByGK(
+	apiGroup, kind string,
+) *v1alpha1.GenericControllerAttachment {
+	return mgr.rules[makeAttachmentRuleKeyFromGK(apiGroup, kind)]
+}
+
+// IsReadOnlyByGK returns true if attachments based on the given
+// api group & kind should never be created, updated or deleted
+func (mgr attachmentRuleManager) IsReadOnlyByGK(apiGroup, kind string) bool {
+	rule := mgr.getRuleByGK(apiGroup, kind)
+	if rule == nil || rule.ReadOnly == nil {
+		return false
+	}
+	return *rule.ReadOnly
+}
+
+// IsCreateOnlyByGK returns true if attachments based on the given
+// api group & kind should only be created & never be updated or
+// deleted afterwards
+func (mgr attachmentRuleManager) IsCreateOnlyByGK(apiGroup, kind string) bool {
+	rule := mgr.getRuleByGK(apiGroup, kind)
+	if rule == nil {
+		return false
+	}
+	return rule.UpdatePolicy == v1alpha1.AttachmentUpdatePolicyCreateOnly
+}
+
+// GetDeletionPropagationByGK returns the propagation policy to be
+// used while deleting attachments based on the given api group & kind
+func (mgr attachmentRuleManager) GetDeletionPropagationByGK(
+	apiGroup, kind string,
+) metav1.DeletionPropagation {
+	rule := mgr.getRuleByGK(apiGroup, kind)
+	if rule == nil || rule.DeletionPropagation == nil ||
+		*rule.DeletionPropagation == "" {
+		// Explicitly request deletion propagation, which is what
+		// users expect, since some objects default to orphaning
+		// for backwards compatibility.
+		return metav1.DeletePropagationBackground
+	}
+	return *rule.DeletionPropagation
+}
+
+// GetAdoptPolicyByGK returns the policy to be followed to adopt
+// pre-existing attachments based on the given api group & kind
+func (mgr attachmentRuleManager) GetAdoptPolicyByGK(
+	apiGroup, kind string,
+) v1alpha1.GenericControllerAttachmentAdoptPolicy {
+	rule := mgr.getRuleByGK(apiGroup, kind)
+	if rule == nil || rule.Adopt == "" {
+		return v1alpha1.AttachmentAdoptPolicyNever
+	}
+	return rule.Adopt
+}
+
+// GetListMapKeysByGK returns the merge keys of the list fields of
+// attachments based on the given api group & kind. These keys are
+// anchored by the dot separated paths of the list fields. It returns
+// nil if no merge keys are declared.
+func (mgr attachmentRuleManager) GetListMapKeysByGK(
+	apiGroup, kind string,
+) map[string]string {
+	rule := mgr.getRuleByGK(apiGroup, kind)
+	if rule == nil || len(rule.ListMergeKeys) == 0 {
+		return nil
+	}
+	keys := make(map[string]string, len(rule.ListMergeKeys))
+	for _, mergeKey := range rule.ListMergeKeys {
+		keys[mergeKey.Path] = mergeKey.Key
+	}
+	return keys
+}
+
+// GetIgnorePathsByGK returns the fields of attachments based on the
+// given api group & kind that should never be updated. Each field is
+// represented by its path. It returns nil if no fields are ignored.
+func (mgr attachmentRuleManager) GetIgnorePathsByGK(
+	apiGroup, kind string,
+) [][]string {
+	rule := mgr.getRuleByGK(apiGroup, kind)
+	if rule == nil || len(rule.IgnorePaths) == 0 {
+		return nil
+	}
+	paths := make([][]string, 0, len(rule.IgnorePaths))
+	for _, path := range rule.IgnorePaths {
+		paths = append(paths, strings.Split(path, "."))
+	}
+	return paths
+}
+
+// GetConflictPolicyByGK returns the policy to be followed when the
+// desired fields of attachments based on the given api group & kind
+// were changed by someone else. It returns empty policy if none is
+// declared.
+func (mgr attachmentRuleManager) GetConflictPolicyByGK(
+	apiGroup, kind string,
+) v1alpha1.GenericControllerAttachmentConflictPolicy {
+	rule := mgr.getRuleByGK(apiGroup, kind)
+	if rule == nil {
+		return ""
+	}
+	return rule.ConflictPolicy
+}
+
+// IsDryRunCompareByGK returns true if attachments based on the given
+// api group & kind should be compared with their observed state via a
+// dry run update before being updated
+func (mgr attachmentRuleManager) IsDryRunCompareByGK(apiGroup, kind string) bool {
+	rule := mgr.getRuleByGK(apiGroup, kind)
+	if rule == nil || rule.DryRunCompare == nil {
+		return false
+	}
+	return *rule.DryRunCompare
+}
+
+// GetMergeModeByGK returns the way desired state of attachments based
+// on the given api group & kind is merged with their observed state
+func (mgr attachmentRuleManager) GetMergeModeByGK(
+	apiGroup, kind string,
+) v1alpha1.GenericControllerAttachmentMergeMode {
+	rule := mgr.getRuleByGK(apiGroup, kind)
+	if rule == nil || rule.MergeMode == "" {
+		return v1alpha1.AttachmentMergeModeThreeWay
+	}
+	return rule.MergeMode
+}
+
+// GetMaxCountByGK returns the maximum number of attachments based on
+// the given api group & kind that can be desired per watch. It
+// returns nil if there is no such limit.
+func (mgr attachmentRuleManager) GetMaxCountByGK(apiGroup, kind string) *int32 {
+	rule := mgr.getRuleByGK(apiGroup, kind)
+	if rule == nil {
+		return nil
+	}
+	return rule.MaxCount
+}
+
+// getMaxDeletions returns the maximum number of attachments that
+// can be deleted by a single sync based on the given number of
+// observed attachments. It returns nil if there is no such limit.
+//
+// NOTE:
+//	A percentage is rounded up. Hence a non zero percentage allows
+// at least one deletion irrespective of the observed attachments.
+func getMaxDeletions(config *v1alpha1.GenericController, observedCount int) *int {
+	if config.Spec.MaxDeletionsPerSync == nil {
+		return nil
+	}
+	// this has been validated earlier
+	max, _ := intstr.GetValueFromIntOrPercent(
+		config.Spec.MaxDeletionsPerSync, observedCount, true,
+	)
+	return &max
+}
+
+// defaultMaxUpdateConflictRetries is the number of times an update of
+// an attachment that fails due to a conflict is retried by default
+const defaultMaxUpdateConflictRetries = 3
+
+// getMaxUpdateConflictRetries returns the number of times an update
+// of an attachment that fails due to a conflict is retried
+func getMaxUpdateConflictRetries(config *v1alpha1.GenericController) int {
+	if config.Spec.MaxUpdateConflictRetries == nil {
+		return defaultMaxUpdateConflictRetries
+	}
+	return int(*config.Spec.MaxUpdateConflictRetries)
+}
+
+// validateUniqueAttachmentKinds returns error if more than one explicit
+// attachment of the given controller refer to the same api group &
+// kind. Rules of attachments are looked up by api group & kind & hence
+// such attachments would share the rules of one of them.
+//
+// NOTE:
+//	Attachments that are not discovered by the given resource manager
+// are not verified
+func validateUniqueAttachmentKinds(
+	resourceMgr *dynamicdiscovery.APIResourceManager,
+	config *v1alpha1.GenericController,
+) error {
+	// attachments anchored by their api group & kind
+	declared := make(map[string]v1alpha1.GenericControllerAttachment)
+	for _, attachment := range config.Spec.Attachments {
+		if isWildcardAttachment(attachment) {
+			// wildcards never expand to an explicit attachment's kind
+			continue
+		}
+		resource := resourceMgr.GetByResource(attachment.APIVersion, attachment.Resource)
+		if resource == nil {
+			continue
+		}
+		key := makeAttachmentRuleKeyFromGK(resource.Group, resource.Kind)
+		if other, found := declared[key]; found {
+			return errors.Errorf(
+				"Duplicate attachments %s/%s & %s/%s: Only one attachment per kind %s is supported",
+				other.APIVersion,
+				other.Resource,
+				attachment.APIVersion,
+				attachment.Resource,
+				key,
+			)
+		}
+		declared[key] = attachment
+	}
+	return nil
+}
+
+// validateAttachmentRules verifies the attachment rules declared in
+// the given GenericController
+//
+// NOTE:
+//	Attachments of the same api group & resource are rejected here
+// since their rules can't be told apart. Attachments that refer to the
+// same kind via aliases are verified via validateUniqueAttachmentKinds
+// once these are discovered.
+func validateAttachmentRules(config *v1alpha1.GenericController) error {
+	if config.Spec.MaxAttachments != nil && *config.Spec.MaxAttachments < 0 {
+		return errors.Errorf(
+			"Invalid max attachments %d", *config.Spec.MaxAttachments,
+		)
+	}
+	if config.Spec.MaxUpdateConflictRetries != nil &&
+		*config.Spec.MaxUpdateConflictRetries < 0 {
+		return errors.Errorf(
+			"Invalid max update conflict retries %d",
+			*config.Spec.MaxUpdateConflictRetries,
+		)
+	}
+	if config.Spec.MaxDeletionsPerSync != nil {
+		max, err := intstr.GetValueFromIntOrPercent(
+			config.Spec.MaxDeletionsPerSync, 100, false,
+		)
+		if err != nil {
+			return errors.Wrapf(err, "Invalid max deletions per sync")
+		}
+		if max < 0 {
+			return errors.Errorf(
+				"Invalid max deletions per sync %s",
+				config.Spec.MaxDeletionsPerSync.String(),
+			)
+		}
+	}
+	// attachments anchored by their api group & resource
+	declared := make(map[string]v1alpha1.GenericControllerAttachment)
+	for _, attachment := range config.Spec.Attachments {
+		if !isWildcardAttachment(attachment) {
+			apiGroup, _ := common.ParseAPIVersionToGroupVersion(attachment.APIVersion)
+			key := fmt.Sprintf("%s.%s", attachment.Resource, apiGroup)
+			if other, found := declared[key]; found {
+				return errors.Errorf(
+					"Duplicate attachments %s/%s & %s/%s: Only one attachment per resource %s is supported",
+					other.APIVersion,
+					other.Resource,
+					attachment.APIVersion,
+					attachment.Resource,
+					key,
+				)
+			}
+			declared[key] = attachment
+		}
+		if attachment.DeletionPropagation != nil {
+			// NOTE:
+			//	An empty propagation falls back to the default
+			// i.e. background propagation
+			switch *attachment.DeletionPropagation {
+			case "",
+				metav1.DeletePropagationForeground,
+				metav1.DeletePropagationBackground,
+				metav1.DeletePropagationOrphan:
+			default:
+				return errors.Errorf(
+					"Invalid deletion propagation %q for attachment %s/%s",
+					*attachment.DeletionPropagation,
+					attachment.APIVersion,
+					attachment.Resource,
+				)
+			}
+		}
+		switch attachment.Adopt {
+		case "",
+			v1alpha1.AttachmentAdoptPolicyNever,
+			v1alpha1.AttachmentAdoptPolicyIfUnowned,
+			v1alpha1.AttachmentAdoptPolicyAlways:
+		default:
+			return errors.Errorf(
+				"Invalid adopt policy %q for attachment %s/%s",
+				attachment.Adopt,
+				attachment.APIVersion,
+				attachment.Resource,
+			)
+		}
+		switch attachment.ConflictPolicy {
+		case "",
+			v1alpha1.AttachmentConflictPolicyForce,
+			v1alpha1.AttachmentConflictPolicyYield,
+			v1alpha1.AttachmentConflictPolicyFail:
+		default:
+			return errors.Errorf(
+				"Invalid conflict policy %q for attachment %s/%s",
+				attachment.ConflictPolicy,
+				attachment.APIVersion,
+				attachment.Resource,
+			)
+		}
+		if attachment.UpdateStrategy != nil {
+			switch attachment.UpdateStrategy.PatchType {
+			case "",
+				v1alpha1.AttachmentPatchTypeStrategicMerge,
+				v1alpha1.AttachmentPatchTypeMerge,
+				v1alpha1.AttachmentPatchTypeJSON:
+			default:
+				return errors.Errorf(
+					"Invalid patch type %q for attachment %s/%s",
+					attachment.UpdateStrategy.PatchType,
+					attachment.APIVersion,
+					attachment.Resource,
+				)
+			}
+		}
+		switch attachment.MergeMode {
+		case "",
+			v1alpha1.AttachmentMergeModeThreeWay,
+			v1alpha1.AttachmentMergeModePartial:
+		default:
+			return errors.Errorf(
+				"Invalid merge mode %q for attachment %s/%s",
+				attachment.MergeMode,
+				attachment.APIVersion,
+				attachment.Resource,
+			)
+		}
+		if isMetadataOnly(attachment) {
+			if !isReadOnlyAttachment(config, attachment) {
+				return errors.Errorf(
+					"Invalid metadata only attachment %s/%s: Attachment isn't read only",
+					attachment.APIVersion,
+					attachment.Resource,
+				)
+			}
+			if attachment.ResourceSelector != nil {
+				return errors.Errorf(
+					"Invalid metadata only attachment %s/%s: Resource selector isn't supported",
+					attachment.APIVersion,
+					attachment.Resource,
+				)
+			}
+		}
+		if attachment.MaxCount != nil && *attachment.MaxCount < 0 {
+			return errors.Errorf(
+				"Invalid max count %d for attachment %s/%s",
+				*attachment.MaxCount,
+				attachment.APIVersion,
+				attachment.Resource,
+			)
+		}
+		err := validateListMergeKeys(attachment)
+		if err != nil {
+			return err
+		}
+		err = validateIgnorePaths(attachment)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateListMergeKeys verifies the list merge keys declared in the
+// given attachment
+func validateListMergeKeys(attachment v1alpha1.GenericControllerAttachment) error {
+	paths := make(map[string]bool, len(attachment.ListMergeKeys))
+	for _, mergeKey := range attachment.ListMergeKeys {
+		if mergeKey.Key == "" {
+			return errors.Errorf(
+				"Invalid list merge key for path %q of attachment %s/%s: Key is required",
+				mergeKey.Path,
+				attachment.APIVersion,
+				attachment.Resource,
+			)
+		}
+		for _, field := range strings.Split(mergeKey.Path, ".") {
+			if field == "" {
+				return errors.Errorf(
+					"Invalid list merge key path %q of attachment %s/%s",
+					mergeKey.Path,
+					attachment.APIVersion,
+					attachment.Resource,
+				)
+			}
+		}
+		if paths[mergeKey.Path] {
+			return errors.Errorf(
+				"Invalid list merge keys of attachment %s/%s: Duplicate path %q",
+				attachment.APIVersion,
+				attachment.Resource,
+				mergeKey.Path,
+			)
+		}
+		paths[mergeKey.Path] = true
+	}
+	return nil
+}
+
+// ignoreProtectedPaths are the fields that identify a resource & hence
+// can't be ignored
+var ignoreProtectedPaths = map[string]bool{
+	"apiVersion":         true,
+	"kind":               true,
+	"metadata":           true,
+	"metadata.name":      true,
+	"metadata.namespace": true,
+}
+
+// validateIgnorePaths verifies the ignore paths declared in the given
+// attachment
+func validateIgnorePaths(attachment v1alpha1.GenericControllerAttachment) error {
+	for _, path := range attachment.IgnorePaths {
+		for _, field := range strings.Split(path, ".") {
+			if field == "" {
+				return errors.Errorf(
+					"Invalid ignore path %q of attachment %s/%s",
+					path,
+					attachment.APIVersion,
+					attachment.Resource,
+				)
+			}
+		}
+		if ignoreProtectedPaths[path] {
+			return errors.Errorf(
+				"Invalid ignore path %q of attachment %s/%s: Path can't be ignored",
+				path,
+				attachment.APIVersion,
+				attachment.Resource,
+			)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,358 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+)
+
+func deletionPropagationPtr(
+	propagation metav1.DeletionPropagation,
+) *metav1.DeletionPropagation {
+	return &propagation
+}
+
+func TestAttachmentRuleManagerIsCreateOnlyByGK(t *testing.T) {
+	var tests = map[string]struct {
+		rule   *v1alpha1.GenericControllerAttachment
+		expect bool
+	}{
+		"no rule": {
+			expect: false,
+		},
+		"no update policy": {
+			rule:   &v1alpha1.GenericControllerAttachment{},
+			expect: false,
+		},
+		"create only update policy": {
+			rule: &v1alpha1.GenericControllerAttachment{
+				UpdatePolicy: v1alpha1.AttachmentUpdatePolicyCreateOnly,
+			},
+			expect: true,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			mgr := &attachmentRuleManager{
+				rules: map[string]*v1alpha1.GenericControllerAttachment{},
+			}
+			if mock.rule != nil {
+				mgr.rules[makeAttachmentRuleKeyFromGK("apps", "Deployment")] = mock.rule
+			}
+			got := mgr.IsCreateOnlyByGK("apps", "Deployment")
+			if got != mock.expect {
+				t.Fatalf("Expected create only %t got %t", mock.expect, got)
+			}
+		})
+	}
+}
+
+func TestAttachmentRuleManagerGetDeletionPropagationByGK(t *testing.T) {
+	var tests = map[string]struct {
+		rule   *v1alpha1.GenericControllerAttachment
+		expect metav1.DeletionPropagation
+	}{
+		"no rule": {
+			expect: metav1.DeletePropagationBackground,
+		},
+		"no deletion propagation": {
+			rule:   &v1alpha1.GenericControllerAttachment{},
+			expect: metav1.DeletePropagationBackground,
+		},
+		"empty deletion propagation": {
+			rule: &v1alpha1.GenericControllerAttachment{
+				DeletionPropagation: deletionPropagationPtr(""),
+			},
+			expect: metav1.DeletePropagationBackground,
+		},
+		"foreground deletion propagation": {
+			rule: &v1alpha1.GenericControllerAttachment{
+				DeletionPropagation: deletionPropagationPtr(
+					metav1.DeletePropagationForeground,
+				),
+			},
+			expect: metav1.DeletePropagationForeground,
+		},
+		"orphan deletion propagation": {
+			rule: &v1alpha1.GenericControllerAttachment{
+				DeletionPropagation: deletionPropagationPtr(
+					metav1.DeletePropagationOrphan,
+				),
+			},
+			expect: metav1.DeletePropagationOrphan,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			mgr := &attachmentRuleManager{
+				rules: map[string]*v1alpha1.GenericControllerAttachment{},
+			}
+			if mock.rule != nil {
+				mgr.rules[makeAttachmentRuleKeyFromGK("", "Pod")] = mock.rule
+			}
+			got := mgr.GetDeletionPropagationByGK("", "Pod")
+			if got != mock.expect {
+				t.Fatalf("Expected propagation %q got %q", mock.expect, got)
+			}
+		})
+	}
+}
+
+func TestValidateAttachmentRulesDeletionPropagation(t *testing.T) {
+	var tests = map[string]struct {
+		propagation *metav1.DeletionPropagation
+		isErr       bool
+	}{
+		"no deletion propagation": {
+			isErr: false,
+		},
+		"empty deletion propagation": {
+			propagation: deletionPropagationPtr(""),
+			isErr:       false,
+		},
+		"background deletion propagation": {
+			propagation: deletionPropagationPtr(metav1.DeletePropagationBackground),
+			isErr:       false,
+		},
+		"foreground deletion propagation": {
+			propagation: deletionPropagationPtr(metav1.DeletePropagationForeground),
+			isErr:       false,
+		},
+		"orphan deletion propagation": {
+			propagation: deletionPropagationPtr(metav1.DeletePropagationOrphan),
+			isErr:       false,
+		},
+		"invalid deletion propagation": {
+			propagation: deletionPropagationPtr("Later"),
+			isErr:       true,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			config := &v1alpha1.GenericController{
+				Spec: v1alpha1.GenericControllerSpec{
+					Attachments: []v1alpha1.GenericControllerAttachment{
+						{
+							GenericControllerResource: v1alpha1.GenericControllerResource{
+								ResourceRule: v1alpha1.ResourceRule{
+									APIVersion: "v1",
+									Resource:   "pods",
+								},
+							},
+							DeletionPropagation: mock.propagation,
+						},
+					},
+				},
+			}
+			err := validateAttachmentRules(config)
+			if mock.isErr && err == nil {
+				t.Fatalf("Expected error got none")
+			}
+			if !mock.isErr && err != nil {
+				t.Fatalf("Expected no error got %+v", err)
+			}
+		})
+	}
+}
+
+func TestGetMaxDeletions(t *testing.T) {
+	var tests = map[string]struct {
+		maxDeletions  *intstr.IntOrString
+		observedCount int
+		expect        *int
+	}{
+		"no limit": {
+			observedCount: 10,
+			expect:        nil,
+		},
+		"absolute limit": {
+			maxDeletions:  intstrPtr(intstr.FromInt(3)),
+			observedCount: 10,
+			expect:        intPtr(3),
+		},
+		"zero limit": {
+			maxDeletions:  intstrPtr(intstr.FromInt(0)),
+			observedCount: 10,
+			expect:        intPtr(0),
+		},
+		"percentage of observed attachments": {
+			maxDeletions:  intstrPtr(intstr.FromString("50%")),
+			observedCount: 10,
+			expect:        intPtr(5),
+		},
+		"percentage is rounded up": {
+			maxDeletions:  intstrPtr(intstr.FromString("10%")),
+			observedCount: 15,
+			expect:        intPtr(2),
+		},
+		"percentage of fewer attachments allows one deletion": {
+			maxDeletions:  intstrPtr(intstr.FromString("10%")),
+			observedCount: 5,
+			expect:        intPtr(1),
+		},
+		"zero percentage": {
+			maxDeletions:  intstrPtr(intstr.FromString("0%")),
+			observedCount: 5,
+			expect:        intPtr(0),
+		},
+		"percentage of no attachments": {
+			maxDeletions:  intstrPtr(intstr.FromString("10%")),
+			observedCount: 0,
+			expect:        intPtr(0),
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			config := &v1alpha1.GenericController{
+				Spec: v1alpha1.GenericControllerSpec{
+					MaxDeletionsPerSync: mock.maxDeletions,
+				},
+			}
+			got := getMaxDeletions(config, mock.observedCount)
+			if mock.expect == nil && got != nil {
+				t.Fatalf("Expected no limit got %d", *got)
+			}
+			if mock.expect != nil && (got == nil || *got != *mock.expect) {
+				t.Fatalf("Expected limit %d got %v", *mock.expect, got)
+			}
+		})
+	}
+}
+
+func intstrPtr(val intstr.IntOrString) *intstr.IntOrString {
+	return &val
+}
+
+func intPtr(val int) *int {
+	return &val
+}
+
+func TestValidateAttachmentRulesDuplicates(t *testing.T) {
+	var tests = map[string]struct {
+		attachments []v1alpha1.GenericControllerAttachment
+		isErr       bool
+	}{
+		"different resources": {
+			attachments: []v1alpha1.GenericControllerAttachment{
+				newTestAttachment("v1", "secrets"),
+				newTestAttachment("v1", "configmaps"),
+			},
+		},
+		"same resource": {
+			attachments: []v1alpha1.GenericControllerAttachment{
+				newTestAttachment("v1", "secrets"),
+				newTestAttachment("v1", "secrets", "default"),
+			},
+			isErr: true,
+		},
+		"same resource of different versions": {
+			attachments: []v1alpha1.GenericControllerAttachment{
+				newTestAttachment("apps/v1", "deployments"),
+				newTestAttachment("apps/v1beta1", "deployments"),
+			},
+			isErr: true,
+		},
+		"same resource of different groups": {
+			attachments: []v1alpha1.GenericControllerAttachment{
+				newTestAttachment("apps/v1", "deployments"),
+				newTestAttachment("test.io/v1", "deployments"),
+			},
+		},
+		"explicit & wildcard attachments": {
+			attachments: []v1alpha1.GenericControllerAttachment{
+				newTestAttachment("v1", "secrets"),
+				newTestAttachment("v1", "*"),
+			},
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			err := validateAttachmentRules(newWildcardTestController(mock.attachments...))
+			if mock.isErr && err == nil {
+				t.Fatalf("Expected error got none")
+			}
+			if !mock.isErr && err != nil {
+				t.Fatalf("Expected no error got %+v", err)
+			}
+		})
+	}
+}
+
+func TestValidateUniqueAttachmentKinds(t *testing.T) {
+	var tests = map[string]struct {
+		attachments []v1alpha1.GenericControllerAttachment
+		isErr       bool
+	}{
+		"different kinds": {
+			attachments: []v1alpha1.GenericControllerAttachment{
+				newTestAttachment("v1", "pods"),
+				newTestAttachment("apps/v1", "deployments"),
+			},
+		},
+		"same kind of different versions": {
+			attachments: []v1alpha1.GenericControllerAttachment{
+				newTestAttachment("apps/v1", "deployments"),
+				newTestAttachment("apps/v1beta1", "deployments"),
+			},
+			isErr: true,
+		},
+		"undiscovered attachments": {
+			attachments: []v1alpha1.GenericControllerAttachment{
+				newTestAttachment("test.io/v1", "tests"),
+				newTestAttachment("test.io/v1beta1", "tests"),
+			},
+		},
+		"wildcard attachment of explicit kind": {
+			attachments: []v1alpha1.GenericControllerAttachment{
+				newTestAttachment("apps/v1", "deployments"),
+				newTestAttachment("apps/*", "*"),
+			},
+		},
+	}
+	resourceMgr, stop := newFakeResourceManager(t, newWildcardTestResources()...)
+	defer stop()
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			err := validateUniqueAttachmentKinds(
+				resourceMgr, newWildcardTestController(mock.attachments...),
+			)
+			if mock.isErr && err == nil {
+				t.Fatalf("Expected error got none")
+			}
+			if !mock.isErr && err != nil {
+				t.Fatalf("Expected no error got %+v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+	dynamicclientset "openebs.io/metac/dynamic/clientset"
+)
+
+// ClientLimits are the limits of the requests made by the clients of
+// a single watch controller
+type ClientLimits struct {
+	// queries per second & burst; requests are limited by the shared
+	// client limit if these are not set
+	QPS   float32
+	Burst int
+
+	// time within which every request should complete; requests are
+	// not timed out if this is not set
+	Timeout time.Duration
+}
+
+// IsEnabled returns true if these limits apply to the requests
+func (l ClientLimits) IsEnabled() bool {
+	return l.QPS > 0 || l.Timeout > 0
+}
+
+// validateClientLimits returns error if the client limits of the
+// given controller are invalid
+func validateClientLimits(config *v1alpha1.GenericController) error {
+	if config.Spec.ClientQPS != nil && *config.Spec.ClientQPS <= 0 {
+		return errors.Errorf("Invalid client QPS %g: Must be positive", *config.Spec.ClientQPS)
+	}
+	if config.Spec.ClientBurst != nil && *config.Spec.ClientBurst <= 0 {
+		return errors.Errorf("Invalid client burst %d: Must be positive", *config.Spec.ClientBurst)
+	}
+	if config.Spec.ClientTimeout != nil && config.Spec.ClientTimeout.Duration <= 0 {
+		return errors.Errorf(
+			"Invalid client timeout %v: Must be positive", config.Spec.ClientTimeout.Duration,
+		)
+	}
+	return nil
+}
+
+// getClientLimits returns the limits of the requests made by the
+// given controller. The given default limits are used for the limits
+// that are not set by the controller.
+func getClientLimits(
+	config *v1alpha1.GenericController, defaultLimits ClientLimits,
+) ClientLimits {
+	limits := defaultLimits
+	if config.Spec.ClientQPS != nil {
+		limits.QPS = *config.Spec.ClientQPS
+	}
+	if config.Spec.ClientBurst != nil {
+		limits.Burst = int(*config.Spec.ClientBurst)
+	}
+	if config.Spec.ClientTimeout != nil {
+		limits.Timeout = config.Spec.ClientTimeout.Duration
+	}
+	if limits.QPS > 0 && limits.Burst <= 0 {
+		limits.Burst = int(2*limits.QPS + 0.5)
+		if limits.Burst < 1 {
+			limits.Burst = 1
+		}
+	}
+	return limits
+}
+
+// withClientLimits returns the given clientset with its requests
+// limited as per the client limits of the given controller. The given
+// clientset is returned as is if no limits apply.
+func withClientLimits(
+	dynClientset *dynamicclientset.Clientset,
+	config *v1alpha1.GenericController,
+	defaultLimits ClientLimits,
+) (*dynamicclientset.Clientset, error) {
+	limits := getClientLimits(config, defaultLimits)
+	if !limits.IsEnabled() {
+		return dynClientset, nil
+	}
+	return dynClientset.WithLimits(
+		dynamicclientset.RateLimit{QPS: limits.QPS, Burst: limits.Burst}, limits.Timeout,
+	)
+}
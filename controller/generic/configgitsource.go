@@ -0,0 +1,49 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"time"
+
+	"openebs.io/metac/config"
+)
+
+// SetMetaControllerConfigGit sets the git repository having the metac
+// configs at the given ref & sub path against the ConfigBasedMetaController
+// instance. The ref is pulled at the given interval to the given local
+// directory.
+func SetMetaControllerConfigGit(
+	repository, ref, subPath, dir string, pollInterval time.Duration,
+) ConfigBasedMetaControllerOption {
+	return func(c *ConfigBasedMetaController) error {
+		if repository == "" {
+			return nil
+		}
+		source, err := config.NewGit(repository, ref, subPath, dir)
+		if err != nil {
+			return err
+		}
+		if pollInterval <= 0 {
+			pollInterval = defaultConfigPollInterval
+		}
+		c.ConfigGitRepository = repository
+		c.ConfigGitPollInterval = pollInterval
+		c.configGit = source
+		return nil
+	}
+}
+
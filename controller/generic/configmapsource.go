@@ -0,0 +1,46 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"k8s.io/client-go/kubernetes"
+
+	"openebs.io/metac/config"
+)
+
+// SetMetaControllerConfigMapSource sets the config maps having the
+// metac configs against the ConfigBasedMetaController instance. Config
+// maps of the given namespace that match the given label selector are
+// considered.
+func SetMetaControllerConfigMapSource(
+	clientset kubernetes.Interface, namespace, selector string,
+) ConfigBasedMetaControllerOption {
+	return func(c *ConfigBasedMetaController) error {
+		if selector == "" {
+			return nil
+		}
+		source, err := config.NewConfigMaps(clientset, namespace, selector)
+		if err != nil {
+			return err
+		}
+		c.KubeClientset = clientset
+		c.ConfigMapNamespace = namespace
+		c.ConfigMapSelector = selector
+		c.configMaps = source
+		return nil
+	}
+}
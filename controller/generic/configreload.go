@@ -0,0 +1,275 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+	"openebs.io/metac/config"
+)
+
+// configReloadDelay is the duration for which changes to the config
+// files are coalesced before these configs are reloaded
+//
+// NOTE:
+//	Tools like kubectl & kubelet (for mounted config maps) change a
+// config in multiple steps
+var configReloadDelay = 2 * time.Second
+
+// loadConfigs returns the GenericController configs as well as all
+// the metac configs of the enabled profiles
+//
+// NOTE:
+//	Config function returns GenericController configs only
+func (mc *ConfigBasedMetaController) loadConfigs() (
+	[]*v1alpha1.GenericController, config.MetacConfigs, error,
+) {
+	if mc.configSource == nil {
+		gctls, err := mc.GenericControllerAsConfigFn()
+		if err != nil {
+			return nil, nil, err
+		}
+		gctls, err = mc.resolveDuplicates(gctls)
+		if err != nil {
+			return nil, nil, err
+		}
+		return mc.Profiles.Filter(gctls), nil, nil
+	}
+	mconfigs, err := mc.configSource.List()
+	if err != nil {
+		return nil, nil, err
+	}
+	return mc.listGenericControllers(mconfigs)
+}
+
+// resolveDuplicates returns error if more than one of the given
+// configs have the same key. With Override policy a config overrides
+// the earlier configs having the same key instead. The sources of the
+// duplicates are reported in either case.
+func (mc *ConfigBasedMetaController) resolveDuplicates(
+	gctls []*v1alpha1.GenericController,
+) ([]*v1alpha1.GenericController, error) {
+	index := make(map[string]int, len(gctls))
+	var out []*v1alpha1.GenericController
+	var sources [][]string
+	for pos, gctl := range gctls {
+		source := mc.describeSourceOf(gctl, pos)
+		idx, found := index[gctl.Key()]
+		if !found {
+			index[gctl.Key()] = len(out)
+			out = append(out, gctl)
+			sources = append(sources, []string{source})
+			continue
+		}
+		if mc.ConfigDuplicatePolicy == config.DuplicatePolicyOverride {
+			logForConfig(mc, gctl.Key()).Warning(
+				"Controller overrides the one declared earlier",
+				"source", source,
+				"overridden", sources[idx][len(sources[idx])-1],
+			)
+			out[idx] = gctl
+		}
+		sources[idx] = append(sources[idx], source)
+	}
+	if mc.ConfigDuplicatePolicy == config.DuplicatePolicyOverride {
+		return out, nil
+	}
+	var errs []error
+	for idx, gctl := range out {
+		if len(sources[idx]) > 1 {
+			errs = append(errs, errors.Errorf(
+				"Duplicate GenericController %s: Found in %s",
+				gctl.Key(),
+				strings.Join(sources[idx], ", "),
+			))
+		}
+	}
+	if len(errs) != 0 {
+		return nil, utilerrors.NewAggregate(errs)
+	}
+	return out, nil
+}
+
+// describeSourceOf returns the source of the given config that is at
+// the given position of the configs returned by the config function
+//
+// NOTE:
+//	Configs returned by the config function need not be annotated
+// with their source
+func (mc *ConfigBasedMetaController) describeSourceOf(
+	gctl *v1alpha1.GenericController, pos int,
+) string {
+	if source := config.SourceOf(gctl); source != "" {
+		return source
+	}
+	return fmt.Sprintf("%s item %d", mc.describeConfigSource(), pos)
+}
+
+// listGenericControllers returns the GenericController configs as
+// well as all the given configs of the enabled profiles
+func (mc *ConfigBasedMetaController) listGenericControllers(
+	mconfigs config.MetacConfigs,
+) ([]*v1alpha1.GenericController, config.MetacConfigs, error) {
+	mconfigs = mc.Profiles.FilterConfigs(mconfigs)
+	gctls, err := mconfigs.ListGenericControllers()
+	if err != nil {
+		return nil, nil, err
+	}
+	return gctls, mconfigs, nil
+}
+
+// describeConfigSource returns the source of the configs as a string
+func (mc *ConfigBasedMetaController) describeConfigSource() string {
+	if mc.configSource == nil {
+		return "config function"
+	}
+	return mc.configSource.String()
+}
+
+// reloadConfigs loads the configs from their source & syncs the watch
+// controllers & config syncers with these configs
+//
+// NOTE:
+//	Current watch controllers are left running if the configs can't
+// be loaded
+func (mc *ConfigBasedMetaController) reloadConfigs() {
+	mc.reloadMutex.Lock()
+	defer mc.reloadMutex.Unlock()
+
+	logForMeta(mc).Info("Reloading configs", "source", mc.describeConfigSource())
+	configs, mconfigs, err := mc.loadConfigs()
+	mc.recordLoad(err)
+	if err != nil {
+		utilruntime.HandleError(
+			errors.Wrapf(err, "%s: Can't reload configs: Will keep current configs", mc),
+		)
+		return
+	}
+	mc.syncWatchControllers(configs)
+	mc.syncConfigSyncers(mconfigs)
+}
+
+// watchReloadSignal reloads the configs whenever the given channel
+// receives SIGHUP. This blocks till this controller is stopped.
+//
+// NOTE:
+//	This is a lightweight alternative to watching ConfigPath e.g. when
+// the files of a mounted config map get swapped in a way that is not
+// observed as a change. SIGHUPs received during a reload are coalesced
+// into a single reload.
+func (mc *ConfigBasedMetaController) watchReloadSignal(sigCh <-chan os.Signal) {
+	for {
+		select {
+		case <-mc.stopCh:
+			return
+		case sig := <-sigCh:
+			logForMeta(mc).Info("Received signal: Will reload configs", "signal", sig.String())
+			mc.reloadConfigs()
+		}
+	}
+}
+
+// syncWatchControllers stops the watch controllers whose configs got
+// removed, restarts the watch controllers whose configs changed &
+// starts the watch controllers of the given configs that are not
+// running
+//
+// NOTE:
+//	Watch controllers whose configs did not change are left running.
+// A changed controller is created before its current instance is
+// stopped. This lets the informers shared with the current instance
+// retain their caches. Current instance is left running if the changed
+// controller can't be created.
+func (mc *ConfigBasedMetaController) syncWatchControllers(
+	configs []*v1alpha1.GenericController,
+) {
+	desired := make(map[string]*v1alpha1.GenericController, len(configs))
+	for _, conf := range configs {
+		desired[conf.Key()] = conf
+	}
+
+	mc.mutex.Lock()
+	var unchanged, changed, removed int
+	for key, wc := range mc.WatchControllers {
+		conf, found := desired[key]
+		if found && apiequality.Semantic.DeepEqual(conf.Spec, wc.declaredConfig.Spec) {
+			unchanged++
+			wc.syncLogLevel(conf)
+			// a failed restart is resolved by reverting its config
+			delete(mc.startErrs, key)
+			continue
+		}
+		if !found {
+			logForConfig(mc, key).Info("Config removed: Will stop controller")
+			removed++
+			// orphan the attachments if desired before stopping
+			err := wc.ReleaseAll()
+			if err != nil {
+				logForConfig(mc, key).Error(err, "Failed to release attachments")
+			}
+			wc.Stop()
+			delete(mc.WatchControllers, key)
+			continue
+		}
+		logForConfig(mc, key).Info("Config changed: Will restart controller")
+		changed++
+		newWC, err := mc.newWatchController(conf)
+		if err != nil {
+			// current instance keeps running with its earlier config
+			// while the failure is reported in the config status
+			logForConfig(mc, key).Error(err, "Can't restart controller: Will keep current controller")
+			mc.startErrs[key] = errors.Wrapf(err, "%s: Failed to restart key %s", mc, key)
+			continue
+		}
+		wc.Stop()
+		newWC.Start(mc.WorkerCount)
+		mc.WatchControllers[key] = newWC
+		delete(mc.startErrs, key)
+		mc.warnReferenceCycle(newWC.GCtlConfig)
+	}
+	for key := range mc.startErrs {
+		if _, found := desired[key]; !found {
+			delete(mc.startErrs, key)
+		}
+	}
+	mc.GenericControllerConfigs = configs
+	mc.mutex.Unlock()
+
+	logForMeta(mc).Info(
+		"Synced configs",
+		"unchanged", unchanged,
+		"changed", changed,
+		"removed", removed,
+		"added", len(configs)-unchanged-changed,
+	)
+
+	_, err := mc.startAllWatchControllers()
+	if err != nil {
+		utilruntime.HandleError(
+			errors.Wrapf(err, "%s: Failed to start reloaded controllers", mc),
+		)
+	}
+}
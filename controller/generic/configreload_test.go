@@ -0,0 +1,110 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+	"openebs.io/metac/config"
+)
+
+// newSourcedConfig returns the GenericController of the given name
+// that is annotated with the given source if any
+func newSourcedConfig(name, source string) *v1alpha1.GenericController {
+	gctl := &v1alpha1.GenericController{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "metac"},
+	}
+	if source != "" {
+		gctl.SetAnnotations(map[string]string{config.SourceAnnotationKey: source})
+	}
+	return gctl
+}
+
+func TestConfigBasedMetaControllerResolveDuplicates(t *testing.T) {
+	var tests = map[string]struct {
+		policy      config.DuplicatePolicy
+		configs     []*v1alpha1.GenericController
+		wantSources []string
+		wantErrs    []string
+		isErr       bool
+	}{
+		"no duplicates": {
+			configs: []*v1alpha1.GenericController{
+				newSourcedConfig("one", "a.yaml"),
+				newSourcedConfig("two", "b.yaml"),
+			},
+			wantSources: []string{"a.yaml", "b.yaml"},
+		},
+		"duplicates fail with their sources": {
+			configs: []*v1alpha1.GenericController{
+				newSourcedConfig("one", "a.yaml"),
+				newSourcedConfig("one", "b.yaml"),
+			},
+			wantErrs: []string{"Duplicate GenericController metac/one: Found in a.yaml, b.yaml"},
+			isErr:    true,
+		},
+		"duplicates without sources fail with their positions": {
+			configs: []*v1alpha1.GenericController{
+				newSourcedConfig("one", ""),
+				newSourcedConfig("two", ""),
+				newSourcedConfig("one", ""),
+			},
+			wantErrs: []string{"Found in config function item 0, config function item 2"},
+			isErr:    true,
+		},
+		"duplicates are overridden": {
+			policy: config.DuplicatePolicyOverride,
+			configs: []*v1alpha1.GenericController{
+				newSourcedConfig("one", "a.yaml"),
+				newSourcedConfig("two", "b.yaml"),
+				newSourcedConfig("one", "c.yaml"),
+			},
+			wantSources: []string{"c.yaml", "b.yaml"},
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			mc := &ConfigBasedMetaController{ConfigDuplicatePolicy: mock.policy}
+			got, err := mc.resolveDuplicates(mock.configs)
+			if mock.isErr != (err != nil) {
+				t.Fatalf("Expected error %t got %v", mock.isErr, err)
+			}
+			for _, want := range mock.wantErrs {
+				if !strings.Contains(err.Error(), want) {
+					t.Fatalf("Expected error with %q got %v", want, err)
+				}
+			}
+			if len(got) != len(mock.wantSources) {
+				t.Fatalf("Expected %d configs got %d", len(mock.wantSources), len(got))
+			}
+			for i, gctl := range got {
+				if config.SourceOf(gctl) != mock.wantSources[i] {
+					t.Fatalf(
+						"Expected config %d from %q got %q",
+						i, mock.wantSources[i], config.SourceOf(gctl),
+					)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+
+	"openebs.io/metac/config"
+)
+
+// defaultConfigPollInterval is the interval at which the configs are
+// fetched from a polled source unless some other interval is set
+var defaultConfigPollInterval = 1 * time.Minute
+
+// SetMetaControllerConfigSource sets the source that lists & watches
+// the metac configs against the ConfigBasedMetaController instance
+func SetMetaControllerConfigSource(source config.ConfigSource) ConfigBasedMetaControllerOption {
+	return func(c *ConfigBasedMetaController) error {
+		c.ConfigSource = source
+		return nil
+	}
+}
+
+// newConfigSource returns the source of the configs. This is one of
+// ConfigSource, config maps, config url, git repository or config path
+// in that order of priority. Nil is returned if none of these are set.
+//
+// NOTE:
+//	Sources built by metac render, substitute the variables & resolve
+// the duplicates of their configs as per this controller's settings
+func (mc *ConfigBasedMetaController) newConfigSource() config.ConfigSource {
+	switch {
+	case mc.ConfigSource != nil:
+		return mc.ConfigSource
+	case mc.configMaps != nil:
+		mc.configMaps.Renderer = mc.ConfigRenderer
+		mc.configMaps.Substituter = mc.ConfigSubstituter
+		mc.configMaps.DuplicatePolicy = mc.ConfigDuplicatePolicy
+		return mc.configMaps
+	case mc.configRemote != nil:
+		mc.configRemote.Renderer = mc.ConfigRenderer
+		mc.configRemote.Substituter = mc.ConfigSubstituter
+		mc.configRemote.DuplicatePolicy = mc.ConfigDuplicatePolicy
+		return config.NewPollSource(mc.configRemote, mc.ConfigURLPollInterval)
+	case mc.configGit != nil:
+		mc.configGit.Renderer = mc.ConfigRenderer
+		mc.configGit.Substituter = mc.ConfigSubstituter
+		mc.configGit.DuplicatePolicy = mc.ConfigDuplicatePolicy
+		return config.NewPollSource(mc.configGit, mc.ConfigGitPollInterval)
+	case mc.ConfigPath != "":
+		return &config.Config{
+			Path:            mc.ConfigPath,
+			Include:         mc.ConfigPathInclude,
+			Exclude:         mc.ConfigPathExclude,
+			Renderer:        mc.ConfigRenderer,
+			Substituter:     mc.ConfigSubstituter,
+			DuplicatePolicy: mc.ConfigDuplicatePolicy,
+		}
+	default:
+		return nil
+	}
+}
+
+// watchConfigSource reloads the configs whenever the config source
+// finds a change. This blocks till this controller is stopped.
+//
+// NOTE:
+//	Changes are coalesced till no change is found for the duration of
+// configReloadDelay
+func (mc *ConfigBasedMetaController) watchConfigSource() {
+	// buffered to coalesce the changes that are not yet handled
+	changeCh := make(chan struct{}, 1)
+	onChange := func() {
+		select {
+		case changeCh <- struct{}{}:
+		default:
+		}
+	}
+	// the watch of the source is joined as well since it may invoke
+	// onChange till it returns
+	watchDoneCh := make(chan struct{})
+	defer func() { <-watchDoneCh }()
+	go func() {
+		defer close(watchDoneCh)
+		err := mc.configSource.Watch(mc.stopCh, onChange)
+		if err != nil {
+			utilruntime.HandleError(
+				errors.Wrapf(err, "%s: Can't watch %s", mc, mc.configSource),
+			)
+		}
+	}()
+
+	var reloadCh <-chan time.Time
+	for {
+		select {
+		case <-mc.stopCh:
+			return
+		case <-changeCh:
+			glog.V(4).Infof("%s: %s changed", mc, mc.configSource)
+			reloadCh = time.After(configReloadDelay)
+		case <-reloadCh:
+			reloadCh = nil
+			mc.reloadConfigs()
+		}
+	}
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"openebs.io/metac/config"
+)
+
+// blockingSource is a config source without any configs whose watch
+// returns a while after it is stopped
+type blockingSource struct {
+	// returned is set once the watch returns
+	returned int32
+}
+
+func (s *blockingSource) List() (config.MetacConfigs, error) {
+	return nil, nil
+}
+
+func (s *blockingSource) Watch(stopCh <-chan struct{}, onChange func()) error {
+	onChange()
+	<-stopCh
+	time.Sleep(100 * time.Millisecond)
+	atomic.StoreInt32(&s.returned, 1)
+	return nil
+}
+
+func (s *blockingSource) String() string {
+	return "blocking source"
+}
+
+func TestConfigBasedMetaControllerStopJoinsConfigWatch(t *testing.T) {
+	source := &blockingSource{}
+	mc, err := NewConfigBasedMetaController(
+		nil, nil, nil, 1, SetMetaControllerConfigSource(source),
+	)
+	if err != nil {
+		t.Fatalf("Expected no error got %v", err)
+	}
+	mc.Start()
+	// give the start enough time to watch the source
+	time.Sleep(100 * time.Millisecond)
+	mc.Stop()
+	if atomic.LoadInt32(&source.returned) != 1 {
+		t.Fatalf("Expected config watch to return before stop returns: Got still running")
+	}
+}
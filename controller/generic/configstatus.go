@@ -0,0 +1,219 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+
+package generic
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/apimachinery/pkg/util/json"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+	"openebs.io/metac/config"
+	dynamicdiscovery "openebs.io/metac/dynamic/discovery"
+)
+
+// ConfigPhase represents the state of a loaded config
+type ConfigPhase string
+
+const (
+	// ConfigPhaseLoaded implies the config is loaded & handed over
+	// to the controllers of its kind
+	ConfigPhaseLoaded ConfigPhase = "Loaded"
+
+	// ConfigPhaseStarted implies the controller of this config is
+	// running
+	ConfigPhaseStarted ConfigPhase = "Started"
+
+	// ConfigPhasePending implies the controller of this config is
+	// not started since its watch resource is not discovered yet
+	ConfigPhasePending ConfigPhase = "Pending"
+
+	// ConfigPhaseFailed implies the controller of this config could
+	// not be started e.g. due to an invalid config. A controller that
+	// can't be restarted with its changed config keeps running with
+	// its earlier config.
+	ConfigPhaseFailed ConfigPhase = "Failed"
+)
+
+// ConfigStatus represents the state of a single loaded config
+type ConfigStatus struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+
+	// source of this config e.g. the file it was loaded from
+	Source string `json:"source,omitempty"`
+
+	Phase ConfigPhase `json:"phase"`
+
+	// why this config is pending or failed
+	Reason string `json:"reason,omitempty"`
+
+	// deprecation warnings of the watch & attachments of a started
+	// controller
+	Deprecations []string `json:"deprecations,omitempty"`
+
+	// informers of a started controller that fail to list or watch
+	// their resources repeatedly
+	InformerFailures []string `json:"informerFailures,omitempty"`
+}
+
+// ConfigStatusReport represents the configs loaded by the config
+// based metacontroller & the state of their controllers
+type ConfigStatusReport struct {
+	// source of the configs e.g. config path or config maps
+	Source string `json:"source"`
+
+	// time when the configs were loaded successfully for the last time
+	LoadTime time.Time `json:"loadTime"`
+
+	// error of the latest attempt to load the configs; configs of
+	// the previous load are kept if this is set
+	LoadError string `json:"loadError,omitempty"`
+
+	// time of the latest failed attempt to load the configs
+	LoadErrorTime *time.Time `json:"loadErrorTime,omitempty"`
+
+	Configs []ConfigStatus `json:"configs"`
+
+	// errors of the latest sync of the controllers of kinds other
+	// than GenericController
+	SyncErrors []string `json:"syncErrors,omitempty"`
+}
+
+// recordLoad records the outcome of an attempt to load the configs
+//
+// NOTE:
+//	Previous load time is retained if the given error is not nil
+// since the configs of the previous load are kept
+func (mc *ConfigBasedMetaController) recordLoad(err error) {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	if err != nil {
+		mc.loadErr = err
+		mc.loadErrTime = time.Now()
+		return
+	}
+	mc.loadTime = time.Now()
+	mc.loadErr = nil
+}
+
+// ConfigStatusReport returns the configs that are currently loaded &
+// the state of their controllers
+func (mc *ConfigBasedMetaController) ConfigStatusReport() ConfigStatusReport {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	report := ConfigStatusReport{
+		Source:   mc.describeConfigSource(),
+		LoadTime: mc.loadTime,
+		Configs:  []ConfigStatus{},
+	}
+	if mc.loadErr != nil {
+		report.LoadError = mc.loadErr.Error()
+		loadErrTime := mc.loadErrTime
+		report.LoadErrorTime = &loadErrTime
+	}
+	for _, conf := range mc.GenericControllerConfigs {
+		status := ConfigStatus{
+			Kind:      "GenericController",
+			Namespace: conf.GetNamespace(),
+			Name:      conf.GetName(),
+			Source:    config.SourceOf(conf),
+			Phase:     ConfigPhaseStarted,
+		}
+		switch err := mc.startErrs[conf.Key()]; {
+		case err != nil && isWatchNotDiscovered(err):
+			status.Phase = ConfigPhasePending
+			status.Reason = err.Error()
+		case err != nil:
+			status.Phase = ConfigPhaseFailed
+			status.Reason = err.Error()
+		case mc.WatchControllers[conf.Key()] == nil:
+			status.Phase = ConfigPhasePending
+		default:
+			status.Deprecations = listDeprecations(
+				mc.ResourceManager, mc.WatchControllers[conf.Key()].GCtlConfig,
+			)
+			status.InformerFailures = mc.WatchControllers[conf.Key()].listFailingInformers()
+		}
+		report.Configs = append(report.Configs, status)
+	}
+	for idx := range mc.metacConfigs {
+		conf := &mc.metacConfigs[idx]
+		if conf.GetKind() == "GenericController" {
+			// these are reported above
+			continue
+		}
+		report.Configs = append(report.Configs, ConfigStatus{
+			Kind:      conf.GetKind(),
+			Namespace: conf.GetNamespace(),
+			Name:      conf.GetName(),
+			Source:    config.SourceOf(conf),
+			Phase:     ConfigPhaseLoaded,
+		})
+	}
+	sort.SliceStable(report.Configs, func(i, j int) bool {
+		return report.Configs[i].Kind < report.Configs[j].Kind
+	})
+	for _, err := range mc.syncErrs {
+		report.SyncErrors = append(report.SyncErrors, err.Error())
+	}
+	return report
+}
+
+// listDeprecations returns the deprecation warnings of the watch &
+// attachments of the given controller
+func listDeprecations(
+	resourceMgr *dynamicdiscovery.APIResourceManager,
+	config *v1alpha1.GenericController,
+) []string {
+	var deprecations []string
+	rules := []v1alpha1.ResourceRule{config.Spec.Watch.ResourceRule}
+	for _, attachment := range config.Spec.Attachments {
+		rules = append(rules, attachment.ResourceRule)
+	}
+	for _, rule := range rules {
+		warning := resourceMgr.GetDeprecation(rule.APIVersion, rule.Resource)
+		if warning != "" {
+			deprecations = append(deprecations, fmt.Sprintf(
+				"%s %s: %s", rule.APIVersion, rule.Resource, warning,
+			))
+		}
+	}
+	return deprecations
+}
+
+// ServeHTTP implements http.Handler interface. This responds with the
+// config status report as json.
+func (mc *ConfigBasedMetaController) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	data, err := json.Marshal(mc.ConfigStatusReport())
+	if err != nil {
+		glog.Errorf("%s: Can't marshal config status report: %v", mc, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
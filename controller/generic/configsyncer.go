@@ -0,0 +1,77 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"github.com/pkg/errors"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+
+	"openebs.io/metac/config"
+)
+
+// ConfigSyncer runs the controllers of the metac configs of some kind
+// other than GenericController e.g. CompositeController
+type ConfigSyncer interface {
+	// SyncConfigs stops the controllers whose configs got removed or
+	// changed & starts the controllers of the given configs that are
+	// not running
+	//
+	// NOTE:
+	//	This gets invoked with the same configs periodically to start
+	// the controllers that could not be started earlier. Hence this
+	// should be idempotent.
+	SyncConfigs(configs config.MetacConfigs) error
+
+	// Stop stops all the controllers started by this syncer
+	Stop()
+}
+
+// SetMetaControllerConfigSyncers sets the syncers of the configs of
+// kinds other than GenericController against the
+// ConfigBasedMetaController instance
+func SetMetaControllerConfigSyncers(syncers ...ConfigSyncer) ConfigBasedMetaControllerOption {
+	return func(c *ConfigBasedMetaController) error {
+		c.ConfigSyncers = append(c.ConfigSyncers, syncers...)
+		return nil
+	}
+}
+
+// getMetacConfigs returns the configs that were loaded last
+func (mc *ConfigBasedMetaController) getMetacConfigs() config.MetacConfigs {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	return mc.metacConfigs
+}
+
+// syncConfigSyncers syncs all the config syncers with the given
+// configs
+func (mc *ConfigBasedMetaController) syncConfigSyncers(configs config.MetacConfigs) {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	mc.metacConfigs = configs
+	mc.syncErrs = nil
+	for _, syncer := range mc.ConfigSyncers {
+		err := syncer.SyncConfigs(configs)
+		if err != nil {
+			err = errors.Wrapf(err, "%s: Failed to sync configs", mc)
+			mc.syncErrs = append(mc.syncErrs, err)
+			utilruntime.HandleError(err)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"time"
+
+	"openebs.io/metac/config"
+)
+
+// SetMetaControllerConfigURL sets the HTTP(S) endpoint serving the
+// metac configs against the ConfigBasedMetaController instance. This
+// endpoint is polled at the given interval.
+func SetMetaControllerConfigURL(
+	url string, pollInterval time.Duration,
+) ConfigBasedMetaControllerOption {
+	return func(c *ConfigBasedMetaController) error {
+		if url == "" {
+			return nil
+		}
+		remote, err := config.NewRemote(url)
+		if err != nil {
+			return err
+		}
+		if pollInterval <= 0 {
+			pollInterval = defaultConfigPollInterval
+		}
+		c.ConfigURL = url
+		c.ConfigURLPollInterval = pollInterval
+		c.configRemote = remote
+		return nil
+	}
+}
+
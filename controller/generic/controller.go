@@ -17,39 +17,68 @@ limitations under the License.
 package generic
 
 import (
+	"context"
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
-	"github.com/golang/glog"
 	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
 
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/flowcontrol"
 	"k8s.io/client-go/util/workqueue"
 
 	"openebs.io/metac/apis/metacontroller/v1alpha1"
 	"openebs.io/metac/controller/common"
 	"openebs.io/metac/controller/common/finalizer"
+	dynamicapply "openebs.io/metac/dynamic/apply"
 	dynamicclientset "openebs.io/metac/dynamic/clientset"
 	dynamicdiscovery "openebs.io/metac/dynamic/discovery"
 	dynamicinformer "openebs.io/metac/dynamic/informer"
 	dynamicobject "openebs.io/metac/dynamic/object"
+	"openebs.io/metac/hooks/webhook"
+	"openebs.io/metac/logging"
+	"openebs.io/metac/shard"
 	k8s "openebs.io/metac/third_party/kubernetes"
+	"openebs.io/metac/tracing"
+)
+
+// attachmentProtectedConditionType is the type of the watch
+// condition that lists the protected attachments
+const attachmentProtectedConditionType string = "AttachmentProtected"
+
+var (
+	// maximum number of dry run results cached per controller
+	dryRunCacheSize = 1024
 )
 
 // Controller that reconciles GenericController specifications
 type watchController struct {
 	// GCtlConfig config / yaml
+	//
+	// NOTE:
+	//	Wildcard attachments if any are expanded in this config
 	GCtlConfig *v1alpha1.GenericController
 
+	// config as declared i.e. without expanding its wildcard
+	// attachments
+	declaredConfig *v1alpha1.GenericController
+
 	// ResourceManager is used to fetch server API resources
 	ResourceManager *dynamicdiscovery.APIResourceManager
 
@@ -57,6 +86,27 @@ type watchController struct {
 	// by this controller instance
 	DynamicClientSet *dynamicclientset.Clientset
 
+	// discover & operate against the attachments; these refer to
+	// the target cluster of this controller if any & are same as
+	// the ones of the watch otherwise
+	attachmentResourceMgr *dynamicdiscovery.APIResourceManager
+	attachmentClientset   *dynamicclientset.Clientset
+
+	// flags if the attachments are managed in a cluster other than
+	// the cluster of the watch
+	isRemoteAttachments bool
+
+	// captures the warnings of the API server(s) in response to the
+	// requests of this controller
+	warnings *dynamicclientset.WarningRecorder
+
+	// logs the entries of this controller at its own log level
+	log logging.Logger
+
+	// value of the log level annotation that was synced last; nil
+	// if the annotation was never set
+	logLevelAnnotation *string
+
 	// holds all watch API resources declared in this
 	// GenericController yaml
 	watchAPIRegistry common.ResourceRegistryByGK
@@ -69,6 +119,10 @@ type watchController struct {
 	// reconcile process
 	stopCh, doneCh chan struct{}
 
+	// stops enqueueing the watches gained from the changes of the
+	// members of the shard group
+	unsubscribeShard func()
+
 	// watch resources will be queued here
 	// before being reconciled
 	watchQ workqueue.RateLimitingInterface
@@ -82,9 +136,108 @@ type watchController struct {
 	watchInformers      common.ResourceInformerRegistryByVR
 	attachmentInformers common.ResourceInformerRegistryByVR
 
+	// informer of namespaces that is needed if any attachment
+	// is selected via namespace selector or if the watch is
+	// namespaced
+	namespaceInformer *dynamicinformer.ResourceInformer
+
+	// namespace selectors of attachments anchored by api group
+	// & kind
+	namespaceSelectors map[string]labels.Selector
+
+	// explicit namespaces of attachments anchored by api group
+	// & kind; informers of these attachments are scoped to these
+	// namespaces
+	attachmentNamespaces map[string]map[string]bool
+
+	// api group & kind of the attachments whose informers cache
+	// their metadata only
+	metadataOnlyKinds map[string]bool
+
+	// informers that cache the metadata only of their attachments
+	metadataOnlyInformers []*dynamicinformer.ResourceInformer
+
+	// attachments cached as metadata only that were fetched entirely
+	fetchedAttachments *fetchedAttachments
+
+	// explicit namespaces of the watch; watch informers are scoped
+	// to these namespaces. This is nil if the watch is observed
+	// across all the namespaces.
+	watchNamespaces map[string]bool
+
+	// returns true if the given namespace is within the namespaces
+	// that this binary is restricted to
+	isNamespaceInScope func(namespace string) bool
+
+	// flags if this controller should delete the attachments that
+	// can't be owned by the watch, when the watch is deleted
+	isDeleteUnowned bool
+
 	// instance that deals with this controller's finalizer
 	// if any
 	finalizer *finalizer.Finalizer
+
+	// limits the number of attachments that get recreated due
+	// to errors against their immutable fields
+	recreateRateLimiter flowcontrol.RateLimiter
+
+	// detects watches that are synced & mutated continuously
+	hotLoopDetector *common.HotLoopDetector
+
+	// guards the enqueue times & triggers below
+	enqueueMutex sync.Mutex
+
+	// times at which the watches anchored by the watch keys got
+	// queued; these are tracked till the watches are reconciled
+	enqueueTimes map[string]time.Time
+
+	// reasons due to which the watches anchored by the watch keys
+	// got queued; these are tracked till the watches are reconciled
+	enqueueTriggers map[string]string
+
+	// most recent reconciles of the watches; reconciles are not
+	// recorded if this is nil
+	history *reconcileHistory
+
+	// caches the results of dry run updates of attachments
+	dryRunCache *common.DryRunCache
+
+	// strategy used to apply the desired attachments
+	applyStrategy v1alpha1.ApplyStrategy
+
+	// guards the apply diffs below
+	applyDiffMutex sync.Mutex
+
+	// fields of the attachments that got updated by the most recent
+	// sync of the watches anchored by the watch keys
+	applyDiffs map[string]watchApplyDiffs
+
+	// raises events against the watch resources; events are
+	// not raised if this is nil
+	eventRecorder record.EventRecorder
+
+	// flags if events are raised against the GenericController as
+	// well i.e. if this controller is a custom resource
+	isConfigEvents bool
+
+	// number of workers that reconcile the watch resources
+	workerCount int
+
+	// guards the sync errors below
+	syncErrMutex sync.Mutex
+
+	// error of the most recent watch sync; nil if it succeeded
+	recentSyncErr error
+
+	// most recent error observed while syncing the watches
+	lastSyncErr error
+
+	// failures of the watches anchored by the watch keys whose most
+	// recent syncs failed
+	syncFailures map[string]WatchSyncFailure
+
+	// flags if the most recent hook invocation failed
+	isHookFailing bool
 }
 
 // String implements Stringer interface
@@ -97,29 +250,191 @@ func (mgr *watchController) String() string {
 	)
 }
 
+// isSpecChanged returns true if the given spec differs from the spec
+// this controller was created with
+func (mgr *watchController) isSpecChanged(spec v1alpha1.GenericControllerSpec) bool {
+	return !apiequality.Semantic.DeepEqual(spec, mgr.declaredConfig.Spec)
+}
+
+// isWildcardExpansionStale returns true if the wildcard attachments
+// of this controller expand to resources other than the ones this
+// controller was created with. In other words, this controller needs
+// to be recreated to handle newly discovered (or removed) resources.
+func (mgr *watchController) isWildcardExpansionStale() bool {
+	return isWildcardExpansionStale(
+		mgr.attachmentResourceMgr, mgr.declaredConfig, mgr.GCtlConfig,
+	)
+}
+
+// watchNotDiscoveredError is returned when the watch resource of a
+// controller is not discovered yet e.g. its CRD is not installed
+type watchNotDiscoveredError struct {
+	// refers to the controller's namespace & name
+	Key string
+
+	APIVersion string
+	Resource   string
+}
+
+// Error implements error interface
+func (e *watchNotDiscoveredError) Error() string {
+	return fmt.Sprintf(
+		"WatchGCtl %s: Watch %q of %q is not discovered", e.Key, e.Resource, e.APIVersion,
+	)
+}
+
+// isWatchNotDiscovered returns true if the given error is due to
+// the watch resource not being discovered yet
+func isWatchNotDiscovered(err error) bool {
+	_, ok := errors.Cause(err).(*watchNotDiscoveredError)
+	return ok
+}
+
 // newWatchController returns a new instance of watch controller
 // with required watch & child informers, selectors, update
 // strategy & so on.
+//
+// NOTE:
+//	Attachments are managed in the given target cluster if it is
+// not nil & in the cluster of the watch otherwise
 func newWatchController(
 	resourceMgr *dynamicdiscovery.APIResourceManager,
 	dynClientset *dynamicclientset.Clientset,
 	dynInformerFactory *dynamicinformer.SharedInformerFactory,
+	targetCluster *TargetCluster,
+	eventRecorder record.EventRecorder,
+	defaultApplyStrategy v1alpha1.ApplyStrategy,
+	defaultClientLimits ClientLimits,
 	config *v1alpha1.GenericController,
 ) (wCtl *watchController, newErr error) {
 
+	err := ValidateConfig(config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "WatchGCtl %s/%s", config.Namespace, config.Name)
+	}
+
+	// requests of this controller are sent as the identity it
+	// impersonates if any
+	dynClientset, err = withImpersonation(dynClientset, config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "WatchGCtl %s/%s", config.Namespace, config.Name)
+	}
+
+	// requests of this controller are limited independently of other
+	// controllers if it sets its own limits
+	dynClientset, err = withClientLimits(dynClientset, config, defaultClientLimits)
+	if err != nil {
+		return nil, errors.Wrapf(err, "WatchGCtl %s/%s", config.Namespace, config.Name)
+	}
+
+	// requests of this controller are attributed to it in the
+	// client metrics & the warnings of these requests are captured
+	warnings := dynamicclientset.NewWarningRecorder(config.Key())
+	dynClientset, err = dynClientset.ForController(config.Key()).WithWarnings(warnings)
+	if err != nil {
+		return nil, errors.Wrapf(err, "WatchGCtl %s/%s", config.Namespace, config.Name)
+	}
+
+	// attachments are discovered, cached & operated in their own
+	// cluster
+	attachmentCluster := &TargetCluster{
+		ResourceManager:    resourceMgr,
+		DynClientset:       dynClientset,
+		DynInformerFactory: dynInformerFactory,
+	}
+	if targetCluster != nil {
+		attachmentClientset, err :=
+			targetCluster.DynClientset.ForController(config.Key()).WithWarnings(warnings)
+		if err != nil {
+			return nil, errors.Wrapf(err, "WatchGCtl %s/%s", config.Namespace, config.Name)
+		}
+		attachmentCluster = &TargetCluster{
+			Name:               targetCluster.Name,
+			ResourceManager:    targetCluster.ResourceManager,
+			DynClientset:       attachmentClientset,
+			DynInformerFactory: targetCluster.DynInformerFactory,
+		}
+	}
+	attachmentResourceMgr := attachmentCluster.ResourceManager
+	attachmentInformerFactory := attachmentCluster.DynInformerFactory
+
+	// watch & explicit attachments that are installed after the
+	// last discovery are resolved now instead of after the next one
+	_, err = resourceMgr.ResolveByResource(
+		config.Spec.Watch.APIVersion, config.Spec.Watch.Resource,
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "WatchGCtl %s/%s", config.Namespace, config.Name)
+	}
+	for _, a := range config.Spec.Attachments {
+		if isWildcardAttachment(a) {
+			continue
+		}
+		_, err := attachmentResourceMgr.ResolveByResource(a.APIVersion, a.Resource)
+		if err != nil {
+			return nil, errors.Wrapf(err, "WatchGCtl %s/%s", config.Namespace, config.Name)
+		}
+	}
+
+	// short names & categories are resolved to resource names
+	resolvedConfig, err := withResolvedAliases(resourceMgr, attachmentResourceMgr, config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "WatchGCtl %s/%s", config.Namespace, config.Name)
+	}
+	config = resolvedConfig
+
+	// rules of attachments are looked up by api group & kind
+	err = validateUniqueAttachmentKinds(attachmentResourceMgr, config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "WatchGCtl %s/%s", config.Namespace, config.Name)
+	}
+
+	// controller starts once its watch is discovered
+	if resourceMgr.GetByResource(config.Spec.Watch.APIVersion, config.Spec.Watch.Resource) == nil {
+		return nil, &watchNotDiscoveredError{
+			Key:        config.Key(),
+			APIVersion: config.Spec.Watch.APIVersion,
+			Resource:   config.Spec.Watch.Resource,
+		}
+	}
+
+	declaredConfig := config
+	config = withExpandedAttachments(attachmentResourceMgr, declaredConfig)
+
+	// attachments in a target cluster never trigger the watch
+	if targetCluster == nil {
+		err = validateSelfReference(resourceMgr, config)
+		if err != nil {
+			return nil, errors.Wrapf(err, "WatchGCtl %s/%s", config.Namespace, config.Name)
+		}
+	}
+
 	ctl := &watchController{
 		GCtlConfig:       config,
+		declaredConfig:   declaredConfig,
 		ResourceManager:  resourceMgr,
 		DynamicClientSet: dynClientset,
 
+		attachmentResourceMgr: attachmentResourceMgr,
+		attachmentClientset:   attachmentCluster.DynClientset,
+		isRemoteAttachments:   targetCluster != nil,
+		warnings:              warnings,
+		log:                   newControllerLogger(config),
+
 		watchAPIRegistry: make(common.ResourceRegistryByGK),
 
 		watchInformers:      make(common.ResourceInformerRegistryByVR),
 		attachmentInformers: make(common.ResourceInformerRegistryByVR),
+		namespaceSelectors:  make(map[string]labels.Selector),
+
+		attachmentNamespaces: make(map[string]map[string]bool),
+		metadataOnlyKinds:    make(map[string]bool),
+		fetchedAttachments:   newFetchedAttachments(),
+		isNamespaceInScope:   attachmentInformerFactory.IsNamespaceAllowed,
 
 		watchQ: workqueue.NewNamedRateLimitingQueue(
 			workqueue.DefaultControllerRateLimiter(),
-			"WatchGCtl-"+config.Namespace+"-"+config.Name,
+			"WatchGCtl-"+config.Key(),
 		),
 
 		finalizer: &finalizer.Finalizer{
@@ -127,17 +442,54 @@ func newWatchController(
 			// This gets applied against the watch s.t GenericController
 			// has a chance to handle finalize hook i.e. handle deletion
 			// of watch resource
-			Name: "protect.gctl.metac.openebs.io/" +
-				common.DescMetaAsSanitisedNSName(config.GetObjectMeta()),
+			Name: getFinalizerName(config),
+
+			// Watches with the default finalizer are migrated if
+			// finalizer name is set explicitly
+			LegacyNames: []string{getDefaultFinalizerName(config)},
 
 			// Enable if Finalize field is set in the generic controller
-			Enabled: config.Spec.Hooks.Finalize != nil,
+			// or if attachments need to be orphaned on watch deletion
+			Enabled: config.Spec.Hooks.Finalize != nil ||
+				(config.Spec.OrphanOnDelete != nil && *config.Spec.OrphanOnDelete),
 		},
+
+		// allow a burst of 5 recreations & then one recreation
+		// every 10 seconds
+		recreateRateLimiter: flowcontrol.NewTokenBucketRateLimiter(0.1, 5),
+
+		hotLoopDetector: HotLoopSettings{}.newDetector(),
+		enqueueTimes:    make(map[string]time.Time),
+		enqueueTriggers: make(map[string]string),
+
+		dryRunCache: common.NewDryRunCache(dryRunCacheSize),
+
+		applyStrategy: getApplyStrategy(config, defaultApplyStrategy),
+		applyDiffs:    make(map[string]watchApplyDiffs),
+		syncFailures:  make(map[string]WatchSyncFailure),
+
+		eventRecorder: eventRecorder,
 	}
 
-	var err error
+	if attachmentInformerFactory.IsManagedFieldsStripped() &&
+		isManagedFieldsNeeded(config, ctl.applyStrategy) {
+		return nil, errors.Errorf(
+			"%s: Can't apply via %q: Managed fields are stripped from informer caches",
+			ctl, ctl.applyStrategy,
+		)
+	}
+
+	switch config.Spec.NamespacePolicy {
+	case "", v1alpha1.NamespacePolicyOptOut, v1alpha1.NamespacePolicyOptIn:
+	default:
+		return nil, errors.Errorf(
+			"%s: Invalid namespace policy %q", ctl, config.Spec.NamespacePolicy,
+		)
+	}
 
-	ctl.watchSelector, ctl.attachmentSelector, err = makeAllSelector(resourceMgr, config)
+	ctl.watchSelector, ctl.attachmentSelector, err = makeAllSelector(
+		resourceMgr, attachmentResourceMgr, config,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -159,7 +511,7 @@ func newWatchController(
 
 	// Remember the update strategy for each attachment type.
 	ctl.updateStrategies, err = makeUpdateStrategyForAttachments(
-		resourceMgr, config.Spec.Attachments,
+		attachmentResourceMgr, config.Spec.Attachments,
 	)
 	if err != nil {
 		return nil, err
@@ -177,31 +529,91 @@ func newWatchController(
 			for _, informer := range ctl.watchInformers {
 				informer.Close()
 			}
+			if ctl.namespaceInformer != nil {
+				ctl.namespaceInformer.Close()
+			}
 		}
 	}()
 
 	// init watch informers
-	informer, err := dynInformerFactory.GetOrCreate(
-		config.Spec.Watch.APIVersion, config.Spec.Watch.Resource,
-	)
-	if err != nil {
-		return nil, errors.Wrapf(
-			err,
-			"%s: Can't create informer for %q of %q",
-			ctl, config.Spec.Watch.Resource, config.Spec.Watch.APIVersion,
+	if len(config.Spec.Watch.Namespaces) != 0 {
+		ctl.watchNamespaces, err = ctl.initNamespacedInformers(
+			resourceMgr,
+			dynInformerFactory.GetOrCreateInNamespaces,
+			config.Spec.Watch,
+			ctl.watchInformers,
+		)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		watchSelector, err := makeListSelector(config.Spec.Watch)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: Can't push down selectors", ctl)
+		}
+		informer, err := dynInformerFactory.GetOrCreateWithSelector(
+			config.Spec.Watch.APIVersion, config.Spec.Watch.Resource, "", watchSelector,
+		)
+		if err != nil {
+			return nil, errors.Wrapf(
+				err,
+				"%s: Can't create informer for %q of %q",
+				ctl, config.Spec.Watch.Resource, config.Spec.Watch.APIVersion,
+			)
+		}
+		// NOTE:
+		// This is a registry of watch informers even though GenericController
+		// needs only one watch. This may be removed to a single informer
+		// if we conclude that single watch is best for GenericController.
+		ctl.watchInformers.Set(
+			config.Spec.Watch.APIVersion, config.Spec.Watch.Resource, informer,
 		)
 	}
-	// NOTE:
-	// This is a registry of watch informers even though GenericController
-	// needs only one watch. This may be removed to a single informer
-	// if we conclude that single watch is best for GenericController.
-	ctl.watchInformers.Set(
-		config.Spec.Watch.APIVersion, config.Spec.Watch.Resource, informer,
-	)
 
 	// initialise the informers for attachments
 	for _, a := range config.Spec.Attachments {
-		informer, err := dynInformerFactory.GetOrCreate(a.APIVersion, a.Resource)
+		if isMetadataOnly(a) {
+			attachmentAPI := attachmentResourceMgr.GetByResource(a.APIVersion, a.Resource)
+			if attachmentAPI == nil {
+				return nil, errors.Errorf(
+					"%s: Can't find %q of %q", ctl, a.Resource, a.APIVersion,
+				)
+			}
+			ctl.metadataOnlyKinds[makeSelectorKeyFromGK(attachmentAPI.Group, attachmentAPI.Kind)] = true
+		}
+		if len(a.Namespaces) != 0 {
+			err := ctl.initNamespacedAttachmentInformers(attachmentInformerFactory, a)
+			if err != nil {
+				return nil, err
+			}
+			if isMetadataOnly(a) {
+				for _, ns := range a.Namespaces {
+					informer := ctl.attachmentInformers.GetInNamespace(a.APIVersion, a.Resource, ns)
+					if informer != nil {
+						ctl.metadataOnlyInformers = append(ctl.metadataOnlyInformers, informer)
+					}
+				}
+			}
+			continue
+		}
+		attachmentSelector, err := makeListSelector(a.GenericControllerResource)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: Can't push down selectors", ctl)
+		}
+		var informer *dynamicinformer.ResourceInformer
+		if isMetadataOnly(a) {
+			informer, err = attachmentInformerFactory.GetOrCreateMetadataOnly(
+				a.APIVersion, a.Resource, "", attachmentSelector,
+			)
+		} else if isReadOnlyAttachment(config, a) {
+			informer, err = attachmentInformerFactory.GetOrCreateReadOnly(
+				a.APIVersion, a.Resource, "", attachmentSelector,
+			)
+		} else {
+			informer, err = attachmentInformerFactory.GetOrCreateWithSelector(
+				a.APIVersion, a.Resource, "", attachmentSelector,
+			)
+		}
 		if err != nil {
 			return nil, errors.Wrapf(
 				err,
@@ -210,8 +622,68 @@ func newWatchController(
 			)
 		}
 		ctl.attachmentInformers.Set(a.APIVersion, a.Resource, informer)
+		if isMetadataOnly(a) {
+			ctl.metadataOnlyInformers = append(ctl.metadataOnlyInformers, informer)
+		}
+	}
+
+	// initialise the namespace selectors for attachments
+	for _, a := range config.Spec.Attachments {
+		if a.NamespaceSelector == nil {
+			continue
+		}
+		attachmentAPI := attachmentResourceMgr.GetByResource(a.APIVersion, a.Resource)
+		if attachmentAPI == nil {
+			return nil, errors.Errorf(
+				"%s: Can't find %q of %q", ctl, a.Resource, a.APIVersion,
+			)
+		}
+		nsSel, err := metav1.LabelSelectorAsSelector(a.NamespaceSelector)
+		if err != nil {
+			return nil, errors.Wrapf(
+				err,
+				"%s: Invalid namespace selector for %q of %q",
+				ctl, a.Resource, a.APIVersion,
+			)
+		}
+		ctl.namespaceSelectors[makeSelectorKeyFromGK(attachmentAPI.Group, attachmentAPI.Kind)] = nsSel
+	}
+	// A namespaced watch can't be the owner of cluster scoped
+	// attachments or attachments in other namespaces. Neither can
+	// a watch own the attachments in a target cluster. These
+	// attachments are deleted by this controller via finalizer.
+	ctl.isDeleteUnowned = !isReadOnly(config) &&
+		(ctl.isRemoteAttachments ||
+			watchAPI.Namespaced && hasUnownableAttachments(attachmentResourceMgr, config))
+	if ctl.isDeleteUnowned {
+		ctl.finalizer.Enabled = true
+	}
+
+	// namespaces are needed to evaluate namespace selectors as well
+	// as to include or exclude the watches via namespace annotations
+	if len(ctl.namespaceSelectors) != 0 || watchAPI.Namespaced {
+		ctl.namespaceInformer, err = dynInformerFactory.GetOrCreate("v1", "namespaces")
+		if err != nil {
+			return nil, errors.Wrapf(
+				err, "%s: Can't create informer for namespaces", ctl,
+			)
+		}
 	}
 
+	// report this controller as the subscriber of its informers
+	for _, informer := range ctl.watchInformers {
+		informer.SetSubscriber(ctl.String())
+	}
+	for _, informer := range ctl.attachmentInformers {
+		informer.SetSubscriber(ctl.String())
+	}
+	if ctl.namespaceInformer != nil {
+		ctl.namespaceInformer.SetSubscriber(ctl.String())
+	}
+
+	// log level annotation if any takes effect from the start
+	ctl.syncLogLevel(config)
+
 	return ctl, nil
 }
 
@@ -226,9 +698,9 @@ func (mgr *watchController) Start(workerCount int) {
 	// so we have to assume the shared informers are already running. We can't
 	// add event handlers in newController() since c might be incomplete.
 	watchHandlers := cache.ResourceEventHandlerFuncs{
-		AddFunc:    mgr.enqueueWatch,
+		AddFunc:    mgr.addWatch,
 		UpdateFunc: mgr.updateWatch,
-		DeleteFunc: mgr.enqueueWatch,
+		DeleteFunc: mgr.deleteWatch,
 	}
 	var resyncPeriod time.Duration
 	if mgr.GCtlConfig.Spec.ResyncPeriodSeconds != nil {
@@ -247,10 +719,28 @@ func (mgr *watchController) Start(workerCount int) {
 			informer.Informer().AddEventHandler(watchHandlers)
 		}
 	}
+	if mgr.namespaceInformer != nil {
+		mgr.namespaceInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc:    mgr.onNamespaceAdd,
+			UpdateFunc: mgr.onNamespaceUpdate,
+		})
+	}
+	// attachments that were fetched entirely are forgotten once these
+	// are deleted
+	for _, informer := range mgr.metadataOnlyInformers {
+		informer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+			DeleteFunc: mgr.fetchedAttachments.onDelete,
+		})
+	}
 
 	if workerCount <= 0 {
 		workerCount = 5
 	}
+	mgr.workerCount = workerCount
+
+	// watches taken over from replicas that left the shard group
+	// are not changed & hence need to be queued explicitly
+	mgr.unsubscribeShard = shard.Subscribe(mgr.onShardChange)
 
 	go func() {
 		// close done channel i.e. mark closure of this start invocation
@@ -258,11 +748,21 @@ func (mgr *watchController) Start(workerCount int) {
 		// provide the ability to run operations after panics
 		defer utilruntime.HandleCrash()
 
-		glog.Infof("Starting %s", mgr)
-		defer glog.Infof("Shutting down %s", mgr)
+		mgr.log.Info("Starting controller")
+		defer mgr.log.Info("Shutting down controller")
+		if mgr.isDryRun() {
+			mgr.log.Info("Writes of watches & attachments are dry runs")
+		}
+		if mgr.isRemoteAttachments {
+			ref := makeKubeconfigSecretRef(mgr.GCtlConfig)
+			mgr.log.Info(
+				"Attachments are managed in target cluster",
+				"secret", ref.Namespace+"/"+ref.Name,
+			)
+		}
 
 		// Wait for dynamic client and all informers.
-		glog.Infof("%s: Waiting for caches to sync", mgr)
+		mgr.log.Info("Waiting for caches to sync")
 		syncFuncs := make(
 			[]cache.InformerSynced,
 			0,
@@ -274,13 +774,16 @@ func (mgr *watchController) Start(workerCount int) {
 		for _, informer := range mgr.attachmentInformers {
 			syncFuncs = append(syncFuncs, informer.Informer().HasSynced)
 		}
+		if mgr.namespaceInformer != nil {
+			syncFuncs = append(syncFuncs, mgr.namespaceInformer.Informer().HasSynced)
+		}
 		if !k8s.WaitForCacheSync(mgr.GCtlConfig.Key(), mgr.stopCh, syncFuncs...) {
 			// We wait forever unless Stop() is called, so this isn't an error.
-			glog.Warningf("%s: Cache sync never finished", mgr)
+			mgr.log.Warning("Cache sync never finished")
 			return
 		}
 
-		glog.Infof("Starting %d workers for %s", workerCount, mgr)
+		mgr.log.Info("Starting workers", "count", workerCount)
 		var wg sync.WaitGroup
 		for i := 0; i < workerCount; i++ {
 			wg.Add(1)
@@ -294,6 +797,9 @@ func (mgr *watchController) Start(workerCount int) {
 }
 
 func (mgr *watchController) Stop() {
+	if mgr.unsubscribeShard != nil {
+		mgr.unsubscribeShard()
+	}
 	// closing stopCh will unblock all the logics where this
 	// channel was passed earlier. This triggers closing of
 	// doneCh as well
@@ -301,11 +807,12 @@ func (mgr *watchController) Stop() {
 	mgr.watchQ.ShutDown()
 
 	// IMO since nothing is pushed into doneCh, this will block
-	// till doneCh is closed.
+	// till doneCh is closed or till the drain timeout.
 	//
 	// Note: doneCh will be closed after all the workers are
-	// stopped via above close(c.stopCh) invocation
-	<-mgr.doneCh
+	// stopped via above close(c.stopCh) invocation i.e. after
+	// the in-flight syncs complete
+	common.WaitForDrain(mgr.String(), mgr.doneCh)
 
 	// Remove event handlers and close informers for all attachment
 	// resources.
@@ -319,6 +826,59 @@ func (mgr *watchController) Stop() {
 		informer.Informer().RemoveEventHandlers()
 		informer.Close()
 	}
+	// Remove event handlers and close the informer for namespaces
+	// if any
+	if mgr.namespaceInformer != nil {
+		mgr.namespaceInformer.Informer().RemoveEventHandlers()
+		mgr.namespaceInformer.Close()
+	}
+}
+
+// HasSynced returns true if the caches of all the informers of this
+// controller are synced
+func (mgr *watchController) HasSynced() bool {
+	for _, informer := range mgr.watchInformers {
+		if !informer.Informer().HasSynced() {
+			return false
+		}
+	}
+	for _, informer := range mgr.attachmentInformers {
+		if !informer.Informer().HasSynced() {
+			return false
+		}
+	}
+	if mgr.namespaceInformer != nil {
+		return mgr.namespaceInformer.Informer().HasSynced()
+	}
+	return true
+}
+
+// setSyncErr records the outcome of the sync of the watch anchored
+// by the given key
+func (mgr *watchController) setSyncErr(key string, err error) {
+	mgr.syncErrMutex.Lock()
+	defer mgr.syncErrMutex.Unlock()
+
+	mgr.recentSyncErr = err
+	if err == nil {
+		delete(mgr.syncFailures, key)
+		return
+	}
+	mgr.lastSyncErr = err
+	mgr.syncFailures[key] = WatchSyncFailure{
+		Retries: mgr.syncFailures[key].Retries + 1,
+		Error:   err.Error(),
+		Time:    time.Now(),
+	}
+}
+
+// getSyncErrs returns the error of the most recent watch sync &
+// the most recent error observed while syncing the watches
+func (mgr *watchController) getSyncErrs() (recent error, last error) {
+	mgr.syncErrMutex.Lock()
+	defer mgr.syncErrMutex.Unlock()
+
+	return mgr.recentSyncErr, mgr.lastSyncErr
 }
 
 // worker works for ever. Its only work is to process the
@@ -346,12 +906,59 @@ func (mgr *watchController) processNextWorkItem() bool {
 	}
 	defer mgr.watchQ.Done(key)
 
-	// actual reconcile logic is invoked
-	err := mgr.syncWatch(key.(string))
+	// queued watches are not synced once this controller is asked
+	// to stop; only the in-flight syncs are drained
+	if common.IsStopped(mgr.stopCh) {
+		return false
+	}
+
+	// watch is not reconciled if some other replica took it over
+	// after it got queued
+	if !mgr.isShardOwner(key.(string)) {
+		mgr.skipNotOwned(key.(string))
+		mgr.watchQ.Forget(key)
+		return true
+	}
+
+	record := &ReconcileRecord{
+		Watch:   key.(string),
+		Trigger: mgr.takeTrigger(key.(string)),
+		Time:    time.Now(),
+	}
+
+	// actual reconcile logic is invoked & traced
+	// request ID correlates the logs, events & hook calls of this
+	// reconcile
+	ctx, span := mgr.startReconcileSpan(key.(string))
+	record.RequestID = makeRequestID(span)
+	ctx = withRequestID(withReconcileRecord(ctx, record), record.RequestID)
+	err := mgr.syncWatch(ctx, key.(string))
+	tracing.EndSpan(span, err)
+	mgr.setSyncErr(key.(string), err)
+	if record.isWritten {
+		mgr.hotLoopDetector.RecordWrite(key.(string))
+	}
+
+	record.DurationSeconds = time.Since(record.Time).Seconds()
+	if err != nil {
+		record.Error = err.Error()
+	}
+	mgr.history.add(*record)
+	if err != nil && webhook.IsPermanentError(err) {
+		// hook rejected this request; retrying the same request
+		// will fail again. Hence wait for a change to the watch
+		// or its attachments or for the next resync.
+		utilruntime.HandleError(
+			errors.Wrapf(err, "%s: Failed to sync %q: Will not re-queue", mgr, key),
+		)
+		mgr.watchQ.Forget(key)
+		return true
+	}
 	if err != nil {
 		utilruntime.HandleError(
 			errors.Wrapf(err, "%s: Failed to sync %q", mgr, key),
 		)
+		mgr.markTrigger(key.(string), TriggerRetry)
 		mgr.watchQ.AddRateLimited(key)
 		return true
 	}
@@ -365,7 +972,7 @@ func (mgr *watchController) processNextWorkItem() bool {
 //
 // In other words, if the given watch resource is eligible it will be
 // added to this controller queue to be extracted later & reconciled.
-func (mgr *watchController) enqueueWatch(obj interface{}) {
+func (mgr *watchController) enqueueWatch(obj interface{}, trigger string) {
 	// If the watched doesn't match our selector,
 	// and it doesn't have our finalizer, we don't care about it.
 	//
@@ -374,12 +981,19 @@ func (mgr *watchController) enqueueWatch(obj interface{}) {
 	// belongs to this controller & hence should be queued
 	if watchObj, ok := obj.(*unstructured.Unstructured); ok {
 		isMatch := mgr.watchSelector.Matches(watchObj)
-		hasFinalizer := dynamicobject.HasFinalizer(watchObj, mgr.finalizer.Name)
+		hasFinalizer := mgr.finalizer.HasFinalizer(watchObj)
 		if !isMatch && !hasFinalizer {
-			glog.V(4).Infof(
-				"%s: Will not enqueue %s/%s of kind:%s: IsMatch=%t: HasFinalizer=%t",
-				mgr, watchObj.GetNamespace(), watchObj.GetName(), watchObj.GetKind(),
-				isMatch, hasFinalizer,
+			mgr.forWatchObj(watchObj).V(4).Info(
+				"Will not enqueue watch",
+				"isMatch", isMatch, "hasFinalizer", hasFinalizer,
+			)
+			return
+		}
+		// watch resource that belongs to an excluded namespace is not
+		// queued unless it needs to be finalized by us
+		if !hasFinalizer && !mgr.isNamespaceSelected(watchObj.GetNamespace()) {
+			mgr.forWatchObj(watchObj).V(4).Info(
+				"Will not enqueue watch: Namespace is excluded",
 			)
 			return
 		}
@@ -387,55 +1001,152 @@ func (mgr *watchController) enqueueWatch(obj interface{}) {
 
 	key, err := makeWatchQueueKey(obj)
 	if err != nil {
-		glog.V(4).Infof(
-			"%s: Enqueue failed: Can't make key from %+v: %v", mgr, obj, err,
-		)
+		mgr.log.V(4).Info("Enqueue failed: Can't make key", "err", err, "obj", obj)
 		utilruntime.HandleError(
 			errors.Wrapf(err, "%s: Enqueue failed: Can't make key from %+v", mgr, obj),
 		)
 		return
 	}
 
-	glog.V(4).Infof("%s: Will enqueue %s", mgr, key)
-	mgr.watchQ.Add(key)
-}
+	// watch is reconciled by only one replica if the reconciles
+	// are sharded
+	if !mgr.isShardOwner(key) {
+		mgr.forWatch(key).V(4).Info("Will not enqueue watch: Owned by another replica")
+		return
+	}
 
-func (mgr *watchController) enqueueWatchAfter(obj interface{}, delay time.Duration) {
-	key, err := makeWatchQueueKey(obj)
-	if err != nil {
-		glog.V(4).Infof(
-			"%s: Enqueue failed: Can't make key from %+v: %v", mgr, obj, err,
-		)
-		utilruntime.HandleError(
-			errors.Wrapf(err, "%s: Enqueue failed: Can't make key from %+v", mgr, obj),
+	// watch that is hot looping is synced once its backoff is over
+	if backoff := mgr.hotLoopDetector.GetBackoff(key); backoff > 0 {
+		mgr.forWatch(key).V(4).Info(
+			"Will enqueue watch after backoff: Hot loop", "backoff", backoff,
 		)
+		mgr.markTrigger(key, trigger)
+		mgr.watchQ.AddAfter(key, backoff)
 		return
 	}
-	mgr.watchQ.AddAfter(key, delay)
-}
 
-// updateWatch enqueues the watch object without any checks
-func (mgr *watchController) updateWatch(old, cur interface{}) {
-	mgr.enqueueWatch(cur)
+	mgr.forWatch(key).V(4).Info("Will enqueue watch", "trigger", trigger)
+	mgr.markEnqueued(key)
+	mgr.markTrigger(key, trigger)
+	mgr.watchQ.Add(key)
 }
 
-// syncWatch reconciles the watch resource represented by this provided
-// key
-//
-// NOTE:
-//	Errors are logged as debug messages since errors may auto correct
-// eventually
-func (mgr *watchController) syncWatch(key string) error {
-	var err error
-	defer func() {
-		if !glog.V(4) {
-			return
+// isNamespaceSelected returns true if watch resources of the given
+// namespace should be reconciled based on the namespace's annotations
+func (mgr *watchController) isNamespaceSelected(namespace string) bool {
+	if namespace == "" || mgr.namespaceInformer == nil {
+		return true
+	}
+	policy := mgr.GCtlConfig.Spec.NamespacePolicy
+	nsObj, err := mgr.namespaceInformer.Lister().Get("", namespace)
+	if err != nil {
+		// namespace will be evaluated again when it gets cached
+		if !apierrors.IsNotFound(err) {
+			mgr.log.Warning("Can't get namespace", "namespace", namespace, "err", err)
 		}
+		return policy != v1alpha1.NamespacePolicyOptIn
+	}
+	return common.IsNamespaceSelectedByAnnotations(
+		nsObj,
+		policy,
+		mgr.GCtlConfig.Name,
+		mgr.GCtlConfig.Namespace+"/"+mgr.GCtlConfig.Name,
+	)
+}
+
+// enqueueWatchesInNamespace enqueues all the watch resources that
+// belong to the given namespace
+func (mgr *watchController) enqueueWatchesInNamespace(namespace string) {
+	for _, informer := range mgr.watchInformers {
+		watches, err := informer.Lister().ListNamespace(namespace, labels.Everything())
 		if err != nil {
-			glog.Warningf("%s: Can't sync watch %s: %v", mgr, key, err)
+			utilruntime.HandleError(
+				errors.Wrapf(err, "%s: Can't list watches in namespace %q", mgr, namespace),
+			)
+			continue
+		}
+		for _, watch := range watches {
+			mgr.enqueueWatch(watch, TriggerNamespace)
+		}
+	}
+}
+
+// onNamespaceAdd enqueues the watch resources of the added namespace
+// since these watches might have been ignored before this namespace
+// got cached
+func (mgr *watchController) onNamespaceAdd(obj interface{}) {
+	if nsObj, ok := obj.(*unstructured.Unstructured); ok {
+		mgr.enqueueWatchesInNamespace(nsObj.GetName())
+	}
+}
+
+// onNamespaceUpdate enqueues the watch resources of the namespace if
+// the namespace got included or excluded via annotations
+func (mgr *watchController) onNamespaceUpdate(old, cur interface{}) {
+	oldNS, _ := old.(*unstructured.Unstructured)
+	curNS, _ := cur.(*unstructured.Unstructured)
+	if oldNS == nil || curNS == nil ||
+		!common.IsNamespaceAnnotationsChanged(oldNS, curNS) {
+		return
+	}
+	mgr.enqueueWatchesInNamespace(curNS.GetName())
+}
+
+func (mgr *watchController) enqueueWatchAfter(
+	obj interface{}, delay time.Duration, trigger string,
+) {
+	key, err := makeWatchQueueKey(obj)
+	if err != nil {
+		mgr.log.V(4).Info("Enqueue failed: Can't make key", "err", err, "obj", obj)
+		utilruntime.HandleError(
+			errors.Wrapf(err, "%s: Enqueue failed: Can't make key from %+v", mgr, obj),
+		)
+		return
+	}
+	mgr.markTrigger(key, trigger)
+	mgr.watchQ.AddAfter(key, delay)
+}
+
+// addWatch enqueues the watch object that got added to the cache
+func (mgr *watchController) addWatch(obj interface{}) {
+	mgr.enqueueWatch(obj, TriggerAdd)
+}
+
+// updateWatch enqueues the watch object without any checks
+func (mgr *watchController) updateWatch(old, cur interface{}) {
+	trigger := TriggerUpdate
+	oldObj, oldOK := old.(*unstructured.Unstructured)
+	curObj, curOK := cur.(*unstructured.Unstructured)
+	if oldOK && curOK && oldObj.GetResourceVersion() == curObj.GetResourceVersion() {
+		// informer's periodic resync does not change the watch
+		trigger = TriggerResync
+	}
+	mgr.enqueueWatch(cur, trigger)
+}
+
+// deleteWatch enqueues the watch object that got deleted
+func (mgr *watchController) deleteWatch(obj interface{}) {
+	mgr.enqueueWatch(obj, TriggerDelete)
+}
+
+// syncWatch reconciles the watch resource represented by this provided
+// key
+//
+// NOTE:
+//	Errors are logged as debug messages since errors may auto correct
+// eventually
+func (mgr *watchController) syncWatch(ctx context.Context, key string) error {
+	var err error
+	log := mgr.forRequest(ctx, key)
+	defer func() {
+		if !log.V(4).Enabled() {
+			return
+		}
+		if err != nil {
+			log.Warning("Can't sync watch", "err", err)
 			return
 		}
-		glog.Infof("%s: Watch %s sync completed", mgr, key)
+		log.Info("Watch sync completed")
 	}()
 
 	apiVersion, kind, namespace, name, err := splitWatchQueueKey(key)
@@ -448,7 +1159,14 @@ func (mgr *watchController) syncWatch(key string) error {
 		return errors.Errorf("%s: Can't find resource %s", mgr, key)
 	}
 
-	watchInformer := mgr.watchInformers.Get(apiVersion, watchResource.Name)
+	var watchInformer *dynamicinformer.ResourceInformer
+	if mgr.watchNamespaces != nil {
+		watchInformer = mgr.watchInformers.GetInNamespace(
+			apiVersion, watchResource.Name, namespace,
+		)
+	} else {
+		watchInformer = mgr.watchInformers.Get(apiVersion, watchResource.Name)
+	}
 	if watchInformer == nil {
 		return errors.Errorf("%s: Can't find informer %s", mgr, key)
 	}
@@ -457,7 +1175,10 @@ func (mgr *watchController) syncWatch(key string) error {
 	if apierrors.IsNotFound(err) {
 		// Swallow the error since there's no point retrying if the
 		// watch is gone.
-		glog.V(4).Infof("%s: Can't sync %s: Watch doesn't exist: %v", mgr, key, err)
+		log.V(4).Info("Can't sync watch: Watch doesn't exist", "err", err)
+		mgr.hotLoopDetector.Forget(key)
+		mgr.forgetApplyDiffs(key)
+		mgr.takeQueueWait(key)
 		return nil
 	}
 	if err != nil {
@@ -466,31 +1187,41 @@ func (mgr *watchController) syncWatch(key string) error {
 
 	// remember we use a defer statement to intercept error as debug log.
 	// Hence, we dont return below invocation directly.
-	err = mgr.syncWatchObj(watchObj)
+	err = mgr.syncWatchObj(ctx, watchObj)
 	return err
 }
 
 // syncWatchObj reconciles the state based on this observed
 // watch resource instance and other configurations specified
 // in the GenericController
-func (mgr *watchController) syncWatchObj(watch *unstructured.Unstructured) error {
+//
+// NOTE:
+//	Given context holds the trace span of this reconcile
+func (mgr *watchController) syncWatchObj(
+	ctx context.Context, watch *unstructured.Unstructured,
+) (err error) {
 	// If it doesn't match our selector, and it doesn't have our finalizer,
 	// ignore it.
+	log := mgr.forRequestObj(ctx, watch)
 	isMatch := mgr.watchSelector.Matches(watch)
-	hasFinalizer := dynamicobject.HasFinalizer(watch, mgr.finalizer.Name)
+	hasFinalizer := mgr.finalizer.HasFinalizer(watch)
 	if !isMatch && !hasFinalizer {
-		glog.V(4).Infof(
-			"%s: Will not sync watch %s: IsMatch=%t: HasFinalizer=%t",
-			mgr, common.DescObjectAsKey(watch), isMatch, hasFinalizer,
+		log.V(4).Info(
+			"Will not sync watch", "isMatch", isMatch, "hasFinalizer", hasFinalizer,
 		)
 		return nil
 	}
 
-	glog.V(4).Infof("%s: Will sync watch %s", mgr, common.DescObjectAsKey(watch))
+	log.V(4).Info("Will sync watch")
+	defer func() {
+		if err != nil {
+			mgr.recordEvent(
+				ctx, watch, corev1.EventTypeWarning, EventReasonSyncFailed, "Sync failed: %v", err,
+			)
+		}
+	}()
 
-	watchClient, err := mgr.DynamicClientSet.GetClientByKind(
-		watch.GetAPIVersion(), watch.GetKind(),
-	)
+	watchClient, err := mgr.getWatchClient(watch)
 	if err != nil {
 		return errors.Wrapf(
 			err,
@@ -510,15 +1241,17 @@ func (mgr *watchController) syncWatchObj(watch *unstructured.Unstructured) error
 			mgr, common.DescObjectAsKey(watch),
 		)
 	}
+	if watchCopy.GetResourceVersion() != watch.GetResourceVersion() {
+		reconcileRecordFrom(ctx).addWriteAction("Synced finalizer of watch")
+	}
 	watch = watchCopy
 
 	// Check the finalizer again in case we just removed it.
 	isMatch = mgr.watchSelector.Matches(watch)
-	hasFinalizer = dynamicobject.HasFinalizer(watch, mgr.finalizer.Name)
+	hasFinalizer = mgr.finalizer.HasFinalizer(watch)
 	if !isMatch && !hasFinalizer {
-		glog.V(4).Infof(
-			"%s: Will not sync watch %s: IsMatch=%t: HasFinalizer=%t",
-			mgr, common.DescObjectAsKey(watch), isMatch, hasFinalizer,
+		log.V(4).Info(
+			"Will not sync watch", "isMatch", isMatch, "hasFinalizer", hasFinalizer,
 		)
 		return nil
 	}
@@ -530,20 +1263,56 @@ func (mgr *watchController) syncWatchObj(watch *unstructured.Unstructured) error
 		return err
 	}
 
+	// Back off if this watch is not converging
+	if mgr.isHotLoop(ctx, watch, observedAttachments) {
+		reconcileRecordFrom(ctx).addAction("Delayed sync of watch: Hot loop")
+		return nil
+	}
+
+	// Leave the attachments in place if the watch is being deleted
+	// & attachments should be orphaned
+	if watch.GetDeletionTimestamp() != nil && mgr.isOrphanOnDelete() {
+		return mgr.releaseWatch(ctx, watchClient, watch, observedAttachments)
+	}
+
+	// Delete the attachments that are not garbage collected by
+	// Kubernetes if the watch is being deleted & there is no
+	// finalize hook to handle this deletion
+	if watch.GetDeletionTimestamp() != nil && mgr.isDeleteUnowned && !hasFinalizeHook(mgr.GCtlConfig) {
+		return mgr.deleteUnowned(ctx, watchClient, watch, observedAttachments)
+	}
+
+	// Attachments cached as metadata only are fetched entirely since
+	// hooks need the entire resources
+	if mgr.isHookInvoked(watch) {
+		err = mgr.fetchMetadataOnlyAttachments(observedAttachments)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Track the cleanup of attachments if this is a finalize request
+	var finalizeProgress *FinalizeProgress
+	if mgr.isFinalizing(watch) {
+		finalizeProgress = mgr.makeFinalizeProgress(watch, observedAttachments)
+	}
+
 	// Call the sync hook
 	syncRequest := &SyncHookRequest{
-		Controller:  mgr.GCtlConfig,
-		Watch:       watch,
-		Attachments: observedAttachments,
+		Controller:       mgr.GCtlConfig,
+		Watch:            watch,
+		Attachments:      observedAttachments,
+		FinalizeProgress: finalizeProgress,
+		ApplyDiffs:       mgr.takeApplyDiffs(watch),
+		Warnings:         mgr.listHookWarnings(),
+		Parameters:       mgr.GCtlConfig.Spec.Parameters,
 	}
-	syncResult, err := mgr.callSyncHook(syncRequest)
+	syncResult, err := mgr.callSyncHook(ctx, syncRequest)
 	if err != nil {
 		return err
 	}
 	if syncResult == nil {
-		glog.V(4).Infof(
-			"%s: Hook response for watch %s is nil", mgr, common.DescObjectAsKey(watch),
-		)
+		log.V(4).Info("Hook response is nil")
 
 		// nothing to do; hence return
 		//
@@ -553,9 +1322,21 @@ func (mgr *watchController) syncWatchObj(watch *unstructured.Unstructured) error
 		return nil
 	}
 
-	glog.V(4).Infof(
-		"%s: %d attachment(s) received from hook response %v: watch %s",
-		mgr, len(syncResult.Attachments), syncResult, common.DescObjectAsKey(watch),
+	// status rollups are set after attachments are reconciled
+	var rollups []statusRollupResult
+
+	// set the managed status once this sync succeeds
+	defer func() {
+		if err == nil {
+			_, span := tracing.StartSpan(ctx, spanUpdateManaged)
+			err = mgr.syncManagedStatus(watchClient, watch, rollups)
+			tracing.EndSpan(span, err)
+		}
+	}()
+
+	log.V(4).Info(
+		"Received hook response",
+		"attachments", len(syncResult.Attachments), "response", syncResult,
 	)
 
 	// form the desired attachments (received from the sync hook call)
@@ -566,7 +1347,9 @@ func (mgr *watchController) syncWatchObj(watch *unstructured.Unstructured) error
 	// Enqueue a delayed resync, if requested.
 	if syncResult.ResyncAfterSeconds > 0 {
 		mgr.enqueueWatchAfter(
-			watch, time.Duration(syncResult.ResyncAfterSeconds*float64(time.Second)),
+			watch,
+			time.Duration(syncResult.ResyncAfterSeconds*float64(time.Second)),
+			TriggerResyncAfter,
 		)
 	}
 
@@ -595,42 +1378,89 @@ func (mgr *watchController) syncWatchObj(watch *unstructured.Unstructured) error
 		syncResult.Status = finalWatchStatus
 	}
 
-	glog.V(4).Infof(
-		"%s: Desired watch %s: Labels %v: Anns %v: Status %v",
-		mgr, common.DescObjectAsKey(watch), syncResult.Labels, syncResult.Annotations, syncResult.Status,
+	// Surface the protected attachments if any as a condition
+	// of the watch
+	syncResult.Status = setAttachmentProtectedCondition(
+		syncResult.Status, observedAttachments,
+	)
+
+	// Retain the observed generation & status rollups if any since
+	// these are managed by this controller
+	syncResult.Status = mgr.retainManagedStatus(finalWatchStatus, syncResult.Status)
+
+	// Surface the finalize progress if any
+	syncResult.Status = setFinalizeProgress(syncResult.Status, finalizeProgress)
+
+	log.V(4).Info(
+		"Desired watch",
+		"labels", syncResult.Labels,
+		"annotations", syncResult.Annotations,
+		"status", syncResult.Status,
 	)
 
 	labelsChanged := updateStringMap(finalWatchLabels, syncResult.Labels)
 	annotationsChanged := updateStringMap(finalWatchAnnotations, syncResult.Annotations)
 	statusChanged := !reflect.DeepEqual(finalWatchStatus, syncResult.Status)
 
-	glog.V(4).Infof(
-		"%s: Watch %s changes: Labels change? %t: Anns change? %t: Status change? %t",
-		mgr, common.DescObjectAsKey(watch), labelsChanged, annotationsChanged, statusChanged,
+	log.V(4).Info(
+		"Watch changes",
+		"labelsChanged", labelsChanged,
+		"annotationsChanged", annotationsChanged,
+		"statusChanged", statusChanged,
 	)
 
+	// finalizer is removed via the regular update
+	isFinalize :=
+		syncResult.Finalized && mgr.finalizer.HasFinalizer(watch)
+
 	// Only update the watch if anything changed
 	//
 	// Updating a watch is done only if its meta information changes
 	// i.e. labels, annotations &/or status
-	if labelsChanged || annotationsChanged || statusChanged ||
-		(syncResult.Finalized && dynamicobject.HasFinalizer(watch, mgr.finalizer.Name)) {
+	if labelsChanged || annotationsChanged || statusChanged || isFinalize {
 
 		watchCopy.SetLabels(finalWatchLabels)
 		watchCopy.SetAnnotations(finalWatchAnnotations)
 		k8s.SetNestedField(watchCopy.Object, syncResult.Status, "status")
 
+		// reapply sets the changes of this sync against the latest
+		// state of the watch when its update results in a conflict
+		reapply := func(latest *unstructured.Unstructured) bool {
+			latestLabels := latest.GetLabels()
+			if latestLabels == nil {
+				latestLabels = make(map[string]string)
+			}
+			updateStringMap(latestLabels, syncResult.Labels)
+			latest.SetLabels(latestLabels)
+
+			latestAnnotations := latest.GetAnnotations()
+			if latestAnnotations == nil {
+				latestAnnotations = make(map[string]string)
+			}
+			updateStringMap(latestAnnotations, syncResult.Annotations)
+			latest.SetAnnotations(latestAnnotations)
+
+			k8s.SetNestedField(latest.Object, syncResult.Status, "status")
+			if syncResult.Finalized {
+				mgr.finalizer.RemoveFinalizer(latest)
+			}
+			return true
+		}
+		maxRetries := getMaxUpdateConflictRetries(mgr.GCtlConfig)
+
 		hasSubResourceStatus := watchClient.HasSubresource("status")
-		glog.V(4).Infof(
-			"%s: Watch %s: Client has status as sub resource? %t",
-			mgr, common.DescObjectAsKey(watch), hasSubResourceStatus,
-		)
+		log.V(4).Info("Watch client", "hasStatusSubresource", hasSubResourceStatus)
 
 		if statusChanged && hasSubResourceStatus {
 			// The regular Update below will ignore changes to .status
 			// so we do it separately.
+			_, span := tracing.StartSpan(ctx, spanUpdateWatchStatus)
 			result, err := watchClient.Namespace(watch.GetNamespace()).
-				UpdateStatus(watchCopy, metav1.UpdateOptions{})
+				WithAuditDiff(dynamicclientset.AuditDiff(watch, watchCopy)).
+				UpdateStatusWithRetries(
+					watchCopy, metav1.UpdateOptions{}, maxRetries, reapply,
+				)
+			tracing.EndSpan(span, err)
 			if err != nil {
 				return errors.Wrapf(
 					err,
@@ -640,24 +1470,49 @@ func (mgr *watchController) syncWatchObj(watch *unstructured.Unstructured) error
 			}
 			// The Update below needs to use the latest ResourceVersion.
 			watchCopy.SetResourceVersion(result.GetResourceVersion())
-		}
 
-		// check if its time to remove its finalizer
-		if syncResult.Finalized {
-			mgr.finalizer.RemoveFinalizer(watchCopy)
+			log.V(4).Info("Updated status of watch")
+			reconcileRecordFrom(ctx).addWriteAction("Updated status of watch")
 		}
 
-		glog.V(4).Infof("%s: Updating watch %s", mgr, common.DescObjectAsKey(watch))
+		// The regular Update is skipped for a status only change that
+		// was done via the status sub resource. This avoids triggering
+		// the watch's admission webhooks & racing with the spec changes
+		// made by others.
+		//
+		// NOTE:
+		//	Status is updated via the regular Update if the watch does
+		// not have status as a sub resource.
+		if labelsChanged || annotationsChanged || isFinalize ||
+			(statusChanged && !hasSubResourceStatus) {
+
+			// check if its time to remove its finalizer
+			if syncResult.Finalized {
+				mgr.finalizer.RemoveFinalizer(watchCopy)
+			}
 
-		_, err = watchClient.
-			Namespace(watch.GetNamespace()).Update(watchCopy, metav1.UpdateOptions{})
-		if err != nil {
-			return errors.Wrapf(err,
-				"%s: Failed to update watch %s", mgr, common.DescObjectAsKey(watch),
-			)
-		}
+			log.V(4).Info("Updating watch")
 
-		glog.V(4).Infof("%s: Updated watch %s", mgr, common.DescObjectAsKey(watch))
+			_, span := tracing.StartSpan(ctx, spanUpdateWatch)
+			_, err = watchClient.
+				Namespace(watch.GetNamespace()).
+				WithAuditDiff(dynamicclientset.AuditDiff(watch, watchCopy)).
+				UpdateWithRetries(watchCopy, metav1.UpdateOptions{}, maxRetries, reapply)
+			tracing.EndSpan(span, err)
+			if err != nil {
+				return errors.Wrapf(err,
+					"%s: Failed to update watch %s", mgr, common.DescObjectAsKey(watch),
+				)
+			}
+
+			log.V(4).Info("Updated watch")
+			if isFinalize {
+				reconcileRecordFrom(ctx).addWriteAction("Removed finalizer of watch")
+			}
+			if labelsChanged || annotationsChanged || statusChanged {
+				reconcileRecordFrom(ctx).addWriteAction("Updated watch")
+			}
+		}
 	}
 
 	// Check if desired attachments should be reconciled? There will
@@ -675,10 +1530,7 @@ func (mgr *watchController) syncWatchObj(watch *unstructured.Unstructured) error
 	// lifecycle of the controller.
 	if syncResult.SkipReconcile {
 		// should skip reconciling attachments
-		glog.V(4).Infof(
-			"%s: Won't update attachments: SkipReconcile %t",
-			mgr, syncResult.SkipReconcile,
-		)
+		log.V(4).Info("Won't update attachments: SkipReconcile is set")
 		return nil
 	}
 
@@ -690,9 +1542,7 @@ func (mgr *watchController) syncWatchObj(watch *unstructured.Unstructured) error
 	}
 	if readOnly {
 		// this controller instance is only meant for watch related changes
-		glog.V(4).Infof(
-			"%s: Won't update attachments: ReadOnly %t", mgr, readOnly,
-		)
+		log.V(4).Info("Won't update attachments: ReadOnly is set")
 		return nil
 	}
 
@@ -706,25 +1556,68 @@ func (mgr *watchController) syncWatchObj(watch *unstructured.Unstructured) error
 
 		// build a new instance of attachment update strategy finder
 		updateStrategyMgr, err := newAttachmentUpdateStrategyManager(
-			mgr.ResourceManager,
+			mgr.attachmentResourceMgr,
 			mgr.GCtlConfig.Spec.Attachments,
 		)
 		if err != nil {
 			return err
 		}
 
-		glog.V(4).Infof("%s: Will apply attachments: Observed %s: Desired %s",
-			mgr, observedAttachments, desiredAttachments,
+		// build a new instance of attachment rule finder
+		ruleMgr := newAttachmentRuleManager(
+			mgr.attachmentResourceMgr,
+			mgr.GCtlConfig.Spec.Attachments,
 		)
 
+		// verify if hook desires attachments in selected namespaces only
+		err = mgr.validateDesiredNamespaces(watch, desiredAttachments)
+		if err != nil {
+			return err
+		}
+
+		// verify if hook desires attachments within the allowed limits
+		err = mgr.validateDesiredCounts(
+			ctx, watch, ruleMgr, observedAttachments, desiredAttachments,
+		)
+		if err != nil {
+			return err
+		}
+
+		log.V(4).Info(
+			"Will apply attachments",
+			"observed", observedAttachments, "desired", desiredAttachments,
+		)
+
+		// fields of the attachments that get updated by this sync
+		var applyDiffs []AttachmentApplyDiff
+
+		// operations against the attachments are traced as children
+		// of this span
+		applyCtx, applySpan := tracing.StartSpan(ctx, spanApplyAttachments)
+
 		// Reconcile attachments via attachment manager
 		attMgr := &common.AttachmentManager{
 			AttachmentExecuteBase: common.AttachmentExecuteBase{
-				GetChildUpdateStrategyByGK: updateStrategyMgr.GetStrategyByGKOrDefault,
-				IsPatchByGK:                updateStrategyMgr.IsPatchByGK,
-				Watch:                      watch,
-				UpdateAny:                  mgr.GCtlConfig.Spec.UpdateAny,
-				DeleteAny:                  mgr.GCtlConfig.Spec.DeleteAny,
+				GetChildUpdateStrategyByGK:     updateStrategyMgr.GetStrategyByGKOrDefault,
+				IsPatchByGK:                    updateStrategyMgr.IsPatchByGK,
+				GetPatchTypeByGK:               updateStrategyMgr.GetPatchTypeByGK,
+				IsReadOnlyByGK:                 ruleMgr.IsReadOnlyByGK,
+				IsCreateOnlyByGK:               ruleMgr.IsCreateOnlyByGK,
+				GetDeletionPropagationByGK:     ruleMgr.GetDeletionPropagationByGK,
+				IsRecreateOnImmutableErrorByGK: updateStrategyMgr.IsRecreateOnImmutableErrorByGK,
+				GetAdoptPolicyByGK:             ruleMgr.GetAdoptPolicyByGK,
+				GetListMapKeysByGK:             ruleMgr.GetListMapKeysByGK,
+				GetIgnorePathsByGK:             ruleMgr.GetIgnorePathsByGK,
+				GetConflictPolicyByGK:          ruleMgr.GetConflictPolicyByGK,
+				IsDryRunCompareByGK:            ruleMgr.IsDryRunCompareByGK,
+				GetMergeModeByGK:               ruleMgr.GetMergeModeByGK,
+				DryRunCache:                    mgr.dryRunCache,
+				MaxUpdateConflictRetries:       getMaxUpdateConflictRetries(mgr.GCtlConfig),
+				RecreateRateLimiter:            mgr.recreateRateLimiter,
+				EventRecorder:                  mgr.eventRecorder,
+				Watch:                          watch,
+				UpdateAny:                      mgr.GCtlConfig.Spec.UpdateAny,
+				DeleteAny:                      mgr.GCtlConfig.Spec.DeleteAny,
 
 				// TODO (@amitkumardas):
 				//
@@ -735,18 +1628,732 @@ func (mgr *watchController) syncWatchObj(watch *unstructured.Unstructured) error
 				// processed by finalize hook. In other words, this is set
 				// to true during finalize hook invocation.
 				UpdateDuringPendingDelete: k8s.BoolPtr(syncRequest.Finalizing),
+
+				// watch can't own the attachments in a target cluster
+				IsWatchOwner: k8s.BoolPtr(!mgr.isRemoteAttachments),
+
+				ApplyStrategy:         mgr.applyStrategy,
+				FieldManager:          getFieldManager(mgr.GCtlConfig),
+				IsForceApplyConflicts: isForceApplyConflicts(mgr.GCtlConfig),
+
+				OnApplyDiffFn: func(obj *unstructured.Unstructured, diffs []dynamicapply.FieldDiff) {
+					applyDiffs = append(applyDiffs, makeApplyDiff(obj, diffs))
+				},
+				OnActionFn: reconcileRecordFrom(ctx).addAttachmentAction,
+
+				TraceContext: applyCtx,
+				RequestID:    requestIDFrom(ctx),
+				Logger:       log,
 			},
 
-			DynamicClientSet: mgr.DynamicClientSet,
+			DynamicClientSet: mgr.attachmentClientset.ForWatch(common.DescObjectAsKey(watch)),
+			IsDryRun:         mgr.isDryRun(),
 			Observed:         observedAttachments,
 			Desired:          desiredAttachments,
+			MaxDeletions:     getMaxDeletions(mgr.GCtlConfig, observedAttachments.Len()),
+		}
+		err = attMgr.Apply()
+		tracing.EndSpan(applySpan, err)
+		// attachments may have been updated even if apply failed
+		mgr.setApplyDiffs(watch, applyDiffs)
+		if err != nil {
+			return err
+		}
+
+		// replicas of the scalable attachments are set via their
+		// scale subresource
+		if len(syncResult.Scales) != 0 {
+			_, span := tracing.StartSpan(ctx, spanScaleAttachments)
+			err = mgr.applyScales(ctx, watch, observedAttachments, syncResult.Scales, ruleMgr)
+			tracing.EndSpan(span, err)
 		}
-		return attMgr.Apply()
+		if err != nil {
+			return err
+		}
+
+		// aggregate the status of the attachments into the watch
+		rollups, err = rollupStatus(
+			mgr.attachmentResourceMgr,
+			mgr.GCtlConfig.Spec.StatusRollups,
+			observedAttachments,
+			desiredAttachments,
+		)
+		return err
 	}
 
 	return nil
 }
 
+// isHotLoop returns true if the given watch is synced & mutated
+// continuously without converging. The watch's sync is delayed in
+// this case.
+//
+// NOTE:
+//	This typically happens when the desired state differs from the
+// state that gets persisted e.g. due to defaulting of fields by the
+// API server. Each sync then updates the watch or its attachments
+// resulting in another sync. Changes made by others to a watch or its
+// attachments are not counted since only the syncs that follow a sync
+// that wrote are counted.
+func (mgr *watchController) isHotLoop(
+	ctx context.Context,
+	watch *unstructured.Unstructured,
+	attachments common.AnyUnstructRegistry,
+) bool {
+	key, err := makeWatchQueueKey(watch)
+	if err != nil {
+		mgr.log.Warning("Can't detect hot loop", "err", err)
+		return false
+	}
+	versions := []string{string(watch.GetUID()) + ":" + watch.GetResourceVersion()}
+	for _, obj := range attachments.List() {
+		if obj == nil {
+			continue
+		}
+		versions = append(versions, string(obj.GetUID())+":"+obj.GetResourceVersion())
+	}
+	// sort to avoid false positives due to random map iteration
+	sort.Strings(versions[1:])
+
+	if !mgr.hotLoopDetector.Observe(key, strings.Join(versions, ",")) {
+		return false
+	}
+
+	backoff := mgr.hotLoopDetector.Backoff
+	mgr.forRequest(ctx, key).Warning(
+		"Watch is not converging: Will sync after backoff", "backoff", backoff,
+	)
+	common.RecordHotLoop(mgr.GCtlConfig.Namespace + "/" + mgr.GCtlConfig.Name)
+	mgr.recordEvent(
+		ctx,
+		watch,
+		corev1.EventTypeWarning,
+		common.EventReasonHotLoop,
+		"Watch or its attachments changed after each of the last %d syncs that wrote: Will sync after %s",
+		mgr.hotLoopDetector.Threshold, backoff,
+	)
+	mgr.enqueueWatchAfter(watch, backoff, TriggerHotLoopBackoff)
+	return true
+}
+
+// recordEvent raises an event against the given watch. This is a
+// no-op if event recorder is not set.
+//
+// NOTE:
+//	Request ID of the reconcile held by the given context if any is
+// appended to the message
+func (mgr *watchController) recordEvent(
+	ctx context.Context,
+	watch *unstructured.Unstructured,
+	eventType, reason, messageFmt string,
+	args ...interface{},
+) {
+	if mgr.eventRecorder == nil {
+		return
+	}
+	if id := requestIDFrom(ctx); id != "" {
+		messageFmt += ": RequestID=%s"
+		args = append(args, id)
+	}
+	mgr.eventRecorder.Eventf(watch, eventType, reason, messageFmt, args...)
+}
+
+// validateDesiredCounts verifies if the given desired attachments are
+// within the max count of their attachment kind & within the max
+// attachments of this controller. This returns error only if new
+// attachments need to be created beyond these limits. In other words,
+// observed attachments that are already beyond these limits can still
+// be updated or deleted.
+func (mgr *watchController) validateDesiredCounts(
+	ctx context.Context,
+	watch *unstructured.Unstructured,
+	ruleMgr *attachmentRuleManager,
+	observed common.AnyUnstructRegistry,
+	desired common.AnyUnstructRegistry,
+) error {
+	var total, totalNew int
+	for verkind, group := range desired {
+		var newCount int
+		for name := range group {
+			if observed[verkind][name] == nil {
+				newCount++
+			}
+		}
+		total += len(group)
+		totalNew += newCount
+
+		apiVersion, kind := common.ParseKeyToAPIVersionKind(verkind)
+		apiGroup, _ := common.ParseAPIVersionToGroupVersion(apiVersion)
+		maxCount := ruleMgr.GetMaxCountByGK(apiGroup, kind)
+		if maxCount == nil || newCount == 0 || len(group) <= int(*maxCount) {
+			continue
+		}
+		mgr.recordEvent(
+			ctx,
+			watch,
+			corev1.EventTypeWarning,
+			common.EventReasonAttachmentLimitExceeded,
+			"Refused to create %d %s: Desired %d exceeds max count %d",
+			newCount, verkind, len(group), *maxCount,
+		)
+		return errors.Errorf(
+			"%s: Watch %s: Desired %d %s exceeds max count %d",
+			mgr, common.DescObjectAsKey(watch), len(group), verkind, *maxCount,
+		)
+	}
+	maxTotal := mgr.GCtlConfig.Spec.MaxAttachments
+	if maxTotal == nil || totalNew == 0 || total <= int(*maxTotal) {
+		return nil
+	}
+	mgr.recordEvent(
+		ctx,
+		watch,
+		corev1.EventTypeWarning,
+		common.EventReasonAttachmentLimitExceeded,
+		"Refused to create %d attachments: Desired %d exceeds max attachments %d",
+		totalNew, total, *maxTotal,
+	)
+	return errors.Errorf(
+		"%s: Watch %s: Desired %d attachments exceeds max attachments %d",
+		mgr, common.DescObjectAsKey(watch), total, *maxTotal,
+	)
+}
+
+// setAttachmentProtectedCondition sets a condition against the given
+// status if any of the given attachments is protected via annotation.
+// The condition is set to False, if the status already has this
+// condition & none of the attachments are protected. Updated status
+// is returned.
+func setAttachmentProtectedCondition(
+	status map[string]interface{}, attachments common.AnyUnstructRegistry,
+) map[string]interface{} {
+	var protected []string
+	for _, group := range attachments {
+		for _, obj := range group {
+			if common.IsDeleteProtected(obj) {
+				protected = append(protected, common.DescObjectAsKey(obj))
+			}
+		}
+	}
+	// sort to avoid status updates due to random map iteration
+	sort.Strings(protected)
+
+	if len(protected) == 0 {
+		if status == nil || dynamicobject.GetStatusCondition(
+			map[string]interface{}{"status": status},
+			attachmentProtectedConditionType,
+		) == nil {
+			// nothing to be done
+			return status
+		}
+		status = copyStatusConditions(status)
+		dynamicobject.SetCondition(status, &dynamicobject.StatusCondition{
+			Type:   attachmentProtectedConditionType,
+			Status: "False",
+		})
+		return status
+	}
+
+	if status == nil {
+		status = make(map[string]interface{})
+	} else {
+		status = copyStatusConditions(status)
+	}
+	dynamicobject.SetCondition(status, &dynamicobject.StatusCondition{
+		Type:   attachmentProtectedConditionType,
+		Status: "True",
+		Reason: common.EventReasonProtected,
+		Message: fmt.Sprintf(
+			"Annotation %s is set against: %s",
+			common.AttachmentProtectAnnotationKey,
+			strings.Join(protected, ", "),
+		),
+	})
+	return status
+}
+
+// copyStatusConditions returns a copy of the given status that can
+// be used to set conditions without mutating the given status. This
+// is needed since the given status may refer to the watch's current
+// status.
+func copyStatusConditions(status map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(status))
+	for key, value := range status {
+		copied[key] = value
+	}
+	if conditions, ok := copied["conditions"].([]interface{}); ok {
+		copied["conditions"] = append([]interface{}(nil), conditions...)
+	}
+	return copied
+}
+
+// initNamespacedAttachmentInformers initialises one informer per
+// namespace declared in the given attachment instead of a single
+// informer across all the namespaces
+func (mgr *watchController) initNamespacedAttachmentInformers(
+	dynInformerFactory *dynamicinformer.SharedInformerFactory,
+	attachment v1alpha1.GenericControllerAttachment,
+) error {
+	getOrCreate := dynInformerFactory.GetOrCreateInNamespaces
+	if isMetadataOnly(attachment) {
+		getOrCreate = dynInformerFactory.GetOrCreateMetadataOnlyInNamespaces
+	} else if isReadOnlyAttachment(mgr.GCtlConfig, attachment) {
+		getOrCreate = dynInformerFactory.GetOrCreateReadOnlyInNamespaces
+	}
+	namespaces, err := mgr.initNamespacedInformers(
+		mgr.attachmentResourceMgr,
+		getOrCreate,
+		attachment.GenericControllerResource,
+		mgr.attachmentInformers,
+	)
+	if err != nil {
+		return err
+	}
+	attachmentAPI := mgr.attachmentResourceMgr.GetByResource(
+		attachment.APIVersion, attachment.Resource,
+	)
+	mgr.attachmentNamespaces[makeSelectorKeyFromGK(attachmentAPI.Group, attachmentAPI.Kind)] = namespaces
+	return nil
+}
+
+// getOrCreateInNamespacesFn returns one informer per given namespace
+// of the given resource
+type getOrCreateInNamespacesFn func(
+	apiVersion, resource string,
+	namespaces []string,
+	selector dynamicinformer.ListSelector,
+) (map[string]*dynamicinformer.ResourceInformer, error)
+
+// initNamespacedInformers initialises one informer per namespace
+// declared in the given resource via the given function & registers
+// these informers against the given registry. It returns the set of
+// these namespaces.
+func (mgr *watchController) initNamespacedInformers(
+	resourceMgr *dynamicdiscovery.APIResourceManager,
+	getOrCreate getOrCreateInNamespacesFn,
+	resource v1alpha1.GenericControllerResource,
+	registry common.ResourceInformerRegistryByVR,
+) (map[string]bool, error) {
+	api := resourceMgr.GetByResource(resource.APIVersion, resource.Resource)
+	if api == nil {
+		return nil, errors.Errorf(
+			"%s: Can't find %q of %q",
+			mgr, resource.Resource, resource.APIVersion,
+		)
+	}
+	if !api.Namespaced {
+		return nil, errors.Errorf(
+			"%s: Can't set namespaces for cluster scoped %q of %q",
+			mgr, resource.Resource, resource.APIVersion,
+		)
+	}
+	selector, err := makeListSelector(resource)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: Can't push down selectors", mgr)
+	}
+	informers, err := getOrCreate(
+		resource.APIVersion, resource.Resource, resource.Namespaces, selector,
+	)
+	if err != nil {
+		return nil, errors.Wrapf(
+			err,
+			"%s: Can't create informers for %q of %q in namespaces %q",
+			mgr, resource.Resource, resource.APIVersion, resource.Namespaces,
+		)
+	}
+	namespaces := make(map[string]bool, len(informers))
+	for ns, informer := range informers {
+		registry.SetInNamespace(resource.APIVersion, resource.Resource, ns, informer)
+		namespaces[ns] = true
+	}
+	return namespaces, nil
+}
+
+// listAttachments returns all the attachments of the given
+// attachment kind available in the informer caches
+func (mgr *watchController) listAttachments(
+	attachment v1alpha1.GenericControllerAttachment,
+) ([]*unstructured.Unstructured, error) {
+	var informers []*dynamicinformer.ResourceInformer
+	if len(attachment.Namespaces) == 0 {
+		informers = append(
+			informers,
+			mgr.attachmentInformers.Get(attachment.APIVersion, attachment.Resource),
+		)
+	} else {
+		seen := make(map[string]bool)
+		for _, ns := range attachment.Namespaces {
+			if seen[ns] {
+				continue
+			}
+			seen[ns] = true
+			informers = append(
+				informers,
+				mgr.attachmentInformers.GetInNamespace(
+					attachment.APIVersion, attachment.Resource, ns,
+				),
+			)
+		}
+	}
+	var attachmentObjs []*unstructured.Unstructured
+	for _, informer := range informers {
+		if informer == nil {
+			return nil, errors.Errorf(
+				"%s: No attachment informer for %q with ver %q",
+				mgr, attachment.Resource, attachment.APIVersion,
+			)
+		}
+		objs, err := informer.Lister().List(labels.Everything())
+		if err != nil {
+			return nil, errors.Wrapf(
+				err,
+				"%s: Can't list attachments for %s with ver %s",
+				mgr, attachment.Resource, attachment.APIVersion,
+			)
+		}
+		attachmentObjs = append(attachmentObjs, objs...)
+	}
+	return attachmentObjs, nil
+}
+
+// isNamespaceAllowed returns true if the given namespace is one of
+// the explicit namespaces of the attachment based on the given api
+// group & kind. It returns true if this attachment has no explicit
+// namespaces.
+func (mgr *watchController) isNamespaceAllowed(
+	apiGroup, kind, namespace string,
+) bool {
+	namespaces := mgr.attachmentNamespaces[makeSelectorKeyFromGK(apiGroup, kind)]
+	if namespaces == nil {
+		return true
+	}
+	return namespaces[namespace]
+}
+
+// isNamespaceMatch returns true if the given namespace matches the
+// namespace selector of the attachment based on the given api group
+// & kind. It returns true if this attachment has no namespace selector
+// or if the attachment is cluster scoped.
+func (mgr *watchController) isNamespaceMatch(
+	apiGroup, kind, namespace string,
+) (bool, error) {
+	nsSel := mgr.namespaceSelectors[makeSelectorKeyFromGK(apiGroup, kind)]
+	if nsSel == nil || namespace == "" {
+		return true, nil
+	}
+	nsObj, err := mgr.namespaceInformer.Lister().Get("", namespace)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrapf(
+			err, "%s: Can't get namespace %q", mgr, namespace,
+		)
+	}
+	return nsSel.Matches(labels.Set(nsObj.GetLabels())), nil
+}
+
+// validateDesiredNamespaces verifies if the given desired attachments
+// belong to the namespaces this binary is restricted to, to their
+// explicit namespaces & to the namespaces selected by their namespace
+// selectors
+func (mgr *watchController) validateDesiredNamespaces(
+	watch *unstructured.Unstructured, desired common.AnyUnstructRegistry,
+) error {
+	for _, group := range desired {
+		for _, obj := range group {
+			apiGroup, _ := common.ParseAPIVersionToGroupVersion(obj.GetAPIVersion())
+			ns := obj.GetNamespace()
+			if ns == "" {
+				// desired attachment gets created in watch's namespace
+				// if its namespace is not set
+				ns = watch.GetNamespace()
+			}
+			if !mgr.isNamespaceInScope(ns) {
+				return errors.Errorf(
+					"%s: Desired attachment %s: Namespace %q is out of scope",
+					mgr, common.DescObjectAsKey(obj), ns,
+				)
+			}
+			if !mgr.isNamespaceAllowed(apiGroup, obj.GetKind(), ns) {
+				return errors.Errorf(
+					"%s: Desired attachment %s: Namespace %q isn't one of the allowed namespaces",
+					mgr, common.DescObjectAsKey(obj), ns,
+				)
+			}
+			isNSMatch, err := mgr.isNamespaceMatch(apiGroup, obj.GetKind(), ns)
+			if err != nil {
+				return err
+			}
+			if !isNSMatch {
+				return errors.Errorf(
+					"%s: Desired attachment %s: Namespace %q doesn't match namespace selector",
+					mgr, common.DescObjectAsKey(obj), ns,
+				)
+			}
+		}
+	}
+	return nil
+}
+
+// isOrphanOnDelete returns true if attachments should be left in
+// place when the watch or this controller is deleted
+func (mgr *watchController) isOrphanOnDelete() bool {
+	if mgr.GCtlConfig.Spec.OrphanOnDelete == nil {
+		return false
+	}
+	return *mgr.GCtlConfig.Spec.OrphanOnDelete
+}
+
+// isDryRun returns true if every write of this controller should be a
+// dry run
+func (mgr *watchController) isDryRun() bool {
+	if mgr.GCtlConfig.Spec.DryRun == nil {
+		return false
+	}
+	return *mgr.GCtlConfig.Spec.DryRun
+}
+
+// getWatchClient returns the client of the given watch. Writes of this
+// client are dry runs if this controller is a dry run.
+func (mgr *watchController) getWatchClient(
+	watch *unstructured.Unstructured,
+) (*dynamicclientset.ResourceClient, error) {
+	watchClient, err := mgr.DynamicClientSet.ForWatch(common.DescObjectAsKey(watch)).
+		GetClientByKind(watch.GetAPIVersion(), watch.GetKind())
+	if err != nil {
+		return nil, err
+	}
+	if mgr.isDryRun() {
+		return watchClient.DryRun(), nil
+	}
+	return watchClient, nil
+}
+
+// releaseWatch removes the ownership markers set by the given watch
+// against its attachments & then removes the finalizer from the watch
+func (mgr *watchController) releaseWatch(
+	ctx context.Context,
+	watchClient *dynamicclientset.ResourceClient,
+	watch *unstructured.Unstructured,
+	observedAttachments common.AnyUnstructRegistry,
+) error {
+	log := mgr.forRequestObj(ctx, watch)
+	log.V(4).Info(
+		"Will release attachments of watch", "orphanOnDelete", mgr.isOrphanOnDelete(),
+	)
+	attMgr := &common.AttachmentManager{
+		AttachmentExecuteBase: common.AttachmentExecuteBase{
+			Watch:      watch,
+			OnActionFn: reconcileRecordFrom(ctx).addAttachmentAction,
+			RequestID:  requestIDFrom(ctx),
+			Logger:     log,
+		},
+		DynamicClientSet: mgr.attachmentClientset.ForWatch(common.DescObjectAsKey(watch)),
+		IsDryRun:         mgr.isDryRun(),
+		Observed:         observedAttachments,
+	}
+	err := attMgr.Release()
+	if err != nil {
+		return err
+	}
+	err = mgr.removeFinalizer(watchClient, watch)
+	if err != nil {
+		return err
+	}
+	log.Info("Released attachments of watch")
+	return nil
+}
+
+// deleteUnowned deletes the attachments that were created by the given
+// watch but are not owned by the watch & then removes the finalizer
+// from the watch
+func (mgr *watchController) deleteUnowned(
+	ctx context.Context,
+	watchClient *dynamicclientset.ResourceClient,
+	watch *unstructured.Unstructured,
+	observedAttachments common.AnyUnstructRegistry,
+) error {
+	log := mgr.forRequestObj(ctx, watch)
+	log.V(4).Info(
+		"Will delete unowned attachments of watch",
+	)
+	ruleMgr := newAttachmentRuleManager(
+		mgr.attachmentResourceMgr,
+		mgr.GCtlConfig.Spec.Attachments,
+	)
+	attMgr := &common.AttachmentManager{
+		AttachmentExecuteBase: common.AttachmentExecuteBase{
+			IsReadOnlyByGK:             ruleMgr.IsReadOnlyByGK,
+			IsCreateOnlyByGK:           ruleMgr.IsCreateOnlyByGK,
+			GetDeletionPropagationByGK: ruleMgr.GetDeletionPropagationByGK,
+			EventRecorder:              mgr.eventRecorder,
+			Watch:                      watch,
+			OnActionFn:                 reconcileRecordFrom(ctx).addAttachmentAction,
+			RequestID:                  requestIDFrom(ctx),
+			Logger:                     log,
+		},
+		DynamicClientSet: mgr.attachmentClientset.ForWatch(common.DescObjectAsKey(watch)),
+		IsDryRun:         mgr.isDryRun(),
+		Observed:         observedAttachments,
+	}
+	err := attMgr.DeleteUnowned()
+	if err != nil {
+		return err
+	}
+	return mgr.removeFinalizer(watchClient, watch)
+}
+
+// removeFinalizer removes this controller's finalizer from the given
+// watch if available
+func (mgr *watchController) removeFinalizer(
+	watchClient *dynamicclientset.ResourceClient,
+	watch *unstructured.Unstructured,
+) error {
+	if !mgr.finalizer.HasFinalizer(watch) {
+		return nil
+	}
+	_, err := watchClient.Namespace(watch.GetNamespace()).AtomicUpdate(
+		watch,
+		func(obj *unstructured.Unstructured) bool {
+			if !mgr.finalizer.HasFinalizer(obj) {
+				return false
+			}
+			mgr.finalizer.RemoveFinalizer(obj)
+			return true
+		},
+	)
+	if err != nil {
+		return errors.Wrapf(
+			err,
+			"%s: Failed to remove finalizer from watch %s",
+			mgr, common.DescObjectAsKey(watch),
+		)
+	}
+	return nil
+}
+
+// getDefaultFinalizerName returns the finalizer that is set against
+// the watches if the given controller does not specify one
+func getDefaultFinalizerName(config *v1alpha1.GenericController) string {
+	return "protect.gctl.metac.openebs.io/" +
+		common.DescMetaAsSanitisedNSName(config.GetObjectMeta())
+}
+
+// getFinalizerName returns the finalizer that is set against the
+// watches of the given controller
+func getFinalizerName(config *v1alpha1.GenericController) string {
+	if config.Spec.FinalizerName == nil {
+		return getDefaultFinalizerName(config)
+	}
+	return *config.Spec.FinalizerName
+}
+
+// validateFinalizerName returns error if the finalizer name of the
+// given controller is invalid
+func validateFinalizerName(config *v1alpha1.GenericController) error {
+	if config.Spec.FinalizerName == nil {
+		return nil
+	}
+	name := *config.Spec.FinalizerName
+	if !strings.Contains(name, "/") {
+		return errors.Errorf(
+			"Invalid finalizer name %q: Domain prefix is required", name,
+		)
+	}
+	if errs := validation.IsQualifiedName(name); len(errs) != 0 {
+		return errors.Errorf(
+			"Invalid finalizer name %q: %s", name, strings.Join(errs, ", "),
+		)
+	}
+	return nil
+}
+
+// isReadOnly returns true if the given controller should not create,
+// update or delete any attachments
+func isReadOnly(config *v1alpha1.GenericController) bool {
+	return config.Spec.ReadOnly != nil && *config.Spec.ReadOnly
+}
+
+// hasFinalizeHook returns true if the given controller has a
+// finalize hook
+func hasFinalizeHook(config *v1alpha1.GenericController) bool {
+	return config.Spec.Hooks != nil && config.Spec.Hooks.Finalize != nil
+}
+
+// hasUnownableAttachments returns true if any attachment of the given
+// controller can't be owned by a namespaced watch i.e. attachments
+// that are cluster scoped or attachments that are selected across
+// namespaces
+func hasUnownableAttachments(
+	resourceMgr *dynamicdiscovery.APIResourceManager,
+	config *v1alpha1.GenericController,
+) bool {
+	for _, a := range config.Spec.Attachments {
+		if a.ReadOnly != nil && *a.ReadOnly {
+			continue
+		}
+		if a.NamespaceSelector != nil || len(a.Namespaces) != 0 {
+			return true
+		}
+		attachmentAPI := resourceMgr.GetByResource(a.APIVersion, a.Resource)
+		if attachmentAPI != nil && !attachmentAPI.Namespaced {
+			return true
+		}
+	}
+	return false
+}
+
+// ReleaseAll releases the attachments of all the watches handled by
+// this controller. This is a no-op if attachments need not be orphaned.
+//
+// NOTE:
+//	This is expected to be invoked before stopping this controller
+// since this makes use of the informer caches.
+func (mgr *watchController) ReleaseAll() error {
+	if !mgr.isOrphanOnDelete() {
+		return nil
+	}
+	var errs []error
+	for _, informer := range mgr.watchInformers {
+		watches, err := informer.Lister().List(labels.Everything())
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, watch := range watches {
+			if !mgr.watchSelector.Matches(watch) &&
+				!mgr.finalizer.HasFinalizer(watch) {
+				continue
+			}
+			// every replica releases the watches it owns
+			key, err := makeWatchQueueKey(watch)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if !mgr.isShardOwner(key) {
+				continue
+			}
+			watchClient, err := mgr.getWatchClient(watch)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			observedAttachments, err := mgr.getObservedAttachments(watch)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			err = mgr.releaseWatch(context.Background(), watchClient, watch, observedAttachments)
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
 // getObservedAttachments returns the attachments as declared
 // in GenericController resource
 //
@@ -761,44 +2368,27 @@ func (mgr *watchController) getObservedAttachments(
 ) (common.AnyUnstructRegistry, error) {
 
 	attachmentRegistry := make(common.AnyUnstructRegistry)
+	log := mgr.forWatchObj(watch)
 
 	for _, attachmentKind := range mgr.GCtlConfig.Spec.Attachments {
-		attachmentInformer := mgr.attachmentInformers.Get(
-			attachmentKind.APIVersion, attachmentKind.Resource,
-		)
-		if attachmentInformer == nil {
-			return nil, errors.Errorf(
-				"%s: No attachment informer for %q with ver %q",
-				mgr, attachmentKind.Resource, attachmentKind.APIVersion,
-			)
-		}
-		var attachmentObjs []*unstructured.Unstructured
-		var err error
-
 		// all possible attachment object for the given attachment kind
-		attachmentObjs, err = attachmentInformer.Lister().List(labels.Everything())
+		attachmentObjs, err := mgr.listAttachments(attachmentKind)
 		if err != nil {
-			return nil, errors.Wrapf(
-				err,
-				"%s: Can't list attachments for %s with ver %s",
-				mgr, attachmentKind.Resource, attachmentKind.APIVersion,
-			)
+			return nil, err
 		}
 
-		glog.V(4).Infof(
-			"%s: %d attachment(s) listed for %s with ver %s",
-			mgr, len(attachmentObjs), attachmentKind.Resource, attachmentKind.APIVersion,
+		attachmentGVR := attachmentKind.APIVersion + "/" + attachmentKind.Resource
+		log.V(4).Info(
+			"Listed attachments", logging.KeyGVR, attachmentGVR, "count", len(attachmentObjs),
 		)
 
 		// steps to initialize the attachment registry
-		attachResAPI := mgr.ResourceManager.GetByResource(
+		attachResAPI := mgr.attachmentResourceMgr.GetByResource(
 			attachmentKind.APIVersion, attachmentKind.Resource,
 		)
 		if attachResAPI == nil {
-			if glog.V(2) {
-				glog.Warningf("%s: Can't find attachment resource api for %s with ver %s",
-					mgr, attachmentKind.Resource, attachmentKind.APIVersion,
-				)
+			if log.V(2).Enabled() {
+				log.Warning("Can't find attachment resource api", logging.KeyGVR, attachmentGVR)
 			}
 			continue
 		}
@@ -810,10 +2400,23 @@ func (mgr *watchController) getObservedAttachments(
 		// @amitkumardas: Need to think more on this !!
 		for _, attObj := range attachmentObjs {
 			// Do not consider if match fails
-			if !mgr.attachmentSelector.Matches(attObj) {
-				glog.V(4).Infof(
-					"%s: Ignore attachment %s: Selector doesn't match",
-					mgr, common.DescObjectAsKey(attObj),
+			if !mgr.attachmentSelector.MatchesWithWatch(attObj, watch) {
+				log.V(4).Info(
+					"Ignore attachment: Selector doesn't match",
+					logging.KeyAttachment, common.DescObjectAsKey(attObj),
+				)
+				continue
+			}
+			isNSMatch, err := mgr.isNamespaceMatch(
+				attachResAPI.Group, attachResAPI.Kind, attObj.GetNamespace(),
+			)
+			if err != nil {
+				return nil, err
+			}
+			if !isNSMatch {
+				log.V(4).Info(
+					"Ignore attachment: Namespace selector doesn't match",
+					logging.KeyAttachment, common.DescObjectAsKey(attObj),
 				)
 				continue
 			}
@@ -823,8 +2426,15 @@ func (mgr *watchController) getObservedAttachments(
 	return attachmentRegistry, nil
 }
 
+// isFinalizing returns true if the finalize hook should be invoked
+// for the given watch
+func (mgr *watchController) isFinalizing(watch *unstructured.Unstructured) bool {
+	return hasFinalizeHook(mgr.GCtlConfig) &&
+		(watch.GetDeletionTimestamp() != nil || !mgr.watchSelector.Matches(watch))
+}
+
 func (mgr *watchController) callSyncHook(
-	request *SyncHookRequest,
+	ctx context.Context, request *SyncHookRequest,
 ) (*SyncHookResponse, error) {
 
 	if mgr.GCtlConfig.Spec.Hooks == nil ||
@@ -835,6 +2445,7 @@ func (mgr *watchController) callSyncHook(
 	}
 
 	var response SyncHookResponse
+	log := mgr.forRequestObj(ctx, request.Watch)
 
 	// First check if we should instead call the finalize hook,
 	// which has the same API as the sync hook except that it's
@@ -844,43 +2455,59 @@ func (mgr *watchController) callSyncHook(
 	// when the object no longer matches our selector.
 	// This allows the controller to clean up after itself if the object has been
 	// updated to disable the functionality added by the controller.
-	if mgr.GCtlConfig.Spec.Hooks.Finalize != nil &&
-		(request.Watch.GetDeletionTimestamp() != nil ||
-			!mgr.watchSelector.Matches(request.Watch)) {
+	if mgr.isFinalizing(request.Watch) {
 
-		glog.V(4).Infof("%s: Invoking finalize hook", mgr)
+		log.V(4).Info("Invoking finalize hook")
 
 		// Set finalizing to true since this is finalize hook invocation
 		request.Finalizing = true
+		_, span := tracing.StartSpan(
+			ctx, spanHook, trace.BoolAttribute(attributeIsFinalizing, true),
+		)
 		hi := &HookInvoker{
-			Schema: mgr.GCtlConfig.Spec.Hooks.Finalize,
+			Schema:    mgr.GCtlConfig.Spec.Hooks.Finalize,
+			Span:      span,
+			RequestID: requestIDFrom(ctx),
 		}
+		start := time.Now()
 		err := hi.Invoke(request, &response)
+		reconcileRecordFrom(ctx).setHookDuration(time.Since(start))
+		tracing.EndSpan(span, err)
+		mgr.setHookErr(ctx, request.Watch, "Finalize", err)
 		if err != nil {
 			return nil, errors.Wrapf(err, "Finalize hook failed")
 		}
 
-		glog.V(3).Infof("%s: Finalize hook completed", mgr)
+		log.V(3).Info("Finalize hook completed")
 	} else {
 
 		if mgr.GCtlConfig.Spec.Hooks.Sync == nil {
-			glog.V(4).Infof("%s: Skipping sync hook: Sync is not configured", mgr)
+			log.V(4).Info("Skipping sync hook: Sync is not configured")
 			return nil, nil
 		}
 
-		glog.V(4).Infof("%s: Invoking sync hook", mgr)
+		log.V(4).Info("Invoking sync hook")
 
 		// Set finalizing to false since this is sync hook invocation
 		request.Finalizing = false
+		_, span := tracing.StartSpan(
+			ctx, spanHook, trace.BoolAttribute(attributeIsFinalizing, false),
+		)
 		hi := &HookInvoker{
-			Schema: mgr.GCtlConfig.Spec.Hooks.Sync,
+			Schema:    mgr.GCtlConfig.Spec.Hooks.Sync,
+			Span:      span,
+			RequestID: requestIDFrom(ctx),
 		}
+		start := time.Now()
 		err := hi.Invoke(request, &response)
+		reconcileRecordFrom(ctx).setHookDuration(time.Since(start))
+		tracing.EndSpan(span, err)
+		mgr.setHookErr(ctx, request.Watch, "Sync", err)
 		if err != nil {
 			return nil, errors.Wrapf(err, "Sync hook failed")
 		}
 
-		glog.V(3).Infof("%s: Sync hook completed", mgr)
+		log.V(3).Info("Sync hook completed")
 	}
 
 	return &response, nil
@@ -922,12 +2549,13 @@ func makeAttachmentUpdateStrategyKey(apiGroup, kind string) string {
 // makeAllSelector builds selector for watch as well as for all
 // attachments declared in GenericController
 func makeAllSelector(
-	resourceMgr *dynamicdiscovery.APIResourceManager,
+	watchResourceMgr *dynamicdiscovery.APIResourceManager,
+	attachmentResourceMgr *dynamicdiscovery.APIResourceManager,
 	schema *v1alpha1.GenericController,
 ) (watchSelector, attachmentSelector *Selector, selErr error) {
 
 	// selector for watch
-	wSel, err := NewSelector(FromGCtlResourceSelectRequirements(resourceMgr, schema.Spec.Watch))
+	wSel, err := NewSelector(FromGCtlResourceSelectRequirements(watchResourceMgr, schema.Spec.Watch))
 	if err != nil {
 		return nil, nil, err
 	}
@@ -937,7 +2565,7 @@ func makeAllSelector(
 	for _, attachment := range schema.Spec.Attachments {
 		options = append(
 			options,
-			FromGCtlResourceSelectRequirements(resourceMgr, attachment.GenericControllerResource),
+			FromGCtlResourceSelectRequirements(attachmentResourceMgr, attachment.GenericControllerResource),
 		)
 	}
 
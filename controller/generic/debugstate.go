@@ -0,0 +1,213 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/apimachinery/pkg/util/json"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+	dynamicinformer "openebs.io/metac/dynamic/informer"
+	"openebs.io/metac/logging"
+)
+
+// WatchSyncFailure represents the failed syncs of a watch
+type WatchSyncFailure struct {
+	// number of consecutive syncs of this watch that failed; each
+	// of these is retried with backoff unless the hook rejected it
+	// permanently
+	Retries int `json:"retries"`
+
+	// error of the most recent sync
+	Error string `json:"error"`
+
+	// time of the most recent sync
+	Time time.Time `json:"time"`
+}
+
+// WatchControllerState represents the live state of a running watch
+// controller
+type WatchControllerState struct {
+	// refers to the controller's namespace & name
+	Key string `json:"key"`
+
+	// config of this controller as declared i.e. without expanding
+	// its wildcard attachments
+	Config *v1alpha1.GenericController `json:"config"`
+
+	// flags if the caches of all the informers of this controller
+	// are synced
+	HasSynced bool `json:"hasSynced"`
+
+	Workers int `json:"workers"`
+
+	// number of watches that are queued to be synced
+	QueueLength int `json:"queueLength"`
+
+	// keys of the informers of the watch & the attachments
+	Informers []string `json:"informers"`
+
+	// log level of this controller if set at runtime; "default"
+	// implies the -v flag
+	LogLevel string `json:"logLevel"`
+
+	// failures of the watches anchored by the watch keys whose most
+	// recent syncs failed
+	SyncFailures map[string]WatchSyncFailure `json:"syncFailures,omitempty"`
+
+	// error of the most recent watch sync
+	RecentSyncError string `json:"recentSyncError,omitempty"`
+
+	// most recent error observed while syncing the watches
+	LastSyncError string `json:"lastSyncError,omitempty"`
+
+	// warnings of the API server(s) in response to the requests of
+	// this controller
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// DebugState represents the live state of a metacontroller. This is
+// meant to troubleshoot the controllers that don't seem to run.
+type DebugState struct {
+	// watch controllers that are running sorted by their keys
+	Controllers []WatchControllerState `json:"controllers"`
+
+	// controllers that are not running anchored by their keys along
+	// with the reasons
+	NotRunning map[string]string `json:"notRunning,omitempty"`
+
+	// shared informers of the cluster of the watches & the
+	// controllers subscribed to these
+	Informers []dynamicinformer.SharedInformerStatus `json:"informers"`
+}
+
+// debugState returns the live state of this controller
+func (mgr *watchController) debugState() WatchControllerState {
+	state := WatchControllerState{
+		Key:         mgr.GCtlConfig.Key(),
+		Config:      mgr.declaredConfig,
+		HasSynced:   mgr.HasSynced(),
+		Workers:     mgr.workerCount,
+		QueueLength: mgr.watchQ.Len(),
+		Informers:   []string{},
+		LogLevel:    "default",
+		Warnings:    mgr.warnings.List(),
+	}
+	if level, ok := logging.GetVerbosity(mgr.GCtlConfig.Key()).Get(); ok {
+		state.LogLevel = strconv.Itoa(int(level))
+	}
+	for key := range mgr.watchInformers {
+		state.Informers = append(state.Informers, key)
+	}
+	for key := range mgr.attachmentInformers {
+		state.Informers = append(state.Informers, key)
+	}
+	if mgr.namespaceInformer != nil {
+		state.Informers = append(state.Informers, "namespaces.v1")
+	}
+	sort.Strings(state.Informers)
+
+	mgr.syncErrMutex.Lock()
+	defer mgr.syncErrMutex.Unlock()
+
+	if len(mgr.syncFailures) != 0 {
+		state.SyncFailures = make(map[string]WatchSyncFailure, len(mgr.syncFailures))
+		for key, failure := range mgr.syncFailures {
+			state.SyncFailures[key] = failure
+		}
+	}
+	if mgr.recentSyncErr != nil {
+		state.RecentSyncError = mgr.recentSyncErr.Error()
+	}
+	if mgr.lastSyncErr != nil {
+		state.LastSyncError = mgr.lastSyncErr.Error()
+	}
+	return state
+}
+
+// debugState returns the live state of the watch controllers of this
+// metacontroller
+//
+// NOTE:
+//	Caller is expected to guard the watch controllers
+func (mc *MetaController) debugState() DebugState {
+	state := DebugState{
+		Controllers: make([]WatchControllerState, 0, len(mc.WatchControllers)),
+	}
+	for _, wc := range mc.WatchControllers {
+		state.Controllers = append(state.Controllers, wc.debugState())
+	}
+	sort.Slice(state.Controllers, func(i, j int) bool {
+		return state.Controllers[i].Key < state.Controllers[j].Key
+	})
+	return state
+}
+
+// DebugState returns the live state of this metacontroller
+func (mc *ConfigBasedMetaController) DebugState() DebugState {
+	mc.mutex.Lock()
+	state := mc.debugState()
+	for key, err := range mc.startErrs {
+		if state.NotRunning == nil {
+			state.NotRunning = make(map[string]string)
+		}
+		state.NotRunning[key] = err.Error()
+	}
+	mc.mutex.Unlock()
+
+	state.Informers = mc.DynInformerFactory.Status()
+	return state
+}
+
+// DebugState returns the live state of this metacontroller
+func (mc *CRDBasedMetaController) DebugState() DebugState {
+	mc.mutex.Lock()
+	state := mc.debugState()
+	mc.mutex.Unlock()
+
+	state.Informers = mc.DynInformerFactory.Status()
+	return state
+}
+
+// ServeDebugState responds with the debug state of this
+// metacontroller as json
+func (mc *ConfigBasedMetaController) ServeDebugState(w http.ResponseWriter, r *http.Request) {
+	writeDebugState(w, mc.DebugState())
+}
+
+// ServeDebugState responds with the debug state of this
+// metacontroller as json
+func (mc *CRDBasedMetaController) ServeDebugState(w http.ResponseWriter, r *http.Request) {
+	writeDebugState(w, mc.DebugState())
+}
+
+// writeDebugState writes the given debug state as json
+func writeDebugState(w http.ResponseWriter, state DebugState) {
+	data, err := json.Marshal(state)
+	if err != nil {
+		glog.Errorf("Can't marshal debug state: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"openebs.io/metac/controller/common"
+	"openebs.io/metac/hooks/webhook"
+)
+
+const (
+	// EventReasonStarted is the reason of the event raised against
+	// a GenericController when its watch controller is started
+	EventReasonStarted string = "Started"
+
+	// EventReasonStopped is the reason of the event raised against
+	// a GenericController when its watch controller is stopped
+	EventReasonStopped string = "Stopped"
+
+	// EventReasonConfigRejected is the reason of the event raised
+	// against a GenericController when its watch controller can't
+	// be started due to its spec
+	EventReasonConfigRejected string = "ConfigRejected"
+
+	// EventReasonHookFailing is the reason of the event raised
+	// against a GenericController when its hook starts failing
+	EventReasonHookFailing string = "HookFailing"
+
+	// EventReasonSyncFailed is the reason of the event raised
+	// against a watch when its sync fails
+	EventReasonSyncFailed string = "SyncFailed"
+
+	// EventReasonHookTimeout is the reason of the event raised
+	// against a watch when the hook invoked for this watch does not
+	// respond within its timeout
+	EventReasonHookTimeout string = "HookTimeout"
+)
+
+// recordConfigEvent raises an event against the GenericController of
+// this watch controller. This is a no-op if event recorder is not set
+// or if the GenericController is not a custom resource.
+func (mgr *watchController) recordConfigEvent(
+	eventType, reason, messageFmt string, args ...interface{},
+) {
+	if mgr.eventRecorder == nil || !mgr.isConfigEvents {
+		return
+	}
+	mgr.eventRecorder.Eventf(mgr.declaredConfig, eventType, reason, messageFmt, args...)
+}
+
+// setHookErr records the outcome of the given hook invoked for the
+// given watch. Events are raised against the watch if the hook timed
+// out & against the GenericController if the hook starts failing.
+//
+// NOTE:
+//	GenericController gets a single event till the hook succeeds
+// again instead of an event per failed invocation
+func (mgr *watchController) setHookErr(
+	ctx context.Context, watch *unstructured.Unstructured, hook string, err error,
+) {
+	if err != nil && webhook.IsTimeoutError(err) {
+		mgr.recordEvent(
+			ctx,
+			watch,
+			corev1.EventTypeWarning,
+			EventReasonHookTimeout,
+			"%s hook timed out: %v",
+			hook,
+			err,
+		)
+	}
+
+	mgr.syncErrMutex.Lock()
+	isNewFailure := err != nil && !mgr.isHookFailing
+	mgr.isHookFailing = err != nil
+	mgr.syncErrMutex.Unlock()
+
+	if isNewFailure {
+		mgr.recordConfigEvent(
+			corev1.EventTypeWarning,
+			EventReasonHookFailing,
+			"%s hook failed for watch %s: %v: RequestID=%s",
+			hook,
+			common.DescObjectAsKey(watch),
+			err,
+			requestIDFrom(ctx),
+		)
+	}
+}
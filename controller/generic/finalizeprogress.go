@@ -0,0 +1,99 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"openebs.io/metac/controller/common"
+	k8s "openebs.io/metac/third_party/kubernetes"
+)
+
+// finalizeProgressStatusKey is the field of watch's status that
+// tracks the progress of finalization
+const finalizeProgressStatusKey string = "finalizeProgress"
+
+// getFinalizeProgress returns the finalize progress found in the
+// given watch's status
+func getFinalizeProgress(watch *unstructured.Unstructured) FinalizeProgress {
+	return FinalizeProgress{
+		Pending: k8s.GetNestedSlice(
+			watch.UnstructuredContent(), "status", finalizeProgressStatusKey, "pending",
+		),
+		CleanedUp: k8s.GetNestedSlice(
+			watch.UnstructuredContent(), "status", finalizeProgressStatusKey, "cleanedUp",
+		),
+	}
+}
+
+// makeFinalizeProgress returns the finalize progress of the given watch
+// based on its previous progress & the given observed attachments.
+// Attachments that were pending previously & are no longer observed
+// are marked as cleaned up.
+//
+// NOTE:
+//	Read only attachments are never cleaned up & hence are not tracked
+func (mgr *watchController) makeFinalizeProgress(
+	watch *unstructured.Unstructured, observed common.AnyUnstructRegistry,
+) *FinalizeProgress {
+	ruleMgr := newAttachmentRuleManager(
+		mgr.attachmentResourceMgr,
+		mgr.GCtlConfig.Spec.Attachments,
+	)
+	pending := sets.NewString()
+	for _, obj := range observed.List() {
+		if obj == nil {
+			continue
+		}
+		apiGroup, _ := common.ParseAPIVersionToGroupVersion(obj.GetAPIVersion())
+		if ruleMgr.IsReadOnlyByGK(apiGroup, obj.GetKind()) {
+			continue
+		}
+		pending.Insert(common.DescObjectAsKey(obj))
+	}
+
+	previous := getFinalizeProgress(watch)
+	cleanedUp := sets.NewString(previous.CleanedUp...).
+		Union(sets.NewString(previous.Pending...)).
+		Difference(pending)
+
+	return &FinalizeProgress{
+		Pending:   pending.List(),
+		CleanedUp: cleanedUp.List(),
+	}
+}
+
+// setFinalizeProgress sets the given finalize progress against the
+// given status. It returns the resulting status.
+func setFinalizeProgress(
+	status map[string]interface{}, progress *FinalizeProgress,
+) map[string]interface{} {
+	if progress == nil {
+		return status
+	}
+	if status == nil {
+		status = make(map[string]interface{})
+	} else {
+		status = copyStatusConditions(status)
+	}
+	progressObj := make(map[string]interface{})
+	k8s.SetNestedSlice(progressObj, progress.Pending, "pending")
+	k8s.SetNestedSlice(progressObj, progress.CleanedUp, "cleanedUp")
+	status[finalizeProgressStatusKey] = progressObj
+	return status
+}
@@ -0,0 +1,335 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/json"
+
+	"openebs.io/metac/controller/common"
+)
+
+// triggers i.e. the reasons due to which a watch got queued to be
+// reconciled
+const (
+	// watch got added to the informer's cache
+	TriggerAdd = "Add"
+
+	// watch got updated
+	TriggerUpdate = "Update"
+
+	// watch got deleted
+	TriggerDelete = "Delete"
+
+	// informer's periodic resync of the watch
+	TriggerResync = "Resync"
+
+	// namespace of the watch got cached or its annotations changed
+	TriggerNamespace = "Namespace"
+
+	// sync hook requested a resync via resyncAfterSeconds
+	TriggerResyncAfter = "ResyncAfter"
+
+	// previous reconcile of the watch failed
+	TriggerRetry = "Retry"
+
+	// watch is synced once its hot loop backoff is over
+	TriggerHotLoopBackoff = "HotLoopBackoff"
+
+	// watch got taken over by this replica since the members of
+	// the shard group changed
+	TriggerShard = "Shard"
+)
+
+// DefaultReconcileHistorySize is the number of most recent reconciles
+// that are retained per watch controller by default
+const DefaultReconcileHistorySize = 20
+
+// ReconcileRecord represents a single reconcile of a watch
+type ReconcileRecord struct {
+	// watch that got reconciled
+	Watch string `json:"watch"`
+
+	// reason due to which the watch got queued; the earliest reason
+	// is retained if the watch got queued multiple times before
+	// this reconcile
+	Trigger string `json:"trigger"`
+
+	// identifies this reconcile in the logs, the events & the hook
+	// requests i.e. X-Request-Id header
+	RequestID string `json:"requestID"`
+
+	// time at which this reconcile started
+	Time time.Time `json:"time"`
+
+	DurationSeconds float64 `json:"durationSeconds"`
+
+	// time taken by the sync or finalize hook; this is not set if
+	// the hook was not invoked
+	HookDurationSeconds float64 `json:"hookDurationSeconds,omitempty"`
+
+	// changes made to the watch & its attachments
+	Actions []string `json:"actions,omitempty"`
+
+	Error string `json:"error,omitempty"`
+
+	// true if this reconcile wrote to the watch or its attachments
+	isWritten bool
+}
+
+// addAction records the given change made by this reconcile
+//
+// NOTE:
+//	This is a no-op if the record is nil
+func (r *ReconcileRecord) addAction(format string, args ...interface{}) {
+	if r == nil {
+		return
+	}
+	r.Actions = append(r.Actions, fmt.Sprintf(format, args...))
+}
+
+// addWriteAction records the given change made by this reconcile
+// that wrote to the watch or its attachments
+//
+// NOTE:
+//	This is a no-op if the record is nil
+func (r *ReconcileRecord) addWriteAction(format string, args ...interface{}) {
+	if r == nil {
+		return
+	}
+	r.isWritten = true
+	r.addAction(format, args...)
+}
+
+// addAttachmentAction records the given change made by this
+// reconcile to the given attachment
+//
+// NOTE:
+//	This is a no-op if the record is nil
+func (r *ReconcileRecord) addAttachmentAction(action string, obj *unstructured.Unstructured) {
+	r.addWriteAction("%s attachment %s", action, common.DescObjectAsKey(obj))
+}
+
+// setHookDuration records the time taken by the hook invoked by this
+// reconcile
+//
+// NOTE:
+//	This is a no-op if the record is nil
+func (r *ReconcileRecord) setHookDuration(duration time.Duration) {
+	if r == nil {
+		return
+	}
+	r.HookDurationSeconds = duration.Seconds()
+}
+
+// reconcileRecordKey is the key of the reconcile record set against
+// the context of a reconcile
+type reconcileRecordKey struct{}
+
+// withReconcileRecord returns a copy of the given context that holds
+// the given record
+func withReconcileRecord(ctx context.Context, record *ReconcileRecord) context.Context {
+	return context.WithValue(ctx, reconcileRecordKey{}, record)
+}
+
+// reconcileRecordFrom returns the reconcile record held by the given
+// context. Nil is returned if the context does not hold a record.
+func reconcileRecordFrom(ctx context.Context) *ReconcileRecord {
+	record, _ := ctx.Value(reconcileRecordKey{}).(*ReconcileRecord)
+	return record
+}
+
+// reconcileHistory is a ring buffer of the most recent reconciles of
+// a watch controller
+//
+// NOTE:
+//	Methods of a nil history are no-ops
+type reconcileHistory struct {
+	mutex sync.Mutex
+
+	// records in the order of their reconciles; the oldest record
+	// is overwritten once this is full
+	records []ReconcileRecord
+
+	// index at which the next record is set
+	next int
+}
+
+// newReconcileHistory returns a new history that retains the given
+// number of records. Nil is returned if size is not positive.
+func newReconcileHistory(size int) *reconcileHistory {
+	if size <= 0 {
+		return nil
+	}
+	return &reconcileHistory{
+		records: make([]ReconcileRecord, 0, size),
+	}
+}
+
+// add records the given reconcile
+func (h *reconcileHistory) add(record ReconcileRecord) {
+	if h == nil {
+		return
+	}
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if len(h.records) < cap(h.records) {
+		h.records = append(h.records, record)
+		return
+	}
+	h.records[h.next] = record
+	h.next = (h.next + 1) % len(h.records)
+}
+
+// list returns the records of the given watch with the most recent
+// reconcile first. Records of all the watches are returned if watch
+// is empty.
+func (h *reconcileHistory) list(watch string) []ReconcileRecord {
+	if h == nil {
+		return nil
+	}
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	var records []ReconcileRecord
+	for i := len(h.records) - 1; i >= 0; i-- {
+		record := h.records[(h.next+i)%len(h.records)]
+		if watch != "" && record.Watch != watch {
+			continue
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// markTrigger tracks the reason due to which the watch having the
+// given key got queued. The earliest reason is retained till the
+// watch is reconciled.
+func (mgr *watchController) markTrigger(key, trigger string) {
+	mgr.enqueueMutex.Lock()
+	defer mgr.enqueueMutex.Unlock()
+
+	if _, found := mgr.enqueueTriggers[key]; !found {
+		mgr.enqueueTriggers[key] = trigger
+	}
+}
+
+// takeTrigger returns the reason due to which the watch having the
+// given key got queued. The reason is no longer tracked once
+// returned.
+func (mgr *watchController) takeTrigger(key string) string {
+	mgr.enqueueMutex.Lock()
+	defer mgr.enqueueMutex.Unlock()
+
+	trigger := mgr.enqueueTriggers[key]
+	delete(mgr.enqueueTriggers, key)
+	return trigger
+}
+
+// ControllerReconcileHistory represents the most recent reconciles
+// of a watch controller
+type ControllerReconcileHistory struct {
+	// refers to the controller's namespace & name
+	Controller string `json:"controller"`
+
+	// reconciles with the most recent one first
+	Reconciles []ReconcileRecord `json:"reconciles"`
+}
+
+// reconcileHistory returns the reconciles of the watch controllers of
+// this metacontroller sorted by the controller keys. Only the given
+// controller & watch are considered if these are not empty.
+//
+// NOTE:
+//	Caller is expected to guard the watch controllers
+func (mc *MetaController) reconcileHistory(controller, watch string) []ControllerReconcileHistory {
+	histories := []ControllerReconcileHistory{}
+	for key, wc := range mc.WatchControllers {
+		if controller != "" && key != controller {
+			continue
+		}
+		records := wc.history.list(watch)
+		if records == nil {
+			records = []ReconcileRecord{}
+		}
+		histories = append(histories, ControllerReconcileHistory{
+			Controller: key,
+			Reconciles: records,
+		})
+	}
+	sort.Slice(histories, func(i, j int) bool {
+		return histories[i].Controller < histories[j].Controller
+	})
+	return histories
+}
+
+// ReconcileHistory returns the most recent reconciles of the watch
+// controllers of this metacontroller
+func (mc *ConfigBasedMetaController) ReconcileHistory(controller, watch string) []ControllerReconcileHistory {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	return mc.reconcileHistory(controller, watch)
+}
+
+// ReconcileHistory returns the most recent reconciles of the watch
+// controllers of this metacontroller
+func (mc *CRDBasedMetaController) ReconcileHistory(controller, watch string) []ControllerReconcileHistory {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	return mc.reconcileHistory(controller, watch)
+}
+
+// ServeReconcileHistory responds with the most recent reconciles of
+// this metacontroller as json. Query parameters controller & watch
+// filter these reconciles.
+func (mc *ConfigBasedMetaController) ServeReconcileHistory(w http.ResponseWriter, r *http.Request) {
+	writeReconcileHistory(
+		w, mc.ReconcileHistory(r.URL.Query().Get("controller"), r.URL.Query().Get("watch")),
+	)
+}
+
+// ServeReconcileHistory responds with the most recent reconciles of
+// this metacontroller as json. Query parameters controller & watch
+// filter these reconciles.
+func (mc *CRDBasedMetaController) ServeReconcileHistory(w http.ResponseWriter, r *http.Request) {
+	writeReconcileHistory(
+		w, mc.ReconcileHistory(r.URL.Query().Get("controller"), r.URL.Query().Get("watch")),
+	)
+}
+
+// writeReconcileHistory writes the given reconciles as json
+func writeReconcileHistory(w http.ResponseWriter, histories []ControllerReconcileHistory) {
+	data, err := json.Marshal(histories)
+	if err != nil {
+		glog.Errorf("Can't marshal reconcile history: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
@@ -0,0 +1,199 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// newTestHistory returns the history of the given size having the
+// given reconciles added in their order. Each reconcile is a watch &
+// request ID pair.
+func newTestHistory(size int, reconciles ...[2]string) *reconcileHistory {
+	history := newReconcileHistory(size)
+	for _, r := range reconciles {
+		history.add(ReconcileRecord{Watch: r[0], RequestID: r[1]})
+	}
+	return history
+}
+
+// listRequestIDs returns the request IDs of the given records
+func listRequestIDs(records []ReconcileRecord) []string {
+	var ids []string
+	for _, record := range records {
+		ids = append(ids, record.RequestID)
+	}
+	return ids
+}
+
+func TestReconcileHistory(t *testing.T) {
+	var tests = map[string]struct {
+		size       int
+		reconciles [][2]string
+		watch      string
+		want       []string
+	}{
+		"empty history": {
+			size: 3,
+		},
+		"history that is not full": {
+			size:       3,
+			reconciles: [][2]string{{"ns/a", "1"}, {"ns/b", "2"}},
+			want:       []string{"2", "1"},
+		},
+		"history that is just full": {
+			size:       3,
+			reconciles: [][2]string{{"ns/a", "1"}, {"ns/b", "2"}, {"ns/a", "3"}},
+			want:       []string{"3", "2", "1"},
+		},
+		"oldest reconciles are evicted": {
+			size: 3,
+			reconciles: [][2]string{
+				{"ns/a", "1"}, {"ns/b", "2"}, {"ns/a", "3"}, {"ns/b", "4"}, {"ns/a", "5"},
+			},
+			want: []string{"5", "4", "3"},
+		},
+		"history of size 1": {
+			size:       1,
+			reconciles: [][2]string{{"ns/a", "1"}, {"ns/b", "2"}},
+			want:       []string{"2"},
+		},
+		"history of size 0 retains nothing": {
+			reconciles: [][2]string{{"ns/a", "1"}},
+		},
+		"history of negative size retains nothing": {
+			size:       -1,
+			reconciles: [][2]string{{"ns/a", "1"}},
+		},
+		"filter by watch": {
+			size: 3,
+			reconciles: [][2]string{
+				{"ns/a", "1"}, {"ns/b", "2"}, {"ns/a", "3"}, {"ns/b", "4"}, {"ns/a", "5"},
+			},
+			watch: "ns/a",
+			want:  []string{"5", "3"},
+		},
+		"filter by watch that is evicted": {
+			size:       2,
+			reconciles: [][2]string{{"ns/a", "1"}, {"ns/b", "2"}, {"ns/b", "3"}},
+			watch:      "ns/a",
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			history := newTestHistory(mock.size, mock.reconciles...)
+			if mock.size <= 0 && history != nil {
+				t.Fatalf("Expected nil history got %v", history)
+			}
+			got := listRequestIDs(history.list(mock.watch))
+			if !reflect.DeepEqual(got, mock.want) {
+				t.Fatalf("Expected reconciles %v got %v", mock.want, got)
+			}
+		})
+	}
+}
+
+func TestConfigBasedMetaControllerServeReconcileHistory(t *testing.T) {
+	mc := &ConfigBasedMetaController{}
+	mc.WatchControllers = map[string]*watchController{
+		"metac/one": {
+			history: newTestHistory(3, [2]string{"ns/a", "1"}, [2]string{"ns/b", "2"}),
+		},
+		"metac/two": {
+			history: newTestHistory(3, [2]string{"ns/a", "3"}),
+		},
+		"metac/none": {},
+	}
+
+	var tests = map[string]struct {
+		query string
+		want  map[string][]string
+	}{
+		"all controllers & watches": {
+			want: map[string][]string{
+				"metac/none": nil,
+				"metac/one":  {"2", "1"},
+				"metac/two":  {"3"},
+			},
+		},
+		"filter by controller": {
+			query: "?controller=metac/one",
+			want: map[string][]string{
+				"metac/one": {"2", "1"},
+			},
+		},
+		"filter by watch": {
+			query: "?watch=ns/a",
+			want: map[string][]string{
+				"metac/none": nil,
+				"metac/one":  {"1"},
+				"metac/two":  {"3"},
+			},
+		},
+		"filter by controller & watch": {
+			query: "?controller=metac/two&watch=ns/b",
+			want: map[string][]string{
+				"metac/two": nil,
+			},
+		},
+		"filter by unknown controller": {
+			query: "?controller=metac/unknown",
+			want:  map[string][]string{},
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			mc.ServeReconcileHistory(
+				w, httptest.NewRequest(http.MethodGet, "/debug/history"+mock.query, nil),
+			)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status %d got %d", http.StatusOK, w.Code)
+			}
+			var histories []ControllerReconcileHistory
+			err := json.Unmarshal(w.Body.Bytes(), &histories)
+			if err != nil {
+				t.Fatalf("Expected no error got %v: %s", err, w.Body.String())
+			}
+			got := map[string][]string{}
+			var controllers []string
+			for _, history := range histories {
+				if history.Reconciles == nil {
+					t.Fatalf("Expected empty reconciles of %s got null", history.Controller)
+				}
+				got[history.Controller] = listRequestIDs(history.Reconciles)
+				controllers = append(controllers, history.Controller)
+			}
+			if !reflect.DeepEqual(got, mock.want) {
+				t.Fatalf("Expected %v got %v", mock.want, got)
+			}
+			for i := 1; i < len(controllers); i++ {
+				if controllers[i-1] > controllers[i] {
+					t.Fatalf("Expected controllers sorted by key got %v", controllers)
+				}
+			}
+		})
+	}
+}
@@ -17,10 +17,16 @@ limitations under the License.
 package generic
 
 import (
+	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 
 	"openebs.io/metac/apis/metacontroller/v1alpha1"
 	"openebs.io/metac/controller/common"
+	dynamicapply "openebs.io/metac/dynamic/apply"
+	"openebs.io/metac/hooks/webhook"
 )
 
 // SyncHookRequest is the object sent as JSON to the sync hook.
@@ -42,6 +48,51 @@ type SyncHookRequest struct {
 	// It is upto the reconcile logic implementation to separate
 	// create/update from delete logic.
 	Finalizing bool `json:"finalizing"`
+
+	// Progress of the finalization i.e. the attachments that are
+	// cleaned up & the ones that are pending. This is set only when
+	// Finalizing is true.
+	FinalizeProgress *FinalizeProgress `json:"finalizeProgress,omitempty"`
+
+	// Fields of the attachments that got updated by the previous sync
+	// of this watch. This is set only if the controller is configured
+	// to include apply diffs.
+	ApplyDiffs []AttachmentApplyDiff `json:"applyDiffs,omitempty"`
+
+	// Warnings of the API server(s) that were recently seen in
+	// response to the requests of this controller e.g. deprecations.
+	// This is set only if the controller is configured to include
+	// warnings.
+	Warnings []string `json:"warnings,omitempty"`
+
+	// Static parameters of this generic controller that are sent
+	// verbatim as declared at the generic controller specs
+	Parameters *runtime.RawExtension `json:"parameters,omitempty"`
+}
+
+// AttachmentApplyDiff represents the fields of an attachment that
+// got updated by a sync
+type AttachmentApplyDiff struct {
+	// attachment referred to by its apiVersion, kind, namespace & name
+	Attachment string `json:"attachment"`
+
+	// fields of the attachment that got updated
+	Diffs []dynamicapply.FieldDiff `json:"diffs"`
+}
+
+// FinalizeProgress tracks the cleanup of attachments while the
+// watch is being finalized. Attachments are referred to by their
+// apiVersion, kind, namespace & name.
+//
+// NOTE:
+//	This is also set at the watch's status.finalizeProgress
+type FinalizeProgress struct {
+	// attachments that are yet to be cleaned up
+	Pending []string `json:"pending"`
+
+	// attachments that were observed during finalization & are
+	// no longer observed
+	CleanedUp []string `json:"cleanedUp"`
 }
 
 // SyncHookResponse is the expected format of the JSON response
@@ -59,6 +110,10 @@ type SyncHookResponse struct {
 	// desired state of all attachments
 	Attachments []*unstructured.Unstructured `json:"attachments"`
 
+	// desired replicas of the attachments that are scaled via their
+	// scale subresource
+	Scales []AttachmentScale `json:"scales"`
+
 	// indicate the controller if a resync is required after
 	// the specified interval
 	ResyncAfterSeconds float64 `json:"resyncAfterSeconds"`
@@ -73,10 +128,67 @@ type SyncHookResponse struct {
 	Finalized bool `json:"finalized"`
 }
 
+// AttachmentScale represents the desired replicas of an attachment
+// that serves the scale subresource
+//
+// NOTE:
+//	Replicas are set against the attachment without updating the rest
+// of its spec. Hence the desired state of this attachment if any is
+// not expected to set its replicas.
+type AttachmentScale struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+
+	// Namespace of the attachment; defaults to the namespace of the
+	// watch if the attachment is namespaced
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+
+	// desired number of replicas of the attachment
+	Replicas int32 `json:"replicas"`
+}
+
 // HookInvoker manages invocation of hook. This understands inline
+// validateHooks returns error if the hooks set by the given
+// controller can't be invoked
+//
+// NOTE:
+//	Inline hooks are not looked up in the registry since functions
+// may be registered after the controller is validated
+func validateHooks(config *v1alpha1.GenericController) error {
+	if config.Spec.Hooks == nil {
+		return nil
+	}
+	var errs []error
+	for _, named := range []struct {
+		name string
+		hook *v1alpha1.Hook
+	}{
+		{"Sync", config.Spec.Hooks.Sync},
+		{"Finalize", config.Spec.Hooks.Finalize},
+	} {
+		if named.hook == nil ||
+			(named.hook.Inline != nil && named.hook.Inline.FuncName != nil) {
+			continue
+		}
+		err := common.ValidateHook(named.hook)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "Invalid %s hook", named.name))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
 // hook invocation that is supported by generic controller
 type HookInvoker struct {
 	Schema *v1alpha1.Hook
+
+	// trace span of the invocation if any
+	Span *trace.Span
+
+	// identifies the reconcile that results in this invocation if
+	// any; this is sent to webhooks as the X-Request-Id header
+	RequestID string
 }
 
 // Invoke invokes the hook based on the given request & fills the
@@ -92,5 +204,12 @@ func (i *HookInvoker) Invoke(req *SyncHookRequest, resp *SyncHookResponse) error
 		return ihi.Invoke(req, resp)
 	}
 	// this is one of the commonly supported hooks
-	return common.InvokeHook(i.Schema, req, resp)
+	var opts []webhook.InvokerOption
+	if i.Span != nil {
+		opts = append(opts, webhook.SetSpan(i.Span))
+	}
+	if i.RequestID != "" {
+		opts = append(opts, webhook.SetRequestID(i.RequestID))
+	}
+	return common.InvokeHookWith(i.Schema, req, resp, opts...)
 }
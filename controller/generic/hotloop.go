@@ -0,0 +1,89 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"openebs.io/metac/controller/common"
+)
+
+const (
+	// DefaultHotLoopThreshold is the number of syncs of a watch within
+	// the hot loop window that observe a change to this watch or its
+	// attachments after a sync that wrote to consider this watch as
+	// hot looping
+	DefaultHotLoopThreshold = 20
+
+	// DefaultHotLoopWindow is the duration within which the hot loop
+	// threshold is evaluated
+	DefaultHotLoopWindow = time.Minute
+
+	// DefaultHotLoopBackoff is the duration for which a hot looping
+	// watch is not synced
+	DefaultHotLoopBackoff = 5 * time.Minute
+)
+
+// HotLoopSettings tune the detection of the watches that are synced &
+// mutated continuously by their controllers
+type HotLoopSettings struct {
+	// Threshold is the number of syncs within Window that observe a
+	// change after a sync that wrote to consider the watch as hot
+	// looping. Defaults to DefaultHotLoopThreshold if not set. Hot
+	// loops are not detected if this is negative.
+	Threshold int
+
+	// Window is the duration within which Threshold is evaluated.
+	// Defaults to DefaultHotLoopWindow if not set.
+	Window time.Duration
+
+	// Backoff is the duration for which a hot looping watch is not
+	// synced. Defaults to DefaultHotLoopBackoff if not set.
+	Backoff time.Duration
+}
+
+// validate returns error if these settings are invalid
+func (s HotLoopSettings) validate() error {
+	if s.Window < 0 {
+		return errors.Errorf("Invalid hot loop window %s: Can't be negative", s.Window)
+	}
+	if s.Backoff < 0 {
+		return errors.Errorf("Invalid hot loop backoff %s: Can't be negative", s.Backoff)
+	}
+	return nil
+}
+
+// newDetector returns a new hot loop detector based on these settings.
+// Nil is returned if hot loops should not be detected.
+func (s HotLoopSettings) newDetector() *common.HotLoopDetector {
+	if s.Threshold < 0 {
+		return nil
+	}
+	threshold, window, backoff := s.Threshold, s.Window, s.Backoff
+	if threshold == 0 {
+		threshold = DefaultHotLoopThreshold
+	}
+	if window == 0 {
+		window = DefaultHotLoopWindow
+	}
+	if backoff == 0 {
+		backoff = DefaultHotLoopBackoff
+	}
+	return common.NewHotLoopDetector(threshold, window, backoff)
+}
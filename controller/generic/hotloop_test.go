@@ -0,0 +1,77 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHotLoopSettingsNewDetector(t *testing.T) {
+	var tests = map[string]struct {
+		settings        HotLoopSettings
+		isNil           bool
+		expectThreshold int
+		expectWindow    time.Duration
+		expectBackoff   time.Duration
+	}{
+		"defaults": {
+			expectThreshold: DefaultHotLoopThreshold,
+			expectWindow:    DefaultHotLoopWindow,
+			expectBackoff:   DefaultHotLoopBackoff,
+		},
+		"custom settings": {
+			settings: HotLoopSettings{
+				Threshold: 5,
+				Window:    10 * time.Second,
+				Backoff:   time.Minute,
+			},
+			expectThreshold: 5,
+			expectWindow:    10 * time.Second,
+			expectBackoff:   time.Minute,
+		},
+		"disabled": {
+			settings: HotLoopSettings{Threshold: -1},
+			isNil:    true,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			got := mock.settings.newDetector()
+			if mock.isNil {
+				if got != nil {
+					t.Fatalf("Expected no detector got %+v", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatalf("Expected detector got none")
+			}
+			if got.Threshold != mock.expectThreshold ||
+				got.Window != mock.expectWindow ||
+				got.Backoff != mock.expectBackoff {
+				t.Fatalf(
+					"Expected %d/%s/%s got %d/%s/%s",
+					mock.expectThreshold, mock.expectWindow, mock.expectBackoff,
+					got.Threshold, got.Window, got.Backoff,
+				)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"k8s.io/client-go/rest"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+	dynamicclientset "openebs.io/metac/dynamic/clientset"
+)
+
+// validateImpersonation returns error if the identity to be
+// impersonated by the given controller is invalid
+func validateImpersonation(config *v1alpha1.GenericController) error {
+	impersonate := config.Spec.Impersonate
+	if impersonate == nil {
+		return nil
+	}
+	if impersonate.ServiceAccount != nil && impersonate.User != "" {
+		return errors.Errorf(
+			"Invalid impersonation: Can't set both service account & user",
+		)
+	}
+	if impersonate.ServiceAccount == nil && impersonate.User == "" {
+		return errors.Errorf(
+			"Invalid impersonation: Either service account or user must be set",
+		)
+	}
+	if impersonate.ServiceAccount == nil {
+		return nil
+	}
+	if len(impersonate.Groups) != 0 {
+		return errors.Errorf(
+			"Invalid impersonation: Groups can't be set along with service account",
+		)
+	}
+	if impersonate.ServiceAccount.Name == "" {
+		return errors.Errorf(
+			"Invalid impersonation: Missing service account name",
+		)
+	}
+	if impersonate.ServiceAccount.Namespace == "" && config.Namespace == "" {
+		return errors.Errorf(
+			"Invalid impersonation: Missing namespace of service account %q",
+			impersonate.ServiceAccount.Name,
+		)
+	}
+	return nil
+}
+
+// makeImpersonationConfig returns the identity to be impersonated by
+// the given controller
+//
+// NOTE:
+//	API server sets the groups of a service account when it is
+// impersonated without any groups
+func makeImpersonationConfig(config *v1alpha1.GenericController) rest.ImpersonationConfig {
+	impersonate := config.Spec.Impersonate
+	if impersonate.ServiceAccount == nil {
+		return rest.ImpersonationConfig{
+			UserName: impersonate.User,
+			Groups:   impersonate.Groups,
+		}
+	}
+	namespace := impersonate.ServiceAccount.Namespace
+	if namespace == "" {
+		namespace = config.Namespace
+	}
+	return rest.ImpersonationConfig{
+		UserName: fmt.Sprintf(
+			"system:serviceaccount:%s:%s", namespace, impersonate.ServiceAccount.Name,
+		),
+	}
+}
+
+// withImpersonation returns the clientset to be used by the given
+// controller. This is a clientset that impersonates the identity set
+// in the controller if any.
+func withImpersonation(
+	dynClientset *dynamicclientset.Clientset, config *v1alpha1.GenericController,
+) (*dynamicclientset.Clientset, error) {
+	if config.Spec.Impersonate == nil {
+		return dynClientset, nil
+	}
+	impersonate := makeImpersonationConfig(config)
+	newControllerLogger(config).V(2).Info(
+		"Will impersonate", "user", impersonate.UserName, "groups", impersonate.Groups,
+	)
+	return dynClientset.Impersonate(impersonate)
+}
@@ -0,0 +1,237 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+	dynamicclientset "openebs.io/metac/dynamic/clientset"
+)
+
+// newImpersonationController returns the controller in metac namespace
+// that impersonates the given identity
+func newImpersonationController(
+	impersonate *v1alpha1.GenericControllerImpersonation,
+) *v1alpha1.GenericController {
+	return &v1alpha1.GenericController{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-ctl", Namespace: "metac"},
+		Spec:       v1alpha1.GenericControllerSpec{Impersonate: impersonate},
+	}
+}
+
+// newIdentityServer returns a server that records the impersonated
+// identity of the last request it receives
+func newIdentityServer() (*httptest.Server, func() (string, []string)) {
+	var mutex sync.Mutex
+	var user string
+	var groups []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		user = r.Header.Get("Impersonate-User")
+		groups = r.Header["Impersonate-Group"]
+		mutex.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "my-cm", "namespace": "default"}}`))
+	}))
+	return server, func() (string, []string) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return user, groups
+	}
+}
+
+func TestValidateImpersonation(t *testing.T) {
+	var tests = map[string]struct {
+		impersonate *v1alpha1.GenericControllerImpersonation
+		namespace   string
+		isErr       bool
+	}{
+		"no impersonation": {},
+		"user with groups": {
+			impersonate: &v1alpha1.GenericControllerImpersonation{
+				User: "jane", Groups: []string{"dev"},
+			},
+		},
+		"service account": {
+			impersonate: &v1alpha1.GenericControllerImpersonation{
+				ServiceAccount: &v1alpha1.GenericControllerServiceAccount{Name: "sa"},
+			},
+			namespace: "metac",
+		},
+		"both service account & user": {
+			impersonate: &v1alpha1.GenericControllerImpersonation{
+				User:           "jane",
+				ServiceAccount: &v1alpha1.GenericControllerServiceAccount{Name: "sa"},
+			},
+			namespace: "metac",
+			isErr:     true,
+		},
+		"neither service account nor user": {
+			impersonate: &v1alpha1.GenericControllerImpersonation{
+				Groups: []string{"dev"},
+			},
+			isErr: true,
+		},
+		"service account with groups": {
+			impersonate: &v1alpha1.GenericControllerImpersonation{
+				ServiceAccount: &v1alpha1.GenericControllerServiceAccount{Name: "sa"},
+				Groups:         []string{"dev"},
+			},
+			namespace: "metac",
+			isErr:     true,
+		},
+		"service account without name": {
+			impersonate: &v1alpha1.GenericControllerImpersonation{
+				ServiceAccount: &v1alpha1.GenericControllerServiceAccount{Namespace: "ns"},
+			},
+			isErr: true,
+		},
+		"service account without any namespace": {
+			impersonate: &v1alpha1.GenericControllerImpersonation{
+				ServiceAccount: &v1alpha1.GenericControllerServiceAccount{Name: "sa"},
+			},
+			isErr: true,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			config := newImpersonationController(mock.impersonate)
+			config.Namespace = mock.namespace
+			err := validateImpersonation(config)
+			if mock.isErr != (err != nil) {
+				t.Fatalf("Expected error %t got %v", mock.isErr, err)
+			}
+		})
+	}
+}
+
+func TestMakeImpersonationConfig(t *testing.T) {
+	var tests = map[string]struct {
+		impersonate *v1alpha1.GenericControllerImpersonation
+		want        rest.ImpersonationConfig
+	}{
+		"user with groups": {
+			impersonate: &v1alpha1.GenericControllerImpersonation{
+				User: "jane", Groups: []string{"dev", "ops"},
+			},
+			want: rest.ImpersonationConfig{UserName: "jane", Groups: []string{"dev", "ops"}},
+		},
+		"service account of another namespace": {
+			impersonate: &v1alpha1.GenericControllerImpersonation{
+				ServiceAccount: &v1alpha1.GenericControllerServiceAccount{
+					Name: "sa", Namespace: "ns",
+				},
+			},
+			want: rest.ImpersonationConfig{UserName: "system:serviceaccount:ns:sa"},
+		},
+		"service account of controller's namespace": {
+			impersonate: &v1alpha1.GenericControllerImpersonation{
+				ServiceAccount: &v1alpha1.GenericControllerServiceAccount{Name: "sa"},
+			},
+			want: rest.ImpersonationConfig{UserName: "system:serviceaccount:metac:sa"},
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			got := makeImpersonationConfig(newImpersonationController(mock.impersonate))
+			if !reflect.DeepEqual(got, mock.want) {
+				t.Fatalf("Expected %+v got %+v", mock.want, got)
+			}
+		})
+	}
+}
+
+func TestWithImpersonation(t *testing.T) {
+	resourceMgr, stop := newFakeResourceManager(t, newWildcardTestResources()...)
+	defer stop()
+
+	var tests = map[string]struct {
+		impersonate  *v1alpha1.GenericControllerImpersonation
+		wantUser     string
+		wantGroups   []string
+		isNewClientset bool
+	}{
+		"no impersonation uses shared clientset": {},
+		"user with groups": {
+			impersonate: &v1alpha1.GenericControllerImpersonation{
+				User: "jane", Groups: []string{"dev", "ops"},
+			},
+			wantUser:     "jane",
+			wantGroups:   []string{"dev", "ops"},
+			isNewClientset: true,
+		},
+		"service account": {
+			impersonate: &v1alpha1.GenericControllerImpersonation{
+				ServiceAccount: &v1alpha1.GenericControllerServiceAccount{Name: "sa"},
+			},
+			wantUser:     "system:serviceaccount:metac:sa",
+			isNewClientset: true,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			server, getIdentity := newIdentityServer()
+			defer server.Close()
+			shared, err := dynamicclientset.New(&rest.Config{Host: server.URL}, resourceMgr)
+			if err != nil {
+				t.Fatalf("Expected no error got %v", err)
+			}
+			got, err := withImpersonation(shared, newImpersonationController(mock.impersonate))
+			if err != nil {
+				t.Fatalf("Expected no error got %v", err)
+			}
+			if isNewClientset := got != shared; isNewClientset != mock.isNewClientset {
+				t.Fatalf("Expected new clientset %t got %t", mock.isNewClientset, isNewClientset)
+			}
+
+			// get via the returned clientset & then via the shared one
+			for _, cs := range []*dynamicclientset.Clientset{got, shared} {
+				client, err := cs.GetClientByKind("v1", "ConfigMap")
+				if err != nil {
+					t.Fatalf("Expected no error got %v", err)
+				}
+				_, err = client.Namespace("default").Get("my-cm", metav1.GetOptions{})
+				if err != nil {
+					t.Fatalf("Expected no error got %v", err)
+				}
+				user, groups := getIdentity()
+				if user != mock.wantUser || !reflect.DeepEqual(groups, mock.wantGroups) {
+					t.Fatalf(
+						"Expected identity %q %v got %q %v",
+						mock.wantUser, mock.wantGroups, user, groups,
+					)
+				}
+				// shared clientset never impersonates
+				mock.wantUser, mock.wantGroups = "", nil
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+	dynamicinformer "openebs.io/metac/dynamic/informer"
+)
+
+const (
+	// reason of Degraded condition when any informer of the
+	// controller fails to list or watch its resources repeatedly
+	statusReasonInformerFailing string = "InformerFailing"
+
+	// EventReasonInformerFailing is the reason of the event raised
+	// against a GenericController when any of its informers starts
+	// failing to list or watch its resources
+	EventReasonInformerFailing string = "InformerFailing"
+)
+
+// listFailingInformers returns the informers of this controller that
+// fail to list or watch their resources repeatedly. Informers are
+// described along with their failures & are sorted.
+func (mgr *watchController) listFailingInformers() []string {
+	informers := make(map[string]*dynamicinformer.ResourceInformer)
+	for key, informer := range mgr.watchInformers {
+		informers[key] = informer
+	}
+	for key, informer := range mgr.attachmentInformers {
+		informers[key] = informer
+	}
+	if mgr.namespaceInformer != nil {
+		informers["v1/namespaces"] = mgr.namespaceInformer
+	}
+	var failing []string
+	for key, informer := range informers {
+		health := informer.Health()
+		if !health.IsFailing() {
+			continue
+		}
+		failing = append(failing, fmt.Sprintf(
+			"%s: %d consecutive failures since %s: %s",
+			key,
+			health.ConsecutiveFailures,
+			health.FailingSince.Format(time.RFC3339),
+			health.LastError,
+		))
+	}
+	sort.Strings(failing)
+	return failing
+}
+
+// isNewInformerFailure returns true if the given new status is
+// degraded due to failing informers & the given old status is not
+func isNewInformerFailure(
+	old v1alpha1.GenericControllerStatus, new v1alpha1.GenericControllerStatus,
+) bool {
+	isInformerFailing := func(status v1alpha1.GenericControllerStatus) bool {
+		for _, cond := range status.Conditions {
+			if cond.Type == v1alpha1.GenericControllerConditionDegraded &&
+				cond.Status == v1alpha1.GenericControllerConditionTrue &&
+				cond.Reason == statusReasonInformerFailing {
+				return true
+			}
+		}
+		return false
+	}
+	return isInformerFailing(new) && !isInformerFailing(old)
+}
+
+// getDegradedMessage returns the message of the Degraded
+// condition of the given status
+func getDegradedMessage(status v1alpha1.GenericControllerStatus) string {
+	for _, cond := range status.Conditions {
+		if cond.Type == v1alpha1.GenericControllerConditionDegraded {
+			return cond.Message
+		}
+	}
+	return ""
+}
@@ -42,6 +42,14 @@ func AddToInlineRegistry(funcName string, fn InlineInvokeFn) {
 	inlineHookRegistryInstance.invokeFuncs[funcName] = fn
 }
 
+// IsInlineHookRegistered returns true if a function is registered
+// against the given name in the inline hook registry
+func IsInlineHookRegistered(funcName string) bool {
+	inlineHookRegistryInstance.Lock()
+	defer inlineHookRegistryInstance.Unlock()
+	return inlineHookRegistryInstance.invokeFuncs[funcName] != nil
+}
+
 // InlineHookInvoker manages invocation of inline hook
 type InlineHookInvoker struct {
 	FuncName string
@@ -0,0 +1,142 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+	"openebs.io/metac/logging"
+)
+
+// LogLevelAnnotationKey is the annotation of a GenericController that
+// sets the log level of this controller independently of the -v flag
+// e.g. "4". A value of "default" reverts to the -v flag.
+//
+// NOTE:
+//	The log level can also be set at runtime via the debug endpoint.
+// The most recent of the annotation's change & the endpoint's request
+// takes effect.
+const LogLevelAnnotationKey = "metac.openebs.io/log-level"
+
+// MetaControllerLogKey is the controller key of the entries logged by
+// the loops that start & stop the GenericControllers. Its log level can
+// be set at runtime via the debug endpoint like that of a controller.
+const MetaControllerLogKey = "metacontroller:GenericController"
+
+// logForMeta returns the logger of the loops of the given meta
+// controller
+func logForMeta(mc fmt.Stringer) logging.Logger {
+	return logging.ForController(MetaControllerLogKey).WithValues("metacontroller", mc)
+}
+
+// logForConfig returns the logger of the meta controller entries that
+// refer to the GenericController of the given key. These entries are
+// logged at the verbosity that is set for this GenericController.
+func logForConfig(mc fmt.Stringer, key string) logging.Logger {
+	return logging.ForController(key).WithValues("metacontroller", mc)
+}
+
+// validateLogLevel returns error if the log level annotation of the
+// given controller is invalid
+func validateLogLevel(config *v1alpha1.GenericController) error {
+	_, _, err := logging.ParseLevel(config.GetAnnotations()[LogLevelAnnotationKey])
+	if err != nil {
+		return errors.Wrapf(err, "Invalid annotation %s", LogLevelAnnotationKey)
+	}
+	return nil
+}
+
+// newControllerLogger returns the logger of the given controller that
+// sets the controller's key & the watch's group, version & resource
+// against every entry
+func newControllerLogger(config *v1alpha1.GenericController) logging.Logger {
+	return logging.ForController(config.Key()).WithValues(
+		logging.KeyGVR, config.Spec.Watch.APIVersion+"/"+config.Spec.Watch.Resource,
+	)
+}
+
+// forWatch returns the logger of this controller that additionally
+// sets the given watch key against every entry
+func (mgr *watchController) forWatch(key string) logging.Logger {
+	return mgr.log.WithValues(logging.KeyWatch, key)
+}
+
+// forWatchObj returns the logger of this controller that additionally
+// sets the key of the given watch against every entry
+func (mgr *watchController) forWatchObj(watch *unstructured.Unstructured) logging.Logger {
+	key, _ := makeWatchQueueKey(watch)
+	return mgr.forWatch(key)
+}
+
+// forRequest returns the logger of this controller that additionally
+// sets the given watch key & the request ID of the reconcile held by
+// the given context if any against every entry
+func (mgr *watchController) forRequest(ctx context.Context, key string) logging.Logger {
+	log := mgr.forWatch(key)
+	if id := requestIDFrom(ctx); id != "" {
+		log = log.WithValues(logging.KeyRequestID, id)
+	}
+	return log
+}
+
+// forRequestObj returns the logger of this controller that
+// additionally sets the key of the given watch & the request ID of
+// the reconcile held by the given context if any against every entry
+func (mgr *watchController) forRequestObj(
+	ctx context.Context, watch *unstructured.Unstructured,
+) logging.Logger {
+	key, _ := makeWatchQueueKey(watch)
+	return mgr.forRequest(ctx, key)
+}
+
+// syncLogLevel sets the log level of this controller as per the log
+// level annotation of the given config if this annotation changed
+// since it was last synced
+//
+// NOTE:
+//	Log level that is set via the debug endpoint is left as is till
+// the annotation changes. Invalid annotations are ignored since the
+// config is validated before the controller is created.
+func (mgr *watchController) syncLogLevel(config *v1alpha1.GenericController) {
+	value, found := config.GetAnnotations()[LogLevelAnnotationKey]
+	if !found && mgr.logLevelAnnotation == nil {
+		return
+	}
+	if mgr.logLevelAnnotation != nil && *mgr.logLevelAnnotation == value {
+		return
+	}
+	mgr.logLevelAnnotation = &value
+	level, isSet, err := logging.ParseLevel(value)
+	if err != nil {
+		mgr.log.Warning("Ignored log level annotation", "err", err)
+		return
+	}
+	verbosity := logging.GetVerbosity(config.Key())
+	if isSet {
+		verbosity.Set(level)
+		mgr.log.Info("Set log level", "level", int(level))
+		return
+	}
+	verbosity.Unset()
+	mgr.log.Info("Reverted log level to default")
+}
+
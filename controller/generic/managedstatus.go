@@ -0,0 +1,93 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"reflect"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"openebs.io/metac/controller/common"
+	dynamicclientset "openebs.io/metac/dynamic/clientset"
+	k8s "openebs.io/metac/third_party/kubernetes"
+)
+
+// retainManagedStatus sets the status fields that are managed by this
+// controller from the given current status against the given desired
+// status. This ensures these fields are not lost if sync hook returns
+// a status without them.
+func (mgr *watchController) retainManagedStatus(
+	current map[string]interface{}, desired map[string]interface{},
+) map[string]interface{} {
+	desired = mgr.retainObservedGeneration(current, desired)
+	return mgr.retainStatusRollups(current, desired)
+}
+
+// setManagedStatus sets the status fields that are managed by this
+// controller against the given watch. It returns true if the watch
+// got changed.
+func (mgr *watchController) setManagedStatus(
+	watch *unstructured.Unstructured, generation int64, rollups []statusRollupResult,
+) bool {
+	changed := mgr.setObservedGeneration(watch, generation)
+	if len(rollups) == 0 {
+		return changed
+	}
+	status := k8s.GetNestedObject(watch.Object, "status")
+	desired := setStatusRollups(status, rollups)
+	if reflect.DeepEqual(status, desired) {
+		return changed
+	}
+	k8s.SetNestedField(watch.Object, desired, "status")
+	return true
+}
+
+// syncManagedStatus updates the status fields of the given watch that
+// are managed by this controller i.e. observed generation & status
+// rollups. This is a no-op if these fields are already up to date or
+// if the watch is being deleted.
+func (mgr *watchController) syncManagedStatus(
+	watchClient *dynamicclientset.ResourceClient,
+	watch *unstructured.Unstructured,
+	rollups []statusRollupResult,
+) error {
+	if watch.GetDeletionTimestamp() != nil {
+		return nil
+	}
+	if !mgr.setManagedStatus(watch.DeepCopy(), watch.GetGeneration(), rollups) {
+		// nothing to do
+		return nil
+	}
+
+	mgr.forWatchObj(watch).V(4).Info("Updating managed status of watch")
+
+	_, err := watchClient.Namespace(watch.GetNamespace()).AtomicStatusUpdate(
+		watch,
+		func(obj *unstructured.Unstructured) bool {
+			return mgr.setManagedStatus(obj, watch.GetGeneration(), rollups)
+		},
+	)
+	if err != nil {
+		return errors.Wrapf(
+			err,
+			"%s: Failed to update managed status of watch %s",
+			mgr, common.DescObjectAsKey(watch),
+		)
+	}
+	return nil
+}
@@ -17,19 +17,30 @@ limitations under the License.
 package generic
 
 import (
+	"os"
+	"os/signal"
+	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/golang/glog"
 	"github.com/pkg/errors"
 
-	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 
 	"openebs.io/metac/apis/metacontroller/v1alpha1"
+	metaclientset "openebs.io/metac/client/generated/clientset/versioned"
 	metainformers "openebs.io/metac/client/generated/informers/externalversions"
 	metalisters "openebs.io/metac/client/generated/listers/metacontroller/v1alpha1"
 	"openebs.io/metac/config"
@@ -37,9 +48,20 @@ import (
 	dynamicclientset "openebs.io/metac/dynamic/clientset"
 	dynamicdiscovery "openebs.io/metac/dynamic/discovery"
 	dynamicinformer "openebs.io/metac/dynamic/informer"
+	"openebs.io/metac/logging"
+	"openebs.io/metac/shard"
 	k8s "openebs.io/metac/third_party/kubernetes"
 )
 
+// wildcardResyncInterval is the interval at which watch controllers
+// with wildcard attachments are verified for newly discovered or
+// removed resources
+var wildcardResyncInterval = 30 * time.Second
+
+// pendingStartInterval is the interval at which the controllers whose
+// watch resources were not discovered are tried to be started again
+var pendingStartInterval = 30 * time.Second
+
 // MetaController abstracts Kubernetes informers and listers
 // to execute reconcile logic declared in various GenericController
 // resources.
@@ -51,26 +73,193 @@ type MetaController struct {
 	WatchControllers map[string]*watchController
 	WorkerCount      int
 
+	// EventRecorder is used by watch controllers to raise events
+	// against their watch resources
+	//
+	// NOTE:
+	//	This is optional. Events are not raised if this is nil.
+	EventRecorder record.EventRecorder
+
+	// ApplyStrategy is the strategy used by watch controllers to apply
+	// their attachments unless these controllers set their own
+	//
+	// NOTE:
+	//	This is optional. Defaults to LastApplied.
+	ApplyStrategy v1alpha1.ApplyStrategy
+
+	// ClientLimits are the limits of the requests of every watch
+	// controller unless these controllers set their own
+	//
+	// NOTE:
+	//	This is optional. Requests of the watch controllers share the
+	// limit of the clientset if this is not set.
+	ClientLimits ClientLimits
+
+	// TargetClusterFn returns the cluster where the attachments of
+	// the watch controllers that set a target cluster are managed
+	//
+	// NOTE:
+	//	This is optional. Watch controllers that set a target cluster
+	// fail to start if this is nil.
+	TargetClusterFn TargetClusterFn
+
+	// ReconcileHistorySize is the number of most recent reconciles
+	// that are retained per watch controller
+	//
+	// NOTE:
+	//	This is optional. Reconciles are not retained if this is not
+	// set.
+	ReconcileHistorySize int
+
+	// HotLoop tunes the detection of the watches that are synced &
+	// mutated continuously by the watch controllers
+	//
+	// NOTE:
+	//	This is optional. Defaults are used for the settings that are
+	// not set.
+	HotLoop HotLoopSettings
+
 	doneCh chan struct{}
 }
 
+// warnReferenceCycle logs a warning if the given controller forms a
+// cycle with the running watch controllers i.e. the attachments of
+// these controllers trigger each other's watches
+func (mc *MetaController) warnReferenceCycle(config *v1alpha1.GenericController) {
+	var others []*v1alpha1.GenericController
+	for _, wc := range mc.WatchControllers {
+		others = append(others, wc.GCtlConfig)
+	}
+	cycle := findReferenceCycle(mc.ResourceManager, config, others)
+	if len(cycle) == 0 {
+		return
+	}
+	logging.ForController(config.Key()).Warning(
+		"Attachments may trigger watches in a cycle: This may result in runaway creation of resources",
+		"cycle", strings.Join(append(cycle, config.Key()), " -> "),
+	)
+}
+
 // ConfigBasedMetaController represents a MetaController that
 // is based on configs of type GenericController provided to
 // this binary
 type ConfigBasedMetaController struct {
 	MetaController
 
-	// Path from which metac configs will be loaded
+	// ConfigSource lists & watches the metac configs. Binaries that
+	// embed metac can set their own source e.g. a database.
+	//
+	// NOTE:
+	//	Configs are reloaded whenever this source finds a change. This
+	// has higher priority than config maps, ConfigURL,
+	// ConfigGitRepository & ConfigPath.
+	ConfigSource config.ConfigSource
+
+	// Path from which metac configs will be loaded. This may be a
+	// directory, a file or a glob pattern. Directories are walked
+	// recursively.
+	//
+	// NOTE:
+	//	Configs are reloaded whenever the files at this path change.
+	// Watch controllers are started, stopped or restarted to match the
+	// reloaded configs.
 	ConfigPath string
 
+	// Glob patterns of the files at ConfigPath that are loaded. All
+	// the files are loaded if this is empty.
+	ConfigPathInclude []string
+
+	// Glob patterns of the files at ConfigPath that are not loaded
+	ConfigPathExclude []string
+
+	// Label selector of the config maps from which metac configs
+	// will be loaded
+	//
+	// NOTE:
+	//	Configs are reloaded whenever these config maps are added,
+	// changed or deleted. Config maps have higher priority than
+	// ConfigURL, ConfigGitRepository & ConfigPath.
+	ConfigMapSelector string
+
+	// Namespace of the config maps from which metac configs will be
+	// loaded
+	ConfigMapNamespace string
+
+	// Clientset used to list & watch the config maps from which metac
+	// configs will be loaded
+	KubeClientset kubernetes.Interface
+
+	// HTTP(S) endpoint from which metac configs will be fetched
+	//
+	// NOTE:
+	//	This endpoint is polled via conditional requests. Configs are
+	// reloaded whenever the endpoint serves a changed bundle. Config
+	// url has higher priority than ConfigGitRepository & ConfigPath.
+	ConfigURL string
+
+	// Interval at which ConfigURL is polled
+	ConfigURLPollInterval time.Duration
+
+	// Git repository from which metac configs will be fetched
+	//
+	// NOTE:
+	//	A ref of this repository is pulled on an interval. Configs are
+	// reloaded from a sub path of this repository whenever the ref
+	// moves. Git repository has higher priority than ConfigPath.
+	ConfigGitRepository string
+
+	// Interval at which the ref of ConfigGitRepository is pulled
+	ConfigGitPollInterval time.Duration
+
+	// Renderer renders the configs loaded from config maps, ConfigURL,
+	// ConfigGitRepository or ConfigPath as templates against a set of
+	// values
+	//
+	// NOTE:
+	//	This is optional. Configs are not rendered if this is nil.
+	ConfigRenderer *config.Renderer
+
+	// Substituter substitutes the variables referred in the configs
+	// loaded from config maps, ConfigURL, ConfigGitRepository or
+	// ConfigPath
+	//
+	// NOTE:
+	//	This is optional. Variables are not substituted if this is nil.
+	ConfigSubstituter *config.Substituter
+
+	// ConfigDuplicatePolicy decides what happens to the loaded configs
+	// that have the same kind, namespace & name
+	//
+	// NOTE:
+	//	This is optional. Defaults to Fail i.e. configs are not loaded
+	// if duplicates are found.
+	ConfigDuplicatePolicy config.DuplicatePolicy
+
+	// Profiles filters the configs based on their profiles. Configs
+	// of the profiles that are not enabled are not run.
+	//
+	// NOTE:
+	//	Configs of the default profile are run unless this profile is
+	// disabled
+	Profiles config.Profiles
+
 	// Function that fetches all generic controller instances
 	// required to run Metac
 	//
 	// NOTE:
-	//	One can use either ConfigPath or this function. ConfigPath
-	// option has higher priority.
+	//	One can use either config maps, ConfigPath or this function.
+	// This function has the lowest priority.
 	GenericControllerAsConfigFn func() ([]*v1alpha1.GenericController, error)
 
+	// ConfigSyncers run the controllers of kinds other than
+	// GenericController e.g. CompositeController & DecoratorController
+	// found in the loaded configs
+	//
+	// NOTE:
+	//	This is optional. Configs of other kinds are ignored if this
+	// is empty.
+	ConfigSyncers []ConfigSyncer
+
 	// Config instances of type GenericController required to run
 	// generic meta controllers. In other words these are the
 	// configurations to manage (start, stop) specific watch
@@ -90,6 +279,52 @@ type ConfigBasedMetaController struct {
 	// 	This is currently used to load config that is required
 	// to run Metac
 	WaitIntervalForCondition time.Duration
+
+	// To stop verifying the watch controllers with wildcard
+	// attachments
+	stopCh chan struct{}
+
+	// lists & watches the configs of config maps
+	configMaps *config.ConfigMaps
+
+	// fetches configs from ConfigURL
+	configRemote *config.Remote
+
+	// fetches configs from ConfigGitRepository
+	configGit *config.Git
+
+	// source of the configs; this is nil if the configs are loaded
+	// via GenericControllerAsConfigFn
+	configSource config.ConfigSource
+
+	// all the loaded configs that are synced with ConfigSyncers
+	metacConfigs config.MetacConfigs
+
+	// outcome of the latest attempt to load the configs
+	loadTime    time.Time
+	loadErr     error
+	loadErrTime time.Time
+
+	// errors of the watch controllers that could not be started
+	// keyed by their configs
+	startErrs map[string]error
+
+	// errors of the latest sync of ConfigSyncers
+	syncErrs []error
+
+	// mutex guards the watch controllers & their configs that are
+	// mutated by reloads, pending starts & wildcard restarts
+	mutex sync.Mutex
+
+	// reloadMutex serializes the reloads triggered by config changes
+	// & by SIGHUP; this avoids older configs getting synced after the
+	// newer ones
+	reloadMutex sync.Mutex
+
+	// workers tracks the goroutines started by Start that watch the
+	// configs & start the pending controllers; these are joined by
+	// Stop
+	workers sync.WaitGroup
 }
 
 // ConfigBasedMetaControllerOption is a functional option to
@@ -116,6 +351,144 @@ func SetMetaControllerConfigPath(path string) ConfigBasedMetaControllerOption {
 	}
 }
 
+// SetMetaControllerConfigPathPatterns sets the glob patterns of the
+// files at config path that are included or excluded against the
+// ConfigBasedMetaController instance
+func SetMetaControllerConfigPathPatterns(include, exclude []string) ConfigBasedMetaControllerOption {
+	return func(c *ConfigBasedMetaController) error {
+		err := config.ValidatePatterns(include)
+		if err != nil {
+			return err
+		}
+		err = config.ValidatePatterns(exclude)
+		if err != nil {
+			return err
+		}
+		c.ConfigPathInclude = include
+		c.ConfigPathExclude = exclude
+		return nil
+	}
+}
+
+// SetMetaControllerConfigSubstituter sets the substituter of the
+// variables referred in the configs against the ConfigBasedMetaController
+// instance
+func SetMetaControllerConfigSubstituter(substituter *config.Substituter) ConfigBasedMetaControllerOption {
+	return func(c *ConfigBasedMetaController) error {
+		c.ConfigSubstituter = substituter
+		return nil
+	}
+}
+
+// SetMetaControllerConfigRenderer sets the renderer of the configs
+// against the ConfigBasedMetaController instance
+func SetMetaControllerConfigRenderer(renderer *config.Renderer) ConfigBasedMetaControllerOption {
+	return func(c *ConfigBasedMetaController) error {
+		c.ConfigRenderer = renderer
+		return nil
+	}
+}
+
+// SetMetaControllerConfigDuplicatePolicy sets the policy of the
+// duplicate configs against the ConfigBasedMetaController instance
+func SetMetaControllerConfigDuplicatePolicy(policy config.DuplicatePolicy) ConfigBasedMetaControllerOption {
+	return func(c *ConfigBasedMetaController) error {
+		err := config.ValidateDuplicatePolicy(policy)
+		if err != nil {
+			return err
+		}
+		c.ConfigDuplicatePolicy = policy
+		return nil
+	}
+}
+
+// SetMetaControllerProfiles sets the enabled & disabled profiles of
+// the configs against the ConfigBasedMetaController instance
+func SetMetaControllerProfiles(enabled, disabled []string) ConfigBasedMetaControllerOption {
+	return func(c *ConfigBasedMetaController) error {
+		c.Profiles = config.Profiles{
+			Enabled:  enabled,
+			Disabled: disabled,
+		}
+		return nil
+	}
+}
+
+// SetMetaControllerEventRecorder sets the event recorder against
+// the ConfigBasedMetaController instance
+func SetMetaControllerEventRecorder(recorder record.EventRecorder) ConfigBasedMetaControllerOption {
+	return func(c *ConfigBasedMetaController) error {
+		c.EventRecorder = recorder
+		return nil
+	}
+}
+
+// SetMetaControllerApplyStrategy sets the default apply strategy of
+// the watch controllers against the ConfigBasedMetaController instance
+func SetMetaControllerApplyStrategy(strategy v1alpha1.ApplyStrategy) ConfigBasedMetaControllerOption {
+	return func(c *ConfigBasedMetaController) error {
+		err := ValidateApplyStrategy(strategy)
+		if err != nil {
+			return err
+		}
+		c.ApplyStrategy = strategy
+		return nil
+	}
+}
+
+// SetMetaControllerClientLimits sets the default limits of the
+// requests of every watch controller against the
+// ConfigBasedMetaController instance
+func SetMetaControllerClientLimits(limits ClientLimits) ConfigBasedMetaControllerOption {
+	return func(c *ConfigBasedMetaController) error {
+		if limits.QPS < 0 || limits.Burst < 0 || limits.Timeout < 0 {
+			return errors.Errorf(
+				"Invalid client limits: QPS %g, burst %d & timeout %v can't be negative",
+				limits.QPS, limits.Burst, limits.Timeout,
+			)
+		}
+		c.ClientLimits = limits
+		return nil
+	}
+}
+
+// SetMetaControllerReconcileHistorySize sets the number of most
+// recent reconciles that are retained per watch controller against
+// the ConfigBasedMetaController instance
+func SetMetaControllerReconcileHistorySize(size int) ConfigBasedMetaControllerOption {
+	return func(c *ConfigBasedMetaController) error {
+		if size < 0 {
+			return errors.Errorf("Invalid reconcile history size %d: Can't be negative", size)
+		}
+		c.ReconcileHistorySize = size
+		return nil
+	}
+}
+
+// SetMetaControllerHotLoop sets the settings that tune the detection
+// of the watches that are synced & mutated continuously against the
+// ConfigBasedMetaController instance
+func SetMetaControllerHotLoop(settings HotLoopSettings) ConfigBasedMetaControllerOption {
+	return func(c *ConfigBasedMetaController) error {
+		err := settings.validate()
+		if err != nil {
+			return err
+		}
+		c.HotLoop = settings
+		return nil
+	}
+}
+
+// SetMetaControllerTargetClusterFn sets the function that returns the
+// target clusters of the watch controllers against the
+// ConfigBasedMetaController instance
+func SetMetaControllerTargetClusterFn(fn TargetClusterFn) ConfigBasedMetaControllerOption {
+	return func(c *ConfigBasedMetaController) error {
+		c.TargetClusterFn = fn
+		return nil
+	}
+}
+
 // NewConfigBasedMetaController returns a new instance of
 // ConfigBasedMetaController
 func NewConfigBasedMetaController(
@@ -139,39 +512,36 @@ func NewConfigBasedMetaController(
 		}
 	}
 
-	if obj.ConfigPath == "" && obj.GenericControllerAsConfigFn == nil {
+	obj.configSource = obj.newConfigSource()
+	if obj.configSource == nil && obj.GenericControllerAsConfigFn == nil {
 		return nil,
 			errors.Errorf(
-				"New config metacontroller failed: Both ConfigPath & GenericControllerAsConfig can't be empty",
+				"New config metacontroller failed: ConfigSource, ConfigMapSelector, ConfigURL, ConfigGitRepository, ConfigPath & GenericControllerAsConfig can't be empty",
 			)
 	}
 
-	var gctlsAsConfig []*v1alpha1.GenericController
-	var gctlsAsConfigErr error
-	// NOTE: ConfigPath has higher priority to get the
-	// GenericController instances as configs to run Metac
-	if obj.ConfigPath != "" {
-		mconfigs, err := config.New(obj.ConfigPath).Load()
-		if err != nil {
-			return nil, err
-		}
-		gctlsAsConfig, gctlsAsConfigErr = mconfigs.ListGenericControllers()
-
-	} else {
-		gctlsAsConfig, gctlsAsConfigErr = obj.GenericControllerAsConfigFn()
-	}
-
+	gctlsAsConfig, mconfigs, gctlsAsConfigErr := obj.loadConfigs()
 	if gctlsAsConfigErr != nil {
 		return nil, gctlsAsConfigErr
 	}
 
 	obj.GenericControllerConfigs = gctlsAsConfig
+	obj.metacConfigs = mconfigs
+	obj.loadTime = time.Now()
+	obj.startErrs = make(map[string]error)
 	obj.MetaController = MetaController{
 		ResourceManager:    resourceMgr,
 		DynClientset:       dynClientset,
 		DynInformerFactory: dynInformerFactory,
 		WorkerCount:        workerCount,
 		WatchControllers:   make(map[string]*watchController),
+		EventRecorder:      obj.EventRecorder,
+		ApplyStrategy:      obj.ApplyStrategy,
+		ClientLimits:       obj.ClientLimits,
+		TargetClusterFn:    obj.TargetClusterFn,
+
+		ReconcileHistorySize: obj.ReconcileHistorySize,
+		HotLoop:              obj.HotLoop,
 	}
 
 	return obj, nil
@@ -184,13 +554,22 @@ func (mc *ConfigBasedMetaController) String() string {
 // Start generic meta controller by starting watch controllers
 // corresponding to the provided config
 func (mc *ConfigBasedMetaController) Start() {
+	mc.stopCh = make(chan struct{})
 	mc.doneCh = make(chan struct{})
 
+	// SIGHUP is handled from the start since it terminates this
+	// process otherwise; a SIGHUP received while the configs are
+	// loaded for the first time triggers a reload once these are
+	// loaded
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGHUP)
+
 	go func() {
 		defer close(mc.doneCh)
 		defer utilruntime.HandleCrash()
+		defer signal.Stop(sigCh)
 
-		glog.Infof("Starting %s", mc)
+		logForMeta(mc).Info("Starting")
 
 		// we run this as a continuous process
 		// until all the configs are loaded
@@ -198,9 +577,94 @@ func (mc *ConfigBasedMetaController) Start() {
 		if condErr != nil {
 			glog.Fatalf("%s: Failed to start: %v", mc, condErr)
 		}
+
+		// start the controllers of other kinds e.g. CompositeController
+		mc.syncConfigSyncers(mc.getMetacConfigs())
+
+		// start the controllers whose watch resources got discovered
+		// after the above start
+		mc.goWorker(func() {
+			wait.Until(mc.startPendingWatchControllers, pendingStartInterval, mc.stopCh)
+		})
+
+		// sync the controllers with the configs whenever these
+		// configs change
+		if mc.configSource != nil {
+			mc.goWorker(mc.watchConfigSource)
+		}
+
+		// sync the controllers with the configs on demand as well
+		mc.goWorker(func() { mc.watchReloadSignal(sigCh) })
+
+		// recreate watch controllers whose wildcard attachments
+		// expand to a different set of resources
+		wait.Until(mc.restartStaleWatchControllers, wildcardResyncInterval, mc.stopCh)
+	}()
+}
+
+// goWorker runs the given function in a goroutine that is joined
+// when this controller is stopped
+func (mc *ConfigBasedMetaController) goWorker(fn func()) {
+	mc.workers.Add(1)
+	go func() {
+		defer mc.workers.Done()
+		defer utilruntime.HandleCrash()
+		fn()
 	}()
 }
 
+// restartStaleWatchControllers recreates the watch controllers whose
+// wildcard attachments are no longer in sync with discovered resources
+func (mc *ConfigBasedMetaController) restartStaleWatchControllers() {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	for key, wc := range mc.WatchControllers {
+		if !wc.isWildcardExpansionStale() {
+			continue
+		}
+		logForConfig(mc, key).Info("Will restart controller: Wildcard attachments changed")
+		newWC, err := mc.newWatchController(wc.declaredConfig)
+		if err != nil {
+			// keep the current controller running
+			utilruntime.HandleError(
+				errors.Wrapf(err, "%s: Failed to restart %s", mc, key),
+			)
+			continue
+		}
+		wc.Stop()
+		newWC.Start(mc.WorkerCount)
+		mc.WatchControllers[key] = newWC
+	}
+}
+
+// newWatchController returns a new watch controller of the given
+// config that is not started yet
+func (mc *ConfigBasedMetaController) newWatchController(
+	conf *v1alpha1.GenericController,
+) (*watchController, error) {
+	targetCluster, err := mc.getTargetCluster(conf)
+	if err != nil {
+		return nil, err
+	}
+	wc, err := newWatchController(
+		mc.ResourceManager,
+		mc.DynClientset,
+		mc.DynInformerFactory,
+		targetCluster,
+		mc.EventRecorder,
+		mc.ApplyStrategy,
+		mc.ClientLimits,
+		conf,
+	)
+	if err != nil {
+		return nil, err
+	}
+	wc.history = newReconcileHistory(mc.ReconcileHistorySize)
+	wc.hotLoopDetector = mc.HotLoop.newDetector()
+	return wc, nil
+}
+
 // wait polls the condition until it's true, with a configured
 // interval and timeout.
 //
@@ -224,26 +688,53 @@ func (mc *ConfigBasedMetaController) wait(condition func() (bool, error)) error
 		}
 		if err != nil {
 			// Log error, but keep trying until timeout.
-			glog.V(4).Infof("%s: Wait condition failed: Will retry: %v", mc, err)
+			logForMeta(mc).V(4).Info("Wait condition failed: Will retry", "err", err)
 		} else {
-			glog.V(4).Infof("%s: Waiting for condition to succeed: Will retry", mc)
+			logForMeta(mc).V(4).Info("Waiting for condition to succeed: Will retry")
 		}
 		time.Sleep(mc.WaitIntervalForCondition)
 	}
 }
 
+// startPendingWatchControllers starts the watch controllers that
+// could not be started earlier since their watch resources were not
+// discovered
+//
+// NOTE:
+//	Config syncers are synced again as well to start their pending
+// controllers
+func (mc *ConfigBasedMetaController) startPendingWatchControllers() {
+	_, err := mc.startAllWatchControllers()
+	if err != nil {
+		utilruntime.HandleError(
+			errors.Wrapf(err, "%s: Failed to start pending controllers", mc),
+		)
+	}
+	mc.syncConfigSyncers(mc.getMetacConfigs())
+}
+
 // startAllWatchControllers starts all the watch controllers
 // that are specified as config for this binary
+//
+// NOTE:
+//	Controllers whose watch resources are not discovered yet are
+// skipped without failing this condition. These are started later
+// once their watch resources get discovered.
 func (mc *ConfigBasedMetaController) startAllWatchControllers() (bool, error) {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
 	// In this metacontroller, we are only responsible for
 	// starting/stopping the relevant watch based controllers
+	var errs []error
 	for _, conf := range mc.GenericControllerConfigs {
 		key := conf.Key()
 		if _, ok := mc.WatchControllers[key]; ok {
 			// NOTE:
-			//	One needs to be careful not to use duplicate
-			// GenericController configs. Duplicate here implies
-			// more than one configs having same namespace & name.
+			//	Duplicate GenericController configs i.e. the ones
+			// having same namespace & name are resolved while
+			// loading these configs. A later config overrides the
+			// earlier ones.
 
 			// Already added
 			continue
@@ -251,31 +742,54 @@ func (mc *ConfigBasedMetaController) startAllWatchControllers() (bool, error) {
 
 		// watch controller i.e. a controller based on the resource
 		// specified in the watch field of GenericController
-		wc, err := newWatchController(
-			mc.ResourceManager,
-			mc.DynClientset,
-			mc.DynInformerFactory,
-			conf,
-		)
+		wc, err := mc.newWatchController(conf)
+		if isWatchNotDiscovered(err) {
+			logForConfig(mc, key).V(3).Info("Pending start of controller", "err", err)
+			mc.startErrs[key] = err
+			continue
+		}
 		if err != nil {
-			return false, errors.Wrapf(err, "%s: Failed to sync key %s", mc, key)
+			// other controllers are started regardless
+			err = errors.Wrapf(err, "%s: Failed to sync key %s", mc, key)
+			mc.startErrs[key] = err
+			errs = append(errs, err)
+			continue
 		}
 
 		// start this watch controller
 		wc.Start(mc.WorkerCount)
 		mc.WatchControllers[key] = wc
+		delete(mc.startErrs, key)
+		mc.warnReferenceCycle(wc.GCtlConfig)
+	}
+	if len(errs) != 0 {
+		return false, utilerrors.NewAggregate(errs)
 	}
 	return true, nil
 }
 
 // Stop stops this MetaController
 func (mc *ConfigBasedMetaController) Stop() {
-	glog.Infof("Shutting down %s", mc)
+	logForMeta(mc).Info("Shutting down")
 
 	// Stop metacontroller first so there's no more changes
 	// to watch controllers.
+	close(mc.stopCh)
 	<-mc.doneCh
 
+	// Wait for the config watches & pending starts to return; this
+	// avoids a reload in progress mutating the controllers that are
+	// getting stopped
+	mc.workers.Wait()
+
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+
+	// Stop the controllers of other kinds
+	for _, syncer := range mc.ConfigSyncers {
+		syncer.Stop()
+	}
+
 	// Stop all its watch controllers
 	var wg sync.WaitGroup
 	for _, wCtl := range mc.WatchControllers {
@@ -304,8 +818,18 @@ type CRDBasedMetaController struct {
 	// To enqueue & dequeue GenericController CR events
 	Queue workqueue.RateLimitingInterface
 
+	// To update the status of GenericController CRs
+	//
+	// NOTE:
+	//	Status is not updated if this is nil
+	MetaClientset metaclientset.Interface
+
 	// To stop watching GenericController CR events
 	stopCh chan struct{}
+
+	// guards the watch controllers that are read while these are
+	// synced
+	mutex sync.Mutex
 }
 
 // NewCRDBasedMetaController returns a new instance of
@@ -356,13 +880,17 @@ func (mc *CRDBasedMetaController) Start() {
 		defer close(mc.doneCh)
 		defer utilruntime.HandleCrash()
 
-		glog.Infof("Starting %s", mc)
-		defer glog.Infof("Shutting down %s", mc)
+		logForMeta(mc).Info("Starting")
+		defer logForMeta(mc).Info("Shutting down")
 
 		if !k8s.WaitForCacheSync(mc.String(), mc.stopCh, mc.Informer.HasSynced) {
 			return
 		}
 
+		// Periodically enqueue the controllers with wildcard attachments
+		// to pick up newly discovered or removed resources.
+		go wait.Until(mc.enqueueWildcardGenericControllers, wildcardResyncInterval, mc.stopCh)
+
 		// In the metacontroller, we are only responsible for starting/stopping
 		// the watched resources i.e. controllers, so a single worker should be
 		// enough.
@@ -380,6 +908,8 @@ func (mc *CRDBasedMetaController) Stop() {
 	<-mc.doneCh
 
 	// Stop all its watched resources i.e. controllers
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
 	var wg sync.WaitGroup
 	for _, c := range mc.WatchControllers {
 		wg.Add(1)
@@ -419,17 +949,24 @@ func (mc *CRDBasedMetaController) sync(key string) error {
 		return err
 	}
 
-	glog.V(4).Infof("%s: Try sync-ing key %s", mc, key)
+	logForConfig(mc, key).V(4).Info("Try sync-ing controller")
+
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
 
 	ctrl, err := mc.Lister.GenericControllers(ns).Get(name)
 	if apierrors.IsNotFound(err) {
-		glog.V(3).Infof(
-			"%s: Sync key %s ignored: No longer exist: Will stop controller: %v",
-			mc, key, err,
+		logForConfig(mc, key).V(3).Info(
+			"Sync ignored: Controller no longer exists: Will stop controller", "err", err,
 		)
 
 		// cleanup this GenericController instance if exists
 		if c, ok := mc.WatchControllers[key]; ok {
+			// orphan the attachments if desired before stopping
+			err := c.ReleaseAll()
+			if err != nil {
+				return errors.Wrapf(err, "%s: Failed to release attachments of %s", mc, key)
+			}
 			c.Stop()
 			delete(mc.WatchControllers, key)
 		}
@@ -439,7 +976,91 @@ func (mc *CRDBasedMetaController) sync(key string) error {
 		return err
 	}
 
-	return mc.syncGenericController(ctrl)
+	syncErr := mc.syncGenericController(ctrl)
+	if isWatchNotDiscovered(syncErr) {
+		// try again later instead of failing this sync since
+		// the watch resource e.g. CRD may get installed later
+		logForConfig(mc, key).V(3).Info("Pending start of controller", "err", syncErr)
+		mc.Queue.AddAfter(key, pendingStartInterval)
+	} else if syncErr != nil {
+		mc.recordEvent(
+			ctrl, corev1.EventTypeWarning, EventReasonConfigRejected, "Can't start: %v", syncErr,
+		)
+	}
+	if mc.MetaClientset != nil {
+		// status is updated by a single replica if the reconciles
+		// are sharded
+		//
+		// NOTE:
+		//	Sync failures in this status are then limited to the
+		// watches reconciled by this replica
+		if shard.IsOwner(key) {
+			err = mc.updateStatus(ctrl, syncErr)
+			if err != nil {
+				logForConfig(mc, key).Warning("Failed to update status of controller", "err", err)
+			}
+		}
+		// status reflects the watch controller's state that changes
+		// independently of GenericController CR events
+		mc.Queue.AddAfter(key, statusResyncInterval)
+	}
+	if isWatchNotDiscovered(syncErr) {
+		return nil
+	}
+	return syncErr
+}
+
+// updateStatus updates the status of the given GenericController based
+// on its watch controller & the given sync error
+func (mc *CRDBasedMetaController) updateStatus(
+	ctrl *v1alpha1.GenericController, syncErr error,
+) error {
+	status := buildGenericControllerStatus(
+		mc.ResourceManager, ctrl, mc.WatchControllers[ctrl.Key()], syncErr,
+	)
+	status = mergeGenericControllerStatus(ctrl.Status, status, metav1.Now())
+	if !isGenericControllerStatusChanged(ctrl.Status, status) {
+		return nil
+	}
+	if mc.EventRecorder != nil {
+		for _, res := range listNewDeprecations(ctrl.Status, status) {
+			mc.EventRecorder.Eventf(
+				ctrl,
+				corev1.EventTypeWarning,
+				EventReasonDeprecatedAPI,
+				"Resource %q of %q is deprecated: %s",
+				res.Resource, res.APIVersion, res.Deprecation,
+			)
+		}
+		if isNewInformerFailure(ctrl.Status, status) {
+			mc.EventRecorder.Eventf(
+				ctrl,
+				corev1.EventTypeWarning,
+				EventReasonInformerFailing,
+				"Informers fail to list or watch: Cache may be stale: %s",
+				getDegradedMessage(status),
+			)
+		}
+	}
+	copy := ctrl.DeepCopy()
+	copy.Status = status
+	_, err := mc.MetaClientset.MetacontrollerV1alpha1().
+		GenericControllers(ctrl.Namespace).
+		UpdateStatus(copy)
+	return err
+}
+
+// recordEvent raises an event against the given GenericController.
+// This is a no-op if event recorder is not set.
+func (mc *CRDBasedMetaController) recordEvent(
+	ctrl *v1alpha1.GenericController,
+	eventType, reason, messageFmt string,
+	args ...interface{},
+) {
+	if mc.EventRecorder == nil {
+		return
+	}
+	mc.EventRecorder.Eventf(ctrl, eventType, reason, messageFmt, args...)
 }
 
 // syncGenericController is all about starting individual
@@ -447,8 +1068,9 @@ func (mc *CRDBasedMetaController) sync(key string) error {
 func (mc *CRDBasedMetaController) syncGenericController(ctrl *v1alpha1.GenericController) error {
 	if c, ok := mc.WatchControllers[ctrl.Key()]; ok {
 		// The controller was already started.
-		if apiequality.Semantic.DeepEqual(ctrl.Spec, c.GCtlConfig.Spec) {
-			// Nothing has changed.
+		if !c.isSpecChanged(ctrl.Spec) && !c.isWildcardExpansionStale() {
+			// Nothing has changed except possibly the log level.
+			c.syncLogLevel(ctrl)
 			return nil
 		}
 
@@ -456,6 +1078,15 @@ func (mc *CRDBasedMetaController) syncGenericController(ctrl *v1alpha1.GenericCo
 		// stop & recreate.
 		c.Stop()
 		delete(mc.WatchControllers, ctrl.Key())
+		mc.recordEvent(
+			ctrl, corev1.EventTypeNormal, EventReasonStopped, "Stopped to apply the changed spec",
+		)
+	}
+
+	// cluster of the attachments if other than the watch's cluster
+	targetCluster, err := mc.getTargetCluster(ctrl)
+	if err != nil {
+		return err
 	}
 
 	// watched resource / controller
@@ -463,14 +1094,30 @@ func (mc *CRDBasedMetaController) syncGenericController(ctrl *v1alpha1.GenericCo
 		mc.ResourceManager,
 		mc.DynClientset,
 		mc.DynInformerFactory,
+		targetCluster,
+		mc.EventRecorder,
+		mc.ApplyStrategy,
+		mc.ClientLimits,
 		ctrl,
 	)
 	if err != nil {
 		return err
 	}
 
+	wc.isConfigEvents = true
+	wc.history = newReconcileHistory(mc.ReconcileHistorySize)
+	wc.hotLoopDetector = mc.HotLoop.newDetector()
 	wc.Start(mc.WorkerCount)
 	mc.WatchControllers[ctrl.Key()] = wc
+	mc.recordEvent(
+		ctrl,
+		corev1.EventTypeNormal,
+		EventReasonStarted,
+		"Started with %d workers: Watching %s",
+		mc.WorkerCount,
+		ctrl.Spec.Watch.APIVersion+"/"+ctrl.Spec.Watch.Resource,
+	)
+	mc.warnReferenceCycle(wc.GCtlConfig)
 	return nil
 }
 
@@ -486,6 +1133,23 @@ func (mc *CRDBasedMetaController) enqueueGenericController(obj interface{}) {
 	mc.Queue.Add(key)
 }
 
+// enqueueWildcardGenericControllers enqueues all the GenericControllers
+// that have wildcard attachments
+func (mc *CRDBasedMetaController) enqueueWildcardGenericControllers() {
+	ctrls, err := mc.Lister.List(labels.Everything())
+	if err != nil {
+		utilruntime.HandleError(
+			errors.Wrapf(err, "%s: Can't list controllers", mc),
+		)
+		return
+	}
+	for _, ctrl := range ctrls {
+		if hasWildcardAttachments(ctrl) {
+			mc.enqueueGenericController(ctrl)
+		}
+	}
+}
+
 func (mc *CRDBasedMetaController) updateGenericController(old, cur interface{}) {
 	mc.enqueueGenericController(cur)
 }
@@ -0,0 +1,175 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+	"openebs.io/metac/controller/common"
+	"openebs.io/metac/logging"
+)
+
+// isMetadataOnly returns true if only the metadata of the resources
+// of the given attachment should be cached
+func isMetadataOnly(attachment v1alpha1.GenericControllerAttachment) bool {
+	return attachment.MetadataOnly != nil && *attachment.MetadataOnly
+}
+
+// isReadOnlyAttachment returns true if the resources of the given
+// attachment are never created, updated or deleted by the given
+// controller
+//
+// NOTE:
+//	Resources of read only attachments are cached by read only
+// informers that may list & watch these resources as protobuf
+func isReadOnlyAttachment(
+	config *v1alpha1.GenericController, attachment v1alpha1.GenericControllerAttachment,
+) bool {
+	return isReadOnly(config) ||
+		(attachment.ReadOnly != nil && *attachment.ReadOnly)
+}
+
+// isHookInvoked returns true if a sync or finalize hook is invoked
+// when the given watch is reconciled
+func (mgr *watchController) isHookInvoked(watch *unstructured.Unstructured) bool {
+	hooks := mgr.GCtlConfig.Spec.Hooks
+	if hooks == nil {
+		return false
+	}
+	return mgr.isFinalizing(watch) || hooks.Sync != nil
+}
+
+// fetchedAttachments caches the attachments that were fetched entirely
+// from the API server since their informers cache their metadata only.
+// These are anchored by their UIDs.
+type fetchedAttachments struct {
+	mutex sync.Mutex
+	objs  map[types.UID]*unstructured.Unstructured
+}
+
+// newFetchedAttachments returns a new instance of fetchedAttachments
+func newFetchedAttachments() *fetchedAttachments {
+	return &fetchedAttachments{
+		objs: make(map[types.UID]*unstructured.Unstructured),
+	}
+}
+
+// get returns a copy of the fetched attachment of the given UID if it
+// is of the given resource version. It returns nil otherwise.
+func (f *fetchedAttachments) get(
+	uid types.UID, resourceVersion string,
+) *unstructured.Unstructured {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	obj := f.objs[uid]
+	if obj == nil || obj.GetResourceVersion() != resourceVersion {
+		return nil
+	}
+	return obj.DeepCopy()
+}
+
+// set caches a copy of the given fetched attachment
+func (f *fetchedAttachments) set(obj *unstructured.Unstructured) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.objs[obj.GetUID()] = obj.DeepCopy()
+}
+
+// forget removes the fetched attachment of the given UID
+func (f *fetchedAttachments) forget(uid types.UID) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	delete(f.objs, uid)
+}
+
+// onDelete removes the fetched attachment that was deleted. This is
+// meant to be invoked by the informers of metadata only attachments.
+func (f *fetchedAttachments) onDelete(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	if o, ok := obj.(metav1.Object); ok {
+		f.forget(o.GetUID())
+	}
+}
+
+// fetchMetadataOnlyAttachments replaces the observed attachments that
+// are cached as metadata only with their entire resources fetched from
+// the API server. Attachments that no longer exist are removed from the
+// given registry.
+//
+// NOTE:
+//	Attachments are fetched only if their resource versions changed
+// since they were last fetched. Their informers cache their metadata
+// only & hence have their latest resource versions.
+func (mgr *watchController) fetchMetadataOnlyAttachments(
+	observed common.AnyUnstructRegistry,
+) error {
+	if len(mgr.metadataOnlyKinds) == 0 {
+		return nil
+	}
+	for _, group := range observed {
+		for name, obj := range group {
+			apiGroup, _ := common.ParseAPIVersionToGroupVersion(obj.GetAPIVersion())
+			if !mgr.metadataOnlyKinds[makeSelectorKeyFromGK(apiGroup, obj.GetKind())] {
+				continue
+			}
+			fetched := mgr.fetchedAttachments.get(obj.GetUID(), obj.GetResourceVersion())
+			if fetched != nil {
+				group[name] = fetched
+				continue
+			}
+			client, err := mgr.attachmentClientset.GetClientByKind(
+				obj.GetAPIVersion(), obj.GetKind(),
+			)
+			if err != nil {
+				return err
+			}
+			fullObj, err := client.Namespace(obj.GetNamespace()).Get(
+				obj.GetName(), metav1.GetOptions{},
+			)
+			if apierrors.IsNotFound(err) {
+				mgr.log.V(4).Info(
+					"Ignore attachment: Not found",
+					logging.KeyAttachment, common.DescObjectAsKey(obj),
+				)
+				mgr.fetchedAttachments.forget(obj.GetUID())
+				delete(group, name)
+				continue
+			}
+			if err != nil {
+				return errors.Wrapf(
+					err, "%s: Can't get attachment %s", mgr, common.DescObjectAsKey(obj),
+				)
+			}
+			mgr.fetchedAttachments.set(fullObj)
+			group[name] = fullObj
+		}
+	}
+	return nil
+}
@@ -0,0 +1,236 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sync"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+	"openebs.io/metac/controller/common"
+	dynamicclientset "openebs.io/metac/dynamic/clientset"
+	"openebs.io/metac/logging"
+)
+
+// newConfigMapMetadata returns the metadata of the configmap as cached
+// by a metadata only informer
+func newConfigMapMetadata(name, resourceVersion string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind("ConfigMap")
+	obj.SetNamespace("default")
+	obj.SetName(name)
+	obj.SetUID(types.UID(name + "-uid"))
+	obj.SetResourceVersion(resourceVersion)
+	return obj
+}
+
+// configMapServer serves the configmaps of the default namespace &
+// counts the requests to get these
+type configMapServer struct {
+	mutex sync.Mutex
+	// resource versions of the configmaps anchored by their names
+	resourceVersions map[string]string
+	gets             map[string]int
+}
+
+func (s *configMapServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	name := path.Base(r.URL.Path)
+	s.gets[name]++
+	w.Header().Set("Content-Type", "application/json")
+	rv, found := s.resourceVersions[name]
+	if !found {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprintf(
+			w,
+			`{"kind": "Status", "apiVersion": "v1", "status": "Failure", "reason": "NotFound", "code": 404}`,
+		)
+		return
+	}
+	fmt.Fprintf(w, `{
+		"apiVersion": "v1",
+		"kind": "ConfigMap",
+		"metadata": {"name": %q, "namespace": "default", "uid": %q, "resourceVersion": %q},
+		"data": {"rv": %q}
+	}`, name, name+"-uid", rv, rv)
+}
+
+func (s *configMapServer) getCount(name string) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.gets[name]
+}
+
+func TestWatchControllerFetchMetadataOnlyAttachments(t *testing.T) {
+	server := &configMapServer{
+		resourceVersions: map[string]string{"cm-1": "1", "cm-2": "1"},
+		gets:             make(map[string]int),
+	}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	resourceMgr, stop := newFakeResourceManager(t, newWildcardTestResources()...)
+	defer stop()
+	cs, err := dynamicclientset.New(&rest.Config{Host: httpServer.URL}, resourceMgr)
+	if err != nil {
+		t.Fatalf("Can't create clientset: %v", err)
+	}
+	mgr := &watchController{
+		GCtlConfig:          &v1alpha1.GenericController{},
+		attachmentClientset: cs,
+		log:                 logging.ForController("test"),
+		metadataOnlyKinds: map[string]bool{
+			makeSelectorKeyFromGK("", "ConfigMap"): true,
+		},
+		fetchedAttachments: newFetchedAttachments(),
+	}
+	// fetch returns the resource versions of the fetched attachments
+	// anchored by their names
+	fetch := func(observed ...*unstructured.Unstructured) map[string]string {
+		registry := common.AnyUnstructRegistry{"v1:ConfigMap": {}}
+		for _, obj := range observed {
+			registry["v1:ConfigMap"][obj.GetName()] = obj
+		}
+		err := mgr.fetchMetadataOnlyAttachments(registry)
+		if err != nil {
+			t.Fatalf("Expected no error got %v", err)
+		}
+		got := make(map[string]string)
+		for name, obj := range registry["v1:ConfigMap"] {
+			rv, _, _ := unstructured.NestedString(obj.Object, "data", "rv")
+			got[name] = rv
+		}
+		return got
+	}
+	expectGets := func(name string, count int) {
+		if got := server.getCount(name); got != count {
+			t.Fatalf("Expected %d get(s) of %s got %d", count, name, got)
+		}
+	}
+
+	// attachments are fetched once
+	got := fetch(newConfigMapMetadata("cm-1", "1"), newConfigMapMetadata("cm-2", "1"))
+	if got["cm-1"] != "1" || got["cm-2"] != "1" {
+		t.Fatalf("Expected fetched attachments got %v", got)
+	}
+	fetch(newConfigMapMetadata("cm-1", "1"), newConfigMapMetadata("cm-2", "1"))
+	expectGets("cm-1", 1)
+	expectGets("cm-2", 1)
+
+	// changed attachments are fetched again
+	server.mutex.Lock()
+	server.resourceVersions["cm-1"] = "2"
+	server.mutex.Unlock()
+	got = fetch(newConfigMapMetadata("cm-1", "2"), newConfigMapMetadata("cm-2", "1"))
+	if got["cm-1"] != "2" {
+		t.Fatalf("Expected changed cm-1 to be fetched got %v", got)
+	}
+	expectGets("cm-1", 2)
+	expectGets("cm-2", 1)
+
+	// deleted attachments are removed & forgotten
+	server.mutex.Lock()
+	delete(server.resourceVersions, "cm-2")
+	server.mutex.Unlock()
+	got = fetch(newConfigMapMetadata("cm-1", "2"), newConfigMapMetadata("cm-2", "3"))
+	if _, found := got["cm-2"]; found || len(got) != 1 {
+		t.Fatalf("Expected cm-2 to be removed got %v", got)
+	}
+	if mgr.fetchedAttachments.get("cm-2-uid", "1") != nil {
+		t.Fatalf("Expected cm-2 to be forgotten")
+	}
+	expectGets("cm-1", 2)
+	expectGets("cm-2", 2)
+}
+
+func TestFetchedAttachments(t *testing.T) {
+	var tests = map[string]struct {
+		deleted         interface{}
+		uid             types.UID
+		resourceVersion string
+		isFound         bool
+	}{
+		"same resource version": {
+			uid:             "cm-1-uid",
+			resourceVersion: "1",
+			isFound:         true,
+		},
+		"other resource version": {
+			uid:             "cm-1-uid",
+			resourceVersion: "2",
+		},
+		"other uid": {
+			uid:             "cm-2-uid",
+			resourceVersion: "1",
+		},
+		"deleted": {
+			deleted:         newConfigMapMetadata("cm-1", "1"),
+			uid:             "cm-1-uid",
+			resourceVersion: "1",
+		},
+		"deleted via tombstone": {
+			deleted: cache.DeletedFinalStateUnknown{
+				Key: "default/cm-1",
+				Obj: newConfigMapMetadata("cm-1", "1"),
+			},
+			uid:             "cm-1-uid",
+			resourceVersion: "1",
+		},
+		"other deleted": {
+			deleted:         newConfigMapMetadata("cm-2", "1"),
+			uid:             "cm-1-uid",
+			resourceVersion: "1",
+			isFound:         true,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			fetched := newFetchedAttachments()
+			fetched.set(newConfigMapMetadata("cm-1", "1"))
+			if mock.deleted != nil {
+				fetched.onDelete(mock.deleted)
+			}
+			got := fetched.get(mock.uid, mock.resourceVersion)
+			if isFound := got != nil; isFound != mock.isFound {
+				t.Fatalf("Expected found %t got %t", mock.isFound, isFound)
+			}
+			if got == nil {
+				return
+			}
+			// copies are returned to avoid changing the cache
+			got.SetName("changed")
+			if fetched.get(mock.uid, mock.resourceVersion).GetName() != "cm-1" {
+				t.Fatalf("Expected cached attachment to be unchanged")
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+	k8s "openebs.io/metac/third_party/kubernetes"
+)
+
+// getObservedGenerationFields returns the fields of the watch's
+// status that should be set with the watch's generation. Nil is
+// returned if observed generation should not be set.
+func getObservedGenerationFields(config *v1alpha1.GenericController) []string {
+	if config.Spec.ObservedGenerationPath == nil {
+		return nil
+	}
+	return strings.Split(*config.Spec.ObservedGenerationPath, ".")
+}
+
+// validateObservedGenerationPath returns error if the observed
+// generation path of the given controller is invalid
+func validateObservedGenerationPath(config *v1alpha1.GenericController) error {
+	for _, field := range getObservedGenerationFields(config) {
+		if field == "" {
+			return errors.Errorf(
+				"Invalid observed generation path %q",
+				*config.Spec.ObservedGenerationPath,
+			)
+		}
+	}
+	return nil
+}
+
+// retainObservedGeneration sets the observed generation of the given
+// current status against the given desired status. This ensures the
+// observed generation is not lost if sync hook returns a status
+// without it.
+func (mgr *watchController) retainObservedGeneration(
+	current map[string]interface{}, desired map[string]interface{},
+) map[string]interface{} {
+	fields := getObservedGenerationFields(mgr.GCtlConfig)
+	if len(fields) == 0 {
+		return desired
+	}
+	observed := k8s.GetNestedField(current, fields...)
+	if observed == nil {
+		return desired
+	}
+	if desired == nil {
+		desired = make(map[string]interface{})
+	}
+	k8s.SetNestedField(desired, observed, fields...)
+	return desired
+}
+
+// setObservedGeneration sets the given generation at the observed
+// generation path of the given watch's status. It returns true if the
+// watch got changed.
+func (mgr *watchController) setObservedGeneration(
+	watch *unstructured.Unstructured, generation int64,
+) bool {
+	fields := getObservedGenerationFields(mgr.GCtlConfig)
+	if len(fields) == 0 || watch.GetGeneration() != generation {
+		// newer generation will be synced later
+		return false
+	}
+	statusFields := append([]string{"status"}, fields...)
+	observed := k8s.GetNestedInt64Pointer(watch.Object, statusFields...)
+	if observed != nil && *observed == generation {
+		return false
+	}
+	k8s.SetNestedField(watch.Object, generation, statusFields...)
+	return true
+}
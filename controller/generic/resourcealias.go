@@ -0,0 +1,159 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"github.com/pkg/errors"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+	dynamicdiscovery "openebs.io/metac/dynamic/discovery"
+)
+
+// resolveWatchAlias returns the resource name of the watch of the
+// given controller. Watch's resource may be a short name, a singular
+// name or a category that has exactly one resource. Watch's resource
+// is returned as is if it is not discovered.
+func resolveWatchAlias(
+	resourceMgr *dynamicdiscovery.APIResourceManager,
+	config *v1alpha1.GenericController,
+) (string, error) {
+	watch := config.Spec.Watch
+	resource, err := resourceMgr.GetByAlias(watch.APIVersion, watch.Resource)
+	if err != nil {
+		return "", errors.Wrapf(err, "Invalid watch")
+	}
+	if resource != nil {
+		return resource.Name, nil
+	}
+	members := resourceMgr.ListByCategory(watch.APIVersion, watch.Resource)
+	switch len(members) {
+	case 0:
+		return watch.Resource, nil
+	case 1:
+		return members[0].Name, nil
+	default:
+		return "", errors.Errorf(
+			"Invalid watch: Category %q of %q has %d resources: Watch needs exactly one",
+			watch.Resource, watch.APIVersion, len(members),
+		)
+	}
+}
+
+// resolveAttachmentAlias returns the attachments that the given
+// attachment refers to. Attachment's resource may be a short name, a
+// singular name or a category e.g. all that expands to each of its
+// resources. Attachment is returned as is if it is a wildcard or if it
+// is not discovered.
+//
+// NOTE:
+//	Returned bool is true if the attachment refers to a category
+func resolveAttachmentAlias(
+	resourceMgr *dynamicdiscovery.APIResourceManager,
+	attachment v1alpha1.GenericControllerAttachment,
+) ([]v1alpha1.GenericControllerAttachment, bool, error) {
+	if isWildcardAttachment(attachment) {
+		return []v1alpha1.GenericControllerAttachment{attachment}, false, nil
+	}
+	resource, err := resourceMgr.GetByAlias(attachment.APIVersion, attachment.Resource)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "Invalid attachment")
+	}
+	if resource != nil {
+		resolved := *attachment.DeepCopy()
+		resolved.Resource = resource.Name
+		return []v1alpha1.GenericControllerAttachment{resolved}, false, nil
+	}
+	members := resourceMgr.ListByCategory(attachment.APIVersion, attachment.Resource)
+	if len(members) == 0 {
+		return []v1alpha1.GenericControllerAttachment{attachment}, false, nil
+	}
+	var resolved []v1alpha1.GenericControllerAttachment
+	for _, member := range members {
+		if !isListWatchable(member) {
+			continue
+		}
+		concrete := *attachment.DeepCopy()
+		concrete.Resource = member.Name
+		resolved = append(resolved, concrete)
+	}
+	return resolved, true, nil
+}
+
+// withResolvedAliases returns a copy of the given controller with the
+// short names, singular names & categories of its watch & attachments
+// resolved to resource names similar to kubectl. The given controller
+// is returned as is if it refers to resource names only.
+//
+// NOTE:
+//	Categories are resolved to the resources that are currently
+// discovered. Explicit attachments have higher priority than the
+// categories for the same resource. A resource that belongs to more
+// than one category is attached once. Watch & attachments are resolved
+// against the resource managers of their respective clusters.
+func withResolvedAliases(
+	watchResourceMgr *dynamicdiscovery.APIResourceManager,
+	attachmentResourceMgr *dynamicdiscovery.APIResourceManager,
+	config *v1alpha1.GenericController,
+) (*v1alpha1.GenericController, error) {
+	watchResource, err := resolveWatchAlias(watchResourceMgr, config)
+	if err != nil {
+		return nil, err
+	}
+	isResolved := watchResource != config.Spec.Watch.Resource
+
+	var explicit, categories [][]v1alpha1.GenericControllerAttachment
+	// apiVersion & resource of the explicit attachments
+	seen := make(map[string]bool)
+	for _, attachment := range config.Spec.Attachments {
+		resolved, isCategory, err := resolveAttachmentAlias(attachmentResourceMgr, attachment)
+		if err != nil {
+			return nil, err
+		}
+		if isCategory {
+			isResolved = true
+			explicit = append(explicit, nil)
+			categories = append(categories, resolved)
+			continue
+		}
+		if resolved[0].Resource != attachment.Resource {
+			isResolved = true
+		}
+		seen[resolved[0].APIVersion+"/"+resolved[0].Resource] = true
+		explicit = append(explicit, resolved)
+		categories = append(categories, nil)
+	}
+	if !isResolved {
+		return config, nil
+	}
+	// attachments are kept in their declared order
+	var attachments []v1alpha1.GenericControllerAttachment
+	for idx := range config.Spec.Attachments {
+		attachments = append(attachments, explicit[idx]...)
+		for _, member := range categories[idx] {
+			key := member.APIVersion + "/" + member.Resource
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			attachments = append(attachments, member)
+		}
+	}
+	resolvedConfig := config.DeepCopy()
+	resolvedConfig.Spec.Watch.Resource = watchResource
+	resolvedConfig.Spec.Attachments = attachments
+	return resolvedConfig, nil
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"openebs.io/metac/controller/common"
+	"openebs.io/metac/logging"
+)
+
+// String implements Stringer interface
+func (s AttachmentScale) String() string {
+	if s.Namespace == "" {
+		return s.APIVersion + ":" + s.Kind + ":" + s.Name
+	}
+	return s.APIVersion + ":" + s.Kind + ":" + s.Namespace + ":" + s.Name
+}
+
+// applyScales sets the desired replicas of the given attachment scales
+// via the scale subresource of these attachments
+//
+// NOTE:
+//	Only the observed attachments of the given watch are scaled.
+// Attachments that are not observed yet e.g. the ones that got created
+// in this sync are scaled in a later sync.
+func (mgr *watchController) applyScales(
+	ctx context.Context,
+	watch *unstructured.Unstructured,
+	observed common.AnyUnstructRegistry,
+	scales []AttachmentScale,
+	ruleMgr *attachmentRuleManager,
+) error {
+	var errs []error
+	for _, scale := range scales {
+		err := mgr.applyScale(ctx, watch, observed, scale, ruleMgr)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "%s: Can't scale %s", mgr, scale))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// applyScale sets the desired replicas of the given attachment scale
+func (mgr *watchController) applyScale(
+	ctx context.Context,
+	watch *unstructured.Unstructured,
+	observed common.AnyUnstructRegistry,
+	scale AttachmentScale,
+	ruleMgr *attachmentRuleManager,
+) error {
+	if scale.APIVersion == "" || scale.Kind == "" || scale.Name == "" {
+		return errors.Errorf("APIVersion, kind & name are required")
+	}
+	if scale.Replicas < 0 {
+		return errors.Errorf("Invalid replicas %d", scale.Replicas)
+	}
+	client, err := mgr.attachmentClientset.ForWatch(common.DescObjectAsKey(watch)).
+		GetClientByKind(scale.APIVersion, scale.Kind)
+	if err != nil {
+		return err
+	}
+	if !client.HasScale() {
+		return errors.Errorf("Scale subresource is not served")
+	}
+	if ruleMgr.IsReadOnlyByGK(client.Group, client.Kind) ||
+		ruleMgr.IsCreateOnlyByGK(client.Group, client.Kind) {
+		return errors.Errorf("Attachments can't be updated")
+	}
+	namespace := scale.Namespace
+	if !client.Namespaced {
+		namespace = ""
+	} else if namespace == "" {
+		namespace = watch.GetNamespace()
+	}
+
+	log := mgr.forRequestObj(ctx, watch).WithValues(
+		logging.KeyAttachment, scale,
+	)
+	obj := findObservedAttachment(observed, client.Group, client.Kind, namespace, scale.Name)
+	if obj == nil {
+		log.V(4).Info("Won't scale: Attachment is not observed")
+		return nil
+	}
+	if obj.GetDeletionTimestamp() != nil {
+		log.V(4).Info("Won't scale: Attachment is pending deletion")
+		return nil
+	}
+	if common.IsUpdateProtected(obj) {
+		log.V(4).Info("Won't scale: Attachment is protected")
+		return nil
+	}
+	isUpdateAny := mgr.GCtlConfig.Spec.UpdateAny != nil && *mgr.GCtlConfig.Spec.UpdateAny
+	if !common.IsCreatedByWatch(obj, watch) && !isUpdateAny {
+		log.V(4).Info("Won't scale: Attachment is not created by watch: UpdateAny is not set")
+		return nil
+	}
+
+	if mgr.isDryRun() {
+		client = client.DryRun()
+	}
+	client = client.Namespace(namespace)
+	current, err := client.GetScale(scale.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if current.Spec.Replicas == scale.Replicas {
+		log.V(4).Info("Won't scale: Has desired replicas", "replicas", scale.Replicas)
+		return nil
+	}
+	from := current.Spec.Replicas
+	current.Spec.Replicas = scale.Replicas
+	_, err = client.
+		WithAuditDiff(fmt.Sprintf("Replace spec.replicas from %d to %d", from, scale.Replicas)).
+		UpdateScale(current, metav1.UpdateOptions{})
+	if err != nil {
+		return err
+	}
+	log.Info("Scaled attachment", "from", from, "to", scale.Replicas)
+	reconcileRecordFrom(ctx).addWriteAction(
+		"Scaled %s from %d to %d replicas", scale, from, scale.Replicas,
+	)
+	return nil
+}
+
+// findObservedAttachment returns the observed attachment with the given
+// api group, kind, namespace & name. Nil is returned if no such
+// attachment is observed.
+func findObservedAttachment(
+	observed common.AnyUnstructRegistry, apiGroup, kind, namespace, name string,
+) *unstructured.Unstructured {
+	for _, obj := range observed.List() {
+		if obj == nil || obj.GetKind() != kind ||
+			obj.GetNamespace() != namespace || obj.GetName() != name {
+			continue
+		}
+		group, _ := common.ParseAPIVersionToGroupVersion(obj.GetAPIVersion())
+		if group == apiGroup {
+			return obj
+		}
+	}
+	return nil
+}
@@ -18,14 +18,18 @@ package generic
 
 import (
 	"fmt"
+	"regexp"
 
+	"github.com/golang/glog"
 	"github.com/pkg/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 
 	"openebs.io/metac/apis/metacontroller/v1alpha1"
 	"openebs.io/metac/controller/common"
+	"openebs.io/metac/controller/common/selector"
 	dynamicdiscovery "openebs.io/metac/dynamic/discovery"
 )
 
@@ -35,8 +39,10 @@ import (
 // TODO (@amitkumardas) Check if this can be a common package
 type Selector struct {
 	nameSelectors       NameSelectorsByGK
+	namePatterns        NamePatternsByGK
 	labelSelectors      LabelSelectorsByGK
 	annotationSelectors AnnotationSelectorsByGK
+	resourceSelectors   ResourceSelectorsByGK
 }
 
 // NameSelectorsByGK acts as the registrar of NameSelectors anchored by
@@ -55,6 +61,22 @@ func (m NameSelectorsByGK) Get(group, kind string) v1alpha1.NameSelector {
 	return m[makeSelectorKeyFromGK(group, kind)]
 }
 
+// NamePatternsByGK acts as the registrar of compiled name patterns
+// anchored by api group and kind
+type NamePatternsByGK map[string][]*regexp.Regexp
+
+// Set registers the given name patterns based on the given group
+// and kind
+func (m NamePatternsByGK) Set(group, kind string, patterns []*regexp.Regexp) {
+	m[makeSelectorKeyFromGK(group, kind)] = patterns
+}
+
+// Get returns the name patterns from the registrar based on the
+// given group and kind
+func (m NamePatternsByGK) Get(group, kind string) []*regexp.Regexp {
+	return m[makeSelectorKeyFromGK(group, kind)]
+}
+
 // LabelSelectorsByGK acts as the registrar of LabelSelectors anchored by
 // api group and kind
 type LabelSelectorsByGK map[string]labels.Selector
@@ -87,6 +109,95 @@ func (m AnnotationSelectorsByGK) Get(group, kind string) labels.Selector {
 	return m[makeSelectorKeyFromGK(group, kind)]
 }
 
+// ResourceSelectorsByGK acts as the registrar of selector terms
+// anchored by api group and kind
+type ResourceSelectorsByGK map[string][]*v1alpha1.SelectorTerm
+
+// Set registers the given selector terms based on the given group
+// and kind
+func (m ResourceSelectorsByGK) Set(group, kind string, terms []*v1alpha1.SelectorTerm) {
+	m[makeSelectorKeyFromGK(group, kind)] = terms
+}
+
+// Get returns the selector terms from the registrar based on the
+// given group and kind
+func (m ResourceSelectorsByGK) Get(group, kind string) []*v1alpha1.SelectorTerm {
+	return m[makeSelectorKeyFromGK(group, kind)]
+}
+
+// validateSelectorTerms verifies the label & annotation expressions
+// of the given selector terms
+func validateSelectorTerms(terms []*v1alpha1.SelectorTerm) error {
+	for idx, term := range terms {
+		if term == nil {
+			continue
+		}
+		_, err := metav1.LabelSelectorAsSelector(&metav1.LabelSelector{
+			MatchLabels:      term.MatchLabels,
+			MatchExpressions: term.MatchLabelExpressions,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "Invalid label expressions at term %d", idx)
+		}
+		_, err = metav1.LabelSelectorAsSelector(&metav1.LabelSelector{
+			MatchLabels:      term.MatchAnnotations,
+			MatchExpressions: term.MatchAnnotationExpressions,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "Invalid annotation expressions at term %d", idx)
+		}
+	}
+	return nil
+}
+
+// validateResourceSelectors returns error if the selectors of the
+// watch or of any attachment of the given controller can't be
+// converted to the form used to match the resources
+func validateResourceSelectors(config *v1alpha1.GenericController) error {
+	resources := []v1alpha1.GenericControllerResource{config.Spec.Watch}
+	for _, attachment := range config.Spec.Attachments {
+		resources = append(resources, attachment.GenericControllerResource)
+	}
+	var errs []error
+	for _, resource := range resources {
+		err := validateResourceSelector(resource)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(
+				err, "Invalid selector of %s/%s", resource.APIVersion, resource.Resource,
+			))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// validateResourceSelector returns error if the label, annotation,
+// name or resource selector of the given resource is invalid
+func validateResourceSelector(resource v1alpha1.GenericControllerResource) error {
+	if resource.LabelSelector != nil {
+		_, err := metav1.LabelSelectorAsSelector(resource.LabelSelector)
+		if err != nil {
+			return errors.Wrapf(err, "Invalid label selector")
+		}
+	}
+	if resource.AnnotationSelector != nil {
+		_, err := metav1.LabelSelectorAsSelector(&metav1.LabelSelector{
+			MatchLabels:      resource.AnnotationSelector.MatchAnnotations,
+			MatchExpressions: resource.AnnotationSelector.MatchExpressions,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "Invalid annotation selector")
+		}
+	}
+	_, err := resource.NameSelector.Patterns()
+	if err != nil {
+		return errors.Wrapf(err, "Invalid name selector")
+	}
+	if resource.ResourceSelector != nil {
+		return validateSelectorTerms(resource.ResourceSelector.SelectorTerms)
+	}
+	return nil
+}
+
 // SelectorOption is a typed function used to build
 // an instance of selector
 //
@@ -157,6 +268,32 @@ func FromGCtlResourceSelectRequirements(
 		}
 		s.nameSelectors.Set(gctlResObj.Group, gctlResObj.Kind, nameSel)
 
+		// Compile the name patterns if any
+		namePatterns, err := nameSel.Patterns()
+		if err != nil {
+			return errors.Wrapf(
+				err, "Name selector for %s/%s failed",
+				gctlResource.APIVersion, gctlResource.Resource,
+			)
+		}
+		s.namePatterns.Set(gctlResObj.Group, gctlResObj.Kind, namePatterns)
+
+		// Set selector terms if any
+		//
+		// NOTE:
+		//	No terms evaluates to true for any resource
+		if gctlResource.ResourceSelector != nil {
+			terms := gctlResource.ResourceSelector.SelectorTerms
+			err = validateSelectorTerms(terms)
+			if err != nil {
+				return errors.Wrapf(
+					err, "Resource selector for %s/%s failed",
+					gctlResource.APIVersion, gctlResource.Resource,
+				)
+			}
+			s.resourceSelectors.Set(gctlResObj.Group, gctlResObj.Kind, terms)
+		}
+
 		return nil
 	}
 }
@@ -170,8 +307,10 @@ func NewSelector(options ...SelectorOption) (*Selector, error) {
 	// NOTE:
 	// 	Ensure that each option point to different resource kind
 	s.nameSelectors = NameSelectorsByGK(make(map[string]v1alpha1.NameSelector))
+	s.namePatterns = NamePatternsByGK(make(map[string][]*regexp.Regexp))
 	s.labelSelectors = LabelSelectorsByGK(make(map[string]labels.Selector))
 	s.annotationSelectors = AnnotationSelectorsByGK(make(map[string]labels.Selector))
+	s.resourceSelectors = ResourceSelectorsByGK(make(map[string][]*v1alpha1.SelectorTerm))
 
 	for _, o := range options {
 		err := o(s)
@@ -182,9 +321,39 @@ func NewSelector(options ...SelectorOption) (*Selector, error) {
 	return s, nil
 }
 
+// isNameMatch returns true if the name of the provided unstruct
+// instance is present in the given name selector or matches any of
+// its patterns
+func (s *Selector) isNameMatch(
+	nameSelector v1alpha1.NameSelector,
+	apiGroup string,
+	obj *unstructured.Unstructured,
+) bool {
+	if nameSelector.ContainsOrTrue(obj.GetName()) {
+		return true
+	}
+	for _, pattern := range s.namePatterns.Get(apiGroup, obj.GetKind()) {
+		if pattern.MatchString(obj.GetName()) {
+			return true
+		}
+	}
+	return false
+}
+
 // Matches returns true if the provided unstruct instance match this
 // selector settings
+//
+// NOTE:
+//	Resource selector terms that refer to the watch do not match
+// since watch is not provided. Use MatchesWithWatch instead.
 func (s *Selector) Matches(obj *unstructured.Unstructured) bool {
+	return s.MatchesWithWatch(obj, nil)
+}
+
+// MatchesWithWatch returns true if the provided unstruct instance
+// match this selector settings. The given watch is used to evaluate
+// the resource selector terms that refer to the watch.
+func (s *Selector) MatchesWithWatch(obj, watch *unstructured.Unstructured) bool {
 	// Look up the label and annotation selectors for this object.
 	// Use only Group and Kind. Ignore Version.
 	apiGroup, _ := common.ParseAPIVersionToGroupVersion(obj.GetAPIVersion())
@@ -194,9 +363,30 @@ func (s *Selector) Matches(obj *unstructured.Unstructured) bool {
 	annotationSelector := s.annotationSelectors.Get(apiGroup, obj.GetKind())
 
 	// It must match all selectors.
-	return labelSelector.Matches(labels.Set(obj.GetLabels())) &&
-		annotationSelector.Matches(labels.Set(obj.GetAnnotations())) &&
-		nameSelector.ContainsOrTrue(obj.GetName())
+	if !labelSelector.Matches(labels.Set(obj.GetLabels())) ||
+		!annotationSelector.Matches(labels.Set(obj.GetAnnotations())) ||
+		!s.isNameMatch(nameSelector, apiGroup, obj) {
+		return false
+	}
+
+	// evaluate the selector terms if any
+	terms := s.resourceSelectors.Get(apiGroup, obj.GetKind())
+	if len(terms) == 0 {
+		return true
+	}
+	eval := selector.Evaluation{
+		Target:    obj,
+		Terms:     terms,
+		Reference: watch,
+	}
+	isMatch, err := eval.RunMatch()
+	if err != nil {
+		glog.V(4).Infof(
+			"Selector failed for %s: Will not match: %v", common.DescObjectAsKey(obj), err,
+		)
+		return false
+	}
+	return isMatch
 }
 
 // makeSelectorKeyFromGK returns a formatted string suitable to be
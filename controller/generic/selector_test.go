@@ -0,0 +1,92 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+)
+
+func TestSelectorMatchesNamePatterns(t *testing.T) {
+	var tests = map[string]struct {
+		nameSelector  v1alpha1.NameSelector
+		labelSelector *metav1.LabelSelector
+		matches       []string
+		misses        []string
+	}{
+		"no name selector": {
+			matches: []string{"tls-cert", "my-cm"},
+		},
+		"exact names": {
+			nameSelector: v1alpha1.NameSelector{"tls-cert", "my-cm"},
+			matches:      []string{"tls-cert", "my-cm"},
+			misses:       []string{"tls-cert-1"},
+		},
+		"exact names & patterns": {
+			nameSelector: v1alpha1.NameSelector{"my-cm", "tls-*", "pod-?"},
+			matches:      []string{"my-cm", "tls-", "tls-cert", "pod-1"},
+			misses:       []string{"my-cm-1", "my-tls-cert", "pod-12"},
+		},
+		"patterns ANDed with labels": {
+			nameSelector: v1alpha1.NameSelector{"tls-*"},
+			labelSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": "other"},
+			},
+			misses: []string{"tls-cert"},
+		},
+	}
+	resourceMgr, stop := newFakeResourceManager(t, newWildcardTestResources()...)
+	defer stop()
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			resource := v1alpha1.GenericControllerResource{
+				NameSelector:  mock.nameSelector,
+				LabelSelector: mock.labelSelector,
+			}
+			resource.APIVersion = "v1"
+			resource.Resource = "configmaps"
+			s, err := NewSelector(FromGCtlResourceSelectRequirements(resourceMgr, resource))
+			if err != nil {
+				t.Fatalf("Expected no error got %v", err)
+			}
+			newConfigMap := func(name string) *unstructured.Unstructured {
+				obj := &unstructured.Unstructured{}
+				obj.SetAPIVersion("v1")
+				obj.SetKind("ConfigMap")
+				obj.SetName(name)
+				obj.SetLabels(map[string]string{"app": "metac"})
+				return obj
+			}
+			for _, name := range mock.matches {
+				if !s.Matches(newConfigMap(name)) {
+					t.Fatalf("Expected %q to match", name)
+				}
+			}
+			for _, name := range mock.misses {
+				if s.Matches(newConfigMap(name)) {
+					t.Fatalf("Expected %q to not match", name)
+				}
+			}
+		})
+	}
+}
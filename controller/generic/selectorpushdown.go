@@ -0,0 +1,68 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+	dynamicinformer "openebs.io/metac/dynamic/informer"
+)
+
+// isSelectorPushDown returns true if the selectors of the given
+// resource should be sent to the API server
+func isSelectorPushDown(resource v1alpha1.GenericControllerResource) bool {
+	return resource.SelectorPushDown != nil && *resource.SelectorPushDown
+}
+
+// makeListSelector returns the selector that is pushed down to the
+// API server by the informers of the given resource. An empty selector
+// is returned if selectors of this resource should not be pushed down.
+//
+// NOTE:
+//	Selectors pushed down here are evaluated by the controller as
+// well. Hence it is fine to push down a subset of these selectors.
+func makeListSelector(
+	resource v1alpha1.GenericControllerResource,
+) (dynamicinformer.ListSelector, error) {
+	var selector dynamicinformer.ListSelector
+	if !isSelectorPushDown(resource) {
+		return selector, nil
+	}
+	if resource.LabelSelector != nil {
+		lblSel, err := metav1.LabelSelectorAsSelector(resource.LabelSelector)
+		if err != nil {
+			return selector, errors.Wrapf(
+				err, "Label selector for %s/%s failed",
+				resource.APIVersion, resource.Resource,
+			)
+		}
+		if !lblSel.Empty() {
+			selector.LabelSelector = lblSel.String()
+		}
+	}
+	// field selectors can't OR multiple names nor match patterns
+	if len(resource.NameSelector) == 1 &&
+		!v1alpha1.IsNamePattern(resource.NameSelector[0]) {
+		selector.FieldSelector = fields.OneTermEqualSelector(
+			"metadata.name", resource.NameSelector[0],
+		).String()
+	}
+	return selector, nil
+}
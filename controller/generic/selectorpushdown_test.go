@@ -0,0 +1,111 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+	dynamicinformer "openebs.io/metac/dynamic/informer"
+)
+
+func TestMakeListSelector(t *testing.T) {
+	isPushDown := true
+	isNotPushDown := false
+	var tests = map[string]struct {
+		resource v1alpha1.GenericControllerResource
+		expect   dynamicinformer.ListSelector
+		isErr    bool
+	}{
+		"push down is not set": {
+			resource: v1alpha1.GenericControllerResource{
+				NameSelector: v1alpha1.NameSelector{"my-cm"},
+			},
+		},
+		"push down is disabled": {
+			resource: v1alpha1.GenericControllerResource{
+				NameSelector:     v1alpha1.NameSelector{"my-cm"},
+				SelectorPushDown: &isNotPushDown,
+			},
+		},
+		"single name": {
+			resource: v1alpha1.GenericControllerResource{
+				NameSelector:     v1alpha1.NameSelector{"my-cm"},
+				SelectorPushDown: &isPushDown,
+			},
+			expect: dynamicinformer.ListSelector{FieldSelector: "metadata.name=my-cm"},
+		},
+		"multiple names": {
+			resource: v1alpha1.GenericControllerResource{
+				NameSelector:     v1alpha1.NameSelector{"my-cm", "your-cm"},
+				SelectorPushDown: &isPushDown,
+			},
+		},
+		"star pattern": {
+			resource: v1alpha1.GenericControllerResource{
+				NameSelector:     v1alpha1.NameSelector{"tls-*"},
+				SelectorPushDown: &isPushDown,
+			},
+		},
+		"question pattern": {
+			resource: v1alpha1.GenericControllerResource{
+				NameSelector:     v1alpha1.NameSelector{"pod-?"},
+				SelectorPushDown: &isPushDown,
+			},
+		},
+		"pattern with labels": {
+			resource: v1alpha1.GenericControllerResource{
+				NameSelector: v1alpha1.NameSelector{"tls-*"},
+				LabelSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"app": "metac"},
+				},
+				SelectorPushDown: &isPushDown,
+			},
+			expect: dynamicinformer.ListSelector{LabelSelector: "app=metac"},
+		},
+		"invalid labels": {
+			resource: v1alpha1.GenericControllerResource{
+				LabelSelector: &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{{
+						Key:      "app",
+						Operator: "Invalid",
+					}},
+				},
+				SelectorPushDown: &isPushDown,
+			},
+			isErr: true,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			got, err := makeListSelector(mock.resource)
+			if mock.isErr && err == nil {
+				t.Fatalf("Expected error got none")
+			}
+			if !mock.isErr && err != nil {
+				t.Fatalf("Expected no error got %v", err)
+			}
+			if got != mock.expect {
+				t.Fatalf("Expected %+v got %+v", mock.expect, got)
+			}
+		})
+	}
+}
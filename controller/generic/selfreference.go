@@ -0,0 +1,181 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+	dynamicdiscovery "openebs.io/metac/dynamic/discovery"
+)
+
+// isNameSelectorDisjoint returns true if the given name selectors
+// can never select the same resource
+func isNameSelectorDisjoint(one, other v1alpha1.NameSelector) bool {
+	if len(one) == 0 || len(other) == 0 {
+		// empty selector selects all the names
+		return false
+	}
+	names := make(map[string]bool)
+	for _, name := range one {
+		if v1alpha1.IsNamePattern(name) {
+			return false
+		}
+		names[name] = true
+	}
+	for _, name := range other {
+		if v1alpha1.IsNamePattern(name) || names[name] {
+			return false
+		}
+	}
+	return true
+}
+
+// isLabelSelectorDisjoint returns true if the match labels of the
+// given resources can never select the same resource
+func isLabelSelectorDisjoint(one, other v1alpha1.GenericControllerResource) bool {
+	if one.LabelSelector == nil || other.LabelSelector == nil {
+		return false
+	}
+	for key, value := range one.LabelSelector.MatchLabels {
+		if otherValue, ok := other.LabelSelector.MatchLabels[key]; ok && otherValue != value {
+			return true
+		}
+	}
+	return false
+}
+
+// mayOverlap returns true if the given resources may select the same
+// resource. Resources of different api groups or kinds never overlap.
+func mayOverlap(
+	resourceMgr *dynamicdiscovery.APIResourceManager,
+	one, other v1alpha1.GenericControllerResource,
+) bool {
+	oneAPI := resourceMgr.GetByResource(one.APIVersion, one.Resource)
+	otherAPI := resourceMgr.GetByResource(other.APIVersion, other.Resource)
+	if oneAPI == nil || otherAPI == nil ||
+		oneAPI.Group != otherAPI.Group || oneAPI.Kind != otherAPI.Kind {
+		return false
+	}
+	return !isNameSelectorDisjoint(one.NameSelector, other.NameSelector) &&
+		!isLabelSelectorDisjoint(one, other)
+}
+
+// getSelfReferences returns the attachments of the given controller
+// that may match its watch
+//
+// NOTE:
+//	Read only attachments are never created or updated & hence can't
+// trigger this controller
+func getSelfReferences(
+	resourceMgr *dynamicdiscovery.APIResourceManager,
+	config *v1alpha1.GenericController,
+) []string {
+	var refs []string
+	for _, attachment := range config.Spec.Attachments {
+		if attachment.ReadOnly != nil && *attachment.ReadOnly {
+			continue
+		}
+		if mayOverlap(resourceMgr, config.Spec.Watch, attachment.GenericControllerResource) {
+			refs = append(refs, attachment.APIVersion+"/"+attachment.Resource)
+		}
+	}
+	return refs
+}
+
+// validateSelfReference returns error if any attachment of the given
+// controller may match its watch & self reference is not allowed
+func validateSelfReference(
+	resourceMgr *dynamicdiscovery.APIResourceManager,
+	config *v1alpha1.GenericController,
+) error {
+	if config.Spec.AllowSelfReference != nil && *config.Spec.AllowSelfReference {
+		return nil
+	}
+	refs := getSelfReferences(resourceMgr, config)
+	if len(refs) == 0 {
+		return nil
+	}
+	return errors.Errorf(
+		"Attachments [%s] may match the watch: Set allowSelfReference or use disjoint selectors",
+		strings.Join(refs, ", "),
+	)
+}
+
+// findReferenceCycle returns the controllers that form a cycle with
+// the given controller. A cycle is formed when the attachments of a
+// controller match the watch of another controller whose attachments
+// in turn match the watch of the former controller, directly or via
+// other controllers. Nil is returned if there is no cycle.
+//
+// NOTE:
+//	Direct self references are validated separately & are not
+// reported here. Others may include the given controller.
+func findReferenceCycle(
+	resourceMgr *dynamicdiscovery.APIResourceManager,
+	target *v1alpha1.GenericController,
+	others []*v1alpha1.GenericController,
+) []string {
+	// triggers returns true if an attachment of from may match the
+	// watch of to
+	triggers := func(from, to *v1alpha1.GenericController) bool {
+		if isRemoteAttachments(from) {
+			// attachments in a target cluster never match a watch
+			return false
+		}
+		for _, attachment := range from.Spec.Attachments {
+			if attachment.ReadOnly != nil && *attachment.ReadOnly {
+				continue
+			}
+			if mayOverlap(resourceMgr, attachment.GenericControllerResource, to.Spec.Watch) {
+				return true
+			}
+		}
+		return false
+	}
+
+	// depth first search from target back to target
+	visited := make(map[string]bool)
+	var path []string
+	var visit func(current *v1alpha1.GenericController) bool
+	visit = func(current *v1alpha1.GenericController) bool {
+		if current.Key() != target.Key() && triggers(current, target) {
+			return true
+		}
+		for _, next := range others {
+			if next.Key() == current.Key() || next.Key() == target.Key() {
+				continue
+			}
+			if visited[next.Key()] || !triggers(current, next) {
+				continue
+			}
+			visited[next.Key()] = true
+			path = append(path, next.Key())
+			if visit(next) {
+				return true
+			}
+			path = path[:len(path)-1]
+		}
+		return false
+	}
+	if !visit(target) {
+		return nil
+	}
+	return append([]string{target.Key()}, path...)
+}
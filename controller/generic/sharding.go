@@ -0,0 +1,80 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"k8s.io/apimachinery/pkg/labels"
+
+	"openebs.io/metac/shard"
+)
+
+// makeShardKey returns the key that decides the replica of metac
+// that reconciles the watch anchored by the given watch key
+//
+// NOTE:
+//	Controller is part of this key to spread the watches that are
+// reconciled by several controllers across the replicas
+func (mgr *watchController) makeShardKey(key string) string {
+	return mgr.GCtlConfig.Key() + "/" + key
+}
+
+// isShardOwner returns true if the watch anchored by the given key
+// is reconciled by this replica. This is always true if the
+// reconciles are not sharded.
+func (mgr *watchController) isShardOwner(key string) bool {
+	return shard.IsOwner(mgr.makeShardKey(key))
+}
+
+// skipNotOwned forgets the watch anchored by the given key that got
+// dequeued after some other replica took it over
+func (mgr *watchController) skipNotOwned(key string) {
+	mgr.takeTrigger(key)
+	mgr.takeQueueWait(key)
+
+	// failures are now reported by the owner
+	mgr.syncErrMutex.Lock()
+	delete(mgr.syncFailures, key)
+	mgr.syncErrMutex.Unlock()
+
+	mgr.forWatch(key).V(4).Info("Won't sync watch: Owned by another replica")
+}
+
+// onShardChange enqueues the watches that are reconciled by this
+// replica after the given change of the members of the shard group
+//
+// NOTE:
+//	Watches that are taken over by other replicas are skipped when
+// these are dequeued
+func (mgr *watchController) onShardChange(change shard.Change) {
+	var count int
+	for _, informer := range mgr.watchInformers {
+		watches, err := informer.Lister().List(labels.Everything())
+		if err != nil {
+			mgr.log.Warning("Can't enqueue watches gained from shard change", "err", err)
+			continue
+		}
+		for _, watch := range watches {
+			key, err := makeWatchQueueKey(watch)
+			if err != nil || !change.IsGained(mgr.makeShardKey(key)) {
+				continue
+			}
+			mgr.enqueueWatch(watch, TriggerShard)
+			count++
+		}
+	}
+	mgr.log.V(2).Info("Enqueued watches gained from shard change", "count", count)
+}
@@ -0,0 +1,240 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"strings"
+	"time"
+
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+	dynamicdiscovery "openebs.io/metac/dynamic/discovery"
+)
+
+// statusResyncInterval is the interval at which the status of
+// GenericController resources is refreshed
+var statusResyncInterval = 30 * time.Second
+
+const (
+	// reason of Ready condition when controller is running
+	statusReasonRunning string = "Running"
+
+	// reason of Ready & Degraded conditions when controller
+	// failed to start
+	statusReasonStartFailed string = "StartFailed"
+
+	// reason of Ready condition when informers are not synced yet
+	statusReasonInformersNotSynced string = "InformersNotSynced"
+
+	// reason of Degraded condition when the most recent watch
+	// sync failed
+	statusReasonSyncFailed string = "SyncFailed"
+
+	// reason of Degraded condition when the most recent watch
+	// sync succeeded
+	statusReasonSyncSucceeded string = "SyncSucceeded"
+
+	// reason of Ready & Degraded conditions when the watch resource
+	// is not discovered yet
+	statusReasonWatchNotDiscovered string = "WatchNotDiscovered"
+)
+
+const (
+	// EventReasonDeprecatedAPI is the reason of the event raised
+	// against a GenericController when its watch or an attachment is
+	// found to be deprecated
+	EventReasonDeprecatedAPI string = "DeprecatedAPI"
+)
+
+// resolveResource returns the given resource as resolved via
+// discovery
+func resolveResource(
+	resourceMgr *dynamicdiscovery.APIResourceManager, apiVersion, resource string,
+) v1alpha1.GenericControllerResolvedResource {
+	resolved := v1alpha1.GenericControllerResolvedResource{
+		APIVersion: apiVersion,
+		Resource:   resource,
+	}
+	if apiResource := resourceMgr.GetByResource(apiVersion, resource); apiResource != nil {
+		resolved.Kind = apiResource.Kind
+	}
+	resolved.Deprecation = resourceMgr.GetDeprecation(apiVersion, resource)
+	return resolved
+}
+
+// listNewDeprecations returns the resolved resources of the given new
+// status that are deprecated & were not deprecated in the given old
+// status
+func listNewDeprecations(
+	old v1alpha1.GenericControllerStatus, new v1alpha1.GenericControllerStatus,
+) []v1alpha1.GenericControllerResolvedResource {
+	known := make(map[string]bool)
+	for _, res := range resolvedResourcesOf(old) {
+		if res.Deprecation != "" {
+			known[res.APIVersion+"/"+res.Resource] = true
+		}
+	}
+	var deprecated []v1alpha1.GenericControllerResolvedResource
+	for _, res := range resolvedResourcesOf(new) {
+		if res.Deprecation != "" && !known[res.APIVersion+"/"+res.Resource] {
+			deprecated = append(deprecated, res)
+		}
+	}
+	return deprecated
+}
+
+// resolvedResourcesOf returns the resolved watch & attachments of the
+// given status
+func resolvedResourcesOf(
+	status v1alpha1.GenericControllerStatus,
+) []v1alpha1.GenericControllerResolvedResource {
+	var resources []v1alpha1.GenericControllerResolvedResource
+	if status.Watch != nil {
+		resources = append(resources, *status.Watch)
+	}
+	return append(resources, status.Attachments...)
+}
+
+// buildGenericControllerStatus returns the status of the given
+// GenericController based on its watch controller & the error if
+// any that was observed while starting this watch controller
+func buildGenericControllerStatus(
+	resourceMgr *dynamicdiscovery.APIResourceManager,
+	ctrl *v1alpha1.GenericController,
+	wc *watchController,
+	startErr error,
+) v1alpha1.GenericControllerStatus {
+	status := v1alpha1.GenericControllerStatus{
+		Phase:              v1alpha1.GenericControllerStatusPhaseCompleted,
+		ObservedGeneration: ctrl.Generation,
+	}
+
+	ready := v1alpha1.GenericControllerCondition{
+		Type:   v1alpha1.GenericControllerConditionReady,
+		Status: v1alpha1.GenericControllerConditionTrue,
+		Reason: statusReasonRunning,
+	}
+	degraded := v1alpha1.GenericControllerCondition{
+		Type:   v1alpha1.GenericControllerConditionDegraded,
+		Status: v1alpha1.GenericControllerConditionFalse,
+		Reason: statusReasonSyncSucceeded,
+	}
+
+	if isWatchNotDiscovered(startErr) {
+		status.Phase = v1alpha1.GenericControllerStatusPhasePending
+		status.LastError = startErr.Error()
+		ready.Status = v1alpha1.GenericControllerConditionFalse
+		ready.Reason = statusReasonWatchNotDiscovered
+		ready.Message = startErr.Error()
+		degraded.Reason = statusReasonWatchNotDiscovered
+		status.Conditions = []v1alpha1.GenericControllerCondition{ready, degraded}
+		return status
+	}
+
+	if startErr != nil || wc == nil {
+		status.Phase = v1alpha1.GenericControllerStatusPhaseError
+		ready.Status = v1alpha1.GenericControllerConditionFalse
+		ready.Reason = statusReasonStartFailed
+		degraded.Status = v1alpha1.GenericControllerConditionTrue
+		degraded.Reason = statusReasonStartFailed
+		if startErr != nil {
+			status.LastError = startErr.Error()
+			degraded.Message = startErr.Error()
+		}
+		status.Conditions = []v1alpha1.GenericControllerCondition{ready, degraded}
+		return status
+	}
+
+	watch := resolveResource(
+		resourceMgr, wc.GCtlConfig.Spec.Watch.APIVersion, wc.GCtlConfig.Spec.Watch.Resource,
+	)
+	status.Watch = &watch
+	for _, attachment := range wc.GCtlConfig.Spec.Attachments {
+		status.Attachments = append(
+			status.Attachments,
+			resolveResource(wc.attachmentResourceMgr, attachment.APIVersion, attachment.Resource),
+		)
+	}
+	status.InformersSynced = wc.HasSynced()
+	status.WorkerCount = wc.workerCount
+	status.Warnings = wc.warnings.List()
+
+	if !status.InformersSynced {
+		ready.Status = v1alpha1.GenericControllerConditionFalse
+		ready.Reason = statusReasonInformersNotSynced
+	}
+	recentErr, lastErr := wc.getSyncErrs()
+	if lastErr != nil {
+		status.LastError = lastErr.Error()
+	}
+	if recentErr != nil {
+		degraded.Status = v1alpha1.GenericControllerConditionTrue
+		degraded.Reason = statusReasonSyncFailed
+		degraded.Message = recentErr.Error()
+	}
+	if failing := wc.listFailingInformers(); len(failing) != 0 {
+		// syncs use a stale cache while informers keep failing
+		degraded.Status = v1alpha1.GenericControllerConditionTrue
+		degraded.Reason = statusReasonInformerFailing
+		degraded.Message = strings.Join(failing, "; ")
+	}
+	status.Conditions = []v1alpha1.GenericControllerCondition{ready, degraded}
+	return status
+}
+
+// mergeGenericControllerStatus returns the new status with the
+// transition times of its standard conditions set based on the old
+// status. Conditions that are not standard are retained from the old
+// status.
+func mergeGenericControllerStatus(
+	old v1alpha1.GenericControllerStatus,
+	new v1alpha1.GenericControllerStatus,
+	now metav1.Time,
+) v1alpha1.GenericControllerStatus {
+	oldConds := make(map[v1alpha1.GenericControllerConditionType]v1alpha1.GenericControllerCondition)
+	var others []v1alpha1.GenericControllerCondition
+	for _, cond := range old.Conditions {
+		if cond.Type == "" {
+			others = append(others, cond)
+			continue
+		}
+		oldConds[cond.Type] = cond
+	}
+	var conds []v1alpha1.GenericControllerCondition
+	for _, cond := range new.Conditions {
+		cond.ID = string(cond.Type)
+		oldCond, ok := oldConds[cond.Type]
+		if ok && oldCond.Status == cond.Status {
+			cond.LastTransitionTime = oldCond.LastTransitionTime
+		} else {
+			cond.LastTransitionTime = &now
+		}
+		conds = append(conds, cond)
+	}
+	new.Conditions = append(others, conds...)
+	return new
+}
+
+// isGenericControllerStatusChanged returns true if the given statuses
+// are different
+func isGenericControllerStatusChanged(
+	old v1alpha1.GenericControllerStatus, new v1alpha1.GenericControllerStatus,
+) bool {
+	return !apiequality.Semantic.DeepEqual(old, new)
+}
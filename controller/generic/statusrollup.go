@@ -0,0 +1,265 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/util/jsonpath"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+	"openebs.io/metac/controller/common"
+	dynamicdiscovery "openebs.io/metac/dynamic/discovery"
+	dynamicobject "openebs.io/metac/dynamic/object"
+	k8s "openebs.io/metac/third_party/kubernetes"
+)
+
+const (
+	// reason of the rollup condition when all the attachments match
+	statusRollupReasonMatched string = "AllAttachmentsMatched"
+
+	// reason of the rollup condition when some of the attachments
+	// do not match
+	statusRollupReasonNotMatched string = "AttachmentsNotMatched"
+)
+
+// statusRollupResult is the outcome of a status rollup
+type statusRollupResult struct {
+	Rollup v1alpha1.GenericControllerStatusRollup
+
+	// number of desired attachments
+	Total int64
+
+	// number of desired attachments that match the rollup
+	Matched int64
+}
+
+// validateStatusRollups returns error if any of the status rollups
+// of the given controller is invalid
+func validateStatusRollups(config *v1alpha1.GenericController) error {
+	for _, rollup := range config.Spec.StatusRollups {
+		if rollup.APIVersion == "" || rollup.Resource == "" ||
+			rollup.Resource == wildcard || strings.HasSuffix(rollup.APIVersion, "/"+wildcard) {
+			return errors.Errorf(
+				"Invalid status rollup: Explicit apiVersion & resource are required: %s/%s",
+				rollup.APIVersion, rollup.Resource,
+			)
+		}
+		if (rollup.AttachmentCondition == "") == (rollup.JSONPath == "") {
+			return errors.Errorf(
+				"Invalid status rollup %s/%s: Either attachmentCondition or jsonPath is required",
+				rollup.APIVersion, rollup.Resource,
+			)
+		}
+		if rollup.Condition == "" && rollup.CountsPath == "" {
+			return errors.Errorf(
+				"Invalid status rollup %s/%s: Condition or countsPath is required",
+				rollup.APIVersion, rollup.Resource,
+			)
+		}
+		for _, field := range strings.Split(rollup.CountsPath, ".") {
+			if rollup.CountsPath != "" && field == "" {
+				return errors.Errorf(
+					"Invalid status rollup %s/%s: Invalid countsPath %q",
+					rollup.APIVersion, rollup.Resource, rollup.CountsPath,
+				)
+			}
+		}
+		if rollup.JSONPath != "" {
+			_, err := parseRollupJSONPath(rollup.JSONPath)
+			if err != nil {
+				return errors.Wrapf(
+					err,
+					"Invalid status rollup %s/%s: Invalid jsonPath %q",
+					rollup.APIVersion, rollup.Resource, rollup.JSONPath,
+				)
+			}
+		}
+	}
+	return nil
+}
+
+// parseRollupJSONPath returns the parsed form of the given expression
+func parseRollupJSONPath(expr string) (*jsonpath.JSONPath, error) {
+	jp := jsonpath.New("rollup").AllowMissingKeys(true)
+	err := jp.Parse(expr)
+	if err != nil {
+		return nil, err
+	}
+	return jp, nil
+}
+
+// isRollupMatch returns true if the given attachment matches the
+// given status rollup
+func isRollupMatch(
+	rollup v1alpha1.GenericControllerStatusRollup,
+	jp *jsonpath.JSONPath,
+	attachment *unstructured.Unstructured,
+) (bool, error) {
+	if rollup.AttachmentCondition != "" {
+		cond := dynamicobject.GetStatusCondition(
+			attachment.UnstructuredContent(), rollup.AttachmentCondition,
+		)
+		return cond != nil && cond.Status == "True", nil
+	}
+	var buf bytes.Buffer
+	err := jp.Execute(&buf, attachment.UnstructuredContent())
+	if err != nil {
+		return false, err
+	}
+	return buf.String() == rollup.Value, nil
+}
+
+// rollupStatus aggregates the status of the given attachments based
+// on the status rollups of this controller. Desired attachments are
+// counted while their status is found from the observed attachments.
+// A desired attachment that is not yet observed does not match.
+func rollupStatus(
+	resourceMgr *dynamicdiscovery.APIResourceManager,
+	rollups []v1alpha1.GenericControllerStatusRollup,
+	observed common.AnyUnstructRegistry,
+	desired common.AnyUnstructRegistry,
+) ([]statusRollupResult, error) {
+	var results []statusRollupResult
+	for _, rollup := range rollups {
+		resource := resourceMgr.GetByResource(rollup.APIVersion, rollup.Resource)
+		if resource == nil {
+			return nil, errors.Errorf(
+				"Can't rollup status: Can't find resource %s/%s",
+				rollup.APIVersion, rollup.Resource,
+			)
+		}
+		var jp *jsonpath.JSONPath
+		if rollup.JSONPath != "" {
+			var err error
+			jp, err = parseRollupJSONPath(rollup.JSONPath)
+			if err != nil {
+				return nil, err
+			}
+		}
+		result := statusRollupResult{Rollup: rollup}
+		for key, group := range desired {
+			apiVersion, kind := common.ParseKeyToAPIVersionKind(key)
+			apiGroup, _ := common.ParseAPIVersionToGroupVersion(apiVersion)
+			if apiGroup != resource.Group || kind != resource.Kind {
+				continue
+			}
+			for name, obj := range group {
+				if obj == nil {
+					continue
+				}
+				result.Total++
+				observedObj := observed.FindByGroupKindName(apiGroup, kind, name)
+				if observedObj == nil {
+					continue
+				}
+				isMatch, err := isRollupMatch(rollup, jp, observedObj)
+				if err != nil {
+					return nil, errors.Wrapf(
+						err,
+						"Can't rollup status of %s", common.DescObjectAsKey(observedObj),
+					)
+				}
+				if isMatch {
+					result.Matched++
+				}
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// setStatusRollups sets the given rollup results against the given
+// status. It returns the resulting status.
+func setStatusRollups(
+	status map[string]interface{}, results []statusRollupResult,
+) map[string]interface{} {
+	if len(results) == 0 {
+		return status
+	}
+	if status == nil {
+		status = make(map[string]interface{})
+	} else {
+		status = copyStatusConditions(status)
+	}
+	for _, result := range results {
+		if result.Rollup.CountsPath != "" {
+			k8s.SetNestedField(
+				status,
+				map[string]interface{}{
+					"total":   result.Total,
+					"matched": result.Matched,
+				},
+				strings.Split(result.Rollup.CountsPath, ".")...,
+			)
+		}
+		if result.Rollup.Condition != "" {
+			cond := &dynamicobject.StatusCondition{
+				Type:   result.Rollup.Condition,
+				Status: "True",
+				Reason: statusRollupReasonMatched,
+				Message: fmt.Sprintf(
+					"%d of %d attachment(s) matched", result.Matched, result.Total,
+				),
+			}
+			if result.Matched != result.Total {
+				cond.Status = "False"
+				cond.Reason = statusRollupReasonNotMatched
+			}
+			dynamicobject.SetCondition(status, cond)
+		}
+	}
+	return status
+}
+
+// retainStatusRollups sets the rollup fields of the given current
+// status against the given desired status. This ensures these fields
+// are not lost if sync hook returns a status without them.
+func (mgr *watchController) retainStatusRollups(
+	current map[string]interface{}, desired map[string]interface{},
+) map[string]interface{} {
+	if len(mgr.GCtlConfig.Spec.StatusRollups) == 0 || current == nil {
+		return desired
+	}
+	if desired == nil {
+		desired = make(map[string]interface{})
+	} else {
+		desired = copyStatusConditions(desired)
+	}
+	currentObj := map[string]interface{}{"status": current}
+	for _, rollup := range mgr.GCtlConfig.Spec.StatusRollups {
+		if rollup.CountsPath != "" {
+			fields := strings.Split(rollup.CountsPath, ".")
+			if counts := k8s.GetNestedField(current, fields...); counts != nil {
+				k8s.SetNestedField(desired, counts, fields...)
+			}
+		}
+		if rollup.Condition != "" {
+			if cond := dynamicobject.GetStatusCondition(
+				currentObj, rollup.Condition,
+			); cond != nil {
+				dynamicobject.SetCondition(desired, cond)
+			}
+		}
+	}
+	return desired
+}
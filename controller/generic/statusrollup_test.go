@@ -0,0 +1,452 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+	"openebs.io/metac/controller/common"
+	dynamicobject "openebs.io/metac/dynamic/object"
+)
+
+// newRollupPod returns the pod attachment of the given name having
+// the given status
+func newRollupPod(name string, status interface{}) *unstructured.Unstructured {
+	obj := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": "default",
+		},
+	}
+	if status != nil {
+		obj["status"] = status
+	}
+	return &unstructured.Unstructured{Object: obj}
+}
+
+// newReadyStatus returns the status having the Ready condition set
+// to the given value
+func newReadyStatus(value string) map[string]interface{} {
+	return map[string]interface{}{
+		"conditions": []interface{}{
+			map[string]interface{}{"type": "Ready", "status": value},
+		},
+	}
+}
+
+// newRollupRegistry returns the registry of the given attachments of
+// the watch in default namespace
+func newRollupRegistry(attachments ...*unstructured.Unstructured) common.AnyUnstructRegistry {
+	watch := &metav1.ObjectMeta{Name: "my-watch", Namespace: "default"}
+	registry := common.AnyUnstructRegistry{}
+	for _, attachment := range attachments {
+		registry.InsertByReference(watch, attachment)
+	}
+	return registry
+}
+
+// newPodRollup returns the status rollup of pods
+func newPodRollup() v1alpha1.GenericControllerStatusRollup {
+	return v1alpha1.GenericControllerStatusRollup{
+		ResourceRule: v1alpha1.ResourceRule{APIVersion: "v1", Resource: "pods"},
+		Condition:    "PodsReady",
+	}
+}
+
+func TestValidateStatusRollups(t *testing.T) {
+	var tests = map[string]struct {
+		rollup v1alpha1.GenericControllerStatusRollup
+		isErr  bool
+	}{
+		"valid condition rollup": {
+			rollup: func() v1alpha1.GenericControllerStatusRollup {
+				r := newPodRollup()
+				r.AttachmentCondition = "Ready"
+				return r
+			}(),
+		},
+		"valid jsonpath rollup with counts": {
+			rollup: func() v1alpha1.GenericControllerStatusRollup {
+				r := newPodRollup()
+				r.Condition = ""
+				r.JSONPath = "{.status.phase}"
+				r.Value = "Running"
+				r.CountsPath = "rollups.pods"
+				return r
+			}(),
+		},
+		"wildcard resource": {
+			rollup: func() v1alpha1.GenericControllerStatusRollup {
+				r := newPodRollup()
+				r.Resource = wildcard
+				r.AttachmentCondition = "Ready"
+				return r
+			}(),
+			isErr: true,
+		},
+		"wildcard version": {
+			rollup: func() v1alpha1.GenericControllerStatusRollup {
+				r := newPodRollup()
+				r.APIVersion = "apps/" + wildcard
+				r.AttachmentCondition = "Ready"
+				return r
+			}(),
+			isErr: true,
+		},
+		"neither attachment condition nor jsonpath": {
+			rollup: newPodRollup(),
+			isErr:  true,
+		},
+		"both attachment condition & jsonpath": {
+			rollup: func() v1alpha1.GenericControllerStatusRollup {
+				r := newPodRollup()
+				r.AttachmentCondition = "Ready"
+				r.JSONPath = "{.status.phase}"
+				return r
+			}(),
+			isErr: true,
+		},
+		"neither condition nor counts path": {
+			rollup: func() v1alpha1.GenericControllerStatusRollup {
+				r := newPodRollup()
+				r.Condition = ""
+				r.AttachmentCondition = "Ready"
+				return r
+			}(),
+			isErr: true,
+		},
+		"counts path with an empty field": {
+			rollup: func() v1alpha1.GenericControllerStatusRollup {
+				r := newPodRollup()
+				r.AttachmentCondition = "Ready"
+				r.CountsPath = "rollups..pods"
+				return r
+			}(),
+			isErr: true,
+		},
+		"invalid jsonpath": {
+			rollup: func() v1alpha1.GenericControllerStatusRollup {
+				r := newPodRollup()
+				r.JSONPath = "{.status.phase"
+				return r
+			}(),
+			isErr: true,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			config := &v1alpha1.GenericController{
+				Spec: v1alpha1.GenericControllerSpec{
+					StatusRollups: []v1alpha1.GenericControllerStatusRollup{mock.rollup},
+				},
+			}
+			err := validateStatusRollups(config)
+			if mock.isErr != (err != nil) {
+				t.Fatalf("Expected error %t got %v", mock.isErr, err)
+			}
+		})
+	}
+}
+
+func TestRollupStatus(t *testing.T) {
+	resourceMgr, stop := newFakeResourceManager(t, newWildcardTestResources()...)
+	defer stop()
+
+	conditionRollup := newPodRollup()
+	conditionRollup.AttachmentCondition = "Ready"
+	phaseRollup := newPodRollup()
+	phaseRollup.JSONPath = "{.status.phase}"
+	phaseRollup.Value = "Running"
+
+	var tests = map[string]struct {
+		rollup      v1alpha1.GenericControllerStatusRollup
+		observed    common.AnyUnstructRegistry
+		desired     common.AnyUnstructRegistry
+		wantTotal   int64
+		wantMatched int64
+		isErr       bool
+	}{
+		"all conditions match": {
+			rollup: conditionRollup,
+			observed: newRollupRegistry(
+				newRollupPod("pod-1", newReadyStatus("True")),
+				newRollupPod("pod-2", newReadyStatus("True")),
+			),
+			desired:     newRollupRegistry(newRollupPod("pod-1", nil), newRollupPod("pod-2", nil)),
+			wantTotal:   2,
+			wantMatched: 2,
+		},
+		"false condition does not match": {
+			rollup: conditionRollup,
+			observed: newRollupRegistry(
+				newRollupPod("pod-1", newReadyStatus("True")),
+				newRollupPod("pod-2", newReadyStatus("False")),
+			),
+			desired:     newRollupRegistry(newRollupPod("pod-1", nil), newRollupPod("pod-2", nil)),
+			wantTotal:   2,
+			wantMatched: 1,
+		},
+		"desired attachment that is not observed does not match": {
+			rollup:      conditionRollup,
+			observed:    newRollupRegistry(newRollupPod("pod-1", newReadyStatus("True"))),
+			desired:     newRollupRegistry(newRollupPod("pod-1", nil), newRollupPod("pod-2", nil)),
+			wantTotal:   2,
+			wantMatched: 1,
+		},
+		"observed attachment that is not desired is not counted": {
+			rollup: conditionRollup,
+			observed: newRollupRegistry(
+				newRollupPod("pod-1", newReadyStatus("True")),
+				newRollupPod("pod-2", newReadyStatus("True")),
+			),
+			desired:     newRollupRegistry(newRollupPod("pod-1", nil)),
+			wantTotal:   1,
+			wantMatched: 1,
+		},
+		"no desired attachments": {
+			rollup:   conditionRollup,
+			observed: newRollupRegistry(newRollupPod("pod-1", newReadyStatus("True"))),
+			desired:  newRollupRegistry(),
+		},
+		"nil desired attachment is not counted": {
+			rollup:   conditionRollup,
+			observed: newRollupRegistry(newRollupPod("pod-1", newReadyStatus("True"))),
+			desired: func() common.AnyUnstructRegistry {
+				registry := newRollupRegistry(newRollupPod("pod-1", nil))
+				for _, group := range registry {
+					group["pod-1"] = nil
+				}
+				return registry
+			}(),
+		},
+		"attachments of other kinds are not counted": {
+			rollup:   conditionRollup,
+			observed: newRollupRegistry(),
+			desired: newRollupRegistry(&unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"apiVersion": "v1",
+					"kind":       "ConfigMap",
+					"metadata":   map[string]interface{}{"name": "cm-1", "namespace": "default"},
+				},
+			}),
+		},
+		"conditions of an invalid type do not match": {
+			rollup: conditionRollup,
+			observed: newRollupRegistry(
+				newRollupPod("pod-1", map[string]interface{}{"conditions": "Ready"}),
+				newRollupPod("pod-2", map[string]interface{}{
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "Ready", "status": true},
+					},
+				}),
+			),
+			desired:   newRollupRegistry(newRollupPod("pod-1", nil), newRollupPod("pod-2", nil)),
+			wantTotal: 2,
+		},
+		"jsonpath matches": {
+			rollup: phaseRollup,
+			observed: newRollupRegistry(
+				newRollupPod("pod-1", map[string]interface{}{"phase": "Running"}),
+				newRollupPod("pod-2", map[string]interface{}{"phase": "Pending"}),
+			),
+			desired:     newRollupRegistry(newRollupPod("pod-1", nil), newRollupPod("pod-2", nil)),
+			wantTotal:   2,
+			wantMatched: 1,
+		},
+		"jsonpath of a missing field does not match": {
+			rollup:      phaseRollup,
+			observed:    newRollupRegistry(newRollupPod("pod-1", nil)),
+			desired:     newRollupRegistry(newRollupPod("pod-1", nil)),
+			wantTotal:   1,
+			wantMatched: 0,
+		},
+		"jsonpath of a non string field matches its printed value": {
+			rollup: func() v1alpha1.GenericControllerStatusRollup {
+				r := newPodRollup()
+				r.JSONPath = "{.status.restarts}"
+				r.Value = "3"
+				return r
+			}(),
+			observed: newRollupRegistry(
+				newRollupPod("pod-1", map[string]interface{}{"restarts": int64(3)}),
+				newRollupPod("pod-2", map[string]interface{}{"restarts": "three"}),
+			),
+			desired:     newRollupRegistry(newRollupPod("pod-1", nil), newRollupPod("pod-2", nil)),
+			wantTotal:   2,
+			wantMatched: 1,
+		},
+		"jsonpath that does not fit the field's type": {
+			rollup: func() v1alpha1.GenericControllerStatusRollup {
+				r := newPodRollup()
+				r.JSONPath = "{.status.phase[0]}"
+				r.Value = "Running"
+				return r
+			}(),
+			observed: newRollupRegistry(
+				newRollupPod("pod-1", map[string]interface{}{"phase": "Running"}),
+			),
+			desired: newRollupRegistry(newRollupPod("pod-1", nil)),
+			isErr:   true,
+		},
+		"rollup of an undiscovered resource": {
+			rollup: func() v1alpha1.GenericControllerStatusRollup {
+				r := newPodRollup()
+				r.APIVersion = "example.com/v1"
+				r.AttachmentCondition = "Ready"
+				return r
+			}(),
+			observed: newRollupRegistry(),
+			desired:  newRollupRegistry(),
+			isErr:    true,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			results, err := rollupStatus(
+				resourceMgr,
+				[]v1alpha1.GenericControllerStatusRollup{mock.rollup},
+				mock.observed,
+				mock.desired,
+			)
+			if mock.isErr != (err != nil) {
+				t.Fatalf("Expected error %t got %v", mock.isErr, err)
+			}
+			if mock.isErr {
+				return
+			}
+			if len(results) != 1 {
+				t.Fatalf("Expected 1 result got %d", len(results))
+			}
+			if results[0].Total != mock.wantTotal {
+				t.Fatalf("Expected total %d got %d", mock.wantTotal, results[0].Total)
+			}
+			if results[0].Matched != mock.wantMatched {
+				t.Fatalf("Expected matched %d got %d", mock.wantMatched, results[0].Matched)
+			}
+		})
+	}
+}
+
+func TestSetStatusRollups(t *testing.T) {
+	countsRollup := newPodRollup()
+	countsRollup.Condition = ""
+	countsRollup.CountsPath = "rollups.pods"
+
+	var tests = map[string]struct {
+		status      map[string]interface{}
+		result      statusRollupResult
+		wantCounts  interface{}
+		wantCondition string
+	}{
+		"all matched": {
+			result:      statusRollupResult{Rollup: newPodRollup(), Total: 2, Matched: 2},
+			wantCondition: "True",
+		},
+		"some not matched": {
+			result:      statusRollupResult{Rollup: newPodRollup(), Total: 2, Matched: 1},
+			wantCondition: "False",
+		},
+		"no attachments": {
+			result:      statusRollupResult{Rollup: newPodRollup()},
+			wantCondition: "True",
+		},
+		"counts": {
+			status: map[string]interface{}{"phase": "Active"},
+			result: statusRollupResult{Rollup: countsRollup, Total: 3, Matched: 1},
+			wantCounts: map[string]interface{}{
+				"total": int64(3), "matched": int64(1),
+			},
+		},
+		"counts replace a field of another type": {
+			status: map[string]interface{}{"rollups": "none"},
+			result: statusRollupResult{Rollup: countsRollup, Total: 1, Matched: 1},
+			wantCounts: map[string]interface{}{
+				"total": int64(1), "matched": int64(1),
+			},
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			var original map[string]interface{}
+			if mock.status != nil {
+				original = copyStatusConditions(mock.status)
+			}
+			got := setStatusRollups(mock.status, []statusRollupResult{mock.result})
+			if mock.status != nil && !reflect.DeepEqual(mock.status, original) {
+				t.Fatalf("Expected given status to be unchanged got %v", mock.status)
+			}
+			if mock.wantCounts != nil {
+				counts := got["rollups"].(map[string]interface{})["pods"]
+				if !reflect.DeepEqual(counts, mock.wantCounts) {
+					t.Fatalf("Expected counts %v got %v", mock.wantCounts, counts)
+				}
+			}
+			cond := dynamicobject.GetStatusCondition(
+				map[string]interface{}{"status": got}, "PodsReady",
+			)
+			if mock.wantCondition == "" {
+				if cond != nil {
+					t.Fatalf("Expected no condition got %v", cond)
+				}
+				return
+			}
+			if cond == nil || cond.Status != mock.wantCondition {
+				t.Fatalf("Expected condition status %q got %v", mock.wantCondition, cond)
+			}
+		})
+	}
+}
+
+func TestWatchControllerRetainStatusRollups(t *testing.T) {
+	rollup := newPodRollup()
+	rollup.CountsPath = "pods"
+	mgr := &watchController{
+		GCtlConfig: &v1alpha1.GenericController{
+			Spec: v1alpha1.GenericControllerSpec{
+				StatusRollups: []v1alpha1.GenericControllerStatusRollup{rollup},
+			},
+		},
+	}
+	current := setStatusRollups(
+		map[string]interface{}{"phase": "Old"},
+		[]statusRollupResult{{Rollup: rollup, Total: 2, Matched: 2}},
+	)
+	got := mgr.retainStatusRollups(current, map[string]interface{}{"phase": "New"})
+	if got["phase"] != "New" {
+		t.Fatalf("Expected desired phase New got %v", got["phase"])
+	}
+	if !reflect.DeepEqual(got["pods"], current["pods"]) {
+		t.Fatalf("Expected retained counts %v got %v", current["pods"], got["pods"])
+	}
+	cond := dynamicobject.GetStatusCondition(map[string]interface{}{"status": got}, "PodsReady")
+	if cond == nil || cond.Status != "True" {
+		t.Fatalf("Expected retained condition got %v", cond)
+	}
+}
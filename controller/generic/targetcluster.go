@@ -0,0 +1,110 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"github.com/pkg/errors"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+	dynamicclientset "openebs.io/metac/dynamic/clientset"
+	dynamicdiscovery "openebs.io/metac/dynamic/discovery"
+	dynamicinformer "openebs.io/metac/dynamic/informer"
+)
+
+// TargetCluster represents the cluster where the attachments of a
+// controller are created & observed. This is a cluster other than
+// the one that serves the watches of this controller.
+type TargetCluster struct {
+	// Name by which this cluster is referred to
+	Name string
+
+	ResourceManager    *dynamicdiscovery.APIResourceManager
+	DynClientset       *dynamicclientset.Clientset
+	DynInformerFactory *dynamicinformer.SharedInformerFactory
+}
+
+// TargetClusterFn returns the cluster whose kubeconfig is held by
+// the given secret
+type TargetClusterFn func(ref v1alpha1.GenericControllerKubeconfigSecretRef) (*TargetCluster, error)
+
+// isRemoteAttachments returns true if the attachments of the given
+// controller are managed in a cluster other than its watch's cluster
+func isRemoteAttachments(config *v1alpha1.GenericController) bool {
+	return config.Spec.TargetCluster != nil
+}
+
+// validateTargetCluster returns error if the target cluster of the
+// given controller is invalid
+func validateTargetCluster(config *v1alpha1.GenericController) error {
+	if !isRemoteAttachments(config) {
+		return nil
+	}
+	ref := config.Spec.TargetCluster.KubeconfigSecretRef
+	if ref.Name == "" {
+		return errors.Errorf("Invalid target cluster: Missing kubeconfig secret name")
+	}
+	if ref.Namespace == "" && config.Namespace == "" {
+		return errors.Errorf(
+			"Invalid target cluster: Missing namespace of kubeconfig secret %q", ref.Name,
+		)
+	}
+	for _, a := range config.Spec.Attachments {
+		// namespaces of the target cluster are not observed
+		if a.NamespaceSelector != nil {
+			return errors.Errorf(
+				"Invalid target cluster: Attachment %q of %q can't set namespace selector",
+				a.Resource, a.APIVersion,
+			)
+		}
+	}
+	return nil
+}
+
+// makeKubeconfigSecretRef returns the secret that holds the kubeconfig
+// of the target cluster of the given controller
+func makeKubeconfigSecretRef(config *v1alpha1.GenericController) v1alpha1.GenericControllerKubeconfigSecretRef {
+	ref := config.Spec.TargetCluster.KubeconfigSecretRef
+	if ref.Namespace == "" {
+		ref.Namespace = config.Namespace
+	}
+	return ref
+}
+
+// getTargetCluster returns the cluster where the attachments of the
+// given controller are managed. Nil is returned if these attachments
+// are managed in the cluster of the watches.
+func (mc *MetaController) getTargetCluster(config *v1alpha1.GenericController) (*TargetCluster, error) {
+	if !isRemoteAttachments(config) {
+		return nil, nil
+	}
+	ref := makeKubeconfigSecretRef(config)
+	if mc.TargetClusterFn == nil {
+		return nil, errors.Errorf(
+			"WatchGCtl %s/%s: Target clusters are not supported: Kubeconfig secret %s/%s",
+			config.Namespace, config.Name, ref.Namespace, ref.Name,
+		)
+	}
+	cluster, err := mc.TargetClusterFn(ref)
+	if err != nil {
+		return nil, errors.Wrapf(
+			err,
+			"WatchGCtl %s/%s: Can't load target cluster: Kubeconfig secret %s/%s",
+			config.Namespace, config.Name, ref.Namespace, ref.Name,
+		)
+	}
+	return cluster, nil
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+)
+
+// newTargetClusterController returns the controller in metac
+// namespace whose attachments are managed in the cluster of the given
+// kubeconfig secret
+func newTargetClusterController(
+	ref *v1alpha1.GenericControllerKubeconfigSecretRef,
+	attachments ...v1alpha1.GenericControllerAttachment,
+) *v1alpha1.GenericController {
+	config := newWildcardTestController(attachments...)
+	config.Name = "my-ctl"
+	config.Namespace = "metac"
+	if ref != nil {
+		config.Spec.TargetCluster = &v1alpha1.GenericControllerTargetCluster{
+			KubeconfigSecretRef: *ref,
+		}
+	}
+	return config
+}
+
+func TestValidateTargetCluster(t *testing.T) {
+	withNamespaceSelector := newTestAttachment("v1", "pods")
+	withNamespaceSelector.NamespaceSelector = &metav1.LabelSelector{
+		MatchLabels: map[string]string{"team": "dev"},
+	}
+
+	var tests = map[string]struct {
+		config *v1alpha1.GenericController
+		isErr  bool
+	}{
+		"no target cluster": {
+			config: newTargetClusterController(nil, withNamespaceSelector),
+		},
+		"secret of controller's namespace": {
+			config: newTargetClusterController(
+				&v1alpha1.GenericControllerKubeconfigSecretRef{Name: "remote"},
+				newTestAttachment("v1", "pods"),
+			),
+		},
+		"missing secret name": {
+			config: newTargetClusterController(
+				&v1alpha1.GenericControllerKubeconfigSecretRef{Namespace: "ns"},
+			),
+			isErr: true,
+		},
+		"missing secret namespace of cluster scoped controller": {
+			config: func() *v1alpha1.GenericController {
+				config := newTargetClusterController(
+					&v1alpha1.GenericControllerKubeconfigSecretRef{Name: "remote"},
+				)
+				config.Namespace = ""
+				return config
+			}(),
+			isErr: true,
+		},
+		"attachment with namespace selector": {
+			config: newTargetClusterController(
+				&v1alpha1.GenericControllerKubeconfigSecretRef{Name: "remote"},
+				withNamespaceSelector,
+			),
+			isErr: true,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			err := validateTargetCluster(mock.config)
+			if mock.isErr != (err != nil) {
+				t.Fatalf("Expected error %t got %v", mock.isErr, err)
+			}
+		})
+	}
+}
+
+func TestMetaControllerGetTargetCluster(t *testing.T) {
+	remote := &TargetCluster{Name: "remote"}
+
+	var tests = map[string]struct {
+		ref         *v1alpha1.GenericControllerKubeconfigSecretRef
+		fn          func(v1alpha1.GenericControllerKubeconfigSecretRef) (*TargetCluster, error)
+		wantRef     v1alpha1.GenericControllerKubeconfigSecretRef
+		wantCluster *TargetCluster
+		isErr       bool
+	}{
+		"no target cluster": {
+			fn: func(v1alpha1.GenericControllerKubeconfigSecretRef) (*TargetCluster, error) {
+				return remote, nil
+			},
+		},
+		"secret defaults to controller's namespace": {
+			ref: &v1alpha1.GenericControllerKubeconfigSecretRef{Name: "secret", Key: "config"},
+			fn: func(v1alpha1.GenericControllerKubeconfigSecretRef) (*TargetCluster, error) {
+				return remote, nil
+			},
+			wantRef: v1alpha1.GenericControllerKubeconfigSecretRef{
+				Name: "secret", Namespace: "metac", Key: "config",
+			},
+			wantCluster: remote,
+		},
+		"secret of another namespace": {
+			ref: &v1alpha1.GenericControllerKubeconfigSecretRef{Name: "secret", Namespace: "ns"},
+			fn: func(v1alpha1.GenericControllerKubeconfigSecretRef) (*TargetCluster, error) {
+				return remote, nil
+			},
+			wantRef:     v1alpha1.GenericControllerKubeconfigSecretRef{Name: "secret", Namespace: "ns"},
+			wantCluster: remote,
+		},
+		"target clusters are not supported": {
+			ref:   &v1alpha1.GenericControllerKubeconfigSecretRef{Name: "secret"},
+			isErr: true,
+		},
+		"cluster can't be loaded": {
+			ref: &v1alpha1.GenericControllerKubeconfigSecretRef{Name: "secret"},
+			fn: func(v1alpha1.GenericControllerKubeconfigSecretRef) (*TargetCluster, error) {
+				return nil, errors.Errorf("Secret not found")
+			},
+			wantRef: v1alpha1.GenericControllerKubeconfigSecretRef{
+				Name: "secret", Namespace: "metac",
+			},
+			isErr: true,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			var gotRef v1alpha1.GenericControllerKubeconfigSecretRef
+			mc := &MetaController{}
+			if mock.fn != nil {
+				mc.TargetClusterFn = func(
+					ref v1alpha1.GenericControllerKubeconfigSecretRef,
+				) (*TargetCluster, error) {
+					gotRef = ref
+					return mock.fn(ref)
+				}
+			}
+			got, err := mc.getTargetCluster(newTargetClusterController(mock.ref))
+			if mock.isErr != (err != nil) {
+				t.Fatalf("Expected error %t got %v", mock.isErr, err)
+			}
+			if got != mock.wantCluster {
+				t.Fatalf("Expected cluster %v got %v", mock.wantCluster, got)
+			}
+			if gotRef != mock.wantRef {
+				t.Fatalf("Expected secret %+v got %+v", mock.wantRef, gotRef)
+			}
+		})
+	}
+}
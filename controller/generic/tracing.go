@@ -0,0 +1,115 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"context"
+	"time"
+
+	"go.opencensus.io/trace"
+
+	"openebs.io/metac/tracing"
+)
+
+// names of the spans of a reconcile
+const (
+	spanReconcile         = "metac/reconcile"
+	spanHook              = "metac/hook"
+	spanUpdateWatch       = "metac/update-watch"
+	spanUpdateWatchStatus = "metac/update-watch-status"
+	spanUpdateManaged     = "metac/update-managed-status"
+	spanApplyAttachments  = "metac/apply-attachments"
+	spanScaleAttachments  = "metac/scale-attachments"
+)
+
+// keys of the attributes set against the spans of a reconcile
+const (
+	// time spent by the watch in the queue before its reconcile
+	attributeQueueWait = "metac.queue_wait_seconds"
+
+	// flags if the finalize hook is invoked
+	attributeIsFinalizing = "metac.finalizing"
+)
+
+// requestIDKey is the key of the request ID set against the context
+// of a reconcile
+type requestIDKey struct{}
+
+// makeRequestID returns the request ID of the reconcile traced by the
+// given span. This is the trace ID of the span that is sent to hooks
+// as part of the traceparent header as well.
+//
+// NOTE:
+//	Trace ID is set even if the span is not sampled
+func makeRequestID(span *trace.Span) string {
+	return span.SpanContext().TraceID.String()
+}
+
+// withRequestID returns a copy of the given context that holds the
+// given request ID
+func withRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// requestIDFrom returns the request ID held by the given context.
+// Empty string is returned if the context does not hold one.
+func requestIDFrom(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// markEnqueued tracks the time at which the watch having the given
+// key got queued. The earliest time is retained till the watch is
+// reconciled.
+func (mgr *watchController) markEnqueued(key string) {
+	mgr.enqueueMutex.Lock()
+	defer mgr.enqueueMutex.Unlock()
+
+	if _, found := mgr.enqueueTimes[key]; !found {
+		mgr.enqueueTimes[key] = time.Now()
+	}
+}
+
+// takeQueueWait returns the time the watch having the given key was
+// waiting in the queue. The wait is no longer tracked once returned.
+func (mgr *watchController) takeQueueWait(key string) (time.Duration, bool) {
+	mgr.enqueueMutex.Lock()
+	defer mgr.enqueueMutex.Unlock()
+
+	enqueued, found := mgr.enqueueTimes[key]
+	if !found {
+		return 0, false
+	}
+	delete(mgr.enqueueTimes, key)
+	return time.Since(enqueued), true
+}
+
+// startReconcileSpan starts the root span of the reconcile of the
+// watch having the given key
+func (mgr *watchController) startReconcileSpan(key string) (context.Context, *trace.Span) {
+	attrs := []trace.Attribute{
+		trace.StringAttribute(tracing.AttributeController, mgr.GCtlConfig.Key()),
+		trace.StringAttribute(tracing.AttributeWatch, key),
+	}
+	if waited, found := mgr.takeQueueWait(key); found {
+		attrs = append(attrs, trace.Float64Attribute(attributeQueueWait, waited.Seconds()))
+	}
+	return tracing.StartSpan(context.Background(), spanReconcile, attrs...)
+}
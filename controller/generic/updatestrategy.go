@@ -20,6 +20,7 @@ import (
 	"fmt"
 
 	"github.com/golang/glog"
+	"k8s.io/apimachinery/pkg/types"
 
 	"openebs.io/metac/apis/metacontroller/v1alpha1"
 	"openebs.io/metac/controller/common"
@@ -120,3 +121,38 @@ func (mgr attachmentUpdateStrategyManager) IsPatchByGK(apiGroup, kind string) bo
 	}
 	return *strategy.Patch
 }
+
+// GetPatchTypeByGK returns the type of patch used to update the
+// attachment based on the given api group & kind in-place. Empty
+// patch type implies the attachment is updated as a whole.
+func (mgr attachmentUpdateStrategyManager) GetPatchTypeByGK(
+	apiGroup, kind string,
+) types.PatchType {
+	strategy := mgr.getStrategyByGK(apiGroup, kind)
+	if strategy == nil {
+		return ""
+	}
+	switch strategy.PatchType {
+	case v1alpha1.AttachmentPatchTypeStrategicMerge:
+		return types.StrategicMergePatchType
+	case v1alpha1.AttachmentPatchTypeMerge:
+		return types.MergePatchType
+	case v1alpha1.AttachmentPatchTypeJSON:
+		return types.JSONPatchType
+	default:
+		return ""
+	}
+}
+
+// IsRecreateOnImmutableErrorByGK returns true if attachment based on
+// the given api group & kind need to be deleted & recreated when its
+// update fails due to changes in immutable fields.
+func (mgr attachmentUpdateStrategyManager) IsRecreateOnImmutableErrorByGK(
+	apiGroup, kind string,
+) bool {
+	strategy := mgr.getStrategyByGK(apiGroup, kind)
+	if strategy == nil || strategy.RecreateOnImmutableError == nil {
+		return false
+	}
+	return *strategy.RecreateOnImmutableError
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"github.com/pkg/errors"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+	dynamicdiscovery "openebs.io/metac/dynamic/discovery"
+)
+
+// ValidateConfig verifies the given controller as declared i.e. without
+// verifying its resources against the cluster. Problems found by all
+// the validations are returned as a single aggregated error.
+func ValidateConfig(config *v1alpha1.GenericController) error {
+	var errs []error
+	// attachments are validated as declared since wildcard attachments
+	// may not expand to any resource at this point in time
+	err := validateAttachmentRules(config)
+	if err != nil {
+		errs = append(errs, errors.Wrapf(err, "Invalid attachments"))
+	}
+	for _, validate := range []func(*v1alpha1.GenericController) error{
+		validateResourceSelectors,
+		validateObservedGenerationPath,
+		validateStatusRollups,
+		validateFinalizerName,
+		validateServerSideApply,
+		validateImpersonation,
+		validateTargetCluster,
+		validateClientLimits,
+		validateLogLevel,
+		validateHooks,
+	} {
+		err = validate(config)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// ValidateConfigResources verifies the resources of the given controller
+// are discovered by the given resource manager
+//
+// NOTE:
+//	Attachments of a controller that sets a target cluster are not
+// verified since these are discovered in that cluster
+func ValidateConfigResources(
+	resourceMgr *dynamicdiscovery.APIResourceManager, config *v1alpha1.GenericController,
+) error {
+	// short names & categories are verified against the resources
+	// these are resolved to
+	config, err := withResolvedAliases(resourceMgr, resourceMgr, config)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	watch := config.Spec.Watch
+	if resourceMgr.GetByResource(watch.APIVersion, watch.Resource) == nil {
+		errs = append(errs, errors.Errorf(
+			"Watch %s %s is not discovered", watch.APIVersion, watch.Resource,
+		))
+	}
+	attachments := config.Spec.Attachments
+	if isRemoteAttachments(config) {
+		// attachments are discovered in the target cluster
+		attachments = nil
+	}
+	for _, attachment := range attachments {
+		if isWildcardAttachment(attachment) {
+			// wildcards may expand to any resource
+			continue
+		}
+		if resourceMgr.GetByResource(attachment.APIVersion, attachment.Resource) == nil {
+			errs = append(errs, errors.Errorf(
+				"Attachment %s %s is not discovered", attachment.APIVersion, attachment.Resource,
+			))
+		}
+	}
+	if len(errs) == 0 && !isRemoteAttachments(config) {
+		err := validateUniqueAttachmentKinds(resourceMgr, config)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		// references are verified against the expanded attachments
+		err = validateSelfReference(resourceMgr, withExpandedAttachments(resourceMgr, config))
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
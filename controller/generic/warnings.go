@@ -0,0 +1,33 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+// isIncludeWarnings returns true if the sync hook requests should
+// include the warnings returned by the API server
+func (mgr *watchController) isIncludeWarnings() bool {
+	return mgr.GCtlConfig.Spec.IncludeWarnings != nil &&
+		*mgr.GCtlConfig.Spec.IncludeWarnings
+}
+
+// listHookWarnings returns the recently seen warnings that are sent
+// as part of the sync hook request
+func (mgr *watchController) listHookWarnings() []string {
+	if !mgr.isIncludeWarnings() {
+		return nil
+	}
+	return mgr.warnings.List()
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"reflect"
+	"strings"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+	dynamicdiscovery "openebs.io/metac/dynamic/discovery"
+)
+
+// wildcard is used in attachment's resource or in the version part
+// of attachment's apiVersion to refer to all the resources or to the
+// preferred version of an api group respectively
+const wildcard string = "*"
+
+// isWildcardAttachment returns true if the given attachment refers
+// to more than one resource or to the preferred version of an api
+// group
+func isWildcardAttachment(attachment v1alpha1.GenericControllerAttachment) bool {
+	return attachment.Resource == wildcard ||
+		strings.HasSuffix(attachment.APIVersion, "/"+wildcard)
+}
+
+// hasWildcardAttachments returns true if any of the attachments of
+// the given controller is a wildcard attachment
+func hasWildcardAttachments(config *v1alpha1.GenericController) bool {
+	for _, attachment := range config.Spec.Attachments {
+		if isWildcardAttachment(attachment) {
+			return true
+		}
+	}
+	return false
+}
+
+// isListWatchable returns true if the given resource can be cached
+// via informers
+func isListWatchable(resource *dynamicdiscovery.APIResource) bool {
+	var canList, canWatch bool
+	for _, verb := range resource.Verbs {
+		switch verb {
+		case "list":
+			canList = true
+		case "watch":
+			canWatch = true
+		}
+	}
+	return canList && canWatch
+}
+
+// expandWildcardAttachments returns the attachments of the given
+// controller after expanding its wildcard attachments into the
+// resources that are currently discovered. Each expanded attachment
+// inherits all the other fields of its wildcard attachment.
+//
+// NOTE:
+//	Explicit attachments have higher priority than the wildcard
+// ones for the same api group & kind. Resources that can't be listed
+// & watched as well as the watch's resource are never expanded. Cluster
+// scoped resources are not expanded if the wildcard attachment has
+// explicit namespaces.
+func expandWildcardAttachments(
+	resourceMgr *dynamicdiscovery.APIResourceManager,
+	config *v1alpha1.GenericController,
+) []v1alpha1.GenericControllerAttachment {
+	var expanded []v1alpha1.GenericControllerAttachment
+	// api group & kind of the expanded attachments
+	seen := make(map[string]bool)
+
+	watchAPI := resourceMgr.GetByResource(
+		config.Spec.Watch.APIVersion, config.Spec.Watch.Resource,
+	)
+	if watchAPI != nil {
+		seen[makeSelectorKeyFromGK(watchAPI.Group, watchAPI.Kind)] = true
+	}
+	// explicit attachments are kept as is
+	for _, attachment := range config.Spec.Attachments {
+		if isWildcardAttachment(attachment) {
+			continue
+		}
+		expanded = append(expanded, attachment)
+		resource := resourceMgr.GetByResource(attachment.APIVersion, attachment.Resource)
+		if resource != nil {
+			seen[makeSelectorKeyFromGK(resource.Group, resource.Kind)] = true
+		}
+	}
+	for _, attachment := range config.Spec.Attachments {
+		if !isWildcardAttachment(attachment) {
+			continue
+		}
+		apiVersion := attachment.APIVersion
+		if strings.HasSuffix(apiVersion, "/"+wildcard) {
+			apiGroup := strings.TrimSuffix(apiVersion, "/"+wildcard)
+			apiVersion = resourceMgr.GetPreferredAPIVersion(apiGroup)
+			if apiVersion == "" {
+				// api group is not discovered yet
+				continue
+			}
+		}
+		var resources []*dynamicdiscovery.APIResource
+		if attachment.Resource == wildcard {
+			resources = resourceMgr.ListByAPIVersion(apiVersion)
+		} else if resource := resourceMgr.GetByResource(
+			apiVersion, attachment.Resource,
+		); resource != nil {
+			resources = append(resources, resource)
+		}
+		for _, resource := range resources {
+			key := makeSelectorKeyFromGK(resource.Group, resource.Kind)
+			if seen[key] || !isListWatchable(resource) {
+				continue
+			}
+			if len(attachment.Namespaces) != 0 && !resource.Namespaced {
+				// explicit namespaces apply to namespaced resources only
+				continue
+			}
+			seen[key] = true
+			concrete := *attachment.DeepCopy()
+			concrete.APIVersion = apiVersion
+			concrete.Resource = resource.Name
+			expanded = append(expanded, concrete)
+		}
+	}
+	return expanded
+}
+
+// withExpandedAttachments returns a copy of the given controller with
+// its wildcard attachments expanded. The given controller is returned
+// as is if it has no wildcard attachments.
+func withExpandedAttachments(
+	resourceMgr *dynamicdiscovery.APIResourceManager,
+	config *v1alpha1.GenericController,
+) *v1alpha1.GenericController {
+	if !hasWildcardAttachments(config) {
+		return config
+	}
+	expanded := config.DeepCopy()
+	expanded.Spec.Attachments = expandWildcardAttachments(resourceMgr, config)
+	return expanded
+}
+
+// makeAttachmentResourceKeys returns the apiVersion & resource of the
+// given attachments as keys
+func makeAttachmentResourceKeys(attachments []v1alpha1.GenericControllerAttachment) []string {
+	keys := make([]string, 0, len(attachments))
+	for _, attachment := range attachments {
+		keys = append(keys, attachment.APIVersion+"/"+attachment.Resource)
+	}
+	return keys
+}
+
+// isWildcardExpansionStale returns true if the wildcard attachments of
+// the given declared controller expand to resources that differ from
+// the given expanded controller. This happens when resources e.g. CRDs
+// get installed or removed after the expansion.
+func isWildcardExpansionStale(
+	resourceMgr *dynamicdiscovery.APIResourceManager,
+	declared *v1alpha1.GenericController,
+	expanded *v1alpha1.GenericController,
+) bool {
+	if !hasWildcardAttachments(declared) {
+		return false
+	}
+	return !reflect.DeepEqual(
+		makeAttachmentResourceKeys(expandWildcardAttachments(resourceMgr, declared)),
+		makeAttachmentResourceKeys(expanded.Spec.Attachments),
+	)
+}
@@ -0,0 +1,271 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generic
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+	dynamicdiscovery "openebs.io/metac/dynamic/discovery"
+)
+
+// listWatchVerbs are the verbs of the resources that can be cached
+// via informers
+var listWatchVerbs = metav1.Verbs{"get", "list", "watch"}
+
+// newFakeResourceManager returns a resource manager that has discovered
+// the given resources
+func newFakeResourceManager(
+	t *testing.T, resources ...*metav1.APIResourceList,
+) (*dynamicdiscovery.APIResourceManager, func()) {
+	resourceMgr := dynamicdiscovery.NewAPIResourceManager(
+		&fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{Resources: resources}},
+	)
+	resourceMgr.Start(time.Hour)
+	err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return resourceMgr.HasSynced(), nil
+	})
+	if err != nil {
+		t.Fatalf("Can't discover fake resources: %v", err)
+	}
+	return resourceMgr, resourceMgr.Stop
+}
+
+// newWildcardTestResources returns the resources that get discovered
+// by the wildcard tests
+func newWildcardTestResources() []*metav1.APIResourceList {
+	return []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "bindings", Kind: "Binding", Namespaced: true, Verbs: metav1.Verbs{"create"}},
+				{Name: "configmaps", Kind: "ConfigMap", Namespaced: true, Verbs: listWatchVerbs},
+				{Name: "nodes", Kind: "Node", Verbs: listWatchVerbs},
+				{Name: "pods", Kind: "Pod", Namespaced: true, Verbs: listWatchVerbs},
+			},
+		},
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "deployments", Kind: "Deployment", Namespaced: true, Verbs: listWatchVerbs},
+				{Name: "statefulsets", Kind: "StatefulSet", Namespaced: true, Verbs: listWatchVerbs},
+			},
+		},
+		{
+			GroupVersion: "apps/v1beta1",
+			APIResources: []metav1.APIResource{
+				{Name: "deployments", Kind: "Deployment", Namespaced: true, Verbs: listWatchVerbs},
+			},
+		},
+	}
+}
+
+// newTestAttachment returns the attachment of the given apiVersion &
+// resource
+func newTestAttachment(
+	apiVersion, resource string, namespaces ...string,
+) v1alpha1.GenericControllerAttachment {
+	attachment := v1alpha1.GenericControllerAttachment{}
+	attachment.APIVersion = apiVersion
+	attachment.Resource = resource
+	attachment.Namespaces = namespaces
+	return attachment
+}
+
+// newWildcardTestController returns the controller that watches the
+// configmaps with the given attachments
+func newWildcardTestController(
+	attachments ...v1alpha1.GenericControllerAttachment,
+) *v1alpha1.GenericController {
+	config := &v1alpha1.GenericController{}
+	config.Spec.Watch.APIVersion = "v1"
+	config.Spec.Watch.Resource = "configmaps"
+	config.Spec.Attachments = attachments
+	return config
+}
+
+func TestExpandWildcardAttachments(t *testing.T) {
+	readOnly := func(
+		attachment v1alpha1.GenericControllerAttachment,
+	) v1alpha1.GenericControllerAttachment {
+		isReadOnly := true
+		attachment.ReadOnly = &isReadOnly
+		return attachment
+	}
+	var tests = map[string]struct {
+		attachments []v1alpha1.GenericControllerAttachment
+		expect      []v1alpha1.GenericControllerAttachment
+	}{
+		"no attachments": {},
+		"explicit attachments only": {
+			attachments: []v1alpha1.GenericControllerAttachment{
+				newTestAttachment("v1", "pods"),
+				newTestAttachment("test.io/v1", "tests"),
+			},
+			expect: []v1alpha1.GenericControllerAttachment{
+				newTestAttachment("v1", "pods"),
+				newTestAttachment("test.io/v1", "tests"),
+			},
+		},
+		"all resources excluding watch & non list watch resources": {
+			attachments: []v1alpha1.GenericControllerAttachment{
+				newTestAttachment("v1", "*"),
+			},
+			expect: []v1alpha1.GenericControllerAttachment{
+				newTestAttachment("v1", "nodes"),
+				newTestAttachment("v1", "pods"),
+			},
+		},
+		"explicit attachment wins over wildcard attachment": {
+			attachments: []v1alpha1.GenericControllerAttachment{
+				readOnly(newTestAttachment("v1", "*")),
+				newTestAttachment("v1", "pods"),
+			},
+			expect: []v1alpha1.GenericControllerAttachment{
+				newTestAttachment("v1", "pods"),
+				readOnly(newTestAttachment("v1", "nodes")),
+			},
+		},
+		"explicit attachment of another version wins over wildcard attachment": {
+			attachments: []v1alpha1.GenericControllerAttachment{
+				newTestAttachment("apps/v1beta1", "deployments"),
+				newTestAttachment("apps/*", "*"),
+			},
+			expect: []v1alpha1.GenericControllerAttachment{
+				newTestAttachment("apps/v1beta1", "deployments"),
+				newTestAttachment("apps/v1", "statefulsets"),
+			},
+		},
+		"preferred version of resource": {
+			attachments: []v1alpha1.GenericControllerAttachment{
+				newTestAttachment("apps/*", "deployments"),
+			},
+			expect: []v1alpha1.GenericControllerAttachment{
+				newTestAttachment("apps/v1", "deployments"),
+			},
+		},
+		"cluster scoped resources are skipped with namespaces": {
+			attachments: []v1alpha1.GenericControllerAttachment{
+				newTestAttachment("v1", "*", "default"),
+			},
+			expect: []v1alpha1.GenericControllerAttachment{
+				newTestAttachment("v1", "pods", "default"),
+			},
+		},
+		"undiscovered api group": {
+			attachments: []v1alpha1.GenericControllerAttachment{
+				newTestAttachment("test.io/*", "*"),
+			},
+		},
+		"undiscovered resource": {
+			attachments: []v1alpha1.GenericControllerAttachment{
+				newTestAttachment("apps/*", "daemonsets"),
+			},
+		},
+	}
+	resourceMgr, stop := newFakeResourceManager(t, newWildcardTestResources()...)
+	defer stop()
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			got := expandWildcardAttachments(
+				resourceMgr, newWildcardTestController(mock.attachments...),
+			)
+			if !reflect.DeepEqual(got, mock.expect) {
+				t.Fatalf("Expected %+v got %+v", mock.expect, got)
+			}
+		})
+	}
+}
+
+func TestIsWildcardExpansionStale(t *testing.T) {
+	var tests = map[string]struct {
+		declared []v1alpha1.GenericControllerAttachment
+		expanded []v1alpha1.GenericControllerAttachment
+		expect   bool
+	}{
+		"no wildcard attachments": {
+			declared: []v1alpha1.GenericControllerAttachment{
+				newTestAttachment("v1", "pods"),
+			},
+			expect: false,
+		},
+		"same expansion": {
+			declared: []v1alpha1.GenericControllerAttachment{
+				newTestAttachment("apps/*", "*"),
+			},
+			expanded: []v1alpha1.GenericControllerAttachment{
+				newTestAttachment("apps/v1", "deployments"),
+				newTestAttachment("apps/v1", "statefulsets"),
+			},
+			expect: false,
+		},
+		"resource got discovered": {
+			declared: []v1alpha1.GenericControllerAttachment{
+				newTestAttachment("apps/*", "*"),
+			},
+			expanded: []v1alpha1.GenericControllerAttachment{
+				newTestAttachment("apps/v1", "deployments"),
+			},
+			expect: true,
+		},
+		"resource got removed": {
+			declared: []v1alpha1.GenericControllerAttachment{
+				newTestAttachment("apps/*", "*"),
+			},
+			expanded: []v1alpha1.GenericControllerAttachment{
+				newTestAttachment("apps/v1", "daemonsets"),
+				newTestAttachment("apps/v1", "deployments"),
+				newTestAttachment("apps/v1", "statefulsets"),
+			},
+			expect: true,
+		},
+		"preferred version got changed": {
+			declared: []v1alpha1.GenericControllerAttachment{
+				newTestAttachment("apps/*", "deployments"),
+			},
+			expanded: []v1alpha1.GenericControllerAttachment{
+				newTestAttachment("apps/v1beta1", "deployments"),
+			},
+			expect: true,
+		},
+	}
+	resourceMgr, stop := newFakeResourceManager(t, newWildcardTestResources()...)
+	defer stop()
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			got := isWildcardExpansionStale(
+				resourceMgr,
+				newWildcardTestController(mock.declared...),
+				newWildcardTestController(mock.expanded...),
+			)
+			if got != mock.expect {
+				t.Fatalf("Expected stale %t got %t", mock.expect, got)
+			}
+		})
+	}
+}
@@ -138,19 +138,59 @@ func GetLastAppliedByAnnKey(
 // Merge updates the given observed object to apply the desired changes.
 // It returns an updated copy of the observed object if no error occurs.
 func Merge(observed, lastApplied, desired map[string]interface{}) (map[string]interface{}, error) {
+	return MergeWithListMapKeys(observed, lastApplied, desired, nil)
+}
+
+// MergeWithListMapKeys is similar to Merge, except that the lists found
+// at the given paths are merged as list maps by the given keys. Paths
+// are dot separated field names e.g. "spec.volumes". List items are not
+// part of these paths e.g. "spec.containers.ports" refers to the ports
+// of each container.
+//
+// NOTE:
+//	This is useful for custom resources that lack strategic merge
+// metadata & whose lists can't be guessed as list maps. Such lists get
+// replaced otherwise.
+func MergeWithListMapKeys(
+	observed, lastApplied, desired map[string]interface{},
+	listMapKeys map[string]string,
+) (map[string]interface{}, error) {
 	// Make a copy of observed since merge() mutates the destination.
 	destination := runtime.DeepCopyJSON(observed)
 
-	if _, err := merge("", destination, lastApplied, desired); err != nil {
+	m := &merger{listMapKeys: listMapKeys}
+	if _, err := m.merge("", "", destination, lastApplied, desired); err != nil {
 		return nil, errors.Wrapf(err, "Can't merge desired changes")
 	}
 	return destination, nil
 }
 
+// merger merges the desired changes into the destination
+type merger struct {
+	// merge keys of the list fields anchored by the dot separated
+	// paths of these fields
+	listMapKeys map[string]string
+}
+
+// joinPath returns the dot separated path of the given field
+func joinPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
 // merge finds the diff from lastApplied to desired,
 // and applies it to destination, returning the replacement
 // destination value.
-func merge(fieldPath string, destination, lastApplied, desired interface{}) (interface{}, error) {
+//
+// NOTE:
+//	fieldPath is used to log & report errors. It includes the merge
+// keys of list items. path is the dot separated path of the field
+// without these merge keys.
+func (m *merger) merge(
+	fieldPath, path string, destination, lastApplied, desired interface{},
+) (interface{}, error) {
 	glog.V(7).Infof("Will try merge for field %q", fieldPath)
 
 	switch destVal := destination.(type) {
@@ -173,7 +213,7 @@ func merge(fieldPath string, destination, lastApplied, desired interface{}) (int
 					fieldPath, desired,
 				)
 		}
-		return mergeObject(fieldPath, destVal, lastVal, desVal)
+		return m.mergeObject(fieldPath, path, false, destVal, lastVal, desVal)
 	case []interface{}:
 		// destination is an array.
 		// Make sure the others are arrays too (or null).
@@ -193,7 +233,7 @@ func merge(fieldPath string, destination, lastApplied, desired interface{}) (int
 					fieldPath, desired,
 				)
 		}
-		return mergeArray(fieldPath, destVal, lastVal, desVal)
+		return m.mergeArray(fieldPath, path, destVal, lastVal, desVal)
 	default:
 		// destination is a scalar or null.
 		// Just take the desired value. We won't be called if there's none.
@@ -201,7 +241,14 @@ func merge(fieldPath string, destination, lastApplied, desired interface{}) (int
 	}
 }
 
-func mergeObject(fieldPath string, destination, lastApplied, desired map[string]interface{}) (interface{}, error) {
+// mergeObject merges the fields of the given objects. If isListMap is
+// true, the given objects represent list maps keyed by the merge keys
+// of their items. The path of these items is the path of the list.
+func (m *merger) mergeObject(
+	fieldPath, path string,
+	isListMap bool,
+	destination, lastApplied, desired map[string]interface{},
+) (interface{}, error) {
 	glog.V(7).Infof("Will try merge object for field %q", fieldPath)
 
 	// Remove fields that were present in lastApplied, but no longer in desired.
@@ -215,7 +262,17 @@ func mergeObject(fieldPath string, destination, lastApplied, desired map[string]
 	// Add/Update all fields present in desired.
 	var err error
 	for key, desVal := range desired {
-		destination[key], err = merge(fmt.Sprintf("%s[%s]", fieldPath, key), destination[key], lastApplied[key], desVal)
+		keyPath := path
+		if !isListMap {
+			keyPath = joinPath(path, key)
+		}
+		destination[key], err = m.merge(
+			fmt.Sprintf("%s[%s]", fieldPath, key),
+			keyPath,
+			destination[key],
+			lastApplied[key],
+			desVal,
+		)
 		if err != nil {
 			return nil, err
 		}
@@ -224,12 +281,26 @@ func mergeObject(fieldPath string, destination, lastApplied, desired map[string]
 	return destination, nil
 }
 
-func mergeArray(fieldPath string, destination, lastApplied, desired []interface{}) (interface{}, error) {
+func (m *merger) mergeArray(
+	fieldPath, path string, destination, lastApplied, desired []interface{},
+) (interface{}, error) {
 	glog.V(7).Infof("Will try merge array for field %q", fieldPath)
 
+	// Use the merge key that is declared for this field if any
+	if mergeKey, found := m.listMapKeys[path]; found {
+		if hasListMapKey(mergeKey, destination, lastApplied, desired) {
+			return m.mergeListMap(fieldPath, path, mergeKey, destination, lastApplied, desired)
+		}
+		glog.V(4).Infof(
+			"%s merge operation: Will replace list: Items don't have merge key %q",
+			fieldPath, mergeKey,
+		)
+		return desired, nil
+	}
+
 	// If it looks like a list map, use the special merge.
 	if mergeKey := detectListMapKey(destination, lastApplied, desired); mergeKey != "" {
-		return mergeListMap(fieldPath, mergeKey, destination, lastApplied, desired)
+		return m.mergeListMap(fieldPath, path, mergeKey, destination, lastApplied, desired)
 	}
 
 	// It's a normal array. Just replace for now.
@@ -237,13 +308,15 @@ func mergeArray(fieldPath string, destination, lastApplied, desired []interface{
 	return desired, nil
 }
 
-func mergeListMap(fieldPath, mergeKey string, destination, lastApplied, desired []interface{}) (interface{}, error) {
+func (m *merger) mergeListMap(
+	fieldPath, path, mergeKey string, destination, lastApplied, desired []interface{},
+) (interface{}, error) {
 	// Treat each list of objects as if it were a map, keyed by the mergeKey field.
 	destMap := makeListMap(mergeKey, destination)
 	lastMap := makeListMap(mergeKey, lastApplied)
 	desMap := makeListMap(mergeKey, desired)
 
-	_, err := mergeObject(fieldPath, destMap, lastMap, desMap)
+	_, err := m.mergeObject(fieldPath, path, true, destMap, lastMap, desMap)
 	if err != nil {
 		return nil, err
 	}
@@ -272,11 +345,28 @@ func mergeListMap(fieldPath, mergeKey string, destination, lastApplied, desired
 	return destList, nil
 }
 
+// hasListMapKey returns true if all the items of the given lists are
+// objects having the given merge key
+func hasListMapKey(mergeKey string, lists ...[]interface{}) bool {
+	for _, list := range lists {
+		for _, item := range list {
+			obj, ok := item.(map[string]interface{})
+			if !ok {
+				return false
+			}
+			if _, found := obj[mergeKey]; !found {
+				return false
+			}
+		}
+	}
+	return true
+}
+
 func makeListMap(mergeKey string, list []interface{}) map[string]interface{} {
 	res := make(map[string]interface{}, len(list))
 	for _, item := range list {
-		// We only end up here if detectListMapKey() already verified that
-		// all items are objects.
+		// We only end up here if detectListMapKey() or hasListMapKey()
+		// already verified that all items are objects.
 		itemMap := item.(map[string]interface{})
 		res[stringMergeKey(itemMap[mergeKey])] = item
 	}
@@ -843,6 +843,88 @@ func TestMerge(t *testing.T) {
 	}
 }
 
+func TestMergeWithListMapKeys(t *testing.T) {
+	table := []struct {
+		name, observed, lastApplied, desired, want string
+		listMapKeys                                map[string]string
+	}{
+		{
+			name:        "declared merge key",
+			observed:    `{"spec": {"volumes": [{"id": "1", "size": "old", "x": "other"}, {"id": "3"}]}}`,
+			lastApplied: `{"spec": {"volumes": [{"id": "1", "size": "old"}]}}`,
+			desired:     `{"spec": {"volumes": [{"id": "1", "size": "new"}, {"id": "2"}]}}`,
+			want:        `{"spec": {"volumes": [{"id": "1", "size": "new", "x": "other"}, {"id": "3"}, {"id": "2"}]}}`,
+			listMapKeys: map[string]string{"spec.volumes": "id"},
+		},
+		{
+			name:        "declared merge key of nested list",
+			observed:    `{"spec": {"disks": [{"name": "a", "parts": [{"id": "1", "x": "other"}]}]}}`,
+			lastApplied: `{"spec": {"disks": [{"name": "a", "parts": [{"id": "1"}]}]}}`,
+			desired:     `{"spec": {"disks": [{"name": "a", "parts": [{"id": "1", "y": "new"}]}]}}`,
+			want:        `{"spec": {"disks": [{"name": "a", "parts": [{"id": "1", "x": "other", "y": "new"}]}]}}`,
+			listMapKeys: map[string]string{"spec.disks.parts": "id"},
+		},
+		{
+			name:        "declared merge key overrides detected key",
+			observed:    `{"spec": {"items": [{"name": "a", "id": "1", "x": "other"}]}}`,
+			lastApplied: `{}`,
+			desired:     `{"spec": {"items": [{"name": "b", "id": "1"}]}}`,
+			want:        `{"spec": {"items": [{"name": "b", "id": "1", "x": "other"}]}}`,
+			listMapKeys: map[string]string{"spec.items": "id"},
+		},
+		{
+			name:        "items without declared merge key",
+			observed:    `{"spec": {"volumes": [{"id": "1", "x": "other"}]}}`,
+			lastApplied: `{}`,
+			desired:     `{"spec": {"volumes": [{"size": "new"}]}}`,
+			want:        `{"spec": {"volumes": [{"size": "new"}]}}`,
+			listMapKeys: map[string]string{"spec.volumes": "id"},
+		},
+		{
+			name:        "list not declared",
+			observed:    `{"spec": {"volumes": [{"id": "1", "x": "other"}]}}`,
+			lastApplied: `{}`,
+			desired:     `{"spec": {"volumes": [{"id": "1"}]}}`,
+			want:        `{"spec": {"volumes": [{"id": "1"}]}}`,
+			listMapKeys: map[string]string{"spec.other": "id"},
+		},
+	}
+
+	for _, tc := range table {
+		observed := make(map[string]interface{})
+		if err := json.Unmarshal([]byte(tc.observed), &observed); err != nil {
+			t.Errorf("%v: can't unmarshal tc.observed: %v", tc.name, err)
+			continue
+		}
+		lastApplied := make(map[string]interface{})
+		if err := json.Unmarshal([]byte(tc.lastApplied), &lastApplied); err != nil {
+			t.Errorf("%v: can't unmarshal tc.lastApplied: %v", tc.name, err)
+			continue
+		}
+		desired := make(map[string]interface{})
+		if err := json.Unmarshal([]byte(tc.desired), &desired); err != nil {
+			t.Errorf("%v: can't unmarshal tc.desired: %v", tc.name, err)
+			continue
+		}
+		want := make(map[string]interface{})
+		if err := json.Unmarshal([]byte(tc.want), &want); err != nil {
+			t.Errorf("%v: can't unmarshal tc.want: %v", tc.name, err)
+			continue
+		}
+
+		got, err := MergeWithListMapKeys(observed, lastApplied, desired, tc.listMapKeys)
+		if err != nil {
+			t.Errorf("%v: MergeWithListMapKeys error: %v", tc.name, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Logf("reflect diff: a=got, b=want:\n%s", diff.ObjectReflectDiff(got, want))
+			t.Errorf("%v: MergeWithListMapKeys() = %#v, want %#v", tc.name, got, want)
+		}
+	}
+}
+
 func TestLastAppliedAnnotation(t *testing.T) {
 	// Round-trip some JSON through Set/Get methods.
 	inJSON := `{
@@ -0,0 +1,127 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apply
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ComputeConflicts returns the paths of the desired fields whose
+// observed values were changed by someone else since these were last
+// applied. Paths are sorted.
+//
+// NOTE:
+//	Fields that were never applied are not conflicts. Lists are
+// compared as a whole.
+func ComputeConflicts(observed, lastApplied, desired map[string]interface{}) [][]string {
+	var conflicts [][]string
+	conflicts = computeConflicts(nil, observed, lastApplied, desired, conflicts)
+	sortPaths(conflicts)
+	return conflicts
+}
+
+// computeConflicts appends the conflicting fields found at the given
+// path to the given conflicts
+func computeConflicts(
+	path []string, observed, lastApplied, desired map[string]interface{}, conflicts [][]string,
+) [][]string {
+	for key, desiredVal := range desired {
+		lastAppliedVal, found := lastApplied[key]
+		if !found {
+			continue
+		}
+		fieldPath := appendPath(path, key)
+		observedVal, found := observed[key]
+		if !found {
+			// field was removed by someone else
+			conflicts = append(conflicts, fieldPath)
+			continue
+		}
+		desiredMap, dOK := desiredVal.(map[string]interface{})
+		lastAppliedMap, lOK := lastAppliedVal.(map[string]interface{})
+		observedMap, oOK := observedVal.(map[string]interface{})
+		if dOK && lOK && oOK {
+			conflicts = computeConflicts(
+				fieldPath, observedMap, lastAppliedMap, desiredMap, conflicts,
+			)
+			continue
+		}
+		if !reflect.DeepEqual(observedVal, lastAppliedVal) &&
+			!reflect.DeepEqual(observedVal, desiredVal) {
+			conflicts = append(conflicts, fieldPath)
+		}
+	}
+	return conflicts
+}
+
+// ComputeOwnedConflicts returns the paths of the desired fields that
+// differ from their observed values & are part of the given owned
+// state. Owned state is typically the state managed by other field
+// managers. Paths are sorted.
+//
+// NOTE:
+//	Lists are compared as a whole.
+func ComputeOwnedConflicts(observed, owned, desired map[string]interface{}) [][]string {
+	var conflicts [][]string
+	conflicts = computeOwnedConflicts(nil, observed, owned, desired, conflicts)
+	sortPaths(conflicts)
+	return conflicts
+}
+
+// computeOwnedConflicts appends the conflicting fields found at the
+// given path to the given conflicts
+func computeOwnedConflicts(
+	path []string, observed, owned, desired map[string]interface{}, conflicts [][]string,
+) [][]string {
+	for key, desiredVal := range desired {
+		ownedVal, found := owned[key]
+		if !found {
+			continue
+		}
+		fieldPath := appendPath(path, key)
+		observedVal := observed[key]
+		desiredMap, dOK := desiredVal.(map[string]interface{})
+		ownedMap, wOK := ownedVal.(map[string]interface{})
+		observedMap, oOK := observedVal.(map[string]interface{})
+		if dOK && wOK && oOK {
+			conflicts = computeOwnedConflicts(
+				fieldPath, observedMap, ownedMap, desiredMap, conflicts,
+			)
+			continue
+		}
+		if !reflect.DeepEqual(observedVal, desiredVal) {
+			conflicts = append(conflicts, fieldPath)
+		}
+	}
+	return conflicts
+}
+
+// appendPath returns a new path made of the given path & key
+func appendPath(path []string, key string) []string {
+	newPath := make([]string, 0, len(path)+1)
+	newPath = append(newPath, path...)
+	return append(newPath, key)
+}
+
+// sortPaths sorts the given paths by their dot separated form
+func sortPaths(paths [][]string) {
+	sort.Slice(paths, func(i, j int) bool {
+		return strings.Join(paths[i], ".") < strings.Join(paths[j], ".")
+	})
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apply
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+func TestComputeConflicts(t *testing.T) {
+	table := []struct {
+		name                           string
+		observed, lastApplied, desired string
+		want                           [][]string
+	}{
+		{
+			name:        "no changes by others",
+			observed:    `{"spec": {"a": "1", "b": "1"}}`,
+			lastApplied: `{"spec": {"a": "1"}}`,
+			desired:     `{"spec": {"a": "2"}}`,
+		},
+		{
+			name:        "never applied",
+			observed:    `{"spec": {"a": "1"}}`,
+			lastApplied: `{}`,
+			desired:     `{"spec": {"a": "2"}}`,
+		},
+		{
+			name:        "changed by others",
+			observed:    `{"spec": {"a": "3", "b": "3"}}`,
+			lastApplied: `{"spec": {"a": "1", "b": "1"}}`,
+			desired:     `{"spec": {"a": "1", "b": "3"}}`,
+			want:        [][]string{{"spec", "a"}},
+		},
+		{
+			name:        "removed by others",
+			observed:    `{"spec": {}}`,
+			lastApplied: `{"spec": {"a": "1"}}`,
+			desired:     `{"spec": {"a": "1"}}`,
+			want:        [][]string{{"spec", "a"}},
+		},
+		{
+			name:        "lists",
+			observed:    `{"spec": {"items": [1, 3]}}`,
+			lastApplied: `{"spec": {"items": [1, 2]}}`,
+			desired:     `{"spec": {"items": [1, 2]}}`,
+			want:        [][]string{{"spec", "items"}},
+		},
+	}
+	for _, tc := range table {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got := ComputeConflicts(
+				mustUnmarshal(t, tc.observed),
+				mustUnmarshal(t, tc.lastApplied),
+				mustUnmarshal(t, tc.desired),
+			)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("Expected %v got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestComputeOwnedConflicts(t *testing.T) {
+	table := []struct {
+		name                     string
+		observed, owned, desired string
+		want                     [][]string
+	}{
+		{
+			name:     "not owned by others",
+			observed: `{"spec": {"a": "1"}}`,
+			owned:    `{"spec": {"b": "1"}}`,
+			desired:  `{"spec": {"a": "2"}}`,
+		},
+		{
+			name:     "owned by others with same value",
+			observed: `{"spec": {"a": "1"}}`,
+			owned:    `{"spec": {"a": "1"}}`,
+			desired:  `{"spec": {"a": "1"}}`,
+		},
+		{
+			name:     "owned by others with different value",
+			observed: `{"metadata": {"labels": {"a": "1", "b": "1"}}}`,
+			owned:    `{"metadata": {"labels": {"a": "1", "b": "1"}}}`,
+			desired:  `{"metadata": {"labels": {"b": "2", "a": "2"}}}`,
+			want:     [][]string{{"metadata", "labels", "a"}, {"metadata", "labels", "b"}},
+		},
+	}
+	for _, tc := range table {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got := ComputeOwnedConflicts(
+				mustUnmarshal(t, tc.observed),
+				mustUnmarshal(t, tc.owned),
+				mustUnmarshal(t, tc.desired),
+			)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("Expected %v got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func mustUnmarshal(t *testing.T, data string) map[string]interface{} {
+	obj := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(data), &obj); err != nil {
+		t.Fatalf("Can't unmarshal %s: %v", data, err)
+	}
+	return obj
+}
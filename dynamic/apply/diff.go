@@ -0,0 +1,127 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apply
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// FieldDiffOperation represents the change to a field
+type FieldDiffOperation string
+
+const (
+	// FieldDiffAdd implies the field is added
+	FieldDiffAdd FieldDiffOperation = "Add"
+
+	// FieldDiffRemove implies the field is removed
+	FieldDiffRemove FieldDiffOperation = "Remove"
+
+	// FieldDiffReplace implies the value of the field is changed
+	FieldDiffReplace FieldDiffOperation = "Replace"
+)
+
+// FieldDiff represents a field whose value differs between two
+// states of an object
+//
+// NOTE:
+//	Values are not part of the diff since these may be sensitive
+// e.g. data of secrets
+type FieldDiff struct {
+	// Path is the dot separated path of the field. List items are
+	// referred to by their index e.g. spec.containers[0].image
+	Path string `json:"path"`
+
+	// Operation is the change to this field
+	Operation FieldDiffOperation `json:"operation"`
+}
+
+// String implements Stringer interface
+func (d FieldDiff) String() string {
+	return fmt.Sprintf("%s %s", d.Operation, d.Path)
+}
+
+// ComputeFieldDiffs returns the fields that differ between the given
+// states. Diffs are sorted by their paths.
+//
+// NOTE:
+//	Lists whose lengths differ are reported as replaced as a whole
+func ComputeFieldDiffs(from, to map[string]interface{}) []FieldDiff {
+	var diffs []FieldDiff
+	diffs = computeFieldDiffs("", from, to, diffs)
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].Path < diffs[j].Path
+	})
+	return diffs
+}
+
+// computeFieldDiffs appends the diffs of the field at the given path
+// to the given diffs
+func computeFieldDiffs(path string, from, to interface{}, diffs []FieldDiff) []FieldDiff {
+	switch fromVal := from.(type) {
+	case map[string]interface{}:
+		toVal, ok := to.(map[string]interface{})
+		if !ok {
+			break
+		}
+		for key, fromField := range fromVal {
+			toField, found := toVal[key]
+			if !found {
+				diffs = append(diffs, FieldDiff{joinPath(path, key), FieldDiffRemove})
+				continue
+			}
+			diffs = computeFieldDiffs(joinPath(path, key), fromField, toField, diffs)
+		}
+		for key := range toVal {
+			if _, found := fromVal[key]; !found {
+				diffs = append(diffs, FieldDiff{joinPath(path, key), FieldDiffAdd})
+			}
+		}
+		return diffs
+	case []interface{}:
+		toVal, ok := to.([]interface{})
+		if !ok || len(fromVal) != len(toVal) {
+			break
+		}
+		for idx := range fromVal {
+			diffs = computeFieldDiffs(
+				fmt.Sprintf("%s[%d]", path, idx), fromVal[idx], toVal[idx], diffs,
+			)
+		}
+		return diffs
+	}
+	if !reflect.DeepEqual(from, to) {
+		diffs = append(diffs, FieldDiff{path, FieldDiffReplace})
+	}
+	return diffs
+}
+
+// JoinFieldDiffs returns the given diffs as a single line. At most
+// the given number of diffs are included.
+func JoinFieldDiffs(diffs []FieldDiff, max int) string {
+	strs := make([]string, 0, len(diffs))
+	for idx, diff := range diffs {
+		if max > 0 && idx == max {
+			strs = append(strs, fmt.Sprintf("and %d more", len(diffs)-max))
+			break
+		}
+		strs = append(strs, diff.String())
+	}
+	return strings.Join(strs, ", ")
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apply
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+func TestComputeFieldDiffs(t *testing.T) {
+	table := []struct {
+		name, from, to string
+		want           []FieldDiff
+	}{
+		{
+			name: "no changes",
+			from: `{"spec": {"a": "1", "b": [1, 2]}}`,
+			to:   `{"spec": {"a": "1", "b": [1, 2]}}`,
+		},
+		{
+			name: "scalars",
+			from: `{"spec": {"a": "1", "b": "1"}}`,
+			to:   `{"spec": {"a": "2", "c": "1"}}`,
+			want: []FieldDiff{
+				{"spec.a", FieldDiffReplace},
+				{"spec.b", FieldDiffRemove},
+				{"spec.c", FieldDiffAdd},
+			},
+		},
+		{
+			name: "list items",
+			from: `{"spec": {"containers": [{"name": "a", "image": "old"}]}}`,
+			to:   `{"spec": {"containers": [{"name": "a", "image": "new"}]}}`,
+			want: []FieldDiff{
+				{"spec.containers[0].image", FieldDiffReplace},
+			},
+		},
+		{
+			name: "list length",
+			from: `{"spec": {"items": [1]}}`,
+			to:   `{"spec": {"items": [1, 2]}}`,
+			want: []FieldDiff{
+				{"spec.items", FieldDiffReplace},
+			},
+		},
+		{
+			name: "type change",
+			from: `{"spec": {"a": {"b": "1"}}}`,
+			to:   `{"spec": {"a": "1"}}`,
+			want: []FieldDiff{
+				{"spec.a", FieldDiffReplace},
+			},
+		},
+	}
+	for _, tc := range table {
+		from := make(map[string]interface{})
+		if err := json.Unmarshal([]byte(tc.from), &from); err != nil {
+			t.Fatalf("%v: can't unmarshal tc.from: %v", tc.name, err)
+		}
+		to := make(map[string]interface{})
+		if err := json.Unmarshal([]byte(tc.to), &to); err != nil {
+			t.Fatalf("%v: can't unmarshal tc.to: %v", tc.name, err)
+		}
+		got := ComputeFieldDiffs(from, to)
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%v: ComputeFieldDiffs() = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestJoinFieldDiffs(t *testing.T) {
+	diffs := []FieldDiff{
+		{"spec.a", FieldDiffAdd},
+		{"spec.b", FieldDiffRemove},
+		{"spec.c", FieldDiffReplace},
+	}
+	if got, want := JoinFieldDiffs(diffs, 0), "Add spec.a, Remove spec.b, Replace spec.c"; got != want {
+		t.Errorf("JoinFieldDiffs() = %q, want %q", got, want)
+	}
+	if got, want := JoinFieldDiffs(diffs, 2), "Add spec.a, Remove spec.b, and 1 more"; got != want {
+		t.Errorf("JoinFieldDiffs() = %q, want %q", got, want)
+	}
+}
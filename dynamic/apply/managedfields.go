@@ -0,0 +1,261 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apply
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+const (
+	// fieldsV1 is the only format of managed fields understood here
+	fieldsV1 = "FieldsV1"
+
+	// prefixes of the path elements of managed fields
+	fieldsFieldPrefix = "f:"
+	fieldsKeyPrefix   = "k:"
+	fieldsValuePrefix = "v:"
+	fieldsIndexPrefix = "i:"
+)
+
+// GetManagedFieldsState returns the state of the given object that
+// is managed by the given field manager. This consists of the fields
+// of the object that are owned by this manager as per the object's
+// metadata.managedFields.
+//
+// NOTE:
+//	This can be used as the last applied state of a 3-way merge. Nil
+// is returned if the manager does not own any field of the object.
+func GetManagedFieldsState(
+	obj *unstructured.Unstructured, manager string,
+) (map[string]interface{}, error) {
+	return getManagedFieldsState(obj, func(m string) bool {
+		return m == manager
+	})
+}
+
+// GetOthersManagedFieldsState returns the state of the given object
+// that is managed by field managers other than the given manager
+func GetOthersManagedFieldsState(
+	obj *unstructured.Unstructured, manager string,
+) (map[string]interface{}, error) {
+	return getManagedFieldsState(obj, func(m string) bool {
+		return m != manager
+	})
+}
+
+// getManagedFieldsState returns the state of the given object that
+// is managed by the field managers matched by the given function
+func getManagedFieldsState(
+	obj *unstructured.Unstructured, isManager func(manager string) bool,
+) (map[string]interface{}, error) {
+	var state map[string]interface{}
+	for _, entry := range obj.GetManagedFields() {
+		if !isManager(entry.Manager) || entry.FieldsV1 == nil {
+			continue
+		}
+		if entry.FieldsType != fieldsV1 {
+			return nil, errors.Errorf(
+				"%s:%s:%s:%s: Unsupported managed fields type %q of manager %q",
+				obj.GetAPIVersion(),
+				obj.GetKind(),
+				obj.GetNamespace(),
+				obj.GetName(),
+				entry.FieldsType,
+				entry.Manager,
+			)
+		}
+		fields := make(map[string]interface{})
+		err := json.Unmarshal(entry.FieldsV1.Raw, &fields)
+		if err != nil {
+			return nil, errors.Wrapf(
+				err,
+				"%s:%s:%s:%s: Failed to unmarshal managed fields of manager %q",
+				obj.GetAPIVersion(),
+				obj.GetKind(),
+				obj.GetNamespace(),
+				obj.GetName(),
+				entry.Manager,
+			)
+		}
+		projected, _ := projectMap(obj.UnstructuredContent(), fields).(map[string]interface{})
+		state = unionState(state, projected)
+	}
+	return state, nil
+}
+
+// projectValue returns the parts of the given value that are set in
+// the given managed fields
+func projectValue(value interface{}, fields map[string]interface{}) interface{} {
+	if isLeafFields(fields) {
+		return value
+	}
+	switch val := value.(type) {
+	case map[string]interface{}:
+		return projectMap(val, fields)
+	case []interface{}:
+		return projectList(val, fields)
+	default:
+		return value
+	}
+}
+
+// isLeafFields returns true if the given managed fields refer to the
+// value as a whole
+func isLeafFields(fields map[string]interface{}) bool {
+	for key := range fields {
+		if key != "." {
+			return false
+		}
+	}
+	return true
+}
+
+// projectMap returns the fields of the given object that are set in
+// the given managed fields
+func projectMap(obj map[string]interface{}, fields map[string]interface{}) interface{} {
+	projected := make(map[string]interface{})
+	for key, sub := range fields {
+		if !strings.HasPrefix(key, fieldsFieldPrefix) {
+			continue
+		}
+		name := strings.TrimPrefix(key, fieldsFieldPrefix)
+		value, found := obj[name]
+		if !found {
+			continue
+		}
+		subFields, _ := sub.(map[string]interface{})
+		projected[name] = projectValue(value, subFields)
+	}
+	return projected
+}
+
+// projectList returns the items of the given list that are set in
+// the given managed fields. Items are returned in the order of the
+// given list.
+func projectList(list []interface{}, fields map[string]interface{}) interface{} {
+	projected := make([]interface{}, 0, len(list))
+	for idx, item := range list {
+		for key, sub := range fields {
+			subFields, _ := sub.(map[string]interface{})
+			if !isListItemMatch(idx, item, key) {
+				continue
+			}
+			value := projectValue(item, subFields)
+			if strings.HasPrefix(key, fieldsKeyPrefix) {
+				// merge keys are retained to let the merge
+				// identify this item
+				value = withListItemKeys(item, value, key)
+			}
+			projected = append(projected, value)
+			break
+		}
+	}
+	return projected
+}
+
+// isListItemMatch returns true if the given list item at the given
+// index is referred to by the given managed fields key
+func isListItemMatch(idx int, item interface{}, key string) bool {
+	switch {
+	case strings.HasPrefix(key, fieldsKeyPrefix):
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		keys := make(map[string]interface{})
+		err := json.Unmarshal([]byte(strings.TrimPrefix(key, fieldsKeyPrefix)), &keys)
+		if err != nil {
+			return false
+		}
+		for name, want := range keys {
+			if !reflect.DeepEqual(obj[name], want) {
+				return false
+			}
+		}
+		return true
+	case strings.HasPrefix(key, fieldsValuePrefix):
+		var want interface{}
+		err := json.Unmarshal([]byte(strings.TrimPrefix(key, fieldsValuePrefix)), &want)
+		if err != nil {
+			return false
+		}
+		return reflect.DeepEqual(item, want)
+	case strings.HasPrefix(key, fieldsIndexPrefix):
+		want, err := strconv.Atoi(strings.TrimPrefix(key, fieldsIndexPrefix))
+		return err == nil && want == idx
+	default:
+		return false
+	}
+}
+
+// withListItemKeys sets the merge keys of the given item that are
+// referred to by the given managed fields key against the given
+// projected item
+func withListItemKeys(item, projected interface{}, key string) interface{} {
+	itemObj, ok := item.(map[string]interface{})
+	if !ok {
+		return projected
+	}
+	projectedObj, ok := projected.(map[string]interface{})
+	if !ok {
+		return projected
+	}
+	keys := make(map[string]interface{})
+	err := json.Unmarshal([]byte(strings.TrimPrefix(key, fieldsKeyPrefix)), &keys)
+	if err != nil {
+		return projected
+	}
+	for name := range keys {
+		projectedObj[name] = itemObj[name]
+	}
+	return projectedObj
+}
+
+// unionState merges the given states that are projected from the
+// same object by different managed fields entries
+func unionState(a, b map[string]interface{}) map[string]interface{} {
+	if a == nil {
+		return b
+	}
+	for key, bVal := range b {
+		aVal, found := a[key]
+		if !found {
+			a[key] = bVal
+			continue
+		}
+		aMap, aOK := aVal.(map[string]interface{})
+		bMap, bOK := bVal.(map[string]interface{})
+		if aOK && bOK {
+			a[key] = unionState(aMap, bMap)
+			continue
+		}
+		// lists & scalars are projected from the same object;
+		// the larger projection wins
+		if aList, ok := aVal.([]interface{}); ok {
+			if bList, ok := bVal.([]interface{}); ok && len(bList) > len(aList) {
+				a[key] = bVal
+			}
+		}
+	}
+	return a
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apply
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+func TestGetManagedFieldsState(t *testing.T) {
+	observed := `{
+		"metadata": {
+			"name": "test",
+			"labels": {"app": "test", "team": "other"},
+			"finalizers": ["a", "b"]
+		},
+		"spec": {
+			"replicas": 2,
+			"containers": [
+				{"name": "c1", "image": "c1:v1", "args": ["x"]},
+				{"name": "c2", "image": "c2:v1"}
+			]
+		}
+	}`
+	table := []struct {
+		name    string
+		entries []metav1.ManagedFieldsEntry
+		want    string
+		isErr   bool
+	}{
+		{
+			name: "no entries of this manager",
+			entries: []metav1.ManagedFieldsEntry{
+				makeManagedFieldsEntry("other", `{"f:spec": {"f:replicas": {}}}`),
+			},
+			want: `null`,
+		},
+		{
+			name: "maps & scalars",
+			entries: []metav1.ManagedFieldsEntry{
+				makeManagedFieldsEntry(
+					"test",
+					`{"f:metadata": {"f:labels": {".": {}, "f:app": {}}}, "f:spec": {"f:replicas": {}}}`,
+				),
+			},
+			want: `{"metadata": {"labels": {"app": "test"}}, "spec": {"replicas": 2}}`,
+		},
+		{
+			name: "list items by keys",
+			entries: []metav1.ManagedFieldsEntry{
+				makeManagedFieldsEntry(
+					"test",
+					`{"f:spec": {"f:containers": {"k:{\"name\":\"c2\"}": {".": {}, "f:image": {}}}}}`,
+				),
+			},
+			want: `{"spec": {"containers": [{"name": "c2", "image": "c2:v1"}]}}`,
+		},
+		{
+			name: "list items by values",
+			entries: []metav1.ManagedFieldsEntry{
+				makeManagedFieldsEntry(
+					"test",
+					`{"f:metadata": {"f:finalizers": {"v:\"b\"": {}}}}`,
+				),
+			},
+			want: `{"metadata": {"finalizers": ["b"]}}`,
+		},
+		{
+			name: "multiple entries",
+			entries: []metav1.ManagedFieldsEntry{
+				makeManagedFieldsEntry("test", `{"f:spec": {"f:replicas": {}}}`),
+				makeManagedFieldsEntry(
+					"test",
+					`{"f:spec": {"f:containers": {"k:{\"name\":\"c1\"}": {"f:args": {}}}}}`,
+				),
+			},
+			want: `{"spec": {"replicas": 2, "containers": [{"name": "c1", "args": ["x"]}]}}`,
+		},
+		{
+			name: "unsupported fields type",
+			entries: []metav1.ManagedFieldsEntry{
+				{
+					Manager:    "test",
+					FieldsType: "FieldsV2",
+					FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{}`)},
+				},
+			},
+			isErr: true,
+		},
+	}
+	for _, tc := range table {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			obj := &unstructured.Unstructured{}
+			if err := json.Unmarshal([]byte(observed), &obj.Object); err != nil {
+				t.Fatalf("Can't unmarshal observed: %v", err)
+			}
+			obj.SetManagedFields(tc.entries)
+
+			got, err := GetManagedFieldsState(obj, "test")
+			if tc.isErr {
+				if err == nil {
+					t.Fatalf("Expected error got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error got %v", err)
+			}
+			var want map[string]interface{}
+			if err := json.Unmarshal([]byte(tc.want), &want); err != nil {
+				t.Fatalf("Can't unmarshal want: %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("Expected %v got %v", want, got)
+			}
+		})
+	}
+}
+
+func makeManagedFieldsEntry(manager, fields string) metav1.ManagedFieldsEntry {
+	return metav1.ManagedFieldsEntry{
+		Manager:    manager,
+		Operation:  metav1.ManagedFieldsOperationUpdate,
+		FieldsType: "FieldsV1",
+		FieldsV1:   &metav1.FieldsV1{Raw: []byte(fields)},
+	}
+}
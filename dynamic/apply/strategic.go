@@ -0,0 +1,96 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apply
+
+import (
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// strategicMergeGroups are the api groups whose kinds are merged via
+// strategic merge patch
+var strategicMergeGroups = map[string]bool{
+	"":      true,
+	"apps":  true,
+	"batch": true,
+}
+
+// NewStrategicMergeSchema returns a new instance of the compiled-in
+// type of the given built-in kind. This type provides the patch
+// metadata e.g. merge keys needed for strategic merge. It returns
+// false if the given kind is not merged via strategic merge patch.
+func NewStrategicMergeSchema(apiVersion, kind string) (runtime.Object, bool) {
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil || !strategicMergeGroups[gv.Group] {
+		return nil, false
+	}
+	obj, err := scheme.Scheme.New(gv.WithKind(kind))
+	if err != nil {
+		return nil, false
+	}
+	return obj, true
+}
+
+// StrategicMerge updates the given observed object to apply the
+// desired changes in the style of kubectl apply for built-in kinds.
+// The given data struct is the compiled-in type of the object. It
+// returns an updated copy of the observed object if no error occurs.
+func StrategicMerge(
+	observed, lastApplied, desired map[string]interface{}, dataStruct interface{},
+) (map[string]interface{}, error) {
+	patchMeta, err := strategicpatch.NewPatchMetaFromStruct(dataStruct)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Can't get strategic merge patch metadata")
+	}
+	var original []byte
+	if len(lastApplied) != 0 {
+		original, err = json.Marshal(lastApplied)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Can't marshal last applied state")
+		}
+	}
+	modified, err := json.Marshal(desired)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Can't marshal desired state")
+	}
+	current, err := json.Marshal(observed)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Can't marshal observed state")
+	}
+	patch, err := strategicpatch.CreateThreeWayMergePatch(
+		original, modified, current, patchMeta, true,
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Can't create strategic merge patch")
+	}
+	mergedJSON, err := strategicpatch.StrategicMergePatchUsingLookupPatchMeta(
+		current, patch, patchMeta,
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Can't apply strategic merge patch")
+	}
+	merged := make(map[string]interface{})
+	err = json.Unmarshal(mergedJSON, &merged)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Can't unmarshal merged state")
+	}
+	return merged, nil
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apply
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewStrategicMergeSchema(t *testing.T) {
+	table := []struct {
+		apiVersion, kind string
+		want             bool
+	}{
+		{"v1", "Pod", true},
+		{"apps/v1", "Deployment", true},
+		{"batch/v1", "Job", true},
+		{"rbac.authorization.k8s.io/v1", "Role", false},
+		{"example.com/v1", "Pod", false},
+		{"apps/v1", "Unknown", false},
+	}
+	for _, tc := range table {
+		if _, got := NewStrategicMergeSchema(tc.apiVersion, tc.kind); got != tc.want {
+			t.Errorf("%s %s: Expected %t got %t", tc.apiVersion, tc.kind, tc.want, got)
+		}
+	}
+}
+
+func TestStrategicMerge(t *testing.T) {
+	table := []struct {
+		name                           string
+		observed, lastApplied, desired string
+		want                           string
+	}{
+		{
+			name: "containers are merged by name",
+			observed: `{"apiVersion": "v1", "kind": "Pod", "spec": {"containers": [
+				{"name": "sidecar", "image": "sidecar:v1"},
+				{"name": "app", "image": "app:v1", "imagePullPolicy": "Always"}
+			]}}`,
+			lastApplied: `{"apiVersion": "v1", "kind": "Pod", "spec": {"containers": [
+				{"name": "app", "image": "app:v1"}
+			]}}`,
+			desired: `{"apiVersion": "v1", "kind": "Pod", "spec": {"containers": [
+				{"name": "app", "image": "app:v2"}
+			]}}`,
+			want: `{"apiVersion": "v1", "kind": "Pod", "spec": {"containers": [
+				{"name": "sidecar", "image": "sidecar:v1"},
+				{"name": "app", "image": "app:v2", "imagePullPolicy": "Always"}
+			]}}`,
+		},
+		{
+			name: "containers that are no longer desired are removed",
+			observed: `{"apiVersion": "v1", "kind": "Pod", "spec": {"containers": [
+				{"name": "app", "image": "app:v1"},
+				{"name": "old", "image": "old:v1"}
+			]}}`,
+			lastApplied: `{"apiVersion": "v1", "kind": "Pod", "spec": {"containers": [
+				{"name": "app", "image": "app:v1"},
+				{"name": "old", "image": "old:v1"}
+			]}}`,
+			desired: `{"apiVersion": "v1", "kind": "Pod", "spec": {"containers": [
+				{"name": "app", "image": "app:v1"}
+			]}}`,
+			want: `{"apiVersion": "v1", "kind": "Pod", "spec": {"containers": [
+				{"name": "app", "image": "app:v1"}
+			]}}`,
+		},
+		{
+			name: "nothing is removed without last applied state",
+			observed: `{"apiVersion": "v1", "kind": "Pod", "metadata": {"labels": {"a": "1"}}, "spec": {"containers": [
+				{"name": "app", "image": "app:v1"}
+			]}}`,
+			lastApplied: `{}`,
+			desired:     `{"apiVersion": "v1", "kind": "Pod", "metadata": {"labels": {"b": "1"}}}`,
+			want: `{"apiVersion": "v1", "kind": "Pod", "metadata": {"labels": {"a": "1", "b": "1"}}, "spec": {"containers": [
+				{"name": "app", "image": "app:v1"}
+			]}}`,
+		},
+	}
+	for _, tc := range table {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			schema, ok := NewStrategicMergeSchema("v1", "Pod")
+			if !ok {
+				t.Fatalf("Expected schema of pod")
+			}
+			got, err := StrategicMerge(
+				mustUnmarshal(t, tc.observed),
+				mustUnmarshal(t, tc.lastApplied),
+				mustUnmarshal(t, tc.desired),
+				schema,
+			)
+			if err != nil {
+				t.Fatalf("Expected no error got %v", err)
+			}
+			if want := mustUnmarshal(t, tc.want); !reflect.DeepEqual(got, want) {
+				t.Fatalf("Expected %v got %v", want, got)
+			}
+		})
+	}
+}
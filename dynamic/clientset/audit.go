@@ -0,0 +1,331 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clientset
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/client-go/dynamic"
+
+	"openebs.io/metac/audit"
+	dynamicapply "openebs.io/metac/dynamic/apply"
+	dynamicdiscovery "openebs.io/metac/dynamic/discovery"
+)
+
+// maxAuditDiffFields is the maximum number of changed fields that are
+// listed in the diff summary of an audit entry
+const maxAuditDiffFields = 20
+
+// ForWatch returns a copy of this clientset whose writes are audited
+// as made by the sync of the given watch. The copy shares the clients
+// & rate limiters of this clientset.
+func (cs *Clientset) ForWatch(key string) *Clientset {
+	ccs := *cs
+	ccs.watch = key
+	return &ccs
+}
+
+// WithAuditDiff returns a copy of the ResourceClient whose writes are
+// audited with the given summary of the changed fields
+//
+// NOTE:
+//	This needs to be chained after Namespace() since the summary is
+// not retained by the namespaced copies. Writes of dry run clients are
+// not audited & hence these are returned as is.
+func (rc *ResourceClient) WithAuditDiff(diff string) *ResourceClient {
+	client, ok := rc.ResourceInterface.(*auditedResourceClient)
+	if !ok {
+		return rc
+	}
+	copy := *client
+	copy.diff = diff
+	return &ResourceClient{
+		ResourceInterface: &copy,
+		APIResource:       rc.APIResource,
+		rootClient:        rc.rootClient,
+	}
+}
+
+// AuditDiff returns the summary of the fields that differ between the
+// given states of an object. This is empty if auditing is not enabled.
+func AuditDiff(from, to *unstructured.Unstructured) string {
+	if !audit.IsEnabled() || from == nil || to == nil {
+		return ""
+	}
+	return dynamicapply.JoinFieldDiffs(
+		dynamicapply.ComputeFieldDiffs(
+			stripAuditFields(from.UnstructuredContent()),
+			stripAuditFields(to.UnstructuredContent()),
+		),
+		maxAuditDiffFields,
+	)
+}
+
+// stripAuditFields returns a copy of the given object without the
+// fields that are not changed by the writer
+func stripAuditFields(obj map[string]interface{}) map[string]interface{} {
+	c := (&unstructured.Unstructured{Object: obj}).DeepCopy()
+	c.SetResourceVersion("")
+	c.SetManagedFields(nil)
+	return c.UnstructuredContent()
+}
+
+// withAudit returns the given client with its writes audited as made
+// by the given controller & watch
+func withAudit(
+	client dynamic.NamespaceableResourceInterface,
+	apiResource *dynamicdiscovery.APIResource,
+	controller, watch string,
+) dynamic.NamespaceableResourceInterface {
+	return &auditedResourceClient{
+		ResourceInterface: client,
+		root:              client,
+		apiResource:       apiResource,
+		controller:        controller,
+		watch:             watch,
+	}
+}
+
+// auditedResourceClient records an audit entry for every create,
+// update, patch & delete request
+//
+// NOTE:
+//	Dry run requests are not audited since these change nothing
+type auditedResourceClient struct {
+	dynamic.ResourceInterface
+
+	root        dynamic.NamespaceableResourceInterface
+	apiResource *dynamicdiscovery.APIResource
+
+	controller string
+	watch      string
+	namespace  string
+
+	// summary of the fields changed by the writes if known
+	diff string
+}
+
+// auditedResourceClient implements dynamic.NamespaceableResourceInterface
+var _ dynamic.NamespaceableResourceInterface = &auditedResourceClient{}
+
+// Namespace returns the client scoped to the given namespace
+func (c *auditedResourceClient) Namespace(namespace string) dynamic.ResourceInterface {
+	return &auditedResourceClient{
+		ResourceInterface: c.root.Namespace(namespace),
+		root:              c.root,
+		apiResource:       c.apiResource,
+		controller:        c.controller,
+		watch:             c.watch,
+		namespace:         namespace,
+	}
+}
+
+// record records the audit entry of the given write
+func (c *auditedResourceClient) record(
+	operation, name, diff string, subresources []string, err error,
+) {
+	entry := audit.Entry{
+		Time:        time.Now(),
+		Controller:  c.controller,
+		Watch:       c.watch,
+		APIVersion:  c.apiResource.APIVersion,
+		Kind:        c.apiResource.Kind,
+		Namespace:   c.namespace,
+		Name:        name,
+		Subresource: strings.Join(subresources, "/"),
+		Operation:   operation,
+		Diff:        diff,
+		Outcome:     requestResult(err),
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	audit.Record(entry)
+}
+
+// isAudited returns true if a write with the given dry run option
+// needs to be audited
+func isAudited(dryRun []string) bool {
+	return len(dryRun) == 0 && audit.IsEnabled()
+}
+
+// deleteDryRun returns the dry run option of the given delete options
+func deleteDryRun(options *metav1.DeleteOptions) []string {
+	if options == nil {
+		return nil
+	}
+	return options.DryRun
+}
+
+// Create creates the given resource & audits this request
+func (c *auditedResourceClient) Create(
+	obj *unstructured.Unstructured, options metav1.CreateOptions, subresources ...string,
+) (*unstructured.Unstructured, error) {
+	result, err := c.ResourceInterface.Create(obj, options, subresources...)
+	if isAudited(options.DryRun) {
+		name := obj.GetName()
+		if err == nil && name == "" {
+			// name was generated by the API server
+			name = result.GetName()
+		}
+		c.record(audit.OperationCreate, name, c.diff, subresources, err)
+	}
+	return result, err
+}
+
+// Update updates the given resource & audits this request
+func (c *auditedResourceClient) Update(
+	obj *unstructured.Unstructured, options metav1.UpdateOptions, subresources ...string,
+) (*unstructured.Unstructured, error) {
+	result, err := c.ResourceInterface.Update(obj, options, subresources...)
+	if isAudited(options.DryRun) {
+		c.record(audit.OperationUpdate, obj.GetName(), c.diff, subresources, err)
+	}
+	return result, err
+}
+
+// UpdateStatus updates the status of the given resource & audits this
+// request
+func (c *auditedResourceClient) UpdateStatus(
+	obj *unstructured.Unstructured, options metav1.UpdateOptions,
+) (*unstructured.Unstructured, error) {
+	result, err := c.ResourceInterface.UpdateStatus(obj, options)
+	if isAudited(options.DryRun) {
+		c.record(audit.OperationUpdateStatus, obj.GetName(), c.diff, []string{"status"}, err)
+	}
+	return result, err
+}
+
+// Delete deletes the resource with the given name & audits this
+// request
+func (c *auditedResourceClient) Delete(
+	name string, options *metav1.DeleteOptions, subresources ...string,
+) error {
+	err := c.ResourceInterface.Delete(name, options, subresources...)
+	if isAudited(deleteDryRun(options)) {
+		c.record(audit.OperationDelete, name, c.diff, subresources, err)
+	}
+	return err
+}
+
+// DeleteCollection deletes the resources selected by the given
+// options & audits this request
+func (c *auditedResourceClient) DeleteCollection(
+	options *metav1.DeleteOptions, listOptions metav1.ListOptions,
+) error {
+	err := c.ResourceInterface.DeleteCollection(options, listOptions)
+	if isAudited(deleteDryRun(options)) {
+		diff := c.diff
+		if diff == "" && listOptions.LabelSelector != "" {
+			diff = fmt.Sprintf("Selector %s", listOptions.LabelSelector)
+		}
+		c.record(audit.OperationDeleteCollection, "", diff, nil, err)
+	}
+	return err
+}
+
+// Patch patches the resource with the given name & audits this
+// request. Fields set by the patch form the diff summary of the audit
+// entry unless a summary is set against this client.
+func (c *auditedResourceClient) Patch(
+	name string,
+	pt types.PatchType,
+	data []byte,
+	options metav1.PatchOptions,
+	subresources ...string,
+) (*unstructured.Unstructured, error) {
+	result, err := c.ResourceInterface.Patch(name, pt, data, options, subresources...)
+	if isAudited(options.DryRun) {
+		diff := c.diff
+		if diff == "" {
+			diff = summarisePatch(pt, data)
+		}
+		c.record(audit.OperationPatch, name, diff, subresources, err)
+	}
+	return result, err
+}
+
+// summarisePatch returns the fields set by the given patch
+func summarisePatch(pt types.PatchType, data []byte) string {
+	var fields []string
+	if pt == types.JSONPatchType {
+		var ops []struct {
+			Op   string `json:"op"`
+			Path string `json:"path"`
+		}
+		if json.Unmarshal(data, &ops) != nil {
+			return ""
+		}
+		for _, op := range ops {
+			fields = append(fields, op.Op+" "+op.Path)
+		}
+	} else {
+		patch := make(map[string]interface{})
+		if json.Unmarshal(data, &patch) != nil {
+			return ""
+		}
+		fields = listPatchFields("", patch, fields)
+		sort.Strings(fields)
+	}
+	if len(fields) > maxAuditDiffFields {
+		fields = append(
+			fields[:maxAuditDiffFields],
+			fmt.Sprintf("and %d more", len(fields)-maxAuditDiffFields),
+		)
+	}
+	return strings.Join(fields, ", ")
+}
+
+// listPatchFields appends the fields set by the given merge patch
+// to the given list
+func listPatchFields(path string, patch map[string]interface{}, fields []string) []string {
+	for key, value := range patch {
+		if strings.HasPrefix(key, "$") {
+			// directives of strategic merge patch
+			continue
+		}
+		fieldPath := key
+		if path != "" {
+			fieldPath = path + "." + key
+		}
+		if fieldPath == "metadata.resourceVersion" {
+			// set by the patches that are conditional on the
+			// observed state
+			continue
+		}
+		switch val := value.(type) {
+		case nil:
+			fields = append(fields, "Remove "+fieldPath)
+		case map[string]interface{}:
+			if len(val) == 0 {
+				fields = append(fields, "Set "+fieldPath)
+				continue
+			}
+			fields = listPatchFields(fieldPath, val, fields)
+		default:
+			fields = append(fields, "Set "+fieldPath)
+		}
+	}
+	return fields
+}
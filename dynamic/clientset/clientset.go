@@ -25,6 +25,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/metadata"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/util/retry"
 
@@ -39,11 +40,68 @@ type Clientset struct {
 	config          rest.Config
 	resourceManager *dynamicdiscovery.APIResourceManager
 	dynamicClient   dynamic.Interface
+	metadataClient  metadata.Interface
+
+	// lists & watches the built-in resources as protobuf for the
+	// read only clients; this is nil if protobuf is not enabled
+	protobufClient rest.Interface
+
+	// limits of the requests made per resource
+	defaultRateLimit RateLimit
+	rateLimits       map[string]RateLimit
+
+	// rate limiters of the resources that are limited; these are
+	// shared with the impersonated clientsets
+	rateLimiters *resourceRateLimiters
+
+	// writes of all the resources are dry runs if this is true
+	dryRun bool
+
+	// controller to which the requests are attributed in the client
+	// metrics & the audit entries
+	controller string
+
+	// watch whose sync makes the requests; this is set against the
+	// audit entries
+	watch string
+}
+
+// ClientsetOption is a typed function that helps in building a
+// Clientset instance
+//
+// NOTE:
+//	This follows the pattern known as "functional options".
+type ClientsetOption func(*Clientset) error
+
+// WithProtobuf makes the read only clients of built-in resources list
+// & watch these resources as protobuf instead of json. This reduces the
+// CPU spent to decode large lists & watches as well as the bandwidth of
+// the API server. Custom resources are always served as json.
+//
+// NOTE:
+//	Protobuf is decoded into the typed structs known to this binary.
+// Fields that the API server serves but these structs don't know are
+// lost. Hence protobuf is limited to the clients obtained via
+// GetReadOnlyClientByResource whose resources are never written back.
+func WithProtobuf(enabled bool) ClientsetOption {
+	return func(cs *Clientset) error {
+		if !enabled {
+			return nil
+		}
+		client, err := newProtobufRESTClient(&cs.config)
+		if err != nil {
+			return errors.Wrapf(err, "Failed to initialise protobuf client")
+		}
+		cs.protobufClient = client
+		return nil
+	}
 }
 
 // New returns a new instance of Clientset
 func New(
-	config *rest.Config, resourceMgr *dynamicdiscovery.APIResourceManager,
+	config *rest.Config,
+	resourceMgr *dynamicdiscovery.APIResourceManager,
+	opts ...ClientsetOption,
 ) (*Clientset, error) {
 
 	dc, err := dynamic.NewForConfig(config)
@@ -51,11 +109,25 @@ func New(
 		return nil, errors.Wrapf(err, "New clientset failed")
 	}
 
-	return &Clientset{
+	mc, err := metadata.NewForConfig(config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "New clientset failed")
+	}
+
+	cs := &Clientset{
 		config:          *config,
 		resourceManager: resourceMgr,
 		dynamicClient:   dc,
-	}, nil
+		metadataClient:  mc,
+		rateLimiters:    newResourceRateLimiters(),
+	}
+	for _, o := range opts {
+		err = o(cs)
+		if err != nil {
+			return nil, errors.Wrapf(err, "New clientset failed")
+		}
+	}
+	return cs, nil
 }
 
 // HasSynced flags if resources managed by this clientset have
@@ -76,7 +148,28 @@ func (cs *Clientset) GetClientByResource(apiVersion, resource string) (*Resource
 			apiVersion,
 		)
 	}
-	return cs.resource(apiResource), nil
+	return cs.resource(apiResource, false), nil
+}
+
+// GetReadOnlyClientByResource returns the resource client corresponding
+// to the given version & resource name (i.e. plural of kind) that lists
+// & watches built-in resources as protobuf if enabled.
+//
+// NOTE:
+//	Resources got via this client may miss the fields unknown to this
+// binary. These resources must not be used to update the resources.
+func (cs *Clientset) GetReadOnlyClientByResource(
+	apiVersion, resource string,
+) (*ResourceClient, error) {
+	apiResource := cs.resourceManager.GetByResource(apiVersion, resource)
+	if apiResource == nil {
+		return nil, errors.Errorf(
+			"Failed to initialise read only client for resource %q in apiVersion %q",
+			resource,
+			apiVersion,
+		)
+	}
+	return cs.resource(apiResource, true), nil
 }
 
 // GetClientByKind returns the specific dynamic client of the given
@@ -91,15 +184,30 @@ func (cs *Clientset) GetClientByKind(apiVersion, kind string) (*ResourceClient,
 			apiVersion,
 		)
 	}
-	return cs.resource(apiResource), nil
+	return cs.resource(apiResource, false), nil
 }
 
 // resource returns a new dynamic client instance of the given resource
 //
 // NOTE:
-//	The returned client instance is specific to the given resource
-func (cs *Clientset) resource(apiResource *dynamicdiscovery.APIResource) *ResourceClient {
-	client := cs.dynamicClient.Resource(apiResource.GroupVersionResource())
+//	The returned client instance is specific to the given resource.
+// It lists & watches as protobuf if isReadOnly is true & protobuf is
+// enabled for this resource.
+func (cs *Clientset) resource(
+	apiResource *dynamicdiscovery.APIResource, isReadOnly bool,
+) *ResourceClient {
+	var client dynamic.NamespaceableResourceInterface
+	client = cs.dynamicClient.Resource(apiResource.GroupVersionResource())
+	if isReadOnly && cs.protobufClient != nil && isProtobufResource(apiResource) {
+		client = newProtobufResourceClient(client, cs.protobufClient, apiResource)
+	}
+	metrics := cs.newRequestMetrics(apiResource)
+	client = withMetrics(client, metrics)
+	client = cs.withRateLimiter(client, apiResource, metrics)
+	client = withAudit(client, apiResource, cs.controller, cs.watch)
+	if cs.dryRun {
+		client = withDryRun(client)
+	}
 	return &ResourceClient{
 		ResourceInterface: client,
 		APIResource:       apiResource,
@@ -175,12 +283,14 @@ func (rc *ResourceClient) AtomicUpdate(
 			// The original object was deleted and replaced with a new one.
 			return apierrors.NewNotFound(rc.GroupResource(), name)
 		}
+		observed := current.DeepCopy()
 		if changed := updateFunc(current); !changed {
 			// There's nothing to do.
 			result = current
 			return nil
 		}
-		result, err = rc.Update(current, metav1.UpdateOptions{})
+		result, err = rc.WithAuditDiff(AuditDiff(observed, current)).
+			Update(current, metav1.UpdateOptions{})
 		return err
 	})
 	return result, err
@@ -234,16 +344,18 @@ func (rc *ResourceClient) AtomicStatusUpdate(
 			// The original object was deleted and replaced with a new one.
 			return apierrors.NewNotFound(rc.GroupResource(), name)
 		}
+		observed := current.DeepCopy()
 		if changed := update(current); !changed {
 			// There's nothing to do.
 			result = current
 			return nil
 		}
 
+		auditRC := rc.WithAuditDiff(AuditDiff(observed, current))
 		if rc.HasSubresource("status") {
-			result, err = rc.UpdateStatus(current, metav1.UpdateOptions{})
+			result, err = auditRC.UpdateStatus(current, metav1.UpdateOptions{})
 		} else {
-			result, err = rc.Update(current, metav1.UpdateOptions{})
+			result, err = auditRC.Update(current, metav1.UpdateOptions{})
 		}
 		return err
 	})
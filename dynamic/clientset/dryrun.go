@@ -0,0 +1,153 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clientset
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+)
+
+// dryRunAll is the value of the dryRun option that makes the API
+// server process a request without persisting its changes
+var dryRunAll = []string{metav1.DryRunAll}
+
+// WithDryRun makes the create, update, patch & delete requests of all
+// the clients of this clientset dry runs i.e. these requests are
+// validated & admitted by the API server but are never persisted.
+// Reads are not affected.
+func WithDryRun(enabled bool) ClientsetOption {
+	return func(cs *Clientset) error {
+		cs.dryRun = enabled
+		return nil
+	}
+}
+
+// DryRun returns a copy of the ResourceClient whose create, update,
+// patch & delete requests are dry runs
+//
+// NOTE:
+//	This can be chained with Namespace() in any order
+func (rc *ResourceClient) DryRun() *ResourceClient {
+	if _, ok := rc.rootClient.(*dryRunResourceClient); ok {
+		// nothing to do since this is already a dry run client
+		return rc
+	}
+	root := withDryRun(rc.rootClient)
+	ri := dynamic.ResourceInterface(root)
+	if rc.ResourceInterface != dynamic.ResourceInterface(rc.rootClient) {
+		// retain the namespace of this client
+		ri = &dryRunResourceClient{
+			ResourceInterface: rc.ResourceInterface,
+			root:              rc.rootClient,
+		}
+	}
+	return &ResourceClient{
+		ResourceInterface: ri,
+		APIResource:       rc.APIResource,
+		rootClient:        root,
+	}
+}
+
+// withDryRun returns the given client with its writes done as dry
+// runs
+func withDryRun(
+	client dynamic.NamespaceableResourceInterface,
+) dynamic.NamespaceableResourceInterface {
+	return &dryRunResourceClient{ResourceInterface: client, root: client}
+}
+
+// dryRunResourceClient sets the dryRun option of every create, update,
+// patch & delete request
+type dryRunResourceClient struct {
+	dynamic.ResourceInterface
+
+	root dynamic.NamespaceableResourceInterface
+}
+
+// dryRunResourceClient implements dynamic.NamespaceableResourceInterface
+var _ dynamic.NamespaceableResourceInterface = &dryRunResourceClient{}
+
+// Namespace returns the client scoped to the given namespace
+func (c *dryRunResourceClient) Namespace(namespace string) dynamic.ResourceInterface {
+	return &dryRunResourceClient{
+		ResourceInterface: c.root.Namespace(namespace),
+		root:              c.root,
+	}
+}
+
+// Create creates the given resource as a dry run
+func (c *dryRunResourceClient) Create(
+	obj *unstructured.Unstructured, options metav1.CreateOptions, subresources ...string,
+) (*unstructured.Unstructured, error) {
+	options.DryRun = dryRunAll
+	return c.ResourceInterface.Create(obj, options, subresources...)
+}
+
+// Update updates the given resource as a dry run
+func (c *dryRunResourceClient) Update(
+	obj *unstructured.Unstructured, options metav1.UpdateOptions, subresources ...string,
+) (*unstructured.Unstructured, error) {
+	options.DryRun = dryRunAll
+	return c.ResourceInterface.Update(obj, options, subresources...)
+}
+
+// UpdateStatus updates the status of the given resource as a dry run
+func (c *dryRunResourceClient) UpdateStatus(
+	obj *unstructured.Unstructured, options metav1.UpdateOptions,
+) (*unstructured.Unstructured, error) {
+	options.DryRun = dryRunAll
+	return c.ResourceInterface.UpdateStatus(obj, options)
+}
+
+// Delete deletes the given resource as a dry run
+func (c *dryRunResourceClient) Delete(
+	name string, options *metav1.DeleteOptions, subresources ...string,
+) error {
+	return c.ResourceInterface.Delete(name, withDeleteDryRun(options), subresources...)
+}
+
+// DeleteCollection deletes the matching resources as a dry run
+func (c *dryRunResourceClient) DeleteCollection(
+	options *metav1.DeleteOptions, listOptions metav1.ListOptions,
+) error {
+	return c.ResourceInterface.DeleteCollection(withDeleteDryRun(options), listOptions)
+}
+
+// Patch patches the given resource as a dry run
+func (c *dryRunResourceClient) Patch(
+	name string,
+	pt types.PatchType,
+	data []byte,
+	options metav1.PatchOptions,
+	subresources ...string,
+) (*unstructured.Unstructured, error) {
+	options.DryRun = dryRunAll
+	return c.ResourceInterface.Patch(name, pt, data, options, subresources...)
+}
+
+// withDeleteDryRun returns a copy of the given delete options with
+// dryRun set
+func withDeleteDryRun(options *metav1.DeleteOptions) *metav1.DeleteOptions {
+	if options == nil {
+		return &metav1.DeleteOptions{DryRun: dryRunAll}
+	}
+	dryRunOptions := options.DeepCopy()
+	dryRunOptions.DryRun = dryRunAll
+	return dryRunOptions
+}
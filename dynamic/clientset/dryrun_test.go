@@ -0,0 +1,197 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clientset
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+
+	dynamicdiscovery "openebs.io/metac/dynamic/discovery"
+)
+
+// recordedRequest is a request received by the record server
+type recordedRequest struct {
+	method      string
+	path        string
+	contentType string
+	body        []byte
+	isDryRun    bool
+}
+
+// newRecordServer returns a server that records the requests it
+// receives & responds with the pod
+func newRecordServer(t *testing.T) (*httptest.Server, func() []recordedRequest) {
+	var mutex sync.Mutex
+	var requests []recordedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("Failed to read request: %v", err)
+		}
+		mutex.Lock()
+		requests = append(requests, recordedRequest{
+			method:      r.Method,
+			path:        r.URL.Path,
+			contentType: r.Header.Get("Content-Type"),
+			body:        body,
+			isDryRun: r.URL.Query().Get("dryRun") == metav1.DryRunAll ||
+				bytes.Contains(body, []byte(`"dryRun":["All"]`)),
+		})
+		mutex.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodDelete {
+			w.Write([]byte(`{"kind": "Status", "apiVersion": "v1", "status": "Success"}`))
+			return
+		}
+		w.Write([]byte(podJSON))
+	}))
+	return server, func() []recordedRequest {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return requests
+	}
+}
+
+// podResource is the discovered pod resource
+var podResource = &dynamicdiscovery.APIResource{
+	APIResource: metav1.APIResource{
+		Name:       "pods",
+		Kind:       "Pod",
+		Version:    "v1",
+		Namespaced: true,
+	},
+	APIVersion: "v1",
+}
+
+// newPod returns the pod to be written
+func newPod() *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Pod",
+			"metadata": map[string]interface{}{
+				"name":      "my-pod",
+				"namespace": "default",
+			},
+		},
+	}
+}
+
+func TestClientsetDryRun(t *testing.T) {
+	var requests = map[string]func(rc *ResourceClient) error{
+		"create": func(rc *ResourceClient) error {
+			_, err := rc.Create(newPod(), metav1.CreateOptions{})
+			return err
+		},
+		"update": func(rc *ResourceClient) error {
+			_, err := rc.Update(newPod(), metav1.UpdateOptions{})
+			return err
+		},
+		"update status": func(rc *ResourceClient) error {
+			_, err := rc.UpdateStatus(newPod(), metav1.UpdateOptions{})
+			return err
+		},
+		"patch": func(rc *ResourceClient) error {
+			_, err := rc.Patch("my-pod", types.MergePatchType, []byte(`{}`), metav1.PatchOptions{})
+			return err
+		},
+		"delete": func(rc *ResourceClient) error {
+			return rc.Delete("my-pod", &metav1.DeleteOptions{})
+		},
+		"delete without options": func(rc *ResourceClient) error {
+			return rc.Delete("my-pod", nil)
+		},
+		"delete collection": func(rc *ResourceClient) error {
+			return rc.DeleteCollection(nil, metav1.ListOptions{})
+		},
+		"get": func(rc *ResourceClient) error {
+			_, err := rc.Get("my-pod", metav1.GetOptions{})
+			return err
+		},
+	}
+	var tests = map[string]struct {
+		isClientsetDryRun bool
+		isClientDryRun    bool
+		isNamespaceFirst  bool
+	}{
+		"clientset dry run": {
+			isClientsetDryRun: true,
+		},
+		"client dry run": {
+			isClientDryRun: true,
+		},
+		"namespaced client dry run": {
+			isClientDryRun:   true,
+			isNamespaceFirst: true,
+		},
+		"no dry run": {},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			for verb, request := range requests {
+				server, getRequests := newRecordServer(t)
+				cs, err := New(
+					&rest.Config{Host: server.URL}, nil, WithDryRun(mock.isClientsetDryRun),
+				)
+				if err != nil {
+					server.Close()
+					t.Fatalf("Expected no error got %v", err)
+				}
+				rc := cs.resource(podResource, false)
+				if mock.isNamespaceFirst {
+					rc = rc.Namespace("default")
+				}
+				if mock.isClientDryRun {
+					rc = rc.DryRun()
+				}
+				rc = rc.Namespace("default")
+				err = request(rc)
+				server.Close()
+				if err != nil {
+					t.Fatalf("%s: Expected no error got %v", verb, err)
+				}
+				got := getRequests()
+				if len(got) != 1 {
+					t.Fatalf("%s: Expected 1 request got %d", verb, len(got))
+				}
+				if !strings.HasPrefix(got[0].path, "/api/v1/namespaces/default/pods") {
+					t.Fatalf("%s: Expected namespaced request got %q", verb, got[0].path)
+				}
+				isWrite := got[0].method != http.MethodGet
+				wantDryRun := isWrite && (mock.isClientsetDryRun || mock.isClientDryRun)
+				if got[0].isDryRun != wantDryRun {
+					t.Fatalf(
+						"%s: Expected dry run %t got %t: %s %s",
+						verb, wantDryRun, got[0].isDryRun, got[0].method, got[0].body,
+					)
+				}
+			}
+		})
+	}
+}
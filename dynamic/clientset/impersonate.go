@@ -0,0 +1,80 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clientset
+
+import (
+	"github.com/pkg/errors"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/rest"
+)
+
+// Impersonate returns a copy of this clientset whose requests are
+// made as the given identity. The copy retains the options of this
+// clientset e.g. protobuf, rate limits & dry run.
+//
+// NOTE:
+//	Rate limiters of the resources are shared with this clientset.
+// Hence the requests made as different identities are limited
+// together.
+//
+// NOTE:
+//	Identity of this clientset must be allowed to impersonate the
+// given identity
+func (cs *Clientset) Impersonate(impersonate rest.ImpersonationConfig) (*Clientset, error) {
+	config := rest.CopyConfig(&cs.config)
+	config.Impersonate = impersonate
+
+	ics, err := cs.withConfig(config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Impersonate %q failed", impersonate.UserName)
+	}
+	return ics, nil
+}
+
+// withConfig returns a copy of this clientset whose clients are built
+// from the given config. The copy retains the options of this
+// clientset.
+func (cs *Clientset) withConfig(config *rest.Config) (*Clientset, error) {
+	dc, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	mc, err := metadata.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	ccs := &Clientset{
+		config:           *config,
+		resourceManager:  cs.resourceManager,
+		dynamicClient:    dc,
+		metadataClient:   mc,
+		defaultRateLimit: cs.defaultRateLimit,
+		rateLimits:       cs.rateLimits,
+		rateLimiters:     cs.rateLimiters,
+		dryRun:           cs.dryRun,
+		controller:       cs.controller,
+	}
+	if cs.protobufClient != nil {
+		ccs.protobufClient, err = newProtobufRESTClient(config)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ccs, nil
+}
@@ -0,0 +1,216 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clientset
+
+import (
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/metadata"
+
+	dynamicdiscovery "openebs.io/metac/dynamic/discovery"
+)
+
+// GetMetadataClientByResource returns the resource client of the given
+// version & resource name (i.e. plural of kind) that gets, lists &
+// watches only the metadata of the resources. Resources returned by
+// this client have their apiVersion, kind & metadata set.
+//
+// NOTE:
+//	Resources can't be created or updated via this client
+func (cs *Clientset) GetMetadataClientByResource(
+	apiVersion, resource string,
+) (*ResourceClient, error) {
+	apiResource := cs.resourceManager.GetByResource(apiVersion, resource)
+	if apiResource == nil {
+		return nil, errors.Errorf(
+			"Failed to initialise metadata client for resource %q in apiVersion %q",
+			resource,
+			apiVersion,
+		)
+	}
+	client := &metadataResourceClient{
+		getter:      cs.metadataClient.Resource(apiResource.GroupVersionResource()),
+		apiResource: apiResource,
+	}
+	client.client = client.getter
+	metrics := cs.newRequestMetrics(apiResource)
+	limitedClient := cs.withRateLimiter(withMetrics(client, metrics), apiResource, metrics)
+	return &ResourceClient{
+		ResourceInterface: limitedClient,
+		APIResource:       apiResource,
+		rootClient:        limitedClient,
+	}, nil
+}
+
+// metadataResourceClient adapts the metadata client of a resource to
+// the dynamic client interface
+type metadataResourceClient struct {
+	getter      metadata.Getter
+	client      metadata.ResourceInterface
+	apiResource *dynamicdiscovery.APIResource
+}
+
+// metadataResourceClient implements dynamic.NamespaceableResourceInterface
+var _ dynamic.NamespaceableResourceInterface = &metadataResourceClient{}
+
+// Namespace returns the client scoped to the given namespace
+func (c *metadataResourceClient) Namespace(namespace string) dynamic.ResourceInterface {
+	return &metadataResourceClient{
+		getter:      c.getter,
+		client:      c.getter.Namespace(namespace),
+		apiResource: c.apiResource,
+	}
+}
+
+// toUnstructured returns the given metadata as an unstructured
+// instance of this client's resource
+func (c *metadataResourceClient) toUnstructured(
+	obj *metav1.PartialObjectMetadata,
+) (*unstructured.Unstructured, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, errors.Wrapf(
+			err,
+			"Failed to convert metadata of %s/%s of resource %q",
+			obj.GetNamespace(),
+			obj.GetName(),
+			c.apiResource.Name,
+		)
+	}
+	u := &unstructured.Unstructured{Object: content}
+	u.SetAPIVersion(c.apiResource.APIVersion)
+	u.SetKind(c.apiResource.Kind)
+	return u, nil
+}
+
+// Create is not supported
+func (c *metadataResourceClient) Create(
+	obj *unstructured.Unstructured, options metav1.CreateOptions, subresources ...string,
+) (*unstructured.Unstructured, error) {
+	return nil, errors.Errorf("Create is not supported by metadata client")
+}
+
+// Update is not supported
+func (c *metadataResourceClient) Update(
+	obj *unstructured.Unstructured, options metav1.UpdateOptions, subresources ...string,
+) (*unstructured.Unstructured, error) {
+	return nil, errors.Errorf("Update is not supported by metadata client")
+}
+
+// UpdateStatus is not supported
+func (c *metadataResourceClient) UpdateStatus(
+	obj *unstructured.Unstructured, options metav1.UpdateOptions,
+) (*unstructured.Unstructured, error) {
+	return nil, errors.Errorf("UpdateStatus is not supported by metadata client")
+}
+
+// Delete deletes the resource with the given name
+func (c *metadataResourceClient) Delete(
+	name string, options *metav1.DeleteOptions, subresources ...string,
+) error {
+	return c.client.Delete(name, options, subresources...)
+}
+
+// DeleteCollection deletes the resources selected by the given options
+func (c *metadataResourceClient) DeleteCollection(
+	options *metav1.DeleteOptions, listOptions metav1.ListOptions,
+) error {
+	return c.client.DeleteCollection(options, listOptions)
+}
+
+// Get returns the metadata of the resource with the given name
+func (c *metadataResourceClient) Get(
+	name string, options metav1.GetOptions, subresources ...string,
+) (*unstructured.Unstructured, error) {
+	obj, err := c.client.Get(name, options, subresources...)
+	if err != nil {
+		return nil, err
+	}
+	return c.toUnstructured(obj)
+}
+
+// List returns the metadata of the resources selected by the given
+// options
+func (c *metadataResourceClient) List(
+	opts metav1.ListOptions,
+) (*unstructured.UnstructuredList, error) {
+	list, err := c.client.List(opts)
+	if err != nil {
+		return nil, err
+	}
+	result := &unstructured.UnstructuredList{}
+	result.SetAPIVersion(c.apiResource.APIVersion)
+	result.SetKind(c.apiResource.Kind + "List")
+	result.SetResourceVersion(list.GetResourceVersion())
+	result.SetContinue(list.GetContinue())
+	for idx := range list.Items {
+		u, err := c.toUnstructured(&list.Items[idx])
+		if err != nil {
+			return nil, err
+		}
+		result.Items = append(result.Items, *u)
+	}
+	return result, nil
+}
+
+// Watch watches the metadata of the resources selected by the given
+// options
+func (c *metadataResourceClient) Watch(opts metav1.ListOptions) (watch.Interface, error) {
+	w, err := c.client.Watch(opts)
+	if err != nil {
+		return nil, err
+	}
+	return watch.Filter(w, func(in watch.Event) (watch.Event, bool) {
+		// bookmarks are converted as well since informers expect
+		// every event to carry an unstructured instance
+		obj, ok := in.Object.(*metav1.PartialObjectMetadata)
+		if !ok {
+			// errors are left as is
+			return in, true
+		}
+		u, err := c.toUnstructured(obj)
+		if err != nil {
+			return watch.Event{
+				Type:   watch.Error,
+				Object: &metav1.Status{Status: metav1.StatusFailure, Message: err.Error()},
+			}, true
+		}
+		in.Object = u
+		return in, true
+	}), nil
+}
+
+// Patch patches the resource with the given name & returns its
+// metadata
+func (c *metadataResourceClient) Patch(
+	name string,
+	pt types.PatchType,
+	data []byte,
+	options metav1.PatchOptions,
+	subresources ...string,
+) (*unstructured.Unstructured, error) {
+	obj, err := c.client.Patch(name, pt, data, options, subresources...)
+	if err != nil {
+		return nil, err
+	}
+	return c.toUnstructured(obj)
+}
@@ -0,0 +1,345 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clientset
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang/glog"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+
+	dynamicdiscovery "openebs.io/metac/dynamic/discovery"
+)
+
+const (
+	// verbs that tag the metrics of the requests
+	verbCreate           = "create"
+	verbUpdate           = "update"
+	verbUpdateStatus     = "update_status"
+	verbDelete           = "delete"
+	verbDeleteCollection = "delete_collection"
+	verbGet              = "get"
+	verbList             = "list"
+	verbWatch            = "watch"
+	verbPatch            = "patch"
+
+	// resultSuccess tags the metrics of the requests that succeeded
+	resultSuccess = "Success"
+
+	// resultError tags the metrics of the requests that failed
+	// without a status from the API server
+	resultError = "Error"
+)
+
+var (
+	// verbKey tags the client metrics with the verb of the request
+	verbKey, _ = tag.NewKey("verb")
+
+	// apiVersionKey tags the client metrics with the api version of
+	// the requested resource
+	apiVersionKey, _ = tag.NewKey("api_version")
+
+	// resourceNameKey tags the client metrics with the name of the
+	// requested resource
+	resourceNameKey, _ = tag.NewKey("resource")
+
+	// controllerKey tags the client metrics with the controller that
+	// made the request e.g. namespace/name of a GenericController.
+	// This is empty for the requests that are not made on behalf of
+	// a single controller e.g. lists & watches of shared informers.
+	controllerKey, _ = tag.NewKey("controller")
+
+	// resultKey tags the client requests with their result i.e.
+	// Success or the reason of their failure e.g. Conflict
+	resultKey, _ = tag.NewKey("result")
+
+	// requestsMeasure counts the requests
+	requestsMeasure = stats.Int64(
+		"metac/client_requests",
+		"Number of requests made by the dynamic clients",
+		stats.UnitDimensionless,
+	)
+
+	// requestDurationMeasure tracks the time taken by the requests
+	// excluding the time spent waiting for the rate limiter
+	requestDurationMeasure = stats.Float64(
+		"metac/client_request_duration_seconds",
+		"Time taken by the requests made by the dynamic clients",
+		"s",
+	)
+
+	// rateLimiterWaitMeasure tracks the time spent by the requests
+	// waiting for the rate limiter of their resource
+	rateLimiterWaitMeasure = stats.Float64(
+		"metac/client_rate_limiter_wait_seconds",
+		"Time spent by the requests of the dynamic clients waiting for the resource rate limiter",
+		"s",
+	)
+
+	// Views expose all the client metrics
+	//
+	// NOTE:
+	//	These need to be registered to be exported
+	Views = []*view.View{
+		{
+			Name:        "metac/client_requests_total",
+			Description: requestsMeasure.Description(),
+			Measure:     requestsMeasure,
+			TagKeys:     []tag.Key{verbKey, apiVersionKey, resourceNameKey, controllerKey, resultKey},
+			Aggregation: view.Count(),
+		},
+		{
+			Name:        "metac/client_request_duration_seconds",
+			Description: requestDurationMeasure.Description(),
+			Measure:     requestDurationMeasure,
+			TagKeys:     []tag.Key{verbKey, apiVersionKey, resourceNameKey, controllerKey},
+			Aggregation: view.Distribution(0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10),
+		},
+		{
+			Name:        "metac/client_rate_limiter_wait_seconds",
+			Description: rateLimiterWaitMeasure.Description(),
+			Measure:     rateLimiterWaitMeasure,
+			TagKeys:     []tag.Key{verbKey, apiVersionKey, resourceNameKey, controllerKey},
+			Aggregation: view.Distribution(0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10),
+		},
+		{
+			Name:        "metac/client_warnings_total",
+			Description: warningsMeasure.Description(),
+			Measure:     warningsMeasure,
+			TagKeys:     []tag.Key{controllerKey},
+			Aggregation: view.Count(),
+		},
+	}
+)
+
+// ForController returns a copy of this clientset whose requests are
+// attributed to the given controller in the client metrics. The copy
+// shares the clients & rate limiters of this clientset.
+func (cs *Clientset) ForController(name string) *Clientset {
+	ccs := *cs
+	ccs.controller = name
+	return &ccs
+}
+
+// requestMetrics records the metrics of the requests made against a
+// single resource
+type requestMetrics struct {
+	// context tagged with the resource & the controller
+	ctx context.Context
+}
+
+// newRequestMetrics returns a new instance of requestMetrics for the
+// given resource
+func (cs *Clientset) newRequestMetrics(apiResource *dynamicdiscovery.APIResource) *requestMetrics {
+	ctx, err := tag.New(
+		context.Background(),
+		tag.Upsert(apiVersionKey, apiResource.APIVersion),
+		tag.Upsert(resourceNameKey, apiResource.Name),
+		tag.Upsert(controllerKey, cs.controller),
+	)
+	if err != nil {
+		glog.Warningf(
+			"Failed to tag client metrics of resource %q in apiVersion %q: %v",
+			apiResource.Name, apiResource.APIVersion, err,
+		)
+		ctx = context.Background()
+	}
+	return &requestMetrics{ctx: ctx}
+}
+
+// recordRequest records the request of the given verb that started at
+// the given time & its error if any
+func (m *requestMetrics) recordRequest(verb string, start time.Time, err error) {
+	recordErr := stats.RecordWithTags(
+		m.ctx,
+		[]tag.Mutator{tag.Upsert(verbKey, verb), tag.Upsert(resultKey, requestResult(err))},
+		requestsMeasure.M(1),
+		requestDurationMeasure.M(time.Since(start).Seconds()),
+	)
+	if recordErr != nil {
+		glog.Warningf("Failed to record %s request: %v", verb, recordErr)
+	}
+}
+
+// recordRateLimiterWait records the time spent by the request of the
+// given verb waiting for the rate limiter
+func (m *requestMetrics) recordRateLimiterWait(verb string, waited time.Duration) {
+	recordErr := stats.RecordWithTags(
+		m.ctx,
+		[]tag.Mutator{tag.Upsert(verbKey, verb)},
+		rateLimiterWaitMeasure.M(waited.Seconds()),
+	)
+	if recordErr != nil {
+		glog.Warningf("Failed to record rate limiter wait of %s request: %v", verb, recordErr)
+	}
+}
+
+// requestResult returns the result of a request that failed with the
+// given error
+func requestResult(err error) string {
+	if err == nil {
+		return resultSuccess
+	}
+	if reason := apierrors.ReasonForError(err); reason != metav1.StatusReasonUnknown {
+		return string(reason)
+	}
+	return resultError
+}
+
+// withMetrics returns the given client with the metrics of its
+// requests recorded
+func withMetrics(
+	client dynamic.NamespaceableResourceInterface,
+	metrics *requestMetrics,
+) dynamic.NamespaceableResourceInterface {
+	return &instrumentedResourceClient{
+		ResourceInterface: client,
+		root:              client,
+		metrics:           metrics,
+	}
+}
+
+// instrumentedResourceClient records the metrics of every request
+type instrumentedResourceClient struct {
+	dynamic.ResourceInterface
+
+	root    dynamic.NamespaceableResourceInterface
+	metrics *requestMetrics
+}
+
+// instrumentedResourceClient implements dynamic.NamespaceableResourceInterface
+var _ dynamic.NamespaceableResourceInterface = &instrumentedResourceClient{}
+
+// Namespace returns the client scoped to the given namespace
+func (c *instrumentedResourceClient) Namespace(namespace string) dynamic.ResourceInterface {
+	return &instrumentedResourceClient{
+		ResourceInterface: c.root.Namespace(namespace),
+		root:              c.root,
+		metrics:           c.metrics,
+	}
+}
+
+// Create creates the given resource & records this request
+func (c *instrumentedResourceClient) Create(
+	obj *unstructured.Unstructured, options metav1.CreateOptions, subresources ...string,
+) (*unstructured.Unstructured, error) {
+	start := time.Now()
+	result, err := c.ResourceInterface.Create(obj, options, subresources...)
+	c.metrics.recordRequest(verbCreate, start, err)
+	return result, err
+}
+
+// Update updates the given resource & records this request
+func (c *instrumentedResourceClient) Update(
+	obj *unstructured.Unstructured, options metav1.UpdateOptions, subresources ...string,
+) (*unstructured.Unstructured, error) {
+	start := time.Now()
+	result, err := c.ResourceInterface.Update(obj, options, subresources...)
+	c.metrics.recordRequest(verbUpdate, start, err)
+	return result, err
+}
+
+// UpdateStatus updates the status of the given resource & records
+// this request
+func (c *instrumentedResourceClient) UpdateStatus(
+	obj *unstructured.Unstructured, options metav1.UpdateOptions,
+) (*unstructured.Unstructured, error) {
+	start := time.Now()
+	result, err := c.ResourceInterface.UpdateStatus(obj, options)
+	c.metrics.recordRequest(verbUpdateStatus, start, err)
+	return result, err
+}
+
+// Delete deletes the resource with the given name & records this
+// request
+func (c *instrumentedResourceClient) Delete(
+	name string, options *metav1.DeleteOptions, subresources ...string,
+) error {
+	start := time.Now()
+	err := c.ResourceInterface.Delete(name, options, subresources...)
+	c.metrics.recordRequest(verbDelete, start, err)
+	return err
+}
+
+// DeleteCollection deletes the resources selected by the given
+// options & records this request
+func (c *instrumentedResourceClient) DeleteCollection(
+	options *metav1.DeleteOptions, listOptions metav1.ListOptions,
+) error {
+	start := time.Now()
+	err := c.ResourceInterface.DeleteCollection(options, listOptions)
+	c.metrics.recordRequest(verbDeleteCollection, start, err)
+	return err
+}
+
+// Get returns the resource with the given name & records this request
+func (c *instrumentedResourceClient) Get(
+	name string, options metav1.GetOptions, subresources ...string,
+) (*unstructured.Unstructured, error) {
+	start := time.Now()
+	result, err := c.ResourceInterface.Get(name, options, subresources...)
+	c.metrics.recordRequest(verbGet, start, err)
+	return result, err
+}
+
+// List returns the resources selected by the given options & records
+// this request
+func (c *instrumentedResourceClient) List(
+	opts metav1.ListOptions,
+) (*unstructured.UnstructuredList, error) {
+	start := time.Now()
+	result, err := c.ResourceInterface.List(opts)
+	c.metrics.recordRequest(verbList, start, err)
+	return result, err
+}
+
+// Watch watches the resources selected by the given options & records
+// this request
+//
+// NOTE:
+//	Duration of a watch request is the time taken to establish the
+// watch
+func (c *instrumentedResourceClient) Watch(opts metav1.ListOptions) (watch.Interface, error) {
+	start := time.Now()
+	result, err := c.ResourceInterface.Watch(opts)
+	c.metrics.recordRequest(verbWatch, start, err)
+	return result, err
+}
+
+// Patch patches the resource with the given name & records this
+// request
+func (c *instrumentedResourceClient) Patch(
+	name string,
+	pt types.PatchType,
+	data []byte,
+	options metav1.PatchOptions,
+	subresources ...string,
+) (*unstructured.Unstructured, error) {
+	start := time.Now()
+	result, err := c.ResourceInterface.Patch(name, pt, data, options, subresources...)
+	c.metrics.recordRequest(verbPatch, start, err)
+	return result, err
+}
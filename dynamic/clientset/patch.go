@@ -0,0 +1,265 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clientset
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// StrategicMergePatch patches the named resource with the given
+// strategic merge patch
+//
+// NOTE:
+//	Strategic merge patches are supported by built-in resources only.
+// Refer IsStrategicMergePatchSupported.
+func (rc *ResourceClient) StrategicMergePatch(
+	name string, data []byte, options metav1.PatchOptions, subresources ...string,
+) (*unstructured.Unstructured, error) {
+	return rc.Patch(name, types.StrategicMergePatchType, data, options, subresources...)
+}
+
+// MergePatch patches the named resource with the given JSON merge
+// patch i.e. RFC 7386
+func (rc *ResourceClient) MergePatch(
+	name string, data []byte, options metav1.PatchOptions, subresources ...string,
+) (*unstructured.Unstructured, error) {
+	return rc.Patch(name, types.MergePatchType, data, options, subresources...)
+}
+
+// JSONPatch patches the named resource with the given JSON patch i.e.
+// RFC 6902
+func (rc *ResourceClient) JSONPatch(
+	name string, data []byte, options metav1.PatchOptions, subresources ...string,
+) (*unstructured.Unstructured, error) {
+	return rc.Patch(name, types.JSONPatchType, data, options, subresources...)
+}
+
+// ApplyPatch applies the given object via server side apply. The
+// object is created if it does not exist.
+//
+// NOTE:
+//	Options should set the field manager. Fields that were applied
+// earlier by this field manager & are missing from the given object
+// get removed by the API server.
+func (rc *ResourceClient) ApplyPatch(
+	obj *unstructured.Unstructured, options metav1.PatchOptions, subresources ...string,
+) (*unstructured.Unstructured, error) {
+	data, err := json.Marshal(obj.UnstructuredContent())
+	if err != nil {
+		return nil, errors.Wrapf(
+			err, "Can't apply %s %s/%s: Marshal failed",
+			rc.Kind, obj.GetNamespace(), obj.GetName(),
+		)
+	}
+	return rc.Patch(obj.GetName(), types.ApplyPatchType, data, options, subresources...)
+}
+
+// IsStrategicMergePatchSupported returns true if this resource can be
+// patched via strategic merge patch. This is true for the built-in
+// resources i.e. the ones known to the scheme of client-go.
+func (rc *ResourceClient) IsStrategicMergePatchSupported() bool {
+	return !strings.Contains(rc.Name, "/") &&
+		scheme.Scheme.Recognizes(rc.GroupVersionKind())
+}
+
+// CreatePatch returns the patch of the given type that changes the
+// given original state of a resource to the given modified state.
+// Apply patches are not supported since these do not depend on the
+// original state.
+//
+// NOTE:
+//	Resource version of the original state is set against the patch.
+// This makes the API server reject the patch with a conflict if the
+// resource was changed since the original state was read.
+func (rc *ResourceClient) CreatePatch(
+	pt types.PatchType, original, modified *unstructured.Unstructured,
+) ([]byte, error) {
+	switch pt {
+	case types.JSONPatchType:
+		return createJSONPatch(original, modified)
+	case types.MergePatchType, types.StrategicMergePatchType:
+	default:
+		return nil, errors.Errorf("Can't create patch: Unsupported patch type %q", pt)
+	}
+
+	originalJSON, err := json.Marshal(original.UnstructuredContent())
+	if err != nil {
+		return nil, errors.Wrapf(err, "Can't create patch: Marshal failed")
+	}
+	modifiedJSON, err := json.Marshal(modified.UnstructuredContent())
+	if err != nil {
+		return nil, errors.Wrapf(err, "Can't create patch: Marshal failed")
+	}
+
+	var data []byte
+	if pt == types.StrategicMergePatchType {
+		if !rc.IsStrategicMergePatchSupported() {
+			return nil, errors.Errorf(
+				"Can't create patch: Strategic merge patch is not supported by %s",
+				rc.GroupVersionKind(),
+			)
+		}
+		typed, err := scheme.Scheme.New(rc.GroupVersionKind())
+		if err != nil {
+			return nil, errors.Wrapf(err, "Can't create patch")
+		}
+		data, err = strategicpatch.CreateTwoWayMergePatch(originalJSON, modifiedJSON, typed)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Can't create strategic merge patch")
+		}
+	} else {
+		data, err = jsonpatch.CreateMergePatch(originalJSON, modifiedJSON)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Can't create merge patch")
+		}
+	}
+	return withResourceVersion(data, original.GetResourceVersion())
+}
+
+// withResourceVersion sets the given resource version against the
+// given merge patch
+func withResourceVersion(data []byte, resourceVersion string) ([]byte, error) {
+	if resourceVersion == "" {
+		return data, nil
+	}
+	patch := make(map[string]interface{})
+	err := json.Unmarshal(data, &patch)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Can't set resource version against patch")
+	}
+	err = unstructured.SetNestedField(
+		patch, resourceVersion, "metadata", "resourceVersion",
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Can't set resource version against patch")
+	}
+	return json.Marshal(patch)
+}
+
+// jsonPatchOperation is a single operation of a JSON patch
+//
+// NOTE:
+//	This is a map since value of add & replace operations must be set
+// even if it is null
+type jsonPatchOperation map[string]interface{}
+
+// newJSONPatchOperation returns a JSON patch operation without value
+func newJSONPatchOperation(op, path string) jsonPatchOperation {
+	return jsonPatchOperation{"op": op, "path": path}
+}
+
+// newJSONPatchValueOperation returns a JSON patch operation with the
+// given value
+func newJSONPatchValueOperation(op, path string, value interface{}) jsonPatchOperation {
+	return jsonPatchOperation{"op": op, "path": path, "value": value}
+}
+
+// createJSONPatch returns the JSON patch that changes the given
+// original state to the given modified state
+//
+// NOTE:
+//	Lists whose lengths differ are replaced as a whole. The patch
+// starts by setting the resource version of the original state if this
+// is set. This results in a conflict like the other patch types
+// instead of the invalid error of a failed test operation.
+func createJSONPatch(original, modified *unstructured.Unstructured) ([]byte, error) {
+	ops := []jsonPatchOperation{}
+	if rv := original.GetResourceVersion(); rv != "" {
+		ops = append(ops, newJSONPatchValueOperation("replace", "/metadata/resourceVersion", rv))
+	}
+	ops = appendJSONPatchOps(
+		ops, "", original.UnstructuredContent(), modified.UnstructuredContent(),
+	)
+	return json.Marshal(ops)
+}
+
+// appendJSONPatchOps appends the operations that change the given
+// original value at the given path to the given modified value
+func appendJSONPatchOps(
+	ops []jsonPatchOperation, path string, original, modified interface{},
+) []jsonPatchOperation {
+	switch originalVal := original.(type) {
+	case map[string]interface{}:
+		modifiedVal, ok := modified.(map[string]interface{})
+		if !ok {
+			break
+		}
+		for _, key := range sortedKeys(originalVal) {
+			modifiedField, found := modifiedVal[key]
+			if !found {
+				ops = append(ops, newJSONPatchOperation("remove", joinJSONPointer(path, key)))
+				continue
+			}
+			ops = appendJSONPatchOps(
+				ops, joinJSONPointer(path, key), originalVal[key], modifiedField,
+			)
+		}
+		for _, key := range sortedKeys(modifiedVal) {
+			if _, found := originalVal[key]; !found {
+				ops = append(ops, newJSONPatchValueOperation(
+					"add", joinJSONPointer(path, key), modifiedVal[key],
+				))
+			}
+		}
+		return ops
+	case []interface{}:
+		modifiedVal, ok := modified.([]interface{})
+		if !ok || len(originalVal) != len(modifiedVal) {
+			break
+		}
+		for idx := range originalVal {
+			ops = appendJSONPatchOps(
+				ops, fmt.Sprintf("%s/%d", path, idx), originalVal[idx], modifiedVal[idx],
+			)
+		}
+		return ops
+	}
+	if !reflect.DeepEqual(original, modified) {
+		ops = append(ops, newJSONPatchValueOperation("replace", path, modified))
+	}
+	return ops
+}
+
+// sortedKeys returns the keys of the given map in sorted order
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// joinJSONPointer appends the given key to the given JSON pointer
+// i.e. RFC 6901
+func joinJSONPointer(path, key string) string {
+	key = strings.Replace(key, "~", "~0", -1)
+	key = strings.Replace(key, "/", "~1", -1)
+	return path + "/" + key
+}
@@ -0,0 +1,226 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clientset
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/client-go/rest"
+
+	dynamicdiscovery "openebs.io/metac/dynamic/discovery"
+)
+
+func TestResourceClientPatchRequest(t *testing.T) {
+	var tests = map[string]struct {
+		patch           func(rc *ResourceClient) (*unstructured.Unstructured, error)
+		wantContentType string
+		wantBody        string
+	}{
+		"strategic merge patch": {
+			patch: func(rc *ResourceClient) (*unstructured.Unstructured, error) {
+				return rc.StrategicMergePatch(
+					"my-pod", []byte(`{"spec":{}}`), metav1.PatchOptions{},
+				)
+			},
+			wantContentType: string(types.StrategicMergePatchType),
+			wantBody:        `{"spec":{}}`,
+		},
+		"merge patch": {
+			patch: func(rc *ResourceClient) (*unstructured.Unstructured, error) {
+				return rc.MergePatch(
+					"my-pod", []byte(`{"spec":{}}`), metav1.PatchOptions{},
+				)
+			},
+			wantContentType: string(types.MergePatchType),
+			wantBody:        `{"spec":{}}`,
+		},
+		"json patch": {
+			patch: func(rc *ResourceClient) (*unstructured.Unstructured, error) {
+				return rc.JSONPatch(
+					"my-pod", []byte(`[{"op":"remove","path":"/spec"}]`), metav1.PatchOptions{},
+				)
+			},
+			wantContentType: string(types.JSONPatchType),
+			wantBody:        `[{"op":"remove","path":"/spec"}]`,
+		},
+		"apply patch": {
+			patch: func(rc *ResourceClient) (*unstructured.Unstructured, error) {
+				return rc.ApplyPatch(newPod(), metav1.PatchOptions{FieldManager: "metac"})
+			},
+			wantContentType: string(types.ApplyPatchType),
+			wantBody: `{"apiVersion":"v1","kind":"Pod",` +
+				`"metadata":{"name":"my-pod","namespace":"default"}}`,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			server, getRequests := newRecordServer(t)
+			defer server.Close()
+
+			cs, err := New(&rest.Config{Host: server.URL}, nil)
+			if err != nil {
+				t.Fatalf("Expected no error got %v", err)
+			}
+			_, err = mock.patch(cs.resource(podResource, false).Namespace("default"))
+			if err != nil {
+				t.Fatalf("Expected no error got %v", err)
+			}
+			got := getRequests()
+			if len(got) != 1 {
+				t.Fatalf("Expected 1 request got %d", len(got))
+			}
+			if got[0].method != http.MethodPatch {
+				t.Fatalf("Expected method %s got %s", http.MethodPatch, got[0].method)
+			}
+			if got[0].path != "/api/v1/namespaces/default/pods/my-pod" {
+				t.Fatalf("Expected path of my-pod got %q", got[0].path)
+			}
+			if got[0].contentType != mock.wantContentType {
+				t.Fatalf(
+					"Expected content type %q got %q", mock.wantContentType, got[0].contentType,
+				)
+			}
+			if string(got[0].body) != mock.wantBody {
+				t.Fatalf("Expected body %s got %s", mock.wantBody, got[0].body)
+			}
+		})
+	}
+}
+
+func TestResourceClientCreatePatch(t *testing.T) {
+	testResource := &dynamicdiscovery.APIResource{
+		APIResource: metav1.APIResource{
+			Name:       "tests",
+			Kind:       "Test",
+			Group:      "test.io",
+			Version:    "v1",
+			Namespaced: true,
+		},
+		APIVersion: "test.io/v1",
+	}
+	newObj := func(resourceVersion string, labels map[string]string) *unstructured.Unstructured {
+		obj := newPod()
+		obj.SetResourceVersion(resourceVersion)
+		obj.SetLabels(labels)
+		return obj
+	}
+	var tests = map[string]struct {
+		resource *dynamicdiscovery.APIResource
+		pt       types.PatchType
+		original *unstructured.Unstructured
+		modified *unstructured.Unstructured
+		want     string
+		isErr    bool
+	}{
+		"json patch": {
+			resource: podResource,
+			pt:       types.JSONPatchType,
+			original: newObj("1", map[string]string{"app": "old", "env": "dev"}),
+			modified: newObj("1", map[string]string{"app": "new", "tier/x": "db"}),
+			want: `[` +
+				`{"op":"replace","path":"/metadata/resourceVersion","value":"1"},` +
+				`{"op":"replace","path":"/metadata/labels/app","value":"new"},` +
+				`{"op":"remove","path":"/metadata/labels/env"},` +
+				`{"op":"add","path":"/metadata/labels/tier~1x","value":"db"}` +
+				`]`,
+		},
+		"json patch without resource version": {
+			resource: podResource,
+			pt:       types.JSONPatchType,
+			original: newObj("", map[string]string{"app": "old"}),
+			modified: newObj("", map[string]string{"app": "new"}),
+			want:     `[{"op":"replace","path":"/metadata/labels/app","value":"new"}]`,
+		},
+		"merge patch": {
+			resource: testResource,
+			pt:       types.MergePatchType,
+			original: newObj("1", map[string]string{"app": "old", "env": "dev"}),
+			modified: newObj("1", map[string]string{"app": "new"}),
+			want: `{"metadata":{"labels":{"app":"new","env":null},` +
+				`"resourceVersion":"1"}}`,
+		},
+		"merge patch without resource version": {
+			resource: testResource,
+			pt:       types.MergePatchType,
+			original: newObj("", map[string]string{"app": "old"}),
+			modified: newObj("", map[string]string{"app": "new"}),
+			want:     `{"metadata":{"labels":{"app":"new"}}}`,
+		},
+		"strategic merge patch": {
+			resource: podResource,
+			pt:       types.StrategicMergePatchType,
+			original: newObj("1", map[string]string{"app": "old"}),
+			modified: newObj("1", map[string]string{"app": "new"}),
+			want:     `{"metadata":{"labels":{"app":"new"},"resourceVersion":"1"}}`,
+		},
+		"strategic merge patch of custom resource": {
+			resource: testResource,
+			pt:       types.StrategicMergePatchType,
+			original: newObj("1", nil),
+			modified: newObj("1", map[string]string{"app": "new"}),
+			isErr:    true,
+		},
+		"apply patch": {
+			resource: podResource,
+			pt:       types.ApplyPatchType,
+			original: newObj("1", nil),
+			modified: newObj("1", map[string]string{"app": "new"}),
+			isErr:    true,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			cs, err := New(&rest.Config{Host: "http://localhost"}, nil)
+			if err != nil {
+				t.Fatalf("Expected no error got %v", err)
+			}
+			got, err := cs.resource(mock.resource, false).CreatePatch(
+				mock.pt, mock.original, mock.modified,
+			)
+			if mock.isErr {
+				if err == nil {
+					t.Fatalf("Expected error got patch %s", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error got %v", err)
+			}
+			// compare as JSON since key order of merge patches may vary
+			var gotVal, wantVal interface{}
+			if err := json.Unmarshal(got, &gotVal); err != nil {
+				t.Fatalf("Expected valid patch got %s: %v", got, err)
+			}
+			if err := json.Unmarshal([]byte(mock.want), &wantVal); err != nil {
+				t.Fatalf("Expected valid want %s: %v", mock.want, err)
+			}
+			if !reflect.DeepEqual(gotVal, wantVal) {
+				t.Fatalf("Expected patch %s got %s", mock.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clientset
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+
+	dynamicdiscovery "openebs.io/metac/dynamic/discovery"
+)
+
+// parameterVersion is the version that list options are encoded to
+var parameterVersion = schema.GroupVersion{Version: "v1"}
+
+// newProtobufRESTClient returns a REST client that prefers protobuf
+// over json for both requests & responses
+func newProtobufRESTClient(config *rest.Config) (rest.Interface, error) {
+	c := rest.CopyConfig(config)
+	c.ContentType = runtime.ContentTypeProtobuf
+	c.AcceptContentTypes = runtime.ContentTypeProtobuf + "," + runtime.ContentTypeJSON
+	c.NegotiatedSerializer = scheme.Codecs.WithoutConversion()
+	if c.UserAgent == "" {
+		c.UserAgent = rest.DefaultKubernetesUserAgent()
+	}
+	return rest.UnversionedRESTClientFor(c)
+}
+
+// isProtobufResource returns true if the given resource is a built-in
+// type that can be listed & watched as protobuf
+//
+// NOTE:
+//	Custom resources are served as json only. Built-in types are the
+// ones known to the scheme of client-go.
+func isProtobufResource(apiResource *dynamicdiscovery.APIResource) bool {
+	if strings.Contains(apiResource.Name, "/") {
+		// sub resources are left to the dynamic client
+		return false
+	}
+	gvk := apiResource.GroupVersionKind()
+	return scheme.Scheme.Recognizes(gvk) &&
+		scheme.Scheme.Recognizes(gvk.GroupVersion().WithKind(gvk.Kind+"List"))
+}
+
+// protobufResourceClient lists & watches a built-in resource as
+// protobuf. All the other operations are delegated to the dynamic
+// client of this resource.
+type protobufResourceClient struct {
+	dynamic.ResourceInterface
+
+	root        dynamic.NamespaceableResourceInterface
+	restClient  rest.Interface
+	apiResource *dynamicdiscovery.APIResource
+	namespace   string
+}
+
+// protobufResourceClient implements dynamic.NamespaceableResourceInterface
+var _ dynamic.NamespaceableResourceInterface = &protobufResourceClient{}
+
+// newProtobufResourceClient returns a new client of the given
+// resource that lists & watches via the given REST client & does the
+// rest via the given dynamic client
+func newProtobufResourceClient(
+	root dynamic.NamespaceableResourceInterface,
+	restClient rest.Interface,
+	apiResource *dynamicdiscovery.APIResource,
+) *protobufResourceClient {
+	return &protobufResourceClient{
+		ResourceInterface: root,
+		root:              root,
+		restClient:        restClient,
+		apiResource:       apiResource,
+	}
+}
+
+// Namespace returns the client scoped to the given namespace
+func (c *protobufResourceClient) Namespace(namespace string) dynamic.ResourceInterface {
+	return &protobufResourceClient{
+		ResourceInterface: c.root.Namespace(namespace),
+		root:              c.root,
+		restClient:        c.restClient,
+		apiResource:       c.apiResource,
+		namespace:         namespace,
+	}
+}
+
+// request returns a GET request against this client's resource
+func (c *protobufResourceClient) request(opts metav1.ListOptions) *rest.Request {
+	absPath := []string{"/api"}
+	if c.apiResource.Group != "" {
+		absPath = []string{"/apis", c.apiResource.Group}
+	}
+	absPath = append(absPath, c.apiResource.Version)
+	return c.restClient.Get().
+		AbsPath(absPath...).
+		NamespaceIfScoped(c.namespace, c.namespace != "").
+		Resource(c.apiResource.Name).
+		SpecificallyVersionedParams(&opts, scheme.ParameterCodec, parameterVersion)
+}
+
+// toUnstructured returns the given typed object as an unstructured
+// instance of this client's resource
+func (c *protobufResourceClient) toUnstructured(
+	obj runtime.Object,
+) (*unstructured.Unstructured, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, errors.Wrapf(
+			err, "Failed to convert %T of resource %q", obj, c.apiResource.Name,
+		)
+	}
+	u := &unstructured.Unstructured{Object: content}
+	u.SetAPIVersion(c.apiResource.APIVersion)
+	u.SetKind(c.apiResource.Kind)
+	return u, nil
+}
+
+// List returns the resources selected by the given options
+func (c *protobufResourceClient) List(
+	opts metav1.ListOptions,
+) (*unstructured.UnstructuredList, error) {
+	obj, err := c.request(opts).Do().Get()
+	if err != nil {
+		return nil, err
+	}
+	listMeta, err := meta.ListAccessor(obj)
+	if err != nil {
+		return nil, errors.Wrapf(
+			err, "Failed to list resource %q: Invalid list %T", c.apiResource.Name, obj,
+		)
+	}
+	items, err := meta.ExtractList(obj)
+	if err != nil {
+		return nil, errors.Wrapf(
+			err, "Failed to list resource %q: Invalid list %T", c.apiResource.Name, obj,
+		)
+	}
+	result := &unstructured.UnstructuredList{}
+	result.SetAPIVersion(c.apiResource.APIVersion)
+	result.SetKind(c.apiResource.Kind + "List")
+	result.SetResourceVersion(listMeta.GetResourceVersion())
+	result.SetContinue(listMeta.GetContinue())
+	result.Items = make([]unstructured.Unstructured, 0, len(items))
+	for _, item := range items {
+		u, err := c.toUnstructured(item)
+		if err != nil {
+			return nil, err
+		}
+		result.Items = append(result.Items, *u)
+	}
+	return result, nil
+}
+
+// Watch watches the resources selected by the given options
+func (c *protobufResourceClient) Watch(opts metav1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	w, err := c.request(opts).Timeout(timeout).Watch()
+	if err != nil {
+		return nil, err
+	}
+	return watch.Filter(w, func(in watch.Event) (watch.Event, bool) {
+		if in.Type == watch.Error {
+			// errors are left as is
+			return in, true
+		}
+		// bookmarks are converted as well since informers expect
+		// every event to carry an unstructured instance
+		u, err := c.toUnstructured(in.Object)
+		if err != nil {
+			return watch.Event{
+				Type:   watch.Error,
+				Object: &metav1.Status{Status: metav1.StatusFailure, Message: err.Error()},
+			}, true
+		}
+		in.Object = u
+		return in, true
+	}), nil
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clientset
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/rest"
+
+	dynamicdiscovery "openebs.io/metac/dynamic/discovery"
+)
+
+// podJSON is a pod with a spec field unknown to the pod type of
+// this binary
+const podJSON = `{
+	"apiVersion": "v1",
+	"kind": "Pod",
+	"metadata": {"name": "my-pod", "namespace": "default", "resourceVersion": "1"},
+	"spec": {"containers": [{"name": "c", "image": "i"}], "futureField": "future"}
+}`
+
+// newPodServer returns a server that serves the pod list & records
+// the body of the pod updates
+func newPodServer(t *testing.T) (*httptest.Server, func() []byte) {
+	var mutex sync.Mutex
+	var updated []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+			w.Write([]byte(`{
+				"apiVersion": "v1",
+				"kind": "PodList",
+				"metadata": {"resourceVersion": "1"},
+				"items": [` + podJSON + `]
+			}`))
+		case http.MethodPut:
+			body, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				t.Errorf("Failed to read update: %v", err)
+			}
+			mutex.Lock()
+			updated = body
+			mutex.Unlock()
+			w.Write(body)
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	return server, func() []byte {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return updated
+	}
+}
+
+func TestClientsetProtobufUnknownFields(t *testing.T) {
+	var tests = map[string]struct {
+		isReadOnly    bool
+		isFieldListed bool
+	}{
+		"writable client round trips unknown fields": {
+			isReadOnly:    false,
+			isFieldListed: true,
+		},
+		"read only client decodes to the typed pod": {
+			isReadOnly:    true,
+			isFieldListed: false,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			server, getUpdated := newPodServer(t)
+			defer server.Close()
+
+			cs, err := New(
+				&rest.Config{Host: server.URL}, nil, WithProtobuf(true),
+			)
+			if err != nil {
+				t.Fatalf("Expected no error got %v", err)
+			}
+			client := cs.resource(&dynamicdiscovery.APIResource{
+				APIResource: metav1.APIResource{
+					Name:       "pods",
+					Kind:       "Pod",
+					Version:    "v1",
+					Namespaced: true,
+				},
+				APIVersion: "v1",
+			}, mock.isReadOnly).Namespace("default")
+
+			list, err := client.List(metav1.ListOptions{})
+			if err != nil {
+				t.Fatalf("Expected no error got %v", err)
+			}
+			if len(list.Items) != 1 {
+				t.Fatalf("Expected 1 pod got %d", len(list.Items))
+			}
+			pod := &list.Items[0]
+			_, isFieldListed, _ := unstructured.NestedString(pod.Object, "spec", "futureField")
+			if isFieldListed != mock.isFieldListed {
+				t.Fatalf(
+					"Expected listed futureField %t got %t", mock.isFieldListed, isFieldListed,
+				)
+			}
+			if mock.isReadOnly {
+				return
+			}
+
+			// the listed pod is updated as is
+			_, err = client.Update(pod, metav1.UpdateOptions{})
+			if err != nil {
+				t.Fatalf("Expected no error got %v", err)
+			}
+			var updated map[string]interface{}
+			err = json.Unmarshal(getUpdated(), &updated)
+			if err != nil {
+				t.Fatalf("Expected no error got %v", err)
+			}
+			got, _, _ := unstructured.NestedString(updated, "spec", "futureField")
+			if got != "future" {
+				t.Fatalf("Expected updated futureField %q got %q", "future", got)
+			}
+		})
+	}
+}
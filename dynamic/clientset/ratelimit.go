@@ -0,0 +1,291 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clientset
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/flowcontrol"
+
+	dynamicdiscovery "openebs.io/metac/dynamic/discovery"
+)
+
+// throttleLogThreshold is the time spent waiting for the rate limiter
+// beyond which the request is logged
+const throttleLogThreshold = time.Second
+
+// RateLimit represents the queries per second & the burst allowed
+// against a single resource
+type RateLimit struct {
+	QPS   float32
+	Burst int
+}
+
+// IsEnabled returns true if requests are limited as per this limit
+func (l RateLimit) IsEnabled() bool {
+	return l.QPS > 0 && l.Burst > 0
+}
+
+// String implements Stringer interface
+func (l RateLimit) String() string {
+	return fmt.Sprintf("%g:%d", l.QPS, l.Burst)
+}
+
+// WithResourceRateLimits limits the requests made by the clients of
+// each resource i.e. each group, version & resource as per the given
+// limits. Resources are keyed by resource & apiVersion e.g. pods.v1
+// or deployments.apps/v1. Resources that are not found in the given
+// limits are limited as per the given default limit.
+//
+// NOTE:
+//	These limits are in addition to the limit of the rest config that
+// is shared by all the resources. This prevents the requests of a
+// single resource from starving the requests of other resources.
+// Watches are not limited.
+func WithResourceRateLimits(
+	defaultLimit RateLimit, limits map[string]RateLimit,
+) ClientsetOption {
+	return func(cs *Clientset) error {
+		cs.defaultRateLimit = defaultLimit
+		cs.rateLimits = limits
+		return nil
+	}
+}
+
+// getRateLimiter returns the rate limiter of the given resource. Nil
+// is returned if the requests of this resource are not limited.
+func (cs *Clientset) getRateLimiter(
+	apiResource *dynamicdiscovery.APIResource,
+) flowcontrol.RateLimiter {
+	key := fmt.Sprintf("%s.%s", apiResource.Name, apiResource.APIVersion)
+	limit, found := cs.rateLimits[key]
+	if !found {
+		limit = cs.defaultRateLimit
+	}
+	if !limit.IsEnabled() {
+		return nil
+	}
+
+	return cs.rateLimiters.get(apiResource.GroupVersionResource(), limit)
+}
+
+// resourceRateLimiters holds the rate limiters of the resources that
+// are limited
+type resourceRateLimiters struct {
+	mutex    sync.Mutex
+	limiters map[schema.GroupVersionResource]flowcontrol.RateLimiter
+}
+
+// newResourceRateLimiters returns a new instance of resourceRateLimiters
+func newResourceRateLimiters() *resourceRateLimiters {
+	return &resourceRateLimiters{
+		limiters: make(map[schema.GroupVersionResource]flowcontrol.RateLimiter),
+	}
+}
+
+// get returns the rate limiter of the given resource. The limiter is
+// created as per the given limit if it does not exist.
+func (r *resourceRateLimiters) get(
+	gvr schema.GroupVersionResource, limit RateLimit,
+) flowcontrol.RateLimiter {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	limiter, found := r.limiters[gvr]
+	if !found {
+		limiter = flowcontrol.NewTokenBucketRateLimiter(limit.QPS, limit.Burst)
+		r.limiters[gvr] = limiter
+	}
+	return limiter
+}
+
+// withRateLimiter returns the given client with its requests limited
+// by the rate limiter of the given resource. Time spent waiting for
+// the rate limiter is recorded via the given metrics.
+func (cs *Clientset) withRateLimiter(
+	client dynamic.NamespaceableResourceInterface,
+	apiResource *dynamicdiscovery.APIResource,
+	metrics *requestMetrics,
+) dynamic.NamespaceableResourceInterface {
+	limiter := cs.getRateLimiter(apiResource)
+	if limiter == nil {
+		return client
+	}
+	return &rateLimitedResourceClient{
+		ResourceInterface: client,
+		root:              client,
+		limiter:           limiter,
+		resource:          apiResource.Name,
+		metrics:           metrics,
+	}
+}
+
+// rateLimitedResourceClient waits for its rate limiter before every
+// request other than watch
+type rateLimitedResourceClient struct {
+	dynamic.ResourceInterface
+
+	root     dynamic.NamespaceableResourceInterface
+	limiter  flowcontrol.RateLimiter
+	resource string
+	metrics  *requestMetrics
+}
+
+// rateLimitedResourceClient implements dynamic.NamespaceableResourceInterface
+var _ dynamic.NamespaceableResourceInterface = &rateLimitedResourceClient{}
+
+// Namespace returns the client scoped to the given namespace
+func (c *rateLimitedResourceClient) Namespace(namespace string) dynamic.ResourceInterface {
+	return &rateLimitedResourceClient{
+		ResourceInterface: c.root.Namespace(namespace),
+		root:              c.root,
+		limiter:           c.limiter,
+		resource:          c.resource,
+		metrics:           c.metrics,
+	}
+}
+
+// wait blocks till the rate limiter allows the next request
+func (c *rateLimitedResourceClient) wait(verb string) {
+	start := time.Now()
+	c.limiter.Accept()
+	waited := time.Since(start)
+	c.metrics.recordRateLimiterWait(verb, waited)
+	if waited > throttleLogThreshold {
+		glog.V(3).Infof(
+			"Throttled %s request of resource %q for %v: Resource rate limit reached",
+			verb, c.resource, waited,
+		)
+	}
+}
+
+// Create waits for the rate limiter & creates the given resource
+func (c *rateLimitedResourceClient) Create(
+	obj *unstructured.Unstructured, options metav1.CreateOptions, subresources ...string,
+) (*unstructured.Unstructured, error) {
+	c.wait(verbCreate)
+	return c.ResourceInterface.Create(obj, options, subresources...)
+}
+
+// Update waits for the rate limiter & updates the given resource
+func (c *rateLimitedResourceClient) Update(
+	obj *unstructured.Unstructured, options metav1.UpdateOptions, subresources ...string,
+) (*unstructured.Unstructured, error) {
+	c.wait(verbUpdate)
+	return c.ResourceInterface.Update(obj, options, subresources...)
+}
+
+// UpdateStatus waits for the rate limiter & updates the status of the
+// given resource
+func (c *rateLimitedResourceClient) UpdateStatus(
+	obj *unstructured.Unstructured, options metav1.UpdateOptions,
+) (*unstructured.Unstructured, error) {
+	c.wait(verbUpdateStatus)
+	return c.ResourceInterface.UpdateStatus(obj, options)
+}
+
+// Delete waits for the rate limiter & deletes the resource with the
+// given name
+func (c *rateLimitedResourceClient) Delete(
+	name string, options *metav1.DeleteOptions, subresources ...string,
+) error {
+	c.wait(verbDelete)
+	return c.ResourceInterface.Delete(name, options, subresources...)
+}
+
+// DeleteCollection waits for the rate limiter & deletes the resources
+// selected by the given options
+func (c *rateLimitedResourceClient) DeleteCollection(
+	options *metav1.DeleteOptions, listOptions metav1.ListOptions,
+) error {
+	c.wait(verbDeleteCollection)
+	return c.ResourceInterface.DeleteCollection(options, listOptions)
+}
+
+// Get waits for the rate limiter & returns the resource with the
+// given name
+func (c *rateLimitedResourceClient) Get(
+	name string, options metav1.GetOptions, subresources ...string,
+) (*unstructured.Unstructured, error) {
+	c.wait(verbGet)
+	return c.ResourceInterface.Get(name, options, subresources...)
+}
+
+// List waits for the rate limiter & returns the resources selected
+// by the given options
+func (c *rateLimitedResourceClient) List(
+	opts metav1.ListOptions,
+) (*unstructured.UnstructuredList, error) {
+	c.wait(verbList)
+	return c.ResourceInterface.List(opts)
+}
+
+// Watch watches the resources selected by the given options without
+// waiting for the rate limiter
+func (c *rateLimitedResourceClient) Watch(opts metav1.ListOptions) (watch.Interface, error) {
+	return c.ResourceInterface.Watch(opts)
+}
+
+// Patch waits for the rate limiter & patches the resource with the
+// given name
+func (c *rateLimitedResourceClient) Patch(
+	name string,
+	pt types.PatchType,
+	data []byte,
+	options metav1.PatchOptions,
+	subresources ...string,
+) (*unstructured.Unstructured, error) {
+	c.wait(verbPatch)
+	return c.ResourceInterface.Patch(name, pt, data, options, subresources...)
+}
+
+// WithLimits returns a copy of this clientset whose requests are
+// limited as per the given limit & complete within the given timeout.
+// Requests are not limited or timed out by the copy if the limit is
+// not enabled or the timeout is not set respectively.
+//
+// NOTE:
+//	The given limit replaces the limit of the rest config of this
+// clientset. Clients of the copy share this limit. Rate limiters of
+// the resources are shared with this clientset.
+func (cs *Clientset) WithLimits(limit RateLimit, timeout time.Duration) (*Clientset, error) {
+	config := rest.CopyConfig(&cs.config)
+	if limit.IsEnabled() {
+		config.QPS = limit.QPS
+		config.Burst = limit.Burst
+		config.RateLimiter = flowcontrol.NewTokenBucketRateLimiter(limit.QPS, limit.Burst)
+	}
+	if timeout > 0 {
+		config.Timeout = timeout
+	}
+	lcs, err := cs.withConfig(config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to limit clients to %s with timeout %v", limit, timeout)
+	}
+	return lcs, nil
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clientset
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	dynamicdiscovery "openebs.io/metac/dynamic/discovery"
+)
+
+// newAPIResource returns the discovered resource of the given name &
+// apiVersion
+func newAPIResource(apiVersion, name string) *dynamicdiscovery.APIResource {
+	return &dynamicdiscovery.APIResource{
+		APIResource: metav1.APIResource{Name: name},
+		APIVersion:  apiVersion,
+	}
+}
+
+func TestClientsetGetRateLimiter(t *testing.T) {
+	var tests = map[string]struct {
+		defaultLimit RateLimit
+		limits       map[string]RateLimit
+		resource     *dynamicdiscovery.APIResource
+		wantQPS      float32
+		isLimited    bool
+	}{
+		"no limits": {
+			resource: newAPIResource("v1", "pods"),
+		},
+		"default limit": {
+			defaultLimit: RateLimit{QPS: 5, Burst: 10},
+			resource:     newAPIResource("v1", "pods"),
+			wantQPS:      5,
+			isLimited:    true,
+		},
+		"core resource limit": {
+			defaultLimit: RateLimit{QPS: 5, Burst: 10},
+			limits: map[string]RateLimit{
+				"pods.v1": {QPS: 20, Burst: 40},
+			},
+			resource:  newAPIResource("v1", "pods"),
+			wantQPS:   20,
+			isLimited: true,
+		},
+		"grouped resource limit": {
+			limits: map[string]RateLimit{
+				"deployments.apps/v1": {QPS: 2, Burst: 4},
+			},
+			resource:  newAPIResource("apps/v1", "deployments"),
+			wantQPS:   2,
+			isLimited: true,
+		},
+		"limit of another version": {
+			defaultLimit: RateLimit{QPS: 5, Burst: 10},
+			limits: map[string]RateLimit{
+				"deployments.apps/v1beta1": {QPS: 2, Burst: 4},
+			},
+			resource:  newAPIResource("apps/v1", "deployments"),
+			wantQPS:   5,
+			isLimited: true,
+		},
+		"resource limit disables default limit": {
+			defaultLimit: RateLimit{QPS: 5, Burst: 10},
+			limits: map[string]RateLimit{
+				"pods.v1": {},
+			},
+			resource: newAPIResource("v1", "pods"),
+		},
+		"default limit without burst": {
+			defaultLimit: RateLimit{QPS: 5},
+			resource:     newAPIResource("v1", "pods"),
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			cs := &Clientset{rateLimiters: newResourceRateLimiters()}
+			err := WithResourceRateLimits(mock.defaultLimit, mock.limits)(cs)
+			if err != nil {
+				t.Fatalf("Expected no error got %v", err)
+			}
+			limiter := cs.getRateLimiter(mock.resource)
+			if isLimited := limiter != nil; isLimited != mock.isLimited {
+				t.Fatalf("Expected limited %t got %t", mock.isLimited, isLimited)
+			}
+			if !mock.isLimited {
+				return
+			}
+			if limiter.QPS() != mock.wantQPS {
+				t.Fatalf("Expected QPS %g got %g", mock.wantQPS, limiter.QPS())
+			}
+			// clients of the same resource share the limiter
+			if got := cs.getRateLimiter(mock.resource); got != limiter {
+				t.Fatalf("Expected shared rate limiter got a new one")
+			}
+		})
+	}
+}
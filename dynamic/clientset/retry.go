@@ -0,0 +1,98 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clientset
+
+import (
+	"github.com/golang/glog"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// UpdateWithRetries updates the given object. An update that fails
+// due to a conflict i.e. the object was changed since it was read, is
+// retried at most the given number of times. Each retry fetches the
+// latest state of the object & invokes the given function to set the
+// changes against this latest state.
+//
+// The reapply() func should modify the passed object and return true to go
+// ahead with the update, or false if no update is required anymore.
+//
+// NOTE:
+//	Unlike AtomicUpdate, the first attempt updates the given object as
+// is i.e. without fetching it. This saves a GET when there is no
+// conflict.
+func (rc *ResourceClient) UpdateWithRetries(
+	obj *unstructured.Unstructured,
+	options metav1.UpdateOptions,
+	maxRetries int,
+	reapply func(latest *unstructured.Unstructured) bool,
+) (*unstructured.Unstructured, error) {
+	return rc.updateWithRetries(
+		obj, maxRetries, reapply, func(o *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+			return rc.Update(o, options)
+		},
+	)
+}
+
+// UpdateStatusWithRetries is similar to UpdateWithRetries, except that
+// it updates the status sub resource of the given object.
+func (rc *ResourceClient) UpdateStatusWithRetries(
+	obj *unstructured.Unstructured,
+	options metav1.UpdateOptions,
+	maxRetries int,
+	reapply func(latest *unstructured.Unstructured) bool,
+) (*unstructured.Unstructured, error) {
+	return rc.updateWithRetries(
+		obj, maxRetries, reapply, func(o *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+			return rc.UpdateStatus(o, options)
+		},
+	)
+}
+
+// updateWithRetries updates the given object via the given update
+// function & retries this update on conflicts
+func (rc *ResourceClient) updateWithRetries(
+	obj *unstructured.Unstructured,
+	maxRetries int,
+	reapply func(latest *unstructured.Unstructured) bool,
+	update func(obj *unstructured.Unstructured) (*unstructured.Unstructured, error),
+) (*unstructured.Unstructured, error) {
+	result, err := update(obj)
+	for retry := 1; retry <= maxRetries && apierrors.IsConflict(err); retry++ {
+		glog.V(3).Infof(
+			"Will retry update of %s %s/%s: Attempt %d of %d: %v",
+			rc.Kind, obj.GetNamespace(), obj.GetName(), retry, maxRetries, err,
+		)
+		latest, getErr := rc.Get(obj.GetName(), metav1.GetOptions{})
+		if getErr != nil {
+			// error is returned as is to let the callers check for
+			// not found errors
+			return nil, getErr
+		}
+		if obj.GetUID() != "" && latest.GetUID() != obj.GetUID() {
+			// The original object was deleted and replaced with a new one.
+			return nil, apierrors.NewNotFound(rc.GroupResource(), obj.GetName())
+		}
+		if changed := reapply(latest); !changed {
+			// There's nothing to do.
+			return latest, nil
+		}
+		result, err = update(latest)
+	}
+	return result, err
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clientset
+
+import (
+	"github.com/pkg/errors"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// scaleSubresource is the name of the subresource that reads & sets
+// the replicas of a scalable resource
+const scaleSubresource = "scale"
+
+// HasScale returns true if the resource of this client serves the
+// scale subresource e.g. deployments, statefulsets or custom resources
+// whose definitions enable the scale subresource
+func (rc *ResourceClient) HasScale() bool {
+	return rc.HasSubresource(scaleSubresource)
+}
+
+// GetScale returns the scale of the resource with the given name
+func (rc *ResourceClient) GetScale(
+	name string, options metav1.GetOptions,
+) (*autoscalingv1.Scale, error) {
+	if !rc.HasScale() {
+		return nil, errors.Errorf("Resource %q doesn't serve %q", rc.Name, scaleSubresource)
+	}
+	obj, err := rc.Get(name, options, scaleSubresource)
+	if err != nil {
+		return nil, err
+	}
+	return toScale(obj)
+}
+
+// UpdateScale updates the scale of the resource with the name of the
+// given scale
+//
+// NOTE:
+//	Scale's resource version if set makes this update fail with a
+// conflict if the resource got changed after this scale was read
+func (rc *ResourceClient) UpdateScale(
+	scale *autoscalingv1.Scale, options metav1.UpdateOptions,
+) (*autoscalingv1.Scale, error) {
+	if !rc.HasScale() {
+		return nil, errors.Errorf("Resource %q doesn't serve %q", rc.Name, scaleSubresource)
+	}
+	scale = scale.DeepCopy()
+	scale.APIVersion = autoscalingv1.SchemeGroupVersion.String()
+	scale.Kind = "Scale"
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(scale)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Can't convert scale of %q", scale.Name)
+	}
+	obj, err := rc.Update(
+		&unstructured.Unstructured{Object: content}, options, scaleSubresource,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return toScale(obj)
+}
+
+// toScale converts the given object that is served by the scale
+// subresource to a scale
+func toScale(obj *unstructured.Unstructured) (*autoscalingv1.Scale, error) {
+	scale := &autoscalingv1.Scale{}
+	err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), scale)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Can't convert %q to scale", obj.GetName())
+	}
+	return scale, nil
+}
@@ -0,0 +1,244 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clientset
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	// warningHeader is the response header that holds the warnings
+	// of the API server e.g. deprecations & admission warnings
+	warningHeader = "Warning"
+
+	// warningCodeMisc is the only warning code sent by the API server
+	warningCodeMisc = 299
+
+	// maxRecordedWarnings is the maximum number of distinct warnings
+	// that are retained by a WarningRecorder
+	maxRecordedWarnings = 10
+)
+
+var (
+	// warningsMeasure counts the warnings sent by the API server in
+	// response to the requests of the dynamic clients
+	warningsMeasure = stats.Int64(
+		"metac/client_warnings",
+		"Number of warnings returned by the API server to the dynamic clients",
+		stats.UnitDimensionless,
+	)
+)
+
+// WarningRecorder captures the warnings sent by the API server in
+// response to the requests made on behalf of a single controller
+//
+// NOTE:
+//	Only the most recently seen distinct warnings are retained
+type WarningRecorder struct {
+	controller string
+
+	// context tagged with the controller
+	ctx context.Context
+
+	mutex sync.Mutex
+
+	// warnings keyed by their text & mapped to the sequence in
+	// which these were last seen
+	warnings map[string]int64
+	seq      int64
+}
+
+// NewWarningRecorder returns a new instance of WarningRecorder for
+// the given controller
+func NewWarningRecorder(controller string) *WarningRecorder {
+	ctx, err := tag.New(context.Background(), tag.Upsert(controllerKey, controller))
+	if err != nil {
+		glog.Warningf("Failed to tag warning metrics of controller %q: %v", controller, err)
+		ctx = context.Background()
+	}
+	return &WarningRecorder{
+		controller: controller,
+		ctx:        ctx,
+		warnings:   make(map[string]int64),
+	}
+}
+
+// Record captures the given warning
+func (r *WarningRecorder) Record(text string) {
+	stats.Record(r.ctx, warningsMeasure.M(1))
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.seq++
+	if _, found := r.warnings[text]; !found {
+		glog.Warningf("%s: API server warning: %s", r.controller, text)
+		if len(r.warnings) >= maxRecordedWarnings {
+			r.evictOldest()
+		}
+	}
+	r.warnings[text] = r.seq
+}
+
+// evictOldest stops retaining the warning that was seen least
+// recently
+func (r *WarningRecorder) evictOldest() {
+	var oldest string
+	var oldestSeq int64
+	for text, seq := range r.warnings {
+		if oldest == "" || seq < oldestSeq {
+			oldest, oldestSeq = text, seq
+		}
+	}
+	delete(r.warnings, oldest)
+}
+
+// List returns the retained warnings sorted by their text
+func (r *WarningRecorder) List() []string {
+	if r == nil {
+		return nil
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if len(r.warnings) == 0 {
+		return nil
+	}
+	warnings := make([]string, 0, len(r.warnings))
+	for text := range r.warnings {
+		warnings = append(warnings, text)
+	}
+	sort.Strings(warnings)
+	return warnings
+}
+
+// WithWarnings returns a copy of this clientset whose clients capture
+// the warnings of the API server in the given recorder
+//
+// NOTE:
+//	The copy shares the rate limiters of this clientset. Requests of
+// the shared informers are not made via this copy & hence their
+// warnings are not captured.
+func (cs *Clientset) WithWarnings(recorder *WarningRecorder) (*Clientset, error) {
+	config := rest.CopyConfig(&cs.config)
+	config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &warningRoundTripper{delegate: rt, recorder: recorder}
+	})
+	wcs, err := cs.withConfig(config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to capture warnings of %q", recorder.controller)
+	}
+	return wcs, nil
+}
+
+// warningRoundTripper captures the warnings found in the responses
+// of the API server
+type warningRoundTripper struct {
+	delegate http.RoundTripper
+	recorder *WarningRecorder
+}
+
+// RoundTrip implements http.RoundTripper interface
+func (rt *warningRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := rt.delegate.RoundTrip(req)
+	if resp != nil {
+		for _, header := range resp.Header[warningHeader] {
+			for _, text := range parseWarningHeader(header) {
+				rt.recorder.Record(text)
+			}
+		}
+	}
+	return resp, err
+}
+
+// parseWarningHeader returns the texts of the warnings found in the
+// given header value. Warnings are expected in the format sent by the
+// API server i.e. 299 - "text" & are separated by commas. Warnings
+// with other codes & the ones that are malformed are ignored.
+func parseWarningHeader(header string) []string {
+	var texts []string
+	for {
+		header = strings.TrimLeft(header, " ,")
+		if header == "" {
+			return texts
+		}
+		// code
+		parts := strings.SplitN(header, " ", 3)
+		if len(parts) != 3 {
+			return texts
+		}
+		code, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return texts
+		}
+		// agent is not used
+		text, remainder, ok := parseQuotedString(strings.TrimLeft(parts[2], " "))
+		if !ok {
+			return texts
+		}
+		if code == warningCodeMisc && text != "" {
+			texts = append(texts, text)
+		}
+		// optional date
+		remainder = strings.TrimLeft(remainder, " ")
+		if strings.HasPrefix(remainder, `"`) {
+			_, remainder, ok = parseQuotedString(remainder)
+			if !ok {
+				return texts
+			}
+		}
+		header = remainder
+	}
+}
+
+// parseQuotedString returns the unescaped content of the quoted
+// string found at the start of the given value & the remainder of
+// this value
+func parseQuotedString(value string) (string, string, bool) {
+	if !strings.HasPrefix(value, `"`) {
+		return "", "", false
+	}
+	var content strings.Builder
+	isEscaped := false
+	for idx := 1; idx < len(value); idx++ {
+		c := value[idx]
+		switch {
+		case isEscaped:
+			content.WriteByte(c)
+			isEscaped = false
+		case c == '\\':
+			isEscaped = true
+		case c == '"':
+			return content.String(), value[idx+1:], true
+		default:
+			content.WriteByte(c)
+		}
+	}
+	return "", "", false
+}
@@ -0,0 +1,205 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+const (
+	// aggregatedDiscoveryAccept is the content type that requests the
+	// aggregated discovery document. Servers that do not support it
+	// respond with the legacy list of groups.
+	aggregatedDiscoveryAccept = "application/json;g=apidiscovery.k8s.io;v=v2;as=APIGroupDiscoveryList," +
+		"application/json;g=apidiscovery.k8s.io;v=v2beta1;as=APIGroupDiscoveryList," +
+		"application/json"
+
+	// aggregatedDiscoveryKind is the kind of the aggregated discovery
+	// document
+	aggregatedDiscoveryKind = "APIGroupDiscoveryList"
+
+	// aggregatedDiscoveryStale is the freshness of a group version
+	// whose resources could not be discovered recently
+	aggregatedDiscoveryStale = "Stale"
+)
+
+// apiGroupDiscoveryList is the aggregated discovery document that
+// holds all the resources of all the groups served at an endpoint
+//
+// NOTE:
+//	Only the fields that are needed to build the discovery cache are
+// declared here since client-go in use does not provide these types
+type apiGroupDiscoveryList struct {
+	metav1.TypeMeta `json:",inline"`
+
+	Items []apiGroupDiscovery `json:"items"`
+}
+
+// apiGroupDiscovery holds the resources of all versions of a group
+type apiGroupDiscovery struct {
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Versions are sorted in the order of their preference
+	Versions []apiVersionDiscovery `json:"versions,omitempty"`
+}
+
+// apiVersionDiscovery holds the resources of a group version
+type apiVersionDiscovery struct {
+	Version   string                 `json:"version"`
+	Resources []apiResourceDiscovery `json:"resources,omitempty"`
+	Freshness string                 `json:"freshness,omitempty"`
+}
+
+// apiResourceDiscovery represents a resource & its sub resources
+type apiResourceDiscovery struct {
+	Resource         string                    `json:"resource"`
+	ResponseKind     *metav1.GroupVersionKind  `json:"responseKind,omitempty"`
+	Scope            string                    `json:"scope"`
+	SingularResource string                    `json:"singularResource"`
+	Verbs            []string                  `json:"verbs"`
+	ShortNames       []string                  `json:"shortNames,omitempty"`
+	Categories       []string                  `json:"categories,omitempty"`
+	Subresources     []apiSubresourceDiscovery `json:"subresources,omitempty"`
+}
+
+// apiSubresourceDiscovery represents a sub resource of a resource
+type apiSubresourceDiscovery struct {
+	Subresource  string                   `json:"subresource"`
+	ResponseKind *metav1.GroupVersionKind `json:"responseKind,omitempty"`
+	Verbs        []string                 `json:"verbs"`
+}
+
+// serverAggregatedResources returns all the server resources via the
+// aggregated discovery API. Resources of a group are listed in the
+// order of preference of its versions. Group versions whose resources
+// could not be discovered are returned along with their errors.
+//
+// NOTE:
+//	Returned bool is false if the server does not support aggregated
+// discovery. Legacy discovery should be used in this case.
+func (mgr *APIResourceManager) serverAggregatedResources() (
+	[]*metav1.APIResourceList, map[string]error, bool, error,
+) {
+	if mgr.Client.RESTClient() == nil {
+		return nil, nil, false, nil
+	}
+	var apiResourceSetList []*metav1.APIResourceList
+	failed := make(map[string]error)
+	// core group is served at /api & other groups at /apis
+	for _, path := range []string{"/api", "/apis"} {
+		data, err := mgr.Client.RESTClient().Get().
+			AbsPath(path).
+			SetHeader("Accept", aggregatedDiscoveryAccept).
+			Do().
+			Raw()
+		if err != nil {
+			return nil, nil, false, errors.Wrapf(
+				err, "Aggregated discovery at %q failed", path,
+			)
+		}
+		var list apiGroupDiscoveryList
+		err = json.Unmarshal(data, &list)
+		if err != nil {
+			return nil, nil, false, errors.Wrapf(
+				err, "Aggregated discovery at %q failed: Invalid response", path,
+			)
+		}
+		if list.Kind != aggregatedDiscoveryKind {
+			// server responded with the legacy list of groups
+			return nil, nil, false, nil
+		}
+		apiResourceSetList = append(
+			apiResourceSetList, toAPIResourceLists(list, failed)...,
+		)
+	}
+	return apiResourceSetList, failed, true, nil
+}
+
+// toAPIResourceLists returns the given aggregated discovery document
+// as resource lists similar to the ones returned by legacy discovery.
+// Group versions whose resources are stale are set against the given
+// failed group versions.
+func toAPIResourceLists(
+	list apiGroupDiscoveryList, failed map[string]error,
+) []*metav1.APIResourceList {
+	var apiResourceSetList []*metav1.APIResourceList
+	for _, group := range list.Items {
+		for _, version := range group.Versions {
+			gv := schema.GroupVersion{Group: group.Name, Version: version.Version}
+			if version.Freshness == aggregatedDiscoveryStale {
+				// legacy discovery too can't discover these
+				failed[gv.String()] = errors.Errorf(
+					"Resources of %q are stale", gv.String(),
+				)
+				continue
+			}
+			apiResourceSet := &metav1.APIResourceList{
+				GroupVersion: gv.String(),
+			}
+			for _, res := range version.Resources {
+				apiResource := metav1.APIResource{
+					Name:         res.Resource,
+					SingularName: res.SingularResource,
+					Namespaced:   strings.EqualFold(res.Scope, "Namespaced"),
+					Verbs:        res.Verbs,
+					ShortNames:   res.ShortNames,
+					Categories:   res.Categories,
+				}
+				setResponseKind(&apiResource, gv, res.ResponseKind)
+				apiResourceSet.APIResources = append(
+					apiResourceSet.APIResources, apiResource,
+				)
+				for _, sub := range res.Subresources {
+					subResource := metav1.APIResource{
+						Name:       res.Resource + "/" + sub.Subresource,
+						Namespaced: apiResource.Namespaced,
+						Verbs:      sub.Verbs,
+					}
+					setResponseKind(&subResource, gv, sub.ResponseKind)
+					apiResourceSet.APIResources = append(
+						apiResourceSet.APIResources, subResource,
+					)
+				}
+			}
+			apiResourceSetList = append(apiResourceSetList, apiResourceSet)
+		}
+	}
+	return apiResourceSetList
+}
+
+// setResponseKind sets the kind of the given resource. Group & version
+// are set only if these differ from the given group version similar to
+// legacy discovery.
+func setResponseKind(
+	apiResource *metav1.APIResource,
+	gv schema.GroupVersion,
+	kind *metav1.GroupVersionKind,
+) {
+	if kind == nil {
+		return
+	}
+	apiResource.Kind = kind.Kind
+	if kind.Group != gv.Group || kind.Version != gv.Version {
+		apiResource.Group = kind.Group
+		apiResource.Version = kind.Version
+	}
+}
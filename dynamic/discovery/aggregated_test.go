@@ -0,0 +1,248 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+)
+
+// aggregatedCoreJSON is the aggregated discovery document of the core
+// group
+const aggregatedCoreJSON = `{
+	"kind": "APIGroupDiscoveryList",
+	"apiVersion": "apidiscovery.k8s.io/v2",
+	"items": [{
+		"metadata": {"name": ""},
+		"versions": [{
+			"version": "v1",
+			"resources": [{
+				"resource": "pods",
+				"responseKind": {"group": "", "version": "v1", "kind": "Pod"},
+				"scope": "Namespaced",
+				"singularResource": "pod",
+				"verbs": ["get", "list", "watch"],
+				"shortNames": ["po"],
+				"categories": ["all"],
+				"subresources": [{
+					"subresource": "status",
+					"responseKind": {"group": "", "version": "v1", "kind": "Pod"},
+					"verbs": ["get", "update"]
+				}]
+			}]
+		}]
+	}]
+}`
+
+// aggregatedAppsJSON is the aggregated discovery document of the
+// groups other than the core group
+const aggregatedAppsJSON = `{
+	"kind": "APIGroupDiscoveryList",
+	"apiVersion": "apidiscovery.k8s.io/v2",
+	"items": [{
+		"metadata": {"name": "apps"},
+		"versions": [{
+			"version": "v1",
+			"resources": [{
+				"resource": "deployments",
+				"responseKind": {"group": "apps", "version": "v1", "kind": "Deployment"},
+				"scope": "Namespaced",
+				"singularResource": "deployment",
+				"verbs": ["get", "list"],
+				"subresources": [{
+					"subresource": "scale",
+					"responseKind": {"group": "autoscaling", "version": "v1", "kind": "Scale"},
+					"verbs": ["get", "update"]
+				}]
+			}]
+		}, {
+			"version": "v1beta1",
+			"freshness": "Stale"
+		}]
+	}]
+}`
+
+func TestToAPIResourceLists(t *testing.T) {
+	var tests = map[string]struct {
+		list       apiGroupDiscoveryList
+		want       []*metav1.APIResourceList
+		wantFailed []string
+	}{
+		"cluster scoped resource without response kind": {
+			list: apiGroupDiscoveryList{
+				Items: []apiGroupDiscovery{{
+					ObjectMeta: metav1.ObjectMeta{Name: "test.io"},
+					Versions: []apiVersionDiscovery{{
+						Version: "v1",
+						Resources: []apiResourceDiscovery{{
+							Resource: "tests",
+							Scope:    "Cluster",
+							Verbs:    []string{"get"},
+						}},
+					}},
+				}},
+			},
+			want: []*metav1.APIResourceList{
+				newResourceList("test.io/v1", metav1.APIResource{
+					Name:  "tests",
+					Verbs: []string{"get"},
+				}),
+			},
+		},
+		"sub resource of another group version": {
+			list: apiGroupDiscoveryList{
+				Items: []apiGroupDiscovery{{
+					ObjectMeta: metav1.ObjectMeta{Name: "apps"},
+					Versions: []apiVersionDiscovery{{
+						Version: "v1",
+						Resources: []apiResourceDiscovery{{
+							Resource:     "deployments",
+							ResponseKind: &metav1.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+							Scope:        "Namespaced",
+							Subresources: []apiSubresourceDiscovery{{
+								Subresource:  "scale",
+								ResponseKind: &metav1.GroupVersionKind{Group: "autoscaling", Version: "v1", Kind: "Scale"},
+							}},
+						}},
+					}},
+				}},
+			},
+			want: []*metav1.APIResourceList{
+				newResourceList(
+					"apps/v1",
+					metav1.APIResource{Name: "deployments", Kind: "Deployment", Namespaced: true},
+					metav1.APIResource{
+						Name:       "deployments/scale",
+						Kind:       "Scale",
+						Group:      "autoscaling",
+						Version:    "v1",
+						Namespaced: true,
+					},
+				),
+			},
+		},
+		"stale version": {
+			list: apiGroupDiscoveryList{
+				Items: []apiGroupDiscovery{{
+					ObjectMeta: metav1.ObjectMeta{Name: "metrics.k8s.io"},
+					Versions: []apiVersionDiscovery{{
+						Version:   "v1beta1",
+						Freshness: aggregatedDiscoveryStale,
+					}},
+				}},
+			},
+			wantFailed: []string{"metrics.k8s.io/v1beta1"},
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			failed := make(map[string]error)
+			got := toAPIResourceLists(mock.list, failed)
+			if !reflect.DeepEqual(got, mock.want) {
+				t.Fatalf("Expected %+v got %+v", mock.want, got)
+			}
+			if len(failed) != len(mock.wantFailed) {
+				t.Fatalf("Expected failed %v got %v", mock.wantFailed, failed)
+			}
+			for _, gv := range mock.wantFailed {
+				if failed[gv] == nil {
+					t.Fatalf("Expected %q to fail got %v", gv, failed)
+				}
+			}
+		})
+	}
+}
+
+func TestAPIResourceManagerServerAggregatedResources(t *testing.T) {
+	var tests = map[string]struct {
+		responses   map[string]string
+		isSupported bool
+		wantLists   int
+		wantFailed  int
+	}{
+		"aggregated discovery": {
+			responses: map[string]string{
+				"/api":  aggregatedCoreJSON,
+				"/apis": aggregatedAppsJSON,
+			},
+			isSupported: true,
+			wantLists:   2,
+			wantFailed:  1,
+		},
+		"legacy discovery": {
+			responses: map[string]string{
+				"/api":  `{"kind": "APIVersions", "versions": ["v1"]}`,
+				"/apis": `{"kind": "APIGroupList", "groups": []}`,
+			},
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(mock.responses[r.URL.Path]))
+			}))
+			defer server.Close()
+
+			mgr := NewAPIResourceManager(
+				discovery.NewDiscoveryClientForConfigOrDie(&rest.Config{Host: server.URL}),
+			)
+			lists, failed, isSupported, err := mgr.serverAggregatedResources()
+			if err != nil {
+				t.Fatalf("Expected no error got %v", err)
+			}
+			if isSupported != mock.isSupported {
+				t.Fatalf("Expected supported %t got %t", mock.isSupported, isSupported)
+			}
+			if len(lists) != mock.wantLists {
+				t.Fatalf("Expected %d resource lists got %d", mock.wantLists, len(lists))
+			}
+			if len(failed) != mock.wantFailed {
+				t.Fatalf("Expected %d failed group versions got %d", mock.wantFailed, len(failed))
+			}
+			if !isSupported {
+				return
+			}
+
+			// resources are discovered as per the aggregated documents
+			mgr.refresh()
+			pod, err := mgr.GetByAlias("v1", "po")
+			if err != nil || pod == nil || pod.Kind != "Pod" || !pod.Namespaced {
+				t.Fatalf("Expected namespaced pods got %+v %v", pod, err)
+			}
+			if !pod.HasSubresource("status") {
+				t.Fatalf("Expected pods to have status sub resource")
+			}
+			if got := mgr.GetPreferredAPIVersion("apps"); got != "apps/v1" {
+				t.Fatalf("Expected preferred version apps/v1 got %q", got)
+			}
+			if _, found := mgr.Status().FailedGroupVersions["apps/v1beta1"]; !found {
+				t.Fatalf("Expected apps/v1beta1 to fail")
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+// persistedCache is the format in which discovered resources are
+// persisted
+type persistedCache struct {
+	// discovered resources anchored by apiVersion
+	Resources map[string][]metav1.APIResource `json:"resources"`
+
+	// preferred apiVersion anchored by api group
+	PreferredVersions map[string]string `json:"preferredVersions"`
+}
+
+// LoadCache loads the resources that were persisted at CachePath by
+// an earlier discovery. Nothing is loaded if resources were already
+// discovered or if nothing was persisted.
+//
+// NOTE:
+//	Loaded resources are replaced by the next discovery that
+// completes. Hence resources that were removed after these were
+// persisted are available only till then.
+func (mgr *APIResourceManager) LoadCache() error {
+	if mgr.CachePath == "" {
+		return nil
+	}
+	data, err := ioutil.ReadFile(mgr.CachePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "Can't load discovery cache %q", mgr.CachePath)
+	}
+	var cache persistedCache
+	err = json.Unmarshal(data, &cache)
+	if err != nil {
+		return errors.Wrapf(
+			err, "Can't load discovery cache %q: Invalid content", mgr.CachePath,
+		)
+	}
+	groupVersions := make(map[string]apiResourceRegistry, len(cache.Resources))
+	for apiVersion, apiResources := range cache.Resources {
+		// registry can't be built from an invalid group version
+		_, err := schema.ParseGroupVersion(apiVersion)
+		if err != nil {
+			return errors.Wrapf(err, "Can't load discovery cache %q", mgr.CachePath)
+		}
+		groupVersions[apiVersion] = newAPIResourceRegistry(
+			&metav1.APIResourceList{
+				GroupVersion: apiVersion,
+				APIResources: apiResources,
+			},
+		)
+	}
+
+	mgr.mutex.Lock()
+	defer mgr.mutex.Unlock()
+
+	if mgr.resources != nil {
+		// discovery completed before the cache could be loaded
+		return nil
+	}
+	mgr.resources = groupVersions
+	mgr.preferredVersions = cache.PreferredVersions
+
+	glog.V(4).Infof(
+		"Loaded %d API group versions from discovery cache %q",
+		len(groupVersions), mgr.CachePath,
+	)
+	return nil
+}
+
+// saveCache persists the discovered resources at CachePath. Resources
+// are persisted only if these changed since these were last persisted.
+func (mgr *APIResourceManager) saveCache() {
+	if mgr.CachePath == "" {
+		return
+	}
+	mgr.mutex.RLock()
+	cache := persistedCache{
+		Resources:         make(map[string][]metav1.APIResource, len(mgr.resources)),
+		PreferredVersions: mgr.preferredVersions,
+	}
+	for apiVersion, registry := range mgr.resources {
+		apiResources := make([]metav1.APIResource, 0, len(registry.resources))
+		for _, apiResource := range registry.resources {
+			apiResources = append(apiResources, apiResource.APIResource)
+		}
+		// sorted to persist the same content for the same resources
+		sort.Slice(apiResources, func(i, j int) bool {
+			return apiResources[i].Name < apiResources[j].Name
+		})
+		cache.Resources[apiVersion] = apiResources
+	}
+	data, err := json.Marshal(cache)
+	mgr.mutex.RUnlock()
+	if err != nil {
+		glog.Errorf("Can't save discovery cache %q: %v", mgr.CachePath, err)
+		return
+	}
+
+	mgr.cacheMutex.Lock()
+	defer mgr.cacheMutex.Unlock()
+
+	if bytes.Equal(data, mgr.savedCache) {
+		return
+	}
+	err = writeFileAtomic(mgr.CachePath, data)
+	if err != nil {
+		glog.Errorf("Can't save discovery cache %q: %v", mgr.CachePath, err)
+		return
+	}
+	mgr.savedCache = data
+	glog.V(7).Infof("Saved discovery cache %q", mgr.CachePath)
+}
+
+// writeFileAtomic writes the given data to the given file such that
+// readers never observe a partially written file
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+	err = tmp.Close()
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAPIResourceManagerSaveAndLoadCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "discovery")
+	if err != nil {
+		t.Fatalf("Expected no error got %v", err)
+	}
+	defer os.RemoveAll(dir)
+	cachePath := filepath.Join(dir, "cache.json")
+
+	discovered := NewAPIResourceManager(newFakeDiscovery(
+		newResourceList(
+			"v1",
+			metav1.APIResource{Name: "pods", Kind: "Pod", Namespaced: true},
+			metav1.APIResource{Name: "pods/status", Kind: "Pod", Namespaced: true},
+		),
+		newResourceList(
+			"apps/v1",
+			metav1.APIResource{Name: "deployments", Kind: "Deployment", Namespaced: true},
+		),
+	))
+	discovered.CachePath = cachePath
+	discovered.refresh()
+	saved, err := ioutil.ReadFile(cachePath)
+	if err != nil {
+		t.Fatalf("Expected cache to be saved got %v", err)
+	}
+
+	// unchanged resources are not saved again
+	err = os.Remove(cachePath)
+	if err != nil {
+		t.Fatalf("Expected no error got %v", err)
+	}
+	discovered.refresh()
+	if _, err := os.Stat(cachePath); !os.IsNotExist(err) {
+		t.Fatalf("Expected unchanged cache not to be saved got %v", err)
+	}
+	err = ioutil.WriteFile(cachePath, saved, 0644)
+	if err != nil {
+		t.Fatalf("Expected no error got %v", err)
+	}
+
+	loaded := NewAPIResourceManager(newFakeDiscovery())
+	loaded.CachePath = cachePath
+	err = loaded.LoadCache()
+	if err != nil {
+		t.Fatalf("Expected no error got %v", err)
+	}
+	if !loaded.HasSynced() {
+		t.Fatalf("Expected synced after loading cache")
+	}
+	pods := loaded.GetByKind("v1", "Pod")
+	if pods == nil || !pods.Namespaced || !pods.HasSubresource("status") {
+		t.Fatalf("Expected namespaced pods with status got %+v", pods)
+	}
+	deploy := loaded.GetByResource("apps/v1", "deployments")
+	if deploy == nil || deploy.Group != "apps" {
+		t.Fatalf("Expected deployments of apps got %+v", deploy)
+	}
+	if got := loaded.GetPreferredAPIVersion("apps"); got != "apps/v1" {
+		t.Fatalf("Expected preferred version apps/v1 got %q", got)
+	}
+}
+
+func TestAPIResourceManagerLoadCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "discovery")
+	if err != nil {
+		t.Fatalf("Expected no error got %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	var tests = map[string]struct {
+		content      string
+		isDiscovered bool
+		isErr        bool
+		isLoaded     bool
+	}{
+		"no cache": {},
+		"valid cache": {
+			content:  `{"resources": {"v1": [{"name": "pods", "kind": "Pod"}]}}`,
+			isLoaded: true,
+		},
+		"invalid content": {
+			content: `{"resources": [}`,
+			isErr:   true,
+		},
+		"invalid group version": {
+			content: `{"resources": {"a/b/c": [{"name": "tests", "kind": "Test"}]}}`,
+			isErr:   true,
+		},
+		"discovered before load": {
+			content:      `{"resources": {"v1": [{"name": "pods", "kind": "Pod"}]}}`,
+			isDiscovered: true,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			cachePath := filepath.Join(dir, name+".json")
+			if mock.content != "" {
+				err := ioutil.WriteFile(cachePath, []byte(mock.content), 0644)
+				if err != nil {
+					t.Fatalf("Expected no error got %v", err)
+				}
+			}
+			mgr := NewAPIResourceManager(newFakeDiscovery())
+			mgr.CachePath = cachePath
+			if mock.isDiscovered {
+				mgr.refresh()
+			}
+			err := mgr.LoadCache()
+			if mock.isErr && err == nil {
+				t.Fatalf("Expected error got none")
+			}
+			if !mock.isErr && err != nil {
+				t.Fatalf("Expected no error got %v", err)
+			}
+			isLoaded := mgr.GetByResource("v1", "pods") != nil
+			if isLoaded != mock.isLoaded {
+				t.Fatalf("Expected loaded %t got %t", mock.isLoaded, isLoaded)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"sort"
+	"sync"
+)
+
+// ClusterManagers holds a separate APIResourceManager per target
+// cluster. Resources of a cluster are resolved against the API
+// surface of that cluster since clusters may serve different
+// resources & versions.
+//
+// NOTE:
+//	Local cluster i.e. the cluster that metac runs against is
+// referred to by an empty name
+type ClusterManagers struct {
+	mutex sync.RWMutex
+
+	// managers anchored by cluster name
+	managers map[string]*APIResourceManager
+}
+
+// NewClusterManagers returns a new instance of ClusterManagers with
+// the given manager of the local cluster
+func NewClusterManagers(local *APIResourceManager) *ClusterManagers {
+	return &ClusterManagers{
+		managers: map[string]*APIResourceManager{"": local},
+	}
+}
+
+// Get returns the manager of the given cluster. Nil is returned if
+// the cluster is not known.
+func (c *ClusterManagers) Get(cluster string) *APIResourceManager {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return c.managers[cluster]
+}
+
+// Local returns the manager of the local cluster
+func (c *ClusterManagers) Local() *APIResourceManager {
+	return c.Get("")
+}
+
+// Set sets the given manager against the given cluster. Previous
+// manager of this cluster if any is stopped.
+func (c *ClusterManagers) Set(cluster string, mgr *APIResourceManager) {
+	c.mutex.Lock()
+	old := c.managers[cluster]
+	c.managers[cluster] = mgr
+	c.mutex.Unlock()
+
+	if old != nil && old != mgr && old.stopCh != nil {
+		old.Stop()
+	}
+}
+
+// Remove stops & removes the manager of the given remote cluster
+func (c *ClusterManagers) Remove(cluster string) {
+	if cluster == "" {
+		// local cluster is always managed
+		return
+	}
+	c.mutex.Lock()
+	old := c.managers[cluster]
+	delete(c.managers, cluster)
+	c.mutex.Unlock()
+
+	if old != nil && old.stopCh != nil {
+		old.Stop()
+	}
+}
+
+// Clusters returns the sorted names of the remote clusters
+func (c *ClusterManagers) Clusters() []string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	var clusters []string
+	for cluster := range c.managers {
+		if cluster != "" {
+			clusters = append(clusters, cluster)
+		}
+	}
+	sort.Strings(clusters)
+	return clusters
+}
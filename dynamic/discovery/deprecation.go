@@ -0,0 +1,232 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/golang/glog"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/transport"
+)
+
+var (
+	// deprecatedAPIVersionKey tags the deprecated resource metric
+	// with the apiVersion of the resource
+	deprecatedAPIVersionKey, _ = tag.NewKey("api_version")
+
+	// deprecatedResourceKey tags the deprecated resource metric with
+	// the name of the resource
+	deprecatedResourceKey, _ = tag.NewKey("resource")
+
+	// deprecatedResourcesMeasure tracks the resources that are
+	// found to be deprecated
+	deprecatedResourcesMeasure = stats.Int64(
+		"metac/deprecated_api_resources",
+		"Resources that are used by metac & are deprecated by the API server",
+		stats.UnitDimensionless,
+	)
+
+	// DeprecatedResourcesView exposes the resources that are used by
+	// metac & are found to be deprecated
+	//
+	// NOTE:
+	//	This needs to be registered to be exported
+	DeprecatedResourcesView = &view.View{
+		Name:        "metac/deprecated_api_resources",
+		Description: deprecatedResourcesMeasure.Description(),
+		Measure:     deprecatedResourcesMeasure,
+		TagKeys:     []tag.Key{deprecatedAPIVersionKey, deprecatedResourceKey},
+		Aggregation: view.LastValue(),
+	}
+)
+
+// warningCodeMiscellaneous is the code of the warning headers that
+// are sent by the API server e.g. when a deprecated API is used
+const warningCodeMiscellaneous = "299"
+
+// makeDeprecationKey returns the key of the deprecation of the given
+// resource
+func makeDeprecationKey(apiVersion, resource string) string {
+	return apiVersion + ":" + resource
+}
+
+// GetDeprecation returns the deprecation warning of the provided
+// version and resource. Empty string is returned if the resource is
+// not known to be deprecated.
+//
+// NOTE:
+//	Deprecations are learnt from the custom resource definitions
+// & from the warnings sent by the API server in response to the
+// requests made via a config wrapped by WrapConfig
+func (mgr *APIResourceManager) GetDeprecation(apiVersion, resource string) string {
+	mgr.deprecationMutex.RLock()
+	defer mgr.deprecationMutex.RUnlock()
+
+	return mgr.deprecations[makeDeprecationKey(apiVersion, resource)]
+}
+
+// setDeprecation sets the given deprecation warning against the given
+// resource
+func (mgr *APIResourceManager) setDeprecation(apiVersion, resource, warning string) {
+	mgr.deprecationMutex.Lock()
+	defer mgr.deprecationMutex.Unlock()
+
+	key := makeDeprecationKey(apiVersion, resource)
+	if mgr.deprecations[key] == warning {
+		return
+	}
+	if mgr.deprecations == nil {
+		mgr.deprecations = make(map[string]string)
+	}
+	mgr.deprecations[key] = warning
+	glog.Warningf("Resource %q of %q is deprecated: %s", resource, apiVersion, warning)
+
+	err := stats.RecordWithTags(
+		context.Background(),
+		[]tag.Mutator{
+			tag.Upsert(deprecatedAPIVersionKey, apiVersion),
+			tag.Upsert(deprecatedResourceKey, resource),
+		},
+		deprecatedResourcesMeasure.M(1),
+	)
+	if err != nil {
+		glog.Warningf("Failed to record deprecation of %q of %q: %v", resource, apiVersion, err)
+	}
+}
+
+// setCRDDeprecations sets the deprecation warnings of the deprecated
+// versions of the given custom resource definition
+func (mgr *APIResourceManager) setCRDDeprecations(crd *unstructured.Unstructured) {
+	group, _, _ := unstructured.NestedString(crd.Object, "spec", "group")
+	plural, _, _ := unstructured.NestedString(crd.Object, "spec", "names", "plural")
+	versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+	for _, item := range versions {
+		version, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		deprecated, _, _ := unstructured.NestedBool(version, "deprecated")
+		if !deprecated {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(version, "name")
+		gv := schema.GroupVersion{Group: group, Version: name}
+		warning, _, _ := unstructured.NestedString(version, "deprecationWarning")
+		if warning == "" {
+			warning = fmt.Sprintf("%s %s is deprecated", gv.String(), plural)
+		}
+		mgr.setDeprecation(gv.String(), plural, warning)
+	}
+}
+
+// WrapConfig returns a copy of the given config whose clients let
+// this manager learn the deprecated resources from the warnings sent
+// by the API server
+func (mgr *APIResourceManager) WrapConfig(config *rest.Config) *rest.Config {
+	wrapped := rest.CopyConfig(config)
+	wrapped.WrapTransport = transport.Wrappers(
+		config.WrapTransport,
+		func(rt http.RoundTripper) http.RoundTripper {
+			return &warningRoundTripper{mgr: mgr, delegate: rt}
+		},
+	)
+	return wrapped
+}
+
+// warningRoundTripper sets the deprecation warnings sent by the API
+// server against the requested resources
+type warningRoundTripper struct {
+	mgr      *APIResourceManager
+	delegate http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper interface
+func (rt *warningRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := rt.delegate.RoundTrip(req)
+	if err != nil || len(resp.Header["Warning"]) == 0 {
+		return resp, err
+	}
+	apiVersion, resource, ok := parseResourcePath(req.URL.Path)
+	if !ok {
+		return resp, err
+	}
+	for _, header := range resp.Header["Warning"] {
+		warning, ok := parseWarningHeader(header)
+		if ok && strings.Contains(strings.ToLower(warning), "deprecated") {
+			rt.mgr.setDeprecation(apiVersion, resource, warning)
+		}
+	}
+	return resp, err
+}
+
+// parseWarningHeader returns the text of the given warning header if
+// it has the code used by the API server
+//
+// NOTE:
+//	A warning header is formatted as: 299 - "text"
+func parseWarningHeader(header string) (string, bool) {
+	parts := strings.SplitN(header, " ", 3)
+	if len(parts) != 3 || parts[0] != warningCodeMiscellaneous {
+		return "", false
+	}
+	text := strings.TrimSpace(parts[2])
+	if len(text) < 2 || !strings.HasPrefix(text, `"`) {
+		return "", false
+	}
+	// text may be followed by a date
+	end := strings.LastIndex(text, `"`)
+	if end == 0 {
+		return "", false
+	}
+	return strings.Replace(text[1:end], `\"`, `"`, -1), true
+}
+
+// parseResourcePath returns the apiVersion & resource of the given
+// request path e.g. /apis/apps/v1/namespaces/default/deployments
+func parseResourcePath(path string) (string, string, bool) {
+	segs := strings.Split(strings.Trim(path, "/"), "/")
+	var apiVersion string
+	switch {
+	case len(segs) >= 3 && segs[0] == "api":
+		apiVersion = segs[1]
+		segs = segs[2:]
+	case len(segs) >= 4 && segs[0] == "apis":
+		apiVersion = segs[1] + "/" + segs[2]
+		segs = segs[3:]
+	default:
+		return "", "", false
+	}
+	if segs[0] == "watch" {
+		segs = segs[1:]
+	}
+	if len(segs) >= 3 && segs[0] == "namespaces" {
+		segs = segs[2:]
+	}
+	if len(segs) == 0 || segs[0] == "" {
+		return "", "", false
+	}
+	return apiVersion, segs[0], true
+}
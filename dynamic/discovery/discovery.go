@@ -19,6 +19,7 @@ package discovery
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -29,9 +30,14 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/discovery"
 )
 
+// minResolveInterval is the minimum interval between two on demand
+// discoveries of the same group version
+const minResolveInterval = 5 * time.Second
+
 // APIResource wraps the original server API resource
 // with additional info
 type APIResource struct {
@@ -102,9 +108,47 @@ type APIResourceManager struct {
 	// is sufficient to find a particular API resource.
 	resources map[string]apiResourceRegistry
 
+	// preferred apiVersion anchored by api group
+	//
+	// NOTE:
+	//	This is the first version of the group that is listed
+	// by discovery, since server lists the preferred version
+	// first
+	preferredVersions map[string]string
+
 	// Client to discover API resource
 	Client discovery.DiscoveryInterface
 
+	// RefreshJitter randomly delays each full discovery by up to
+	// this fraction of the refresh interval e.g. 0.1 delays by up to
+	// 10%. This spreads the discovery load of many metac instances.
+	RefreshJitter float64
+
+	// CachePath is the file where the discovered resources are
+	// persisted. Resources are not persisted if this is empty.
+	//
+	// NOTE:
+	//	Persisted resources can be loaded via LoadCache to make
+	// these available before the first discovery completes
+	CachePath string
+
+	// last persisted resources
+	cacheMutex sync.Mutex
+	savedCache []byte
+
+	// group versions whose resources could not be discovered by
+	// the latest discovery
+	failedMutex         sync.RWMutex
+	failedGroupVersions map[string]error
+
+	// deprecation warnings anchored by apiVersion & resource
+	deprecationMutex sync.RWMutex
+	deprecations     map[string]string
+
+	// time when the group versions were last resolved on demand
+	resolveMutex sync.Mutex
+	resolvedAt   map[string]time.Time
+
 	stopCh, doneCh chan struct{}
 }
 
@@ -131,6 +175,48 @@ func (mgr *APIResourceManager) GetByResource(
 	return registry.resources[resource]
 }
 
+// ResolveByResource returns the API resource based on the provided
+// version and resource. The resource's group version is discovered
+// from the server if the resource is not discovered yet.
+//
+// NOTE:
+//	Nil is returned if the resource is not served. A group version
+// is discovered on demand at most once in minResolveInterval.
+func (mgr *APIResourceManager) ResolveByResource(
+	apiVersion, resource string,
+) (*APIResource, error) {
+	if apiResource := mgr.GetByResource(apiVersion, resource); apiResource != nil {
+		return apiResource, nil
+	}
+	if !mgr.shouldResolve(apiVersion) {
+		return nil, nil
+	}
+	err := mgr.refreshGroupVersion(apiVersion)
+	if err != nil {
+		return nil, errors.Wrapf(
+			err, "Can't resolve %q of %q", resource, apiVersion,
+		)
+	}
+	return mgr.GetByResource(apiVersion, resource), nil
+}
+
+// shouldResolve returns true if the given group version was not
+// resolved on demand recently
+func (mgr *APIResourceManager) shouldResolve(apiVersion string) bool {
+	mgr.resolveMutex.Lock()
+	defer mgr.resolveMutex.Unlock()
+
+	if mgr.resolvedAt == nil {
+		mgr.resolvedAt = make(map[string]time.Time)
+	}
+	if last, found := mgr.resolvedAt[apiVersion]; found &&
+		time.Since(last) < minResolveInterval {
+		return false
+	}
+	mgr.resolvedAt[apiVersion] = time.Now()
+	return true
+}
+
 // GetByKind returns the API resource based on the provided
 // version and kind
 func (mgr *APIResourceManager) GetByKind(
@@ -147,6 +233,201 @@ func (mgr *APIResourceManager) GetByKind(
 	return registry.kinds[kind]
 }
 
+// ListByAPIVersion returns all the API resources excluding the
+// sub resources based on the provided version. Resources are
+// sorted by their names.
+func (mgr *APIResourceManager) ListByAPIVersion(apiVersion string) []*APIResource {
+	mgr.mutex.RLock()
+	defer mgr.mutex.RUnlock()
+
+	registry, ok := mgr.resources[apiVersion]
+	if !ok {
+		return nil
+	}
+	list := make([]*APIResource, 0, len(registry.kinds))
+	for _, resource := range registry.kinds {
+		list = append(list, resource)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// GetByAlias returns the API resource based on the provided version
+// and either the resource name, its singular name or one of its short
+// names similar to kubectl e.g. deploy for deployments. Nil is
+// returned if none match.
+//
+// NOTE:
+//	Error is returned if the alias matches more than one resource
+func (mgr *APIResourceManager) GetByAlias(
+	apiVersion, alias string,
+) (*APIResource, error) {
+	if resource := mgr.GetByResource(apiVersion, alias); resource != nil {
+		return resource, nil
+	}
+	var matches []*APIResource
+	for _, resource := range mgr.ListByAPIVersion(apiVersion) {
+		if resource.SingularName == alias {
+			matches = append(matches, resource)
+			continue
+		}
+		for _, shortName := range resource.ShortNames {
+			if shortName == alias {
+				matches = append(matches, resource)
+				break
+			}
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, nil
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, errors.Errorf(
+			"Ambiguous resource %q of %q: Matches %s",
+			alias, apiVersion, joinResourceNames(matches),
+		)
+	}
+}
+
+// ListByCategory returns the API resources of the provided version
+// that belong to the provided category e.g. all. Resources are sorted
+// by their names.
+func (mgr *APIResourceManager) ListByCategory(
+	apiVersion, category string,
+) []*APIResource {
+	var list []*APIResource
+	for _, resource := range mgr.ListByAPIVersion(apiVersion) {
+		for _, c := range resource.Categories {
+			if c == category {
+				list = append(list, resource)
+				break
+			}
+		}
+	}
+	return list
+}
+
+// joinResourceNames returns the names of the given resources as a
+// comma separated string
+func joinResourceNames(resources []*APIResource) string {
+	names := make([]string, 0, len(resources))
+	for _, resource := range resources {
+		names = append(names, resource.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// GetPreferredAPIVersion returns the preferred version of the
+// provided api group. It returns empty string if this group is
+// not discovered.
+func (mgr *APIResourceManager) GetPreferredAPIVersion(apiGroup string) string {
+	mgr.mutex.RLock()
+	defer mgr.mutex.RUnlock()
+
+	return mgr.preferredVersions[apiGroup]
+}
+
+// parseDiscoveredGroupVersion returns the given group version that
+// was discovered from the server as a GroupVersion
+func parseDiscoveredGroupVersion(groupVersion string) schema.GroupVersion {
+	gv, err := schema.ParseGroupVersion(groupVersion)
+	if err != nil {
+		// This shouldn't happen because we get these values
+		// from the server.
+		panic(errors.Errorf(
+			"API resource discovery failed: Invalid group version %q: %v",
+			groupVersion, err,
+		))
+	}
+	return gv
+}
+
+// newAPIResourceRegistry returns the registry of the resources of
+// the given discovered group version
+func newAPIResourceRegistry(apiResourceSet *metav1.APIResourceList) apiResourceRegistry {
+	gv := parseDiscoveredGroupVersion(apiResourceSet.GroupVersion)
+	registrySet := apiResourceRegistry{
+		resources:    make(map[string]*APIResource, len(apiResourceSet.APIResources)),
+		kinds:        make(map[string]*APIResource, len(apiResourceSet.APIResources)),
+		subresources: make(map[string]*APIResource, len(apiResourceSet.APIResources)),
+	}
+
+	for i := range apiResourceSet.APIResources {
+		apiResource := &APIResource{
+			APIResource: apiResourceSet.APIResources[i],
+			APIVersion:  apiResourceSet.GroupVersion,
+		}
+		// Materialize default values from the list into each entry
+		if apiResource.Group == "" {
+			apiResource.Group = gv.Group
+		}
+		if apiResource.Version == "" {
+			apiResource.Version = gv.Version
+		}
+		registrySet.resources[apiResource.Name] = apiResource
+
+		// Remember which resources are subresources, and map the kind
+		// to the main resource. This is different from what RESTMapper
+		// provides because we already know the full GroupVersionKind
+		// and just need the resource name.
+		if strings.ContainsRune(apiResource.Name, '/') {
+			registrySet.subresources[apiResource.Name] = apiResource
+		} else {
+			registrySet.kinds[apiResource.Kind] = apiResource
+		}
+	}
+
+	// Flag each resource with all its supported sub resources
+	for subResourceNamedPath := range registrySet.subresources {
+		arr := strings.Split(subResourceNamedPath, "/")
+		resName := arr[0]
+		subResName := arr[1]
+		apiResource := registrySet.resources[resName]
+		if apiResource == nil {
+			continue
+		}
+		if apiResource.hasSubresource == nil {
+			apiResource.hasSubresource = make(map[string]bool)
+		}
+		apiResource.hasSubresource[subResName] = true
+	}
+	return registrySet
+}
+
+// serverResources returns all the server resources. Aggregated
+// discovery is used if the server supports it since it discovers all
+// the resources in a single request. Legacy discovery that requests
+// each group version is used otherwise.
+//
+// NOTE:
+//	Group versions whose resources could not be discovered e.g. due
+// to an unavailable aggregated api service are returned along with
+// their errors. Resources of the other group versions are returned
+// in this case.
+func (mgr *APIResourceManager) serverResources() (
+	[]*metav1.APIResourceList, map[string]error, error,
+) {
+	apiResourceSetList, failed, isSupported, err := mgr.serverAggregatedResources()
+	if err != nil {
+		glog.V(4).Infof("Will use legacy API resource discovery: %v", err)
+	} else if isSupported {
+		return apiResourceSetList, failed, nil
+	}
+	apiResourceSetList, err = mgr.Client.ServerResources()
+	if err != nil && discovery.IsGroupDiscoveryFailedError(err) {
+		failed = make(map[string]error)
+		for gv, gvErr := range err.(*discovery.ErrGroupDiscoveryFailed).Groups {
+			failed[gv.String()] = gvErr
+		}
+		return apiResourceSetList, failed, nil
+	}
+	return apiResourceSetList, nil, err
+}
+
 // refresh discovers all Kubernetes server resources
 //
 // NOTE:
@@ -156,13 +437,15 @@ func (mgr *APIResourceManager) refresh() {
 	var err error
 
 	glog.V(7).Info("Discovering API resources")
+	start := time.Now()
 	defer func() {
+		recordRefresh(refreshScopeFull, start, err)
 		if err == nil {
 			glog.V(7).Info("API resources discovery completed")
 		}
 	}()
 
-	apiResourceSetList, err := mgr.Client.ServerResources()
+	apiResourceSetList, failed, err := mgr.serverResources()
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			glog.Warningf("Can't discover api resource: %v", err)
@@ -175,69 +458,37 @@ func (mgr *APIResourceManager) refresh() {
 	// Denormalize resourceset list into map for convenient lookup
 	// by either Group-Version-Kind or Group-Version-Resource
 	groupVersions := make(map[string]apiResourceRegistry, len(apiResourceSetList))
+	preferredVersions := make(map[string]string)
 	for _, apiResourceSet := range apiResourceSetList {
-		gv, err := schema.ParseGroupVersion(apiResourceSet.GroupVersion)
-		if err != nil {
-			// This shouldn't happen because we get these values
-			// from the server.
-			panic(errors.Errorf(
-				"API resource discovery failed: Invalid group version %q: %v",
-				apiResourceSet.GroupVersion, err,
-			))
-		}
-		registrySet := apiResourceRegistry{
-			resources:    make(map[string]*APIResource, len(apiResourceSet.APIResources)),
-			kinds:        make(map[string]*APIResource, len(apiResourceSet.APIResources)),
-			subresources: make(map[string]*APIResource, len(apiResourceSet.APIResources)),
-		}
-
-		for i := range apiResourceSet.APIResources {
-			apiResource := &APIResource{
-				APIResource: apiResourceSet.APIResources[i],
-				APIVersion:  apiResourceSet.GroupVersion,
-			}
-			// Materialize default values from the list into each entry
-			if apiResource.Group == "" {
-				apiResource.Group = gv.Group
-			}
-			if apiResource.Version == "" {
-				apiResource.Version = gv.Version
-			}
-			registrySet.resources[apiResource.Name] = apiResource
-
-			// Remember which resources are subresources, and map the kind
-			// to the main resource. This is different from what RESTMapper
-			// provides because we already know the full GroupVersionKind
-			// and just need the resource name.
-			if strings.ContainsRune(apiResource.Name, '/') {
-				registrySet.subresources[apiResource.Name] = apiResource
-			} else {
-				registrySet.kinds[apiResource.Kind] = apiResource
-			}
+		gv := parseDiscoveredGroupVersion(apiResourceSet.GroupVersion)
+		groupVersions[apiResourceSet.GroupVersion] = newAPIResourceRegistry(apiResourceSet)
+		if _, ok := preferredVersions[gv.Group]; !ok {
+			preferredVersions[gv.Group] = apiResourceSet.GroupVersion
 		}
-
-		// Flag each resource with all its supported sub resources
-		for subResourceNamedPath := range registrySet.subresources {
-			arr := strings.Split(subResourceNamedPath, "/")
-			resName := arr[0]
-			subResName := arr[1]
-			apiResource := registrySet.resources[resName]
-			if apiResource == nil {
-				continue
-			}
-			if apiResource.hasSubresource == nil {
-				apiResource.hasSubresource = make(map[string]bool)
-			}
-			apiResource.hasSubresource[subResName] = true
-		}
-
-		groupVersions[apiResourceSet.GroupVersion] = registrySet
 	}
 
 	// Replace the local cache.
 	mgr.mutex.Lock()
+	// failed group versions keep serving their last discovered
+	// resources
+	for groupVersion := range failed {
+		registry, found := mgr.resources[groupVersion]
+		if !found {
+			continue
+		}
+		groupVersions[groupVersion] = registry
+		gv := parseDiscoveredGroupVersion(groupVersion)
+		if _, ok := preferredVersions[gv.Group]; !ok {
+			preferredVersions[gv.Group] = mgr.preferredVersions[gv.Group]
+		}
+	}
 	mgr.resources = groupVersions
+	mgr.preferredVersions = preferredVersions
 	mgr.mutex.Unlock()
+
+	mgr.setFailedGroupVersions(failed)
+	mgr.recordCardinality()
+	mgr.saveCache()
 }
 
 // Start executes resource discovery in the given interval
@@ -248,9 +499,6 @@ func (mgr *APIResourceManager) Start(refreshInterval time.Duration) {
 	go func() {
 		defer close(mgr.doneCh)
 
-		ticker := time.NewTicker(refreshInterval)
-		defer ticker.Stop()
-
 		for {
 			mgr.refresh()
 
@@ -258,12 +506,20 @@ func (mgr *APIResourceManager) Start(refreshInterval time.Duration) {
 			case <-mgr.stopCh:
 				// return / exit from this anonymous func
 				return
-			case <-ticker.C:
+			case <-time.After(mgr.jitter(refreshInterval)):
 			}
 		}
 	}()
 }
 
+// jitter returns the given interval jittered by RefreshJitter
+func (mgr *APIResourceManager) jitter(interval time.Duration) time.Duration {
+	if mgr.RefreshJitter <= 0 {
+		return interval
+	}
+	return wait.Jitter(interval, mgr.RefreshJitter)
+}
+
 // Stop stops resource discovery ticker
 func (mgr *APIResourceManager) Stop() {
 	close(mgr.stopCh)
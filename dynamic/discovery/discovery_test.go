@@ -0,0 +1,124 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+// fakeDiscovery serves the given resources. Group versions that are
+// set as failed can't be discovered by a full discovery.
+type fakeDiscovery struct {
+	*fakediscovery.FakeDiscovery
+
+	failed map[schema.GroupVersion]error
+}
+
+// newFakeDiscovery returns a fake discovery that serves the given
+// resources
+func newFakeDiscovery(resources ...*metav1.APIResourceList) *fakeDiscovery {
+	return &fakeDiscovery{
+		FakeDiscovery: &fakediscovery.FakeDiscovery{
+			Fake: &clienttesting.Fake{Resources: resources},
+		},
+	}
+}
+
+// ServerResourcesForGroupVersion returns not found error if the given
+// group version is not served similar to the server
+func (d *fakeDiscovery) ServerResourcesForGroupVersion(
+	groupVersion string,
+) (*metav1.APIResourceList, error) {
+	list, err := d.FakeDiscovery.ServerResourcesForGroupVersion(groupVersion)
+	if err != nil {
+		return nil, apierrors.NewNotFound(schema.GroupResource{}, groupVersion)
+	}
+	return list, nil
+}
+
+// ServerResources returns the resources of the group versions that
+// are not set as failed
+func (d *fakeDiscovery) ServerResources() ([]*metav1.APIResourceList, error) {
+	lists, err := d.FakeDiscovery.ServerResources()
+	if err != nil || len(d.failed) == 0 {
+		return lists, err
+	}
+	var served []*metav1.APIResourceList
+	for _, list := range lists {
+		gv, _ := schema.ParseGroupVersion(list.GroupVersion)
+		if _, found := d.failed[gv]; !found {
+			served = append(served, list)
+		}
+	}
+	return served, &discovery.ErrGroupDiscoveryFailed{Groups: d.failed}
+}
+
+// newResourceList returns the resource list of the given group
+// version with the given resources
+func newResourceList(groupVersion string, resources ...metav1.APIResource) *metav1.APIResourceList {
+	return &metav1.APIResourceList{
+		GroupVersion: groupVersion,
+		APIResources: resources,
+	}
+}
+
+func TestAPIResourceManagerRefresh(t *testing.T) {
+	mgr := NewAPIResourceManager(newFakeDiscovery(
+		newResourceList(
+			"v1",
+			metav1.APIResource{Name: "pods", Kind: "Pod", Namespaced: true},
+			metav1.APIResource{Name: "pods/status", Kind: "Pod", Namespaced: true},
+		),
+		newResourceList(
+			"apps/v1",
+			metav1.APIResource{Name: "deployments", Kind: "Deployment", Namespaced: true},
+		),
+		newResourceList(
+			"apps/v1beta1",
+			metav1.APIResource{Name: "deployments", Kind: "Deployment", Namespaced: true},
+		),
+	))
+	if mgr.HasSynced() {
+		t.Fatalf("Expected not synced before refresh")
+	}
+	mgr.refresh()
+	if !mgr.HasSynced() {
+		t.Fatalf("Expected synced after refresh")
+	}
+
+	pods := mgr.GetByKind("v1", "Pod")
+	if pods == nil || pods.Name != "pods" {
+		t.Fatalf("Expected pods got %+v", pods)
+	}
+	if !pods.HasSubresource("status") {
+		t.Fatalf("Expected pods to have status sub resource")
+	}
+	deploy := mgr.GetByResource("apps/v1", "deployments")
+	if deploy == nil || deploy.Group != "apps" || deploy.Version != "v1" {
+		t.Fatalf("Expected deployments of apps/v1 got %+v", deploy)
+	}
+	if got := mgr.GetPreferredAPIVersion("apps"); got != "apps/v1" {
+		t.Fatalf("Expected preferred version apps/v1 got %q", got)
+	}
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/golang/glog"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+// ResourceMapping represents the resource that an apiVersion along
+// with a resource or a kind maps to as per discovery
+type ResourceMapping struct {
+	APIVersion   string   `json:"apiVersion"`
+	Group        string   `json:"group"`
+	Version      string   `json:"version"`
+	Resource     string   `json:"resource"`
+	Kind         string   `json:"kind"`
+	Namespaced   bool     `json:"namespaced"`
+	Verbs        []string `json:"verbs,omitempty"`
+	ShortNames   []string `json:"shortNames,omitempty"`
+	Categories   []string `json:"categories,omitempty"`
+	Subresources []string `json:"subresources,omitempty"`
+}
+
+// newResourceMapping returns the mapping of the given resource
+func newResourceMapping(resource *APIResource) ResourceMapping {
+	mapping := ResourceMapping{
+		APIVersion: resource.APIVersion,
+		Group:      resource.Group,
+		Version:    resource.Version,
+		Resource:   resource.Name,
+		Kind:       resource.Kind,
+		Namespaced: resource.Namespaced,
+		Verbs:      resource.Verbs,
+		ShortNames: resource.ShortNames,
+		Categories: resource.Categories,
+	}
+	for subresource := range resource.hasSubresource {
+		mapping.Subresources = append(mapping.Subresources, subresource)
+	}
+	sort.Strings(mapping.Subresources)
+	return mapping
+}
+
+// ListResourceMappings returns the mappings of the resources that the
+// given query refers to. The query consists of an apiVersion or a
+// group whose preferred version is used along with an optional resource
+// or kind. Resource may be a short name or a singular name. All the
+// resources of the apiVersion are returned if neither resource nor
+// kind is set.
+func (mgr *APIResourceManager) ListResourceMappings(
+	apiVersion, group, resource, kind string,
+) ([]ResourceMapping, error) {
+	if apiVersion == "" && group != "" {
+		apiVersion = mgr.GetPreferredAPIVersion(group)
+	}
+	var resources []*APIResource
+	switch {
+	case resource != "":
+		apiResource, err := mgr.GetByAlias(apiVersion, resource)
+		if err != nil {
+			return nil, err
+		}
+		if apiResource != nil {
+			resources = append(resources, apiResource)
+		}
+	case kind != "":
+		if apiResource := mgr.GetByKind(apiVersion, kind); apiResource != nil {
+			resources = append(resources, apiResource)
+		}
+	default:
+		resources = mgr.ListByAPIVersion(apiVersion)
+	}
+	mappings := make([]ResourceMapping, 0, len(resources))
+	for _, apiResource := range resources {
+		mappings = append(mappings, newResourceMapping(apiResource))
+	}
+	return mappings, nil
+}
+
+// ServeResourceMappings responds with the resource mappings that the
+// query of the given request refers to as json. The query is set via
+// the apiVersion, group, resource & kind parameters e.g.
+// ?apiVersion=apps/v1&resource=deploy
+//
+// NOTE:
+//	This is read only & is meant for hook authors & for debugging
+func (mgr *APIResourceManager) ServeResourceMappings(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	apiVersion := strings.TrimSpace(query.Get("apiVersion"))
+	group := strings.TrimSpace(query.Get("group"))
+	if apiVersion == "" && group == "" {
+		http.Error(w, "Either apiVersion or group is required", http.StatusBadRequest)
+		return
+	}
+	mappings, err := mgr.ListResourceMappings(
+		apiVersion,
+		group,
+		strings.TrimSpace(query.Get("resource")),
+		strings.TrimSpace(query.Get("kind")),
+	)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(mappings) == 0 {
+		http.Error(w, "No resource is discovered for this query", http.StatusNotFound)
+		return
+	}
+	data, err := json.Marshal(mappings)
+	if err != nil {
+		glog.Errorf("Can't marshal resource mappings: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
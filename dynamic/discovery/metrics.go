@@ -0,0 +1,145 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang/glog"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+const (
+	// refreshScopeFull tags the metrics of a discovery of all the
+	// group versions
+	refreshScopeFull = "full"
+
+	// refreshScopeGroupVersion tags the metrics of a discovery of a
+	// single group version
+	refreshScopeGroupVersion = "group_version"
+)
+
+var (
+	// refreshScopeKey tags the discovery metrics with the scope of
+	// the discovery
+	refreshScopeKey, _ = tag.NewKey("scope")
+
+	// refreshDurationMeasure tracks the time taken by discovery
+	refreshDurationMeasure = stats.Float64(
+		"metac/discovery_refresh_duration_seconds",
+		"Time taken to discover API resources",
+		"s",
+	)
+
+	// refreshErrorsMeasure counts the failed discoveries
+	refreshErrorsMeasure = stats.Int64(
+		"metac/discovery_refresh_errors",
+		"Number of times API resource discovery failed",
+		stats.UnitDimensionless,
+	)
+
+	// discoveredGroupVersionsMeasure tracks the number of discovered
+	// group versions
+	discoveredGroupVersionsMeasure = stats.Int64(
+		"metac/discovered_group_versions",
+		"Number of discovered API group versions",
+		stats.UnitDimensionless,
+	)
+
+	// discoveredResourcesMeasure tracks the number of discovered
+	// resources including sub resources
+	discoveredResourcesMeasure = stats.Int64(
+		"metac/discovered_resources",
+		"Number of discovered API resources including sub resources",
+		stats.UnitDimensionless,
+	)
+
+	// Views expose all the discovery metrics
+	//
+	// NOTE:
+	//	These need to be registered to be exported
+	Views = []*view.View{
+		{
+			Name:        "metac/discovery_refresh_duration_seconds",
+			Description: refreshDurationMeasure.Description(),
+			Measure:     refreshDurationMeasure,
+			TagKeys:     []tag.Key{refreshScopeKey},
+			Aggregation: view.Distribution(0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60),
+		},
+		{
+			Name:        "metac/discovery_refresh_errors_total",
+			Description: refreshErrorsMeasure.Description(),
+			Measure:     refreshErrorsMeasure,
+			TagKeys:     []tag.Key{refreshScopeKey},
+			Aggregation: view.Count(),
+		},
+		{
+			Name:        "metac/discovered_group_versions",
+			Description: discoveredGroupVersionsMeasure.Description(),
+			Measure:     discoveredGroupVersionsMeasure,
+			Aggregation: view.LastValue(),
+		},
+		{
+			Name:        "metac/discovered_resources",
+			Description: discoveredResourcesMeasure.Description(),
+			Measure:     discoveredResourcesMeasure,
+			Aggregation: view.LastValue(),
+		},
+		FailedGroupVersionsView,
+		DeprecatedResourcesView,
+	}
+)
+
+// recordRefresh records the duration of the discovery of the given
+// scope that started at the given time & its error if any
+func recordRefresh(scope string, start time.Time, err error) {
+	measurements := []stats.Measurement{
+		refreshDurationMeasure.M(time.Since(start).Seconds()),
+	}
+	if err != nil {
+		measurements = append(measurements, refreshErrorsMeasure.M(1))
+	}
+	recordErr := stats.RecordWithTags(
+		context.Background(),
+		[]tag.Mutator{tag.Upsert(refreshScopeKey, scope)},
+		measurements...,
+	)
+	if recordErr != nil {
+		glog.Warningf("Failed to record %s discovery: %v", scope, recordErr)
+	}
+}
+
+// recordCardinality records the number of the discovered group
+// versions & resources
+func (mgr *APIResourceManager) recordCardinality() {
+	mgr.mutex.RLock()
+	groupVersions := len(mgr.resources)
+	var resources int
+	for _, registry := range mgr.resources {
+		resources += len(registry.resources)
+	}
+	mgr.mutex.RUnlock()
+
+	stats.Record(
+		context.Background(),
+		discoveredGroupVersionsMeasure.M(int64(groupVersions)),
+		discoveredResourcesMeasure.M(int64(resources)),
+	)
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/golang/glog"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+var (
+	// failedGroupVersionsMeasure tracks the group versions whose
+	// resources could not be discovered
+	failedGroupVersionsMeasure = stats.Int64(
+		"metac/discovery_failed_group_versions",
+		"Number of group versions whose resources could not be discovered",
+		stats.UnitDimensionless,
+	)
+
+	// FailedGroupVersionsView exposes the number of group versions
+	// whose resources could not be discovered
+	//
+	// NOTE:
+	//	This needs to be registered to be exported
+	FailedGroupVersionsView = &view.View{
+		Name:        "metac/discovery_failed_group_versions",
+		Description: failedGroupVersionsMeasure.Description(),
+		Measure:     failedGroupVersionsMeasure,
+		Aggregation: view.LastValue(),
+	}
+)
+
+// Status represents the state of resource discovery
+type Status struct {
+	// number of group versions whose resources are discovered
+	GroupVersions int `json:"groupVersions"`
+
+	// group versions whose resources could not be discovered by
+	// the latest discovery anchored by the reason. Last discovered
+	// resources if any are served for these.
+	FailedGroupVersions map[string]string `json:"failedGroupVersions,omitempty"`
+}
+
+// setFailedGroupVersions replaces the failed group versions with
+// the given ones
+func (mgr *APIResourceManager) setFailedGroupVersions(failed map[string]error) {
+	mgr.failedMutex.Lock()
+	defer mgr.failedMutex.Unlock()
+
+	for groupVersion, err := range failed {
+		if _, found := mgr.failedGroupVersions[groupVersion]; !found {
+			glog.Warningf(
+				"API resource discovery of %q failed: Will serve last discovered resources if any: %v",
+				groupVersion, err,
+			)
+		}
+	}
+	mgr.failedGroupVersions = failed
+	mgr.recordFailedGroupVersions()
+}
+
+// setGroupVersionFailure sets the given group version as failed if
+// the given error is not nil & as not failed otherwise
+func (mgr *APIResourceManager) setGroupVersionFailure(groupVersion string, err error) {
+	mgr.failedMutex.Lock()
+	defer mgr.failedMutex.Unlock()
+
+	if err == nil {
+		delete(mgr.failedGroupVersions, groupVersion)
+	} else {
+		if mgr.failedGroupVersions == nil {
+			mgr.failedGroupVersions = make(map[string]error)
+		}
+		mgr.failedGroupVersions[groupVersion] = err
+	}
+	mgr.recordFailedGroupVersions()
+}
+
+// recordFailedGroupVersions records the number of failed group
+// versions
+//
+// NOTE:
+//	This is expected to be invoked with failedMutex held
+func (mgr *APIResourceManager) recordFailedGroupVersions() {
+	stats.Record(
+		context.Background(),
+		failedGroupVersionsMeasure.M(int64(len(mgr.failedGroupVersions))),
+	)
+}
+
+// Status returns the current state of resource discovery
+func (mgr *APIResourceManager) Status() Status {
+	mgr.mutex.RLock()
+	status := Status{
+		GroupVersions: len(mgr.resources),
+	}
+	mgr.mutex.RUnlock()
+
+	mgr.failedMutex.RLock()
+	defer mgr.failedMutex.RUnlock()
+
+	if len(mgr.failedGroupVersions) != 0 {
+		status.FailedGroupVersions = make(map[string]string, len(mgr.failedGroupVersions))
+	}
+	for groupVersion, err := range mgr.failedGroupVersions {
+		status.FailedGroupVersions[groupVersion] = err.Error()
+	}
+	return status
+}
+
+// ServeHTTP implements http.Handler interface. This responds with the
+// discovery status as json.
+func (mgr *APIResourceManager) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	data, err := json.Marshal(mgr.Status())
+	if err != nil {
+		glog.Errorf("Can't marshal discovery status: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestAPIResourceManagerToleratesFailedGroupVersions(t *testing.T) {
+	metricsGV := schema.GroupVersion{Group: "metrics.k8s.io", Version: "v1beta1"}
+	fake := newFakeDiscovery(
+		newResourceList("v1", metav1.APIResource{Name: "pods", Kind: "Pod"}),
+		newResourceList(
+			metricsGV.String(), metav1.APIResource{Name: "pods", Kind: "PodMetrics"},
+		),
+	)
+	mgr := NewAPIResourceManager(fake)
+	mgr.refresh()
+
+	// failed group version serves its last discovered resources
+	fake.failed = map[schema.GroupVersion]error{
+		metricsGV: errors.New("service unavailable"),
+	}
+	fake.Resources = append(
+		fake.Resources,
+		newResourceList("apps/v1", metav1.APIResource{Name: "deployments", Kind: "Deployment"}),
+	)
+	mgr.refresh()
+	if mgr.GetByResource("apps/v1", "deployments") == nil {
+		t.Fatalf("Expected deployments to be discovered despite failed group versions")
+	}
+	if mgr.GetByKind(metricsGV.String(), "PodMetrics") == nil {
+		t.Fatalf("Expected last discovered resources of %q to be served", metricsGV)
+	}
+	if got := mgr.GetPreferredAPIVersion("metrics.k8s.io"); got != metricsGV.String() {
+		t.Fatalf("Expected preferred version %q got %q", metricsGV, got)
+	}
+	status := mgr.Status()
+	if status.GroupVersions != 3 {
+		t.Fatalf("Expected 3 group versions got %d", status.GroupVersions)
+	}
+	if got := status.FailedGroupVersions[metricsGV.String()]; got != "service unavailable" {
+		t.Fatalf("Expected %q to fail got %q", metricsGV, got)
+	}
+
+	// group version that never got discovered is not served
+	fake.failed[schema.GroupVersion{Group: "test.io", Version: "v1"}] = errors.New("timeout")
+	mgr.refresh()
+	if got := len(mgr.Status().FailedGroupVersions); got != 2 {
+		t.Fatalf("Expected 2 failed group versions got %d", got)
+	}
+	if mgr.GetPreferredAPIVersion("test.io") != "" {
+		t.Fatalf("Expected test.io not to be served")
+	}
+
+	// recovered group versions are no longer failed
+	fake.failed = nil
+	mgr.refresh()
+	if got := mgr.Status().FailedGroupVersions; len(got) != 0 {
+		t.Fatalf("Expected no failed group versions got %v", got)
+	}
+}
+
+func TestAPIResourceManagerSetGroupVersionFailure(t *testing.T) {
+	mgr := NewAPIResourceManager(newFakeDiscovery())
+	mgr.setGroupVersionFailure("test.io/v1", errors.New("timeout"))
+	mgr.setGroupVersionFailure("apps/v1", nil)
+	if got := mgr.Status().FailedGroupVersions; len(got) != 1 || got["test.io/v1"] != "timeout" {
+		t.Fatalf("Expected test.io/v1 to fail got %v", got)
+	}
+	mgr.setGroupVersionFailure("test.io/v1", nil)
+	if got := mgr.Status().FailedGroupVersions; len(got) != 0 {
+		t.Fatalf("Expected no failed group versions got %v", got)
+	}
+}
+
+func TestAPIResourceManagerServeHTTP(t *testing.T) {
+	mgr := NewAPIResourceManager(newFakeDiscovery(
+		newResourceList("v1", metav1.APIResource{Name: "pods", Kind: "Pod"}),
+	))
+	mgr.refresh()
+	mgr.setGroupVersionFailure("test.io/v1", errors.New("timeout"))
+
+	rec := httptest.NewRecorder()
+	mgr.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/discovery", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d got %d", http.StatusOK, rec.Code)
+	}
+	var got Status
+	err := json.Unmarshal(rec.Body.Bytes(), &got)
+	if err != nil {
+		t.Fatalf("Expected valid status got %v", err)
+	}
+	if got.GroupVersions != 1 || got.FailedGroupVersions["test.io/v1"] != "timeout" {
+		t.Fatalf("Expected 1 group version & test.io/v1 to fail got %+v", got)
+	}
+}
@@ -0,0 +1,295 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+var (
+	// crdGVR is the resource whose changes result in new or removed
+	// custom resources
+	crdGVR = schema.GroupVersionResource{
+		Group:    "apiextensions.k8s.io",
+		Version:  "v1beta1",
+		Resource: "customresourcedefinitions",
+	}
+
+	// apiServiceGVR is the resource whose changes result in new or
+	// removed aggregated resources
+	apiServiceGVR = schema.GroupVersionResource{
+		Group:    "apiregistration.k8s.io",
+		Version:  "v1",
+		Resource: "apiservices",
+	}
+)
+
+// StartWatching executes resource discovery of the group versions
+// whose custom resource definitions or api services change. A full
+// resource discovery is executed at start & in the given resync
+// interval.
+//
+// NOTE:
+//	Newly installed resources become available as soon as their
+// definitions are observed instead of waiting for the next full
+// discovery. Use Stop to stop this.
+func (mgr *APIResourceManager) StartWatching(
+	client dynamic.Interface, resyncInterval time.Duration,
+) {
+	mgr.stopCh = make(chan struct{})
+	mgr.doneCh = make(chan struct{})
+
+	queue := workqueue.NewNamedRateLimitingQueue(
+		workqueue.DefaultControllerRateLimiter(), "discovery",
+	)
+	w := &groupVersionWatcher{
+		mgr:   mgr,
+		queue: queue,
+	}
+	factory := dynamicinformer.NewDynamicSharedInformerFactory(client, 0)
+	informers := []cache.SharedIndexInformer{
+		factory.ForResource(crdGVR).Informer(),
+		factory.ForResource(apiServiceGVR).Informer(),
+	}
+	for _, informer := range informers {
+		informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc:    w.enqueue,
+			UpdateFunc: func(old, cur interface{}) { w.enqueue(cur) },
+			DeleteFunc: w.enqueue,
+		})
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer close(mgr.doneCh)
+		wg.Wait()
+	}()
+	go func() {
+		defer wg.Done()
+		defer queue.ShutDown()
+
+		for {
+			mgr.refresh()
+
+			select {
+			case <-mgr.stopCh:
+				// return / exit from this anonymous func
+				return
+			case <-time.After(mgr.jitter(resyncInterval)):
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		wait.Until(w.worker, time.Second, mgr.stopCh)
+	}()
+	go func() {
+		defer wg.Done()
+		factory.Start(mgr.stopCh)
+		var hasSynced []cache.InformerSynced
+		for _, informer := range informers {
+			hasSynced = append(hasSynced, informer.HasSynced)
+		}
+		if !cache.WaitForCacheSync(mgr.stopCh, hasSynced...) {
+			return
+		}
+		// changes observed so far are covered by a full discovery
+		w.setSynced()
+		mgr.refresh()
+	}()
+}
+
+// groupVersionWatcher queues the group versions of the changed
+// custom resource definitions & api services to be discovered
+type groupVersionWatcher struct {
+	mgr   *APIResourceManager
+	queue workqueue.RateLimitingInterface
+
+	mutex  sync.RWMutex
+	synced bool
+}
+
+// setSynced flags the initial listing of definitions to be complete
+func (w *groupVersionWatcher) setSynced() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.synced = true
+}
+
+// isSynced returns true if the initial listing of definitions is
+// complete
+func (w *groupVersionWatcher) isSynced() bool {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+
+	return w.synced
+}
+
+// enqueue queues the group versions of the given definition
+func (w *groupVersionWatcher) enqueue(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		utilruntime.HandleError(
+			errors.Errorf("Can't queue discovery: Unexpected type %T", obj),
+		)
+		return
+	}
+	if u.GetKind() == "CustomResourceDefinition" {
+		w.mgr.setCRDDeprecations(u)
+	}
+	if !w.isSynced() {
+		// initial listing is followed by a full discovery
+		return
+	}
+	for _, gv := range getDefinedGroupVersions(u) {
+		w.queue.Add(gv)
+	}
+}
+
+// worker discovers the queued group versions till the queue is shut
+// down
+func (w *groupVersionWatcher) worker() {
+	for w.processNextItem() {
+	}
+}
+
+// processNextItem discovers the next queued group version
+func (w *groupVersionWatcher) processNextItem() bool {
+	key, quit := w.queue.Get()
+	if quit {
+		return false
+	}
+	defer w.queue.Done(key)
+
+	err := w.mgr.refreshGroupVersion(key.(string))
+	if err != nil {
+		glog.Warningf("API resource discovery of %q failed: Will retry: %v", key, err)
+		w.queue.AddRateLimited(key)
+		return true
+	}
+	w.queue.Forget(key)
+	return true
+}
+
+// getDefinedGroupVersions returns the group versions that are served
+// as per the given custom resource definition or api service
+func getDefinedGroupVersions(obj *unstructured.Unstructured) []string {
+	group, _, _ := unstructured.NestedString(obj.Object, "spec", "group")
+	versions := map[string]bool{}
+	version, _, _ := unstructured.NestedString(obj.Object, "spec", "version")
+	if version != "" {
+		versions[version] = true
+	}
+	items, _, _ := unstructured.NestedSlice(obj.Object, "spec", "versions")
+	for _, item := range items {
+		itemObj, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(itemObj, "name")
+		if name != "" {
+			versions[name] = true
+		}
+	}
+	var gvs []string
+	for v := range versions {
+		gvs = append(gvs, schema.GroupVersion{Group: group, Version: v}.String())
+	}
+	return gvs
+}
+
+// refreshGroupVersion discovers the resources of the given group
+// version & replaces these in the local cache. The group version is
+// removed from the local cache if it is no longer served.
+func (mgr *APIResourceManager) refreshGroupVersion(groupVersion string) error {
+	start := time.Now()
+	err := mgr.replaceGroupVersion(groupVersion)
+	recordRefresh(refreshScopeGroupVersion, start, err)
+	mgr.setGroupVersionFailure(groupVersion, err)
+	if err != nil {
+		return err
+	}
+	mgr.recordCardinality()
+	mgr.saveCache()
+	return nil
+}
+
+// replaceGroupVersion discovers the resources of the given group
+// version & replaces these in the local cache
+func (mgr *APIResourceManager) replaceGroupVersion(groupVersion string) error {
+	glog.V(7).Infof("Discovering API resources of %q", groupVersion)
+
+	apiResourceSet, err := mgr.Client.ServerResourcesForGroupVersion(groupVersion)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	isServed := err == nil
+	apiGroupList, err := mgr.Client.ServerGroups()
+	if err != nil {
+		return err
+	}
+
+	mgr.mutex.Lock()
+	defer mgr.mutex.Unlock()
+
+	// Replace the local cache. Local cache is nil if the full
+	// discovery has not completed yet.
+	groupVersions := make(map[string]apiResourceRegistry, len(mgr.resources)+1)
+	for gv, registry := range mgr.resources {
+		groupVersions[gv] = registry
+	}
+	if isServed {
+		groupVersions[groupVersion] = newAPIResourceRegistry(apiResourceSet)
+	} else {
+		delete(groupVersions, groupVersion)
+	}
+
+	// preferred version of a group is its first discovered version
+	// similar to a full discovery
+	preferredVersions := make(map[string]string)
+	for _, apiGroup := range apiGroupList.Groups {
+		for _, version := range apiGroup.Versions {
+			if _, found := groupVersions[version.GroupVersion]; found {
+				preferredVersions[apiGroup.Name] = version.GroupVersion
+				break
+			}
+		}
+	}
+	mgr.resources = groupVersions
+	mgr.preferredVersions = preferredVersions
+
+	glog.V(7).Infof("API resources discovery of %q completed", groupVersion)
+	return nil
+}
@@ -0,0 +1,222 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// newCRD returns a custom resource definition of the given group
+// that serves the given versions
+func newCRD(group string, versions ...string) *unstructured.Unstructured {
+	var items []interface{}
+	for _, v := range versions {
+		items = append(items, map[string]interface{}{"name": v})
+	}
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "apiextensions.k8s.io/v1beta1",
+			"kind":       "CustomResourceDefinition",
+			"metadata": map[string]interface{}{
+				"name": "tests." + group,
+			},
+			"spec": map[string]interface{}{
+				"group":    group,
+				"versions": items,
+			},
+		},
+	}
+}
+
+// newAPIService returns an api service of the given group & version
+func newAPIService(group, version string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "apiregistration.k8s.io/v1",
+			"kind":       "APIService",
+			"metadata": map[string]interface{}{
+				"name": version + "." + group,
+			},
+			"spec": map[string]interface{}{
+				"group":   group,
+				"version": version,
+			},
+		},
+	}
+}
+
+func TestGetDefinedGroupVersions(t *testing.T) {
+	var tests = map[string]struct {
+		obj  *unstructured.Unstructured
+		want []string
+	}{
+		"crd with versions": {
+			obj:  newCRD("test.io", "v1", "v1beta1"),
+			want: []string{"test.io/v1", "test.io/v1beta1"},
+		},
+		"crd with version & same versions": {
+			obj: func() *unstructured.Unstructured {
+				crd := newCRD("test.io", "v1")
+				unstructured.SetNestedField(crd.Object, "v1", "spec", "version")
+				return crd
+			}(),
+			want: []string{"test.io/v1"},
+		},
+		"api service": {
+			obj:  newAPIService("metrics.k8s.io", "v1beta1"),
+			want: []string{"metrics.k8s.io/v1beta1"},
+		},
+		"no versions": {
+			obj: newCRD("test.io"),
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			got := getDefinedGroupVersions(mock.obj)
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, mock.want) {
+				t.Fatalf("Expected %v got %v", mock.want, got)
+			}
+		})
+	}
+}
+
+func TestGroupVersionWatcherEnqueue(t *testing.T) {
+	var tests = map[string]struct {
+		obj      interface{}
+		isSynced bool
+		want     []string
+	}{
+		"crd before initial listing": {
+			obj: newCRD("test.io", "v1"),
+		},
+		"crd after initial listing": {
+			obj:      newCRD("test.io", "v1"),
+			isSynced: true,
+			want:     []string{"test.io/v1"},
+		},
+		"deleted crd": {
+			obj: cache.DeletedFinalStateUnknown{
+				Key: "tests.test.io",
+				Obj: newCRD("test.io", "v1"),
+			},
+			isSynced: true,
+			want:     []string{"test.io/v1"},
+		},
+		"api service after initial listing": {
+			obj:      newAPIService("metrics.k8s.io", "v1beta1"),
+			isSynced: true,
+			want:     []string{"metrics.k8s.io/v1beta1"},
+		},
+		"unexpected type": {
+			obj:      "test.io/v1",
+			isSynced: true,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			w := &groupVersionWatcher{
+				mgr:   NewAPIResourceManager(newFakeDiscovery()),
+				queue: workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+			}
+			defer w.queue.ShutDown()
+			if mock.isSynced {
+				w.setSynced()
+			}
+			w.enqueue(mock.obj)
+			var got []string
+			for w.queue.Len() > 0 {
+				key, _ := w.queue.Get()
+				got = append(got, key.(string))
+				w.queue.Done(key)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, mock.want) {
+				t.Fatalf("Expected queued %v got %v", mock.want, got)
+			}
+		})
+	}
+}
+
+func TestGroupVersionWatcherRefreshesQueuedGroupVersions(t *testing.T) {
+	fake := newFakeDiscovery(
+		newResourceList("v1", metav1.APIResource{Name: "pods", Kind: "Pod"}),
+	)
+	mgr := NewAPIResourceManager(fake)
+	mgr.refresh()
+	w := &groupVersionWatcher{
+		mgr:   mgr,
+		queue: workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+	}
+	defer w.queue.ShutDown()
+	w.setSynced()
+
+	// a new crd is discovered without a full discovery
+	fake.Resources = append(
+		fake.Resources,
+		newResourceList("test.io/v1", metav1.APIResource{Name: "tests", Kind: "Test"}),
+	)
+	w.enqueue(newCRD("test.io", "v1"))
+	if !w.processNextItem() {
+		t.Fatalf("Expected queued crd to be processed")
+	}
+	if mgr.GetByResource("test.io/v1", "tests") == nil {
+		t.Fatalf("Expected tests of test.io/v1 to be discovered")
+	}
+	if got := mgr.GetPreferredAPIVersion("test.io"); got != "test.io/v1" {
+		t.Fatalf("Expected preferred version test.io/v1 got %q", got)
+	}
+	if mgr.GetByResource("v1", "pods") == nil {
+		t.Fatalf("Expected pods of v1 to be retained")
+	}
+
+	// a new api service is discovered without a full discovery
+	fake.Resources = append(
+		fake.Resources,
+		newResourceList("metrics.k8s.io/v1beta1", metav1.APIResource{Name: "pods", Kind: "PodMetrics"}),
+	)
+	w.enqueue(newAPIService("metrics.k8s.io", "v1beta1"))
+	w.processNextItem()
+	if mgr.GetByKind("metrics.k8s.io/v1beta1", "PodMetrics") == nil {
+		t.Fatalf("Expected pods of metrics.k8s.io/v1beta1 to be discovered")
+	}
+
+	// a removed crd is no longer served
+	fake.Resources = fake.Resources[:1]
+	w.enqueue(newCRD("test.io", "v1"))
+	w.processNextItem()
+	if mgr.GetByResource("test.io/v1", "tests") != nil {
+		t.Fatalf("Expected tests of test.io/v1 to be removed")
+	}
+	if got := mgr.GetPreferredAPIVersion("test.io"); got != "" {
+		t.Fatalf("Expected no preferred version of test.io got %q", got)
+	}
+	if got := len(mgr.Status().FailedGroupVersions); got != 0 {
+		t.Fatalf("Expected no failed group versions got %d", got)
+	}
+}
@@ -33,9 +33,29 @@ type SharedInformerFactory struct {
 	clientset     *dynamicclientset.Clientset
 	defaultResync time.Duration
 
+	// restricts the namespaces whose resources get cached
+	namespaceFilter NamespaceFilter
+
+	// removes the fields of the resources before these get cached
+	cacheTransform CacheTransform
+
+	// number of resources fetched per list request; resources are
+	// listed in a single request if this is not set
+	listPageSize int64
+
+	// duration for which a shared informer without subscribers is
+	// kept running; such an informer is stopped immediately if this
+	// is not set
+	idleTTL time.Duration
+
 	mutex           sync.Mutex
-	refCount        map[string]int
 	sharedInformers map[string]*sharedResourceInformer
+
+	// subscribers of each shared informer mapped to their names
+	subscribers map[string]map[*ResourceInformer]string
+
+	// shared informers without subscribers that are yet to be stopped
+	idleInformers map[string]*idleInformer
 }
 
 // NewSharedInformerFactory creates a new factory for shared, dynamic informers.
@@ -44,12 +64,81 @@ func NewSharedInformerFactory(
 	clientset *dynamicclientset.Clientset,
 	defaultResync time.Duration,
 ) *SharedInformerFactory {
-	return &SharedInformerFactory{
+	return NewSharedInformerFactoryWithOptions(clientset, defaultResync)
+}
+
+// SharedInformerFactoryOption is a typed function that helps in
+// building a SharedInformerFactory instance
+//
+// NOTE:
+//	This follows the pattern known as "functional options".
+type SharedInformerFactoryOption func(*SharedInformerFactory)
+
+// WithNamespaceFilter restricts all the informers created by the
+// factory to the namespaces allowed by the given filter
+func WithNamespaceFilter(filter NamespaceFilter) SharedInformerFactoryOption {
+	return func(f *SharedInformerFactory) {
+		f.namespaceFilter = filter
+	}
+}
+
+// WithCacheTransform removes the fields set in the given transform
+// from the resources cached by all the informers created by the factory
+func WithCacheTransform(transform CacheTransform) SharedInformerFactoryOption {
+	return func(f *SharedInformerFactory) {
+		f.cacheTransform = transform
+	}
+}
+
+// WithListPageSize makes the informers created by the factory list
+// their resources in pages of the given size
+func WithListPageSize(pageSize int64) SharedInformerFactoryOption {
+	return func(f *SharedInformerFactory) {
+		f.listPageSize = pageSize
+	}
+}
+
+// WithIdleTTL keeps the informers created by the factory running for
+// the given duration after their last subscriber is closed. An informer
+// that gets subscribed again within this duration is reused along with
+// its synced cache.
+func WithIdleTTL(ttl time.Duration) SharedInformerFactoryOption {
+	return func(f *SharedInformerFactory) {
+		f.idleTTL = ttl
+	}
+}
+
+// NewSharedInformerFactoryWithOptions creates a new factory for shared,
+// dynamic informers with the given options.
+func NewSharedInformerFactoryWithOptions(
+	clientset *dynamicclientset.Clientset,
+	defaultResync time.Duration,
+	opts ...SharedInformerFactoryOption,
+) *SharedInformerFactory {
+	f := &SharedInformerFactory{
 		clientset:       clientset,
 		defaultResync:   defaultResync,
-		refCount:        make(map[string]int),
 		sharedInformers: make(map[string]*sharedResourceInformer),
+		subscribers:     make(map[string]map[*ResourceInformer]string),
+		idleInformers:   make(map[string]*idleInformer),
 	}
+	for _, o := range opts {
+		o(f)
+	}
+	return f
+}
+
+// IsNamespaceAllowed returns true if resources of the given namespace
+// can be cached by the informers of this factory
+func (f *SharedInformerFactory) IsNamespaceAllowed(namespace string) bool {
+	return f.namespaceFilter.IsAllowed(namespace)
+}
+
+// IsManagedFieldsStripped returns true if metadata.managedFields of
+// the resources are not available in the caches of this factory's
+// informers
+func (f *SharedInformerFactory) IsManagedFieldsStripped() bool {
+	return f.cacheTransform.StripManagedFields
 }
 
 // GetOrCreate returns a dynamic informer and lister for the given resource.
@@ -59,79 +148,258 @@ func NewSharedInformerFactory(
 // If this function returns successfully, the caller should ensure they call
 // Close() on the returned ResourceInformer when they no longer need it.
 // Shared informers that become unused will be stopped to minimize our load on
-// the API server.
+// the API server & to reclaim the memory of their caches.
 func (f *SharedInformerFactory) GetOrCreate(apiVersion, resource string) (*ResourceInformer, error) {
+	return f.GetOrCreateInNamespace(apiVersion, resource, "")
+}
+
+// GetOrCreateInNamespace returns a dynamic informer and lister for the
+// given resource that caches the resources of the given namespace only.
+// Resources of all namespaces are cached if the given namespace is empty.
+//
+// NOTE:
+//	Informers are shared per namespace. In other words, an informer for
+// a namespace is not shared with an informer for all namespaces.
+func (f *SharedInformerFactory) GetOrCreateInNamespace(
+	apiVersion, resource, namespace string,
+) (*ResourceInformer, error) {
+	return f.GetOrCreateWithSelector(apiVersion, resource, namespace, ListSelector{})
+}
+
+// GetOrCreateWithSelector returns a dynamic informer and lister for
+// the given resource that caches the resources of the given namespace
+// that match the given selector. Resources of all namespaces are
+// cached if the given namespace is empty.
+//
+// NOTE:
+//	The selector is pushed down to the API server. Hence resources
+// that don't match the selector are neither listed nor watched.
+func (f *SharedInformerFactory) GetOrCreateWithSelector(
+	apiVersion, resource, namespace string, selector ListSelector,
+) (*ResourceInformer, error) {
+	return f.getOrCreate(apiVersion, resource, namespace, selector, cacheFull)
+}
+
+// GetOrCreateMetadataOnly returns a dynamic informer and lister for
+// the given resource that caches only the metadata of the resources
+// of the given namespace that match the given selector. Cached
+// resources have their apiVersion, kind & metadata set.
+//
+// NOTE:
+//	Metadata only informers are not shared with the informers that
+// cache the entire resources.
+func (f *SharedInformerFactory) GetOrCreateMetadataOnly(
+	apiVersion, resource, namespace string, selector ListSelector,
+) (*ResourceInformer, error) {
+	return f.getOrCreate(apiVersion, resource, namespace, selector, cacheMetadataOnly)
+}
+
+// GetOrCreateReadOnly returns a dynamic informer and lister for the
+// given resource that caches the resources of the given namespace that
+// match the given selector. Built-in resources are listed & watched as
+// protobuf if the clientset of this factory enables protobuf.
+//
+// NOTE:
+//	Resources cached by read only informers may miss the fields unknown
+// to this binary. Hence these resources must never be written back.
+// Read only informers are not shared with the other informers.
+func (f *SharedInformerFactory) GetOrCreateReadOnly(
+	apiVersion, resource, namespace string, selector ListSelector,
+) (*ResourceInformer, error) {
+	return f.getOrCreate(apiVersion, resource, namespace, selector, cacheReadOnly)
+}
+
+// cacheMode determines how the resources are listed & cached by an
+// informer
+type cacheMode int
+
+const (
+	// cacheFull caches the entire resources
+	cacheFull cacheMode = iota
+
+	// cacheMetadataOnly caches only the metadata of the resources
+	cacheMetadataOnly
+
+	// cacheReadOnly caches the entire resources that are never
+	// written back
+	cacheReadOnly
+)
+
+// getOrCreate returns a dynamic informer and lister for the given
+// resource that caches the resources as per the given mode
+func (f *SharedInformerFactory) getOrCreate(
+	apiVersion, resource, namespace string,
+	selector ListSelector,
+	mode cacheMode,
+) (*ResourceInformer, error) {
+	key := resourceKeyWithSelector(apiVersion, resource, namespace, selector)
+	switch mode {
+	case cacheMetadataOnly:
+		key = key + "#metadata"
+	case cacheReadOnly:
+		key = key + "#readonly"
+	}
+	if !f.namespaceFilter.IsAllowed(namespace) {
+		return nil, fmt.Errorf(
+			"Failed to subscribe shared informer %v: Namespace %q is not allowed",
+			key,
+			namespace,
+		)
+	}
+
 	f.mutex.Lock()
 	defer f.mutex.Unlock()
 
 	// Return existing informer if there is one.
-	key := resourceKey(apiVersion, resource)
-	if sharedInformer, ok := f.sharedInformers[key]; ok {
-		count := f.refCount[key] + 1
-		f.refCount[key] = count
-		glog.V(4).Infof(
-			"Subscribed to shared informer for %v in %v (total subscribers now %v)",
-			resource,
-			apiVersion,
-			count,
-		)
-		return newResourceInformer(sharedInformer), nil
+	if _, ok := f.sharedInformers[key]; ok {
+		return f.subscribe(key), nil
 	}
 
 	// Create one if it doesn't exist.
-	client, err := f.clientset.GetClientByResource(apiVersion, resource)
+	var client *dynamicclientset.ResourceClient
+	var err error
+	switch mode {
+	case cacheMetadataOnly:
+		client, err = f.clientset.GetMetadataClientByResource(apiVersion, resource)
+	case cacheReadOnly:
+		client, err = f.clientset.GetReadOnlyClientByResource(apiVersion, resource)
+	default:
+		client, err = f.clientset.GetClientByResource(apiVersion, resource)
+	}
 	if err != nil {
 		return nil, fmt.Errorf(
 			"Failed to subscribe shared informer %v: %v", key, err,
 		)
 	}
-	stopCh := make(chan struct{})
-
-	// closeFn is called by users of the shared informer (via Close())
-	// to indicate they no longer need it. We do all incrementing/decrementing
-	// of the ref count in the factory while holding the factory mutex, so that
-	// removing shared informers is serialized along with creating them.
-	closeFn := func() {
-		f.mutex.Lock()
-		defer f.mutex.Unlock()
-
-		count := f.refCount[key] - 1
-		glog.V(4).Infof(
-			"Unsubscribed from shared informer %v in %v (total subscribers now %v)",
-			resource,
-			apiVersion,
-			count,
-		)
-
-		if count > 0 {
-			// Others are still using it.
-			f.refCount[key] = count
-			return
-		}
-
-		// We're the last ones using it.
-		glog.V(4).Infof(
-			"Stopping shared informer for %v in %v (no more subscribers)",
-			resource,
-			apiVersion,
-		)
-		close(stopCh)
-		delete(f.refCount, key)
-		delete(f.sharedInformers, key)
+	filter := f.namespaceFilter
+	if namespace != "" {
+		// list & watch the resources of this namespace only
+		client = client.Namespace(namespace)
+		filter = NamespaceFilter{}
 	}
 
 	glog.V(4).Infof("Starting shared informer for %v in %v", resource, apiVersion)
-	sharedInformer := newSharedResourceInformer(client, f.defaultResync, closeFn)
-	f.sharedInformers[key] = sharedInformer
-	f.refCount[key] = 1
+	stopCh := make(chan struct{})
+	metrics := newCacheMetrics(apiVersion, resource, key)
+	health := newListWatchHealth(key, stopCh)
+	// resources are listed in pages, filtered & transformed
+	// before these get cached; failures are backed off
+	lw := health.wrapListWatch(
+		metrics.wrapListWatch(
+			newPagedListWatch(
+				f.cacheTransform.wrapListWatch(filter.newListWatch(client, selector)),
+				f.listPageSize,
+			),
+		),
+	)
+	sharedInformer := newFilteredSharedResourceInformer(client, lw, f.defaultResync)
+	sharedInformer.stopCh = stopCh
+	sharedInformer.metrics = metrics
+	sharedInformer.health = health
+	sharedInformer.informer.AddEventHandler(metrics)
+	f.register(key, sharedInformer)
 
 	// Start the new informer immediately.
 	// Users should check HasSynced() before using it.
-	go sharedInformer.informer.Run(stopCh)
+	go sharedInformer.informer.Run(sharedInformer.stopCh)
 
-	return newResourceInformer(sharedInformer), nil
+	return f.subscribe(key), nil
+}
+
+// GetOrCreateInNamespaces returns one dynamic informer & lister per
+// given namespace for the given resource that caches the resources
+// matching the given selector. The returned informers are keyed by
+// their namespaces. Duplicate namespaces are ignored.
+//
+// NOTE:
+//	Either all the informers are returned or none. Informers created
+// by this call are closed if any of the namespaces fails.
+func (f *SharedInformerFactory) GetOrCreateInNamespaces(
+	apiVersion, resource string, namespaces []string, selector ListSelector,
+) (map[string]*ResourceInformer, error) {
+	return f.getOrCreateInNamespaces(apiVersion, resource, namespaces, selector, cacheFull)
+}
+
+// GetOrCreateMetadataOnlyInNamespaces returns one metadata only
+// informer & lister per given namespace for the given resource. The
+// returned informers are keyed by their namespaces.
+func (f *SharedInformerFactory) GetOrCreateMetadataOnlyInNamespaces(
+	apiVersion, resource string, namespaces []string, selector ListSelector,
+) (map[string]*ResourceInformer, error) {
+	return f.getOrCreateInNamespaces(
+		apiVersion, resource, namespaces, selector, cacheMetadataOnly,
+	)
+}
+
+// GetOrCreateReadOnlyInNamespaces returns one read only informer &
+// lister per given namespace for the given resource. The returned
+// informers are keyed by their namespaces.
+func (f *SharedInformerFactory) GetOrCreateReadOnlyInNamespaces(
+	apiVersion, resource string, namespaces []string, selector ListSelector,
+) (map[string]*ResourceInformer, error) {
+	return f.getOrCreateInNamespaces(
+		apiVersion, resource, namespaces, selector, cacheReadOnly,
+	)
+}
+
+// getOrCreateInNamespaces returns one informer & lister per given
+// namespace that caches the resources as per the given mode
+func (f *SharedInformerFactory) getOrCreateInNamespaces(
+	apiVersion, resource string,
+	namespaces []string,
+	selector ListSelector,
+	mode cacheMode,
+) (map[string]*ResourceInformer, error) {
+	for _, ns := range namespaces {
+		if ns == "" {
+			return nil, fmt.Errorf(
+				"Failed to subscribe shared informer %v: Empty namespace",
+				resourceKey(apiVersion, resource),
+			)
+		}
+		if !f.namespaceFilter.IsAllowed(ns) {
+			return nil, fmt.Errorf(
+				"Failed to subscribe shared informer %v: Namespace %q is not allowed",
+				resourceKeyInNamespace(apiVersion, resource, ns),
+				ns,
+			)
+		}
+	}
+	informers := make(map[string]*ResourceInformer, len(namespaces))
+	for _, ns := range namespaces {
+		if informers[ns] != nil {
+			continue
+		}
+		informer, err := f.getOrCreate(apiVersion, resource, ns, selector, mode)
+		if err != nil {
+			for _, created := range informers {
+				created.Close()
+			}
+			return nil, err
+		}
+		informers[ns] = informer
+	}
+	return informers, nil
 }
 
 func resourceKey(apiVersion, resource string) string {
 	return fmt.Sprintf("%s.%s", resource, apiVersion)
 }
+
+func resourceKeyInNamespace(apiVersion, resource, namespace string) string {
+	if namespace == "" {
+		return resourceKey(apiVersion, resource)
+	}
+	return fmt.Sprintf("%s/%s", resourceKey(apiVersion, resource), namespace)
+}
+
+func resourceKeyWithSelector(
+	apiVersion, resource, namespace string, selector ListSelector,
+) string {
+	if selector.IsEmpty() {
+		return resourceKeyInNamespace(apiVersion, resource, namespace)
+	}
+	return fmt.Sprintf(
+		"%s?%s", resourceKeyInNamespace(apiVersion, resource, namespace), selector,
+	)
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package informer
+
+import (
+	"testing"
+)
+
+func TestSharedInformerFactoryGetOrCreateInNamespacesError(t *testing.T) {
+	var tests = map[string]struct {
+		filter     NamespaceFilter
+		namespaces []string
+	}{
+		"empty namespace": {
+			namespaces: []string{"ns1", ""},
+		},
+		"excluded namespace": {
+			filter:     NamespaceFilter{ExcludeNamespaces: []string{"ns2"}},
+			namespaces: []string{"ns1", "ns2"},
+		},
+		"namespace not in allow list": {
+			filter:     NamespaceFilter{Namespaces: []string{"ns1"}},
+			namespaces: []string{"ns1", "ns3"},
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			f := NewSharedInformerFactoryWithOptions(
+				nil, 0, WithNamespaceFilter(mock.filter),
+			)
+			got, err := f.GetOrCreateInNamespaces(
+				"v1", "configmaps", mock.namespaces, ListSelector{},
+			)
+			if err == nil {
+				t.Fatalf("Expected error got none")
+			}
+			if got != nil {
+				t.Fatalf("Expected no informers got %v", got)
+			}
+			if len(f.sharedInformers) != 0 {
+				t.Fatalf("Expected no shared informers got %d", len(f.sharedInformers))
+			}
+		})
+	}
+}
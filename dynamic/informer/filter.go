@@ -0,0 +1,192 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package informer
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+
+	dynamicclientset "openebs.io/metac/dynamic/clientset"
+)
+
+// NamespaceFilter restricts the namespaces whose resources are
+// cached by the informers
+//
+// NOTE:
+//	Cluster scoped resources are never filtered
+type NamespaceFilter struct {
+	// Namespaces whose resources are cached. Resources of all the
+	// namespaces are cached if this is empty.
+	Namespaces []string
+
+	// Namespaces whose resources are never cached. This has higher
+	// priority than Namespaces.
+	ExcludeNamespaces []string
+}
+
+// IsEmpty returns true if this filter does not restrict any
+// namespace
+func (f NamespaceFilter) IsEmpty() bool {
+	return len(f.Namespaces) == 0 && len(f.ExcludeNamespaces) == 0
+}
+
+// IsAllowed returns true if resources of the given namespace can be
+// cached. An empty namespace i.e. cluster scope is always allowed.
+func (f NamespaceFilter) IsAllowed(namespace string) bool {
+	if namespace == "" {
+		return true
+	}
+	for _, ns := range f.ExcludeNamespaces {
+		if ns == namespace {
+			return false
+		}
+	}
+	if len(f.Namespaces) == 0 {
+		return true
+	}
+	for _, ns := range f.Namespaces {
+		if ns == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// allowedNamespaces returns the unique namespaces that can be cached
+func (f NamespaceFilter) allowedNamespaces() []string {
+	var allowed []string
+	seen := make(map[string]bool)
+	for _, ns := range f.Namespaces {
+		if seen[ns] || !f.IsAllowed(ns) {
+			continue
+		}
+		seen[ns] = true
+		allowed = append(allowed, ns)
+	}
+	return allowed
+}
+
+// excludeFieldSelector returns the field selector that lets the API
+// server skip the resources of excluded namespaces
+func (f NamespaceFilter) excludeFieldSelector() fields.Selector {
+	var selectors []fields.Selector
+	for _, ns := range f.ExcludeNamespaces {
+		selectors = append(selectors, fields.OneTermNotEqualSelector("metadata.namespace", ns))
+	}
+	return fields.AndSelectors(selectors...)
+}
+
+// newListWatch returns the list & watch functions of the given client
+// that are restricted to the namespaces allowed by this filter
+//
+// NOTE:
+//	Resources of a single allowed namespace & resources of excluded
+// namespaces are filtered by the API server. Resources of multiple
+// allowed namespaces are listed & watched across the cluster but are
+// filtered before they get cached.
+func (f NamespaceFilter) newListWatch(
+	client *dynamicclientset.ResourceClient, selector ListSelector,
+) cache.ListerWatcher {
+	if f.IsEmpty() || !client.Namespaced {
+		return newListWatch(client, selector.tweak, nil)
+	}
+	allowed := f.allowedNamespaces()
+	if len(f.Namespaces) == 1 && len(allowed) == 1 {
+		return newListWatch(client.Namespace(allowed[0]), selector.tweak, nil)
+	}
+	tweak := selector.tweak
+	if len(f.ExcludeNamespaces) != 0 {
+		exclude := f.excludeFieldSelector()
+		tweak = func(opts *metav1.ListOptions) {
+			selector.tweak(opts)
+			if opts.FieldSelector == "" {
+				opts.FieldSelector = exclude.String()
+				return
+			}
+			opts.FieldSelector = fields.AndSelectors(
+				fields.ParseSelectorOrDie(opts.FieldSelector), exclude,
+			).String()
+		}
+	}
+	var isAllowed func(string) bool
+	if len(f.Namespaces) != 0 {
+		isAllowed = f.IsAllowed
+	}
+	return newListWatch(client, tweak, isAllowed)
+}
+
+// newListWatch returns the list & watch functions of the given client.
+// List options are tweaked via the given tweak function & resources
+// are filtered via the given isAllowed function if these are not nil.
+//
+// NOTE:
+//	Watches always request bookmarks. Bookmarks keep the resource
+// version of a quiet resource up to date. Hence a watch that expires
+// is restarted from a recent resource version instead of relisting
+// all the resources.
+func newListWatch(
+	client *dynamicclientset.ResourceClient,
+	tweak func(*metav1.ListOptions),
+	isAllowed func(namespace string) bool,
+) cache.ListerWatcher {
+	return &cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			if tweak != nil {
+				tweak(&opts)
+			}
+			list, err := client.List(opts)
+			if err != nil || isAllowed == nil {
+				return list, err
+			}
+			var items []unstructured.Unstructured
+			for _, item := range list.Items {
+				if isAllowed(item.GetNamespace()) {
+					items = append(items, item)
+				}
+			}
+			list.Items = items
+			return list, nil
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			if tweak != nil {
+				tweak(&opts)
+			}
+			// servers that don't support bookmarks ignore this
+			opts.AllowWatchBookmarks = true
+			w, err := client.Watch(opts)
+			if err != nil || isAllowed == nil {
+				return w, err
+			}
+			return watch.Filter(w, func(in watch.Event) (watch.Event, bool) {
+				if in.Type == watch.Error || in.Type == watch.Bookmark {
+					return in, true
+				}
+				obj, err := meta.Accessor(in.Object)
+				if err != nil {
+					// let the informer deal with this
+					return in, true
+				}
+				return in, isAllowed(obj.GetNamespace())
+			}), nil
+		},
+	}
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package informer
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+
+	dynamicclientset "openebs.io/metac/dynamic/clientset"
+	dynamicdiscovery "openebs.io/metac/dynamic/discovery"
+)
+
+func TestNamespaceFilterIsAllowed(t *testing.T) {
+	var tests = map[string]struct {
+		filter    NamespaceFilter
+		namespace string
+		isAllowed bool
+	}{
+		"empty filter": {
+			namespace: "ns1",
+			isAllowed: true,
+		},
+		"cluster scope is always allowed": {
+			filter: NamespaceFilter{
+				Namespaces:        []string{"ns1"},
+				ExcludeNamespaces: []string{"ns2"},
+			},
+			namespace: "",
+			isAllowed: true,
+		},
+		"allowed namespace": {
+			filter:    NamespaceFilter{Namespaces: []string{"ns1", "ns2"}},
+			namespace: "ns2",
+			isAllowed: true,
+		},
+		"not an allowed namespace": {
+			filter:    NamespaceFilter{Namespaces: []string{"ns1", "ns2"}},
+			namespace: "ns3",
+			isAllowed: false,
+		},
+		"excluded namespace": {
+			filter:    NamespaceFilter{ExcludeNamespaces: []string{"ns1"}},
+			namespace: "ns1",
+			isAllowed: false,
+		},
+		"not an excluded namespace": {
+			filter:    NamespaceFilter{ExcludeNamespaces: []string{"ns1"}},
+			namespace: "ns2",
+			isAllowed: true,
+		},
+		"exclude has higher priority": {
+			filter: NamespaceFilter{
+				Namespaces:        []string{"ns1"},
+				ExcludeNamespaces: []string{"ns1"},
+			},
+			namespace: "ns1",
+			isAllowed: false,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			got := mock.filter.IsAllowed(mock.namespace)
+			if got != mock.isAllowed {
+				t.Fatalf("Expected is allowed %t got %t", mock.isAllowed, got)
+			}
+		})
+	}
+}
+
+func TestNamespaceFilterAllowedNamespaces(t *testing.T) {
+	filter := NamespaceFilter{
+		Namespaces:        []string{"ns1", "ns2", "ns1", "ns3"},
+		ExcludeNamespaces: []string{"ns2"},
+	}
+	got := filter.allowedNamespaces()
+	if len(got) != 2 || got[0] != "ns1" || got[1] != "ns3" {
+		t.Fatalf("Expected allowed namespaces [ns1 ns3] got %v", got)
+	}
+}
+
+func TestNamespaceFilterExcludeFieldSelector(t *testing.T) {
+	filter := NamespaceFilter{ExcludeNamespaces: []string{"ns1", "ns2"}}
+	got := filter.excludeFieldSelector().String()
+	expect := "metadata.namespace!=ns1,metadata.namespace!=ns2"
+	if got != expect {
+		t.Fatalf("Expected field selector %q got %q", expect, got)
+	}
+}
+
+type fakeWatchResourceInterface struct {
+	dynamic.ResourceInterface
+
+	watcher *watch.FakeWatcher
+	opts    metav1.ListOptions
+}
+
+func (f *fakeWatchResourceInterface) Watch(opts metav1.ListOptions) (watch.Interface, error) {
+	f.opts = opts
+	return f.watcher, nil
+}
+
+func TestNamespaceFilterNewListWatchBookmarks(t *testing.T) {
+	fakeRI := &fakeWatchResourceInterface{watcher: watch.NewFake()}
+	client := &dynamicclientset.ResourceClient{
+		ResourceInterface: fakeRI,
+		APIResource: &dynamicdiscovery.APIResource{
+			APIResource: metav1.APIResource{Namespaced: true},
+		},
+	}
+	filter := NamespaceFilter{Namespaces: []string{"ns1", "ns2"}}
+	w, err := filter.newListWatch(client, ListSelector{}).Watch(metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("Expected no error got %v", err)
+	}
+	defer w.Stop()
+	if !fakeRI.opts.AllowWatchBookmarks {
+		t.Fatalf("Expected watch to allow bookmarks")
+	}
+
+	bookmark := &unstructured.Unstructured{}
+	bookmark.SetResourceVersion("10")
+	go fakeRI.watcher.Action(watch.Bookmark, bookmark)
+	event := <-w.ResultChan()
+	if event.Type != watch.Bookmark {
+		t.Fatalf("Expected bookmark event got %q", event.Type)
+	}
+	got := event.Object.(*unstructured.Unstructured).GetResourceVersion()
+	if got != "10" {
+		t.Fatalf("Expected bookmark resource version 10 got %q", got)
+	}
+}
@@ -0,0 +1,229 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package informer
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+var (
+	// failureThreshold is the number of consecutive list or watch
+	// failures after which an informer is considered as failing
+	failureThreshold = 3
+
+	// failureResetInterval is the duration after which the previous
+	// failures of an informer are forgotten if it did not fail again
+	failureResetInterval = 5 * time.Minute
+
+	// baseFailureBackoff is the delay before the list or watch that
+	// follows the first failure. This doubles with every consecutive
+	// failure.
+	baseFailureBackoff = time.Second
+
+	// maxFailureBackoff is the maximum delay before the list or watch
+	// that follows a failure
+	maxFailureBackoff = time.Minute
+)
+
+// InformerHealth represents the state of the list & watch of an
+// informer
+type InformerHealth struct {
+	// ConsecutiveFailures is the number of list or watch requests
+	// that failed since the last healthy watch
+	ConsecutiveFailures int `json:"consecutiveFailures,omitempty"`
+
+	// LastError is the error of the latest failed request
+	LastError string `json:"lastError,omitempty"`
+
+	// FailingSince is the time of the first of the consecutive
+	// failures
+	FailingSince *time.Time `json:"failingSince,omitempty"`
+}
+
+// IsFailing returns true if the informer failed to list or watch
+// its resources repeatedly. The cache of such an informer is likely
+// to be stale.
+func (h InformerHealth) IsFailing() bool {
+	return h.ConsecutiveFailures >= failureThreshold
+}
+
+// listWatchHealth tracks the failures of the list & watch requests
+// of a single informer
+type listWatchHealth struct {
+	// key of the informer used while logging
+	key string
+
+	// closed when the informer is stopped
+	stopCh <-chan struct{}
+
+	mutex        sync.Mutex
+	failures     int
+	lastErr      error
+	lastFailure  time.Time
+	failingSince time.Time
+}
+
+// newListWatchHealth returns a new instance of listWatchHealth for the
+// informer of the given key that is stopped via the given channel
+func newListWatchHealth(key string, stopCh <-chan struct{}) *listWatchHealth {
+	return &listWatchHealth{key: key, stopCh: stopCh}
+}
+
+// recordFailure records a failed list or watch request
+func (h *listWatchHealth) recordFailure(err error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	now := time.Now()
+	if h.failures > 0 && now.Sub(h.lastFailure) > failureResetInterval {
+		// earlier failures are too old to be consecutive
+		h.failures = 0
+	}
+	if h.failures == 0 {
+		h.failingSince = now
+	}
+	h.failures++
+	h.lastErr = err
+	h.lastFailure = now
+	if h.failures == failureThreshold {
+		glog.Warningf(
+			"Shared informer %v is failing: %d consecutive list or watch failures since %v: Cache may be stale: %v",
+			h.key, h.failures, h.failingSince, err,
+		)
+		return
+	}
+	glog.V(4).Infof(
+		"Shared informer %v failed to list or watch: %d consecutive failures: %v",
+		h.key, h.failures, err,
+	)
+}
+
+// recordSuccess records a healthy watch
+func (h *listWatchHealth) recordSuccess() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.failures >= failureThreshold {
+		glog.Infof(
+			"Shared informer %v recovered after %d consecutive failures since %v",
+			h.key, h.failures, h.failingSince,
+		)
+	}
+	h.failures = 0
+	h.lastErr = nil
+}
+
+// get returns the current health
+func (h *listWatchHealth) get() InformerHealth {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.failures == 0 || time.Since(h.lastFailure) > failureResetInterval {
+		return InformerHealth{}
+	}
+	since := h.failingSince
+	return InformerHealth{
+		ConsecutiveFailures: h.failures,
+		LastError:           h.lastErr.Error(),
+		FailingSince:        &since,
+	}
+}
+
+// backoff returns the delay before the next list or watch request
+// based on the consecutive failures. Delay is 0 if there are no
+// failures.
+func (h *listWatchHealth) backoff() time.Duration {
+	failures := h.get().ConsecutiveFailures
+	if failures == 0 {
+		return 0
+	}
+	delay := float64(baseFailureBackoff) * math.Pow(2, float64(failures-1))
+	if delay > float64(maxFailureBackoff) {
+		delay = float64(maxFailureBackoff)
+	}
+	return wait.Jitter(time.Duration(delay), 1.0)
+}
+
+// wait waits for the backoff of the consecutive failures. It returns
+// false if the informer got stopped while waiting.
+func (h *listWatchHealth) wait() bool {
+	delay := h.backoff()
+	if delay == 0 {
+		return true
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-h.stopCh:
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// wrapListWatch returns the list & watch functions that back off
+// with jitter after the failures of the given list & watch functions
+//
+// NOTE:
+//	Informers otherwise retry a failed list or watch every second.
+// Error events of a watch e.g. resource version too old are failures
+// as well since these result in a relist. Any other event of a watch
+// implies the informer is healthy.
+func (h *listWatchHealth) wrapListWatch(lw cache.ListerWatcher) cache.ListerWatcher {
+	return &cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			// subsequent pages of a list are not delayed
+			if opts.Continue == "" && !h.wait() {
+				return nil, apierrors.NewServiceUnavailable("Informer is stopped")
+			}
+			obj, err := lw.List(opts)
+			if err != nil {
+				h.recordFailure(err)
+			}
+			return obj, err
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			if !h.wait() {
+				return nil, apierrors.NewServiceUnavailable("Informer is stopped")
+			}
+			w, err := lw.Watch(opts)
+			if err != nil {
+				h.recordFailure(err)
+				return w, err
+			}
+			return watch.Filter(w, func(in watch.Event) (watch.Event, bool) {
+				if in.Type == watch.Error {
+					h.recordFailure(apierrors.FromObject(in.Object))
+				} else {
+					h.recordSuccess()
+				}
+				return in, true
+			}), nil
+		},
+	}
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package informer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestListWatchHealthRecord(t *testing.T) {
+	h := newListWatchHealth("pods.v1", nil)
+	for i := 0; i < failureThreshold-1; i++ {
+		h.recordFailure(errors.New("list failed"))
+	}
+	got := h.get()
+	if got.IsFailing() || got.ConsecutiveFailures != failureThreshold-1 {
+		t.Fatalf("Expected %d failures without failing got %+v", failureThreshold-1, got)
+	}
+	h.recordFailure(errors.New("watch failed"))
+	got = h.get()
+	if !got.IsFailing() || got.LastError != "watch failed" || got.FailingSince == nil {
+		t.Fatalf("Expected failing with last error got %+v", got)
+	}
+
+	// old failures are forgotten
+	h.lastFailure = time.Now().Add(-2 * failureResetInterval)
+	if got := h.get(); got.ConsecutiveFailures != 0 {
+		t.Fatalf("Expected no failures got %+v", got)
+	}
+	h.recordFailure(errors.New("list failed"))
+	if got := h.get(); got.ConsecutiveFailures != 1 {
+		t.Fatalf("Expected 1 failure got %+v", got)
+	}
+
+	h.recordSuccess()
+	if got := h.get(); got.ConsecutiveFailures != 0 || got.LastError != "" {
+		t.Fatalf("Expected healthy got %+v", got)
+	}
+}
+
+func TestListWatchHealthBackoff(t *testing.T) {
+	h := newListWatchHealth("pods.v1", nil)
+	if got := h.backoff(); got != 0 {
+		t.Fatalf("Expected no backoff got %v", got)
+	}
+	for i := 0; i < 20; i++ {
+		h.recordFailure(errors.New("list failed"))
+	}
+	got := h.backoff()
+	// jitter adds up to the max backoff
+	if got < maxFailureBackoff || got > 2*maxFailureBackoff {
+		t.Fatalf("Expected backoff between %v & %v got %v", maxFailureBackoff, 2*maxFailureBackoff, got)
+	}
+}
+
+func TestListWatchHealthWrapListWatch(t *testing.T) {
+	stopCh := make(chan struct{})
+	h := newListWatchHealth("pods.v1", stopCh)
+	fakeWatch := watch.NewFake()
+	var listErr error
+	lw := h.wrapListWatch(&cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			return &unstructured.UnstructuredList{}, listErr
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			return fakeWatch, nil
+		},
+	})
+
+	listErr = errors.New("forbidden")
+	_, err := lw.List(metav1.ListOptions{})
+	if err == nil || h.get().ConsecutiveFailures != 1 {
+		t.Fatalf("Expected 1 list failure got %v: %+v", err, h.get())
+	}
+
+	// a stopped informer does not wait for the backoff
+	close(stopCh)
+	_, err = lw.List(metav1.ListOptions{})
+	if err == nil || h.get().ConsecutiveFailures != 1 {
+		t.Fatalf("Expected list to fail without request got %v: %+v", err, h.get())
+	}
+
+	// events of the watch are checked
+	h.stopCh = nil
+	h.recordSuccess()
+	w, err := lw.Watch(metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("Expected no error got %v", err)
+	}
+	go fakeWatch.Error(&metav1.Status{Status: metav1.StatusFailure, Message: "too old"})
+	<-w.ResultChan()
+	if got := h.get(); got.ConsecutiveFailures != 1 || got.LastError != "too old" {
+		t.Fatalf("Expected 1 watch failure got %+v", got)
+	}
+	go fakeWatch.Add(&unstructured.Unstructured{})
+	<-w.ResultChan()
+	if got := h.get(); got.ConsecutiveFailures != 0 {
+		t.Fatalf("Expected healthy watch got %+v", got)
+	}
+	w.Stop()
+}
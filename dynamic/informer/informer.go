@@ -21,10 +21,8 @@ import (
 	"sync"
 	"time"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
-	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/tools/cache"
 
@@ -56,7 +54,8 @@ type SharedIndexInformer interface {
 //
 // When you're done with a ResourceInformer, you should call Close() on it.
 // Once all ResourceInformers for a shared informer are closed, the shared
-// informer is stopped.
+// informer is stopped either immediately or after the idle TTL of its
+// factory.
 type ResourceInformer struct {
 	sharedResourceInformer *sharedResourceInformer
 	informerWrapper        *informerWrapper
@@ -86,13 +85,32 @@ func (ri *ResourceInformer) Lister() *dynamiclister.Lister {
 	return ri.sharedResourceInformer.lister
 }
 
+// Health returns the state of the list & watch of the underlying
+// shared informer
+func (ri *ResourceInformer) Health() InformerHealth {
+	if ri.sharedResourceInformer.health == nil {
+		return InformerHealth{}
+	}
+	return ri.sharedResourceInformer.health.get()
+}
+
+// SetSubscriber sets the name of the controller that uses this
+// ResourceInformer. This name is reported in the status of the
+// underlying shared informer.
+func (ri *ResourceInformer) SetSubscriber(name string) {
+	ri.sharedResourceInformer.setSubscriber(ri, name)
+}
+
 // Close marks this ResourceInformer as unused, allowing the underlying
 // shared informer to be stopped when no users are left.
 // You should call this when you no longer need the informer, so the watches
 // and relists can be stopped.
+//
+// NOTE:
+//	Closing the same ResourceInformer more than once is a no-op
 func (ri *ResourceInformer) Close() {
-	// Decrement the reference count for the sharedResourceInformer.
-	ri.sharedResourceInformer.close()
+	// Remove this subscriber from the sharedResourceInformer.
+	ri.sharedResourceInformer.close(ri)
 }
 
 // sharedResourceInformer is the actual, single informer that's shared by
@@ -107,21 +125,30 @@ type sharedResourceInformer struct {
 
 	eventHandlers *sharedEventHandler
 
-	close func()
+	// stopCh is closed to stop the informer
+	stopCh chan struct{}
+
+	// records the cache metrics of this informer
+	metrics *cacheMetrics
+
+	// tracks the list & watch failures of this informer
+	health *listWatchHealth
+
+	// these are set by the factory that owns this informer
+	close         func(*ResourceInformer)
+	setSubscriber func(*ResourceInformer, string)
 }
 
-func newSharedResourceInformer(
+// newFilteredSharedResourceInformer returns a new shared informer that
+// caches the resources of the given client that are listed & watched
+// via the given list & watch functions
+func newFilteredSharedResourceInformer(
 	client *dynamicclientset.ResourceClient,
+	lw cache.ListerWatcher,
 	defaultResyncPeriod time.Duration,
-	close func(),
 ) *sharedResourceInformer {
 	informer := cache.NewSharedIndexInformer(
-		&cache.ListWatch{
-			ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
-				return client.List(opts)
-			},
-			WatchFunc: client.Watch,
-		},
+		lw,
 		&unstructured.Unstructured{},
 		defaultResyncPeriod,
 		cache.Indexers{
@@ -129,7 +156,6 @@ func newSharedResourceInformer(
 		},
 	)
 	sri := &sharedResourceInformer{
-		close:               close,
 		informer:            informer,
 		defaultResyncPeriod: defaultResyncPeriod,
 
@@ -0,0 +1,283 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package informer
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/glog"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// operationList tags the metrics of list requests
+	operationList = "list"
+
+	// operationWatch tags the metrics of watch requests
+	operationWatch = "watch"
+
+	// scalarSize is the estimated size of a value that is not a
+	// string
+	scalarSize = 8
+)
+
+var (
+	// apiVersionKey tags the informer metrics with the api version
+	// of the cached resources
+	apiVersionKey, _ = tag.NewKey("api_version")
+
+	// resourceNameKey tags the informer metrics with the name of the
+	// cached resources
+	resourceNameKey, _ = tag.NewKey("resource")
+
+	// informerKey tags the informer metrics with the key of the
+	// shared informer. This distinguishes the informers of the same
+	// resource that differ by their namespaces or selectors.
+	informerKey, _ = tag.NewKey("informer")
+
+	// operationKey tags the informer errors with the failed request
+	operationKey, _ = tag.NewKey("operation")
+
+	// cachedObjectsMeasure tracks the number of cached resources
+	cachedObjectsMeasure = stats.Int64(
+		"metac/informer_cached_objects",
+		"Number of resources cached by the informer",
+		stats.UnitDimensionless,
+	)
+
+	// cachedBytesMeasure tracks the estimated size of the cached
+	// resources
+	cachedBytesMeasure = stats.Int64(
+		"metac/informer_cached_bytes",
+		"Estimated size of the resources cached by the informer",
+		stats.UnitBytes,
+	)
+
+	// errorsMeasure counts the failed list & watch requests
+	errorsMeasure = stats.Int64(
+		"metac/informer_errors",
+		"Number of times the informer failed to list or watch its resources",
+		stats.UnitDimensionless,
+	)
+
+	// lastSyncMeasure tracks the time of the last successful list
+	lastSyncMeasure = stats.Float64(
+		"metac/informer_last_sync_timestamp_seconds",
+		"Unix time of the last successful list of the informer's resources",
+		"s",
+	)
+
+	// Views expose all the informer metrics
+	//
+	// NOTE:
+	//	These need to be registered to be exported
+	Views = []*view.View{
+		{
+			Name:        "metac/informer_cached_objects",
+			Description: cachedObjectsMeasure.Description(),
+			Measure:     cachedObjectsMeasure,
+			TagKeys:     []tag.Key{apiVersionKey, resourceNameKey, informerKey},
+			Aggregation: view.LastValue(),
+		},
+		{
+			Name:        "metac/informer_cached_bytes",
+			Description: cachedBytesMeasure.Description(),
+			Measure:     cachedBytesMeasure,
+			TagKeys:     []tag.Key{apiVersionKey, resourceNameKey, informerKey},
+			Aggregation: view.LastValue(),
+		},
+		{
+			Name:        "metac/informer_errors_total",
+			Description: errorsMeasure.Description(),
+			Measure:     errorsMeasure,
+			TagKeys:     []tag.Key{apiVersionKey, resourceNameKey, informerKey, operationKey},
+			Aggregation: view.Count(),
+		},
+		{
+			Name:        "metac/informer_last_sync_timestamp_seconds",
+			Description: lastSyncMeasure.Description(),
+			Measure:     lastSyncMeasure,
+			TagKeys:     []tag.Key{apiVersionKey, resourceNameKey, informerKey},
+			Aggregation: view.LastValue(),
+		},
+	}
+)
+
+// cacheMetrics records the metrics of a single shared informer. It
+// tracks the cached resources as an event handler of the informer.
+type cacheMetrics struct {
+	// context tagged with this informer
+	ctx context.Context
+
+	// number & estimated size of the cached resources; these are
+	// accessed atomically
+	objects int64
+	bytes   int64
+}
+
+// newCacheMetrics returns a new instance of cacheMetrics for the
+// informer of the given key that caches the given resource
+func newCacheMetrics(apiVersion, resource, key string) *cacheMetrics {
+	ctx, err := tag.New(
+		context.Background(),
+		tag.Upsert(apiVersionKey, apiVersion),
+		tag.Upsert(resourceNameKey, resource),
+		tag.Upsert(informerKey, key),
+	)
+	if err != nil {
+		glog.Warningf("Failed to tag metrics of shared informer %v: %v", key, err)
+		ctx = context.Background()
+	}
+	return &cacheMetrics{ctx: ctx}
+}
+
+// OnAdd implements cache.ResourceEventHandler interface
+func (m *cacheMetrics) OnAdd(obj interface{}) {
+	m.recordCache(1, estimateObjectSize(obj))
+}
+
+// OnUpdate implements cache.ResourceEventHandler interface
+func (m *cacheMetrics) OnUpdate(oldObj, newObj interface{}) {
+	if oldObj == newObj {
+		// resync does not change the cache
+		return
+	}
+	m.recordCache(0, estimateObjectSize(newObj)-estimateObjectSize(oldObj))
+}
+
+// OnDelete implements cache.ResourceEventHandler interface
+func (m *cacheMetrics) OnDelete(obj interface{}) {
+	m.recordCache(-1, -estimateObjectSize(obj))
+}
+
+// recordCache changes the number & size of the cached resources by
+// the given deltas & records the result
+func (m *cacheMetrics) recordCache(objectsDelta, bytesDelta int64) {
+	stats.Record(
+		m.ctx,
+		cachedObjectsMeasure.M(atomic.AddInt64(&m.objects, objectsDelta)),
+		cachedBytesMeasure.M(atomic.AddInt64(&m.bytes, bytesDelta)),
+	)
+}
+
+// reset records an empty cache. This is invoked when the informer
+// is stopped.
+func (m *cacheMetrics) reset() {
+	atomic.StoreInt64(&m.objects, 0)
+	atomic.StoreInt64(&m.bytes, 0)
+	stats.Record(m.ctx, cachedObjectsMeasure.M(0), cachedBytesMeasure.M(0))
+}
+
+// recordError records a failed request of the given operation
+func (m *cacheMetrics) recordError(operation string) {
+	err := stats.RecordWithTags(
+		m.ctx,
+		[]tag.Mutator{tag.Upsert(operationKey, operation)},
+		errorsMeasure.M(1),
+	)
+	if err != nil {
+		glog.Warningf("Failed to record informer %s error: %v", operation, err)
+	}
+}
+
+// wrapListWatch returns the list & watch functions that record the
+// errors & syncs of the given list & watch functions
+//
+// NOTE:
+//	A list is considered as a sync once its last page is received.
+// Error events of a watch e.g. resource version too old are recorded
+// as watch errors.
+func (m *cacheMetrics) wrapListWatch(lw cache.ListerWatcher) cache.ListerWatcher {
+	return &cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			obj, err := lw.List(opts)
+			if err != nil {
+				m.recordError(operationList)
+				return obj, err
+			}
+			if list, err := meta.ListAccessor(obj); err == nil && list.GetContinue() == "" {
+				stats.Record(m.ctx, lastSyncMeasure.M(float64(time.Now().Unix())))
+			}
+			return obj, nil
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			w, err := lw.Watch(opts)
+			if err != nil {
+				m.recordError(operationWatch)
+				return w, err
+			}
+			return watch.Filter(w, func(in watch.Event) (watch.Event, bool) {
+				if in.Type == watch.Error {
+					m.recordError(operationWatch)
+				}
+				return in, true
+			}), nil
+		},
+	}
+}
+
+// estimateObjectSize returns the estimated size of the given cached
+// object in bytes
+func estimateObjectSize(obj interface{}) int64 {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok || u == nil {
+		return 0
+	}
+	return estimateSize(u.Object)
+}
+
+// estimateSize returns the estimated size of the given value in
+// bytes
+//
+// NOTE:
+//	This is the sum of the lengths of all the keys & strings of the
+// value. Every other value including the maps & lists themselves is
+// considered to be of fixed size. This is far cheaper than encoding
+// the value & is good enough to plan the memory of the caches.
+func estimateSize(value interface{}) int64 {
+	switch val := value.(type) {
+	case map[string]interface{}:
+		size := int64(scalarSize)
+		for key, field := range val {
+			size += int64(len(key)) + estimateSize(field)
+		}
+		return size
+	case []interface{}:
+		size := int64(scalarSize)
+		for _, item := range val {
+			size += estimateSize(item)
+		}
+		return size
+	case string:
+		return int64(len(val))
+	default:
+		return scalarSize
+	}
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package informer
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestEstimateSize(t *testing.T) {
+	var tests = map[string]struct {
+		value interface{}
+		want  int64
+	}{
+		"string": {
+			value: "abc",
+			want:  3,
+		},
+		"scalar": {
+			value: int64(10),
+			want:  scalarSize,
+		},
+		"map": {
+			value: map[string]interface{}{"ab": "cd", "e": true},
+			want:  scalarSize + 2 + 2 + 1 + scalarSize,
+		},
+		"list": {
+			value: []interface{}{"ab", map[string]interface{}{}},
+			want:  scalarSize + 2 + scalarSize,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			got := estimateSize(mock.value)
+			if got != mock.want {
+				t.Fatalf("Expected size %d got %d", mock.want, got)
+			}
+		})
+	}
+}
+
+func TestCacheMetricsEventHandler(t *testing.T) {
+	m := newCacheMetrics("v1", "pods", "pods.v1")
+	small := &unstructured.Unstructured{
+		Object: map[string]interface{}{"kind": "Pod"},
+	}
+	large := &unstructured.Unstructured{
+		Object: map[string]interface{}{"kind": "Pod", "spec": "large"},
+	}
+	m.OnAdd(small)
+	m.OnAdd(small)
+	m.OnUpdate(small, large)
+	// resync does not change the size
+	m.OnUpdate(large, large)
+	if m.objects != 2 {
+		t.Fatalf("Expected 2 objects got %d", m.objects)
+	}
+	want := estimateSize(small.Object) + estimateSize(large.Object)
+	if m.bytes != want {
+		t.Fatalf("Expected %d bytes got %d", want, m.bytes)
+	}
+	m.OnDelete(cache.DeletedFinalStateUnknown{Key: "pod", Obj: large})
+	if m.objects != 1 || m.bytes != estimateSize(small.Object) {
+		t.Fatalf(
+			"Expected 1 object of %d bytes got %d objects of %d bytes",
+			estimateSize(small.Object), m.objects, m.bytes,
+		)
+	}
+	m.reset()
+	if m.objects != 0 || m.bytes != 0 {
+		t.Fatalf("Expected empty cache got %d objects of %d bytes", m.objects, m.bytes)
+	}
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package informer
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+)
+
+// newPagedListWatch returns the list & watch functions that list the
+// resources in pages of the given size via the given list & watch
+// functions. The given functions are returned as is if page size is
+// not positive.
+//
+// NOTE:
+//	Informers already list in pages. However the API server ignores
+// the page size of a list request that can be served from its watch
+// cache i.e. the initial list with resource version "0". Hence such a
+// list is sent without a resource version. This results in a
+// consistent read from etcd that is returned in pages.
+func newPagedListWatch(lw cache.ListerWatcher, pageSize int64) cache.ListerWatcher {
+	if pageSize <= 0 {
+		return lw
+	}
+	return &cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			opts.Limit = pageSize
+			if opts.Continue == "" && opts.ResourceVersion == "0" {
+				opts.ResourceVersion = ""
+			}
+			return lw.List(opts)
+		},
+		WatchFunc: lw.Watch,
+	}
+}
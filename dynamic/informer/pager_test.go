@@ -0,0 +1,86 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package informer
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestNewPagedListWatch(t *testing.T) {
+	var tests = map[string]struct {
+		pageSize        int64
+		opts            metav1.ListOptions
+		limit           int64
+		resourceVersion string
+	}{
+		"paging disabled": {
+			opts:            metav1.ListOptions{ResourceVersion: "0", Limit: 500},
+			limit:           500,
+			resourceVersion: "0",
+		},
+		"initial list from watch cache": {
+			pageSize:        100,
+			opts:            metav1.ListOptions{ResourceVersion: "0", Limit: 500},
+			limit:           100,
+			resourceVersion: "",
+		},
+		"relist at resource version": {
+			pageSize:        100,
+			opts:            metav1.ListOptions{ResourceVersion: "10", Limit: 500},
+			limit:           100,
+			resourceVersion: "10",
+		},
+		"continued list": {
+			pageSize: 100,
+			opts:     metav1.ListOptions{Continue: "token", Limit: 500},
+			limit:    100,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			var got metav1.ListOptions
+			lw := newPagedListWatch(&cache.ListWatch{
+				ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+					got = opts
+					return &unstructured.UnstructuredList{}, nil
+				},
+			}, mock.pageSize)
+			_, err := lw.List(mock.opts)
+			if err != nil {
+				t.Fatalf("Expected no error got %v", err)
+			}
+			if got.Limit != mock.limit {
+				t.Fatalf("Expected limit %d got %d", mock.limit, got.Limit)
+			}
+			if got.ResourceVersion != mock.resourceVersion {
+				t.Fatalf(
+					"Expected resource version %q got %q", mock.resourceVersion, got.ResourceVersion,
+				)
+			}
+			if got.Continue != mock.opts.Continue {
+				t.Fatalf("Expected continue %q got %q", mock.opts.Continue, got.Continue)
+			}
+		})
+	}
+}
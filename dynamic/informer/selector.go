@@ -0,0 +1,80 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package informer
+
+import (
+	"net/url"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ListSelector restricts the resources that are cached by an
+// informer to the ones that match its label & field selectors. These
+// selectors are sent to the API server as part of the list & watch
+// requests.
+//
+// NOTE:
+//	Informers are shared per selector. In other words, an informer
+// with a selector is not shared with an informer without one or with
+// an informer with some other selector.
+type ListSelector struct {
+	// LabelSelector in its string form e.g. "app=nginx,tier!=db"
+	LabelSelector string
+
+	// FieldSelector in its string form e.g. "metadata.name=my-cm"
+	FieldSelector string
+}
+
+// IsEmpty returns true if this selector does not restrict any
+// resource
+func (s ListSelector) IsEmpty() bool {
+	return s.LabelSelector == "" && s.FieldSelector == ""
+}
+
+// String implements Stringer interface
+func (s ListSelector) String() string {
+	if s.IsEmpty() {
+		return ""
+	}
+	values := url.Values{}
+	if s.LabelSelector != "" {
+		values.Set("labelSelector", s.LabelSelector)
+	}
+	if s.FieldSelector != "" {
+		values.Set("fieldSelector", s.FieldSelector)
+	}
+	return values.Encode()
+}
+
+// tweak sets the selectors against the given list options. These
+// are ANDed with the selectors that are already set if any.
+func (s ListSelector) tweak(opts *metav1.ListOptions) {
+	opts.LabelSelector = joinSelectors(opts.LabelSelector, s.LabelSelector)
+	opts.FieldSelector = joinSelectors(opts.FieldSelector, s.FieldSelector)
+}
+
+// joinSelectors ANDs the given selectors that are in their string
+// forms
+func joinSelectors(one, other string) string {
+	if one == "" {
+		return other
+	}
+	if other == "" {
+		return one
+	}
+	return one + "," + other
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package informer
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestListSelectorTweak(t *testing.T) {
+	var tests = map[string]struct {
+		selector      ListSelector
+		opts          metav1.ListOptions
+		labelSelector string
+		fieldSelector string
+	}{
+		"empty selector": {
+			opts: metav1.ListOptions{
+				FieldSelector: "metadata.namespace!=ns1",
+			},
+			fieldSelector: "metadata.namespace!=ns1",
+		},
+		"selector without existing options": {
+			selector: ListSelector{
+				LabelSelector: "app=nginx",
+				FieldSelector: "metadata.name=cm1",
+			},
+			labelSelector: "app=nginx",
+			fieldSelector: "metadata.name=cm1",
+		},
+		"selector with existing options": {
+			selector: ListSelector{
+				LabelSelector: "app=nginx",
+				FieldSelector: "metadata.name=cm1",
+			},
+			opts: metav1.ListOptions{
+				LabelSelector: "tier=web",
+				FieldSelector: "metadata.namespace!=ns1",
+			},
+			labelSelector: "tier=web,app=nginx",
+			fieldSelector: "metadata.namespace!=ns1,metadata.name=cm1",
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			opts := mock.opts
+			mock.selector.tweak(&opts)
+			if opts.LabelSelector != mock.labelSelector {
+				t.Fatalf(
+					"Expected label selector %q got %q", mock.labelSelector, opts.LabelSelector,
+				)
+			}
+			if opts.FieldSelector != mock.fieldSelector {
+				t.Fatalf(
+					"Expected field selector %q got %q", mock.fieldSelector, opts.FieldSelector,
+				)
+			}
+		})
+	}
+}
+
+func TestResourceKeyWithSelector(t *testing.T) {
+	var tests = map[string]struct {
+		namespace string
+		selector  ListSelector
+		expect    string
+	}{
+		"no namespace & no selector": {
+			expect: "secrets.v1",
+		},
+		"namespace & no selector": {
+			namespace: "ns1",
+			expect:    "secrets.v1/ns1",
+		},
+		"namespace & selector": {
+			namespace: "ns1",
+			selector: ListSelector{
+				LabelSelector: "app=nginx",
+				FieldSelector: "metadata.name=s1",
+			},
+			expect: "secrets.v1/ns1?fieldSelector=metadata.name%3Ds1&labelSelector=app%3Dnginx",
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			got := resourceKeyWithSelector("v1", "secrets", mock.namespace, mock.selector)
+			if got != mock.expect {
+				t.Fatalf("Expected key %q got %q", mock.expect, got)
+			}
+		})
+	}
+}
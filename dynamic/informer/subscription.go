@@ -0,0 +1,217 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package informer
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+// idleInformer is a shared informer without subscribers that gets
+// stopped once its idle TTL expires
+type idleInformer struct {
+	since time.Time
+	timer *time.Timer
+}
+
+// SharedInformerStatus represents the current state of a shared
+// informer
+type SharedInformerStatus struct {
+	// Key identifies the resources cached by this informer
+	Key string `json:"key"`
+
+	// Subscribers are the names of the controllers using this
+	// informer. Subscribers without names are not listed.
+	Subscribers []string `json:"subscribers,omitempty"`
+
+	// SubscriberCount is the number of subscriptions to this
+	// informer including the ones without names
+	SubscriberCount int `json:"subscriberCount"`
+
+	// IdleSince is set if this informer has no subscribers & is
+	// yet to be stopped
+	IdleSince *time.Time `json:"idleSince,omitempty"`
+
+	// Health is set if this informer failed to list or watch its
+	// resources recently
+	Health *InformerHealth `json:"health,omitempty"`
+}
+
+// register adds the given shared informer against the given key
+//
+// NOTE:
+//	This is expected to be invoked with the factory mutex held
+func (f *SharedInformerFactory) register(key string, sri *sharedResourceInformer) {
+	// close & setSubscriber are called by users of the shared informer.
+	// We do all the subscription changes in the factory while holding
+	// the factory mutex, so that removing shared informers is serialized
+	// along with creating them.
+	sri.close = func(ri *ResourceInformer) {
+		f.unsubscribe(key, ri)
+	}
+	sri.setSubscriber = func(ri *ResourceInformer, name string) {
+		f.mutex.Lock()
+		defer f.mutex.Unlock()
+
+		if _, found := f.subscribers[key][ri]; found {
+			f.subscribers[key][ri] = name
+		}
+	}
+	f.sharedInformers[key] = sri
+	f.subscribers[key] = make(map[*ResourceInformer]string)
+}
+
+// subscribe returns a new subscription to the shared informer of the
+// given key. An idle informer of this key is no longer stopped.
+//
+// NOTE:
+//	This is expected to be invoked with the factory mutex held
+func (f *SharedInformerFactory) subscribe(key string) *ResourceInformer {
+	if idle, found := f.idleInformers[key]; found {
+		idle.timer.Stop()
+		delete(f.idleInformers, key)
+		glog.V(4).Infof(
+			"Reusing idle shared informer %v (idle since %v)", key, idle.since,
+		)
+	}
+	ri := newResourceInformer(f.sharedInformers[key])
+	f.subscribers[key][ri] = ""
+	glog.V(4).Infof(
+		"Subscribed to shared informer %v (total subscribers now %v)",
+		key,
+		len(f.subscribers[key]),
+	)
+	return ri
+}
+
+// unsubscribe removes the given subscription of the shared informer
+// of the given key. The informer is stopped either immediately or
+// after the idle TTL if this was its last subscription.
+func (f *SharedInformerFactory) unsubscribe(key string, ri *ResourceInformer) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if _, found := f.subscribers[key][ri]; !found {
+		// already closed
+		return
+	}
+	delete(f.subscribers[key], ri)
+	count := len(f.subscribers[key])
+	glog.V(4).Infof(
+		"Unsubscribed from shared informer %v (total subscribers now %v)",
+		key,
+		count,
+	)
+	if count > 0 {
+		// Others are still using it.
+		return
+	}
+
+	// We're the last ones using it.
+	if f.idleTTL <= 0 {
+		glog.V(4).Infof("Stopping shared informer %v (no more subscribers)", key)
+		f.stop(key)
+		return
+	}
+	glog.V(4).Infof(
+		"Shared informer %v has no more subscribers: Will stop after %v",
+		key,
+		f.idleTTL,
+	)
+	idle := &idleInformer{since: time.Now()}
+	idle.timer = time.AfterFunc(f.idleTTL, func() {
+		f.mutex.Lock()
+		defer f.mutex.Unlock()
+
+		if f.idleInformers[key] != idle {
+			// subscribed again or stopped already
+			return
+		}
+		glog.V(4).Infof(
+			"Stopping shared informer %v (no subscribers since %v)", key, idle.since,
+		)
+		f.stop(key)
+	})
+	f.idleInformers[key] = idle
+}
+
+// stop stops the shared informer of the given key
+//
+// NOTE:
+//	This is expected to be invoked with the factory mutex held
+func (f *SharedInformerFactory) stop(key string) {
+	sri := f.sharedInformers[key]
+	close(sri.stopCh)
+	if sri.metrics != nil {
+		sri.metrics.reset()
+	}
+	delete(f.sharedInformers, key)
+	delete(f.subscribers, key)
+	delete(f.idleInformers, key)
+}
+
+// Status returns the current state of the shared informers of this
+// factory sorted by their keys
+func (f *SharedInformerFactory) Status() []SharedInformerStatus {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	statuses := make([]SharedInformerStatus, 0, len(f.sharedInformers))
+	for key := range f.sharedInformers {
+		status := SharedInformerStatus{
+			Key:             key,
+			SubscriberCount: len(f.subscribers[key]),
+		}
+		for _, name := range f.subscribers[key] {
+			if name != "" {
+				status.Subscribers = append(status.Subscribers, name)
+			}
+		}
+		sort.Strings(status.Subscribers)
+		if idle, found := f.idleInformers[key]; found {
+			since := idle.since
+			status.IdleSince = &since
+		}
+		if sri := f.sharedInformers[key]; sri.health != nil {
+			if health := sri.health.get(); health.ConsecutiveFailures > 0 {
+				status.Health = &health
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Key < statuses[j].Key
+	})
+	return statuses
+}
+
+// ServeHTTP implements http.Handler interface. This responds with the
+// status of the shared informers as json.
+func (f *SharedInformerFactory) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	data, err := json.Marshal(f.Status())
+	if err != nil {
+		glog.Errorf("Can't marshal shared informer status: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package informer
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newTestSubscription registers a shared informer against the given
+// key of the given factory & returns the given number of subscriptions
+// to it
+func newTestSubscription(
+	f *SharedInformerFactory, key string, count int,
+) (*sharedResourceInformer, []*ResourceInformer) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	sri := &sharedResourceInformer{stopCh: make(chan struct{})}
+	f.register(key, sri)
+	var subscriptions []*ResourceInformer
+	for i := 0; i < count; i++ {
+		subscriptions = append(subscriptions, f.subscribe(key))
+	}
+	return sri, subscriptions
+}
+
+func isStopped(sri *sharedResourceInformer) bool {
+	select {
+	case <-sri.stopCh:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestSharedInformerFactoryCloseWithoutIdleTTL(t *testing.T) {
+	f := NewSharedInformerFactoryWithOptions(nil, 0)
+	sri, subscriptions := newTestSubscription(f, "pods.v1", 2)
+
+	subscriptions[0].Close()
+	// closing again must not remove the other subscription
+	subscriptions[0].Close()
+	if isStopped(sri) {
+		t.Fatalf("Expected informer to run with 1 subscriber")
+	}
+	status := f.Status()
+	if len(status) != 1 || status[0].SubscriberCount != 1 {
+		t.Fatalf("Expected 1 informer with 1 subscriber got %+v", status)
+	}
+
+	subscriptions[1].Close()
+	if !isStopped(sri) {
+		t.Fatalf("Expected informer to stop without subscribers")
+	}
+	if status := f.Status(); len(status) != 0 {
+		t.Fatalf("Expected no informers got %+v", status)
+	}
+}
+
+func TestSharedInformerFactoryCloseWithIdleTTL(t *testing.T) {
+	f := NewSharedInformerFactoryWithOptions(nil, 0, WithIdleTTL(50*time.Millisecond))
+	sri, subscriptions := newTestSubscription(f, "pods.v1", 1)
+
+	subscriptions[0].Close()
+	if isStopped(sri) {
+		t.Fatalf("Expected idle informer to run till its TTL")
+	}
+	status := f.Status()
+	if len(status) != 1 || status[0].SubscriberCount != 0 || status[0].IdleSince == nil {
+		t.Fatalf("Expected 1 idle informer got %+v", status)
+	}
+
+	// subscribing again reuses the idle informer
+	f.mutex.Lock()
+	ri := f.subscribe("pods.v1")
+	f.mutex.Unlock()
+	time.Sleep(100 * time.Millisecond)
+	if isStopped(sri) {
+		t.Fatalf("Expected reused informer to run")
+	}
+	status = f.Status()
+	if len(status) != 1 || status[0].SubscriberCount != 1 || status[0].IdleSince != nil {
+		t.Fatalf("Expected 1 informer with 1 subscriber got %+v", status)
+	}
+
+	ri.Close()
+	select {
+	case <-sri.stopCh:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Expected idle informer to stop after its TTL")
+	}
+	if status := f.Status(); len(status) != 0 {
+		t.Fatalf("Expected no informers got %+v", status)
+	}
+}
+
+func TestSharedInformerFactoryStatusSubscribers(t *testing.T) {
+	f := NewSharedInformerFactoryWithOptions(nil, 0)
+	_, pods := newTestSubscription(f, "pods.v1", 3)
+	_, secrets := newTestSubscription(f, "secrets.v1", 1)
+
+	pods[0].SetSubscriber("WatchGCtl ns/b")
+	pods[1].SetSubscriber("WatchGCtl ns/a")
+	secrets[0].SetSubscriber("WatchGCtl ns/a")
+	// a closed subscription is no longer reported
+	secrets[0].Close()
+	secrets[0].SetSubscriber("WatchGCtl ns/c")
+
+	status := f.Status()
+	want := []SharedInformerStatus{
+		{
+			Key:             "pods.v1",
+			Subscribers:     []string{"WatchGCtl ns/a", "WatchGCtl ns/b"},
+			SubscriberCount: 3,
+		},
+	}
+	if !reflect.DeepEqual(status, want) {
+		t.Fatalf("Expected status %+v got %+v", want, status)
+	}
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package informer
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+// lastAppliedConfigAnnotationKey is the annotation set by kubectl
+// apply that holds the entire last applied configuration
+const lastAppliedConfigAnnotationKey = "kubectl.kubernetes.io/last-applied-configuration"
+
+// CacheTransform removes the fields of the resources that are not
+// needed by metac before these resources get cached by the informers.
+// This reduces the memory used by the informer caches.
+//
+// NOTE:
+//	Resources are transformed when these are listed or watched. Hence
+// hooks receive the transformed resources as well.
+type CacheTransform struct {
+	// StripManagedFields removes metadata.managedFields
+	StripManagedFields bool
+
+	// StripLastAppliedConfig removes the last applied configuration
+	// annotation that is set by kubectl apply
+	StripLastAppliedConfig bool
+
+	// StripPaths are the paths of the fields that get removed e.g.
+	// [["metadata", "annotations", "example.com/bulky"]]
+	StripPaths [][]string
+}
+
+// IsEmpty returns true if this transform does not remove any field
+func (t CacheTransform) IsEmpty() bool {
+	return !t.StripManagedFields && !t.StripLastAppliedConfig && len(t.StripPaths) == 0
+}
+
+// transform removes the configured fields from the given object
+func (t CacheTransform) transform(obj *unstructured.Unstructured) {
+	if t.StripManagedFields {
+		unstructured.RemoveNestedField(obj.Object, "metadata", "managedFields")
+	}
+	if t.StripLastAppliedConfig {
+		unstructured.RemoveNestedField(
+			obj.Object, "metadata", "annotations", lastAppliedConfigAnnotationKey,
+		)
+	}
+	for _, path := range t.StripPaths {
+		unstructured.RemoveNestedField(obj.Object, path...)
+	}
+	// empty annotations are removed to match the API server
+	if ann, found, _ := unstructured.NestedMap(
+		obj.Object, "metadata", "annotations",
+	); found && len(ann) == 0 {
+		unstructured.RemoveNestedField(obj.Object, "metadata", "annotations")
+	}
+}
+
+// wrapListWatch returns the list & watch functions that transform the
+// resources returned by the given list & watch functions
+func (t CacheTransform) wrapListWatch(lw cache.ListerWatcher) cache.ListerWatcher {
+	if t.IsEmpty() {
+		return lw
+	}
+	return &cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			obj, err := lw.List(opts)
+			if err != nil {
+				return obj, err
+			}
+			if list, ok := obj.(*unstructured.UnstructuredList); ok {
+				for idx := range list.Items {
+					t.transform(&list.Items[idx])
+				}
+			}
+			return obj, nil
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			w, err := lw.Watch(opts)
+			if err != nil {
+				return w, err
+			}
+			return watch.Filter(w, func(in watch.Event) (watch.Event, bool) {
+				if in.Type == watch.Bookmark {
+					// bookmarks carry only the resource version
+					return in, true
+				}
+				if obj, ok := in.Object.(*unstructured.Unstructured); ok {
+					t.transform(obj)
+				}
+				return in, true
+			}), nil
+		},
+	}
+}
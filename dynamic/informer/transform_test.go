@@ -0,0 +1,138 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package informer
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+func newTransformTestObj() *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name": "cm1",
+				"annotations": map[string]interface{}{
+					lastAppliedConfigAnnotationKey: "{}",
+				},
+				"managedFields": []interface{}{
+					map[string]interface{}{"manager": "kubectl"},
+				},
+			},
+			"data": map[string]interface{}{
+				"key":   "value",
+				"bulky": "value",
+			},
+		},
+	}
+}
+
+func TestCacheTransformTransform(t *testing.T) {
+	var tests = map[string]struct {
+		transform CacheTransform
+		expect    map[string]interface{}
+	}{
+		"empty transform": {
+			expect: newTransformTestObj().Object,
+		},
+		"strip all": {
+			transform: CacheTransform{
+				StripManagedFields:     true,
+				StripLastAppliedConfig: true,
+				StripPaths:             [][]string{{"data", "bulky"}},
+			},
+			expect: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"name": "cm1",
+				},
+				"data": map[string]interface{}{
+					"key": "value",
+				},
+			},
+		},
+		"strip managed fields only": {
+			transform: CacheTransform{
+				StripManagedFields: true,
+			},
+			expect: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"name": "cm1",
+					"annotations": map[string]interface{}{
+						lastAppliedConfigAnnotationKey: "{}",
+					},
+				},
+				"data": map[string]interface{}{
+					"key":   "value",
+					"bulky": "value",
+				},
+			},
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			obj := newTransformTestObj()
+			mock.transform.transform(obj)
+			if !reflect.DeepEqual(obj.Object, mock.expect) {
+				t.Fatalf("Expected %v got %v", mock.expect, obj.Object)
+			}
+		})
+	}
+}
+
+func TestCacheTransformWrapListWatch(t *testing.T) {
+	fakeWatch := watch.NewFake()
+	lw := CacheTransform{StripManagedFields: true}.wrapListWatch(&cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			return &unstructured.UnstructuredList{
+				Items: []unstructured.Unstructured{*newTransformTestObj()},
+			}, nil
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			return fakeWatch, nil
+		},
+	})
+
+	listed, err := lw.List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("Expected no list error got %v", err)
+	}
+	for _, item := range listed.(*unstructured.UnstructuredList).Items {
+		if item.GetManagedFields() != nil {
+			t.Fatalf("Expected no managed fields in listed item got %v", item.GetManagedFields())
+		}
+	}
+
+	w, err := lw.Watch(metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("Expected no watch error got %v", err)
+	}
+	defer w.Stop()
+	go fakeWatch.Add(newTransformTestObj())
+	event := <-w.ResultChan()
+	obj := event.Object.(*unstructured.Unstructured)
+	if obj.GetManagedFields() != nil {
+		t.Fatalf("Expected no managed fields in watched item got %v", obj.GetManagedFields())
+	}
+}
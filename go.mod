@@ -5,6 +5,8 @@ go 1.13.5
 require (
 	contrib.go.opencensus.io/exporter/prometheus v0.1.0
 	github.com/coreos/etcd v3.3.15+incompatible // indirect
+	github.com/evanphx/json-patch v4.2.0+incompatible
+	github.com/fsnotify/fsnotify v1.4.7
 	github.com/ghodss/yaml v1.0.0
 	github.com/golang/glog v0.0.0-20160126235308-23def4e6c14b
 	github.com/google/go-cmp v0.3.0
@@ -12,6 +14,7 @@ require (
 	github.com/pkg/errors v0.8.1
 	go.opencensus.io v0.21.0
 	gopkg.in/yaml.v2 v2.2.4
+	gopkg.in/yaml.v3 v3.0.1
 	k8s.io/api v0.17.0
 	k8s.io/apiextensions-apiserver v0.17.0
 	k8s.io/apimachinery v0.17.0
@@ -19,17 +19,114 @@ package webhook
 
 import (
 	"bytes"
+	"crypto/tls"
 	gojson "encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/golang/glog"
 	"github.com/pkg/errors"
+	"go.opencensus.io/plugin/ochttp/propagation/tracecontext"
+	"go.opencensus.io/trace"
 	"k8s.io/apimachinery/pkg/util/json"
 )
 
+// StatusError is returned when webhook responds with a status
+// other than OK
+type StatusError struct {
+	// describes the webhook invoker
+	Invoker string
+
+	// status code of the webhook response
+	StatusCode int
+
+	// body of the webhook response
+	Body []byte
+}
+
+// Error implements error interface
+func (e *StatusError) Error() string {
+	return fmt.Sprintf(
+		"%s: Response status is not OK: Got %d: Response %q", e.Invoker, e.StatusCode, e.Body,
+	)
+}
+
+// IsPermanent returns true if this error is due to a client error
+// i.e. 4xx status. Such errors are expected to recur for the same
+// request & hence need not be retried.
+//
+// NOTE:
+//	Request timeout i.e. 408 & too many requests i.e. 429 are
+// considered as transient errors
+func (e *StatusError) IsPermanent() bool {
+	switch e.StatusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return false
+	}
+	return e.StatusCode >= 400 && e.StatusCode < 500
+}
+
+// IsPermanentError returns true if the given error is due to a
+// webhook response that should not be retried for the same request.
+// Other errors e.g. 5xx responses or timeouts are transient.
+func IsPermanentError(err error) bool {
+	statusErr, ok := errors.Cause(err).(*StatusError)
+	return ok && statusErr.IsPermanent()
+}
+
+// IsTimeoutError returns true if the given error is due to a webhook
+// that did not respond within its timeout
+func IsTimeoutError(err error) bool {
+	timeoutErr, ok := errors.Cause(err).(interface{ Timeout() bool })
+	return ok && timeoutErr.Timeout()
+}
+
+// RequestIDHeader is the header that is set against the requests of
+// an invocation with its request ID
+const RequestIDHeader = "X-Request-Id"
+
+// retryInterval is the time to wait before the first retry of a
+// failed invocation. This is doubled for every subsequent retry.
+var retryInterval = 1 * time.Second
+
+// tlsTransports caches the transports of the webhooks that set their
+// own TLS config. These are keyed by their TLS settings.
+var tlsTransports = struct {
+	sync.Mutex
+	items map[string]*http.Transport
+}{items: make(map[string]*http.Transport)}
+
+// GetTLSTransport returns the transport cached against the given key.
+// A new transport is built from the TLS config returned by the given
+// function & is cached if there is none.
+//
+// NOTE:
+//	Transports are shared by all the invocations of webhooks with the
+// same TLS settings. This lets these invocations reuse their keep alive
+// connections. Idle connections are closed as per the default
+// transport.
+func GetTLSTransport(
+	key string, newTLSConfig func() (*tls.Config, error),
+) (*http.Transport, error) {
+	tlsTransports.Lock()
+	defer tlsTransports.Unlock()
+
+	if transport, ok := tlsTransports.items[key]; ok {
+		return transport, nil
+	}
+	config, err := newTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = config
+	tlsTransports.items[key] = transport
+	return transport, nil
+}
+
 // Invoker manages invocation of webhook
 type Invoker struct {
 	// webhook URL
@@ -37,6 +134,29 @@ type Invoker struct {
 
 	// webhook invocation timeout
 	Timeout time.Duration
+
+	// number of times an invocation that fails with a transient
+	// error is retried
+	Retries int
+
+	// transport used to invoke https webhooks with their own TLS
+	// config; the default transport is used if this is nil
+	//
+	// NOTE:
+	//	This is expected to be shared across invocations of the same
+	// webhook. Use GetTLSTransport to get one.
+	Transport http.RoundTripper
+
+	// headers set against every request
+	Headers map[string]string
+
+	// trace span of this invocation if any; its context is sent to
+	// the webhook as the traceparent & tracestate headers
+	Span *trace.Span
+
+	// identifies the reconcile that results in this invocation if
+	// any; this is sent to the webhook as the X-Request-Id header
+	RequestID string
 }
 
 // InvokerOption is a typed function that is used
@@ -59,9 +179,44 @@ func NewInvoker(opts ...InvokerOption) (*Invoker, error) {
 	return i, nil
 }
 
+// SetSpan sets the trace span of the invocation against the Invoker
+// instance. The webhook can continue this trace from the traceparent
+// header of the request.
+func SetSpan(span *trace.Span) InvokerOption {
+	return func(i *Invoker) error {
+		i.Span = span
+		return nil
+	}
+}
+
+// SetRequestID sets the request ID of the invocation against the
+// Invoker instance. The webhook can correlate its own logs with the
+// ones of metac via the X-Request-Id header of the request.
+func SetRequestID(id string) InvokerOption {
+	return func(i *Invoker) error {
+		i.RequestID = id
+		return nil
+	}
+}
+
 // String implements Stringer interface
 func (i *Invoker) String() string {
-	return fmt.Sprintf("Webhook Invoker: URL=%s: Timeout=%s", i.URL, i.Timeout)
+	return fmt.Sprintf(
+		"Webhook Invoker: URL=%s: Timeout=%s: Retries=%d", i.URL, i.Timeout, i.Retries,
+	)
+}
+
+// logName returns the description of this invoker that is set
+// against its log entries
+//
+// NOTE:
+//	Request ID is not part of String since errors of this invoker are
+// expected to be reported against the reconcile that has this ID
+func (i *Invoker) logName() string {
+	if i.RequestID == "" {
+		return i.String()
+	}
+	return i.String() + ": RequestID=" + i.RequestID
 }
 
 // Invoke this webhook by passing the given request
@@ -74,12 +229,47 @@ func (i *Invoker) Invoke(request, response interface{}) error {
 	}
 	if glog.V(6) {
 		reqBodyIndent, _ := gojson.MarshalIndent(request, "", "  ")
-		glog.Infof("%s: Will invoke %q", i, reqBodyIndent)
+		glog.Infof("%s: Will invoke %q", i.logName(), reqBodyIndent)
 	}
 
+	client := &http.Client{Timeout: i.Timeout, Transport: i.Transport}
+
+	wait := retryInterval
+	for retry := 1; ; retry++ {
+		err = i.invoke(client, reqBody, response)
+		if err == nil || retry > i.Retries || IsPermanentError(err) {
+			return err
+		}
+		glog.V(3).Infof(
+			"%s: Will retry %d/%d after %s: %v", i.logName(), retry, i.Retries, wait, err,
+		)
+		if i.Span != nil {
+			i.Span.Annotatef(nil, "Will retry %d/%d after %s: %v", retry, i.Retries, wait, err)
+		}
+		time.Sleep(wait)
+		wait *= 2
+	}
+}
+
+// invoke sends the given request body to this webhook & fills up
+// the given response with the webhook response
+func (i *Invoker) invoke(client *http.Client, reqBody []byte, response interface{}) error {
 	// Send request.
-	client := &http.Client{Timeout: i.Timeout}
-	resp, err := client.Post(i.URL, "application/json", bytes.NewReader(reqBody))
+	req, err := http.NewRequest(http.MethodPost, i.URL, bytes.NewReader(reqBody))
+	if err != nil {
+		return errors.Wrapf(err, "%s: Failed to build request", i)
+	}
+	for name, value := range i.Headers {
+		req.Header.Set(name, value)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if i.RequestID != "" {
+		req.Header.Set(RequestIDHeader, i.RequestID)
+	}
+	if i.Span != nil {
+		(&tracecontext.HTTPFormat{}).SpanContextToRequest(i.Span.SpanContext(), req)
+	}
+	resp, err := client.Do(req)
 	if err != nil {
 		return errors.Wrapf(err, "%s: Failed to invoke", i)
 	}
@@ -90,13 +280,15 @@ func (i *Invoker) Invoke(request, response interface{}) error {
 	if err != nil {
 		return errors.Wrapf(err, "%s: Failed to read response", i)
 	}
-	glog.V(6).Infof("%s: Got response %q", i, respBody)
+	glog.V(6).Infof("%s: Got response %q", i.logName(), respBody)
 
 	// Check status code.
 	if resp.StatusCode != http.StatusOK {
-		return errors.Errorf(
-			"%s: Response status is not OK: Got %d: Response %q", i, resp.StatusCode, respBody,
-		)
+		return &StatusError{
+			Invoker:    i.String(),
+			StatusCode: resp.StatusCode,
+			Body:       respBody,
+		}
 	}
 
 	// Decode response.
@@ -104,6 +296,6 @@ func (i *Invoker) Invoke(request, response interface{}) error {
 		return errors.Wrapf(err, "%s: Failed to unmarshal response", i)
 	}
 
-	glog.V(6).Infof("%s: Invoked successfully", i)
+	glog.V(6).Infof("%s: Invoked successfully", i.logName())
 	return nil
 }
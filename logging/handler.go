@@ -0,0 +1,66 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logging
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/golang/glog"
+)
+
+// VerbosityHandler returns the http handler that responds with the
+// log levels of the controllers on GET & sets the log level of a
+// controller on PUT or POST e.g. ?controller=ns/name&level=4. A level
+// of "default" reverts the controller to glog's verbosity.
+func VerbosityHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			name := r.URL.Query().Get("controller")
+			if name == "" {
+				http.Error(w, "Missing controller parameter", http.StatusBadRequest)
+				return
+			}
+			level, isSet, err := ParseLevel(r.URL.Query().Get("level"))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			v := GetVerbosity(name)
+			if isSet {
+				v.Set(level)
+				glog.Infof("Set log level of %s to %d", name, level)
+			} else {
+				v.Unset()
+				glog.Infof("Reverted log level of %s to default", name)
+			}
+		default:
+			http.Error(w, "Only GET, PUT & POST are supported", http.StatusMethodNotAllowed)
+			return
+		}
+		data, err := json.Marshal(ListVerbosities())
+		if err != nil {
+			glog.Errorf("Can't marshal log levels: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	})
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package logging provides leveled & structured logging on top of
+// glog. Log entries carry key value pairs e.g. the controller & the
+// watch these refer to, & are written in the form:
+//
+//	"Synced watch" controller="ns/name" watch="v1:Pod:ns:name"
+//
+// The verbosity of the entries of a controller can be set at runtime
+// independently of glog's -v flag.
+package logging
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/golang/glog"
+)
+
+const (
+	// KeyController is the key of the controller that logs an entry
+	KeyController = "controller"
+
+	// KeyWatch is the key of the watch an entry refers to
+	KeyWatch = "watch"
+
+	// KeyGVR is the key of the group, version & resource an entry
+	// refers to
+	KeyGVR = "gvr"
+
+	// KeyAttachment is the key of the attachment an entry refers to
+	KeyAttachment = "attachment"
+
+	// KeyRequestID is the key of the request ID of the reconcile
+	// that logs an entry
+	KeyRequestID = "requestID"
+)
+
+// Logger writes structured log entries. The zero value logs without
+// any key value pairs at glog's verbosity.
+type Logger struct {
+	// key value pairs that are set against every entry
+	values []interface{}
+
+	// verbosity of this logger if set at runtime; glog's verbosity
+	// is used otherwise
+	verbosity *Verbosity
+}
+
+// New returns a logger that sets the given key value pairs against
+// every entry
+func New(keysAndValues ...interface{}) Logger {
+	return Logger{}.WithValues(keysAndValues...)
+}
+
+// ForController returns a logger that sets the given controller key
+// against every entry & logs at the verbosity that is set for this
+// controller
+func ForController(key string) Logger {
+	return Logger{verbosity: GetVerbosity(key)}.WithValues(KeyController, key)
+}
+
+// WithValues returns a copy of this logger that additionally sets
+// the given key value pairs against every entry
+func (l Logger) WithValues(keysAndValues ...interface{}) Logger {
+	values := make([]interface{}, 0, len(l.values)+len(keysAndValues))
+	values = append(values, l.values...)
+	values = append(values, keysAndValues...)
+	return Logger{values: values, verbosity: l.verbosity}
+}
+
+// V returns a logger that writes entries only if the given level is
+// within the verbosity of this logger
+func (l Logger) V(level glog.Level) VerboseLogger {
+	return VerboseLogger{logger: l, enabled: l.isEnabled(level)}
+}
+
+// isEnabled returns true if entries at the given level are written
+func (l Logger) isEnabled(level glog.Level) bool {
+	if v, ok := l.verbosity.Get(); ok {
+		return level <= v
+	}
+	return bool(glog.V(level))
+}
+
+// Info logs the given message with the given key value pairs
+func (l Logger) Info(msg string, keysAndValues ...interface{}) {
+	glog.InfoDepth(1, l.format(msg, nil, keysAndValues))
+}
+
+// Warning logs the given message with the given key value pairs as
+// a warning
+func (l Logger) Warning(msg string, keysAndValues ...interface{}) {
+	glog.WarningDepth(1, l.format(msg, nil, keysAndValues))
+}
+
+// Error logs the given message & error with the given key value
+// pairs as an error
+func (l Logger) Error(err error, msg string, keysAndValues ...interface{}) {
+	glog.ErrorDepth(1, l.format(msg, err, keysAndValues))
+}
+
+// format returns the entry of the given message, error & key value
+// pairs
+//
+// NOTE:
+//	A key without a value is logged with a missing value marker
+// instead of being dropped
+func (l Logger) format(msg string, err error, keysAndValues []interface{}) string {
+	var b strings.Builder
+	b.WriteString(strconv.Quote(msg))
+	if err != nil {
+		writeKeyValue(&b, "err", err)
+	}
+	for _, kvs := range [][]interface{}{l.values, keysAndValues} {
+		for i := 0; i < len(kvs); i += 2 {
+			var value interface{} = "(MISSING)"
+			if i+1 < len(kvs) {
+				value = kvs[i+1]
+			}
+			writeKeyValue(&b, fmt.Sprint(kvs[i]), value)
+		}
+	}
+	return b.String()
+}
+
+// writeKeyValue writes the given key & value to the given builder
+//
+// NOTE:
+//	Strings, errors & stringers are quoted while other values are
+// written as is
+func writeKeyValue(b *strings.Builder, key string, value interface{}) {
+	b.WriteByte(' ')
+	b.WriteString(key)
+	b.WriteByte('=')
+	switch val := value.(type) {
+	case string:
+		b.WriteString(strconv.Quote(val))
+	case error:
+		b.WriteString(strconv.Quote(val.Error()))
+	case fmt.Stringer:
+		b.WriteString(strconv.Quote(val.String()))
+	default:
+		fmt.Fprintf(b, "%+v", val)
+	}
+}
+
+// VerboseLogger writes entries only if its level is within the
+// verbosity of the logger it is derived from
+type VerboseLogger struct {
+	logger  Logger
+	enabled bool
+}
+
+// Enabled returns true if this logger writes entries
+func (v VerboseLogger) Enabled() bool {
+	return v.enabled
+}
+
+// Info logs the given message with the given key value pairs if this
+// logger is enabled
+func (v VerboseLogger) Info(msg string, keysAndValues ...interface{}) {
+	if !v.enabled {
+		return
+	}
+	glog.InfoDepth(1, v.logger.format(msg, nil, keysAndValues))
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logging
+
+import (
+	"testing"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+)
+
+func TestLoggerFormat(t *testing.T) {
+	var tests = map[string]struct {
+		logger        Logger
+		msg           string
+		err           error
+		keysAndValues []interface{}
+		expect        string
+	}{
+		"message only": {
+			msg:    "Synced",
+			expect: `"Synced"`,
+		},
+		"logger values before entry values": {
+			logger:        New(KeyController, "ns/name"),
+			msg:           "Synced",
+			keysAndValues: []interface{}{KeyWatch, "v1:Pod:ns:pod", "count", 2},
+			expect:        `"Synced" controller="ns/name" watch="v1:Pod:ns:pod" count=2`,
+		},
+		"error is quoted": {
+			msg:    "Can't sync",
+			err:    errors.New(`not "found"`),
+			expect: `"Can't sync" err="not \"found\""`,
+		},
+		"key without value": {
+			msg:           "Synced",
+			keysAndValues: []interface{}{"count"},
+			expect:        `"Synced" count="(MISSING)"`,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			got := mock.logger.format(mock.msg, mock.err, mock.keysAndValues)
+			if got != mock.expect {
+				t.Fatalf("Expected %s got %s", mock.expect, got)
+			}
+		})
+	}
+}
+
+func TestLoggerWithValuesDoesNotShare(t *testing.T) {
+	base := New("a", 1)
+	first := base.WithValues("b", 2)
+	second := base.WithValues("c", 3)
+	if got := first.format("m", nil, nil); got != `"m" a=1 b=2` {
+		t.Fatalf("Expected first with a & b got %s", got)
+	}
+	if got := second.format("m", nil, nil); got != `"m" a=1 c=3` {
+		t.Fatalf("Expected second with a & c got %s", got)
+	}
+}
+
+func TestLoggerVerbosity(t *testing.T) {
+	logger := ForController("test/verbosity")
+	if logger.V(4).Enabled() {
+		t.Fatalf("Expected level 4 disabled at default verbosity")
+	}
+	GetVerbosity("test/verbosity").Set(4)
+	if !logger.V(4).Enabled() {
+		t.Fatalf("Expected level 4 enabled at verbosity 4")
+	}
+	if logger.V(5).Enabled() {
+		t.Fatalf("Expected level 5 disabled at verbosity 4")
+	}
+	if got := ListVerbosities()["test/verbosity"]; got != "4" {
+		t.Fatalf("Expected listed level 4 got %q", got)
+	}
+	GetVerbosity("test/verbosity").Unset()
+	if logger.V(4).Enabled() {
+		t.Fatalf("Expected level 4 disabled after unset")
+	}
+	if got := ListVerbosities()["test/verbosity"]; got != "default" {
+		t.Fatalf("Expected listed level default got %q", got)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	var tests = map[string]struct {
+		value   string
+		level   glog.Level
+		isSet   bool
+		isError bool
+	}{
+		"empty":    {value: ""},
+		"default":  {value: "default"},
+		"level":    {value: "4", level: 4, isSet: true},
+		"negative": {value: "-1", isError: true},
+		"invalid":  {value: "debug", isError: true},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			level, isSet, err := ParseLevel(mock.value)
+			if mock.isError != (err != nil) {
+				t.Fatalf("Expected error %t got %v", mock.isError, err)
+			}
+			if level != mock.level || isSet != mock.isSet {
+				t.Fatalf(
+					"Expected level %d set %t got %d set %t",
+					mock.level, mock.isSet, level, isSet,
+				)
+			}
+		})
+	}
+}
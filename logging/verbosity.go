@@ -0,0 +1,115 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logging
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+)
+
+// unsetVerbosity implies the verbosity is not set at runtime
+const unsetVerbosity int32 = -1
+
+// Verbosity is the log level that is set at runtime for a named
+// component e.g. a controller. It is safe for concurrent use.
+type Verbosity struct {
+	level int32
+}
+
+// Get returns the level of this verbosity & true if it is set
+//
+// NOTE:
+//	This is nil safe
+func (v *Verbosity) Get() (glog.Level, bool) {
+	if v == nil {
+		return 0, false
+	}
+	level := atomic.LoadInt32(&v.level)
+	if level == unsetVerbosity {
+		return 0, false
+	}
+	return glog.Level(level), true
+}
+
+// Set sets the level of this verbosity
+func (v *Verbosity) Set(level glog.Level) {
+	atomic.StoreInt32(&v.level, int32(level))
+}
+
+// Unset unsets the level of this verbosity; glog's verbosity is used
+// thereafter
+func (v *Verbosity) Unset() {
+	atomic.StoreInt32(&v.level, unsetVerbosity)
+}
+
+// verbosities holds the runtime verbosities anchored by their names
+//
+// NOTE:
+//	Verbosities are never removed. This lets a level that was set
+// for a controller survive the restarts of this controller.
+var verbosities = struct {
+	sync.Mutex
+	byName map[string]*Verbosity
+}{byName: make(map[string]*Verbosity)}
+
+// GetVerbosity returns the verbosity of the given name. An unset
+// verbosity is registered if none exists.
+func GetVerbosity(name string) *Verbosity {
+	verbosities.Lock()
+	defer verbosities.Unlock()
+	v, found := verbosities.byName[name]
+	if !found {
+		v = &Verbosity{level: unsetVerbosity}
+		verbosities.byName[name] = v
+	}
+	return v
+}
+
+// ListVerbosities returns the levels of all the registered
+// verbosities anchored by their names. Verbosities that are not set
+// are listed as "default".
+func ListVerbosities() map[string]string {
+	verbosities.Lock()
+	defer verbosities.Unlock()
+	levels := make(map[string]string, len(verbosities.byName))
+	for name, v := range verbosities.byName {
+		levels[name] = "default"
+		if level, ok := v.Get(); ok {
+			levels[name] = strconv.Itoa(int(level))
+		}
+	}
+	return levels
+}
+
+// ParseLevel returns the level of the given value. False is returned
+// if the value is empty or "default" which implies glog's verbosity.
+func ParseLevel(value string) (glog.Level, bool, error) {
+	if value == "" || value == "default" {
+		return 0, false, nil
+	}
+	level, err := strconv.ParseInt(value, 10, 32)
+	if err != nil || level < 0 {
+		return 0, false, errors.Errorf(
+			"Invalid log level %q: Want a non-negative integer or \"default\"", value,
+		)
+	}
+	return glog.Level(level), true, nil
+}
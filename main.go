@@ -18,9 +18,17 @@ limitations under the License.
 package main
 
 import (
+	"os"
+
 	"openebs.io/metac/start"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "validate" {
+		os.Exit(start.Validate(os.Args[2:], os.Stdin, os.Stdout, os.Stderr))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "schema" {
+		os.Exit(start.Schema(os.Args[2:], os.Stdout, os.Stderr))
+	}
 	start.Start()
 }
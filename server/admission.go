@@ -0,0 +1,45 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"openebs.io/metac/admission"
+)
+
+// StartAdmissionHandler returns the http handler that validates the
+// metac controllers sent as admission reviews. Webhooks of these
+// controllers are dialled within the given timeout if it is > 0. The
+// returned function stops the discovery of this handler.
+//
+// NOTE:
+//	Resources are verified against a discovery of its own since this
+// handler is served by the standby replicas as well i.e. before this
+// server is started
+func (s *Server) StartAdmissionHandler(hookDialTimeout time.Duration) (http.Handler, func(), error) {
+	resourceMgr, err := s.newResourceManager(s.Config, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	validator := &admission.Validator{
+		ResourceManager: resourceMgr,
+		HookDialTimeout: hookDialTimeout,
+	}
+	return validator, resourceMgr.Stop, nil
+}
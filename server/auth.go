@@ -0,0 +1,80 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"crypto/subtle"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/golang/glog"
+)
+
+// WithBearerToken returns a handler that serves the given handler only
+// to the requests that send the token stored in the given file as the
+// bearer token of their Authorization header
+//
+// NOTE:
+//	The file is read on every request. This lets the token be rotated
+// e.g. via a mounted secret without restarting metac. All requests are
+// forbidden if the file is not set.
+func WithBearerToken(tokenFile string, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if tokenFile == "" {
+			http.Error(w, "Forbidden: No token is configured", http.StatusForbidden)
+			return
+		}
+		data, err := ioutil.ReadFile(tokenFile)
+		if err != nil {
+			glog.Errorf("Can't read token file %q: %v", tokenFile, err)
+			http.Error(w, "Token is not available", http.StatusServiceUnavailable)
+			return
+		}
+		token := strings.TrimSpace(string(data))
+		if token == "" {
+			glog.Errorf("Can't use token file %q: File is empty", tokenFile)
+			http.Error(w, "Token is not available", http.StatusServiceUnavailable)
+			return
+		}
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "Bearer ") ||
+			subtle.ConstantTimeCompare(
+				[]byte(strings.TrimPrefix(auth, "Bearer ")), []byte(token),
+			) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// WithBearerTokenForWrites returns a handler that serves the given
+// handler to GET & HEAD requests as is & to all the other requests only
+// if these send the token stored in the given file. Refer
+// WithBearerToken.
+func WithBearerTokenForWrites(tokenFile string, handler http.Handler) http.Handler {
+	authorized := WithBearerToken(tokenFile, handler)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		authorized.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWithBearerTokenForWrites(t *testing.T) {
+	dir, err := ioutil.TempDir("", "auth")
+	if err != nil {
+		t.Fatalf("Expected no error got %v", err)
+	}
+	defer os.RemoveAll(dir)
+	tokenFile := filepath.Join(dir, "token")
+	err = ioutil.WriteFile(tokenFile, []byte("secret\n"), 0600)
+	if err != nil {
+		t.Fatalf("Expected no error got %v", err)
+	}
+
+	var tests = map[string]struct {
+		tokenFile  string
+		method     string
+		auth       string
+		expectCode int
+	}{
+		"get without token": {
+			tokenFile:  tokenFile,
+			method:     http.MethodGet,
+			expectCode: http.StatusOK,
+		},
+		"get without token file": {
+			method:     http.MethodGet,
+			expectCode: http.StatusOK,
+		},
+		"put without token": {
+			tokenFile:  tokenFile,
+			method:     http.MethodPut,
+			expectCode: http.StatusUnauthorized,
+		},
+		"post with wrong token": {
+			tokenFile:  tokenFile,
+			method:     http.MethodPost,
+			auth:       "Bearer other",
+			expectCode: http.StatusUnauthorized,
+		},
+		"put with token": {
+			tokenFile:  tokenFile,
+			method:     http.MethodPut,
+			auth:       "Bearer secret",
+			expectCode: http.StatusOK,
+		},
+		"put without token file": {
+			method:     http.MethodPut,
+			auth:       "Bearer secret",
+			expectCode: http.StatusForbidden,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			handler := WithBearerTokenForWrites(
+				mock.tokenFile,
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+			)
+			req := httptest.NewRequest(mock.method, "/loglevel", nil)
+			if mock.auth != "" {
+				req.Header.Set("Authorization", mock.auth)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != mock.expectCode {
+				t.Fatalf("Expected status %d got %d", mock.expectCode, rec.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+	"openebs.io/metac/controller/generic"
+	dynamicdiscovery "openebs.io/metac/dynamic/discovery"
+)
+
+// defaultTargetClusterSecretKey is the key of the secret data that
+// holds the kubeconfig of a target cluster
+const defaultTargetClusterSecretKey = "kubeconfig"
+
+// TargetCluster represents a remote cluster whose resources are
+// discovered separately from the cluster that metac runs against
+//
+// NOTE:
+//	Kubeconfig of this cluster is either read from KubeconfigPath
+// or from a secret of the local cluster
+type TargetCluster struct {
+	// Name by which this cluster is referred to
+	Name string
+
+	// Path to the kubeconfig file of this cluster
+	KubeconfigPath string
+
+	// Secret that holds the kubeconfig of this cluster
+	SecretNamespace string
+	SecretName      string
+
+	// Key of the secret data that holds the kubeconfig. Defaults
+	// to "kubeconfig".
+	SecretKey string
+}
+
+// String implements Stringer interface
+func (c TargetCluster) String() string {
+	if c.KubeconfigPath != "" {
+		return fmt.Sprintf("TargetCluster %s: kubeconfig %s", c.Name, c.KubeconfigPath)
+	}
+	return fmt.Sprintf(
+		"TargetCluster %s: secret %s/%s", c.Name, c.SecretNamespace, c.SecretName,
+	)
+}
+
+// restConfig returns the config to access the given target cluster
+func (s *Server) restConfig(cluster TargetCluster) (*rest.Config, error) {
+	if cluster.KubeconfigPath != "" {
+		return clientcmd.BuildConfigFromFlags("", cluster.KubeconfigPath)
+	}
+	if cluster.SecretNamespace == "" || cluster.SecretName == "" {
+		return nil, errors.Errorf("Either kubeconfig path or secret is required")
+	}
+	kubeClientset, err := kubernetes.NewForConfig(s.Config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Can't create clientset")
+	}
+	secret, err := kubeClientset.CoreV1().
+		Secrets(cluster.SecretNamespace).
+		Get(cluster.SecretName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	key := cluster.SecretKey
+	if key == "" {
+		key = defaultTargetClusterSecretKey
+	}
+	data, found := secret.Data[key]
+	if !found {
+		return nil, errors.Errorf("Secret has no key %q", key)
+	}
+	return clientcmd.RESTConfigFromKubeConfig(data)
+}
+
+// startTargetClusterManagers starts a resource manager per target
+// cluster & sets these against the given cluster managers
+//
+// NOTE:
+//	Resources of a target cluster are resolved against its own
+// resource manager since it may serve different resources & versions
+// than the local cluster
+func (s *Server) startTargetClusterManagers(
+	clusterMgrs *dynamicdiscovery.ClusterManagers,
+) error {
+	for _, cluster := range s.TargetClusters {
+		if cluster.Name == "" {
+			return errors.Errorf("%s: Name is required", cluster)
+		}
+		if clusterMgrs.Get(cluster.Name) != nil {
+			return errors.Errorf("%s: Duplicate name", cluster)
+		}
+		config, err := s.restConfig(cluster)
+		if err != nil {
+			return errors.Wrapf(err, "%s: Can't load config", cluster)
+		}
+		var cachePath string
+		if s.DiscoveryCachePath != "" {
+			cachePath = s.DiscoveryCachePath + "." + cluster.Name
+		}
+		resourceMgr, err := s.newResourceManager(config, cachePath)
+		if err != nil {
+			return errors.Wrapf(err, "%s", cluster)
+		}
+		clusterMgrs.Set(cluster.Name, resourceMgr)
+		glog.Infof("%s: Started discovery", cluster)
+	}
+	return nil
+}
+
+// controllerClusters holds the target clusters of GenericControllers
+type controllerClusters struct {
+	mutex sync.Mutex
+
+	// clusters anchored by the names derived from their kubeconfig
+	// secrets
+	clusters map[string]*generic.TargetCluster
+}
+
+// getControllerCluster returns the target cluster whose kubeconfig is
+// held by the given secret. Clients, discovery & informers of this
+// cluster are created once & are shared by all the GenericControllers
+// that refer to this secret.
+//
+// NOTE:
+//	Resources of this cluster are served by the discovery handlers
+// against the cluster name secret/<namespace>/<name>/<key>. Changes to
+// the kubeconfig are picked up once metac is restarted.
+func (s *Server) getControllerCluster(
+	ref v1alpha1.GenericControllerKubeconfigSecretRef,
+) (*generic.TargetCluster, error) {
+	key := ref.Key
+	if key == "" {
+		key = defaultTargetClusterSecretKey
+	}
+	target := TargetCluster{
+		Name:            fmt.Sprintf("secret/%s/%s/%s", ref.Namespace, ref.Name, key),
+		SecretNamespace: ref.Namespace,
+		SecretName:      ref.Name,
+		SecretKey:       key,
+	}
+
+	s.controllerClusters.mutex.Lock()
+	defer s.controllerClusters.mutex.Unlock()
+
+	if cluster, found := s.controllerClusters.clusters[target.Name]; found {
+		return cluster, nil
+	}
+	config, err := s.restConfig(target)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: Can't load config", target)
+	}
+	resourceMgr, err := s.newResourceManager(config, "")
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s", target)
+	}
+	dynamicClientset, err := s.newDynamicClientset(config, resourceMgr)
+	if err != nil {
+		resourceMgr.Stop()
+		return nil, errors.Wrapf(err, "%s", target)
+	}
+	cluster := &generic.TargetCluster{
+		Name:               target.Name,
+		ResourceManager:    resourceMgr,
+		DynClientset:       dynamicClientset,
+		DynInformerFactory: s.makeDynamicInformerFactory(dynamicClientset),
+	}
+	s.controllerClusters.clusters[target.Name] = cluster
+	s.clusterMgrs.Set(target.Name, resourceMgr)
+	glog.Infof("%s: Started discovery", target)
+	return cluster, nil
+}
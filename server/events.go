@@ -0,0 +1,113 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/glog"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/record"
+
+	"openebs.io/metac/controller/common"
+)
+
+// eventFlushIdleTime is the duration for which no event should be
+// written to consider the pending events as flushed
+const eventFlushIdleTime = 500 * time.Millisecond
+
+// flushingEventSink tracks the writes of events to its sink to let
+// the pending events be flushed on shutdown
+type flushingEventSink struct {
+	record.EventSink
+
+	// number of writes in progress & the unix nano time of the most
+	// recent activity; these are accessed atomically
+	inFlight     int64
+	lastActivity int64
+}
+
+// track marks a write as in progress. The returned function marks
+// this write as completed.
+func (s *flushingEventSink) track() func() {
+	atomic.AddInt64(&s.inFlight, 1)
+	return func() {
+		atomic.StoreInt64(&s.lastActivity, time.Now().UnixNano())
+		atomic.AddInt64(&s.inFlight, -1)
+	}
+}
+
+// Create implements record.EventSink interface
+func (s *flushingEventSink) Create(event *corev1.Event) (*corev1.Event, error) {
+	defer s.track()()
+	return s.EventSink.Create(event)
+}
+
+// Update implements record.EventSink interface
+func (s *flushingEventSink) Update(event *corev1.Event) (*corev1.Event, error) {
+	defer s.track()()
+	return s.EventSink.Update(event)
+}
+
+// Patch implements record.EventSink interface
+func (s *flushingEventSink) Patch(event *corev1.Event, data []byte) (*corev1.Event, error) {
+	defer s.track()()
+	return s.EventSink.Patch(event, data)
+}
+
+// isIdle returns true if no event is being written & none was
+// written recently
+func (s *flushingEventSink) isIdle() bool {
+	last := time.Unix(0, atomic.LoadInt64(&s.lastActivity))
+	return atomic.LoadInt64(&s.inFlight) == 0 && time.Since(last) >= eventFlushIdleTime
+}
+
+// flushEvents stops the given broadcaster & waits till its pending
+// events are written to the given sink or till the drain timeout
+// that is left after draining the in-flight syncs
+//
+// NOTE:
+//	Broadcaster hands over the pending events to the sink before its
+// shutdown returns. The sink then writes these one after the other.
+// Hence the events are considered flushed once the sink is idle.
+func flushEvents(broadcaster record.EventBroadcaster, sink *flushingEventSink) {
+	atomic.StoreInt64(&sink.lastActivity, time.Now().UnixNano())
+	broadcaster.Shutdown()
+
+	isIdle := func() (bool, error) {
+		return sink.isIdle(), nil
+	}
+	var err error
+	left, isLimited := common.GetDrainTimeLeft()
+	switch {
+	case !isLimited:
+		err = wait.PollImmediateInfinite(eventFlushIdleTime/10, isIdle)
+	case left > 0:
+		err = wait.PollImmediate(eventFlushIdleTime/10, left, isIdle)
+	case !sink.isIdle():
+		// NOTE:
+		//	A zero timeout makes the poll wait forever
+		err = wait.ErrWaitTimeout
+	}
+	if err != nil {
+		glog.Warningf("Pending events were not flushed within drain timeout: %v", err)
+		return
+	}
+	glog.Info("Flushed pending events")
+}
@@ -17,16 +17,28 @@ limitations under the License.
 package server
 
 import (
+	"net/http"
 	"sync"
 	"time"
 
+	"github.com/golang/glog"
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 
 	"openebs.io/metac/apis/metacontroller/v1alpha1"
 	metaclientset "openebs.io/metac/client/generated/clientset/versioned"
+	metascheme "openebs.io/metac/client/generated/clientset/versioned/scheme"
 	metainformers "openebs.io/metac/client/generated/informers/externalversions"
+	"openebs.io/metac/config"
+	"openebs.io/metac/controller/common"
 	"openebs.io/metac/controller/composite"
 	"openebs.io/metac/controller/decorator"
 	"openebs.io/metac/controller/generic"
@@ -54,13 +66,320 @@ type Server struct {
 	// Kubernetes config required to make API calls
 	Config *rest.Config
 
-	// How often to refresh discovery cache to pick
-	// up newly-installed resources
+	// How often to refresh the entire discovery cache. Discovery
+	// cache is also refreshed when custom resource definitions or
+	// api services change.
 	DiscoveryInterval time.Duration
 
+	// Fraction of the discovery interval by which each full
+	// discovery is randomly delayed
+	DiscoveryJitter float64
+
+	// Maximum time taken by a single discovery request. Default
+	// timeout of discovery client is used if this is not set.
+	DiscoveryTimeout time.Duration
+
+	// File to persist discovered resources. Persisted resources
+	// are loaded at start so that controllers can start before
+	// the first discovery completes.
+	DiscoveryCachePath string
+
 	// How often to flush local caches and relist
 	// objects from the API server
 	InformerRelist time.Duration
+
+	// Namespaces that all the controllers & informers are
+	// restricted to; all namespaces are considered if empty
+	Namespaces []string
+
+	// Namespaces that all the controllers & informers ignore
+	ExcludeNamespaces []string
+
+	// When true built-in resources of read only attachments are
+	// listed & watched as protobuf instead of json
+	ClientProtobuf bool
+
+	// Rate limit of the requests made against a single resource i.e.
+	// group, version & resource unless the resource is found in the
+	// resource rate limits
+	ResourceRateLimit dynamicclientset.RateLimit
+
+	// Rate limits of the requests made against the resources keyed
+	// by resource & apiVersion e.g. deployments.apps/v1
+	ResourceRateLimits map[string]dynamicclientset.RateLimit
+
+	// When true every create, update, patch & delete made via the
+	// dynamic clients is a dry run
+	DryRun bool
+
+	// Number of resources fetched per list request by the informers;
+	// resources are listed in a single request if this is not set
+	CacheListPageSize int64
+
+	// Duration for which an informer without subscribers is kept
+	// running before it is stopped; such an informer is stopped
+	// immediately if this is not set
+	InformerIdleTTL time.Duration
+
+	// Fields that are removed from the resources before these get
+	// cached by the informers
+	CacheTransform dynamicinformer.CacheTransform
+
+	// Strategy used by GenericControllers to apply their attachments
+	// unless these controllers set their own
+	ApplyStrategy v1alpha1.ApplyStrategy
+
+	// Limits of the requests made by every GenericController unless
+	// these controllers set their own
+	ControllerClientLimits generic.ClientLimits
+
+	// Number of most recent reconciles that are retained per
+	// GenericController; these are not retained if this is not set
+	ReconcileHistorySize int
+
+	// Tunes the detection of the watches that are synced & mutated
+	// continuously by GenericControllers
+	HotLoop generic.HotLoopSettings
+
+	// Remote clusters whose resources are discovered separately
+	// from the cluster that metac runs against
+	TargetClusters []TargetCluster
+
+	// discover the server resources of the local & target clusters
+	// once this server is started
+	clusterMgrs *dynamicdiscovery.ClusterManagers
+
+	// shares the dynamic informers of all the controllers once this
+	// server is started
+	informerFactory *dynamicinformer.SharedInformerFactory
+
+	// target clusters of the GenericControllers once this server
+	// is started
+	controllerClusters *controllerClusters
+}
+
+// ClusterResourceManagers returns the resource managers of the local
+// & target clusters
+//
+// NOTE:
+//	This is valid only after this server is started
+func (s *Server) ClusterResourceManagers() *dynamicdiscovery.ClusterManagers {
+	return s.clusterMgrs
+}
+
+// DiscoveryStatusHandler returns the http handler that responds with
+// the state of resource discovery including the group versions that
+// could not be discovered. Target cluster is set via the cluster
+// parameter.
+//
+// NOTE:
+//	This is valid only after this server is started
+func (s *Server) DiscoveryStatusHandler() http.Handler {
+	return s.discoveryHandler(func(
+		mgr *dynamicdiscovery.APIResourceManager, w http.ResponseWriter, r *http.Request,
+	) {
+		mgr.ServeHTTP(w, r)
+	})
+}
+
+// ResourceMappingHandler returns the http handler that responds with
+// the resources that the queried apiVersion & resource or kind map to
+// as per discovery e.g. ?apiVersion=apps/v1&resource=deploy. Target
+// cluster is set via the cluster parameter.
+//
+// NOTE:
+//	This is valid only after this server is started
+func (s *Server) ResourceMappingHandler() http.Handler {
+	return s.discoveryHandler(func(
+		mgr *dynamicdiscovery.APIResourceManager, w http.ResponseWriter, r *http.Request,
+	) {
+		mgr.ServeResourceMappings(w, r)
+	})
+}
+
+// InformerStatusHandler returns the http handler that responds with
+// the shared informers & the controllers subscribed to these
+//
+// NOTE:
+//	This is valid only after this server is started
+func (s *Server) InformerStatusHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.informerFactory == nil {
+			http.Error(w, "Informers are not started yet", http.StatusServiceUnavailable)
+			return
+		}
+		if r.Method != http.MethodGet {
+			http.Error(w, "Only GET is supported", http.StatusMethodNotAllowed)
+			return
+		}
+		s.informerFactory.ServeHTTP(w, r)
+	})
+}
+
+// discoveryHandler returns a handler that invokes the given function
+// with the resource manager of the requested cluster. This responds
+// only after discovery is started.
+func (s *Server) discoveryHandler(
+	serve func(*dynamicdiscovery.APIResourceManager, http.ResponseWriter, *http.Request),
+) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.clusterMgrs == nil {
+			http.Error(w, "Discovery is not started yet", http.StatusServiceUnavailable)
+			return
+		}
+		if r.Method != http.MethodGet {
+			http.Error(w, "Only GET is supported", http.StatusMethodNotAllowed)
+			return
+		}
+		cluster := r.URL.Query().Get("cluster")
+		mgr := s.clusterMgrs.Get(cluster)
+		if mgr == nil {
+			http.Error(w, "Unknown cluster "+cluster, http.StatusNotFound)
+			return
+		}
+		serve(mgr, w, r)
+	})
+}
+
+// newDynamicClientset returns a new instance of dynamic clientset
+// of the cluster of the given config
+func (s *Server) newDynamicClientset(
+	config *rest.Config, resourceMgr *dynamicdiscovery.APIResourceManager,
+) (*dynamicclientset.Clientset, error) {
+	return dynamicclientset.New(
+		config,
+		resourceMgr,
+		dynamicclientset.WithProtobuf(s.ClientProtobuf),
+		dynamicclientset.WithResourceRateLimits(s.ResourceRateLimit, s.ResourceRateLimits),
+		dynamicclientset.WithDryRun(s.DryRun),
+	)
+}
+
+// newDynamicInformerFactory returns a new instance of dynamic informer
+// factory restricted to the namespaces of this server
+func (s *Server) newDynamicInformerFactory(
+	dynamicClientset *dynamicclientset.Clientset,
+) *dynamicinformer.SharedInformerFactory {
+	s.informerFactory = s.makeDynamicInformerFactory(dynamicClientset)
+	return s.informerFactory
+}
+
+// makeDynamicInformerFactory returns a new instance of dynamic
+// informer factory of the cluster of the given clientset
+func (s *Server) makeDynamicInformerFactory(
+	dynamicClientset *dynamicclientset.Clientset,
+) *dynamicinformer.SharedInformerFactory {
+	return dynamicinformer.NewSharedInformerFactoryWithOptions(
+		dynamicClientset,
+		s.InformerRelist,
+		dynamicinformer.WithNamespaceFilter(
+			dynamicinformer.NamespaceFilter{
+				Namespaces:        s.Namespaces,
+				ExcludeNamespaces: s.ExcludeNamespaces,
+			},
+		),
+		dynamicinformer.WithCacheTransform(s.CacheTransform),
+		dynamicinformer.WithListPageSize(s.CacheListPageSize),
+		dynamicinformer.WithIdleTTL(s.InformerIdleTTL),
+	)
+}
+
+// newEventRecorder returns a new instance of event recorder that
+// raises events against the kubernetes cluster. The returned function
+// flushes the pending events & stops the recorder.
+func (s *Server) newEventRecorder() (record.EventRecorder, func(), error) {
+	kubeClientset, err := kubernetes.NewForConfig(s.Config)
+	if err != nil {
+		return nil, nil, errors.Wrapf(
+			err, "Can't create event recorder: Can't create clientset",
+		)
+	}
+	// events are raised against GenericController custom resources
+	// as well
+	eventScheme := runtime.NewScheme()
+	for _, addToScheme := range []func(*runtime.Scheme) error{
+		scheme.AddToScheme, metascheme.AddToScheme,
+	} {
+		err = addToScheme(eventScheme)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "Can't create event recorder: Can't build scheme")
+		}
+	}
+	sink := &flushingEventSink{
+		EventSink: &typedcorev1.EventSinkImpl{
+			Interface: kubeClientset.CoreV1().Events(""),
+		},
+	}
+	broadcaster := record.NewBroadcaster()
+	broadcaster.StartLogging(glog.Infof)
+	broadcaster.StartRecordingToSink(sink)
+	recorder := broadcaster.NewRecorder(
+		eventScheme,
+		corev1.EventSource{Component: "metac"},
+	)
+	return recorder, func() {
+		flushEvents(broadcaster, sink)
+	}, nil
+}
+
+// startResourceManager starts the discovery of server resources
+//
+// NOTE:
+//	Discovery cache is refreshed when custom resource definitions or
+// api services change to pick up newly-installed resources. It is
+// also refreshed in the discovery interval.
+func (s *Server) startResourceManager() (*dynamicdiscovery.APIResourceManager, error) {
+	resourceMgr, err := s.newResourceManager(s.Config, s.DiscoveryCachePath)
+	if err != nil {
+		return nil, err
+	}
+	clusterMgrs := dynamicdiscovery.NewClusterManagers(resourceMgr)
+	err = s.startTargetClusterManagers(clusterMgrs)
+	if err != nil {
+		return nil, err
+	}
+	s.clusterMgrs = clusterMgrs
+	s.controllerClusters = &controllerClusters{
+		clusters: make(map[string]*generic.TargetCluster),
+	}
+
+	// clients created henceforth let discovery learn the deprecated
+	// resources from the warnings of the API server
+	s.Config = resourceMgr.WrapConfig(s.Config)
+	return resourceMgr, nil
+}
+
+// newResourceManager returns a started resource manager that discovers
+// the resources of the cluster of the given config
+func (s *Server) newResourceManager(
+	config *rest.Config, cachePath string,
+) (*dynamicdiscovery.APIResourceManager, error) {
+	discoveryConfig := rest.CopyConfig(config)
+	discoveryConfig.Timeout = s.DiscoveryTimeout
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(discoveryConfig)
+	if err != nil {
+		return nil, errors.Wrapf(
+			err, "Can't start discovery: Can't create discovery client",
+		)
+	}
+	resourceMgr := dynamicdiscovery.NewAPIResourceManager(discoveryClient)
+	resourceMgr.RefreshJitter = s.DiscoveryJitter
+	resourceMgr.CachePath = cachePath
+	err = resourceMgr.LoadCache()
+	if err != nil {
+		// resources will be available once discovered
+		glog.Warningf("%v", err)
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, errors.Wrapf(
+			err, "Can't start discovery: Can't create dynamic client",
+		)
+	}
+	// We don't care about stopping this cleanly since it has no external effects.
+	resourceMgr.StartWatching(dynamicClient, s.DiscoveryInterval)
+	return resourceMgr, nil
 }
 
 // CRDBasedServer represents metac server based on
@@ -68,20 +387,52 @@ type Server struct {
 // Kubernetes CustomResourceDefinition(s).
 type CRDBasedServer struct {
 	Server
+
+	// generic meta controller of the GenericController resources;
+	// this is set once this server is started
+	genericMetac *generic.CRDBasedMetaController
 }
 
 func (s *CRDBasedServer) String() string {
 	return "CRDMetacServer"
 }
 
+// DebugStateHandler returns the http handler that responds with the
+// live state of the watch controllers & the informers
+//
+// NOTE:
+//	This is valid only after this server is started
+func (s *CRDBasedServer) DebugStateHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.genericMetac == nil {
+			http.Error(w, "Controllers are not started yet", http.StatusServiceUnavailable)
+			return
+		}
+		s.genericMetac.ServeDebugState(w, r)
+	})
+}
+
+// ReconcileHistoryHandler returns the http handler that responds with
+// the most recent reconciles of the watch controllers
+//
+// NOTE:
+//	This is valid only after this server is started
+func (s *CRDBasedServer) ReconcileHistoryHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.genericMetac == nil {
+			http.Error(w, "Controllers are not started yet", http.StatusServiceUnavailable)
+			return
+		}
+		s.genericMetac.ServeReconcileHistory(w, r)
+	})
+}
+
 // Start metac server
 func (s *CRDBasedServer) Start(workerCount int) (stop func(), err error) {
-	// Periodically refresh discovery cache to pick up newly-installed resources.
-	discoveryClient := discovery.NewDiscoveryClientForConfigOrDie(s.Config)
-	resourceMgr := dynamicdiscovery.NewAPIResourceManager(discoveryClient)
-
-	// We don't care about stopping this cleanly since it has no external effects.
-	resourceMgr.Start(s.DiscoveryInterval)
+	resourceMgr, err := s.startResourceManager()
+	if err != nil {
+		return nil, err
+	}
 
 	// Create informer factory for metacontroller API objects.
 	metaClientset, err := metaclientset.NewForConfig(s.Config)
@@ -96,13 +447,34 @@ func (s *CRDBasedServer) Start(workerCount int) (stop func(), err error) {
 		metainformers.NewSharedInformerFactory(metaClientset, s.InformerRelist)
 
 	// Create dynamic clientset (factory for dynamic clients).
-	dynamicClientset, err := dynamicclientset.New(s.Config, resourceMgr)
+	dynamicClientset, err := s.newDynamicClientset(s.Config, resourceMgr)
 	if err != nil {
 		return nil, err
 	}
 	// Create dynamic informer factory (for sharing dynamic informers).
-	dynamicInformerFactory :=
-		dynamicinformer.NewSharedInformerFactory(dynamicClientset, s.InformerRelist)
+	dynamicInformerFactory := s.newDynamicInformerFactory(dynamicClientset)
+
+	// Create event recorder to raise events against watch resources
+	eventRecorder, stopEvents, err := s.newEventRecorder()
+	if err != nil {
+		return nil, err
+	}
+
+	genericMetac := generic.NewCRDBasedMetaController(
+		resourceMgr,
+		dynamicClientset,
+		dynamicInformerFactory,
+		metaInformerFactory,
+		workerCount,
+	)
+	genericMetac.EventRecorder = eventRecorder
+	genericMetac.MetaClientset = metaClientset
+	genericMetac.ApplyStrategy = s.ApplyStrategy
+	genericMetac.ClientLimits = s.ControllerClientLimits
+	genericMetac.ReconcileHistorySize = s.ReconcileHistorySize
+	genericMetac.HotLoop = s.HotLoop
+	genericMetac.TargetClusterFn = s.getControllerCluster
+	s.genericMetac = genericMetac
 
 	// Start various metacontrollers (controllers that spawn controllers).
 	// Each one requests the informers it needs from the factory.
@@ -122,13 +494,7 @@ func (s *CRDBasedServer) Start(workerCount int) (stop func(), err error) {
 			metaInformerFactory,
 			workerCount,
 		),
-		generic.NewCRDBasedMetaController(
-			resourceMgr,
-			dynamicClientset,
-			dynamicInformerFactory,
-			metaInformerFactory,
-			workerCount,
-		),
+		genericMetac,
 	}
 
 	// Start all requested informers.
@@ -141,7 +507,14 @@ func (s *CRDBasedServer) Start(workerCount int) (stop func(), err error) {
 	}
 
 	// Return a function that will stop all controllers.
+	//
+	// NOTE:
+	//	Controllers stop syncing & drain their in-flight syncs
+	// before their informers are stopped. Events raised by these
+	// syncs are flushed once all the controllers are stopped. Both
+	// of these are done within a single drain timeout.
 	return func() {
+		common.StartDrain()
 		var wg sync.WaitGroup
 		for _, c := range metaControllers {
 			wg.Add(1)
@@ -151,6 +524,7 @@ func (s *CRDBasedServer) Start(workerCount int) (stop func(), err error) {
 			}(c)
 		}
 		wg.Wait()
+		stopEvents()
 	}, nil
 }
 
@@ -162,45 +536,233 @@ type ConfigBasedServer struct {
 	// Path that has the config files(s) to run Metac
 	ConfigPath string
 
+	// Glob patterns of the files at ConfigPath that are loaded
+	ConfigPathInclude []string
+
+	// Glob patterns of the files at ConfigPath that are not loaded
+	ConfigPathExclude []string
+
+	// Label selector of the config maps that have the configs to
+	// run Metac
+	//
+	// NOTE:
+	//	Config maps have higher priority than ConfigPath
+	ConfigMapSelector string
+
+	// Namespace of the config maps that have the configs to run Metac
+	ConfigMapNamespace string
+
+	// HTTP(S) endpoint that serves the configs to run Metac
+	//
+	// NOTE:
+	//	Config url has higher priority than ConfigPath
+	ConfigURL string
+
+	// Interval at which ConfigURL is polled for changes
+	ConfigURLPollInterval time.Duration
+
+	// Git repository that has the configs to run Metac
+	//
+	// NOTE:
+	//	Git repository has higher priority than ConfigPath
+	ConfigGitRepository string
+
+	// Branch, tag or commit of ConfigGitRepository that has the configs
+	ConfigGitRef string
+
+	// Path relative to the root of ConfigGitRepository that has the
+	// config files
+	ConfigGitSubPath string
+
+	// Local directory where ConfigGitRepository is checked out
+	ConfigGitDir string
+
+	// Interval at which ConfigGitRef is pulled for changes
+	ConfigGitPollInterval time.Duration
+
+	// Path to a yaml file of values against which the configs to run
+	// Metac are rendered as templates
+	//
+	// NOTE:
+	//	Configs are not rendered if this is empty
+	ConfigValuesPath string
+
+	// Substitute the variables referred in the configs to run Metac
+	// with the values of environment variables & runtime values
+	IsSubstituteConfigVars bool
+
+	// Name of the cluster that can be referred in the configs as
+	// ${METAC_CLUSTER_NAME}
+	ClusterName string
+
+	// Policy of the configs to run Metac that have the same kind,
+	// namespace & name
+	ConfigDuplicatePolicy config.DuplicatePolicy
+
+	// Profiles whose configs are run in addition to the configs of
+	// the default profile
+	EnabledProfiles []string
+
+	// Profiles whose configs are not run. This has higher priority
+	// than EnabledProfiles.
+	DisabledProfiles []string
+
 	// Function that fetches GenericController instances to
 	// be used as configs to run Metac
 	//
 	// NOTE:
-	//	One may use ConfigPath or this function. ConfigPath has
-	// higher priority
+	//	One may use config maps, ConfigPath or this function. This
+	// function has the lowest priority
 	GenericControllerAsConfigFn func() ([]*v1alpha1.GenericController, error)
 
+	// Source that lists & watches the metac configs e.g. a database
+	// of a binary that embeds metac
+	//
+	// NOTE:
+	//	This has higher priority than config maps, ConfigURL,
+	// ConfigGitRepository & ConfigPath
+	ConfigSource config.ConfigSource
+
 	// Number of workers per watch controller
 	workerCount int
+
+	// generic meta controller that loads the configs; this is set
+	// once this server is started
+	genericMetac *generic.ConfigBasedMetaController
 }
 
 func (s *ConfigBasedServer) String() string {
 	return "ConfigMetacServer"
 }
 
+// ConfigStatusHandler returns the http handler that responds with the
+// report of the loaded configs & the state of their controllers
+//
+// NOTE:
+//	This is valid only after this server is started
+func (s *ConfigBasedServer) ConfigStatusHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.genericMetac == nil {
+			http.Error(w, "Configs are not loaded yet", http.StatusServiceUnavailable)
+			return
+		}
+		s.genericMetac.ServeHTTP(w, r)
+	})
+}
+
+// DebugStateHandler returns the http handler that responds with the
+// live state of the watch controllers & the informers
+//
+// NOTE:
+//	This is valid only after this server is started
+func (s *ConfigBasedServer) DebugStateHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.genericMetac == nil {
+			http.Error(w, "Configs are not loaded yet", http.StatusServiceUnavailable)
+			return
+		}
+		s.genericMetac.ServeDebugState(w, r)
+	})
+}
+
+// ReconcileHistoryHandler returns the http handler that responds with
+// the most recent reconciles of the watch controllers
+//
+// NOTE:
+//	This is valid only after this server is started
+func (s *ConfigBasedServer) ReconcileHistoryHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.genericMetac == nil {
+			http.Error(w, "Configs are not loaded yet", http.StatusServiceUnavailable)
+			return
+		}
+		s.genericMetac.ServeReconcileHistory(w, r)
+	})
+}
+
 // Start metac server
 func (s *ConfigBasedServer) Start(workerCount int) (stop func(), err error) {
-	// Periodically refresh discovery cache to pick up newly-installed resources.
-	discoveryClient := discovery.NewDiscoveryClientForConfigOrDie(s.Config)
-	resourceMgr := dynamicdiscovery.NewAPIResourceManager(discoveryClient)
-
-	// We don't care about stopping this cleanly since it has no external effects.
-	resourceMgr.Start(s.DiscoveryInterval)
+	resourceMgr, err := s.startResourceManager()
+	if err != nil {
+		return nil, err
+	}
 
 	// Create dynamic clientset (factory for dynamic clients).
-	dynamicClientset, err := dynamicclientset.New(s.Config, resourceMgr)
+	dynamicClientset, err := s.newDynamicClientset(s.Config, resourceMgr)
 	if err != nil {
 		return nil, err
 	}
 	// Create dynamic informer factory (for sharing dynamic informers).
-	dynamicInformerFactory :=
-		dynamicinformer.NewSharedInformerFactory(dynamicClientset, s.InformerRelist)
+	dynamicInformerFactory := s.newDynamicInformerFactory(dynamicClientset)
+
+	// Create event recorder to raise events against watch resources
+	eventRecorder, stopEvents, err := s.newEventRecorder()
+	if err != nil {
+		return nil, err
+	}
 
 	// various generic meta controller options to setup meta controller
 	// that runs using these configurations
 	configOpts := []generic.ConfigBasedMetaControllerOption{
 		generic.SetGenericControllerAsConfigFn(s.GenericControllerAsConfigFn),
+		generic.SetMetaControllerConfigSource(s.ConfigSource),
 		generic.SetMetaControllerConfigPath(s.ConfigPath),
+		generic.SetMetaControllerConfigPathPatterns(s.ConfigPathInclude, s.ConfigPathExclude),
+		generic.SetMetaControllerEventRecorder(eventRecorder),
+		generic.SetMetaControllerProfiles(s.EnabledProfiles, s.DisabledProfiles),
+		generic.SetMetaControllerConfigDuplicatePolicy(s.ConfigDuplicatePolicy),
+		generic.SetMetaControllerApplyStrategy(s.ApplyStrategy),
+		generic.SetMetaControllerClientLimits(s.ControllerClientLimits),
+		generic.SetMetaControllerReconcileHistorySize(s.ReconcileHistorySize),
+		generic.SetMetaControllerHotLoop(s.HotLoop),
+		generic.SetMetaControllerTargetClusterFn(s.getControllerCluster),
+		generic.SetMetaControllerConfigURL(s.ConfigURL, s.ConfigURLPollInterval),
+		generic.SetMetaControllerConfigGit(
+			s.ConfigGitRepository,
+			s.ConfigGitRef,
+			s.ConfigGitSubPath,
+			s.ConfigGitDir,
+			s.ConfigGitPollInterval,
+		),
+		// CompositeController & DecoratorController configs are run
+		// by the generic meta controller's config syncers
+		generic.SetMetaControllerConfigSyncers(
+			composite.NewConfigBasedMetacontroller(
+				resourceMgr, dynamicClientset, dynamicInformerFactory, workerCount,
+			),
+			decorator.NewConfigBasedMetacontroller(
+				resourceMgr, dynamicClientset, dynamicInformerFactory, workerCount,
+			),
+		),
+	}
+	if s.ConfigValuesPath != "" {
+		renderer, err := config.NewRenderer(s.ConfigValuesPath)
+		if err != nil {
+			return nil, err
+		}
+		configOpts = append(configOpts, generic.SetMetaControllerConfigRenderer(renderer))
+	}
+	if s.IsSubstituteConfigVars {
+		configOpts = append(
+			configOpts,
+			generic.SetMetaControllerConfigSubstituter(
+				config.NewSubstituter(config.RuntimeVars(s.ClusterName)),
+			),
+		)
+	}
+	if s.ConfigMapSelector != "" {
+		kubeClientset, err := kubernetes.NewForConfig(s.Config)
+		if err != nil {
+			return nil, errors.Wrapf(
+				err, "Can't load configs from config maps: Can't create clientset",
+			)
+		}
+		configOpts = append(
+			configOpts,
+			generic.SetMetaControllerConfigMapSource(
+				kubeClientset, s.ConfigMapNamespace, s.ConfigMapSelector,
+			),
+		)
 	}
 
 	genericMetac, err := generic.NewConfigBasedMetaController(
@@ -213,16 +775,16 @@ func (s *ConfigBasedServer) Start(workerCount int) (stop func(), err error) {
 	if err != nil {
 		return nil, err
 	}
+	s.genericMetac = genericMetac
 
 	// Start various metacontrollers (controllers that spawn controllers).
 	// Each one requests the informers it needs from the factory.
+	//
+	// NOTE:
+	//	Generic meta controller loads the configs & runs the
+	// CompositeControllers & DecoratorControllers found in these
+	// configs as well
 	metaControllers := []controller{
-		// TODO (@amitkumardas):
-		//
-		// Currently GenericController is the only meta controller
-		// supported to run using config i.e. runaslocal flag.
-		// Support for other controllers will be introduced once
-		// config mode for GenericController works fine.
 		genericMetac,
 	}
 
@@ -232,7 +794,14 @@ func (s *ConfigBasedServer) Start(workerCount int) (stop func(), err error) {
 	}
 
 	// Return a function that will stop all controllers.
+	//
+	// NOTE:
+	//	Controllers stop syncing & drain their in-flight syncs
+	// before their informers are stopped. Events raised by these
+	// syncs are flushed once all the controllers are stopped. Both
+	// of these are done within a single drain timeout.
 	return func() {
+		common.StartDrain()
 		var wg sync.WaitGroup
 		for _, c := range metaControllers {
 			wg.Add(1)
@@ -242,5 +811,6 @@ func (s *ConfigBasedServer) Start(workerCount int) (stop func(), err error) {
 			}(c)
 		}
 		wg.Wait()
+		stopEvents()
 	}, nil
 }
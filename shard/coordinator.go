@@ -0,0 +1,406 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shard
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	coordinationv1client "k8s.io/client-go/kubernetes/typed/coordination/v1"
+	"k8s.io/client-go/rest"
+)
+
+// observedLease is a lease of some other member as seen by this
+// replica
+type observedLease struct {
+	// resource version of the lease; this changes on every renew
+	resourceVersion string
+
+	// local time at which this resource version was first seen
+	//
+	// NOTE:
+	//	Local times are used instead of the renew time of the lease
+	// since the clocks of the replicas may differ
+	observedAt time.Time
+}
+
+// coordinator renews the lease of this replica & builds the ring
+// from the leases of the members that are alive
+type coordinator struct {
+	config Config
+	leases coordinationv1client.LeaseInterface
+
+	// holder identity & name of the lease of this replica
+	identity string
+	member   string
+
+	// current time; this is overridden in tests
+	now func() time.Time
+
+	// fields below are accessed only from the sync loop
+
+	// leases of the other members by their names
+	observed map[string]observedLease
+
+	// local time at which the lease of this replica was last renewed
+	lastRenewed time.Time
+
+	// guards the fields below
+	mutex sync.RWMutex
+
+	// ring of the members that are alive
+	ring *Ring
+
+	// functions that are invoked when the members change
+	subscribers      map[int]func(Change)
+	nextSubscriberID int
+
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// newCoordinator returns a coordinator of the given shard group
+func newCoordinator(config *rest.Config, shard Config) (*coordinator, error) {
+	err := shard.validate()
+	if err != nil {
+		return nil, err
+	}
+	// a hung request should not delay the renew of the lease
+	config = rest.CopyConfig(config)
+	config.Timeout = shard.RenewInterval
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Can't create clientset for shard group %q", shard.Group)
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, errors.Wrapf(err, "Can't get identity for shard group %q", shard.Group)
+	}
+	id := string(uuid.NewUUID())
+	return newCoordinatorFor(
+		clientset,
+		shard,
+		// unique even if replicas share the hostname e.g. after restarts
+		hostname+"_"+id,
+		shard.Group+"-"+id,
+	), nil
+}
+
+// newCoordinatorFor returns a coordinator that holds the lease of
+// the given member via the given clientset
+func newCoordinatorFor(
+	clientset kubernetes.Interface, shard Config, identity, member string,
+) *coordinator {
+	return &coordinator{
+		config:      shard,
+		leases:      clientset.CoordinationV1().Leases(shard.Namespace),
+		identity:    identity,
+		member:      member,
+		now:         time.Now,
+		observed:    make(map[string]observedLease),
+		subscribers: make(map[int]func(Change)),
+		stopCh:      make(chan struct{}),
+		doneCh:      make(chan struct{}),
+	}
+}
+
+// start joins the group & keeps syncing the members in the background
+func (c *coordinator) start() error {
+	err := c.sync()
+	if err != nil {
+		return errors.Wrapf(err, "Can't join shard group %q", c.config.Group)
+	}
+	go func() {
+		defer close(c.doneCh)
+		wait.Until(func() {
+			err := c.sync()
+			if err != nil {
+				glog.Warningf("Can't sync shard group %q: %v", c.config.Group, err)
+			}
+		}, c.config.RenewInterval, c.stopCh)
+	}()
+	return nil
+}
+
+// stop stops the sync loop & deletes the lease of this replica to
+// let the other members take over its keys right away
+func (c *coordinator) stop() {
+	c.stopOnce.Do(func() {
+		close(c.stopCh)
+		<-c.doneCh
+
+		// nothing is owned once this replica leaves the group
+		c.mutex.Lock()
+		c.ring = NewRing(nil)
+		c.mutex.Unlock()
+
+		err := c.leases.Delete(c.member, &metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			glog.Warningf(
+				"Can't delete lease %s/%s of shard group %q: %v",
+				c.config.Namespace, c.member, c.config.Group, err,
+			)
+			return
+		}
+		glog.Infof(
+			"Left shard group %q: Deleted lease %s/%s",
+			c.config.Group, c.config.Namespace, c.member,
+		)
+	})
+}
+
+// sync renews the lease of this replica & rebuilds the ring from the
+// members that are alive
+//
+// NOTE:
+//	This replica drops out of the ring if it fails to renew its lease
+// for the lease duration. The other members take over its keys only
+// after they observe the lease not being renewed for this duration.
+func (c *coordinator) sync() error {
+	now := c.now()
+	renewErr := c.renew(now)
+
+	members, listErr := c.listMembers(now)
+	if listErr != nil {
+		// members are retained till these can be listed again
+		members = withoutMember(c.getRing().Members(), c.member)
+	}
+	if !c.lastRenewed.IsZero() && now.Sub(c.lastRenewed) < c.config.LeaseDuration {
+		members = append(members, c.member)
+	}
+	c.setRing(NewRing(members))
+	return utilerrors.NewAggregate([]error{renewErr, listErr})
+}
+
+// renew creates or renews the lease of this replica
+func (c *coordinator) renew(now time.Time) error {
+	renewTime := metav1.NewMicroTime(now)
+	durationSeconds := int32(c.config.LeaseDuration / time.Second)
+	lease, err := c.leases.Get(c.member, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = c.leases.Create(&coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      c.member,
+				Namespace: c.config.Namespace,
+				Labels: map[string]string{
+					GroupLabelKey: c.config.Group,
+				},
+			},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       &c.identity,
+				LeaseDurationSeconds: &durationSeconds,
+				AcquireTime:          &renewTime,
+				RenewTime:            &renewTime,
+			},
+		})
+	} else if err == nil {
+		lease.Spec.HolderIdentity = &c.identity
+		lease.Spec.LeaseDurationSeconds = &durationSeconds
+		lease.Spec.RenewTime = &renewTime
+		_, err = c.leases.Update(lease)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "Can't renew lease %s/%s", c.config.Namespace, c.member)
+	}
+	c.lastRenewed = now
+	return nil
+}
+
+// listMembers returns the other members of the group whose leases
+// are alive. Leases that expired are deleted.
+func (c *coordinator) listMembers(now time.Time) ([]string, error) {
+	list, err := c.leases.List(metav1.ListOptions{
+		LabelSelector: labels.Set{GroupLabelKey: c.config.Group}.String(),
+	})
+	if err != nil {
+		return nil, errors.Wrapf(
+			err, "Can't list leases of shard group %q", c.config.Group,
+		)
+	}
+	var members []string
+	seen := make(map[string]bool, len(list.Items))
+	for idx := range list.Items {
+		lease := &list.Items[idx]
+		if lease.Name == c.member {
+			continue
+		}
+		seen[lease.Name] = true
+		if c.isAlive(lease, now) {
+			members = append(members, lease.Name)
+			continue
+		}
+		c.deleteExpired(lease)
+	}
+	for name := range c.observed {
+		if !seen[name] {
+			delete(c.observed, name)
+		}
+	}
+	return members, nil
+}
+
+// isAlive returns true if the given lease was renewed within its
+// lease duration as observed by this replica
+func (c *coordinator) isAlive(lease *coordinationv1.Lease, now time.Time) bool {
+	duration := c.config.LeaseDuration
+	if lease.Spec.LeaseDurationSeconds != nil && *lease.Spec.LeaseDurationSeconds > 0 {
+		duration = time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second
+	}
+	observed, found := c.observed[lease.Name]
+	if !found || observed.resourceVersion != lease.ResourceVersion {
+		observed = observedLease{
+			resourceVersion: lease.ResourceVersion,
+			observedAt:      now,
+		}
+		c.observed[lease.Name] = observed
+	}
+	return now.Sub(observed.observedAt) < duration
+}
+
+// deleteExpired deletes the given lease of a member that is gone. The
+// lease is not deleted if it got renewed meanwhile.
+func (c *coordinator) deleteExpired(lease *coordinationv1.Lease) {
+	err := c.leases.Delete(lease.Name, &metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{
+			ResourceVersion: &lease.ResourceVersion,
+		},
+	})
+	if apierrors.IsNotFound(err) || apierrors.IsConflict(err) {
+		return
+	}
+	if err != nil {
+		glog.Warningf(
+			"Can't delete expired lease %s/%s of shard group %q: %v",
+			c.config.Namespace, lease.Name, c.config.Group, err,
+		)
+		return
+	}
+	glog.V(4).Infof(
+		"Deleted expired lease %s/%s of shard group %q",
+		c.config.Namespace, lease.Name, c.config.Group,
+	)
+}
+
+// getRing returns the current ring
+func (c *coordinator) getRing() *Ring {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return c.ring
+}
+
+// setRing sets the given ring as the current ring & notifies the
+// subscribers if the members changed
+func (c *coordinator) setRing(ring *Ring) {
+	c.mutex.Lock()
+	previous := c.ring
+	if previous != nil && isSameMembers(previous.members, ring.members) {
+		c.mutex.Unlock()
+		return
+	}
+	c.ring = ring
+	subscribers := make([]func(Change), 0, len(c.subscribers))
+	for _, fn := range c.subscribers {
+		subscribers = append(subscribers, fn)
+	}
+	c.mutex.Unlock()
+
+	glog.Infof(
+		"Shard group %q has %d members %q: This replica is %s",
+		c.config.Group, len(ring.members), ring.members, c.member,
+	)
+	if previous == nil {
+		// nothing is subscribed before the group is joined
+		return
+	}
+	change := Change{
+		Previous: previous,
+		Current:  ring,
+		Member:   c.member,
+	}
+	for _, fn := range subscribers {
+		fn(change)
+	}
+}
+
+// isOwner returns true if the given key is owned by this replica
+func (c *coordinator) isOwner(key string) bool {
+	return c.getRing().Owner(key) == c.member
+}
+
+// subscribe invokes the given function whenever the members change
+func (c *coordinator) subscribe(fn func(Change)) func() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	id := c.nextSubscriberID
+	c.nextSubscriberID++
+	c.subscribers[id] = fn
+	return func() {
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+
+		delete(c.subscribers, id)
+	}
+}
+
+// status returns the state of sharding of this replica
+func (c *coordinator) status() Status {
+	return Status{
+		Enabled:   true,
+		Group:     c.config.Group,
+		Namespace: c.config.Namespace,
+		Member:    c.member,
+		Members:   c.getRing().Members(),
+	}
+}
+
+// withoutMember returns the given members except the given member
+func withoutMember(members []string, member string) []string {
+	var others []string
+	for _, m := range members {
+		if m != member {
+			others = append(others, m)
+		}
+	}
+	return others
+}
+
+// isSameMembers returns true if the given sorted members are same
+func isSameMembers(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for idx := range a {
+		if a[idx] != b[idx] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shard
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestCoordinatorSync(t *testing.T) {
+	config := Config{
+		Group:         "metac",
+		Namespace:     "metac",
+		LeaseDuration: 15 * time.Second,
+		RenewInterval: 5 * time.Second,
+	}
+	now := time.Now()
+	clock := func() time.Time {
+		return now
+	}
+	clientset := fake.NewSimpleClientset()
+	a := newCoordinatorFor(clientset, config, "host-a", "metac-a")
+	a.now = clock
+	b := newCoordinatorFor(clientset, config, "host-b", "metac-b")
+	b.now = clock
+
+	err := a.sync()
+	if err != nil {
+		t.Fatalf("Expected no error got %v", err)
+	}
+	if got := a.status().Members; !isSameMembers(got, []string{"metac-a"}) {
+		t.Fatalf("Expected members [metac-a] got %q", got)
+	}
+	if !a.isOwner("any-key") {
+		t.Fatalf("Expected sole member to own all keys")
+	}
+
+	var changes []Change
+	a.subscribe(func(change Change) {
+		changes = append(changes, change)
+	})
+	err = b.sync()
+	if err != nil {
+		t.Fatalf("Expected no error got %v", err)
+	}
+	err = a.sync()
+	if err != nil {
+		t.Fatalf("Expected no error got %v", err)
+	}
+	want := []string{"metac-a", "metac-b"}
+	if got := a.status().Members; !isSameMembers(got, want) {
+		t.Fatalf("Expected members %q got %q", want, got)
+	}
+	if len(changes) != 1 {
+		t.Fatalf("Expected 1 change got %d", len(changes))
+	}
+	for _, key := range []string{"key-1", "key-2", "key-3", "key-4"} {
+		if a.isOwner(key) == b.isOwner(key) {
+			t.Fatalf("Expected key %q to be owned by exactly one member", key)
+		}
+		if changes[0].IsGained(key) {
+			t.Fatalf("Expected no key to be gained by metac-a got %q", key)
+		}
+	}
+
+	// lease of b is not renewed any more
+	now = now.Add(config.LeaseDuration)
+	err = a.sync()
+	if err != nil {
+		t.Fatalf("Expected no error got %v", err)
+	}
+	if got := a.status().Members; !isSameMembers(got, []string{"metac-a"}) {
+		t.Fatalf("Expected members [metac-a] got %q", got)
+	}
+	_, err = clientset.CoordinationV1().Leases("metac").Get("metac-b", metav1.GetOptions{})
+	if err == nil {
+		t.Fatalf("Expected expired lease to be deleted")
+	}
+	if len(changes) != 2 {
+		t.Fatalf("Expected 2 changes got %d", len(changes))
+	}
+}
+
+func TestCoordinatorSyncWithRenewFailure(t *testing.T) {
+	config := Config{
+		Group:         "metac",
+		Namespace:     "metac",
+		LeaseDuration: 15 * time.Second,
+		RenewInterval: 5 * time.Second,
+	}
+	now := time.Now()
+	clientset := fake.NewSimpleClientset()
+	a := newCoordinatorFor(clientset, config, "host-a", "metac-a")
+	a.now = func() time.Time {
+		return now
+	}
+	err := a.sync()
+	if err != nil {
+		t.Fatalf("Expected no error got %v", err)
+	}
+
+	clientset.PrependReactor(
+		"get", "leases",
+		func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, errors.New("API server is down")
+		},
+	)
+	// keys are retained while the lease is not expired
+	now = now.Add(config.RenewInterval)
+	err = a.sync()
+	if err == nil {
+		t.Fatalf("Expected error got none")
+	}
+	if !a.isOwner("any-key") {
+		t.Fatalf("Expected keys to be owned before the lease expires")
+	}
+
+	// keys are released once the lease expires
+	now = now.Add(config.LeaseDuration)
+	err = a.sync()
+	if err == nil {
+		t.Fatalf("Expected error got none")
+	}
+	if a.isOwner("any-key") {
+		t.Fatalf("Expected no key to be owned after the lease expires")
+	}
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shard
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+)
+
+// virtualNodes is the number of points that each member gets on the
+// ring; more points spread the keys more evenly across the members
+const virtualNodes = 128
+
+// Ring partitions keys among a set of members via consistent hashing.
+// Only the keys of a joining or leaving member move when the members
+// change.
+type Ring struct {
+	// sorted names of the members
+	members []string
+
+	// sorted points on the ring & the members that own these
+	points []uint32
+	owners map[uint32]string
+}
+
+// NewRing returns a ring of the given members
+//
+// NOTE:
+//	Rings built from the same members assign the same owner to a
+// key irrespective of the order of the given members
+func NewRing(members []string) *Ring {
+	r := &Ring{
+		members: append([]string(nil), members...),
+		owners:  make(map[uint32]string, len(members)*virtualNodes),
+	}
+	sort.Strings(r.members)
+	for _, member := range r.members {
+		for i := 0; i < virtualNodes; i++ {
+			point := hash(member + "#" + strconv.Itoa(i))
+			if _, found := r.owners[point]; found {
+				// collisions are won by the member that sorts first
+				continue
+			}
+			r.owners[point] = member
+			r.points = append(r.points, point)
+		}
+	}
+	sort.Slice(r.points, func(i, j int) bool {
+		return r.points[i] < r.points[j]
+	})
+	return r
+}
+
+// Members returns the sorted names of the members of this ring
+func (r *Ring) Members() []string {
+	if r == nil {
+		return nil
+	}
+	return append([]string(nil), r.members...)
+}
+
+// Owner returns the member that owns the given key. Empty string is
+// returned if this ring has no members.
+func (r *Ring) Owner(key string) string {
+	if r == nil || len(r.points) == 0 {
+		return ""
+	}
+	point := hash(key)
+	idx := sort.Search(len(r.points), func(i int) bool {
+		return r.points[i] >= point
+	})
+	if idx == len(r.points) {
+		// wrap around the ring
+		idx = 0
+	}
+	return r.owners[r.points[idx]]
+}
+
+// hash returns the point of the given string on the ring
+//
+// NOTE:
+//	FNV hashes of strings that differ only in their suffix e.g. the
+// names of the virtual nodes are close to each other. These are
+// mixed further to spread the points across the ring.
+func hash(s string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(s))
+	x := h.Sum32()
+	x ^= x >> 16
+	x *= 0x85ebca6b
+	x ^= x >> 13
+	x *= 0xc2b2ae35
+	x ^= x >> 16
+	return x
+}
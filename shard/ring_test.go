@@ -0,0 +1,95 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shard
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRingOwner(t *testing.T) {
+	var tests = map[string]struct {
+		members []string
+		isEmpty bool
+	}{
+		"no members": {
+			isEmpty: true,
+		},
+		"single member": {
+			members: []string{"metac-a"},
+		},
+		"multiple members": {
+			members: []string{"metac-c", "metac-a", "metac-b"},
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			ring := NewRing(mock.members)
+			reversed := make([]string, 0, len(mock.members))
+			for idx := len(mock.members) - 1; idx >= 0; idx-- {
+				reversed = append(reversed, mock.members[idx])
+			}
+			other := NewRing(reversed)
+			counts := make(map[string]int)
+			for idx := 0; idx < 3000; idx++ {
+				key := fmt.Sprintf("test/gctl/v1:Pod:ns:pod-%d", idx)
+				owner := ring.Owner(key)
+				if mock.isEmpty && owner != "" {
+					t.Fatalf("Expected no owner got %q", owner)
+				}
+				if owner != other.Owner(key) {
+					t.Fatalf(
+						"Expected same owner of %q got %q & %q",
+						key, owner, other.Owner(key),
+					)
+				}
+				counts[owner]++
+			}
+			if mock.isEmpty {
+				return
+			}
+			for _, member := range mock.members {
+				// each member gets a fair share of the keys
+				if counts[member] < 3000/len(mock.members)/2 {
+					t.Fatalf("Expected fair share of keys got %v", counts)
+				}
+			}
+		})
+	}
+}
+
+func TestRingOwnerOnJoin(t *testing.T) {
+	before := NewRing([]string{"metac-a", "metac-b"})
+	after := NewRing([]string{"metac-a", "metac-b", "metac-c"})
+	var moved int
+	for idx := 0; idx < 3000; idx++ {
+		key := fmt.Sprintf("test/gctl/v1:Pod:ns:pod-%d", idx)
+		from, to := before.Owner(key), after.Owner(key)
+		if from == to {
+			continue
+		}
+		if to != "metac-c" {
+			t.Fatalf("Expected %q to move to metac-c got %q", key, to)
+		}
+		moved++
+	}
+	if moved == 0 || moved > 3000/2 {
+		t.Fatalf("Expected about a third of the keys to move got %d", moved)
+	}
+}
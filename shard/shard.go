@@ -0,0 +1,194 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package shard partitions the reconciles of the controllers among
+// the replicas of metac. Every replica holds a lease of its own; the
+// replicas whose leases are renewed form a consistent hash ring that
+// decides the replica that reconciles a watch.
+package shard
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/rest"
+)
+
+// GroupLabelKey is the label set against the leases of the members of
+// a shard group. Its value is the name of the group.
+const GroupLabelKey = "metac.openebs.io/shard-group"
+
+// Config represents the tunables of sharding
+type Config struct {
+	// name of the group of replicas that share the reconciles; this
+	// is the prefix of the names of the leases of the members &
+	// sharding is disabled if this is not set
+	Group string
+
+	// namespace of the leases of the members
+	Namespace string
+
+	// duration after which a member whose lease is not renewed is
+	// considered gone & its watches are taken over by the others
+	LeaseDuration time.Duration
+
+	// interval at which a member renews its lease & looks for the
+	// members that joined or left
+	RenewInterval time.Duration
+}
+
+// validate returns error if this config is invalid
+func (c Config) validate() error {
+	if c.Namespace == "" {
+		return errors.Errorf("Invalid shard group %q: Namespace can't be empty", c.Group)
+	}
+	if errs := validation.IsDNS1123Label(c.Group); len(errs) != 0 {
+		return errors.Errorf("Invalid shard group %q: %v", c.Group, errs)
+	}
+	if c.RenewInterval <= 0 || c.LeaseDuration <= c.RenewInterval {
+		return errors.Errorf(
+			"Invalid shard group %q: Want lease duration %s > renew interval %s > 0",
+			c.Group, c.LeaseDuration, c.RenewInterval,
+		)
+	}
+	return nil
+}
+
+// Change represents a change of the members of the shard group
+type Change struct {
+	// rings before & after the change
+	Previous *Ring
+	Current  *Ring
+
+	// name of the member i.e. lease of this replica
+	Member string
+}
+
+// IsGained returns true if the given key is owned by this replica
+// after this change & was not owned before it
+func (c Change) IsGained(key string) bool {
+	return c.Current.Owner(key) == c.Member && c.Previous.Owner(key) != c.Member
+}
+
+// Status represents the state of sharding of this replica
+type Status struct {
+	// true if the reconciles are sharded
+	Enabled bool `json:"enabled"`
+
+	// group, namespace & lease of this replica
+	Group     string `json:"group,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Member    string `json:"member,omitempty"`
+
+	// sorted leases of the replicas that share the reconciles; this
+	// excludes this replica if it failed to renew its lease
+	Members []string `json:"members,omitempty"`
+}
+
+// current is the running coordinator if sharding is enabled; this is
+// accessed atomically
+var current atomic.Value
+
+// getCoordinator returns the running coordinator or nil
+func getCoordinator() *coordinator {
+	c, _ := current.Load().(*coordinator)
+	return c
+}
+
+// Start joins the shard group of the given config & returns once the
+// members of the group are known. Keys are not sharded if the config
+// does not set a group. The returned function leaves the group.
+//
+// NOTE:
+//	This needs to be invoked before the controllers are started
+func Start(config *rest.Config, shard Config) (func(), error) {
+	if shard.Group == "" {
+		return func() {}, nil
+	}
+	c, err := newCoordinator(config, shard)
+	if err != nil {
+		return nil, err
+	}
+	err = c.start()
+	if err != nil {
+		return nil, err
+	}
+	current.Store(c)
+	return c.stop, nil
+}
+
+// IsEnabled returns true if the reconciles are sharded
+func IsEnabled() bool {
+	return getCoordinator() != nil
+}
+
+// IsOwner returns true if the given key is reconciled by this
+// replica. This is always true if sharding is not enabled.
+func IsOwner(key string) bool {
+	c := getCoordinator()
+	if c == nil {
+		return true
+	}
+	return c.isOwner(key)
+}
+
+// Subscribe invokes the given function whenever the members of the
+// shard group change. The returned function unsubscribes. This is a
+// no-op if sharding is not enabled.
+//
+// NOTE:
+//	The given function should not block since the members are
+// synced again only after it returns
+func Subscribe(fn func(Change)) func() {
+	c := getCoordinator()
+	if c == nil {
+		return func() {}
+	}
+	return c.subscribe(fn)
+}
+
+// GetStatus returns the state of sharding of this replica
+func GetStatus() Status {
+	c := getCoordinator()
+	if c == nil {
+		return Status{}
+	}
+	return c.status()
+}
+
+// Handler returns the http handler that serves the state of
+// sharding of this replica as JSON
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Only GET is supported", http.StatusMethodNotAllowed)
+			return
+		}
+		data, err := json.Marshal(GetStatus())
+		if err != nil {
+			glog.Errorf("Can't marshal shard status: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	})
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package start
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+
+	"openebs.io/metac/admission"
+	"openebs.io/metac/server"
+)
+
+// AdmissionConfig represents the tunables of the validating admission
+// webhook that is served by this binary
+type AdmissionConfig struct {
+	// address at which the webhook is served; the webhook is not
+	// served if this is empty
+	Addr string
+
+	// files of the certificate & the key with which the webhook is
+	// served over TLS
+	CertFile string
+	KeyFile  string
+
+	// duration within which a connection to every webhook of a
+	// controller needs to be opened; webhooks are not dialled if
+	// this is 0
+	HookDialTimeout time.Duration
+}
+
+// validate returns error if this config is invalid
+func (c AdmissionConfig) validate() error {
+	if c.CertFile == "" || c.KeyFile == "" {
+		return errors.Errorf(
+			"Invalid admission webhook: TLS cert file %q & key file %q can't be empty",
+			c.CertFile, c.KeyFile,
+		)
+	}
+	if c.HookDialTimeout < 0 {
+		return errors.Errorf(
+			"Invalid admission webhook: Hook dial timeout must be >= 0: Got %s",
+			c.HookDialTimeout,
+		)
+	}
+	return nil
+}
+
+// startAdmission serves the validating admission webhook of the given
+// config. The returned function stops serving this webhook.
+//
+// NOTE:
+//	The certificate is loaded once. The binary needs to be restarted
+// to pick up a renewed certificate.
+func startAdmission(mserver *server.Server, c AdmissionConfig) (func(), error) {
+	if c.Addr == "" {
+		return func() {}, nil
+	}
+	err := c.validate()
+	if err != nil {
+		return nil, err
+	}
+	cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Can't load admission webhook certificate")
+	}
+	handler, stopDiscovery, err := mserver.StartAdmissionHandler(c.HookDialTimeout)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Can't start admission webhook")
+	}
+	mux := http.NewServeMux()
+	mux.Handle(admission.Path, handler)
+	srv := &http.Server{
+		Addr:    c.Addr,
+		Handler: mux,
+		TLSConfig: &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			MinVersion:   tls.VersionTLS12,
+		},
+	}
+	go func() {
+		err := srv.ListenAndServeTLS("", "")
+		if err != http.ErrServerClosed {
+			glog.Errorf("Error serving admission webhook: %v", err)
+		}
+	}()
+	glog.Infof("Serving admission webhook at %s%s", c.Addr, admission.Path)
+	return func() {
+		srv.Shutdown(context.Background())
+		stopDiscovery()
+	}, nil
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package start
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+// LeaderElectionConfig represents the tunables of the lease based
+// leader election of this binary
+type LeaderElectionConfig struct {
+	// name & namespace of the lease that is held by the leader
+	LeaseName      string
+	LeaseNamespace string
+
+	// duration for which the standby instances wait before they
+	// take over a lease that is not renewed
+	LeaseDuration time.Duration
+
+	// duration within which the leader should renew the lease; the
+	// leader stops leading if it fails to renew within this duration
+	RenewDeadline time.Duration
+
+	// interval between the attempts to acquire or renew the lease
+	RetryPeriod time.Duration
+}
+
+// validate returns error if this config is invalid
+func (c LeaderElectionConfig) validate() error {
+	if c.LeaseName == "" || c.LeaseNamespace == "" {
+		return errors.Errorf(
+			"Invalid leader election: Lease name %q & namespace %q can't be empty",
+			c.LeaseName, c.LeaseNamespace,
+		)
+	}
+	if c.RetryPeriod <= 0 || c.RenewDeadline <= c.RetryPeriod ||
+		c.LeaseDuration <= c.RenewDeadline {
+		return errors.Errorf(
+			"Invalid leader election: Want lease duration %s > renew deadline %s > retry period %s > 0",
+			c.LeaseDuration, c.RenewDeadline, c.RetryPeriod,
+		)
+	}
+	return nil
+}
+
+// startAsLeader invokes the given start function once this binary
+// acquires the lease of the given config. This does not wait for the
+// lease to be acquired. The returned function stops whatever got
+// started & then releases the lease.
+//
+// NOTE:
+//	This binary exits if it stops leading without being asked to i.e.
+// if it fails to renew the lease. This avoids running the controllers
+// alongside the new leader.
+func startAsLeader(
+	config *rest.Config, election LeaderElectionConfig, start func() (func(), error),
+) (func(), error) {
+	err := election.validate()
+	if err != nil {
+		return nil, err
+	}
+	// a hung request should not exhaust the renew deadline
+	config = rest.CopyConfig(config)
+	config.Timeout = election.RenewDeadline / 2
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Can't create clientset for leader election")
+	}
+	return startAsLeaderWithClientset(clientset, election, start)
+}
+
+// startAsLeaderWithClientset invokes the given start function once
+// this binary acquires the lease of the given config via the given
+// clientset. The config is expected to be valid.
+func startAsLeaderWithClientset(
+	clientset kubernetes.Interface, election LeaderElectionConfig, start func() (func(), error),
+) (func(), error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, errors.Wrapf(err, "Can't get identity for leader election")
+	}
+	// unique even if instances share the hostname e.g. after restarts
+	identity := hostname + "_" + string(uuid.NewUUID())
+	lock, err := resourcelock.New(
+		resourcelock.LeasesResourceLock,
+		election.LeaseNamespace,
+		election.LeaseName,
+		clientset.CoreV1(),
+		clientset.CoordinationV1(),
+		resourcelock.ResourceLockConfig{Identity: identity},
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Can't create lock for leader election")
+	}
+
+	var (
+		// guards the fields below
+		mutex sync.Mutex
+
+		// stops whatever got started once this binary is leading
+		stop func()
+
+		// flags if this binary is asked to stop
+		isStopping bool
+	)
+	elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		Name:            election.LeaseName,
+		LeaseDuration:   election.LeaseDuration,
+		RenewDeadline:   election.RenewDeadline,
+		RetryPeriod:     election.RetryPeriod,
+		ReleaseOnCancel: true,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				mutex.Lock()
+				defer mutex.Unlock()
+
+				if isStopping {
+					return
+				}
+				glog.Infof(
+					"Acquired lease %s/%s as %s: Will start",
+					election.LeaseNamespace, election.LeaseName, identity,
+				)
+				var err error
+				stop, err = start()
+				if err != nil {
+					glog.Fatal(err)
+				}
+			},
+			OnStoppedLeading: func() {
+				mutex.Lock()
+				defer mutex.Unlock()
+
+				if isStopping {
+					return
+				}
+				glog.Fatalf(
+					"Lost lease %s/%s as %s: Exiting to let the new leader take over",
+					election.LeaseNamespace, election.LeaseName, identity,
+				)
+			},
+			OnNewLeader: func(leader string) {
+				if leader != identity {
+					glog.Infof(
+						"Lease %s/%s is held by %s: Waiting to acquire it",
+						election.LeaseNamespace, election.LeaseName, leader,
+					)
+				}
+			},
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "Can't create leader elector")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	doneCh := make(chan struct{})
+	go func() {
+		defer close(doneCh)
+		elector.Run(ctx)
+	}()
+	glog.Infof(
+		"Will start once lease %s/%s is acquired as %s",
+		election.LeaseNamespace, election.LeaseName, identity,
+	)
+
+	return func() {
+		mutex.Lock()
+		isStopping = true
+		if stop != nil {
+			// stop before releasing the lease to avoid running
+			// alongside the next leader
+			stop()
+		}
+		mutex.Unlock()
+
+		// releases the lease if held
+		cancel()
+		<-doneCh
+	}, nil
+}
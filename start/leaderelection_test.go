@@ -0,0 +1,204 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package start
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+// newTestLeaderElectionConfig returns a valid leader election config
+// having short durations
+func newTestLeaderElectionConfig() LeaderElectionConfig {
+	return LeaderElectionConfig{
+		LeaseName:      "metac",
+		LeaseNamespace: "metac",
+		LeaseDuration:  2 * time.Second,
+		RenewDeadline:  time.Second,
+		RetryPeriod:    100 * time.Millisecond,
+	}
+}
+
+// getLeaseHolder returns the holder of the test lease
+func getLeaseHolder(t *testing.T, clientset *fake.Clientset) string {
+	lease, err := clientset.CoordinationV1().Leases("metac").Get("metac", metav1.GetOptions{})
+	if err != nil {
+		t.Errorf("Can't get lease: %v", err)
+		return ""
+	}
+	if lease.Spec.HolderIdentity == nil {
+		return ""
+	}
+	return *lease.Spec.HolderIdentity
+}
+
+func TestLeaderElectionConfigValidate(t *testing.T) {
+	var tests = map[string]struct {
+		update func(*LeaderElectionConfig)
+		isErr  bool
+	}{
+		"valid": {
+			update: func(*LeaderElectionConfig) {},
+		},
+		"empty lease name": {
+			update: func(c *LeaderElectionConfig) { c.LeaseName = "" },
+			isErr:  true,
+		},
+		"empty lease namespace": {
+			update: func(c *LeaderElectionConfig) { c.LeaseNamespace = "" },
+			isErr:  true,
+		},
+		"zero retry period": {
+			update: func(c *LeaderElectionConfig) { c.RetryPeriod = 0 },
+			isErr:  true,
+		},
+		"renew deadline equal to retry period": {
+			update: func(c *LeaderElectionConfig) { c.RenewDeadline = c.RetryPeriod },
+			isErr:  true,
+		},
+		"lease duration equal to renew deadline": {
+			update: func(c *LeaderElectionConfig) { c.LeaseDuration = c.RenewDeadline },
+			isErr:  true,
+		},
+		"lease duration less than retry period": {
+			update: func(c *LeaderElectionConfig) { c.LeaseDuration = c.RetryPeriod / 2 },
+			isErr:  true,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			config := newTestLeaderElectionConfig()
+			mock.update(&config)
+			err := config.validate()
+			if mock.isErr != (err != nil) {
+				t.Fatalf("Expected error %t got %v", mock.isErr, err)
+			}
+		})
+	}
+}
+
+func TestStartAsLeaderStopsBeforeReleasingLease(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	startedCh := make(chan struct{})
+	var holderAtStop string
+	stop, err := startAsLeaderWithClientset(
+		clientset,
+		newTestLeaderElectionConfig(),
+		func() (func(), error) {
+			close(startedCh)
+			return func() {
+				holderAtStop = getLeaseHolder(t, clientset)
+			}, nil
+		},
+	)
+	if err != nil {
+		t.Fatalf("Expected no error got %v", err)
+	}
+	select {
+	case <-startedCh:
+	case <-time.After(5 * time.Second):
+		stop()
+		t.Fatalf("Expected start once lease is acquired got none")
+	}
+	holder := getLeaseHolder(t, clientset)
+	if holder == "" {
+		t.Fatalf("Expected lease to be held after start got none")
+	}
+
+	// this exits the test binary if the guard against the stop of
+	// leading fails
+	stop()
+	if holderAtStop != holder {
+		t.Fatalf("Expected lease held by %q at stop got %q", holder, holderAtStop)
+	}
+	if got := getLeaseHolder(t, clientset); got != "" {
+		t.Fatalf("Expected lease to be released after stop got holder %q", got)
+	}
+}
+
+func TestStartAsLeaderDoesNotStartOnceStopping(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+
+	// lease gets acquired only after this binary is asked to stop
+	acquiringCh := make(chan struct{})
+	proceedCh := make(chan struct{})
+	var once sync.Once
+	clientset.PrependReactor(
+		"create", "leases",
+		func(clienttesting.Action) (bool, runtime.Object, error) {
+			once.Do(func() { close(acquiringCh) })
+			<-proceedCh
+			return false, nil, nil
+		},
+	)
+	var mutex sync.Mutex
+	var isStarted bool
+	stop, err := startAsLeaderWithClientset(
+		clientset,
+		newTestLeaderElectionConfig(),
+		func() (func(), error) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			isStarted = true
+			return func() {}, nil
+		},
+	)
+	if err != nil {
+		t.Fatalf("Expected no error got %v", err)
+	}
+	select {
+	case <-acquiringCh:
+	case <-time.After(5 * time.Second):
+		close(proceedCh)
+		stop()
+		t.Fatalf("Expected an attempt to acquire lease got none")
+	}
+	stoppedCh := make(chan struct{})
+	go func() {
+		defer close(stoppedCh)
+		stop()
+	}()
+	time.Sleep(100 * time.Millisecond)
+	close(proceedCh)
+	select {
+	case <-stoppedCh:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Expected stop to return got none")
+	}
+
+	// start is invoked asynchronously once the lease is acquired
+	err = wait.Poll(10*time.Millisecond, 200*time.Millisecond, func() (bool, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return isStarted, nil
+	})
+	if err == nil {
+		t.Fatalf("Expected no start after stop got start")
+	}
+	if got := getLeaseHolder(t, clientset); got != "" {
+		t.Fatalf("Expected lease to be released after stop got holder %q", got)
+	}
+}
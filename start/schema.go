@@ -0,0 +1,108 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package start
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"openebs.io/metac/config"
+	"openebs.io/metac/controller/composite"
+	"openebs.io/metac/controller/decorator"
+	"openebs.io/metac/controller/generic"
+)
+
+// schemaExitError is the exit code when the schema can't be generated
+// e.g. due to wrong usage
+const schemaExitError = 2
+
+// schemaTypes returns the go types whose schemas can be generated
+// anchored by their names. These are the metac config types & the
+// payloads of their sync hooks.
+func schemaTypes() map[string]reflect.Type {
+	types := config.ConfigTypes()
+	types["GenericControllerSyncRequest"] = reflect.TypeOf(generic.SyncHookRequest{})
+	types["GenericControllerSyncResponse"] = reflect.TypeOf(generic.SyncHookResponse{})
+	types["CompositeControllerSyncRequest"] = reflect.TypeOf(composite.SyncHookRequest{})
+	types["CompositeControllerSyncResponse"] = reflect.TypeOf(composite.SyncHookResponse{})
+	types["DecoratorControllerSyncRequest"] = reflect.TypeOf(decorator.SyncHookRequest{})
+	types["DecoratorControllerSyncResponse"] = reflect.TypeOf(decorator.SyncHookResponse{})
+	return types
+}
+
+// Schema writes the schema of the metac config types or of the sync
+// hook payloads. This implements the schema subcommand & returns the
+// exit code of this subcommand.
+//
+// NOTE:
+//	Schema of all the config types is written if no type names are
+// provided
+func Schema(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("schema", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	format := flags.String(
+		"format",
+		string(config.SchemaFormatJSONSchema),
+		"Format of the schema; one of jsonschema or openapi",
+	)
+	all := schemaTypes()
+	var names []string
+	for name := range all {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: metac schema [flags] [type...]\n")
+		fmt.Fprintf(stderr, "Types: %s\n", strings.Join(names, ", "))
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return schemaExitError
+	}
+
+	types := config.ConfigTypes()
+	if flags.NArg() != 0 {
+		types = make(map[string]reflect.Type, flags.NArg())
+		for _, name := range flags.Args() {
+			typ, found := all[name]
+			if !found {
+				fmt.Fprintf(stderr, "Unknown type %q\n", name)
+				flags.Usage()
+				return schemaExitError
+			}
+			types[name] = typ
+		}
+	}
+
+	doc, err := config.GenerateSchema(config.SchemaFormat(*format), types)
+	if err != nil {
+		fmt.Fprintf(stderr, "%v\n", err)
+		return schemaExitError
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to marshal schema: %v\n", err)
+		return schemaExitError
+	}
+	fmt.Fprintf(stdout, "%s\n", data)
+	return 0
+}
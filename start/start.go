@@ -20,27 +20,58 @@ import (
 	"context"
 	"flag"
 	"net/http"
+	"net/http/pprof"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	//"go.opencensus.io/exporter/prometheus"
 	"contrib.go.opencensus.io/exporter/prometheus"
 	"github.com/golang/glog"
+	"github.com/pkg/errors"
 	"go.opencensus.io/stats/view"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+	"openebs.io/metac/audit"
+	metacconfig "openebs.io/metac/config"
+	"openebs.io/metac/controller/common"
+	"openebs.io/metac/controller/generic"
+	dynamicclientset "openebs.io/metac/dynamic/clientset"
+	dynamicdiscovery "openebs.io/metac/dynamic/discovery"
+	dynamicinformer "openebs.io/metac/dynamic/informer"
+	"openebs.io/metac/logging"
 	"openebs.io/metac/server"
+	"openebs.io/metac/shard"
+	"openebs.io/metac/tracing"
 )
 
 var (
 	discoveryInterval = flag.Duration(
 		"discovery-interval",
-		30*time.Second,
-		"How often to refresh discovery cache to pick up newly-installed resources",
+		10*time.Minute,
+		"How often to refresh the entire discovery cache. Newly-installed resources are picked up when their CRDs or APIServices change",
+	)
+	discoveryJitter = flag.Float64(
+		"discovery-jitter",
+		0,
+		"Fraction of the discovery interval by which each full discovery is randomly delayed e.g. 0.1 delays by up to 10%",
+	)
+	discoveryRequestTimeout = flag.Duration(
+		"discovery-timeout",
+		32*time.Second,
+		"Maximum time taken by a single discovery request",
+	)
+	discoveryCachePath = flag.String(
+		"discovery-cache-path",
+		"",
+		"File to persist discovered resources. Persisted resources are loaded at startup before the first discovery completes",
 	)
 	informerRelist = flag.Duration(
 		"cache-flush-interval",
@@ -52,6 +83,16 @@ var (
 		":9999",
 		"The address to bind the debug http endpoints",
 	)
+	enablePprof = flag.Bool(
+		"enable-pprof",
+		false,
+		"When true serves the profiles of net/http/pprof at /debug/pprof/ of the debug http endpoints; these require the debug token",
+	)
+	debugTokenFile = flag.String(
+		"debug-token-file",
+		"",
+		"Path of the file with the bearer token that is required to query the /debug/state, /debug/history & /debug/pprof/ endpoints. These endpoints are forbidden if this is not set.",
+	)
 	clientConfigPath = flag.String(
 		"client-config-path",
 		"",
@@ -73,31 +114,645 @@ var (
 		10,
 		"Allowed burst queries for client-go (default 10)",
 	)
+	clientResourceQPS = flag.Float64(
+		"client-resource-qps",
+		0,
+		`Number of queries per second allowed against a single resource e.g.
+		 pods; 0 allows half of client-go-qps; negative value disables this limit`,
+	)
+	clientResourceBurst = flag.Int(
+		"client-resource-burst",
+		0,
+		`Allowed burst queries against a single resource e.g. pods; 0 allows
+		 half of client-go-burst; negative value disables this limit`,
+	)
+	clientResourceRateLimits = flag.String(
+		"client-resource-rate-limits",
+		"",
+		`Comma separated list of resource=qps:burst that override the limits of
+		 the given resources e.g. pods.v1=10:20,deployments.apps/v1=5:10`,
+	)
+	controllerClientQPS = flag.Float64(
+		"controller-client-qps",
+		0,
+		`Number of queries per second allowed to each GenericController that
+		 does not set its own clientQPS; 0 lets these controllers share the
+		 client-go-qps limit`,
+	)
+	controllerClientBurst = flag.Int(
+		"controller-client-burst",
+		0,
+		`Allowed burst queries of each GenericController that does not set its
+		 own clientBurst; 0 allows twice the controller-client-qps`,
+	)
+	controllerClientTimeout = flag.Duration(
+		"controller-client-timeout",
+		0,
+		`Time within which every request of a GenericController that does not
+		 set its own clientTimeout should complete; 0 disables this timeout`,
+	)
+	dryRun = flag.Bool(
+		"dry-run",
+		false,
+		`When true makes every create, update, patch & delete of the watches &
+		 attachments of all the controllers a dry run; nothing gets persisted`,
+	)
 	runAsLocal = flag.Bool(
 		"run-as-local",
 		false,
 		`When true enables metac to run by looking up its config file;
-		 Metac will no longer be dependent on its CRDs and CRs`,
+		 Metac will no longer be dependent on its CRDs and CRs; sending SIGHUP
+		 to metac reloads its configs`,
 	)
 	metacConfigPath = flag.String(
 		"metac-config-path",
 		"/etc/config/metac/",
 		`Path to metac config file to let metac run as a self contained binary;
-		 Needs run-as-local set to true`,
+		 Needs run-as-local set to true; may be a directory, a file or a glob
+		 pattern; directories are walked recursively; configs are reloaded when
+		 files at this path change & when metac receives SIGHUP`,
+	)
+	metacConfigInclude = flag.String(
+		"metac-config-include",
+		"",
+		`Comma separated list of glob patterns of the files at metac-config-path
+		 that are loaded; patterns are matched against the relative path as well
+		 as the name of the file; if not specified, all files are loaded`,
+	)
+	metacConfigExclude = flag.String(
+		"metac-config-exclude",
+		"",
+		`Comma separated list of glob patterns of the files at metac-config-path
+		 that are not loaded; this has higher priority than metac-config-include`,
+	)
+	metacConfigMapSelector = flag.String(
+		"metac-config-map-selector",
+		"",
+		`Label selector of the config maps that have the metac configs;
+		 Needs run-as-local set to true; has higher priority than
+		 metac-config-path; configs are reloaded when these config maps change`,
+	)
+	metacConfigMapNamespace = flag.String(
+		"metac-config-map-namespace",
+		"metac",
+		`Namespace of the config maps selected by metac-config-map-selector`,
+	)
+	configURL = flag.String(
+		"config-url",
+		"",
+		`HTTP(S) endpoint serving the metac config bundle; Needs run-as-local
+		 set to true; has higher priority than metac-config-path; the endpoint
+		 is polled via conditional requests & configs are reloaded when these change`,
+	)
+	configURLPollInterval = flag.Duration(
+		"config-url-poll-interval",
+		1*time.Minute,
+		"How often to poll config-url for changed configs",
+	)
+	configGitRepo = flag.String(
+		"config-git-repo",
+		"",
+		`Git repository having the metac configs; Needs run-as-local set to true;
+		 has higher priority than metac-config-path; configs are reloaded when
+		 config-git-ref moves`,
+	)
+	configGitRef = flag.String(
+		"config-git-ref",
+		"HEAD",
+		"Branch, tag or commit of config-git-repo having the metac configs",
+	)
+	configGitSubPath = flag.String(
+		"config-git-subpath",
+		"",
+		"Path relative to the root of config-git-repo having the metac config files",
+	)
+	configGitDir = flag.String(
+		"config-git-dir",
+		"",
+		`Local directory where config-git-repo is checked out; a temporary
+		 directory is used if not specified`,
+	)
+	configGitPollInterval = flag.Duration(
+		"config-git-poll-interval",
+		1*time.Minute,
+		"How often to pull config-git-ref for changed configs",
+	)
+	substituteConfigVars = flag.Bool(
+		"substitute-config-vars",
+		false,
+		`When true substitutes the variables referred as ${VAR} or ${VAR:-default}
+		 in the configs loaded with run-as-local set to true; variables are
+		 resolved from METAC_CLUSTER_NAME, METAC_NAMESPACE, METAC_POD_NAME &
+		 environment variables in that order; $$ is substituted with $`,
+	)
+	configValues = flag.String(
+		"config-values",
+		"",
+		`Path to a yaml file of values against which the configs loaded with
+		 run-as-local set to true are rendered as go templates; values are referred
+		 as {{ .Values.key }}; rendering happens before variables are substituted`,
+	)
+	clusterName = flag.String(
+		"cluster-name",
+		"",
+		"Name of the cluster that can be referred in the configs as ${METAC_CLUSTER_NAME}",
+	)
+	configDuplicatePolicy = flag.String(
+		"config-duplicate-policy",
+		string(metacconfig.DuplicatePolicyFail),
+		`What happens when configs loaded with run-as-local set to true have the
+		 same kind, namespace & name; Fail does not load the configs & reports the
+		 sources of the duplicates; Override lets a config override the ones loaded
+		 earlier e.g. of 20-override.yaml over 10-base.yaml & logs a warning`,
+	)
+	enableProfiles = flag.String(
+		"enable-profiles",
+		"",
+		`Comma separated list of config profiles whose GenericControllers are run
+		 along with the ones of the default profile; needs run-as-local set to true;
+		 a config sets its profile via metac.openebs.io/profile label or by being
+		 at profiles/<profile>/ of metac-config-path; '*' enables all profiles`,
+	)
+	disableProfiles = flag.String(
+		"disable-profiles",
+		"",
+		`Comma separated list of config profiles whose GenericControllers are not
+		 run; this has higher priority than enable-profiles; configs that do not
+		 set any profile belong to the default profile`,
+	)
+	namespaces = flag.String(
+		"namespaces",
+		"",
+		`Comma separated list of namespaces that all the controllers & informers
+		 are restricted to; if not specified, all namespaces are considered`,
+	)
+	excludeNamespaces = flag.String(
+		"exclude-namespaces",
+		"",
+		`Comma separated list of namespaces that all the controllers & informers
+		 ignore; this has higher priority than namespaces`,
+	)
+	clientProtobuf = flag.Bool(
+		"client-protobuf",
+		false,
+		`When true lists & watches built-in resources of read only attachments
+		 as protobuf instead of json; custom resources are always served as json`,
+	)
+	cacheListPageSize = flag.Int64(
+		"cache-list-page-size",
+		0,
+		`Number of resources fetched per request when informers list resources;
+		 0 lists all the resources of a kind in a single response`,
+	)
+	informerIdleTTL = flag.Duration(
+		"informer-idle-ttl",
+		0,
+		`Duration for which an informer without any controller using it is kept
+		 running; an informer used again within this duration is reused along
+		 with its cache; 0 stops such an informer immediately`,
+	)
+	cacheStripManagedFields = flag.Bool(
+		"cache-strip-managed-fields",
+		false,
+		`When true removes metadata.managedFields of the resources before these
+		 get cached; GenericControllers that need managed fields fail to start`,
+	)
+	cacheStripLastApplied = flag.Bool(
+		"cache-strip-last-applied",
+		false,
+		`When true removes the last applied configuration annotation set by
+		 kubectl apply from the resources before these get cached`,
+	)
+	cacheStripPaths = flag.String(
+		"cache-strip-paths",
+		"",
+		`Comma separated list of dot separated paths of the fields that are removed
+		 from the resources before these get cached e.g. status.history`,
+	)
+	targetClusters = flag.String(
+		"target-clusters",
+		"",
+		`Comma separated list of remote clusters whose resources are discovered
+		 separately; each is either name=path/to/kubeconfig or
+		 name=secret:namespace/secret-name[/key] where key defaults to kubeconfig`,
+	)
+	applyStrategy = flag.String(
+		"apply-strategy",
+		string(v1alpha1.ApplyStrategyLastApplied),
+		`Strategy used by GenericControllers to apply their attachments;
+		 one of LastApplied, ServerSideApply or ManagedFields; a GenericController may
+		 override this via its spec.applyStrategy`,
+	)
+	hookDefaultsPath = flag.String(
+		"hook-defaults-path",
+		"",
+		`Path to a yaml file of the default timeout, retries, tls & headers of all
+		 the webhooks; a webhook inherits the settings it does not set; hook-timeout,
+		 hook-retries & hook-headers override the ones set in this file`,
+	)
+	hookTimeout = flag.Duration(
+		"hook-timeout",
+		0,
+		"Default timeout of the webhooks that do not set their timeout; defaults to 10s",
+	)
+	hookRetries = flag.Int(
+		"hook-retries",
+		0,
+		`Default number of times a webhook invocation that fails with a transient
+		 error is retried; applies to the webhooks that do not set their retries`,
+	)
+	hookHeaders = flag.String(
+		"hook-headers",
+		"",
+		`Comma separated list of name=value headers that are set against every
+		 webhook request; a webhook may override these via its headers`,
+	)
+	tracingEndpoint = flag.String(
+		"tracing-otlp-endpoint",
+		"",
+		`OTLP/HTTP endpoint of the OpenTelemetry collector to which the traces of
+		 the reconciles are exported e.g. http://otel-collector:4318; tracing is
+		 disabled if this is not set`,
+	)
+	tracingSampleRatio = flag.Float64(
+		"tracing-sample-ratio",
+		1,
+		"Fraction of the reconciles that are traced; must be greater than 0 & at most 1",
+	)
+	tracingServiceName = flag.String(
+		"tracing-service-name",
+		"metac",
+		"Service name that is set against the exported traces",
+	)
+	auditSink = flag.String(
+		"audit-sink",
+		"",
+		`Sink to which every create, update, patch & delete made by metac is
+		 recorded as a JSON entry i.e. stdout, stderr, file:<path> or an http(s)
+		 URL of a webhook; writes are not audited if this is not set`,
+	)
+	reconcileHistorySize = flag.Int(
+		"reconcile-history-size",
+		generic.DefaultReconcileHistorySize,
+		"Number of most recent reconciles that are retained per controller & served at /debug/history; 0 disables this history",
+	)
+	hotLoopThreshold = flag.Int(
+		"hot-loop-threshold",
+		generic.DefaultHotLoopThreshold,
+		`Number of syncs of a watch within hot-loop-window that observe a change
+		 after a sync that wrote to the watch or its attachments to consider the
+		 watch as hot looping; a negative value disables hot loop detection`,
+	)
+	hotLoopWindow = flag.Duration(
+		"hot-loop-window",
+		generic.DefaultHotLoopWindow,
+		"Duration within which hot-loop-threshold is evaluated",
+	)
+	hotLoopBackoff = flag.Duration(
+		"hot-loop-backoff",
+		generic.DefaultHotLoopBackoff,
+		"Duration for which a hot looping watch is not synced",
+	)
+	leaderElect = flag.Bool(
+		"leader-elect",
+		false,
+		"When true starts the controllers only after acquiring the leader election lease; this lets multiple replicas run with one of them active",
+	)
+	leaderElectLeaseName = flag.String(
+		"leader-elect-lease-name",
+		"metac",
+		"Name of the lease that is held by the leader",
+	)
+	leaderElectLeaseNamespace = flag.String(
+		"leader-elect-lease-namespace",
+		"metac",
+		"Namespace of the lease that is held by the leader",
+	)
+	leaderElectLeaseDuration = flag.Duration(
+		"leader-elect-lease-duration",
+		15*time.Second,
+		"Duration for which standby replicas wait before taking over a lease that is not renewed",
+	)
+	leaderElectRenewDeadline = flag.Duration(
+		"leader-elect-renew-deadline",
+		10*time.Second,
+		"Duration within which the leader should renew the lease; the leader exits if it fails to do so",
+	)
+	leaderElectRetryPeriod = flag.Duration(
+		"leader-elect-retry-period",
+		2*time.Second,
+		"Interval between the attempts to acquire or renew the lease",
+	)
+	drainTimeout = flag.Duration(
+		"drain-timeout",
+		common.DefaultDrainTimeout,
+		`Maximum duration for which the controllers wait for their in-flight syncs
+		 & then for the pending events to be written on shutdown; both of these
+		 share this duration; 0 waits till these complete`,
+	)
+	shardGroup = flag.String(
+		"shard-group",
+		"",
+		`Name of the group of replicas that share the reconciles of the watches
+		 via consistent hashing; every replica holds a lease prefixed with this
+		 name & reconciles are not sharded if this is not set`,
+	)
+	shardNamespace = flag.String(
+		"shard-namespace",
+		"metac",
+		"Namespace of the leases of the replicas of the shard group",
+	)
+	shardLeaseDuration = flag.Duration(
+		"shard-lease-duration",
+		15*time.Second,
+		"Duration after which the watches of a replica that does not renew its lease are taken over by the other replicas",
+	)
+	shardRenewInterval = flag.Duration(
+		"shard-renew-interval",
+		5*time.Second,
+		"Interval at which a replica renews its lease & looks for the replicas that joined or left the shard group",
+	)
+	admissionAddr = flag.String(
+		"admission-addr",
+		"",
+		`Address at which the validating admission webhook of GenericController,
+		 CompositeController & DecoratorController is served over TLS e.g. :9443;
+		 the webhook is not served if this is not set`,
+	)
+	admissionTLSCertFile = flag.String(
+		"admission-tls-cert-file",
+		"",
+		"Path to the certificate file with which the admission webhook is served",
+	)
+	admissionTLSKeyFile = flag.String(
+		"admission-tls-key-file",
+		"",
+		"Path to the key file of the certificate of the admission webhook",
+	)
+	admissionHookDialTimeout = flag.Duration(
+		"admission-hook-dial-timeout",
+		0,
+		`Duration within which the admission webhook needs to open a connection to
+		 every webhook of a controller for the controller to be admitted; webhooks
+		 are not dialled if this is 0`,
 	)
 )
 
+// isFlagSet returns true if the flag with the given name was set
+// from the command line
+func isFlagSet(name string) bool {
+	var found bool
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			found = true
+		}
+	})
+	return found
+}
+
+// getHookDefaults returns the webhook defaults that are set via the
+// hook defaults file & the hook flags. Nil is returned if these are
+// not set.
+func getHookDefaults() (*v1alpha1.Webhook, error) {
+	var defaults *v1alpha1.Webhook
+	if *hookDefaultsPath != "" {
+		var err error
+		defaults, err = common.LoadWebhookDefaults(*hookDefaultsPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !isFlagSet("hook-timeout") && !isFlagSet("hook-retries") && *hookHeaders == "" {
+		return defaults, nil
+	}
+	if defaults == nil {
+		defaults = &v1alpha1.Webhook{}
+	}
+	if isFlagSet("hook-timeout") {
+		defaults.Timeout = &metav1.Duration{Duration: *hookTimeout}
+	}
+	if isFlagSet("hook-retries") {
+		retries := int32(*hookRetries)
+		defaults.Retries = &retries
+	}
+	for _, header := range splitList(*hookHeaders) {
+		kv := strings.SplitN(header, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return nil, errors.Errorf(
+				"Invalid hook header %q: Want name=value", header,
+			)
+		}
+		if defaults.Headers == nil {
+			defaults.Headers = make(map[string]string)
+		}
+		defaults.Headers[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	return defaults, nil
+}
+
+// parseCacheTransform returns the transform that removes the fields
+// set via the cache strip flags
+func parseCacheTransform() (dynamicinformer.CacheTransform, error) {
+	transform := dynamicinformer.CacheTransform{
+		StripManagedFields:     *cacheStripManagedFields,
+		StripLastAppliedConfig: *cacheStripLastApplied,
+	}
+	for _, path := range splitList(*cacheStripPaths) {
+		fields := strings.Split(path, ".")
+		for _, field := range fields {
+			if field == "" {
+				return transform, errors.Errorf(
+					"Invalid cache strip path %q: Empty field", path,
+				)
+			}
+		}
+		transform.StripPaths = append(transform.StripPaths, fields)
+	}
+	return transform, nil
+}
+
+// parseResourceRateLimits returns the default rate limit of a single
+// resource & the rate limits of the resources set via the client
+// resource flags. Default limit is derived from the client-go limits
+// if not set.
+func parseResourceRateLimits() (
+	dynamicclientset.RateLimit, map[string]dynamicclientset.RateLimit, error,
+) {
+	defaultLimit := dynamicclientset.RateLimit{
+		QPS:   float32(*clientResourceQPS),
+		Burst: *clientResourceBurst,
+	}
+	if *clientResourceQPS == 0 {
+		defaultLimit.QPS = float32(*clientGoQPS) / 2
+	}
+	if *clientResourceBurst == 0 {
+		defaultLimit.Burst = (*clientGoBurst + 1) / 2
+	}
+	limits := make(map[string]dynamicclientset.RateLimit)
+	for _, item := range splitList(*clientResourceRateLimits) {
+		kv := strings.SplitN(item, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return defaultLimit, nil, errors.Errorf(
+				"Invalid client resource rate limit %q: Want resource=qps:burst", item,
+			)
+		}
+		qpsBurst := strings.Split(kv[1], ":")
+		if len(qpsBurst) != 2 {
+			return defaultLimit, nil, errors.Errorf(
+				"Invalid client resource rate limit %q: Want resource=qps:burst", item,
+			)
+		}
+		qps, err := strconv.ParseFloat(qpsBurst[0], 32)
+		if err != nil {
+			return defaultLimit, nil, errors.Wrapf(
+				err, "Invalid client resource rate limit %q: Invalid qps", item,
+			)
+		}
+		burst, err := strconv.Atoi(qpsBurst[1])
+		if err != nil {
+			return defaultLimit, nil, errors.Wrapf(
+				err, "Invalid client resource rate limit %q: Invalid burst", item,
+			)
+		}
+		limits[kv[0]] = dynamicclientset.RateLimit{QPS: float32(qps), Burst: burst}
+	}
+	return defaultLimit, limits, nil
+}
+
+// splitList returns the items from the given comma separated list
+// e.g. namespaces or glob patterns
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseTargetClusters returns the target clusters of the given comma
+// separated list. Each item is either name=path/to/kubeconfig or
+// name=secret:namespace/secret-name[/key].
+func parseTargetClusters(list string) ([]server.TargetCluster, error) {
+	var clusters []server.TargetCluster
+	for _, item := range splitList(list) {
+		kv := strings.SplitN(item, "=", 2)
+		if len(kv) != 2 || kv[0] == "" || kv[1] == "" {
+			return nil, errors.Errorf(
+				"Invalid target cluster %q: Want name=kubeconfig or name=secret:namespace/name", item,
+			)
+		}
+		cluster := server.TargetCluster{Name: kv[0]}
+		if !strings.HasPrefix(kv[1], "secret:") {
+			cluster.KubeconfigPath = kv[1]
+			clusters = append(clusters, cluster)
+			continue
+		}
+		ref := strings.Split(strings.TrimPrefix(kv[1], "secret:"), "/")
+		if len(ref) < 2 || len(ref) > 3 || ref[0] == "" || ref[1] == "" {
+			return nil, errors.Errorf(
+				"Invalid target cluster %q: Want secret:namespace/name[/key]", item,
+			)
+		}
+		cluster.SecretNamespace = ref[0]
+		cluster.SecretName = ref[1]
+		if len(ref) == 3 {
+			cluster.SecretKey = ref[2]
+		}
+		clusters = append(clusters, cluster)
+	}
+	return clusters, nil
+}
+
 // Start starts this binary
 func Start() {
 	flag.Parse()
 
 	glog.Infof("Discovery cache refresh interval: %v", *discoveryInterval)
+	glog.Infof("Discovery jitter: %v", *discoveryJitter)
+	glog.Infof("Discovery request timeout: %v", *discoveryRequestTimeout)
+	glog.Infof("Discovery cache path: %v", *discoveryCachePath)
 	glog.Infof("API server relist interval i.e. cache flush interval: %v", *informerRelist)
 	glog.Infof("Debug http server address: %v", *debugAddr)
+	glog.Infof("Debug token file: %v", *debugTokenFile)
+	glog.Infof("Enable pprof: %t", *enablePprof)
 	glog.Infof("Run metac locally: %t", *runAsLocal)
+	glog.Infof("Namespaces: %q", *namespaces)
+	glog.Infof("Excluded namespaces: %q", *excludeNamespaces)
+	glog.Infof("Client protobuf: %t", *clientProtobuf)
+	glog.Infof("Client resource QPS: %v", *clientResourceQPS)
+	glog.Infof("Client resource burst: %v", *clientResourceBurst)
+	glog.Infof("Client resource rate limits: %q", *clientResourceRateLimits)
+	glog.Infof("Controller client QPS: %v", *controllerClientQPS)
+	glog.Infof("Controller client burst: %v", *controllerClientBurst)
+	glog.Infof("Controller client timeout: %v", *controllerClientTimeout)
+	glog.Infof("Dry run: %t", *dryRun)
+	glog.Infof("Cache list page size: %d", *cacheListPageSize)
+	glog.Infof("Informer idle TTL: %v", *informerIdleTTL)
+	glog.Infof("Cache strip managed fields: %t", *cacheStripManagedFields)
+	glog.Infof("Cache strip last applied: %t", *cacheStripLastApplied)
+	glog.Infof("Cache strip paths: %q", *cacheStripPaths)
+	glog.Infof("Apply strategy: %q", *applyStrategy)
+	glog.Infof("Tracing OTLP endpoint: %q", *tracingEndpoint)
+	glog.Infof("Tracing sample ratio: %v", *tracingSampleRatio)
+	glog.Infof("Audit sink: %q", *auditSink)
+	glog.Infof("Reconcile history size: %d", *reconcileHistorySize)
+	glog.Infof(
+		"Hot loop: Threshold %d: Window %v: Backoff %v",
+		*hotLoopThreshold, *hotLoopWindow, *hotLoopBackoff,
+	)
+	glog.Infof("Leader elect: %t", *leaderElect)
+	glog.Infof(
+		"Leader elect lease: %s/%s", *leaderElectLeaseNamespace, *leaderElectLeaseName,
+	)
+	glog.Infof("Drain timeout: %v", *drainTimeout)
+	glog.Infof("Shard group: %q", *shardGroup)
+	glog.Infof("Shard namespace: %q", *shardNamespace)
+	glog.Infof("Shard lease duration: %v", *shardLeaseDuration)
+	glog.Infof("Shard renew interval: %v", *shardRenewInterval)
+	glog.Infof("Admission webhook address: %q", *admissionAddr)
+	glog.Infof("Admission webhook hook dial timeout: %v", *admissionHookDialTimeout)
+
+	if *leaderElect && *shardGroup != "" {
+		// every replica of a shard group is active
+		glog.Fatal("Can't set both --leader-elect & --shard-group")
+	}
+
+	err := generic.ValidateApplyStrategy(v1alpha1.ApplyStrategy(*applyStrategy))
+	if err != nil {
+		glog.Fatal(err)
+	}
+
+	stopTracing, err := tracing.Start(tracing.Config{
+		Endpoint:    *tracingEndpoint,
+		SampleRatio: *tracingSampleRatio,
+		ServiceName: *tracingServiceName,
+	})
+	if err != nil {
+		glog.Fatal(err)
+	}
+
+	stopAudit, err := audit.Start(audit.Config{Sink: *auditSink})
+	if err != nil {
+		glog.Fatal(err)
+	}
+
+	hookDefaults, err := getHookDefaults()
+	if err != nil {
+		glog.Fatal(err)
+	}
+	err = common.SetWebhookDefaults(hookDefaults)
+	if err != nil {
+		glog.Fatal(err)
+	}
+	err = common.SetDrainTimeout(*drainTimeout)
+	if err != nil {
+		glog.Fatal(err)
+	}
 
 	var config *rest.Config
-	var err error
 	if *clientConfigPath != "" {
 		glog.Infof("Using current context from kubeconfig file: %v", *clientConfigPath)
 		config, err = clientcmd.BuildConfigFromFlags("", *clientConfigPath)
@@ -111,24 +766,140 @@ func Start() {
 	config.QPS = float32(*clientGoQPS)
 	config.Burst = *clientGoBurst
 
-	var stopServer func()
+	// watches are partitioned among the replicas only after the
+	// members of the shard group are known
+	stopShard, err := shard.Start(config, shard.Config{
+		Group:         *shardGroup,
+		Namespace:     *shardNamespace,
+		LeaseDuration: *shardLeaseDuration,
+		RenewInterval: *shardRenewInterval,
+	})
+	if err != nil {
+		glog.Fatal(err)
+	}
+
+	var startServer func(workerCount int) (func(), error)
+	var configStatusHandler, discoveryStatusHandler, resourceMappingHandler http.Handler
+	var informerStatusHandler, debugStateHandler, reconcileHistoryHandler http.Handler
+	clusters, err := parseTargetClusters(*targetClusters)
+	if err != nil {
+		glog.Fatal(err)
+	}
+	cacheTransform, err := parseCacheTransform()
+	if err != nil {
+		glog.Fatal(err)
+	}
+	resourceRateLimit, resourceRateLimits, err := parseResourceRateLimits()
+	if err != nil {
+		glog.Fatal(err)
+	}
+
+	// workqueues of the controllers record their metrics only if
+	// these are registered before the controllers are started
+	common.RegisterQueueMetrics()
+
 	var mserver = server.Server{
-		Config:            config,
-		DiscoveryInterval: *discoveryInterval,
-		InformerRelist:    *informerRelist,
+		Config:             config,
+		DiscoveryInterval:  *discoveryInterval,
+		DiscoveryJitter:    *discoveryJitter,
+		DiscoveryTimeout:   *discoveryRequestTimeout,
+		DiscoveryCachePath: *discoveryCachePath,
+		InformerRelist:     *informerRelist,
+		Namespaces:         splitList(*namespaces),
+		ExcludeNamespaces:  splitList(*excludeNamespaces),
+		ClientProtobuf:     *clientProtobuf,
+		ResourceRateLimit:  resourceRateLimit,
+		ResourceRateLimits: resourceRateLimits,
+		DryRun:             *dryRun,
+		CacheListPageSize:  *cacheListPageSize,
+		InformerIdleTTL:    *informerIdleTTL,
+		CacheTransform:     cacheTransform,
+		ApplyStrategy:      v1alpha1.ApplyStrategy(*applyStrategy),
+		ControllerClientLimits: generic.ClientLimits{
+			QPS:     float32(*controllerClientQPS),
+			Burst:   *controllerClientBurst,
+			Timeout: *controllerClientTimeout,
+		},
+		TargetClusters:       clusters,
+		ReconcileHistorySize: *reconcileHistorySize,
+		HotLoop: generic.HotLoopSettings{
+			Threshold: *hotLoopThreshold,
+			Window:    *hotLoopWindow,
+			Backoff:   *hotLoopBackoff,
+		},
 	}
 	// start metac either as config based or CRD based
 	if *runAsLocal {
 		configServer := &server.ConfigBasedServer{
-			Server:     mserver,
-			ConfigPath: *metacConfigPath,
+			Server:                 mserver,
+			ConfigPath:             *metacConfigPath,
+			ConfigPathInclude:      splitList(*metacConfigInclude),
+			ConfigPathExclude:      splitList(*metacConfigExclude),
+			ConfigMapSelector:      *metacConfigMapSelector,
+			ConfigMapNamespace:     *metacConfigMapNamespace,
+			ConfigURL:              *configURL,
+			ConfigURLPollInterval:  *configURLPollInterval,
+			ConfigGitRepository:    *configGitRepo,
+			ConfigGitRef:           *configGitRef,
+			ConfigGitSubPath:       *configGitSubPath,
+			ConfigGitDir:           *configGitDir,
+			ConfigGitPollInterval:  *configGitPollInterval,
+			ConfigValuesPath:       *configValues,
+			IsSubstituteConfigVars: *substituteConfigVars,
+			ClusterName:            *clusterName,
+			ConfigDuplicatePolicy:  metacconfig.DuplicatePolicy(*configDuplicatePolicy),
+			EnabledProfiles:        splitList(*enableProfiles),
+			DisabledProfiles:       splitList(*disableProfiles),
 		}
-		stopServer, err = configServer.Start(*workerCount)
+		startServer = configServer.Start
+		configStatusHandler = configServer.ConfigStatusHandler()
+		debugStateHandler = configServer.DebugStateHandler()
+		reconcileHistoryHandler = configServer.ReconcileHistoryHandler()
+		discoveryStatusHandler = configServer.DiscoveryStatusHandler()
+		resourceMappingHandler = configServer.ResourceMappingHandler()
+		informerStatusHandler = configServer.InformerStatusHandler()
 	} else {
 		crdServer := &server.CRDBasedServer{Server: mserver}
-		stopServer, err = crdServer.Start(*workerCount)
+		startServer = crdServer.Start
+		debugStateHandler = crdServer.DebugStateHandler()
+		reconcileHistoryHandler = crdServer.ReconcileHistoryHandler()
+		discoveryStatusHandler = crdServer.DiscoveryStatusHandler()
+		resourceMappingHandler = crdServer.ResourceMappingHandler()
+		informerStatusHandler = crdServer.InformerStatusHandler()
+	}
+
+	// standby replicas validate the controllers as well since the
+	// API server sends the reviews to any replica
+	stopAdmission, err := startAdmission(&mserver, AdmissionConfig{
+		Addr:            *admissionAddr,
+		CertFile:        *admissionTLSCertFile,
+		KeyFile:         *admissionTLSKeyFile,
+		HookDialTimeout: *admissionHookDialTimeout,
+	})
+	if err != nil {
+		glog.Fatal(err)
 	}
 
+	// standby replicas serve the debug endpoints while these wait
+	// for the lease
+	var stopServer func()
+	if *leaderElect {
+		stopServer, err = startAsLeader(
+			config,
+			LeaderElectionConfig{
+				LeaseName:      *leaderElectLeaseName,
+				LeaseNamespace: *leaderElectLeaseNamespace,
+				LeaseDuration:  *leaderElectLeaseDuration,
+				RenewDeadline:  *leaderElectRenewDeadline,
+				RetryPeriod:    *leaderElectRetryPeriod,
+			},
+			func() (func(), error) {
+				return startServer(*workerCount)
+			},
+		)
+	} else {
+		stopServer, err = startServer(*workerCount)
+	}
 	if err != nil {
 		glog.Fatal(err)
 	}
@@ -138,9 +909,56 @@ func Start() {
 		glog.Fatalf("Can't create prometheus exporter: %v", err)
 	}
 	view.RegisterExporter(exporter)
+	views := append([]*view.View{common.HotLoopView}, common.QueueViews...)
+	views = append(views, dynamicdiscovery.Views...)
+	views = append(views, dynamicclientset.Views...)
+	err = view.Register(append(views, dynamicinformer.Views...)...)
+	if err != nil {
+		glog.Fatalf("Can't register metric views: %v", err)
+	}
 
 	mux := http.NewServeMux()
 	mux.Handle("/metrics", exporter)
+	// state of resource discovery & the group versions that failed
+	mux.Handle("/discovery", discoveryStatusHandler)
+	// resources that an apiVersion & resource or kind map to
+	mux.Handle("/discovery/resources", resourceMappingHandler)
+	// shared informers & the controllers subscribed to these
+	mux.Handle("/informers", informerStatusHandler)
+	if configStatusHandler != nil {
+		// report of the loaded configs & their controllers
+		mux.Handle("/configs", configStatusHandler)
+	}
+	// members of the shard group if the reconciles are sharded
+	mux.Handle("/shards", shard.Handler())
+	// log levels of the controllers that are set at runtime; raising
+	// these may log the hook payloads & hence requires the token
+	mux.Handle(
+		"/loglevel", server.WithBearerTokenForWrites(*debugTokenFile, logging.VerbosityHandler()),
+	)
+	// live state of the controllers; this includes their configs &
+	// hence requires the token
+	mux.Handle("/debug/state", server.WithBearerToken(*debugTokenFile, debugStateHandler))
+	// most recent reconciles of the controllers; these include the
+	// errors of the hooks & hence require the token
+	mux.Handle(
+		"/debug/history", server.WithBearerToken(*debugTokenFile, reconcileHistoryHandler),
+	)
+	if *enablePprof {
+		// cpu, heap & other profiles of this binary; these include
+		// the command line & the goroutine stacks & hence require
+		// the token
+		pprofHandlers := map[string]http.HandlerFunc{
+			"/debug/pprof/":        pprof.Index,
+			"/debug/pprof/cmdline": pprof.Cmdline,
+			"/debug/pprof/profile": pprof.Profile,
+			"/debug/pprof/symbol":  pprof.Symbol,
+			"/debug/pprof/trace":   pprof.Trace,
+		}
+		for path, handler := range pprofHandlers {
+			mux.Handle(path, server.WithBearerToken(*debugTokenFile, handler))
+		}
+	}
 	srv := &http.Server{
 		Addr:    *debugAddr,
 		Handler: mux,
@@ -156,5 +974,10 @@ func Start() {
 	glog.Infof("Received %q signal. Shutting down...", sig)
 
 	stopServer()
+	// watches are handed over once the controllers are stopped
+	stopShard()
+	stopAdmission()
+	stopAudit()
+	stopTracing()
 	srv.Shutdown(context.Background())
 }
@@ -0,0 +1,104 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package start
+
+import (
+	"reflect"
+	"testing"
+
+	"openebs.io/metac/server"
+)
+
+func TestParseTargetClusters(t *testing.T) {
+	var tests = map[string]struct {
+		list  string
+		want  []server.TargetCluster
+		isErr bool
+	}{
+		"empty list": {},
+		"kubeconfig path": {
+			list: "east=/etc/east/kubeconfig",
+			want: []server.TargetCluster{
+				{Name: "east", KubeconfigPath: "/etc/east/kubeconfig"},
+			},
+		},
+		"secret without key": {
+			list: "east=secret:metac/east",
+			want: []server.TargetCluster{
+				{Name: "east", SecretNamespace: "metac", SecretName: "east"},
+			},
+		},
+		"secret with key": {
+			list: "east=secret:metac/east/config",
+			want: []server.TargetCluster{
+				{Name: "east", SecretNamespace: "metac", SecretName: "east", SecretKey: "config"},
+			},
+		},
+		"multiple clusters with spaces": {
+			list: " east=secret:metac/east , west=/etc/west/kubeconfig,",
+			want: []server.TargetCluster{
+				{Name: "east", SecretNamespace: "metac", SecretName: "east"},
+				{Name: "west", KubeconfigPath: "/etc/west/kubeconfig"},
+			},
+		},
+		"missing equals": {
+			list:  "east",
+			isErr: true,
+		},
+		"missing name": {
+			list:  "=secret:metac/east",
+			isErr: true,
+		},
+		"missing kubeconfig": {
+			list:  "east=",
+			isErr: true,
+		},
+		"secret without name": {
+			list:  "east=secret:metac",
+			isErr: true,
+		},
+		"secret with empty namespace": {
+			list:  "east=secret:/east",
+			isErr: true,
+		},
+		"secret with empty name": {
+			list:  "east=secret:metac//config",
+			isErr: true,
+		},
+		"secret with extra path": {
+			list:  "east=secret:metac/east/config/extra",
+			isErr: true,
+		},
+		"invalid item after a valid one": {
+			list:  "east=secret:metac/east,west",
+			isErr: true,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			got, err := parseTargetClusters(mock.list)
+			if mock.isErr != (err != nil) {
+				t.Fatalf("Expected error %t got %v", mock.isErr, err)
+			}
+			if !reflect.DeepEqual(got, mock.want) {
+				t.Fatalf("Expected %+v got %+v", mock.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,309 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package start
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"openebs.io/metac/apis/metacontroller/v1alpha1"
+	"openebs.io/metac/config"
+	"openebs.io/metac/controller/composite"
+	"openebs.io/metac/controller/decorator"
+	"openebs.io/metac/controller/generic"
+	dynamicdiscovery "openebs.io/metac/dynamic/discovery"
+	k8s "openebs.io/metac/third_party/kubernetes"
+)
+
+const (
+	// validateExitInvalid is the exit code when the configs are invalid
+	validateExitInvalid = 1
+
+	// validateExitError is the exit code when the configs can't be
+	// validated e.g. due to wrong usage
+	validateExitError = 2
+)
+
+// discoveryTimeout is the duration within which the API resources of
+// the cluster need to be discovered during validation
+var discoveryTimeout = 30 * time.Second
+
+// Validate validates the metac configs & reports the problems found.
+// This implements the validate subcommand & returns the exit code of
+// this subcommand.
+//
+// NOTE:
+//	Configs are read from the given path or from stdin if the path is
+// '-' or is not provided
+func Validate(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	offline := flags.Bool(
+		"offline",
+		false,
+		"When true the watch & attachment resources are not verified against the cluster",
+	)
+	kubeconfig := flags.String(
+		"client-config-path",
+		"",
+		`Path to kubeconfig file used to verify the resources; if not specified,
+		 uses KUBECONFIG or the default kubeconfig file`,
+	)
+	include := flags.String(
+		"include",
+		"",
+		"Comma separated list of glob patterns of the config files that are validated",
+	)
+	exclude := flags.String(
+		"exclude",
+		"",
+		"Comma separated list of glob patterns of the config files that are not validated",
+	)
+	values := flags.String(
+		"values",
+		"",
+		"Path to a yaml file of values against which the configs are rendered as go templates",
+	)
+	duplicatePolicy := flags.String(
+		"duplicate-policy",
+		string(config.DuplicatePolicyFail),
+		`Fail reports the configs having the same kind, namespace & name as problems;
+		 Override lets a config override the ones loaded earlier`,
+	)
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: metac validate [flags] [path | -]\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return validateExitError
+	}
+	if flags.NArg() > 1 {
+		flags.Usage()
+		return validateExitError
+	}
+
+	loader := &config.Config{
+		Path:            flags.Arg(0),
+		Include:         splitList(*include),
+		Exclude:         splitList(*exclude),
+		DuplicatePolicy: config.DuplicatePolicy(*duplicatePolicy),
+	}
+	err := config.ValidateDuplicatePolicy(loader.DuplicatePolicy)
+	if err != nil {
+		fmt.Fprintf(stderr, "%v\n", err)
+		return validateExitError
+	}
+	if *values != "" {
+		loader.Renderer, err = config.NewRenderer(*values)
+		if err != nil {
+			fmt.Fprintf(stderr, "%v\n", err)
+			return validateExitError
+		}
+	}
+
+	var mconfigs config.MetacConfigs
+	if loader.Path == "" || loader.Path == "-" {
+		mconfigs, err = loadConfigsFromReader(stdin, loader)
+	} else {
+		mconfigs, err = loader.Load()
+	}
+	if os.IsNotExist(errors.Cause(err)) {
+		fmt.Fprintf(stderr, "%v\n", err)
+		return validateExitError
+	}
+	var ctls *validatedConfigs
+	if err == nil {
+		ctls, err = listValidatedConfigs(mconfigs)
+	}
+	if err != nil {
+		problems := printProblems(stdout, "", err)
+		fmt.Fprintf(stdout, "%d problem(s) found\n", problems)
+		return validateExitInvalid
+	}
+
+	var resourceMgr *dynamicdiscovery.APIResourceManager
+	if !*offline {
+		resourceMgr, err = newSyncedResourceManager(*kubeconfig)
+		if err != nil {
+			fmt.Fprintf(stderr, "Can't verify resources: %v: Use --offline to skip\n", err)
+			return validateExitError
+		}
+		defer resourceMgr.Stop()
+	}
+
+	// NOTE:
+	//	Duplicates are reported while loading the configs as per the
+	// duplicate policy
+	var problems int
+	for _, gctl := range ctls.gctls {
+		var errs []error
+		errs = append(errs, generic.ValidateConfig(gctl))
+		if resourceMgr != nil {
+			errs = append(errs, generic.ValidateConfigResources(resourceMgr, gctl))
+		}
+		problems += printConfigProblems(stdout, "GenericController "+gctl.Key(), errs)
+	}
+	for _, cctl := range ctls.cctls {
+		var errs []error
+		errs = append(errs, composite.ValidateConfig(cctl))
+		if resourceMgr != nil {
+			errs = append(errs, composite.ValidateConfigResources(resourceMgr, cctl))
+		}
+		problems += printConfigProblems(stdout, "CompositeController "+cctl.Name, errs)
+	}
+	for _, dctl := range ctls.dctls {
+		var errs []error
+		errs = append(errs, decorator.ValidateConfig(dctl))
+		if resourceMgr != nil {
+			errs = append(errs, decorator.ValidateConfigResources(resourceMgr, dctl))
+		}
+		problems += printConfigProblems(stdout, "DecoratorController "+dctl.Name, errs)
+	}
+	if problems != 0 {
+		fmt.Fprintf(stdout, "%d problem(s) found\n", problems)
+		return validateExitInvalid
+	}
+	fmt.Fprintf(
+		stdout,
+		"%d GenericController(s), %d CompositeController(s) & %d DecoratorController(s) are valid\n",
+		len(ctls.gctls), len(ctls.cctls), len(ctls.dctls),
+	)
+	return 0
+}
+
+// validatedConfigs holds the configs of each controller kind that
+// are validated
+type validatedConfigs struct {
+	gctls []*v1alpha1.GenericController
+	cctls []*v1alpha1.CompositeController
+	dctls []*v1alpha1.DecoratorController
+}
+
+// listValidatedConfigs decodes the given metac configs of each
+// controller kind
+func listValidatedConfigs(mconfigs config.MetacConfigs) (*validatedConfigs, error) {
+	gctls, err := mconfigs.ListGenericControllers()
+	if err != nil {
+		return nil, err
+	}
+	cctls, err := mconfigs.ListCompositeControllers()
+	if err != nil {
+		return nil, err
+	}
+	dctls, err := mconfigs.ListDecoratorControllers()
+	if err != nil {
+		return nil, err
+	}
+	return &validatedConfigs{gctls: gctls, cctls: cctls, dctls: dctls}, nil
+}
+
+// printConfigProblems prints the problems of the given config & returns
+// the number of problems printed
+func printConfigProblems(out io.Writer, desc string, errs []error) int {
+	err := utilerrors.Flatten(utilerrors.NewAggregate(errs))
+	if err == nil {
+		return 0
+	}
+	return printProblems(out, desc+": ", err)
+}
+
+// loadConfigsFromReader loads the metac configs from the given reader.
+// These are rendered & their duplicates are resolved as per the given
+// loader.
+func loadConfigsFromReader(
+	reader io.Reader, loader *config.Config,
+) (config.MetacConfigs, error) {
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to read metac config(s) from stdin")
+	}
+	data, err = loader.Renderer.Render("<stdin>", data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to render metac config(s) from stdin")
+	}
+	err = config.ValidateGenericControllers("<stdin>", data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Invalid metac config(s) from stdin")
+	}
+	ul, err := k8s.YAMLToUnstructuredSlice(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to load metac config(s) from stdin")
+	}
+	// documents may have the same key
+	overrides := config.NewOverrides(loader.DuplicatePolicy)
+	overrides.Add("<stdin>", ul)
+	mconfigs, err := overrides.Configs()
+	if err != nil {
+		return nil, errors.Wrapf(err, "Invalid metac config(s) from stdin")
+	}
+	return mconfigs, nil
+}
+
+// newSyncedResourceManager returns a resource manager that has
+// discovered the API resources of the cluster referred by the given
+// kubeconfig
+func newSyncedResourceManager(kubeconfig string) (*dynamicdiscovery.APIResourceManager, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if kubeconfig != "" {
+		rules.ExplicitPath = kubeconfig
+	}
+	restConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		rules, &clientcmd.ConfigOverrides{},
+	).ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	resourceMgr := dynamicdiscovery.NewAPIResourceManager(discoveryClient)
+	resourceMgr.Start(discoveryTimeout)
+	err = wait.PollImmediate(100*time.Millisecond, discoveryTimeout, func() (bool, error) {
+		return resourceMgr.HasSynced(), nil
+	})
+	if err != nil {
+		resourceMgr.Stop()
+		return nil, errors.Wrapf(err, "Can't discover API resources")
+	}
+	return resourceMgr, nil
+}
+
+// printProblems prints each problem of the given error on its own line
+// & returns the number of problems printed
+func printProblems(out io.Writer, prefix string, err error) int {
+	agg, ok := errors.Cause(err).(utilerrors.Aggregate)
+	if !ok {
+		fmt.Fprintf(out, "%s%v\n", prefix, err)
+		return 1
+	}
+	var count int
+	for _, e := range utilerrors.Flatten(agg).Errors() {
+		count += printProblems(out, prefix, e)
+	}
+	return count
+}
@@ -0,0 +1,352 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
+)
+
+const (
+	// otlpTracesPath is the path of the OTLP/HTTP traces endpoint
+	otlpTracesPath = "/v1/traces"
+
+	// scope of the exported spans
+	otlpScopeName = "openebs.io/metac"
+
+	// maximum number of spans that are buffered before these get
+	// exported; spans are dropped once the buffer is full
+	otlpBufferSize = 4096
+
+	// maximum number of spans sent per export request
+	otlpBatchSize = 512
+
+	// interval at which the buffered spans are exported
+	otlpFlushInterval = 5 * time.Second
+
+	// time within which a single export request should complete
+	otlpRequestTimeout = 10 * time.Second
+)
+
+// OTLP span kinds & status codes
+const (
+	otlpSpanKindInternal = 1
+	otlpSpanKindServer   = 2
+	otlpSpanKindClient   = 3
+
+	otlpStatusCodeUnset = 0
+	otlpStatusCodeError = 2
+)
+
+// otlpExporter exports the spans to an OpenTelemetry collector as
+// OTLP/HTTP json
+type otlpExporter struct {
+	url         string
+	serviceName string
+	client      *http.Client
+
+	spans    chan *trace.SpanData
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	doneCh   chan struct{}
+
+	// number of spans that were dropped since the buffer was full
+	dropped int64
+}
+
+// otlpExporter implements trace.Exporter interface
+var _ trace.Exporter = &otlpExporter{}
+
+// newOTLPExporter returns a new exporter that sends the spans to the
+// given endpoint. The traces path is appended to the endpoint if it
+// does not have a path.
+func newOTLPExporter(endpoint, serviceName string) (*otlpExporter, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Invalid tracing endpoint %q", endpoint)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, errors.Errorf(
+			"Invalid tracing endpoint %q: Want http or https scheme", endpoint,
+		)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = otlpTracesPath
+	}
+	return &otlpExporter{
+		url:         u.String(),
+		serviceName: serviceName,
+		client:      &http.Client{Timeout: otlpRequestTimeout},
+		spans:       make(chan *trace.SpanData, otlpBufferSize),
+		stopCh:      make(chan struct{}),
+		doneCh:      make(chan struct{}),
+	}, nil
+}
+
+// ExportSpan buffers the given span to be exported. The span is
+// dropped if the buffer is full.
+func (e *otlpExporter) ExportSpan(span *trace.SpanData) {
+	select {
+	case e.spans <- span:
+	default:
+		atomic.AddInt64(&e.dropped, 1)
+	}
+}
+
+// run exports the buffered spans in batches till this exporter is
+// stopped
+func (e *otlpExporter) run() {
+	defer close(e.doneCh)
+
+	ticker := time.NewTicker(otlpFlushInterval)
+	defer ticker.Stop()
+
+	var batch []*trace.SpanData
+	for {
+		select {
+		case span := <-e.spans:
+			batch = append(batch, span)
+			if len(batch) >= otlpBatchSize {
+				batch = e.flush(batch)
+			}
+		case <-ticker.C:
+			batch = e.flush(batch)
+		case <-e.stopCh:
+			// export whatever is buffered
+			for {
+				select {
+				case span := <-e.spans:
+					batch = append(batch, span)
+				default:
+					e.flush(batch)
+					return
+				}
+			}
+		}
+	}
+}
+
+// stop exports the buffered spans & stops this exporter. It is safe
+// to invoke this more than once.
+func (e *otlpExporter) stop() {
+	e.stopOnce.Do(func() {
+		close(e.stopCh)
+	})
+	<-e.doneCh
+}
+
+// flush exports the given spans & returns the batch to be filled
+// next. Spans are dropped if their export fails.
+func (e *otlpExporter) flush(batch []*trace.SpanData) []*trace.SpanData {
+	if dropped := atomic.SwapInt64(&e.dropped, 0); dropped > 0 {
+		glog.Warningf("Dropped %d span(s): Tracing buffer is full", dropped)
+	}
+	if len(batch) == 0 {
+		return batch
+	}
+	err := e.export(batch)
+	if err != nil {
+		glog.Warningf("Failed to export %d span(s) to %s: %v", len(batch), e.url, err)
+	}
+	return batch[:0]
+}
+
+// export sends the given spans to the collector
+func (e *otlpExporter) export(spans []*trace.SpanData) error {
+	body, err := json.Marshal(e.makeRequest(spans))
+	if err != nil {
+		return errors.Wrapf(err, "Failed to marshal spans")
+	}
+	resp, err := e.client.Post(e.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return errors.Errorf("Got status %d: Response %q", resp.StatusCode, respBody)
+	}
+	return nil
+}
+
+// OTLP/HTTP json request of the traces; only the fields that are set
+// by this exporter are declared
+type otlpTracesRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Events            []otlpEvent    `json:"events,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpEvent struct {
+	TimeUnixNano string         `json:"timeUnixNano"`
+	Name         string         `json:"name"`
+	Attributes   []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+// makeRequest returns the OTLP request of the given spans
+func (e *otlpExporter) makeRequest(spans []*trace.SpanData) otlpTracesRequest {
+	otlpSpans := make([]otlpSpan, 0, len(spans))
+	for _, span := range spans {
+		otlpSpans = append(otlpSpans, makeOTLPSpan(span))
+	}
+	return otlpTracesRequest{
+		ResourceSpans: []otlpResourceSpans{
+			{
+				Resource: otlpResource{
+					Attributes: []otlpKeyValue{makeOTLPKeyValue("service.name", e.serviceName)},
+				},
+				ScopeSpans: []otlpScopeSpans{
+					{
+						Scope: otlpScope{Name: otlpScopeName},
+						Spans: otlpSpans,
+					},
+				},
+			},
+		},
+	}
+}
+
+// makeOTLPSpan returns the given span in OTLP format
+func makeOTLPSpan(span *trace.SpanData) otlpSpan {
+	s := otlpSpan{
+		TraceID:           hex.EncodeToString(span.TraceID[:]),
+		SpanID:            hex.EncodeToString(span.SpanID[:]),
+		Name:              span.Name,
+		Kind:              otlpSpanKindInternal,
+		StartTimeUnixNano: unixNano(span.StartTime),
+		EndTimeUnixNano:   unixNano(span.EndTime),
+		Attributes:        makeOTLPAttributes(span.Attributes),
+		Status:            otlpStatus{Code: otlpStatusCodeUnset},
+	}
+	if span.ParentSpanID != (trace.SpanID{}) {
+		s.ParentSpanID = hex.EncodeToString(span.ParentSpanID[:])
+	}
+	switch span.SpanKind {
+	case trace.SpanKindServer:
+		s.Kind = otlpSpanKindServer
+	case trace.SpanKindClient:
+		s.Kind = otlpSpanKindClient
+	}
+	if span.Code != trace.StatusCodeOK {
+		s.Status = otlpStatus{Code: otlpStatusCodeError, Message: span.Message}
+	}
+	for _, annotation := range span.Annotations {
+		s.Events = append(s.Events, otlpEvent{
+			TimeUnixNano: unixNano(annotation.Time),
+			Name:         annotation.Message,
+			Attributes:   makeOTLPAttributes(annotation.Attributes),
+		})
+	}
+	return s
+}
+
+// makeOTLPAttributes returns the given attributes in OTLP format
+func makeOTLPAttributes(attrs map[string]interface{}) []otlpKeyValue {
+	if len(attrs) == 0 {
+		return nil
+	}
+	kvs := make([]otlpKeyValue, 0, len(attrs))
+	for key, value := range attrs {
+		kvs = append(kvs, makeOTLPKeyValue(key, value))
+	}
+	return kvs
+}
+
+// makeOTLPKeyValue returns the given attribute in OTLP format
+func makeOTLPKeyValue(key string, value interface{}) otlpKeyValue {
+	kv := otlpKeyValue{Key: key}
+	switch val := value.(type) {
+	case string:
+		kv.Value.StringValue = &val
+	case bool:
+		kv.Value.BoolValue = &val
+	case int64:
+		str := strconv.FormatInt(val, 10)
+		kv.Value.IntValue = &str
+	case float64:
+		kv.Value.DoubleValue = &val
+	default:
+		str := fmt.Sprint(val)
+		kv.Value.StringValue = &str
+	}
+	return kv
+}
+
+// unixNano returns the given time as nanoseconds since epoch
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
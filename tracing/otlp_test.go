@@ -0,0 +1,263 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opencensus.io/trace"
+)
+
+func TestMakeOTLPSpan(t *testing.T) {
+	start := time.Unix(1570000000, 123456789)
+	end := start.Add(1500 * time.Millisecond)
+	traceID := trace.TraceID{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x10}
+	spanID := trace.SpanID{0xa1, 0xb2, 0xc3, 0xd4, 0xe5, 0xf6, 0x07, 0x18}
+	parentSpanID := trace.SpanID{0, 0, 0, 0, 0, 0, 0, 0x01}
+	newSpan := func() *trace.SpanData {
+		return &trace.SpanData{
+			SpanContext: trace.SpanContext{TraceID: traceID, SpanID: spanID},
+			Name:        "sync",
+			StartTime:   start,
+			EndTime:     end,
+		}
+	}
+
+	var tests = map[string]struct {
+		span *trace.SpanData
+		want otlpSpan
+	}{
+		"root internal span": {
+			span: newSpan(),
+			want: otlpSpan{
+				TraceID:           "0102030405060708090a0b0c0d0e0f10",
+				SpanID:            "a1b2c3d4e5f60718",
+				Name:              "sync",
+				Kind:              otlpSpanKindInternal,
+				StartTimeUnixNano: "1570000000123456789",
+				EndTimeUnixNano:   "1570000001623456789",
+				Status:            otlpStatus{Code: otlpStatusCodeUnset},
+			},
+		},
+		"server span with parent": {
+			span: func() *trace.SpanData {
+				s := newSpan()
+				s.SpanKind = trace.SpanKindServer
+				s.ParentSpanID = parentSpanID
+				return s
+			}(),
+			want: otlpSpan{
+				TraceID:           "0102030405060708090a0b0c0d0e0f10",
+				SpanID:            "a1b2c3d4e5f60718",
+				ParentSpanID:      "0000000000000001",
+				Name:              "sync",
+				Kind:              otlpSpanKindServer,
+				StartTimeUnixNano: "1570000000123456789",
+				EndTimeUnixNano:   "1570000001623456789",
+				Status:            otlpStatus{Code: otlpStatusCodeUnset},
+			},
+		},
+		"failed client span": {
+			span: func() *trace.SpanData {
+				s := newSpan()
+				s.SpanKind = trace.SpanKindClient
+				s.Status = trace.Status{Code: trace.StatusCodeUnavailable, Message: "hook unavailable"}
+				return s
+			}(),
+			want: otlpSpan{
+				TraceID:           "0102030405060708090a0b0c0d0e0f10",
+				SpanID:            "a1b2c3d4e5f60718",
+				Name:              "sync",
+				Kind:              otlpSpanKindClient,
+				StartTimeUnixNano: "1570000000123456789",
+				EndTimeUnixNano:   "1570000001623456789",
+				Status:            otlpStatus{Code: otlpStatusCodeError, Message: "hook unavailable"},
+			},
+		},
+		"span with annotation": {
+			span: func() *trace.SpanData {
+				s := newSpan()
+				s.Annotations = []trace.Annotation{{
+					Time:       start.Add(time.Millisecond),
+					Message:    "retry",
+					Attributes: map[string]interface{}{"attempt": int64(2)},
+				}}
+				return s
+			}(),
+			want: otlpSpan{
+				TraceID:           "0102030405060708090a0b0c0d0e0f10",
+				SpanID:            "a1b2c3d4e5f60718",
+				Name:              "sync",
+				Kind:              otlpSpanKindInternal,
+				StartTimeUnixNano: "1570000000123456789",
+				EndTimeUnixNano:   "1570000001623456789",
+				Events: []otlpEvent{{
+					TimeUnixNano: "1570000000124456789",
+					Name:         "retry",
+					Attributes:   []otlpKeyValue{makeOTLPKeyValue("attempt", int64(2))},
+				}},
+				Status: otlpStatus{Code: otlpStatusCodeUnset},
+			},
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			got := makeOTLPSpan(mock.span)
+			if !reflect.DeepEqual(got, mock.want) {
+				t.Fatalf("Expected %+v got %+v", mock.want, got)
+			}
+		})
+	}
+}
+
+func TestMakeOTLPKeyValue(t *testing.T) {
+	var tests = map[string]struct {
+		value interface{}
+		want  string
+	}{
+		"string": {
+			value: "my-pod",
+			want:  `{"key":"attr","value":{"stringValue":"my-pod"}}`,
+		},
+		"bool": {
+			value: false,
+			want:  `{"key":"attr","value":{"boolValue":false}}`,
+		},
+		"int64 as string": {
+			value: int64(9007199254740993),
+			want:  `{"key":"attr","value":{"intValue":"9007199254740993"}}`,
+		},
+		"float64": {
+			value: 0.5,
+			want:  `{"key":"attr","value":{"doubleValue":0.5}}`,
+		},
+		"other type as string": {
+			value: []string{"a", "b"},
+			want:  `{"key":"attr","value":{"stringValue":"[a b]"}}`,
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			got, err := json.Marshal(makeOTLPKeyValue("attr", mock.value))
+			if err != nil {
+				t.Fatalf("Expected no error got %v", err)
+			}
+			if string(got) != mock.want {
+				t.Fatalf("Expected %s got %s", mock.want, got)
+			}
+		})
+	}
+}
+
+// newCollectorServer returns a server that records the number of
+// spans per export request it receives
+func newCollectorServer(t *testing.T) (*httptest.Server, func() []int) {
+	var mutex sync.Mutex
+	var exports []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != otlpTracesPath {
+			t.Errorf("Expected path %q got %q", otlpTracesPath, r.URL.Path)
+		}
+		var req otlpTracesRequest
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			t.Errorf("Expected valid request got %v", err)
+		}
+		var count int
+		for _, rs := range req.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				count += len(ss.Spans)
+			}
+		}
+		mutex.Lock()
+		exports = append(exports, count)
+		mutex.Unlock()
+	}))
+	return server, func() []int {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return append([]int(nil), exports...)
+	}
+}
+
+func TestOTLPExporterRunAndStop(t *testing.T) {
+	var tests = map[string]struct {
+		spans       int
+		wantBatched []int
+		want        []int
+	}{
+		"no spans": {},
+		"spans below batch size": {
+			spans: 3,
+			want:  []int{3},
+		},
+		"spans of exact batch size": {
+			spans:       otlpBatchSize,
+			wantBatched: []int{otlpBatchSize},
+			want:        []int{otlpBatchSize},
+		},
+		"spans above batch size": {
+			spans:       otlpBatchSize + 10,
+			wantBatched: []int{otlpBatchSize},
+			want:        []int{otlpBatchSize, 10},
+		},
+	}
+	for name, mock := range tests {
+		name := name
+		mock := mock
+		t.Run(name, func(t *testing.T) {
+			server, getExports := newCollectorServer(t)
+			defer server.Close()
+
+			e, err := newOTLPExporter(server.URL, "metac")
+			if err != nil {
+				t.Fatalf("Expected no error got %v", err)
+			}
+			go e.run()
+			for i := 0; i < mock.spans; i++ {
+				e.ExportSpan(&trace.SpanData{Name: "sync"})
+			}
+
+			// full batches are exported without waiting for the flush
+			// interval
+			deadline := time.Now().Add(otlpFlushInterval / 2)
+			for len(getExports()) < len(mock.wantBatched) && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+			}
+			if got := getExports(); !reflect.DeepEqual(got, mock.wantBatched) {
+				t.Fatalf("Expected batched exports %v got %v", mock.wantBatched, got)
+			}
+
+			// stop flushes the buffered spans & is safe to repeat
+			e.stop()
+			e.stop()
+			if got := getExports(); !reflect.DeepEqual(got, mock.want) {
+				t.Fatalf("Expected exports %v got %v", mock.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2019 The MayaData Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tracing traces the reconciles of metac & exports the
+// spans of these traces to an OpenTelemetry collector via OTLP
+package tracing
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
+)
+
+const (
+	// keys of the attributes set against the spans
+
+	// AttributeController is the namespace/name of the controller
+	AttributeController = "metac.controller"
+
+	// AttributeWatch is the watch that is reconciled
+	AttributeWatch = "metac.watch"
+
+	// AttributeAttachment is the attachment that is operated upon
+	AttributeAttachment = "metac.attachment"
+)
+
+// Config represents the tunables of tracing
+type Config struct {
+	// OTLP/HTTP endpoint of the collector e.g. http://collector:4318;
+	// tracing is disabled if this is not set
+	Endpoint string
+
+	// fraction of the reconciles that are traced
+	SampleRatio float64
+
+	// name of the service that is set against the exported spans
+	ServiceName string
+}
+
+// Start exports the sampled spans to the collector of the given
+// config. No span is sampled if the config does not set an endpoint.
+// The returned function flushes the pending spans & stops the export.
+func Start(config Config) (func(), error) {
+	if config.Endpoint == "" {
+		trace.ApplyConfig(trace.Config{DefaultSampler: trace.NeverSample()})
+		return func() {}, nil
+	}
+	if config.SampleRatio <= 0 || config.SampleRatio > 1 {
+		return nil, errors.Errorf(
+			"Invalid tracing sample ratio %g: Must be greater than 0 & at most 1",
+			config.SampleRatio,
+		)
+	}
+	if config.ServiceName == "" {
+		config.ServiceName = "metac"
+	}
+	exporter, err := newOTLPExporter(config.Endpoint, config.ServiceName)
+	if err != nil {
+		return nil, err
+	}
+	trace.RegisterExporter(exporter)
+	trace.ApplyConfig(trace.Config{
+		DefaultSampler: trace.ProbabilitySampler(config.SampleRatio),
+	})
+	go exporter.run()
+	return func() {
+		trace.UnregisterExporter(exporter)
+		exporter.stop()
+	}, nil
+}
+
+// StartSpan starts a span of the given name that is a child of the
+// span found in the given context if any. The returned context holds
+// the started span.
+//
+// NOTE:
+//	Given context may be nil
+func StartSpan(
+	ctx context.Context, name string, attrs ...trace.Attribute,
+) (context.Context, *trace.Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, span := trace.StartSpan(ctx, name)
+	if len(attrs) != 0 && span.IsRecordingEvents() {
+		span.AddAttributes(attrs...)
+	}
+	return ctx, span
+}
+
+// EndSpan sets the status of the given span based on the given error
+// & ends this span
+func EndSpan(span *trace.Span, err error) {
+	if err != nil && span.IsRecordingEvents() {
+		span.SetStatus(trace.Status{
+			Code:    trace.StatusCodeUnknown,
+			Message: firstLine(err.Error()),
+		})
+	}
+	span.End()
+}
+
+// IsSampled returns true if the span found in the given context is
+// sampled i.e. will be exported
+func IsSampled(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	span := trace.FromContext(ctx)
+	return span != nil && span.SpanContext().IsSampled()
+}
+
+// firstLine returns the first line of the given message
+func firstLine(msg string) string {
+	if idx := strings.IndexByte(msg, '\n'); idx >= 0 {
+		return msg[:idx]
+	}
+	return msg
+}